@@ -213,7 +213,7 @@ export default function TaskGraph({ taskGraphData }) {
     );
   }
 
-  const { tasks, relationships, root_tasks, leaf_tasks, critical_path, graph_complexity } = taskGraphData;
+  const { tasks, relationships, root_tasks, leaf_tasks, critical_path, critical_path_effort, graph_complexity } = taskGraphData;
 
   const getTaskConnections = (taskId) => {
     return relationships?.filter(
@@ -461,7 +461,9 @@ export default function TaskGraph({ taskGraphData }) {
         </View>
         <View style={styles.statCard}>
           <Text style={styles.statValue}>{critical_path?.length || 0}</Text>
-          <Text style={styles.statLabel}>Critical Path</Text>
+          <Text style={styles.statLabel}>
+            Critical Path{critical_path_effort ? ` (${critical_path_effort} pts)` : ''}
+          </Text>
         </View>
         <View style={styles.statCard}>
           <Text style={styles.statValue}>{Math.round(graph_complexity * 100)}%</Text>
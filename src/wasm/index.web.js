@@ -86,7 +86,9 @@ export async function initWasm() {
   return initPromise;
 }
 
-export async function processText(operation, text) {
+// options (analyze only): { stages: [...], format: 'json' | 'object' }.
+// With format 'object' the result data is a JS object instead of a JSON string.
+export async function processText(operation, text, options) {
   // Check if WASM is still running
   if (!isRunning) {
     console.log('WASM not running, reinitializing...');
@@ -99,8 +101,10 @@ export async function processText(operation, text) {
     throw new Error('processText not available');
   }
   
+  const call = (f) => (options === undefined ? f(operation, text) : f(operation, text, options));
+
   try {
-    return fn(operation, text);
+    return call(fn);
   } catch (error) {
     console.error('Error calling processText:', error);
     // If the error indicates the Go program has exited, reset and retry once
@@ -111,8 +115,46 @@ export async function processText(operation, text) {
       goInstance = null;
       await initWasm();
       // Try once more
-      return globalThis.processText(operation, text);
+      return call(globalThis.processText);
     }
     throw error;
   }
 }
+
+// analyzeAsync runs a full analysis off the calling task and resolves with the
+// result (a JSON string, or an object with format 'object'). options may hold
+// `stages`, `format`, an AbortSignal as `signal`, and `onProgress(event)`, which
+// receives stage_start/stage_complete events with completed/total/percent.
+export async function analyzeAsync(text, options = {}) {
+  if (!isRunning) {
+    initPromise = null;
+    await initWasm();
+  }
+
+  const fn = globalThis.analyzeAsync;
+  if (typeof fn !== 'function') {
+    throw new Error('analyzeAsync not available');
+  }
+  return fn(text, options);
+}
+
+// startAnalysis begins an analysis that can run alongside others and returns its
+// request ID. Results go to options.onComplete / options.onError, or are held
+// until getAnalysisResult(id) collects them.
+export async function startAnalysis(text, options = {}) {
+  if (!isRunning) {
+    initPromise = null;
+    await initWasm();
+  }
+  const res = globalThis.startAnalysis(text, options);
+  if (!res.success) throw new Error(res.error);
+  return res.id;
+}
+
+export function cancelAnalysis(id) {
+  return typeof globalThis.cancelAnalysis === 'function' && globalThis.cancelAnalysis(id);
+}
+
+export function getAnalysisResult(id) {
+  return typeof globalThis.getAnalysisResult === 'function' ? globalThis.getAnalysisResult(id) : null;
+}
@@ -31,18 +31,19 @@ class WasmWorkerManager {
   }
 
   // Send message to worker and wait for response
-  sendMessage(type, payload) {
+  sendMessage(type, payload, { onProgress, onId, timeoutMs = 30000 } = {}) {
     return new Promise((resolve, reject) => {
       const id = ++this.messageId;
       
       // Store the promise callbacks
-      this.pendingMessages.set(id, { resolve, reject });
+      this.pendingMessages.set(id, { resolve, reject, onProgress });
+      if (onId) onId(id);
       
       // Set a timeout
       const timeout = setTimeout(() => {
         this.pendingMessages.delete(id);
         reject(new Error(`Worker timeout for message ${id}`));
-      }, 30000); // 30 second timeout
+      }, timeoutMs);
       
       // Update callbacks to clear timeout
       const originalResolve = this.pendingMessages.get(id).resolve;
@@ -94,11 +95,25 @@ class WasmWorkerManager {
             if (pending) {
               pending.resolve(payload);
             }
+          } else if (type === 'progress') {
+            // Stage event for a running analyze request
+            const pending = this.pendingMessages.get(id);
+            if (pending && pending.onProgress) {
+              pending.onProgress(payload);
+            }
+          } else if (type === 'closed') {
+            // Worker acknowledged shutdown
+            const pending = this.pendingMessages.get(id);
+            if (pending) {
+              pending.resolve(true);
+            }
           } else if (type === 'error') {
             // Error occurred
             const pending = this.pendingMessages.get(id);
             if (pending) {
-              pending.reject(new Error(payload.message || 'Worker error'));
+              const error = new Error(payload.message || 'Worker error');
+              if (payload.name) error.name = payload.name;
+              pending.reject(error);
             }
           }
         });
@@ -150,6 +165,63 @@ class WasmWorkerManager {
     });
   }
 
+  // Run a full analysis in the worker. options takes stages, format,
+  // onProgress(event) and an AbortSignal as signal. JSON results arrive as a
+  // transferred ArrayBuffer and are decoded back to the JSON string here.
+  // With compression: 'gzip' the worker sends gzipped JSON, which is
+  // decompressed here, so callers still get the JSON string.
+  async analyze(text, options = {}) {
+    if (!this.isInitialized) {
+      await this.init();
+    }
+
+    const { onProgress, signal, timeoutMs, ...analysisOptions } = options;
+    if (signal && signal.aborted) {
+      const error = new Error('analysis was aborted');
+      error.name = 'AbortError';
+      throw error;
+    }
+
+    let messageId = null;
+    const onAbort = () => {
+      if (messageId !== null && this.worker) {
+        this.worker.postMessage({ type: 'cancel', id: messageId });
+      }
+    };
+    if (signal) signal.addEventListener('abort', onAbort);
+
+    try {
+      const payload = await this.sendMessage(
+        'analyze',
+        { text, options: analysisOptions },
+        { onProgress, onId: (id) => { messageId = id; }, timeoutMs }
+      );
+      if (payload.encoding === 'json') {
+        return new TextDecoder().decode(payload.buffer);
+      }
+      if (payload.encoding === 'gzip') {
+        const stream = new Blob([payload.buffer]).stream().pipeThrough(new DecompressionStream('gzip'));
+        return new Response(stream).text();
+      }
+      return payload.data;
+    } finally {
+      if (signal) signal.removeEventListener('abort', onAbort);
+    }
+  }
+
+  // Ask the Go side to cancel work and exit through its cleanup hook,
+  // then terminate the worker
+  async shutdown() {
+    if (this.worker && this.isInitialized) {
+      try {
+        await this.sendMessage('shutdown', {}, { timeoutMs: 2000 });
+      } catch (error) {
+        console.warn('Worker did not acknowledge shutdown:', error);
+      }
+    }
+    this.cleanup();
+  }
+
   // Clean up resources
   cleanup() {
     if (this.worker) {
@@ -194,6 +266,22 @@ export async function processText(operation, text) {
   return workerManager.process(operation, text);
 }
 
+export async function analyze(text, options) {
+  if (!workerManager) {
+    await initWasm();
+  }
+
+  return workerManager.analyze(text, options);
+}
+
+export async function shutdownWasm() {
+  if (workerManager) {
+    const manager = workerManager;
+    workerManager = null;
+    await manager.shutdown();
+  }
+}
+
 export function cleanupWasm() {
   if (workerManager) {
     workerManager.cleanup();
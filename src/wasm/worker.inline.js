@@ -19,16 +19,25 @@ export const createWorker = () => {
             self.postMessage({ type: 'ready', id });
             break;
             
-          case 'process':
+          default:
             if (!isReady) {
               throw new Error('WASM not initialized');
             }
+            // Newer modules speak the message protocol themselves
+            // (process, analyze, cancel, shutdown) and reply via postMessage
+            if (typeof self.fulcrumHandleMessage === 'function') {
+              self.fulcrumHandleMessage(e.data);
+              if (type === 'shutdown') {
+                self.close();
+              }
+              break;
+            }
+            if (type !== 'process') {
+              throw new Error('Unknown message type: ' + type);
+            }
             const result = await processInWorker(payload);
             self.postMessage({ type: 'result', id, payload: result });
             break;
-            
-          default:
-            throw new Error('Unknown message type: ' + type);
         }
       } catch (error) {
         self.postMessage({ 
@@ -0,0 +1,127 @@
+//go:build !js
+
+// Command fulcrum-corpus audits a prompt library: it analyzes every prompt in a
+// directory or zip archive and prints the aggregate CorpusReport as JSON, or
+// with -format sarif a SARIF log of per-prompt findings for code scanning.
+//
+//	fulcrum-corpus prompts/
+//	fulcrum-corpus -timeout 5m library.zip > report.json
+//	fulcrum-corpus -format sarif prompts/ > fulcrum.sarif
+//	fulcrum-corpus -options options.yaml prompts/
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"fulcrum-wasm/internal/analyzer"
+)
+
+func main() {
+	timeout := flag.Duration("timeout", 10*time.Minute, "stop after this long")
+	maxZipBytes := flag.Int64("max-zip-bytes", 256<<20, "maximum uncompressed size of a zip corpus")
+	format := flag.String("format", "json", "output format: json (corpus report) or sarif")
+	optionsPath := flag.String("options", "", "JSON or YAML analysis options (grader, rubric, glossary, rules...) for every prompt")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [flags] <directory|archive.zip>\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 || (*format != "json" && *format != "sarif") {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	opts := analyzer.AnalysisOptions{}
+	if *optionsPath != "" {
+		data, err := os.ReadFile(*optionsPath)
+		if err == nil {
+			err = analyzer.DecodeConfig(data, &opts)
+		}
+		if err == nil {
+			err = opts.Validate()
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+
+	docs, err := loadCorpus(flag.Arg(0), *maxZipBytes)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if len(docs) == 0 {
+		fmt.Fprintln(os.Stderr, "no .txt, .md or .prompt files found")
+		os.Exit(1)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	ctx, cancel := context.WithTimeout(ctx, *timeout)
+	defer cancel()
+
+	var out interface{}
+	if *format == "sarif" {
+		out, err = sarifLog(ctx, flag.Arg(0), docs, opts)
+	} else {
+		out, err = analyzer.AnalyzeCorpus(ctx, docs, opts)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(out); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// sarifLog analyzes each document and exports its findings. Files from a
+// directory are reported relative to the working directory so code scanning
+// can place them; zip entries keep their archive paths.
+func sarifLog(ctx context.Context, root string, docs []analyzer.CorpusDocument, opts analyzer.AnalysisOptions) (*analyzer.SARIFLog, error) {
+	prefix := ""
+	if !strings.EqualFold(filepath.Ext(root), ".zip") {
+		prefix = root
+	}
+	sarifDocs := make([]analyzer.SARIFDocument, 0, len(docs))
+	for _, doc := range docs {
+		result, err := analyzer.Analyze(ctx, doc.Text, opts, analyzer.AnalysisRun{})
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			fmt.Fprintf(os.Stderr, "%s: %v\n", doc.Name, err)
+			continue
+		}
+		sarifDocs = append(sarifDocs, analyzer.SARIFDocument{URI: filepath.ToSlash(filepath.Join(prefix, doc.Name)), Text: doc.Text, Result: result})
+	}
+	return analyzer.ExportSARIF(sarifDocs), nil
+}
+
+func loadCorpus(path string, maxZipBytes int64) ([]analyzer.CorpusDocument, error) {
+	if !strings.EqualFold(filepath.Ext(path), ".zip") {
+		return analyzer.LoadCorpusDir(path)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	return analyzer.LoadCorpusZip(f, info.Size(), maxZipBytes)
+}
@@ -0,0 +1,121 @@
+//go:build !js
+
+// Command fulcrum-dataset turns prompt-response pairs into a JSON Lines
+// dataset for training prompt-improvement models. Each input line is a JSON
+// object with "prompt" and optional "id", "response" and "tags"; each output
+// line carries the prompt's grade, its rewrite and the response's scores, or
+// with -format chat a system/user/assistant example of prompt to rewrite.
+//
+//	fulcrum-dataset pairs.jsonl > dataset.jsonl
+//	fulcrum-dataset -format chat -min-improvement 5 -o train.jsonl pairs.jsonl
+//	FULCRUM_LLM_PROVIDER=openai fulcrum-dataset -llm - < pairs.jsonl
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"time"
+
+	"fulcrum-wasm/internal/analyzer"
+)
+
+func main() {
+	format := flag.String("format", analyzer.DatasetRecords, "output format: records or chat")
+	minImprovement := flag.Float64("min-improvement", 0, "drop examples whose rewrite gains fewer score points")
+	llm := flag.Bool("llm", false, "rewrite with the model configured by FULCRUM_LLM_* instead of the rule-based rewriter")
+	output := flag.String("o", "", "write the dataset to this file instead of stdout")
+	timeout := flag.Duration("timeout", 30*time.Minute, "stop after this long")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [flags] <pairs.jsonl|->\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 || *minImprovement < 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	examples, err := readExamples(flag.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	opts := analyzer.DatasetOptions{Format: *format, MinImprovement: *minImprovement}
+	if *llm {
+		rewriter, err := analyzer.NewLLMRewriter(analyzer.LLMRewriterConfigFromEnv())
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		opts.Rewriter = rewriter
+	}
+
+	var out io.Writer = os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		out = f
+	}
+	w := bufio.NewWriter(out)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	ctx, cancel := context.WithTimeout(ctx, *timeout)
+	defer cancel()
+
+	summary, err := analyzer.ExportDataset(ctx, w, examples, opts)
+	if flushErr := w.Flush(); err == nil {
+		err = flushErr
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	fmt.Fprintf(os.Stderr, "wrote %d examples, skipped %d\n", summary.Written, summary.Skipped)
+}
+
+// readExamples parses one DatasetExample per non-blank line of path, or of
+// stdin when path is "-"
+func readExamples(path string) ([]analyzer.DatasetExample, error) {
+	var in io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		in = f
+	}
+
+	var examples []analyzer.DatasetExample
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var ex analyzer.DatasetExample
+		if err := json.Unmarshal(scanner.Bytes(), &ex); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		if ex.Prompt == "" {
+			return nil, fmt.Errorf("%s:%d: missing prompt", path, line)
+		}
+		if ex.ID == "" {
+			ex.ID = fmt.Sprintf("%d", line)
+		}
+		examples = append(examples, ex)
+	}
+	return examples, scanner.Err()
+}
@@ -0,0 +1,61 @@
+//go:build !js
+
+// Command fulcrum-lsp is a language server for prompt files. It speaks LSP
+// over stdin and stdout, publishing suggestions, grammar and spelling issues
+// and injection risks as diagnostics, with quick fixes for misspellings and
+// a code action that applies the rule-based rewrite.
+//
+//	fulcrum-lsp
+//	fulcrum-lsp -ext .prompt,.md,.txt
+//	fulcrum-lsp -glossary terms.json
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+
+	"fulcrum-wasm/internal/analyzer"
+)
+
+func main() {
+	extensions := flag.String("ext", strings.Join(analyzer.DefaultLSPExtensions, ","), "comma-separated file extensions to analyze")
+	glossaryPath := flag.String("glossary", "", "JSON array of {term, definition, aliases} domain terms")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [flags]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	opts := analyzer.AnalysisOptions{}
+	if *glossaryPath != "" {
+		data, err := os.ReadFile(*glossaryPath)
+		if err == nil {
+			opts.Glossary, err = analyzer.LoadGlossary(data)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+	exts := []string{}
+	for _, ext := range strings.Split(*extensions, ",") {
+		if ext = strings.TrimSpace(ext); ext != "" {
+			exts = append(exts, strings.ToLower(ext))
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if err := analyzer.NewLSPServer(opts, exts...).Serve(ctx, os.Stdin, os.Stdout); err != nil && err != context.Canceled {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
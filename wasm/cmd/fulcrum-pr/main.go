@@ -0,0 +1,136 @@
+//go:build !js
+
+// Command fulcrum-pr lints the prompt files a pull request changes. It finds
+// changed files matching the prompt globs, grades them and prints a markdown
+// summary ready to post as a PR comment. It exits 1 when any prompt scores
+// below -min-score.
+//
+//	fulcrum-pr -base origin/main
+//	git diff origin/main | fulcrum-pr -diff -
+//	fulcrum-pr -repo ../prompts -glob '**/*.md' -min-score 60 -o comment.md
+//	fulcrum-pr -all
+//	fulcrum-pr -base origin/main -rubric team-rubric.yaml
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"time"
+
+	"fulcrum-wasm/internal/analyzer"
+)
+
+func main() {
+	repo := flag.String("repo", ".", "repository root the diff's paths are relative to")
+	diffPath := flag.String("diff", "", "unified diff file, or - for stdin")
+	base := flag.String("base", "", "diff the working tree against this git ref instead of reading -diff")
+	all := flag.Bool("all", false, "grade every prompt file in the repository")
+	globs := flag.String("glob", strings.Join(analyzer.DefaultPromptGlobs, ","), "comma-separated prompt file globs; ** matches any directories")
+	minScore := flag.Float64("min-score", 0, "fail prompts scoring below this (0-100)")
+	output := flag.String("o", "", "write the comment to this file instead of stdout")
+	timeout := flag.Duration("timeout", 5*time.Minute, "stop after this long")
+	rubricPath := flag.String("rubric", "", "JSON or YAML grading rubric; grades with the modern engine")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [flags] (-base <ref> | -diff <file|-> | -all)\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	sources := 0
+	for _, set := range []bool{*diffPath != "", *base != "", *all} {
+		if set {
+			sources++
+		}
+	}
+	if flag.NArg() != 0 || sources != 1 || *minScore < 0 || *minScore > 100 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var patterns []string
+	for _, g := range strings.Split(*globs, ",") {
+		if g = strings.TrimSpace(g); g != "" {
+			patterns = append(patterns, g)
+		}
+	}
+	var docs []analyzer.CorpusDocument
+	var err error
+	if *all {
+		docs, err = analyzer.LoadPromptFiles(*repo, patterns)
+	} else {
+		var diff string
+		if diff, err = readDiff(*repo, *diffPath, *base); err == nil {
+			docs, err = analyzer.LoadChangedPrompts(*repo, diff, patterns)
+		}
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	opts := analyzer.AnalysisOptions{}
+	if *rubricPath != "" {
+		data, err := os.ReadFile(*rubricPath)
+		var rubric analyzer.RubricConfig
+		if err == nil {
+			rubric, err = analyzer.LoadRubricConfig(data)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		opts.Rubric = &rubric
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	ctx, cancel := context.WithTimeout(ctx, *timeout)
+	defer cancel()
+
+	review, err := analyzer.ReviewPrompts(ctx, docs, opts, *minScore)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	out := os.Stdout
+	if *output != "" {
+		if out, err = os.Create(*output); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+	if _, err := io.WriteString(out, analyzer.RenderPRComment(review)); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if err := out.Close(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if !review.Passed {
+		os.Exit(1)
+	}
+}
+
+// readDiff reads the diff from git, stdin or a file
+func readDiff(repo, path, base string) (string, error) {
+	switch {
+	case base != "":
+		out, err := exec.Command("git", "-C", repo, "diff", base, "--").Output()
+		if err != nil {
+			return "", fmt.Errorf("git diff %s: %w", base, err)
+		}
+		return string(out), nil
+	case path == "-":
+		b, err := io.ReadAll(os.Stdin)
+		return string(b), err
+	}
+	b, err := os.ReadFile(path)
+	return string(b), err
+}
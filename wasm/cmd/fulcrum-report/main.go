@@ -0,0 +1,123 @@
+//go:build !js
+
+// Command fulcrum-report analyzes one prompt and renders a self-contained
+// markdown, HTML or PDF report of its grade, suggestions and task graph.
+//
+//	fulcrum-report prompt.md > report.md
+//	fulcrum-report -format html -o report.html prompt.md
+//	fulcrum-report -format pdf -o report.pdf prompt.md
+//	fulcrum-report -glossary terms.json prompt.md
+//	fulcrum-report -classifier model.json prompt.md
+//	fulcrum-report -rubric team-rubric.yaml prompt.md
+//	cat prompt.txt | fulcrum-report -title "Onboarding prompt" -
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"time"
+
+	"fulcrum-wasm/internal/analyzer"
+)
+
+func main() {
+	format := flag.String("format", analyzer.ReportMarkdown, "report format: markdown, html or pdf")
+	title := flag.String("title", "", "report heading (defaults to the file name)")
+	output := flag.String("o", "", "write the report to this file instead of stdout")
+	timeout := flag.Duration("timeout", time.Minute, "stop after this long")
+	glossaryPath := flag.String("glossary", "", "JSON array of {term, definition, aliases} domain terms")
+	classifierPath := flag.String("classifier", "", "JSON prompt classifier model trained from labeled examples")
+	rubricPath := flag.String("rubric", "", "JSON or YAML grading rubric; grades with the modern engine")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [flags] <prompt-file|->\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if _, ok := analyzer.ReportContentTypes[*format]; flag.NArg() != 1 || !ok {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	text, err := readPrompt(flag.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	opts := analyzer.AnalysisOptions{}
+	if *glossaryPath != "" {
+		data, err := os.ReadFile(*glossaryPath)
+		if err == nil {
+			opts.Glossary, err = analyzer.LoadGlossary(data)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+	if *classifierPath != "" {
+		data, err := os.ReadFile(*classifierPath)
+		if err == nil {
+			opts.Classifier, err = analyzer.LoadClassifierModel(data)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+	if *rubricPath != "" {
+		data, err := os.ReadFile(*rubricPath)
+		var rubric analyzer.RubricConfig
+		if err == nil {
+			rubric, err = analyzer.LoadRubricConfig(data)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		opts.Rubric = &rubric
+	}
+	if *title == "" && flag.Arg(0) != "-" {
+		*title = "Prompt quality report: " + filepath.Base(flag.Arg(0))
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	ctx, cancel := context.WithTimeout(ctx, *timeout)
+	defer cancel()
+
+	result, err := analyzer.Analyze(ctx, text, opts, analyzer.AnalysisRun{})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	out := os.Stdout
+	if *output != "" {
+		if out, err = os.Create(*output); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+	if err := analyzer.RenderReport(out, result, *format, *title); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if err := out.Close(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func readPrompt(path string) (string, error) {
+	if path == "-" {
+		b, err := io.ReadAll(os.Stdin)
+		return string(b), err
+	}
+	b, err := os.ReadFile(path)
+	return string(b), err
+}
@@ -0,0 +1,252 @@
+//go:build !js
+
+// Command fulcrum-server serves the analyzer over HTTP: /analyze, /batch,
+// the reports, reviews and experiments built on it, the API description at
+// /openapi.json and /schema, Prometheus metrics at /metrics, liveness and
+// readiness checks at /health and /health/ready and, when admin.token is set,
+// runtime tuning at /admin/config.
+// Analyses are kept in memory for /history and search until the process
+// exits. It stops gracefully on SIGINT or SIGTERM.
+// Settings come from the YAML or JSON file named by -config or FULCRUM_CONFIG
+// (see config.example.yaml), overridden by FULCRUM_* variables; bad values
+// stop it at startup.
+//
+//	fulcrum-server
+//	fulcrum-server -config fulcrum.yaml
+//	FULCRUM_SERVER_ADDR=:9090 fulcrum-server
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"runtime"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"fulcrum-wasm/internal/analyzer"
+	"fulcrum-wasm/internal/config"
+)
+
+func main() {
+	cfg, err := config.FromFlags(flag.CommandLine, os.Args[1:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	srv, err := newServer(cfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	fmt.Fprintf(os.Stderr, "fulcrum-server listening on %s\n", cfg.Server.Addr)
+	if err := srv.listenAndServe(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// historyLimit is how many analyses the in-memory store keeps
+const historyLimit = 1000
+
+// server holds what the routes share
+type server struct {
+	settings config.Config
+	cfg      analyzer.ServerConfig
+	store    *memoryStore
+	metrics  *analyzer.StageMetrics
+	health   analyzer.HealthConfig
+	queue    *analyzer.WorkerPool
+	exps     *analyzer.Experiments
+}
+
+// newServer applies the loaded configuration: the LLM client, webhooks,
+// analysis defaults and memory budget, with CORS applied per request
+func newServer(cfg config.Config) (*server, error) {
+	if cfg.Storage.DSN != "" {
+		return nil, fmt.Errorf("storage.dsn: fulcrum-server keeps analyses in memory and has no driver for %q", cfg.Storage.DSN)
+	}
+	s := &server{
+		settings: cfg,
+		cfg:      cfg.ServerConfig(),
+		store:    newMemoryStore(historyLimit),
+		metrics:  analyzer.NewStageMetrics(),
+		health:   analyzer.HealthConfig{Started: time.Now(), Checks: map[string]analyzer.HealthCheckFunc{}},
+		queue:    analyzer.NewWorkerPool(runtime.NumCPU()),
+		exps:     analyzer.NewExperiments(),
+	}
+	if cfg.LLM.Provider != "" {
+		model, err := analyzer.NewLLMRewriter(cfg.LLMConfig())
+		if err != nil {
+			return nil, fmt.Errorf("llm: %w", err)
+		}
+		s.cfg.Model = model
+	}
+	if len(cfg.Webhooks.Hooks) > 0 {
+		webhooks, err := analyzer.NewWebhookNotifier(cfg.WebhookConfig())
+		if err != nil {
+			return nil, fmt.Errorf("webhooks: %w", err)
+		}
+		s.cfg.Webhooks = webhooks
+	}
+	s.cfg.Defaults = cfg.AnalysisOptions()
+	s.health.Checks["workers"] = s.workersReady
+	_, err := analyzer.UpdateRuntimeConfig(func(c *analyzer.RuntimeConfig) error {
+		c.MemoryBudgetBytes = cfg.Analysis.MemoryBudgetBytes
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("analysis.memory_budget_bytes: %w", err)
+	}
+	return s, nil
+}
+
+// handler builds the mux that serves every route
+func (s *server) handler() http.Handler {
+	analysis := analyzer.RecordedAnalysis(s.metrics, s.store.record)
+	experiments := analyzer.ExperimentsHandler(s.cfg, s.exps)
+
+	mux := http.NewServeMux()
+	mux.Handle("/analyze", analyzer.AnalyzeHandler(s.cfg, analyzer.QueuedAnalysis(s.queue, analyzer.PriorityInteractive, analysis)))
+	mux.Handle("/batch", analyzer.BatchHandler(s.cfg, s.queue, analysis))
+	mux.Handle("/report", analyzer.ReportHandler(s.cfg))
+	mux.Handle("/compare", analyzer.CompareHandler(s.cfg))
+	mux.Handle("/wordcloud", analyzer.WordCloudHandler(s.cfg))
+	mux.Handle("/corpus", analyzer.CorpusHandler(s.cfg))
+	mux.Handle("/pr-review", analyzer.PRReviewHandler(s.cfg))
+	mux.Handle("/evaluate", analyzer.EvaluateHandler(s.cfg))
+	mux.Handle("/what-if", analyzer.WhatIfHandler(s.cfg))
+	mux.Handle("/classifier/train", analyzer.TrainClassifierHandler(s.cfg))
+	mux.Handle("/experiments", experiments)
+	mux.Handle("/experiments/", experiments)
+	mux.Handle("/history", analyzer.HistoryHandler(s.store.list))
+	mux.Handle("/analyses/", analyzer.AnalysisSearchHandler(s.store.load))
+	mux.Handle("/openapi.json", analyzer.OpenAPIHandler(analyzer.CombinedResult{}))
+	mux.Handle("/schema", analyzer.SchemaHandler(analyzer.CombinedResult{}))
+	mux.Handle("/metrics", analyzer.MetricsHandler(s.metrics))
+	mux.Handle("/health", analyzer.LivenessHandler(s.health))
+	mux.Handle("/health/ready", analyzer.ReadinessHandler(s.health))
+	mux.Handle("/admin/config", analyzer.AdminConfigHandler(s.settings.Admin.Token))
+	return s.cors(mux)
+}
+
+// workersReady is the readiness check that the shared worker pool still
+// starts interactive jobs
+func (s *server) workersReady(ctx context.Context) error {
+	done := make(chan struct{})
+	if err := s.queue.SubmitContext(ctx, analyzer.PriorityInteractive, func() { close(done) }); err != nil {
+		return err
+	}
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("no worker started a job within the check timeout")
+	}
+}
+
+// cors lets browsers on the configured origins call the API and answers
+// their preflight requests
+func (s *server) cors(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" || !s.settings.AllowsOrigin(origin) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Add("Vary", "Origin")
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			w.Header().Set("Access-Control-Allow-Methods", "GET, HEAD, POST, PATCH, DELETE")
+			w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type, Accept-Encoding")
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// listenAndServe serves the routes until SIGINT or SIGTERM
+func (s *server) listenAndServe() error {
+	return analyzer.ListenAndServe(s.cfg, s.handler())
+}
+
+// previewRunes is how much of the prompt a history entry shows
+const previewRunes = 120
+
+// memoryStore keeps the latest analyses for /history and
+// /analyses/{id}/search, dropping the oldest past its limit
+type memoryStore struct {
+	mu      sync.Mutex
+	limit   int
+	entries []analyzer.HistoryEntry // Newest first
+	results map[string]*analyzer.CombinedResult
+}
+
+func newMemoryStore(limit int) *memoryStore {
+	return &memoryStore{limit: limit, results: map[string]*analyzer.CombinedResult{}}
+}
+
+// record is the analyzer.AnalysisRecorder that keeps each analysis
+func (s *memoryStore) record(_ context.Context, req analyzer.AnalyzeRequest, result *analyzer.CombinedResult) {
+	id := result.Performance.RequestID
+	if id == "" {
+		return
+	}
+	entry := analyzer.HistoryEntry{
+		ID:          id,
+		CreatedAt:   time.Now().UTC(),
+		TextPreview: preview(req.Text),
+		Score:       result.Grades.Score,
+		Grade:       result.Grades.Grade,
+		Tags:        req.Tags.Normalize(),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, seen := s.results[id]; seen {
+		// Deterministic requests reuse the ID of identical text; keep the latest
+		for i, e := range s.entries {
+			if e.ID == id {
+				s.entries = append(s.entries[:i], s.entries[i+1:]...)
+				break
+			}
+		}
+	}
+	s.entries = append([]analyzer.HistoryEntry{entry}, s.entries...)
+	s.results[id] = result
+	for len(s.entries) > s.limit {
+		delete(s.results, s.entries[len(s.entries)-1].ID)
+		s.entries = s.entries[:len(s.entries)-1]
+	}
+}
+
+// list is the analyzer.HistoryLister over the kept analyses
+func (s *memoryStore) list(_ context.Context, q analyzer.HistoryQuery) (analyzer.HistoryResponse, error) {
+	s.mu.Lock()
+	entries := append([]analyzer.HistoryEntry(nil), s.entries...)
+	s.mu.Unlock()
+	return analyzer.PageHistory(entries, q)
+}
+
+// load is the analyzer.AnalysisLoader over the kept analyses
+func (s *memoryStore) load(_ context.Context, id string) (*analyzer.CombinedResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result, ok := s.results[id]
+	if !ok {
+		return nil, analyzer.ErrAnalysisNotFound
+	}
+	return result, nil
+}
+
+// preview cuts text to previewRunes runes
+func preview(text string) string {
+	if utf8.RuneCountInString(text) <= previewRunes {
+		return text
+	}
+	return string([]rune(text)[:previewRunes]) + "…"
+}
@@ -0,0 +1,298 @@
+//go:build !js
+
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"fulcrum-wasm/internal/analyzer"
+	"fulcrum-wasm/internal/config"
+)
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv, err := newServer(config.Default())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(srv.handler())
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+// TestRoutesMounted checks that every path in the OpenAPI description, and
+// the routes it leaves out, reaches a handler rather than the mux's 404
+func TestRoutesMounted(t *testing.T) {
+	cfg := config.Default()
+	cfg.Admin.Token = "s3cret"
+	srv, err := newServer(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(srv.handler())
+	defer ts.Close()
+
+	routes := []string{"/openapi.json", "/corpus", "/classifier/train"}
+	for path := range analyzer.OpenAPISpec(analyzer.CombinedResult{})["paths"].(map[string]interface{}) {
+		routes = append(routes, strings.ReplaceAll(path, "{id}", "missing"))
+	}
+	for _, path := range routes {
+		resp, err := http.Get(ts.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		// Handlers answer unknown IDs with a JSON error; the mux with plain text
+		if resp.StatusCode == http.StatusNotFound && resp.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("GET %s: route not mounted", path)
+		}
+	}
+}
+
+// TestSchemaRoute checks that /schema serves the versioned result schema
+func TestSchemaRoute(t *testing.T) {
+	ts := newTestServer(t)
+	resp, err := http.Get(ts.URL + "/schema")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var schema map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&schema); err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK || schema["version"] != analyzer.ResultSchemaVersion {
+		t.Errorf("/schema = %d, version %v", resp.StatusCode, schema["version"])
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "application/schema+json" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+}
+
+// TestAnalysisHistory checks that an analysis is listed in /history and
+// searchable under its request ID
+func TestAnalysisHistory(t *testing.T) {
+	ts := newTestServer(t)
+	body := `{"text": "Write unit tests for the parser.", "options": {"deterministic": true}, "tags": {"project": "parser"}}`
+	resp, err := http.Post(ts.URL+"/analyze", "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var result struct {
+		Performance struct {
+			RequestID string `json:"request_id"`
+		} `json:"performance_metrics"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	resp.Body.Close()
+	if err != nil || resp.StatusCode != http.StatusOK || result.Performance.RequestID == "" {
+		t.Fatalf("/analyze = %d, %v", resp.StatusCode, err)
+	}
+	id := result.Performance.RequestID
+
+	resp, err = http.Get(ts.URL + "/history?project=parser")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var history analyzer.HistoryResponse
+	err = json.NewDecoder(resp.Body).Decode(&history)
+	resp.Body.Close()
+	if err != nil || len(history.Entries) != 1 || history.Entries[0].ID != id {
+		t.Fatalf("/history = %+v, %v", history, err)
+	}
+
+	resp, err = http.Get(ts.URL + "/analyses/" + id + "/search?q=parser")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("search %s = %d", id, resp.StatusCode)
+	}
+
+	resp, err = http.Get(ts.URL + "/analyses/unknown/search?q=parser")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("unknown analysis = %d, want 404", resp.StatusCode)
+	}
+}
+
+// TestMetricsRoute checks that /metrics counts the analyses served
+func TestMetricsRoute(t *testing.T) {
+	ts := newTestServer(t)
+	resp, err := http.Post(ts.URL+"/analyze", "application/json", strings.NewReader(`{"text": "List three risks of the launch plan."}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	resp, err = http.Get(ts.URL + "/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	for _, want := range []string{"fulcrum_analysis_requests_total 1\n", `fulcrum_analysis_stage_duration_seconds_count{stage="total"} 1`, "fulcrum_worker_queue_depth"} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("/metrics is missing %q:\n%s", want, body)
+		}
+	}
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/plain") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+}
+
+// TestHealthRoute checks that /health reports the process without running
+// readiness checks
+func TestHealthRoute(t *testing.T) {
+	ts := newTestServer(t)
+	resp, err := http.Get(ts.URL + "/health")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var health analyzer.HealthResponse
+	if err := json.NewDecoder(resp.Body).Decode(&health); err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK || health.Status != "ok" || health.SchemaVersion != analyzer.ResultSchemaVersion {
+		t.Errorf("/health = %d %+v", resp.StatusCode, health)
+	}
+	if health.UptimeSeconds <= 0 || health.Checks != nil {
+		t.Errorf("/health uptime %v, checks %v", health.UptimeSeconds, health.Checks)
+	}
+}
+
+// TestReadinessRoute checks that /health/ready runs the worker pool check
+func TestReadinessRoute(t *testing.T) {
+	ts := newTestServer(t)
+	resp, err := http.Get(ts.URL + "/health/ready")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var health analyzer.HealthResponse
+	if err := json.NewDecoder(resp.Body).Decode(&health); err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK || len(health.Checks) != 1 || health.Checks[0].Name != "workers" || !health.Checks[0].OK {
+		t.Errorf("/health/ready = %d %+v", resp.StatusCode, health.Checks)
+	}
+}
+
+// TestAdminConfigRoute checks that /admin/config needs the configured token
+// and is disabled without one
+func TestAdminConfigRoute(t *testing.T) {
+	before := analyzer.CurrentRuntimeConfig()
+	t.Cleanup(func() {
+		analyzer.UpdateRuntimeConfig(func(c *analyzer.RuntimeConfig) error {
+			*c = before
+			return nil
+		})
+	})
+
+	cfg := config.Default()
+	cfg.Admin.Token = "s3cret"
+	srv, err := newServer(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(srv.handler())
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/admin/config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("no token: expected 401, got %d", resp.StatusCode)
+	}
+
+	req, _ := http.NewRequest(http.MethodPatch, ts.URL+"/admin/config", strings.NewReader(`{"max_task_sentences": 321}`))
+	req.Header.Set("Authorization", "Bearer s3cret")
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var limits analyzer.RuntimeConfig
+	json.NewDecoder(resp.Body).Decode(&limits)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || limits.MaxTaskSentences != 321 || analyzer.CurrentRuntimeConfig().MaxTaskSentences != 321 {
+		t.Errorf("PATCH = %d %+v", resp.StatusCode, limits)
+	}
+
+	resp, err = http.Get(newTestServer(t).URL + "/admin/config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("without admin.token: expected 404, got %d", resp.StatusCode)
+	}
+}
+
+// TestConfigApplied checks that the analysis defaults and CORS origins from
+// the configuration reach requests, including ones that set other options,
+// and that an unsupported store is refused
+func TestConfigApplied(t *testing.T) {
+	cfg := config.Default()
+	cfg.Analysis.Deterministic = true
+	cfg.CORS.AllowedOrigins = []string{"https://app.example"}
+	srv, err := newServer(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(srv.handler())
+	defer ts.Close()
+
+	var ids []string
+	for i := 0; i < 2; i++ {
+		req, _ := http.NewRequest(http.MethodPost, ts.URL+"/analyze", strings.NewReader(`{"text": "Summarize the incident report.", "options": {"explain": true}}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Origin", "https://app.example")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var result struct {
+			Performance struct {
+				RequestID string `json:"request_id"`
+			} `json:"performance_metrics"`
+		}
+		json.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if got := resp.Header.Get("Access-Control-Allow-Origin"); got != "https://app.example" {
+			t.Errorf("Access-Control-Allow-Origin = %q", got)
+		}
+		ids = append(ids, result.Performance.RequestID)
+	}
+	if ids[0] == "" || ids[0] != ids[1] {
+		t.Errorf("deterministic default not applied: request IDs %v", ids)
+	}
+
+	req, _ := http.NewRequest(http.MethodOptions, ts.URL+"/analyze", nil)
+	req.Header.Set("Origin", "https://other.example")
+	req.Header.Set("Access-Control-Request-Method", "POST")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.Header.Get("Access-Control-Allow-Origin") != "" {
+		t.Error("preflight from an unlisted origin was allowed")
+	}
+
+	cfg.Storage.DSN = "postgres://localhost/fulcrum"
+	if _, err := newServer(cfg); err == nil {
+		t.Error("storage DSN: expected an error")
+	}
+}
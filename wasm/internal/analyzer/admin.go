@@ -0,0 +1,60 @@
+//go:build !js
+
+package analyzer
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// maxAdminBodyBytes caps PATCH /admin/config bodies, which are a few fields
+const maxAdminBodyBytes = 64 << 10
+
+// AdminConfigHandler serves the runtime limits at /admin/config: GET reports
+// them and PATCH merges a partial RuntimeConfig into them. Requests need an
+// Authorization: Bearer header matching token; with no token configured the
+// endpoint answers 404, so it cannot be left open by accident.
+func AdminConfigHandler(token string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token == "" {
+			writeAPIError(w, http.StatusNotFound, "admin endpoint disabled")
+			return
+		}
+		given, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="fulcrum-admin"`)
+			writeAPIError(w, http.StatusUnauthorized, "missing or invalid admin token")
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet, http.MethodHead:
+		case http.MethodPatch:
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxAdminBodyBytes))
+			if err != nil {
+				var tooLarge *http.MaxBytesError
+				if errors.As(err, &tooLarge) {
+					writeAPIError(w, http.StatusRequestEntityTooLarge, "request body exceeds "+formatBytes(maxAdminBodyBytes))
+					return
+				}
+				writeAPIError(w, http.StatusBadRequest, "failed to read request body")
+				return
+			}
+			if _, err := PatchRuntimeConfig(body); err != nil {
+				writeAPIError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+		default:
+			w.Header().Set("Allow", "GET, HEAD, PATCH")
+			writeAPIError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		json.NewEncoder(w).Encode(CurrentRuntimeConfig())
+	})
+}
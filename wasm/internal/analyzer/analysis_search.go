@@ -0,0 +1,151 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Kinds of result item SearchAnalysis looks through
+const (
+	SearchKindCluster    = "cluster"
+	SearchKindTask       = "task"
+	SearchKindSuggestion = "suggestion"
+)
+
+// maxSearchQuery bounds the query length a search accepts
+const maxSearchQuery = 200
+
+// searchSnippetContext is how many bytes of context a snippet keeps on each
+// side of the match
+const searchSnippetContext = 40
+
+// ErrAnalysisNotFound is returned by an AnalysisLoader for an unknown ID
+var ErrAnalysisNotFound = errors.New("analysis not found")
+
+// AnalysisLoader fetches a stored analysis by ID, returning
+// ErrAnalysisNotFound when there is none
+type AnalysisLoader func(ctx context.Context, id string) (*CombinedResult, error)
+
+// SearchMatch is one cluster, task or suggestion that mentions the query
+type SearchMatch struct {
+	Kind    string   `json:"kind"`    // cluster, task or suggestion
+	ID      string   `json:"id"`      // Cluster ID, task ID or suggestion rule
+	Index   int      `json:"index"`   // Position in its list in the result
+	Title   string   `json:"title"`   // Cluster topic, task title or suggestion message
+	Fields  []string `json:"fields"`  // JSON names of the fields that mention the query
+	Snippet string   `json:"snippet"` // The first mention with some context
+}
+
+// SearchResponse lists the matches for a query, clusters first, then tasks,
+// then suggestions, each in result order
+type SearchResponse struct {
+	Query   string         `json:"query"`
+	Matches []SearchMatch  `json:"matches"`
+	Counts  map[string]int `json:"counts"` // Matches per kind
+}
+
+// searchField is one named text of a result item
+type searchField struct {
+	name  string
+	texts []string
+}
+
+// SearchAnalysis finds the clusters, tasks and suggestions of result that
+// mention query, ignoring case
+func SearchAnalysis(result *CombinedResult, query string) SearchResponse {
+	query = strings.TrimSpace(query)
+	resp := SearchResponse{
+		Query:   query,
+		Matches: []SearchMatch{},
+		Counts:  map[string]int{SearchKindCluster: 0, SearchKindTask: 0, SearchKindSuggestion: 0},
+	}
+	if query == "" {
+		return resp
+	}
+	needle := strings.ToLower(query)
+	add := func(kind, id string, index int, title string, fields []searchField) {
+		match := SearchMatch{Kind: kind, ID: id, Index: index, Title: title}
+		for _, f := range fields {
+			for _, text := range f.texts {
+				at := strings.Index(strings.ToLower(text), needle)
+				if at < 0 {
+					continue
+				}
+				if match.Snippet == "" {
+					match.Snippet = searchSnippet(text, at, len(needle))
+				}
+				match.Fields = append(match.Fields, f.name)
+				break
+			}
+		}
+		if len(match.Fields) > 0 {
+			resp.Matches = append(resp.Matches, match)
+			resp.Counts[kind]++
+		}
+	}
+
+	for i, c := range result.Ideas.SemanticClusters.Value {
+		add(SearchKindCluster, strconv.Itoa(c.ID), i, c.MainTopic, []searchField{
+			{"main_topic", []string{c.MainTopic}},
+			{"key_phrases", c.KeyPhrases},
+			{"key_words", c.KeyWords},
+			{"description", []string{c.Description}},
+			{"sentences", c.Sentences},
+		})
+	}
+	for i, t := range result.TaskGraph.Tasks {
+		add(SearchKindTask, t.ID, i, t.Title, []searchField{
+			{"title", []string{t.Title}},
+			{"description", []string{t.Description}},
+			{"keywords", t.Keywords},
+			{"source_text", []string{t.SourceText}},
+		})
+	}
+	for i, s := range gradeSuggestions(result) {
+		add(SearchKindSuggestion, s.Rule, i, s.Message, []searchField{
+			{"message", []string{s.Message}},
+			{"dimension", []string{s.Dimension}},
+			{"impact", []string{s.Impact}},
+			{"example", []string{s.Example}},
+		})
+	}
+	return resp
+}
+
+// searchSnippet cuts text to the match at [at, at+n) with some context on
+// each side, marking cut ends with an ellipsis. Lowercasing can change byte
+// lengths, so offsets are clamped and moved to rune boundaries.
+func searchSnippet(text string, at, n int) string {
+	at = min(at, len(text))
+	start := max(0, at-searchSnippetContext)
+	end := min(len(text), at+n+searchSnippetContext)
+	for start > 0 && !utf8.RuneStart(text[start]) {
+		start--
+	}
+	for end < len(text) && !utf8.RuneStart(text[end]) {
+		end++
+	}
+	snippet := strings.TrimSpace(text[start:end])
+	if start > 0 {
+		snippet = "…" + snippet
+	}
+	if end < len(text) {
+		snippet += "…"
+	}
+	return snippet
+}
+
+// validateSearchQuery rejects empty and overlong queries
+func validateSearchQuery(query string) error {
+	switch query = strings.TrimSpace(query); {
+	case query == "":
+		return errors.New("q is required")
+	case len(query) > maxSearchQuery:
+		return fmt.Errorf("q must be at most %d bytes", maxSearchQuery)
+	}
+	return nil
+}
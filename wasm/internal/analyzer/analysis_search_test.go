@@ -0,0 +1,47 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSearchAnalysis(t *testing.T) {
+	result := &CombinedResult{}
+	result.Ideas.SemanticClusters.Value = []IdeaCluster{
+		{ID: 1, MainTopic: "Billing Database", Sentences: []string{"Migrate the billing database tonight."}},
+		{ID: 2, MainTopic: "Release Notes", Sentences: []string{"Write the release notes."}},
+	}
+	result.TaskGraph.Tasks = []Task{{ID: "task_1", Title: "Migrate database", SourceText: "Migrate the billing database tonight."}}
+	result.PromptGrade.Suggestions = []Suggestion{{Rule: "FUL001", Message: "Name the target database version."}}
+
+	resp := SearchAnalysis(result, "  DATABASE ")
+	if resp.Query != "DATABASE" || len(resp.Matches) != 3 {
+		t.Fatalf("matches = %+v", resp.Matches)
+	}
+	if resp.Counts[SearchKindCluster] != 1 || resp.Counts[SearchKindTask] != 1 || resp.Counts[SearchKindSuggestion] != 1 {
+		t.Errorf("counts = %v", resp.Counts)
+	}
+	cluster := resp.Matches[0]
+	if cluster.Kind != SearchKindCluster || cluster.ID != "1" || strings.Join(cluster.Fields, ",") != "main_topic,sentences" {
+		t.Errorf("cluster match = %+v", cluster)
+	}
+	if task := resp.Matches[1]; task.ID != "task_1" || strings.Join(task.Fields, ",") != "title,source_text" {
+		t.Errorf("task match = %+v", task)
+	}
+
+	if got := SearchAnalysis(result, "nothing"); len(got.Matches) != 0 {
+		t.Errorf("unexpected matches: %+v", got.Matches)
+	}
+}
+
+func TestSearchSnippet(t *testing.T) {
+	text := strings.Repeat("é", 40) + " the billing database " + strings.Repeat("x", 60)
+	at := strings.Index(text, "database")
+	snippet := searchSnippet(text, at, len("database"))
+	if !strings.HasPrefix(snippet, "…") || !strings.HasSuffix(snippet, "…") || !strings.Contains(snippet, "billing database") {
+		t.Errorf("snippet = %q", snippet)
+	}
+	if !strings.HasPrefix(strings.TrimPrefix(snippet, "…"), "é") {
+		t.Errorf("snippet split a rune: %q", snippet)
+	}
+}
@@ -0,0 +1,250 @@
+package analyzer
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Span is a byte range [Start, End) of the analyzed text
+type Span struct {
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+	Text  string `json:"text"`
+}
+
+// Annotation sources
+const (
+	AnnotationSuggestion  = "suggestion"
+	AnnotationFactor      = "factor"
+	AnnotationThoughtType = "thought_type"
+	AnnotationSpelling    = "spelling"
+	AnnotationGrammar     = "grammar"
+	AnnotationStyle       = "style"
+	AnnotationQuality     = "quality"
+	AnnotationPII         = "pii"
+	AnnotationInjection   = "injection"
+)
+
+// Annotation is a finding tied to one region of the text, so editors can underline it
+type Annotation struct {
+	Source   string `json:"source"`         // One of the Annotation* sources
+	Rule     string `json:"rule,omitempty"` // Suggestion rule ID or grammar rule
+	Severity string `json:"severity"`       // "info", "low", "medium", "high"
+	Message  string `json:"message"`
+	Span     Span   `json:"span"`
+}
+
+// weakFactorThreshold is the factor value below which a located factor is annotated
+const weakFactorThreshold = 60.0
+
+// newSpan builds a span for text[start:end]
+func newSpan(text string, start, end int) Span {
+	return Span{Start: start, End: end, Text: text[start:end]}
+}
+
+// patternSpans returns the span of every match of re in text
+func patternSpans(text string, re *regexp.Regexp) []Span {
+	spans := []Span{}
+	for _, m := range re.FindAllStringIndex(text, -1) {
+		spans = append(spans, newSpan(text, m[0], m[1]))
+	}
+	return spans
+}
+
+// wordSpans returns the span of every word in text whose lowercase form is in words
+func wordSpans(text string, words map[string]bool) []Span {
+	spans := []Span{}
+	for _, m := range alphaWordPattern.FindAllStringIndex(text, -1) {
+		if words[strings.ToLower(text[m[0]:m[1]])] {
+			spans = append(spans, newSpan(text, m[0], m[1]))
+		}
+	}
+	return spans
+}
+
+// fragmentSpans locates the first occurrence of each fragment in text, skipping any not found
+func fragmentSpans(text string, fragments []string) []Span {
+	spans := []Span{}
+	for _, f := range fragments {
+		if f == "" {
+			continue
+		}
+		if i := strings.Index(text, f); i >= 0 {
+			spans = append(spans, newSpan(text, i, i+len(f)))
+		}
+	}
+	return spans
+}
+
+// locateSentences returns the span of each extractSentences sentence, found
+// in text order so repeated sentences map to successive occurrences
+func locateSentences(text string) []Span {
+	spans := []Span{}
+	cursor := 0
+	for _, sentence := range extractSentences(text) {
+		i := strings.Index(text[cursor:], sentence)
+		if i < 0 {
+			continue
+		}
+		start := cursor + i
+		spans = append(spans, newSpan(text, start, start+len(sentence)))
+		cursor = start + len(sentence)
+	}
+	return spans
+}
+
+// attachSentenceSpans sets each cluster's SentenceSpans. Sentences come from
+// extractSentences, so each is located in text order; repeated sentences map
+// to successive occurrences.
+func attachSentenceSpans(text string, clusters []IdeaCluster) {
+	occurrences := make(map[string][]Span)
+	for _, span := range locateSentences(text) {
+		occurrences[span.Text] = append(occurrences[span.Text], span)
+	}
+
+	for c := range clusters {
+		spans := make([]Span, 0, len(clusters[c].Sentences))
+		for _, sentence := range clusters[c].Sentences {
+			queue := occurrences[sentence]
+			if len(queue) == 0 {
+				spans = append(spans, Span{Start: -1, End: -1, Text: sentence})
+				continue
+			}
+			spans = append(spans, queue[0])
+			occurrences[sentence] = queue[1:]
+		}
+		clusters[c].SentenceSpans = spans
+	}
+}
+
+// BuildAnnotations collects every located finding in a result into one list
+// ordered by position. Findings without a location are left out.
+func BuildAnnotations(text string, result *CombinedResult) []Annotation {
+	annotations := []Annotation{}
+	add := func(source, rule, severity, message string, span Span) {
+		if span.Start < 0 || span.End > len(text) || span.Start >= span.End {
+			return
+		}
+		annotations = append(annotations, Annotation{
+			Source: source, Rule: rule, Severity: severity, Message: message, Span: span,
+		})
+	}
+
+	grade := result.PromptGrade
+	for _, s := range grade.Suggestions {
+		for _, span := range s.Spans {
+			add(AnnotationSuggestion, s.Rule, s.Priority, s.Message, span)
+		}
+	}
+	for _, dim := range gradeDimensionsByName(&grade) {
+		for _, f := range dim.dimension.Factors {
+			if f.Value >= weakFactorThreshold {
+				continue
+			}
+			severity := "low"
+			if f.Value < weakFactorThreshold/2 {
+				severity = "medium"
+			}
+			msg := fmt.Sprintf("%s: weak %s (%.0f/100)", dim.name, strings.ToLower(f.Name), f.Value)
+			for _, span := range f.Spans {
+				add(AnnotationFactor, "", severity, msg, span)
+			}
+		}
+	}
+
+	for _, cluster := range result.Ideas.SemanticClusters.Value {
+		for i, st := range cluster.SentenceTypes {
+			if i >= len(cluster.SentenceSpans) {
+				break
+			}
+			msg := fmt.Sprintf("%s (%.0f%% confidence)", st.Type, st.Confidence*100)
+			add(AnnotationThoughtType, "", "info", msg, cluster.SentenceSpans[i])
+		}
+	}
+
+	quality := result.Preprocessing.QualityMetrics
+	for _, e := range quality.SpellingErrors.Value {
+		msg := fmt.Sprintf("Possible misspelling of %q", e.Word)
+		if len(e.Suggestions) > 0 {
+			msg = fmt.Sprintf("Possible misspelling; did you mean %q?", e.Suggestions[0])
+		}
+		if e.Position+len(e.Word) <= len(text) {
+			add(AnnotationSpelling, "", "low", msg, newSpan(text, e.Position, e.Position+len(e.Word)))
+		}
+	}
+	for _, g := range quality.GrammarIssues.Value {
+		if g.Position+g.Length <= len(text) {
+			add(AnnotationGrammar, g.Rule, "medium", g.Description, newSpan(text, g.Position, g.Position+g.Length))
+		}
+	}
+	for _, s := range quality.StyleSuggestions.Value {
+		if s.Position+s.Length <= len(text) {
+			add(AnnotationStyle, s.Kind, "low", s.Suggestion, newSpan(text, s.Position, s.Position+s.Length))
+		}
+	}
+	for _, q := range quality.QualityIssues.Value {
+		if q.Position >= 0 && q.Position+q.Length <= len(text) {
+			add(AnnotationQuality, q.Type, q.Severity, q.Description, newSpan(text, q.Position, q.Position+q.Length))
+		}
+	}
+
+	if contains(result.Stages, StagePreprocessing) {
+		for _, span := range patternSpans(text, emailPattern) {
+			add(AnnotationPII, "email", "medium", "Email address; remove or mask before sharing", span)
+		}
+		extraction := result.Preprocessing.ExtractionResults
+		for _, p := range extraction.ParsedPhones.Value {
+			if p.Valid {
+				add(AnnotationPII, "phone", "medium", "Phone number; remove or mask before sharing", p.Span)
+			}
+		}
+		for _, d := range extraction.IBANs.Value {
+			if d.Valid {
+				add(AnnotationPII, "iban", "high", "Bank account number (IBAN); remove or mask before sharing", d.Span)
+			}
+		}
+		for _, d := range extraction.CreditCards.Value {
+			if d.Valid {
+				add(AnnotationPII, "credit_card", "high", "Card number passing the Luhn check; remove before sharing", d.Span)
+			}
+		}
+		for _, u := range extraction.ParsedURLs.Value {
+			if u.Credentials != "" {
+				add(AnnotationPII, "credential_url", "high", fmt.Sprintf("URL carries a credential (%s); revoke it and remove it before sharing", u.Credentials), u.Span)
+			}
+		}
+	}
+
+	for _, a := range injectionAnnotations(text) {
+		add(a.Source, a.Rule, a.Severity, a.Message, a.Span)
+	}
+
+	sort.SliceStable(annotations, func(i, j int) bool {
+		if annotations[i].Span.Start != annotations[j].Span.Start {
+			return annotations[i].Span.Start < annotations[j].Span.Start
+		}
+		return annotations[i].Span.End < annotations[j].Span.End
+	})
+	return annotations
+}
+
+type namedDimension struct {
+	name      string
+	dimension GradeDimension
+}
+
+// gradeDimensionsByName pairs each grade dimension with the name used in weak areas
+func gradeDimensionsByName(grade *PromptGrade) []namedDimension {
+	return []namedDimension{
+		{"Understandability", grade.Understandability},
+		{"Specificity", grade.Specificity},
+		{"Task Complexity", grade.TaskComplexity},
+		{"Clarity", grade.Clarity},
+		{"Actionability", grade.Actionability},
+		{"Structure", grade.StructureQuality},
+		{"Context", grade.ContextSufficiency},
+		{"Scope", grade.ScopeManagement},
+	}
+}
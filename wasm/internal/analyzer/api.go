@@ -0,0 +1,143 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// HTTP API request and response bodies. OpenAPISpec describes them and the
+// internal/client package consumes them, so both stay in step with the server.
+
+// Response encodings for /analyze
+const (
+	ResponseJSON = "json" // The result as a plain JSON body
+	ResponseSSE  = "sse"  // A DataStar patch-signals event carrying {"result": ...}
+)
+
+// AnalyzeRequest is the body of POST /analyze and one item of POST /batch
+type AnalyzeRequest struct {
+	Text    string          `json:"text"`
+	Options AnalysisOptions `json:"options,omitempty"`
+	Tags    AnalysisTags    `json:"tags,omitempty"` // Stored with the analysis for history filtering
+}
+
+// BatchRequest analyzes several prompts in one call
+type BatchRequest struct {
+	Items []AnalyzeRequest `json:"items"`
+}
+
+// BatchResponse holds one entry per request item, in order
+type BatchResponse struct {
+	Results []BatchItem `json:"results"`
+}
+
+// BatchItem is the analysis result or error for one batch item
+type BatchItem struct {
+	Index  int             `json:"index"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  string          `json:"error,omitempty"`
+}
+
+// CompareRequest grades two versions of a prompt
+type CompareRequest struct {
+	Original string          `json:"original"`
+	Revised  string          `json:"revised"`
+	Options  AnalysisOptions `json:"options,omitempty"` // Grader, rubric, rules and classifier used for both grades
+}
+
+// CompareResponse shows both grades and the overall score change
+type CompareResponse struct {
+	OriginalGrade  *PromptGrade  `json:"original_grade"` // Nil unless the classic engine ran
+	RevisedGrade   *PromptGrade  `json:"revised_grade"`
+	OriginalGrades GradeEnvelope `json:"original_grades"` // Every engine's score, as in grades
+	RevisedGrades  GradeEnvelope `json:"revised_grades"`
+	ScoreDelta     float64       `json:"score_delta"` // Revised minus original overall score
+}
+
+// HistoryEntry summarizes a stored analysis
+type HistoryEntry struct {
+	ID          string       `json:"id"`
+	CreatedAt   time.Time    `json:"created_at"`
+	TextPreview string       `json:"text_preview"`
+	Score       float64      `json:"score"`
+	Grade       string       `json:"grade"`
+	Tags        AnalysisTags `json:"tags"`
+}
+
+// HistoryResponse is one page of GET /history
+type HistoryResponse struct {
+	Entries    []HistoryEntry    `json:"entries"`
+	NextCursor string            `json:"next_cursor,omitempty"`
+	Stats      []HistoryTagStats `json:"stats"` // Per project, author and label, over every entry the filter matches
+}
+
+// HealthResponse is returned by GET /health (liveness) and GET /health/ready
+// (readiness). Only readiness runs the dependency checks.
+type HealthResponse struct {
+	Status          string          `json:"status"` // "ok" or "degraded"
+	AnalyzerVersion string          `json:"analyzer_version"`
+	SchemaVersion   string          `json:"schema_version"`
+	Build           BuildInfo       `json:"build"`
+	UptimeSeconds   float64         `json:"uptime_seconds"`
+	Workers         WorkerPoolState `json:"workers"`
+	Cache           CacheStats      `json:"cache"`
+	Checks          []HealthCheck   `json:"checks,omitempty"` // Readiness only, e.g. storage
+}
+
+// BuildInfo identifies the binary serving the API
+type BuildInfo struct {
+	GitCommit string `json:"git_commit,omitempty"`
+	BuildTime string `json:"build_time,omitempty"` // Commit time, RFC 3339
+	Modified  bool   `json:"modified"`             // Built from a tree with uncommitted changes
+	GoVersion string `json:"go_version"`
+}
+
+// WorkerPoolState counts the analysis worker pools running in this process
+type WorkerPoolState struct {
+	Pools  int64 `json:"pools"`  // Analyses in flight, one pool each
+	Idle   int64 `json:"idle"`   // Workers waiting for a stage
+	Busy   int64 `json:"busy"`   // Workers running a stage
+	Queued int64 `json:"queued"` // Stages submitted but not started
+
+	Priorities map[string]JobStats `json:"priorities"` // Job counts and timing by priority
+}
+
+// CacheStats describes the shared compiled-pattern cache
+type CacheStats struct {
+	Entries int64 `json:"entries"`
+	Hits    int64 `json:"hits"`
+	Misses  int64 `json:"misses"`
+	Limit   int64 `json:"limit"` // 0 is unbounded
+}
+
+// HealthCheck is the outcome of one readiness check
+type HealthCheck struct {
+	Name      string  `json:"name"`
+	OK        bool    `json:"ok"`
+	Error     string  `json:"error,omitempty"`
+	LatencyMs float64 `json:"latency_ms"`
+}
+
+// APIError is the body of every non-2xx response
+type APIError struct {
+	Error string `json:"error"`
+}
+
+// ComparePrompts grades both prompts for POST /compare with the engines opts picks
+func ComparePrompts(original, revised string, opts AnalysisOptions) CompareResponse {
+	grade := func(text string) (*PromptGrade, GradeEnvelope) {
+		in := AnalyzeForGrading(text, NewPromptClassifierWithModel(opts.classifierModel()))
+		envelope, graded := GradeAll(GradersFor(opts), in)
+		for _, g := range graded {
+			if classic, ok := g.(*PromptGrade); ok {
+				return classic, envelope
+			}
+		}
+		return nil, envelope
+	}
+	resp := CompareResponse{}
+	resp.OriginalGrade, resp.OriginalGrades = grade(original)
+	resp.RevisedGrade, resp.RevisedGrades = grade(revised)
+	resp.ScoreDelta = resp.RevisedGrades.Score - resp.OriginalGrades.Score
+	return resp
+}
@@ -0,0 +1,75 @@
+package analyzer
+
+import "regexp"
+
+// hedgeWeights maps hedging words and phrases to how much doubt they add.
+// Stacked hedges ("could possibly") count once per word.
+var hedgeWeights = map[string]float64{
+	"might": 1, "may": 0.5, "could": 0.5, "possibly": 1, "perhaps": 1, "maybe": 1,
+	"probably": 0.5, "likely": 0.5, "unlikely": 0.5, "apparently": 0.5, "presumably": 0.5,
+	"seemingly": 0.5, "somewhat": 0.5, "arguably": 0.5, "roughly": 0.5, "generally": 0.5,
+	"i think": 1, "i believe": 1, "i guess": 1, "i suppose": 1, "i feel": 1,
+	"it seems": 1, "seems to": 1, "appears to": 0.5, "sort of": 1, "kind of": 1,
+	"not sure": 1, "in some cases": 0.5, "to some extent": 0.5, "if possible": 0.5,
+}
+
+// assertiveWeights maps assertive words and phrases to how much certainty they add
+var assertiveWeights = map[string]float64{
+	"definitely": 1, "certainly": 1, "absolutely": 1, "clearly": 1, "undoubtedly": 1,
+	"always": 0.5, "never": 0.5, "must": 1, "will": 0.5, "cannot": 0.5,
+	"obviously": 1, "surely": 1, "without doubt": 1, "of course": 0.5, "in fact": 1,
+	"guaranteed": 1, "proven": 1, "exactly": 0.5, "is required": 1, "are required": 1,
+}
+
+// certaintyMarkerPattern matches any hedging or assertive marker, longest first
+var certaintyMarkerPattern = func() *regexp.Regexp {
+	markers := make([]string, 0, len(hedgeWeights)+len(assertiveWeights))
+	for m := range hedgeWeights {
+		markers = append(markers, m)
+	}
+	for m := range assertiveWeights {
+		markers = append(markers, m)
+	}
+	return phrasePattern(markers)
+}()
+
+// certaintyMarkers sums the hedging and assertive weight of the markers in text
+func certaintyMarkers(text string) (hedging, assertive float64) {
+	for _, m := range certaintyMarkerPattern.FindAllString(text, -1) {
+		m = phraseKey(m)
+		hedging += hedgeWeights[m]
+		assertive += assertiveWeights[m]
+	}
+	return hedging, assertive
+}
+
+// sentenceCertainty rates how sure a sentence sounds, from 0 (speculative) to
+// 1 (certain). A sentence without markers reads as a plain claim at 0.6;
+// each unit of assertive weight adds 0.2 and each unit of hedging takes 0.25.
+func sentenceCertainty(sentence string) float64 {
+	hedging, assertive := certaintyMarkers(sentence)
+	certainty := 0.6 + 0.2*assertive - 0.25*hedging
+	if certainty < 0 {
+		return 0
+	}
+	if certainty > 1 {
+		return 1
+	}
+	return certainty
+}
+
+// calculateHedging measures hedging against assertive language across the
+// document: the share of the certainty markers' weight that hedges
+func calculateHedging(text string) EnhancedFloatMetric {
+	hedging, assertive := certaintyMarkers(text)
+	share := 0.0
+	if hedging+assertive > 0 {
+		share = hedging / (hedging + assertive)
+	}
+	return NewEnhancedFloatMetric(
+		share,
+		"0-1 (Higher = More Hedged)",
+		"Share of hedging language (\"might\", \"could possibly\", \"I think\") against assertive language (\"definitely\", \"must\") across the text. 0 when the text uses neither.",
+		"Below 0.3 reads as confident; above 0.6 signals uncertainty the model may mirror. State requirements plainly and keep hedges for what is truly optional.",
+	).WithMethodology("Formula: hedging weight / (hedging + assertive weight). Markers weigh 1, or 0.5 for weak ones such as \"may\" and \"will\"")
+}
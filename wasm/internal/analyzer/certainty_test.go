@@ -0,0 +1,38 @@
+package analyzer
+
+import "testing"
+
+func TestCalculateHedging(t *testing.T) {
+	hedged := calculateHedging("I think the cache might possibly help. Maybe we could try it.")
+	assertive := calculateHedging("The cache must be warmed first. It will definitely cut latency.")
+	if hedged.Value < 0.9 || assertive.Value > 0.1 {
+		t.Errorf("expected hedged text near 1 and assertive text near 0, got %.2f and %.2f", hedged.Value, assertive.Value)
+	}
+	if plain := calculateHedging("Load the file. Print the totals."); plain.Value != 0 {
+		t.Errorf("expected 0 without markers, got %.2f", plain.Value)
+	}
+	mixed := calculateHedging("The fix must ship today. It might need a second pass.")
+	if mixed.Value != 0.5 {
+		t.Errorf("expected an even split, got %.2f", mixed.Value)
+	}
+	if hedging, _ := certaintyMarkers("The plan helps in some\ncases and I\tthink it works."); hedging != 1.5 {
+		t.Errorf("hedges split across whitespace weigh %.2f, want 1.5", hedging)
+	}
+}
+
+func TestDetermineCertaintyLevel(t *testing.T) {
+	cases := []struct {
+		sentences []string
+		want      string
+	}{
+		{[]string{"This approach is definitely faster."}, "certain"},
+		{[]string{"This approach is faster."}, "probable"},
+		{[]string{"This approach might be faster."}, "possible"},
+		{[]string{"I think this might possibly be faster."}, "speculative"},
+	}
+	for _, c := range cases {
+		if got := determineCertaintyLevel(c.sentences); got != c.want {
+			t.Errorf("%q: expected %s, got %s", c.sentences[0], c.want, got)
+		}
+	}
+}
@@ -0,0 +1,246 @@
+package analyzer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// ClassifierExample is one labeled prompt used to train a category
+type ClassifierExample struct {
+	Label string `json:"label"` // e.g. "legal-drafting", or a built-in type such as "code_generation"
+	Text  string `json:"text"`
+}
+
+// TrainedCategory is a prompt category learned from labeled examples. A
+// category named after a built-in type replaces that type's keyword patterns.
+type TrainedCategory struct {
+	Label    string             `json:"label"`
+	Base     PromptType         `json:"base"`     // Built-in type whose grading rules apply
+	Keywords []string           `json:"keywords"` // Terms that best separate this category from the others
+	Centroid map[string]float64 `json:"centroid"` // Mean normalized term frequencies of the examples
+	Examples int                `json:"examples"`
+}
+
+// ClassifierModel is the output of TrainClassifier, stored in config as
+// analysis.classifier and passed to NewPromptClassifierWithModel
+type ClassifierModel struct {
+	Categories []TrainedCategory `json:"categories"`
+}
+
+// Training limits keep the persisted model small
+const (
+	centroidTerms     = 60 // Highest-weight terms kept per centroid
+	categoryKeywords  = 10 // Distinctive keywords kept per category
+	centroidScale     = 10 // Score for a perfect centroid match, comparable to a few pattern hits
+	trainedKeywordHit = 1  // Score per keyword present, like a built-in keyword
+)
+
+// builtinPromptTypes are the types NewPromptClassifier knows
+var builtinPromptTypes = map[PromptType]bool{
+	TechnicalSpec: true, CreativeTask: true, CodeGeneration: true, DataAnalysis: true,
+	Writing: true, ProblemSolving: true, Learning: true, General: true,
+}
+
+// TrainClassifier builds a category per label from the examples. Each
+// category's centroid is the mean of its examples' term vectors, its keywords
+// are the centroid terms most over-represented against the other categories,
+// and its base is the built-in type the examples classify as, so grading
+// knows which rules to apply.
+func TrainClassifier(examples []ClassifierExample) (ClassifierModel, error) {
+	if len(examples) == 0 {
+		return ClassifierModel{}, fmt.Errorf("no training examples")
+	}
+	grouped := map[string][]string{}
+	labels := []string{}
+	for i, ex := range examples {
+		label := normalizeCategoryLabel(ex.Label)
+		if label == "" {
+			return ClassifierModel{}, fmt.Errorf("example %d has no label", i)
+		}
+		if strings.TrimSpace(ex.Text) == "" {
+			return ClassifierModel{}, fmt.Errorf("example %d (%s) has no text", i, label)
+		}
+		if _, ok := grouped[label]; !ok {
+			labels = append(labels, label)
+		}
+		grouped[label] = append(grouped[label], ex.Text)
+	}
+	sort.Strings(labels)
+
+	centroids := map[string]map[string]float64{}
+	for _, label := range labels {
+		centroid := map[string]float64{}
+		for _, text := range grouped[label] {
+			for term, w := range classifierTerms(text) {
+				centroid[term] += w / float64(len(grouped[label]))
+			}
+		}
+		centroids[label] = centroid
+	}
+
+	builtin := NewPromptClassifier()
+	model := ClassifierModel{Categories: []TrainedCategory{}}
+	for _, label := range labels {
+		category := TrainedCategory{
+			Label:    label,
+			Base:     PromptType(label),
+			Keywords: distinctiveTerms(label, centroids),
+			Centroid: topTerms(centroids[label], centroidTerms),
+			Examples: len(grouped[label]),
+		}
+		if !builtinPromptTypes[category.Base] {
+			category.Base = builtin.ClassifyPrompt(strings.Join(grouped[label], "\n\n")).PrimaryType
+		}
+		model.Categories = append(model.Categories, category)
+	}
+	return model, nil
+}
+
+// LoadClassifierExamples parses a JSON array of {label, text} examples
+func LoadClassifierExamples(data []byte) ([]ClassifierExample, error) {
+	var examples []ClassifierExample
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&examples); err != nil {
+		return nil, fmt.Errorf("invalid classifier examples: %w", err)
+	}
+	return examples, nil
+}
+
+// LoadClassifierModel parses a model saved from TrainClassifier
+func LoadClassifierModel(data []byte) (ClassifierModel, error) {
+	var model ClassifierModel
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&model); err != nil {
+		return ClassifierModel{}, fmt.Errorf("invalid classifier model: %w", err)
+	}
+	if err := model.Validate(); err != nil {
+		return ClassifierModel{}, err
+	}
+	return model, nil
+}
+
+// Validate rejects categories that could not have come from TrainClassifier
+func (m ClassifierModel) Validate() error {
+	seen := map[string]bool{}
+	for i, c := range m.Categories {
+		if normalizeCategoryLabel(c.Label) == "" {
+			return fmt.Errorf("classifier category %d has no label", i)
+		}
+		if seen[c.Label] {
+			return fmt.Errorf("classifier category %q is defined twice", c.Label)
+		}
+		seen[c.Label] = true
+		if c.Base != "" && !builtinPromptTypes[c.Base] {
+			return fmt.Errorf("classifier category %q has unknown base type %q", c.Label, c.Base)
+		}
+		if len(c.Centroid) == 0 && len(c.Keywords) == 0 {
+			return fmt.Errorf("classifier category %q has no centroid or keywords", c.Label)
+		}
+	}
+	return nil
+}
+
+// normalizeCategoryLabel lowercases a label and joins its words with hyphens,
+// keeping built-in type names such as "code_generation" as they are
+func normalizeCategoryLabel(label string) string {
+	return strings.Join(strings.Fields(strings.ToLower(label)), "-")
+}
+
+// classifierTerms is a text's L2-normalized content word frequencies
+func classifierTerms(text string) map[string]float64 {
+	counts := map[string]float64{}
+	for _, w := range extractWords(text) {
+		if len(w) >= 3 && !isStopWord(w) {
+			counts[w]++
+		}
+	}
+	norm := 0.0
+	for _, c := range counts {
+		norm += c * c
+	}
+	norm = math.Sqrt(norm)
+	for w := range counts {
+		counts[w] /= norm
+	}
+	return counts
+}
+
+// distinctiveTerms ranks a category's terms by how much more weight they
+// carry than in the closest other category
+func distinctiveTerms(label string, centroids map[string]map[string]float64) []string {
+	margins := map[string]float64{}
+	for term, w := range centroids[label] {
+		other := 0.0
+		for l, c := range centroids {
+			if l != label && c[term] > other {
+				other = c[term]
+			}
+		}
+		if w > other {
+			margins[term] = w - other
+		}
+	}
+	ranked := topTerms(margins, categoryKeywords)
+	keywords := make([]string, 0, len(ranked))
+	for term := range ranked {
+		keywords = append(keywords, term)
+	}
+	sort.Slice(keywords, func(i, j int) bool {
+		if ranked[keywords[i]] != ranked[keywords[j]] {
+			return ranked[keywords[i]] > ranked[keywords[j]]
+		}
+		return keywords[i] < keywords[j]
+	})
+	return keywords
+}
+
+// topTerms keeps the n highest-weighted terms, rounded for storage
+func topTerms(weights map[string]float64, n int) map[string]float64 {
+	terms := make([]string, 0, len(weights))
+	for t := range weights {
+		terms = append(terms, t)
+	}
+	sort.Slice(terms, func(i, j int) bool {
+		if weights[terms[i]] != weights[terms[j]] {
+			return weights[terms[i]] > weights[terms[j]]
+		}
+		return terms[i] < terms[j]
+	})
+	if len(terms) > n {
+		terms = terms[:n]
+	}
+	top := make(map[string]float64, len(terms))
+	for _, t := range terms {
+		top[t] = math.Round(weights[t]*10000) / 10000
+	}
+	return top
+}
+
+// trainedScore rates text against a trained category: cosine similarity with
+// the centroid scaled to centroidScale, plus a point per keyword present
+func trainedScore(terms map[string]float64, c TrainedCategory) (float64, []string) {
+	dot, norm := 0.0, 0.0
+	for _, term := range sortedKeys(c.Centroid) { // Fixed order keeps the float sums identical across runs
+		w := c.Centroid[term]
+		dot += w * terms[term]
+		norm += w * w
+	}
+	score := 0.0
+	if norm > 0 {
+		score = centroidScale * dot / math.Sqrt(norm) // terms is already unit length
+	}
+	hits := []string{}
+	for _, k := range c.Keywords {
+		if terms[k] > 0 {
+			score += trainedKeywordHit
+			hits = append(hits, k)
+		}
+	}
+	return score, hits
+}
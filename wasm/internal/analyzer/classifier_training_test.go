@@ -0,0 +1,117 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+var domainExamples = []ClassifierExample{
+	{Label: "Legal Drafting", Text: "Draft an indemnification clause for a software license agreement governed by Delaware law."},
+	{Label: "Legal Drafting", Text: "Write a confidentiality clause for a mutual NDA between two contracting parties."},
+	{Label: "Legal Drafting", Text: "Prepare a termination clause for a services agreement with thirty days notice to the other party."},
+	{Label: "SQL Generation", Text: "Write a SQL query that joins orders and customers tables and returns total revenue per customer."},
+	{Label: "SQL Generation", Text: "Generate a SQL query selecting the top ten products by sales from the orders table, grouped by category."},
+	{Label: "SQL Generation", Text: "Create a SQL query to find customers with no orders in the last year using a left join."},
+}
+
+func TestTrainClassifierCustomCategories(t *testing.T) {
+	model, err := TrainClassifier(domainExamples)
+	if err != nil {
+		t.Fatalf("TrainClassifier: %v", err)
+	}
+	if len(model.Categories) != 2 {
+		t.Fatalf("expected 2 categories, got %d", len(model.Categories))
+	}
+	legal, sql := model.Categories[0], model.Categories[1]
+	if legal.Label != "legal-drafting" || sql.Label != "sql-generation" {
+		t.Fatalf("expected normalized labels, got %q and %q", legal.Label, sql.Label)
+	}
+	if !contains(legal.Keywords, "clause") || !contains(sql.Keywords, "sql") {
+		t.Errorf("expected distinctive keywords, got %v and %v", legal.Keywords, sql.Keywords)
+	}
+	if legal.Examples != 3 || !builtinPromptTypes[legal.Base] {
+		t.Errorf("expected 3 examples and a built-in base, got %d and %q", legal.Examples, legal.Base)
+	}
+
+	// The model survives a round trip through config
+	data, err := json.Marshal(model)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadClassifierModel(data)
+	if err != nil {
+		t.Fatalf("LoadClassifierModel: %v", err)
+	}
+
+	classifier := NewPromptClassifierWithModel(loaded)
+	cls := classifier.ClassifyPrompt("Draft a limitation of liability clause for our supplier agreement.")
+	if cls.PrimaryType != "legal-drafting" {
+		t.Fatalf("expected legal-drafting, got %q (%+v)", cls.PrimaryType, cls.Labels)
+	}
+	if cls.GradingType() != legal.Base {
+		t.Errorf("expected grading as %q, got %q", legal.Base, cls.GradingType())
+	}
+	if GetPromptTypeDisplayName(cls.PrimaryType) != "legal-drafting" {
+		t.Errorf("expected trained categories to display by label")
+	}
+
+	cls = classifier.ClassifyPrompt("Write a SQL query that counts orders per customer from the orders table.")
+	if cls.PrimaryType != "sql-generation" {
+		t.Errorf("expected sql-generation, got %q (%+v)", cls.PrimaryType, cls.Labels)
+	}
+
+	// Unrelated prompts keep their built-in type
+	poem := "Write a poem about autumn leaves and a lonely fox."
+	if got, want := classifier.ClassifyPrompt(poem).PrimaryType, NewPromptClassifier().ClassifyPrompt(poem).PrimaryType; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestTrainClassifierOverridesBuiltinType(t *testing.T) {
+	model, err := TrainClassifier([]ClassifierExample{
+		{Label: string(DataAnalysis), Text: "Reconcile the quarterly ledger balances against the bank statements."},
+		{Label: string(DataAnalysis), Text: "Reconcile vendor invoices with ledger entries and flag mismatched balances."},
+	})
+	if err != nil {
+		t.Fatalf("TrainClassifier: %v", err)
+	}
+	if model.Categories[0].Base != DataAnalysis {
+		t.Errorf("expected a built-in label to be its own base, got %q", model.Categories[0].Base)
+	}
+	classifier := NewPromptClassifierWithModel(model)
+	if _, ok := classifier.patterns[DataAnalysis]; ok {
+		t.Error("expected the trained category to replace the built-in data analysis patterns")
+	}
+	cls := classifier.ClassifyPrompt("Reconcile the ledger balances for March.")
+	if cls.PrimaryType != DataAnalysis {
+		t.Errorf("expected data_analysis, got %q", cls.PrimaryType)
+	}
+}
+
+func TestClassifierModelValidation(t *testing.T) {
+	if _, err := TrainClassifier(nil); err == nil {
+		t.Error("expected an error for no examples")
+	}
+	if _, err := TrainClassifier([]ClassifierExample{{Label: " ", Text: "x"}}); err == nil {
+		t.Error("expected an error for a blank label")
+	}
+	if _, err := LoadClassifierExamples([]byte(`[{"label":"a","text":"b","extra":1}]`)); err == nil {
+		t.Error("expected unknown fields to be rejected")
+	}
+
+	bad := []ClassifierModel{
+		{Categories: []TrainedCategory{{Label: "", Keywords: []string{"a"}}}},
+		{Categories: []TrainedCategory{{Label: "a", Keywords: []string{"a"}}, {Label: "a", Keywords: []string{"b"}}}},
+		{Categories: []TrainedCategory{{Label: "a", Base: "poetry", Keywords: []string{"a"}}}},
+		{Categories: []TrainedCategory{{Label: "a"}}},
+	}
+	for i, m := range bad {
+		if err := m.Validate(); err == nil {
+			t.Errorf("model %d: expected a validation error", i)
+		}
+	}
+	opts := AnalysisOptions{Classifier: bad[0]}
+	if err := opts.Validate(); err == nil {
+		t.Error("expected AnalysisOptions to validate the classifier")
+	}
+}
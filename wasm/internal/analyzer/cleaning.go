@@ -0,0 +1,114 @@
+package analyzer
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Cleaning step names accepted in AnalysisOptions.Cleaning
+const (
+	CleanStripHTML          = "strip_html"
+	CleanStripURLs          = "strip_urls"
+	CleanStripEmails        = "strip_emails"
+	CleanStripEmoji         = "strip_emoji"
+	CleanJoinLines          = "join_lines"
+	CleanCollapseWhitespace = "collapse_whitespace"
+	CleanRemoveControl      = "remove_control_chars"
+	CleanTrim               = "trim"
+)
+
+// DefaultCleaningSteps is the cleaning pipeline used when none is configured:
+// one line of single-spaced text without control characters
+var DefaultCleaningSteps = []string{CleanJoinLines, CleanCollapseWhitespace, CleanRemoveControl, CleanTrim}
+
+var (
+	htmlTagPattern       = regexp.MustCompile(`</?[A-Za-z][^<>]*>`)
+	innerSpacePattern    = regexp.MustCompile(`(\S)[^\S\n]+`)
+	trailingSpacePattern = regexp.MustCompile(`[^\S\n]+\n`)
+)
+
+// cleaningStep is one named transformation of the cleaning pipeline
+type cleaningStep struct {
+	description string
+	apply       func(string) string
+}
+
+var cleaningSteps = map[string]cleaningStep{
+	CleanStripHTML: {"Removed HTML tags", func(s string) string {
+		return htmlTagPattern.ReplaceAllString(s, "")
+	}},
+	CleanStripURLs: {"Removed URLs", func(s string) string {
+		return urlPattern.ReplaceAllString(s, "")
+	}},
+	CleanStripEmails: {"Removed email addresses", func(s string) string {
+		return emailPattern.ReplaceAllString(s, "")
+	}},
+	CleanStripEmoji: {"Removed emoji", stripEmoji},
+	CleanJoinLines: {"Joined lines with spaces", func(s string) string {
+		return lineBreakPattern.ReplaceAllString(s, " ")
+	}},
+	// Newlines survive when join_lines did not run, so collapsing keeps them,
+	// along with list indentation, and limits blank lines to one
+	CleanCollapseWhitespace: {"Collapsed runs of whitespace", func(s string) string {
+		s = lineBreakPattern.ReplaceAllString(s, "\n")
+		s = innerSpacePattern.ReplaceAllString(s, "$1 ")
+		s = trailingSpacePattern.ReplaceAllString(s, "\n")
+		return blankLinesPattern.ReplaceAllString(s, "\n\n")
+	}},
+	CleanRemoveControl: {"Removed control and other non-printing characters, keeping emoji sequences whole", func(s string) string {
+		return outsideEmoji(s, func(s string) string {
+			return nonPrintablePattern.ReplaceAllString(s, "")
+		})
+	}},
+	CleanTrim: {"Trimmed leading and trailing whitespace", strings.TrimSpace},
+}
+
+// validateCleaningSteps rejects unknown and repeated cleaning steps
+func validateCleaningSteps(steps []string) error {
+	seen := make(map[string]bool, len(steps))
+	for _, step := range steps {
+		if _, ok := cleaningSteps[step]; !ok {
+			return fmt.Errorf("unknown cleaning step %q (expected one of %v)", step, cleaningStepNames())
+		}
+		if seen[step] {
+			return fmt.Errorf("cleaning step %q listed twice", step)
+		}
+		seen[step] = true
+	}
+	return nil
+}
+
+// cleaningStepNames lists the accepted cleaning steps in the order they
+// usually run
+func cleaningStepNames() []string {
+	return []string{CleanStripHTML, CleanStripURLs, CleanStripEmails, CleanStripEmoji, CleanJoinLines, CleanCollapseWhitespace, CleanRemoveControl, CleanTrim}
+}
+
+// cleaningStepName is the transformation log step for a cleaning step
+func cleaningStepName(step string) string { return "cleaning:" + step }
+
+// cleanText runs the cleaning steps in order; nil steps run DefaultCleaningSteps
+func cleanText(text string, steps []string) string {
+	return runCleaningSteps(text, steps, nil)
+}
+
+// runCleaningSteps runs each step in order, handing its input and output to
+// logStep when it is set
+func runCleaningSteps(text string, steps []string, logStep func(name, description, before, after string)) string {
+	if steps == nil {
+		steps = DefaultCleaningSteps
+	}
+	for _, name := range steps {
+		step, ok := cleaningSteps[name]
+		if !ok {
+			continue
+		}
+		after := step.apply(text)
+		if logStep != nil {
+			logStep(name, step.description, text, after)
+		}
+		text = after
+	}
+	return text
+}
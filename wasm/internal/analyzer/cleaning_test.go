@@ -0,0 +1,38 @@
+package analyzer
+
+import "testing"
+
+func TestCleaningStepsCompose(t *testing.T) {
+	text := "<p>Read  https://example.com/docs\tfirst.</p>\n\n  Then mail ops@example.com 🚀 "
+	data := PreprocessTextWithOptions(text, PreprocessOptions{
+		Cleaning: []string{CleanStripHTML, CleanStripURLs, CleanStripEmails, CleanCollapseWhitespace, CleanTrim},
+	})
+	if got := data.CleanedText.Value; got != "Read first.\n\n  Then mail 🚀" {
+		t.Errorf("cleaned = %q", got)
+	}
+
+	var steps []string
+	for _, s := range data.TransformationLog.Value {
+		steps = append(steps, s.Step)
+	}
+	want := []string{"original", "cleaning:strip_html", "cleaning:strip_urls", "cleaning:strip_emails", "cleaning:collapse_whitespace", "cleaning:trim", "normalization"}
+	for i, step := range want {
+		if i >= len(steps) || steps[i] != step {
+			t.Fatalf("log steps = %v, want prefix %v", steps, want)
+		}
+	}
+	before, after, err := data.StepText("cleaning:strip_urls")
+	if err != nil || before != "Read  https://example.com/docs\tfirst.\n\n  Then mail ops@example.com 🚀 " || after != "Read  \tfirst.\n\n  Then mail ops@example.com 🚀 " {
+		t.Errorf("strip_urls step = %q -> %q (%v)", before, after, err)
+	}
+
+	if got := cleanText("a\r\n  b", nil); got != "a b" {
+		t.Errorf("default cleaning = %q", got)
+	}
+	if err := (AnalysisOptions{Cleaning: []string{"trim", "trim"}}).Validate(); err == nil {
+		t.Error("repeated cleaning step accepted")
+	}
+	if err := (AnalysisOptions{Cleaning: []string{"strip_everything"}}).Validate(); err == nil {
+		t.Error("unknown cleaning step accepted")
+	}
+}
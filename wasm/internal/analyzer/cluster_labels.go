@@ -0,0 +1,193 @@
+package analyzer
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// maxClusterKeyPhrases is how many keyphrases each cluster keeps
+const maxClusterKeyPhrases = 3
+
+// keyphraseLengthBoost favors multiword phrases, which make better labels
+// than the single words inside them
+var keyphraseLengthBoost = map[int]float64{1: 1, 2: 1.5, 3: 1.75}
+
+// labelBreakWords end a keyphrase: prepositions, quantifiers and light verbs
+// that the short stopword lists leave in
+var labelBreakWords = newStopwordSet([]string{
+	"about", "above", "across", "after", "against", "along", "among", "around", "before", "behind",
+	"below", "between", "beyond", "during", "into", "onto", "over", "through", "under", "until",
+	"upon", "within", "without", "out", "off", "up", "down", "than", "then", "there", "here",
+	"all", "any", "each", "every", "some", "many", "much", "more", "most", "few", "other",
+	"very", "also", "just", "only", "not", "no", "too", "still", "even", "again", "now",
+	"get", "gets", "got", "make", "makes", "keep", "keeps", "need", "needs", "want", "wants",
+	"use", "uses", "using", "like", "take", "takes", "give", "gives", "let", "lets",
+})
+
+// labelClusters names each cluster after its most distinctive keyphrases and
+// describes it in a sentence. Candidate phrases are runs of one to three
+// content words. Each word scores its TF-IDF, treating clusters as documents
+// so words shared by every cluster rank low; a phrase scores the mean of its
+// words, boosted by its length and how often the whole phrase recurs.
+func labelClusters(clusters []IdeaCluster, stop StopwordSet) {
+	counts := make([]map[string]int, len(clusters))
+	surfaces := map[string]string{} // Phrase to its first spelling, to keep acronyms
+	df := map[string]int{}
+	for i, c := range clusters {
+		counts[i] = map[string]int{}
+		for _, s := range c.Sentences {
+			for _, p := range sentenceKeyphrases(s, stop) {
+				key := strings.ToLower(p)
+				if _, ok := surfaces[key]; !ok {
+					surfaces[key] = p
+				}
+				counts[i][key]++
+			}
+		}
+		for p := range counts[i] {
+			df[p]++
+		}
+	}
+
+	for i := range clusters {
+		type scored struct {
+			phrase string
+			score  float64
+		}
+		tfidf := func(p string) float64 {
+			return float64(counts[i][p]) * (math.Log(float64(len(clusters)+1)/float64(df[p]+1)) + 1)
+		}
+		candidates := make([]scored, 0, len(counts[i]))
+		for p, tf := range counts[i] {
+			words := strings.Fields(p)
+			if len(words) == 3 && tf < 2 {
+				continue // A three-word run seen once is usually a clause, not a topic
+			}
+			wordScore := 0.0
+			for _, w := range words {
+				wordScore += tfidf(w)
+			}
+			score := wordScore / float64(len(words)) * keyphraseLengthBoost[len(words)]
+			if len(words) > 1 {
+				score *= float64(tf)
+			}
+			candidates = append(candidates, scored{p, score})
+		}
+		sort.Slice(candidates, func(a, b int) bool {
+			if candidates[a].score != candidates[b].score {
+				return candidates[a].score > candidates[b].score
+			}
+			return candidates[a].phrase < candidates[b].phrase
+		})
+
+		phrases := []string{}
+		for _, c := range candidates {
+			if len(phrases) == maxClusterKeyPhrases {
+				break
+			}
+			if !phraseCovered(c.phrase, phrases) {
+				phrases = append(phrases, c.phrase)
+			}
+		}
+		for j, p := range phrases {
+			phrases[j] = displayPhrase(surfaces[p])
+		}
+		clusters[i].KeyPhrases = phrases
+		clusters[i].MainTopic = keyphraseLabel(phrases)
+		clusters[i].Description = clusterDescription(clusters[i])
+	}
+}
+
+// sentenceKeyphrases lists the one- to three-word runs of content words in a
+// sentence; stopwords, intent verbs, adverbs, punctuation and line breaks
+// break a run
+func sentenceKeyphrases(sentence string, stop StopwordSet) []string {
+	var phrases, run []string
+	flush := func() {
+		for n := 1; n <= 3; n++ {
+			for i := 0; i+n <= len(run); i++ {
+				phrases = append(phrases, strings.Join(run[i:i+n], " "))
+			}
+		}
+		run = run[:0]
+	}
+	for _, line := range strings.Split(sentence, "\n") {
+		for _, field := range strings.Fields(line) {
+			word := nonWordCharPattern.ReplaceAllString(field, "")
+			lower := strings.ToLower(word)
+			_, verb := goalIntents[lower]
+			if len(word) < 3 || stop.Contains(lower) || isStopWord(lower) || labelBreakWords.Contains(lower) ||
+				topicFillers.Contains(lower) || verb || strings.HasSuffix(lower, "ly") || !strings.ContainsFunc(word, unicode.IsLetter) {
+				flush()
+				continue
+			}
+			run = append(run, word)
+			if strings.ContainsAny(field[len(field)-1:], ",.;:!?)") {
+				flush()
+			}
+		}
+		flush()
+	}
+	return phrases
+}
+
+// phraseCovered reports whether phrase shares a word with a chosen phrase,
+// so "database" and "database time" are skipped after "billing database"
+func phraseCovered(phrase string, chosen []string) bool {
+	for _, c := range chosen {
+		for _, w := range strings.Fields(phrase) {
+			if contains(strings.Fields(c), w) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// displayPhrase lowercases a phrase but keeps acronyms such as "API"
+func displayPhrase(p string) string {
+	words := strings.Fields(p)
+	for i, w := range words {
+		if len(w) < 2 || strings.ToUpper(w) != w {
+			words[i] = strings.ToLower(w)
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// keyphraseLabel is the top keyphrase when it has several words, otherwise the
+// top two joined
+func keyphraseLabel(phrases []string) string {
+	switch {
+	case len(phrases) == 0:
+		return "General"
+	case len(phrases) == 1 || strings.Contains(phrases[0], " "):
+		return titleCase(phrases[0])
+	default:
+		return titleCase(phrases[0] + " and " + phrases[1])
+	}
+}
+
+// clusterDescription summarizes what a cluster says in one line, such as
+// "3 sentences, mostly instructions, about billing database and invoices"
+func clusterDescription(c IdeaCluster) string {
+	about := "no distinctive terms"
+	switch len(c.KeyPhrases) {
+	case 0:
+	case 1:
+		about = c.KeyPhrases[0]
+	default:
+		about = strings.Join(c.KeyPhrases[:len(c.KeyPhrases)-1], ", ") + " and " + c.KeyPhrases[len(c.KeyPhrases)-1]
+	}
+	kind := c.ThoughtType
+	if kind == "" {
+		kind = "idea"
+	}
+	if len(c.Sentences) == 1 {
+		return fmt.Sprintf("One %s about %s", kind, about)
+	}
+	return fmt.Sprintf("%d sentences, mostly %ss, about %s", len(c.Sentences), kind, about)
+}
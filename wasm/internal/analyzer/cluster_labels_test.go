@@ -0,0 +1,48 @@
+package analyzer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLabelClustersPrefersDistinctivePhrases(t *testing.T) {
+	clusters := []IdeaCluster{
+		{Sentences: []string{"The billing database is slow.", "Queries against the billing database time out at night."}, ThoughtType: "fact"},
+		{Sentences: []string{"Our API gateway rejects large uploads."}, ThoughtType: "fact"},
+	}
+	labelClusters(clusters, stopWords)
+
+	if clusters[0].MainTopic != "Billing Database" {
+		t.Errorf("label = %q, want %q", clusters[0].MainTopic, "Billing Database")
+	}
+	if clusters[0].Description != "2 sentences, mostly facts, about billing database, night and queries" {
+		t.Errorf("description = %q", clusters[0].Description)
+	}
+	if clusters[1].KeyPhrases[0] != "API gateway" || clusters[1].MainTopic != "API Gateway" {
+		t.Errorf("acronym lost: phrases %q, label %q", clusters[1].KeyPhrases, clusters[1].MainTopic)
+	}
+}
+
+func TestSentenceKeyphrasesBreakRuns(t *testing.T) {
+	got := sentenceKeyphrases("Customers complain about invoices, refunds\nand totally broken exports", stopWords)
+	want := []string{"Customers", "complain", "Customers complain", "invoices", "refunds", "broken", "exports", "broken exports"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestKeyphraseLabelFallbacks(t *testing.T) {
+	for _, tc := range []struct {
+		phrases []string
+		want    string
+	}{
+		{nil, "General"},
+		{[]string{"latency"}, "Latency"},
+		{[]string{"latency", "caching"}, "Latency and Caching"},
+		{[]string{"cache invalidation", "latency"}, "Cache Invalidation"},
+	} {
+		if got := keyphraseLabel(tc.phrases); got != tc.want {
+			t.Errorf("keyphraseLabel(%q) = %q, want %q", tc.phrases, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,291 @@
+package analyzer
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Clustering strategies for grouping sentences into ideas, fastest first
+const (
+	ClusterGreedy        = "greedy"        // One pass joining sentences to the first similar seed (default)
+	ClusterKMeans        = "kmeans"        // K-means over term-frequency vectors with cosine similarity
+	ClusterCommunity     = "community"     // Label propagation over the sentence similarity graph
+	ClusterAgglomerative = "agglomerative" // Average-linkage merging with the dendrogram cut at the threshold
+)
+
+// ClusteringStrategies lists the accepted AnalysisOptions.ClusteringStrategy values
+var ClusteringStrategies = []string{ClusterGreedy, ClusterKMeans, ClusterCommunity, ClusterAgglomerative}
+
+// maxClusteringIterations bounds the k-means and label propagation loops
+const maxClusteringIterations = 20
+
+// validateClusteringStrategy rejects unknown strategy names; "" means greedy
+func validateClusteringStrategy(strategy string) error {
+	if strategy != "" && !contains(ClusteringStrategies, strategy) {
+		return fmt.Errorf("unknown clustering strategy %q (expected one of %v)", strategy, ClusteringStrategies)
+	}
+	return nil
+}
+
+// clusterThreshold is the similarity two sentences need to share a cluster;
+// longer texts use a lower threshold to form fewer, larger clusters
+func clusterThreshold(sentences int) float64 {
+	if sentences > 50 {
+		return 0.15
+	}
+	return 0.2
+}
+
+// groupSentences partitions sentence indexes into clusters with the chosen
+// strategy. Groups list their members in text order and are ordered by their
+// first member.
+func groupSentences(strategy string, terms [][]string, sets []map[string]bool) [][]int {
+	threshold := clusterThreshold(len(terms))
+	var groups [][]int
+	switch strategy {
+	case ClusterKMeans:
+		groups = kmeansGroups(terms)
+	case ClusterCommunity:
+		groups = communityGroups(similarityMatrix(terms, sets), threshold)
+	case ClusterAgglomerative:
+		groups = agglomerativeGroups(similarityMatrix(terms, sets), threshold)
+	default:
+		return greedyGroups(terms, sets, threshold)
+	}
+	for _, g := range groups {
+		sort.Ints(g)
+	}
+	sort.Slice(groups, func(a, b int) bool { return groups[a][0] < groups[b][0] })
+	if len(groups) > maxIdeaClusters {
+		groups = groups[:maxIdeaClusters]
+	}
+	return groups
+}
+
+// greedyGroups seeds a cluster with each unused sentence and adds later
+// sentences similar to the seed, up to maxClusterSize members
+func greedyGroups(terms [][]string, sets []map[string]bool, threshold float64) [][]int {
+	groups := [][]int{}
+	used := make([]bool, len(terms))
+	for i := range terms {
+		if used[i] || len(groups) >= maxIdeaClusters {
+			continue
+		}
+		used[i] = true
+		members := []int{i}
+		for j := i + 1; j < len(terms) && len(members) < maxClusterSize; j++ {
+			if !used[j] && termSetSimilarity(terms[i], sets[j], len(terms[j])) > threshold {
+				members = append(members, j)
+				used[j] = true
+			}
+		}
+		groups = append(groups, members)
+	}
+	return groups
+}
+
+// similarityMatrix holds the Jaccard similarity of every pair of sentences
+func similarityMatrix(terms [][]string, sets []map[string]bool) [][]float64 {
+	sim := make([][]float64, len(terms))
+	for i := range sim {
+		sim[i] = make([]float64, len(terms))
+	}
+	for i := range terms {
+		sim[i][i] = 1
+		for j := i + 1; j < len(terms); j++ {
+			s := termSetSimilarity(terms[i], sets[j], len(terms[j]))
+			sim[i][j], sim[j][i] = s, s
+		}
+	}
+	return sim
+}
+
+// agglomerativeGroups starts from single sentences and repeatedly merges the
+// two clusters with the highest average similarity between their members,
+// stopping when no pair clears the threshold or when the merges would
+// otherwise leave more than maxIdeaClusters clusters
+func agglomerativeGroups(sim [][]float64, threshold float64) [][]int {
+	groups := make([][]int, len(sim))
+	linkage := make([][]float64, len(sim)) // Average similarity between groups
+	for i := range sim {
+		groups[i] = []int{i}
+		linkage[i] = append([]float64{}, sim[i]...)
+	}
+	alive := len(groups)
+	for alive > 1 {
+		a, b, best := -1, -1, -1.0
+		for i := range groups {
+			for j := i + 1; j < len(groups); j++ {
+				if groups[i] != nil && groups[j] != nil && linkage[i][j] > best {
+					a, b, best = i, j, linkage[i][j]
+				}
+			}
+		}
+		if best <= threshold && alive <= maxIdeaClusters {
+			break
+		}
+		// Lance-Williams update for average linkage
+		na, nb := float64(len(groups[a])), float64(len(groups[b]))
+		for k := range groups {
+			if groups[k] != nil && k != a && k != b {
+				linkage[a][k] = (na*linkage[a][k] + nb*linkage[b][k]) / (na + nb)
+				linkage[k][a] = linkage[a][k]
+			}
+		}
+		groups[a] = append(groups[a], groups[b]...)
+		groups[b] = nil
+		alive--
+	}
+	out := [][]int{}
+	for _, g := range groups {
+		if g != nil {
+			out = append(out, g)
+		}
+	}
+	return out
+}
+
+// communityGroups links sentences whose similarity clears the threshold and
+// finds communities by label propagation: each sentence repeatedly takes the
+// label with the most edge weight among its neighbors, lowest label on ties,
+// until no label changes
+func communityGroups(sim [][]float64, threshold float64) [][]int {
+	labels := make([]int, len(sim))
+	for i := range labels {
+		labels[i] = i
+	}
+	for iter := 0; iter < maxClusteringIterations; iter++ {
+		changed := false
+		for i := range sim {
+			weights := map[int]float64{labels[i]: 0}
+			for j, s := range sim[i] {
+				if j != i && s > threshold {
+					weights[labels[j]] += s
+				}
+			}
+			best := labels[i]
+			for label, w := range weights {
+				if w > weights[best] || (w == weights[best] && label < best) {
+					best = label
+				}
+			}
+			if best != labels[i] {
+				labels[i] = best
+				changed = true
+			}
+		}
+		if !changed {
+			break
+		}
+	}
+	byLabel := map[int][]int{}
+	for i, label := range labels {
+		byLabel[label] = append(byLabel[label], i)
+	}
+	groups := make([][]int, 0, len(byLabel))
+	for _, g := range byLabel {
+		groups = append(groups, g)
+	}
+	return groups
+}
+
+// kmeansGroups runs k-means on term-frequency vectors with cosine similarity.
+// k is the square root of half the sentence count, and the initial centroids
+// are picked farthest-first from the first sentence so results are stable.
+func kmeansGroups(terms [][]string) [][]int {
+	if len(terms) == 0 {
+		return nil
+	}
+	vectors := make([]map[string]float64, len(terms))
+	for i, t := range terms {
+		vectors[i] = map[string]float64{}
+		for _, term := range t {
+			vectors[i][term]++
+		}
+	}
+	k := int(math.Round(math.Sqrt(float64(len(terms)) / 2)))
+	k = max(1, min(k, min(maxIdeaClusters, len(terms))))
+
+	centroids := []map[string]float64{vectors[0]}
+	for len(centroids) < k {
+		far, farthest := -1, 2.0
+		for i, v := range vectors {
+			nearest := -1.0
+			for _, c := range centroids {
+				nearest = math.Max(nearest, cosineSimilarity(v, c))
+			}
+			if nearest < farthest {
+				far, farthest = i, nearest
+			}
+		}
+		centroids = append(centroids, vectors[far])
+	}
+
+	assign := make([]int, len(vectors))
+	for iter := 0; iter < maxClusteringIterations; iter++ {
+		changed := iter == 0
+		for i, v := range vectors {
+			best, bestSim := 0, -1.0
+			for c, centroid := range centroids {
+				if s := cosineSimilarity(v, centroid); s > bestSim {
+					best, bestSim = c, s
+				}
+			}
+			if assign[i] != best {
+				assign[i] = best
+				changed = true
+			}
+		}
+		if !changed {
+			break
+		}
+		for c := range centroids {
+			mean, n := map[string]float64{}, 0.0
+			for i, v := range vectors {
+				if assign[i] == c {
+					n++
+					for term, w := range v {
+						mean[term] += w
+					}
+				}
+			}
+			if n > 0 {
+				for term := range mean {
+					mean[term] /= n
+				}
+				centroids[c] = mean
+			}
+		}
+	}
+
+	byCentroid := make([][]int, k)
+	for i, c := range assign {
+		byCentroid[c] = append(byCentroid[c], i)
+	}
+	groups := [][]int{}
+	for _, g := range byCentroid {
+		if len(g) > 0 {
+			groups = append(groups, g)
+		}
+	}
+	return groups
+}
+
+// cosineSimilarity compares two sparse term vectors. Terms are summed in
+// sorted order so rounding, and therefore tie-breaking, is the same every run.
+func cosineSimilarity(a, b map[string]float64) float64 {
+	dot, na, nb := 0.0, 0.0, 0.0
+	for _, term := range sortedKeys(a) {
+		w := a[term]
+		dot += w * b[term]
+		na += w * w
+	}
+	for _, term := range sortedKeys(b) {
+		nb += b[term] * b[term]
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / math.Sqrt(na*nb)
+}
@@ -0,0 +1,78 @@
+package analyzer
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+const clusteringTestText = "The billing database stores invoices and payments. " +
+	"The billing database needs a faster invoices index. " +
+	"Our marketing team plans a spring campaign launch. " +
+	"The spring campaign launch targets marketing newsletters. " +
+	"Payments in the billing database fail on invoices over limits."
+
+func TestClusteringStrategiesGroupRelatedSentences(t *testing.T) {
+	sentences := extractSentences(clusteringTestText)
+	plan := DefaultMemoryBudget().Plan(len(sentences), len(extractWords(clusteringTestText)))
+	for _, strategy := range ClusteringStrategies {
+		clusters := extractIdeaClusters(sentences, plan, stopWords, strategy)
+		var groups [][]string
+		for _, c := range clusters {
+			group := append([]string{}, c.Sentences...)
+			sort.Strings(group)
+			groups = append(groups, group)
+		}
+		want := [][]string{
+			{sentences[0], sentences[1], sentences[4]},
+			{sentences[2], sentences[3]},
+		}
+		for _, g := range want {
+			sort.Strings(g)
+		}
+		if !reflect.DeepEqual(groups, want) {
+			t.Errorf("%s: got %q, want billing and campaign sentences apart", strategy, groups)
+		}
+	}
+}
+
+func TestAgglomerativeStopsAtThreshold(t *testing.T) {
+	sim := [][]float64{
+		{1, 0.9, 0.1},
+		{0.9, 1, 0.05},
+		{0.1, 0.05, 1},
+	}
+	got := agglomerativeGroups(sim, 0.2)
+	want := [][]int{{0, 1}, {2}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestCommunityGroupsFollowEdges(t *testing.T) {
+	// 0-1-2 form a chain; 3 is isolated
+	sim := [][]float64{
+		{1, 0.5, 0, 0},
+		{0.5, 1, 0.5, 0},
+		{0, 0.5, 1, 0},
+		{0, 0, 0, 1},
+	}
+	got := communityGroups(sim, 0.2)
+	for _, g := range got {
+		sort.Ints(g)
+	}
+	sort.Slice(got, func(a, b int) bool { return got[a][0] < got[b][0] })
+	want := [][]int{{0, 1, 2}, {3}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestClusteringStrategyOption(t *testing.T) {
+	if _, err := ParseAnalysisOptions([]byte(`{"clustering_strategy":"agglomerative"}`)); err != nil {
+		t.Errorf("valid strategy rejected: %v", err)
+	}
+	if _, err := ParseAnalysisOptions([]byte(`{"clustering_strategy":"dbscan"}`)); err == nil {
+		t.Error("unknown strategy accepted")
+	}
+}
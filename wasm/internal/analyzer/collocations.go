@@ -0,0 +1,173 @@
+package analyzer
+
+import (
+	"math"
+	"sort"
+	"strings"
+)
+
+// NGramCount is one n-gram with how often it occurs
+type NGramCount struct {
+	NGram string `json:"ngram"`
+	Count int    `json:"count"`
+}
+
+// Collocation is a word pair or triple that occurs together more often than
+// its words' own frequencies predict, such as "machine learning"
+type Collocation struct {
+	Phrase string   `json:"phrase"`
+	Words  []string `json:"words"`
+	Count  int      `json:"count"`
+	PMI    float64  `json:"pmi"` // Pointwise mutual information in bits
+}
+
+// PhraseStatistics lists the most frequent bigrams and trigrams and the
+// collocations among them
+type PhraseStatistics struct {
+	TopBigrams   []NGramCount  `json:"top_bigrams"`
+	TopTrigrams  []NGramCount  `json:"top_trigrams"`
+	Collocations []Collocation `json:"collocations"`
+}
+
+const (
+	minCollocationCount = 2
+	minCollocationPMI   = 1.0
+	maxTopNGrams        = 10
+	maxCollocations     = 20
+)
+
+// wordRuns splits the word tokens into runs of adjacent lowercased words. A
+// run ends at any token other than a word, whitespace or hyphen, so n-grams
+// never span sentences, numbers or links.
+func wordRuns(tokens []Token) [][]string {
+	var runs [][]string
+	var run []string
+	for _, t := range tokens {
+		switch {
+		case t.Type == Word:
+			run = append(run, strings.ToLower(t.Text))
+		case t.Type == Whitespace || t.Text == "-":
+		default:
+			if len(run) > 0 {
+				runs = append(runs, run)
+			}
+			run = nil
+		}
+	}
+	if len(run) > 0 {
+		runs = append(runs, run)
+	}
+	return runs
+}
+
+// sentenceWordRuns gives one run of words per sentence
+func sentenceWordRuns(sentences []string) [][]string {
+	runs := make([][]string, 0, len(sentences))
+	for _, sentence := range sentences {
+		if words := extractWords(sentence); len(words) > 0 {
+			runs = append(runs, words)
+		}
+	}
+	return runs
+}
+
+// countNGrams counts the n-grams within each run
+func countNGrams(runs [][]string, n int) map[string]int {
+	counts := map[string]int{}
+	for _, run := range runs {
+		for i := 0; i+n <= len(run); i++ {
+			counts[strings.Join(run[i:i+n], " ")]++
+		}
+	}
+	return counts
+}
+
+// analyzePhrases counts the bigrams and trigrams within each run and finds
+// their collocations
+func analyzePhrases(runs [][]string, stop StopwordSet) PhraseStatistics {
+	bigrams, trigrams := countNGrams(runs, 2), countNGrams(runs, 3)
+	return PhraseStatistics{
+		TopBigrams:   topNGrams(bigrams),
+		TopTrigrams:  topNGrams(trigrams),
+		Collocations: findCollocations(runs, bigrams, trigrams, stop),
+	}
+}
+
+// topNGrams lists the n-grams seen at least twice, most frequent first
+func topNGrams(counts map[string]int) []NGramCount {
+	top := []NGramCount{}
+	for ngram, count := range counts {
+		if count >= minCollocationCount {
+			top = append(top, NGramCount{NGram: ngram, Count: count})
+		}
+	}
+	sort.Slice(top, func(i, j int) bool {
+		if top[i].Count != top[j].Count {
+			return top[i].Count > top[j].Count
+		}
+		return top[i].NGram < top[j].NGram
+	})
+	if len(top) > maxTopNGrams {
+		top = top[:maxTopNGrams]
+	}
+	return top
+}
+
+// findCollocations keeps the repeated bigrams and trigrams whose PMI,
+// log2(p(phrase) / product of p(word)), clears minCollocationPMI. A phrase
+// must start and end on a content word; a trigram may have a stopword in the
+// middle, as in "point of view".
+func findCollocations(runs [][]string, bigrams, trigrams map[string]int, stop StopwordSet) []Collocation {
+	unigrams := map[string]int{}
+	total := 0
+	for _, run := range runs {
+		for _, w := range run {
+			unigrams[w]++
+			total++
+		}
+	}
+	collocations := []Collocation{}
+	consider := func(counts map[string]int) {
+		for phrase, count := range counts {
+			if count < minCollocationCount {
+				continue
+			}
+			words := strings.Fields(phrase)
+			if !collocationWord(words[0], stop) || !collocationWord(words[len(words)-1], stop) {
+				continue
+			}
+			// p(phrase) / prod p(w) = count * total^(n-1) / prod count(w)
+			pmi := math.Log2(float64(count))
+			for _, w := range words {
+				pmi += math.Log2(float64(total)) - math.Log2(float64(unigrams[w]))
+			}
+			pmi -= math.Log2(float64(total))
+			if pmi < minCollocationPMI {
+				continue
+			}
+			collocations = append(collocations, Collocation{Phrase: phrase, Words: words, Count: count, PMI: math.Round(pmi*1000) / 1000})
+		}
+	}
+	consider(bigrams)
+	consider(trigrams)
+	sort.Slice(collocations, func(i, j int) bool {
+		a, b := collocations[i], collocations[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		if a.PMI != b.PMI {
+			return a.PMI > b.PMI
+		}
+		return a.Phrase < b.Phrase
+	})
+	if len(collocations) > maxCollocations {
+		collocations = collocations[:maxCollocations]
+	}
+	return collocations
+}
+
+// collocationWord reports whether w can start or end a collocation: a
+// content word of more than two letters
+func collocationWord(w string, stop StopwordSet) bool {
+	return len(w) > 2 && !stop.Contains(w)
+}
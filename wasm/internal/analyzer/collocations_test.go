@@ -0,0 +1,58 @@
+package analyzer
+
+import "testing"
+
+func TestAnalyzePhrases(t *testing.T) {
+	text := "Machine learning helps reviewers. Open a pull request for the model. " +
+		"Machine learning needs data. Every pull request gets a review. The data is small."
+	data := TokenizeText(text)
+	found := map[string]Collocation{}
+	for _, c := range data.Phrases.Collocations {
+		found[c.Phrase] = c
+	}
+	for _, phrase := range []string{"machine learning", "pull request"} {
+		c, ok := found[phrase]
+		if !ok {
+			t.Fatalf("collocation %q missing from %+v", phrase, data.Phrases.Collocations)
+		}
+		if c.Count != 2 || c.PMI < minCollocationPMI {
+			t.Errorf("%q = %+v", phrase, c)
+		}
+	}
+	// Sentence ends break n-grams, so "reviewers open" is never counted
+	for _, b := range data.Phrases.TopBigrams {
+		if b.NGram == "reviewers open" {
+			t.Errorf("bigram spans a sentence end: %+v", b)
+		}
+	}
+	if len(data.Phrases.TopBigrams) == 0 || data.Phrases.TopBigrams[0].Count != 2 {
+		t.Errorf("top bigrams = %+v", data.Phrases.TopBigrams)
+	}
+}
+
+func TestKeyConceptsPreferCollocations(t *testing.T) {
+	sentences := []string{
+		"Machine learning helps reviewers.",
+		"Machine learning needs data.",
+		"Reviewers trust machine learning.",
+	}
+	var words []string
+	for _, s := range sentences {
+		words = append(words, extractWords(s)...)
+	}
+	concepts := extractKeyConcepts(sentences, words, stopWords)
+	names := map[string]KeyConcept{}
+	for _, c := range concepts {
+		names[c.Concept] = c
+	}
+	c, ok := names["machine learning"]
+	if !ok || c.Frequency != 3 || len(c.Context) == 0 {
+		t.Fatalf("machine learning concept = %+v (concepts %+v)", c, concepts)
+	}
+	if _, ok := names["machine"]; ok {
+		t.Errorf("word subsumed by a collocation still listed: %+v", concepts)
+	}
+	if concepts[0].Concept != "machine learning" {
+		t.Errorf("first concept = %q, want the collocation", concepts[0].Concept)
+	}
+}
@@ -3,6 +3,7 @@ package analyzer
 import (
 	"math"
 	"regexp"
+	"sort"
 	"strings"
 	"unicode"
 )
@@ -20,6 +21,11 @@ type ComplexityMetrics struct {
 	SyllableStats              EnhancedSyllableStatistics   `json:"syllable_stats"`
 	SentenceStats              EnhancedSentenceStatistics   `json:"sentence_stats"`
 	WordStats                  EnhancedWordStatistics       `json:"word_stats"`
+	Paragraphs                 ParagraphStructure           `json:"paragraph_structure"`
+	SentenceReadability        []SentenceReadability        `json:"sentence_readability"` // Per-sentence scores for a heatmap
+	ReadingTime                EnhancedFloatMetric          `json:"reading_time"`  // Minutes
+	SpeakingTime               EnhancedFloatMetric          `json:"speaking_time"` // Minutes
+	SkimmingTime               EnhancedFloatMetric          `json:"skimming_time"` // Minutes
 }
 
 type EnhancedSyllableStatistics struct {
@@ -28,12 +34,17 @@ type EnhancedSyllableStatistics struct {
 	SyllableVariance  EnhancedFloatMetric  `json:"syllable_variance"`
 	MaxSyllablesWord  EnhancedStringMetric `json:"max_syllables_word"`
 	MaxSyllableCount  EnhancedIntMetric    `json:"max_syllable_count"`
+	Confidence        EnhancedFloatMetric  `json:"estimation_confidence"`
 }
 
 type EnhancedSentenceStatistics struct {
 	TotalSentences      EnhancedIntMetric    `json:"total_sentences"`
 	AverageWordsPerSent EnhancedFloatMetric  `json:"average_words_per_sentence"`
 	SentenceLengthVar   EnhancedFloatMetric  `json:"sentence_length_variance"`
+	SentenceLengthStd   EnhancedFloatMetric  `json:"sentence_length_std_dev"`
+	SentenceLengthP50   EnhancedFloatMetric  `json:"sentence_length_p50"`
+	SentenceLengthP90   EnhancedFloatMetric  `json:"sentence_length_p90"`
+	LengthHistogram     EnhancedMapMetric    `json:"sentence_length_histogram"`
 	LongestSentence     EnhancedStringMetric `json:"longest_sentence"`
 	ShortestSentence    EnhancedStringMetric `json:"shortest_sentence"`
 	ComplexSentences    EnhancedIntMetric    `json:"complex_sentences"`
@@ -52,15 +63,27 @@ type EnhancedWordStatistics struct {
 }
 
 func AnalyzeComplexity(text string) ComplexityMetrics {
-	sentences := extractSentences(text)
-	words := extractWords(text)
+	return AnalyzeComplexityWithSpeeds(text, ReadingSpeedConfig{})
+}
+
+// AnalyzeComplexityWithSpeeds analyzes text, estimating reading, speaking and
+// skimming times at the given words per minute. Embedded JSON, YAML and XML
+// payloads are left out of the readability measures, though reading them
+// still counts toward the time estimates.
+func AnalyzeComplexityWithSpeeds(text string, speeds ReadingSpeedConfig) ComplexityMetrics {
+	prose := maskPayloads(text, AnalyzePayloads(text).Payloads)
+	sentences := extractSentences(prose)
+	words := extractWords(prose)
 	syllables := calculateTotalSyllables(words)
 
 	metrics := ComplexityMetrics{
-		SyllableStats: calculateEnhancedSyllableStats(words),
-		SentenceStats: calculateEnhancedSentenceStats(sentences, words),
-		WordStats:     calculateEnhancedWordStats(words),
+		SyllableStats:       calculateEnhancedSyllableStats(words),
+		SentenceStats:       calculateEnhancedSentenceStats(sentences, words),
+		WordStats:           calculateEnhancedWordStats(words),
+		Paragraphs:          AnalyzeParagraphs(text),
+		SentenceReadability: AnalyzeSentenceReadability(prose),
 	}
+	metrics.setTimeEstimates(len(extractWords(text)), speeds)
 
 	numSentences := float64(len(sentences))
 	numWords := float64(len(words))
@@ -86,7 +109,7 @@ func AnalyzeComplexity(text string) ComplexityMetrics {
 			"Target 60-70 for general audience, 80+ for children, 30-50 for academic/technical content. Optimize by shortening sentences and using simpler words.",
 		).WithMethodology("Formula: 206.835 - 1.015 × (words/sentences) - 84.6 × (syllables/words)")
 
-		characters := float64(countCharacters(text))
+		characters := float64(countCharacters(prose))
 		ari := 4.71*(characters/numWords) + 0.5*(numWords/numSentences) - 21.43
 		metrics.AutomatedReadabilityIndex = NewEnhancedFloatMetric(
 			ari,
@@ -95,7 +118,7 @@ func AnalyzeComplexity(text string) ComplexityMetrics {
 			"Use for precise grade-level targeting. Particularly useful for technical writing where syllable counting may be unreliable.",
 		).WithMethodology("Formula: 4.71 × (characters/words) + 0.5 × (words/sentences) - 21.43")
 
-		letters := float64(countLetters(text))
+		letters := float64(countLetters(prose))
 		colemanLiau := 0.0588*(letters/numWords*100) - 0.296*(numSentences/numWords*100) - 15.8
 		metrics.ColemanLiauIndex = NewEnhancedFloatMetric(
 			colemanLiau,
@@ -165,8 +188,10 @@ func AnalyzeComplexity(text string) ComplexityMetrics {
 	return metrics
 }
 
+var sentenceBoundaryPattern = regexp.MustCompile(`[.!?]+\s+`)
+
 func extractSentences(text string) []string {
-	re := regexp.MustCompile(`[.!?]+\s+`)
+	re := sentenceBoundaryPattern
 	sentences := re.Split(text, -1)
 
 	var cleanSentences []string
@@ -179,24 +204,20 @@ func extractSentences(text string) []string {
 	return cleanSentences
 }
 
-func extractWords(text string) []string {
-	re := regexp.MustCompile(`\b[a-zA-Z]+\b`)
-	words := re.FindAllString(text, -1)
-
-	var cleanWords []string
-	for _, word := range words {
-		cleanWords = append(cleanWords, strings.ToLower(word))
-	}
-	return cleanWords
+// countSyllables estimates a word's syllables; see estimateSyllables
+func countSyllables(word string) int {
+	return estimateSyllables(word).Count
 }
 
-func countSyllables(word string) int {
+// vowelRunSyllables counts runs of vowels, less a final silent "e". It is the
+// fallback for words the hyphenation patterns don't cover.
+func vowelRunSyllables(word string) int {
 	word = strings.ToLower(word)
 	syllables := 0
 	prevVowel := false
 
 	for _, char := range word {
-		isVowel := strings.ContainsRune("aeiou", char)
+		isVowel := strings.ContainsRune("aeiouáàâäãåéèêëíìîïóòôöõúùûü", char)
 		if isVowel && !prevVowel {
 			syllables++
 		}
@@ -278,17 +299,9 @@ func calculateAverageSentenceComplexity(sentences []string) float64 {
 	if len(sentences) == 0 {
 		return 0
 	}
-	indicators := []string{",", ";", ":", " and ", " or ", " because ", " although ", " however "}
 	total := 0
 	for _, s := range sentences {
-		score := 1 // minimum complexity per sentence
-		ls := " " + strings.ToLower(s) + " "
-		for _, ind := range indicators {
-			if strings.Contains(ls, ind) {
-				score++
-			}
-		}
-		total += score
+		total += 1 + len(complexityIndicators(s)) // minimum complexity per sentence is 1
 	}
 	return float64(total) / float64(len(sentences))
 }
@@ -315,8 +328,11 @@ func calculateEnhancedSyllableStats(words []string) EnhancedSyllableStatistics {
 	var counts []int
 	maxCount := 0
 	maxWord := ""
+	confidence := 0.0
 	for _, w := range words {
-		c := countSyllables(w)
+		estimate := estimateSyllables(w)
+		c := estimate.Count
+		confidence += estimate.Confidence
 		counts = append(counts, c)
 		total += c
 		if c > maxCount {
@@ -327,6 +343,7 @@ func calculateEnhancedSyllableStats(words []string) EnhancedSyllableStatistics {
 	avg := 0.0
 	if len(words) > 0 {
 		avg = float64(total) / float64(len(words))
+		confidence /= float64(len(words))
 	}
 	// simple variance
 	var sumSq float64
@@ -370,6 +387,12 @@ func calculateEnhancedSyllableStats(words []string) EnhancedSyllableStatistics {
 			"Maximum syllable count of any single word. Indicates peak word complexity.",
 			"Words with 4+ syllables significantly impact readability. Consider context and audience when using complex terms.",
 		),
+		Confidence: NewEnhancedFloatMetric(
+			confidence,
+			"0-1 (Higher = More Reliable)",
+			"Average confidence of the per-word syllable estimates. Dictionary words score highest, hyphenation-pattern splits next, and words outside the patterns (numbers, non-English) lowest.",
+			"Treat syllable-based scores (Flesch, Gunning Fog, SMOG) as approximate below 0.7; prefer character-based indexes such as ARI for such text.",
+		).WithMethodology("Exception dictionary, then Knuth-Liang hyphenation patterns with vowel runs counted per segment, then vowel runs alone"),
 	}
 }
 
@@ -379,14 +402,16 @@ func calculateEnhancedSentenceStats(sentences, words []string) EnhancedSentenceS
 		avg = float64(len(words)) / float64(len(sentences))
 	}
 
-	// Find longest and shortest sentences
+	// Find longest and shortest sentences, measuring length in words
 	longestSent := ""
 	shortestSent := ""
 	maxWords := 0
 	minWords := 1000000 // arbitrarily large number
+	lengths := make([]float64, 0, len(sentences))
 
 	for _, sent := range sentences {
-		wordCount := len(strings.Fields(sent))
+		wordCount := len(extractWords(sent))
+		lengths = append(lengths, float64(wordCount))
 		if wordCount > maxWords {
 			maxWords = wordCount
 			longestSent = sent
@@ -397,6 +422,21 @@ func calculateEnhancedSentenceStats(sentences, words []string) EnhancedSentenceS
 		}
 	}
 
+	// Population variance around the mean sentence length
+	mean, variance := 0.0, 0.0
+	for _, n := range lengths {
+		mean += n
+	}
+	if len(lengths) > 0 {
+		mean /= float64(len(lengths))
+		for _, n := range lengths {
+			variance += (n - mean) * (n - mean)
+		}
+		variance /= float64(len(lengths))
+	}
+	sorted := append([]float64(nil), lengths...)
+	sort.Float64s(sorted)
+
 	// Count complex and compound sentences (simplified heuristics)
 	complexCount := 0
 	compoundCount := 0
@@ -424,10 +464,34 @@ func calculateEnhancedSentenceStats(sentences, words []string) EnhancedSentenceS
 			"Aim for 15-20 words for general audience, 10-15 for simple text, 20+ acceptable for academic writing. Vary length for flow.",
 		),
 		SentenceLengthVar: NewEnhancedFloatMetric(
-			0.0, // Not calculated in this simplified version
+			variance,
 			"0-∞ (Variance)",
 			"Variance in sentence length. Higher variance indicates varied sentence structure.",
 			"Moderate variance creates better reading rhythm. Too much variance may be jarring, too little may be monotonous.",
+		).WithMethodology("Population variance of words per sentence: mean of (length - mean length)²"),
+		SentenceLengthStd: NewEnhancedFloatMetric(
+			math.Sqrt(variance),
+			"0-∞ (Words)",
+			"Standard deviation of sentence length, in words. The typical distance of a sentence from the average length.",
+			"3-8 words gives a natural rhythm. Near 0 reads as monotonous; above 12 usually means a few run-on sentences among short ones.",
+		).WithMethodology("Square root of the sentence length variance"),
+		SentenceLengthP50: NewEnhancedFloatMetric(
+			percentile(sorted, 50),
+			"0-∞ (Words)",
+			"Median sentence length. Unlike the average, it is not pulled up by a single very long sentence.",
+			"Compare with the average: a median well below the average means a few long sentences dominate.",
+		).WithMethodology("50th percentile of words per sentence, interpolated between ranks"),
+		SentenceLengthP90: NewEnhancedFloatMetric(
+			percentile(sorted, 90),
+			"0-∞ (Words)",
+			"90th percentile sentence length. One sentence in ten is at least this long.",
+			"Keep below about 30 words; above that, the longest sentences are likely hard to follow.",
+		).WithMethodology("90th percentile of words per sentence, interpolated between ranks"),
+		LengthHistogram: NewEnhancedMapMetric(
+			sentenceLengthHistogram(lengths),
+			"Count by Length (Words)",
+			"Number of sentences in each length band.",
+			"A healthy spread mostly falls in 6-20; a heavy 31+ band flags sentences to split.",
 		),
 		LongestSentence: NewEnhancedStringMetric(
 			longestSent,
@@ -456,6 +520,44 @@ func calculateEnhancedSentenceStats(sentences, words []string) EnhancedSentenceS
 	}
 }
 
+// percentile interpolates the p-th percentile (0-100) of sorted values
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(rank)
+	if lower+1 >= len(sorted) {
+		return sorted[len(sorted)-1]
+	}
+	return sorted[lower] + (rank-float64(lower))*(sorted[lower+1]-sorted[lower])
+}
+
+// sentenceLengthBands are the histogram buckets for sentence lengths in words
+var sentenceLengthBands = []struct {
+	label string
+	max   float64
+}{
+	{"1-5", 5}, {"6-10", 10}, {"11-20", 20}, {"21-30", 30}, {"31+", math.Inf(1)},
+}
+
+// sentenceLengthHistogram counts sentences per length band; every band is present
+func sentenceLengthHistogram(lengths []float64) map[string]int {
+	histogram := make(map[string]int, len(sentenceLengthBands))
+	for _, band := range sentenceLengthBands {
+		histogram[band.label] = 0
+	}
+	for _, n := range lengths {
+		for _, band := range sentenceLengthBands {
+			if n <= band.max {
+				histogram[band.label]++
+				break
+			}
+		}
+	}
+	return histogram
+}
+
 func calculateEnhancedWordStats(words []string) EnhancedWordStatistics {
 	unique := make(map[string]struct{})
 	longest := ""
@@ -0,0 +1,45 @@
+package analyzer
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSentenceLengthStatistics(t *testing.T) {
+	// Sentences of 2, 4, 4, 4, 5, 5, 7 and 9 words: mean 5, variance 4
+	text := "Stop now. Read the whole file. Then list every bug. Fix each bug carefully. " +
+		"Run all of the tests. Write a short summary today. Explain which changes were made and why. " +
+		"Finally open a pull request with the test output."
+	stats := AnalyzeComplexity(text).SentenceStats
+
+	if got := stats.SentenceLengthVar.Value; math.Abs(got-4) > 1e-9 {
+		t.Errorf("expected variance 4, got %v", got)
+	}
+	if got := stats.SentenceLengthStd.Value; math.Abs(got-2) > 1e-9 {
+		t.Errorf("expected standard deviation 2, got %v", got)
+	}
+	if got := stats.SentenceLengthP50.Value; got != 4.5 {
+		t.Errorf("expected median 4.5, got %v", got)
+	}
+	if got := stats.SentenceLengthP90.Value; math.Abs(got-7.6) > 1e-9 {
+		t.Errorf("expected p90 7.6, got %v", got)
+	}
+	want := map[string]int{"1-5": 6, "6-10": 2, "11-20": 0, "21-30": 0, "31+": 0}
+	for band, n := range want {
+		if stats.LengthHistogram.Value[band] != n {
+			t.Errorf("expected %d sentences in band %s, got %v", n, band, stats.LengthHistogram.Value)
+		}
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	if got := percentile(nil, 50); got != 0 {
+		t.Errorf("expected 0 for no values, got %v", got)
+	}
+	if got := percentile([]float64{7}, 90); got != 7 {
+		t.Errorf("expected the single value, got %v", got)
+	}
+	if got := percentile([]float64{1, 2, 3, 4}, 100); got != 4 {
+		t.Errorf("expected the maximum at p100, got %v", got)
+	}
+}
@@ -0,0 +1,45 @@
+package analyzer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+)
+
+// Result compression accepted in AnalysisOptions.Compression. Brotli is left
+// out because the standard library has no encoder for it.
+const (
+	CompressionNone = ""
+	CompressionGzip = "gzip" // gzip-compressed JSON bytes, for hosts that store or forward results
+)
+
+// validateCompression accepts gzip for JSON results only; object results are
+// handed to JS as values and have no bytes to compress
+func validateCompression(compression, format string) error {
+	switch compression {
+	case CompressionNone:
+		return nil
+	case CompressionGzip:
+		if format == FormatObject {
+			return fmt.Errorf("compression %q needs the %q format", compression, FormatJSON)
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown compression %q (expected %q)", compression, CompressionGzip)
+}
+
+// CompressResult gzips a marshaled result when compression asks for it
+func CompressResult(b []byte, compression string) ([]byte, error) {
+	if compression != CompressionGzip {
+		return b, nil
+	}
+	var buf bytes.Buffer
+	zw, _ := gzip.NewWriterLevel(&buf, gzip.BestSpeed)
+	if _, err := zw.Write(b); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
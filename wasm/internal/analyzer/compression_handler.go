@@ -0,0 +1,143 @@
+//go:build !js
+
+package analyzer
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// minCompressBytes is the smallest body worth compressing; gzip framing
+// outweighs the savings on short error responses
+const minCompressBytes = 1024
+
+var gzipWriters = sync.Pool{New: func() interface{} {
+	zw, _ := gzip.NewWriterLevel(io.Discard, gzip.BestSpeed)
+	return zw
+}}
+
+// CompressHandler gzips JSON, text and SSE responses for clients that accept
+// it. Bodies under minCompressBytes go out as is, except event streams, which
+// are compressed from the first flush so every event still arrives promptly.
+func CompressHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+		if !acceptsGzip(r.Header.Get("Accept-Encoding")) || r.Method == http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+		gw := &gzipResponseWriter{ResponseWriter: w, status: http.StatusOK}
+		defer gw.close()
+		next.ServeHTTP(gw, r)
+	})
+}
+
+// acceptsGzip reads an Accept-Encoding header, honoring gzip;q=0
+func acceptsGzip(header string) bool {
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if name != "gzip" && name != "*" {
+			continue
+		}
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if v, err := strconv.ParseFloat(q, 64); err == nil && v == 0 {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// compressible reports whether a content type benefits from gzip
+func compressible(contentType string) bool {
+	mediaType := strings.TrimSpace(strings.SplitN(contentType, ";", 2)[0])
+	return strings.HasPrefix(mediaType, "text/") || mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// gzipResponseWriter holds back the start of the body until it knows whether
+// compressing is worthwhile
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	status  int
+	buf     []byte
+	decided bool
+	zw      *gzip.Writer
+}
+
+func (g *gzipResponseWriter) WriteHeader(status int) {
+	if !g.decided {
+		g.status = status
+	}
+}
+
+func (g *gzipResponseWriter) Write(p []byte) (int, error) {
+	if g.decided {
+		if g.zw != nil {
+			return g.zw.Write(p)
+		}
+		return g.ResponseWriter.Write(p)
+	}
+	g.buf = append(g.buf, p...)
+	if len(g.buf) >= minCompressBytes {
+		if err := g.decide(false); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// Flush sends what is buffered; event streams start compressing here
+func (g *gzipResponseWriter) Flush() {
+	if !g.decided {
+		g.decide(true)
+	}
+	if g.zw != nil {
+		g.zw.Flush()
+	}
+	if f, ok := g.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// decide picks plain or gzip output, writes the header and the held bytes
+func (g *gzipResponseWriter) decide(flushing bool) error {
+	g.decided = true
+	h := g.Header()
+	large := len(g.buf) >= minCompressBytes || (flushing && strings.HasPrefix(h.Get("Content-Type"), "text/event-stream"))
+	if large && compressible(h.Get("Content-Type")) && h.Get("Content-Encoding") == "" &&
+		g.status != http.StatusNoContent && g.status != http.StatusNotModified {
+		h.Set("Content-Encoding", "gzip")
+		h.Del("Content-Length")
+		g.zw = gzipWriters.Get().(*gzip.Writer)
+		g.zw.Reset(g.ResponseWriter)
+	}
+	g.ResponseWriter.WriteHeader(g.status)
+	if len(g.buf) == 0 {
+		return nil
+	}
+	var err error
+	if g.zw != nil {
+		_, err = g.zw.Write(g.buf)
+	} else {
+		_, err = g.ResponseWriter.Write(g.buf)
+	}
+	g.buf = nil
+	return err
+}
+
+// close finishes the gzip stream once the handler returns
+func (g *gzipResponseWriter) close() {
+	if !g.decided {
+		g.decide(false)
+	}
+	if g.zw != nil {
+		g.zw.Close()
+		gzipWriters.Put(g.zw)
+		g.zw = nil
+	}
+}
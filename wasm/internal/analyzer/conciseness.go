@@ -0,0 +1,215 @@
+package analyzer
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Conciseness finding kinds
+const (
+	ConcisenessCliche = "cliche" // Stock phrase with a plainer equivalent
+	ConcisenessFiller = "filler" // Adds words without adding meaning
+	ConcisenessWordy  = "wordy"  // Long construction with a shorter equivalent
+)
+
+// wordyPhrase is a phrase to flag and its tighter replacement; an empty
+// replacement means the phrase can be cut
+type wordyPhrase struct {
+	kind        string
+	replacement string
+}
+
+// wordyPhrases maps lowercase phrases to their replacements
+var wordyPhrases = map[string]wordyPhrase{
+	// Wordy constructions
+	"in order to":                    {ConcisenessWordy, "to"},
+	"so as to":                       {ConcisenessWordy, "to"},
+	"in an effort to":                {ConcisenessWordy, "to"},
+	"for the purpose of":             {ConcisenessWordy, "to"},
+	"due to the fact that":           {ConcisenessWordy, "because"},
+	"owing to the fact that":         {ConcisenessWordy, "because"},
+	"the reason why is that":         {ConcisenessWordy, "because"},
+	"in spite of the fact that":      {ConcisenessWordy, "although"},
+	"despite the fact that":          {ConcisenessWordy, "although"},
+	"at this point in time":          {ConcisenessWordy, "now"},
+	"at this moment in time":         {ConcisenessWordy, "now"},
+	"at the present time":            {ConcisenessWordy, "now"},
+	"in the event that":              {ConcisenessWordy, "if"},
+	"until such time as":             {ConcisenessWordy, "until"},
+	"with regard to":                 {ConcisenessWordy, "about"},
+	"with respect to":                {ConcisenessWordy, "about"},
+	"in regard to":                   {ConcisenessWordy, "about"},
+	"in relation to":                 {ConcisenessWordy, "about"},
+	"in the near future":             {ConcisenessWordy, "soon"},
+	"a large number of":              {ConcisenessWordy, "many"},
+	"the majority of":                {ConcisenessWordy, "most"},
+	"in close proximity to":          {ConcisenessWordy, "near"},
+	"has the ability to":             {ConcisenessWordy, "can"},
+	"have the ability to":            {ConcisenessWordy, "can"},
+	"is able to":                     {ConcisenessWordy, "can"},
+	"are able to":                    {ConcisenessWordy, "can"},
+	"make a decision":                {ConcisenessWordy, "decide"},
+	"take into consideration":        {ConcisenessWordy, "consider"},
+	"give consideration to":          {ConcisenessWordy, "consider"},
+	"come to the conclusion":         {ConcisenessWordy, "conclude"},
+	"prior to":                       {ConcisenessWordy, "before"},
+	"subsequent to":                  {ConcisenessWordy, "after"},
+	"on a daily basis":               {ConcisenessWordy, "daily"},
+	"on a regular basis":             {ConcisenessWordy, "regularly"},
+	"each and every":                 {ConcisenessWordy, "every"},
+	"first and foremost":             {ConcisenessWordy, "first"},
+	"whether or not":                 {ConcisenessWordy, "whether"},
+	"at all times":                   {ConcisenessWordy, "always"},
+	"along the lines of":             {ConcisenessWordy, "like"},
+	"in the process of":              {ConcisenessWordy, ""},
+	"it is important to note that":   {ConcisenessWordy, ""},
+	"it should be noted that":        {ConcisenessWordy, ""},
+	"please note that":               {ConcisenessWordy, ""},
+	"i was wondering if you could":   {ConcisenessWordy, "please"},
+	"it would be great if you could": {ConcisenessWordy, "please"},
+
+	// Filler
+	"at the end of the day":        {ConcisenessFiller, "ultimately"},
+	"needless to say":              {ConcisenessFiller, ""},
+	"it goes without saying that":  {ConcisenessFiller, ""},
+	"as a matter of fact":          {ConcisenessFiller, ""},
+	"for all intents and purposes": {ConcisenessFiller, ""},
+	"all things considered":        {ConcisenessFiller, ""},
+	"to be honest":                 {ConcisenessFiller, ""},
+	"if you don't mind":            {ConcisenessFiller, ""},
+	"basically":                    {ConcisenessFiller, ""},
+	"essentially":                  {ConcisenessFiller, ""},
+	"literally":                    {ConcisenessFiller, ""},
+	"actually":                     {ConcisenessFiller, ""},
+	"kind of":                      {ConcisenessFiller, ""},
+	"sort of":                      {ConcisenessFiller, ""},
+
+	// Clichés
+	"think outside the box": {ConcisenessCliche, "think creatively"},
+	"low-hanging fruit":     {ConcisenessCliche, "easy wins"},
+	"move the needle":       {ConcisenessCliche, "make a measurable difference"},
+	"game changer":          {ConcisenessCliche, "major improvement"},
+	"paradigm shift":        {ConcisenessCliche, "fundamental change"},
+	"touch base":            {ConcisenessCliche, "talk"},
+	"circle back":           {ConcisenessCliche, "follow up"},
+	"on the same page":      {ConcisenessCliche, "in agreement"},
+	"last but not least":    {ConcisenessCliche, "finally"},
+	"in this day and age":   {ConcisenessCliche, "today"},
+	"tip of the iceberg":    {ConcisenessCliche, "a small part"},
+	"at the drop of a hat":  {ConcisenessCliche, "immediately"},
+	"in a nutshell":         {ConcisenessCliche, "in short"},
+	"few and far between":   {ConcisenessCliche, "rare"},
+	"cutting-edge":          {ConcisenessCliche, "latest"},
+	"cutting edge":          {ConcisenessCliche, "latest"},
+	"best of breed":         {ConcisenessCliche, "leading"},
+}
+
+// wordyPhrasePattern matches every phrase in wordyPhrases
+var wordyPhrasePattern = func() *regexp.Regexp {
+	phrases := make([]string, 0, len(wordyPhrases))
+	for p := range wordyPhrases {
+		phrases = append(phrases, p)
+	}
+	return phrasePattern(phrases)
+}()
+
+// phrasePattern matches any of the phrases as whole words, in any case and
+// with any spacing. Longer phrases come first so "at this point in time" wins
+// over shorter overlapping entries.
+func phrasePattern(phrases []string) *regexp.Regexp {
+	sorted := append([]string{}, phrases...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if len(sorted[i]) != len(sorted[j]) {
+			return len(sorted[i]) > len(sorted[j])
+		}
+		return sorted[i] < sorted[j]
+	})
+	parts := make([]string, len(sorted))
+	for i, p := range sorted {
+		parts[i] = strings.Join(strings.Fields(regexp.QuoteMeta(p)), `\s+`)
+	}
+	return regexp.MustCompile(`(?i)\b(?:` + strings.Join(parts, "|") + `)\b`)
+}
+
+// phraseKey normalizes a matched phrase for lookup
+func phraseKey(matched string) string {
+	return strings.Join(strings.Fields(strings.ToLower(matched)), " ")
+}
+
+// ConcisenessFinding is one cliché, filler phrase or wordy construction
+type ConcisenessFinding struct {
+	Text        string `json:"text"`
+	Position    int    `json:"position"` // Byte offset in the original text
+	Length      int    `json:"length"`
+	Kind        string `json:"kind"`        // cliche, filler or wordy
+	Replacement string `json:"replacement"` // Tighter wording; "" means cut the phrase
+	WordsSaved  int    `json:"words_saved"`
+}
+
+// ConcisenessAnalysis lists wordy phrases and rates how tight the text is
+type ConcisenessAnalysis struct {
+	Findings   []ConcisenessFinding `json:"findings"`
+	WordsSaved int                  `json:"words_saved"` // Words the replacements would remove
+	Score      float64              `json:"score"`       // 0-100; 100 when nothing can be tightened
+}
+
+// AnalyzeConciseness finds clichés, filler phrases and wordy constructions
+// and suggests tighter wording. The score drops 4 points for each percent of
+// the text's words the replacements would save, and 5 per cliché.
+func AnalyzeConciseness(text string) ConcisenessAnalysis {
+	analysis := ConcisenessAnalysis{Findings: []ConcisenessFinding{}, Score: 100}
+	cliches := 0
+	for _, m := range wordyPhrasePattern.FindAllStringIndex(text, -1) {
+		matched := text[m[0]:m[1]]
+		phrase := wordyPhrases[phraseKey(matched)]
+		if phrase.replacement != "" {
+			phrase.replacement = matchCase(matched, phrase.replacement) // "In order to" becomes "To"
+		}
+		finding := ConcisenessFinding{
+			Text:        matched,
+			Position:    m[0],
+			Length:      m[1] - m[0],
+			Kind:        phrase.kind,
+			Replacement: phrase.replacement,
+			WordsSaved:  max(len(strings.Fields(matched))-len(strings.Fields(phrase.replacement)), 0),
+		}
+		analysis.Findings = append(analysis.Findings, finding)
+		analysis.WordsSaved += finding.WordsSaved
+		if finding.Kind == ConcisenessCliche {
+			cliches++
+		}
+	}
+
+	words := len(strings.Fields(text))
+	if words > 0 {
+		saved := float64(analysis.WordsSaved) / float64(words) * 100
+		analysis.Score = math.Max(0, math.Round((100-saved*4-float64(cliches)*5)*100)/100)
+	}
+	return analysis
+}
+
+// concisenessSuggestion words the style suggestion for a finding
+func concisenessSuggestion(f ConcisenessFinding) StyleSuggestion {
+	suggestion := fmt.Sprintf("Replace %q with %q", f.Text, f.Replacement)
+	if f.Replacement == "" {
+		suggestion = fmt.Sprintf("Cut %q", f.Text)
+	}
+	reason := "Shorter wording says the same thing"
+	switch f.Kind {
+	case ConcisenessCliche:
+		reason = "Clichés are vague; say what you mean directly"
+	case ConcisenessFiller:
+		reason = "Filler adds words without adding meaning"
+	}
+	return StyleSuggestion{
+		Text:       f.Text,
+		Position:   f.Position,
+		Length:     f.Length,
+		Suggestion: suggestion,
+		Reason:     reason,
+		Kind:       f.Kind,
+	}
+}
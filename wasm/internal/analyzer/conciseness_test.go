@@ -0,0 +1,60 @@
+package analyzer
+
+import "testing"
+
+func TestAnalyzeConciseness(t *testing.T) {
+	text := "In order to ship on time, we basically need to think outside the box. " +
+		"Summarize the report at this point in time."
+	analysis := AnalyzeConciseness(text)
+
+	want := []struct {
+		text, kind, replacement string
+	}{
+		{"In order to", ConcisenessWordy, "To"},
+		{"basically", ConcisenessFiller, ""},
+		{"think outside the box", ConcisenessCliche, "think creatively"},
+		{"at this point in time", ConcisenessWordy, "now"},
+	}
+	if len(analysis.Findings) != len(want) {
+		t.Fatalf("expected %d findings, got %+v", len(want), analysis.Findings)
+	}
+	for i, w := range want {
+		f := analysis.Findings[i]
+		if f.Text != w.text || f.Kind != w.kind || f.Replacement != w.replacement {
+			t.Errorf("finding %d: expected %q (%s) -> %q, got %+v", i, w.text, w.kind, w.replacement, f)
+		}
+		if text[f.Position:f.Position+f.Length] != f.Text {
+			t.Errorf("finding %d: offsets do not point at %q", i, f.Text)
+		}
+	}
+	// 2 + 1 + 2 + 4 words saved out of 22
+	if analysis.WordsSaved != 9 {
+		t.Errorf("expected 9 words saved, got %d", analysis.WordsSaved)
+	}
+	if analysis.Score >= 50 {
+		t.Errorf("expected a low conciseness score, got %.2f", analysis.Score)
+	}
+
+	if tight := AnalyzeConciseness("Summarize the report in three bullet points."); len(tight.Findings) != 0 || tight.Score != 100 {
+		t.Errorf("expected tight text to score 100, got %+v", tight)
+	}
+}
+
+func TestConcisenessStyleSuggestions(t *testing.T) {
+	text := "The report was written by the team in order to explain the outage."
+	quality := assessQuality(text, defaultSpellChecker(), nil)
+
+	if len(quality.StyleSuggestions) != 2 {
+		t.Fatalf("expected a passive voice and a wordy suggestion, got %+v", quality.StyleSuggestions)
+	}
+	passive, wordy := quality.StyleSuggestions[0], quality.StyleSuggestions[1]
+	if passive.Kind != "passive_voice" || wordy.Kind != ConcisenessWordy {
+		t.Errorf("expected suggestions in text order, got %q then %q", passive.Kind, wordy.Kind)
+	}
+	if wordy.Suggestion != `Replace "in order to" with "to"` || wordy.Position != 35 {
+		t.Errorf("unexpected wordy suggestion %+v", wordy)
+	}
+	if quality.Conciseness.Score >= 100 {
+		t.Errorf("expected the conciseness score to drop, got %.2f", quality.Conciseness.Score)
+	}
+}
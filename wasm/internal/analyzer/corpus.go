@@ -0,0 +1,258 @@
+package analyzer
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+)
+
+// CorpusDocument is one prompt in a corpus, named by its file path or ID
+type CorpusDocument struct {
+	Name string `json:"name"`
+	Text string `json:"text"`
+}
+
+// CorpusRequest is the JSON body of POST /corpus: the documents and the
+// options each is analyzed with
+type CorpusRequest struct {
+	Documents []CorpusDocument `json:"documents"`
+	Options   AnalysisOptions  `json:"options,omitempty"`
+}
+
+// CorpusReport aggregates the analyses of a whole prompt library
+type CorpusReport struct {
+	DocumentCount     int                     `json:"document_count"`
+	FailedDocuments   []CorpusFailure         `json:"failed_documents"`
+	Scores            CorpusScoreStats        `json:"scores"`
+	GradeDistribution map[string]int          `json:"grade_distribution"`
+	WeakDimensions    []CorpusDimension       `json:"weak_dimensions"` // Most often weak first
+	SharedConcepts    []SharedConcept         `json:"shared_concepts"` // Concepts in two or more documents
+	Outliers          []CorpusOutlier         `json:"outliers"`
+	Similarity        CorpusSimilarity        `json:"similarity"`
+	Documents         []CorpusDocumentSummary `json:"documents"`
+}
+
+// CorpusScoreStats summarizes overall grade scores across documents
+type CorpusScoreStats struct {
+	Mean   float64 `json:"mean"`
+	Median float64 `json:"median"`
+	Min    float64 `json:"min"`
+	Max    float64 `json:"max"`
+	StdDev float64 `json:"std_dev"`
+}
+
+// CorpusDimension reports how a grade dimension fares across the corpus
+type CorpusDimension struct {
+	Dimension    string  `json:"dimension"`
+	WeakCount    int     `json:"weak_count"` // Documents scoring below 60
+	AverageScore float64 `json:"average_score"`
+}
+
+// SharedConcept is a key concept that recurs across documents
+type SharedConcept struct {
+	Concept       string   `json:"concept"`
+	DocumentCount int      `json:"document_count"`
+	Documents     []string `json:"documents"`
+}
+
+// CorpusOutlier is a document whose overall score is far from the corpus mean
+type CorpusOutlier struct {
+	Name   string  `json:"name"`
+	Score  float64 `json:"score"`
+	ZScore float64 `json:"z_score"`
+	Reason string  `json:"reason"`
+}
+
+// CorpusDocumentSummary is the per-document row of a corpus report
+type CorpusDocumentSummary struct {
+	Name      string   `json:"name"`
+	Score     float64  `json:"score"`
+	Grade     string   `json:"grade"`
+	WordCount int      `json:"word_count"`
+	WeakAreas []string `json:"weak_areas"`
+}
+
+// CorpusFailure records a document that could not be analyzed
+type CorpusFailure struct {
+	Name  string `json:"name"`
+	Error string `json:"error"`
+}
+
+// Corpus aggregation thresholds
+const (
+	weakDimensionScore  = 60  // Matches identifyStrengthsAndWeaknesses
+	outlierZScore       = 2.0 // Standard deviations from the mean
+	minOutlierDocuments = 5   // Below this a z-score says little
+	maxSharedConcepts   = 25
+)
+
+// AnalyzeCorpus analyzes every document with opts and aggregates the results,
+// so scores match /analyze for the same text and options. Documents that fail
+// are listed in FailedDocuments; cancellation stops the whole run.
+func AnalyzeCorpus(ctx context.Context, docs []CorpusDocument, opts AnalysisOptions) (*CorpusReport, error) {
+	report := &CorpusReport{
+		FailedDocuments:   []CorpusFailure{},
+		GradeDistribution: make(map[string]int),
+		Documents:         []CorpusDocumentSummary{},
+	}
+
+	dimensionTotals := make(map[string]float64)
+	dimensionWeak := make(map[string]int)
+	var dimensionOrder []string
+	conceptDocs := make(map[string][]string)
+	scores := []float64{}
+	analyzed := []CorpusDocument{}
+
+	for _, doc := range docs {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		result, err := Analyze(ctx, doc.Text, opts, AnalysisRun{})
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			report.FailedDocuments = append(report.FailedDocuments, CorpusFailure{Name: doc.Name, Error: err.Error()})
+			continue
+		}
+
+		grade := leadingGradeOf(result)
+		summary := CorpusDocumentSummary{
+			Name:      doc.Name,
+			Score:     grade.Overall.Score,
+			Grade:     grade.Overall.Grade,
+			WordCount: len(strings.Fields(doc.Text)),
+			WeakAreas: []string{},
+		}
+		for _, dim := range grade.Dimensions {
+			if _, seen := dimensionTotals[dim.Name]; !seen {
+				dimensionOrder = append(dimensionOrder, dim.Name)
+			}
+			dimensionTotals[dim.Name] += dim.Score
+			if dim.Score < weakDimensionScore {
+				dimensionWeak[dim.Name]++
+				summary.WeakAreas = append(summary.WeakAreas, dim.Name)
+			}
+		}
+
+		seenConcepts := make(map[string]bool)
+		for _, concept := range result.Ideas.KeyConcepts.Value {
+			name := strings.ToLower(concept.Concept)
+			if name == "" || seenConcepts[name] {
+				continue
+			}
+			seenConcepts[name] = true
+			conceptDocs[name] = append(conceptDocs[name], doc.Name)
+		}
+
+		report.GradeDistribution[summary.Grade]++
+		report.Documents = append(report.Documents, summary)
+		scores = append(scores, summary.Score)
+		analyzed = append(analyzed, doc)
+	}
+
+	report.DocumentCount = len(report.Documents)
+	report.Similarity = CompareCorpus(analyzed)
+	if report.DocumentCount == 0 {
+		report.WeakDimensions = []CorpusDimension{}
+		report.SharedConcepts = []SharedConcept{}
+		report.Outliers = []CorpusOutlier{}
+		return report, nil
+	}
+
+	report.Scores = scoreStats(scores)
+	report.WeakDimensions = make([]CorpusDimension, 0, len(dimensionOrder))
+	for _, name := range dimensionOrder {
+		report.WeakDimensions = append(report.WeakDimensions, CorpusDimension{
+			Dimension:    name,
+			WeakCount:    dimensionWeak[name],
+			AverageScore: dimensionTotals[name] / float64(report.DocumentCount),
+		})
+	}
+	sort.SliceStable(report.WeakDimensions, func(i, j int) bool {
+		a, b := report.WeakDimensions[i], report.WeakDimensions[j]
+		if a.WeakCount != b.WeakCount {
+			return a.WeakCount > b.WeakCount
+		}
+		return a.AverageScore < b.AverageScore
+	})
+
+	report.SharedConcepts = sharedConcepts(conceptDocs)
+	report.Outliers = scoreOutliers(report.Documents, report.Scores)
+	return report, nil
+}
+
+func scoreStats(scores []float64) CorpusScoreStats {
+	sorted := append([]float64(nil), scores...)
+	sort.Float64s(sorted)
+
+	sum := 0.0
+	for _, s := range sorted {
+		sum += s
+	}
+	mean := sum / float64(len(sorted))
+
+	variance := 0.0
+	for _, s := range sorted {
+		variance += (s - mean) * (s - mean)
+	}
+	variance /= float64(len(sorted))
+
+	median := sorted[len(sorted)/2]
+	if len(sorted)%2 == 0 {
+		median = (sorted[len(sorted)/2-1] + sorted[len(sorted)/2]) / 2
+	}
+
+	return CorpusScoreStats{
+		Mean:   mean,
+		Median: median,
+		Min:    sorted[0],
+		Max:    sorted[len(sorted)-1],
+		StdDev: math.Sqrt(variance),
+	}
+}
+
+// sharedConcepts keeps concepts found in at least two documents, most widespread first
+func sharedConcepts(conceptDocs map[string][]string) []SharedConcept {
+	shared := []SharedConcept{}
+	for concept, docs := range conceptDocs {
+		if len(docs) < 2 {
+			continue
+		}
+		shared = append(shared, SharedConcept{Concept: concept, DocumentCount: len(docs), Documents: docs})
+	}
+	sort.Slice(shared, func(i, j int) bool {
+		if shared[i].DocumentCount != shared[j].DocumentCount {
+			return shared[i].DocumentCount > shared[j].DocumentCount
+		}
+		return shared[i].Concept < shared[j].Concept
+	})
+	if len(shared) > maxSharedConcepts {
+		shared = shared[:maxSharedConcepts]
+	}
+	return shared
+}
+
+// scoreOutliers flags documents more than outlierZScore deviations from the mean
+func scoreOutliers(docs []CorpusDocumentSummary, stats CorpusScoreStats) []CorpusOutlier {
+	outliers := []CorpusOutlier{}
+	if len(docs) < minOutlierDocuments || stats.StdDev == 0 {
+		return outliers
+	}
+	for _, doc := range docs {
+		z := (doc.Score - stats.Mean) / stats.StdDev
+		if math.Abs(z) < outlierZScore {
+			continue
+		}
+		reason := "scores far above the rest of the corpus"
+		if z < 0 {
+			reason = "scores far below the rest of the corpus"
+		}
+		outliers = append(outliers, CorpusOutlier{Name: doc.Name, Score: doc.Score, ZScore: z, Reason: reason})
+	}
+	sort.Slice(outliers, func(i, j int) bool {
+		return math.Abs(outliers[i].ZScore) > math.Abs(outliers[j].ZScore)
+	})
+	return outliers
+}
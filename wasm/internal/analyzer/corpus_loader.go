@@ -0,0 +1,91 @@
+//go:build !js
+
+package analyzer
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// corpusExtensions are the file types read as prompts from a directory or zip
+var corpusExtensions = map[string]bool{".txt": true, ".md": true, ".prompt": true}
+
+// maxCorpusFileBytes caps a single prompt file; larger files are skipped
+const maxCorpusFileBytes = 1 << 20
+
+// LoadCorpusDir reads every .txt, .md and .prompt file under root, named by relative path
+func LoadCorpusDir(root string) ([]CorpusDocument, error) {
+	docs := []CorpusDocument{}
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !corpusExtensions[strings.ToLower(filepath.Ext(p))] {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if info.Size() > maxCorpusFileBytes {
+			return nil
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		name, err := filepath.Rel(root, p)
+		if err != nil {
+			name = p
+		}
+		docs = append(docs, CorpusDocument{Name: filepath.ToSlash(name), Text: string(data)})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("load corpus %s: %w", root, err)
+	}
+	return docs, nil
+}
+
+// LoadCorpusZip reads prompt files from a zip archive. maxTotalBytes bounds the
+// uncompressed size so a small upload cannot expand without limit.
+func LoadCorpusZip(r io.ReaderAt, size, maxTotalBytes int64) ([]CorpusDocument, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("invalid corpus zip: %w", err)
+	}
+
+	docs := []CorpusDocument{}
+	var total int64
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() || !corpusExtensions[strings.ToLower(path.Ext(f.Name))] {
+			continue
+		}
+		if f.UncompressedSize64 > maxCorpusFileBytes {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("open %s: %w", f.Name, err)
+		}
+		data, err := io.ReadAll(io.LimitReader(rc, maxCorpusFileBytes+1))
+		rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", f.Name, err)
+		}
+		total += int64(len(data))
+		if maxTotalBytes > 0 && total > maxTotalBytes {
+			return nil, fmt.Errorf("corpus zip expands beyond %s", formatBytes(maxTotalBytes))
+		}
+		docs = append(docs, CorpusDocument{Name: f.Name, Text: string(data)})
+	}
+	sort.Slice(docs, func(i, j int) bool { return docs[i].Name < docs[j].Name })
+	return docs, nil
+}
@@ -0,0 +1,96 @@
+package analyzer
+
+import (
+	"context"
+	"testing"
+)
+
+func TestAnalyzeCorpus(t *testing.T) {
+	docs := []CorpusDocument{
+		{Name: "poem.txt", Text: "Write a poem."},
+		{Name: "api.md", Text: "Build a REST API for user accounts with authentication. Write integration tests for the API and deploy it to staging by Friday."},
+		{Name: "orders.md", Text: "Build a REST API for orders. Add tests for the order API."},
+	}
+	report, err := AnalyzeCorpus(context.Background(), docs, AnalysisOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.DocumentCount != 3 || len(report.Documents) != 3 {
+		t.Fatalf("expected 3 analyzed documents, got %d", report.DocumentCount)
+	}
+	total := 0
+	for _, n := range report.GradeDistribution {
+		total += n
+	}
+	if total != 3 {
+		t.Errorf("expected grade distribution to cover 3 documents, got %d", total)
+	}
+	if len(report.WeakDimensions) != 8 {
+		t.Errorf("expected all 8 grade dimensions, got %d", len(report.WeakDimensions))
+	}
+	if report.Scores.Min > report.Scores.Median || report.Scores.Median > report.Scores.Max {
+		t.Errorf("inconsistent score stats: %+v", report.Scores)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := AnalyzeCorpus(ctx, docs, AnalysisOptions{}); err == nil {
+		t.Error("expected a canceled context to stop the corpus run")
+	}
+}
+
+// TestAnalyzeCorpusOptions checks that documents are graded with the
+// caller's options, matching Analyze for the same text
+func TestAnalyzeCorpusOptions(t *testing.T) {
+	docs := []CorpusDocument{{Name: "api.md", Text: "Build a REST API for orders. Add tests for the order API."}}
+	opts := AnalysisOptions{Grader: GraderModern}
+	report, err := AnalyzeCorpus(context.Background(), docs, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := Analyze(context.Background(), docs[0].Text, opts, AnalysisRun{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := report.Documents[0]; got.Score != result.Grades.Score || got.Grade != result.Grades.Grade {
+		t.Errorf("corpus graded %v %s, /analyze %v %s", got.Score, got.Grade, result.Grades.Score, result.Grades.Grade)
+	}
+	if len(report.WeakDimensions) != 6 {
+		t.Errorf("expected the modern engine's 6 dimensions, got %d", len(report.WeakDimensions))
+	}
+}
+
+func TestScoreOutliers(t *testing.T) {
+	docs := []CorpusDocumentSummary{}
+	scores := []float64{}
+	for i, s := range []float64{70, 71, 69, 70, 72, 68, 70, 71, 69, 10} {
+		docs = append(docs, CorpusDocumentSummary{Name: string(rune('a' + i)), Score: s})
+		scores = append(scores, s)
+	}
+	outliers := scoreOutliers(docs, scoreStats(scores))
+	if len(outliers) != 1 || outliers[0].Name != "j" || outliers[0].ZScore >= 0 {
+		t.Errorf("expected only the low-scoring document as an outlier, got %+v", outliers)
+	}
+}
+
+func TestCompareCorpus(t *testing.T) {
+	docs := []CorpusDocument{
+		{Name: "a", Text: "Build a REST API for user accounts with login, logout and password reset endpoints."},
+		{Name: "b", Text: "Build a REST API for user accounts with login, logout and password reset endpoints please."},
+		{Name: "c", Text: "Write a short poem about autumn leaves falling in the quiet forest."},
+		{Name: "d", Text: ""},
+	}
+	sim := CompareCorpus(docs)
+	if len(sim.Matrix) != 4 || sim.Matrix[0][1] != sim.Matrix[1][0] {
+		t.Fatalf("expected a symmetric 4x4 matrix, got %v", sim.Matrix)
+	}
+	if len(sim.NearDuplicates) != 1 || sim.NearDuplicates[0].A != "a" || sim.NearDuplicates[0].B != "b" {
+		t.Errorf("expected a and b as the only near duplicates, got %+v", sim.NearDuplicates)
+	}
+	if sim.Assignments[0] != sim.Assignments[1] || sim.Assignments[0] == sim.Assignments[2] {
+		t.Errorf("expected a and b clustered apart from c, got %v", sim.Assignments)
+	}
+	if sim.Matrix[2][3] != 0 {
+		t.Errorf("expected an empty document to match nothing, got %v", sim.Matrix[2][3])
+	}
+}
@@ -0,0 +1,189 @@
+package analyzer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+// Dataset formats
+const (
+	DatasetRecords = "records" // One flat record per prompt with every score
+	DatasetChat    = "chat"    // Chat fine-tuning messages: prompt in, rewrite out
+)
+
+// datasetSystemPrompt is the system message of chat-format examples
+const datasetSystemPrompt = "Rewrite the user's prompt so it is clearer, more specific and easier to act on. Keep the author's intent and facts."
+
+// DatasetExample is one stored analysis to export, with the response it
+// produced and that response's evaluation when there is one
+type DatasetExample struct {
+	ID         string              `json:"id,omitempty"`
+	Prompt     string              `json:"prompt"`
+	Result     *CombinedResult     `json:"-"` // Analysis of Prompt; graded on export when nil
+	Tags       AnalysisTags        `json:"tags"`
+	Response   string              `json:"response,omitempty"`
+	Evaluation *ResponseEvaluation `json:"-"` // Evaluated on export when nil and Response is set
+}
+
+// DatasetOptions selects the format and which examples make it into a dataset
+type DatasetOptions struct {
+	Format   string   // DatasetRecords (the default) or DatasetChat
+	Rewriter Rewriter // Produces the rewritten prompt; nil uses the rule-based rewriter
+	// MinImprovement drops examples whose rewrite gains fewer points; chat
+	// datasets always drop rewrites that do not improve the score
+	MinImprovement float64
+}
+
+// DatasetResponseScores are the evaluation scores of a prompt's response
+type DatasetResponseScores struct {
+	Score            float64 `json:"score"`
+	Grade            string  `json:"grade"`
+	TaskCoverage     float64 `json:"task_coverage"`
+	QuestionCoverage float64 `json:"question_coverage"`
+	FormatCompliance float64 `json:"format_compliance"`
+}
+
+// DatasetRecord is one line of a records dataset
+type DatasetRecord struct {
+	ID             string                 `json:"id,omitempty"`
+	Prompt         string                 `json:"prompt"`
+	PromptType     string                 `json:"prompt_type"`
+	Score          float64                `json:"score"`
+	Grade          string                 `json:"grade"`
+	Dimensions     map[string]float64     `json:"dimensions"`  // Dimension scores by snake_case name
+	Suggestions    []string               `json:"suggestions"` // Rule IDs of the grade's suggestions
+	Rewrite        string                 `json:"rewrite,omitempty"`
+	RewriteScore   float64                `json:"rewrite_score,omitempty"`
+	Response       string                 `json:"response,omitempty"`
+	ResponseScores *DatasetResponseScores `json:"response_scores,omitempty"`
+	Tags           AnalysisTags           `json:"tags"`
+}
+
+// DatasetMessage is one chat turn of a chat-format example
+type DatasetMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// DatasetChatExample is one line of a chat dataset, in the messages shape
+// chat fine-tuning APIs accept
+type DatasetChatExample struct {
+	Messages []DatasetMessage `json:"messages"`
+}
+
+// DatasetSummary counts what an export wrote and skipped
+type DatasetSummary struct {
+	Written int `json:"written"`
+	Skipped int `json:"skipped"` // Examples whose rewrite did not improve enough, or failed
+}
+
+// ExportDataset writes examples to w as JSON Lines. Each prompt is graded
+// (unless its Result is given), rewritten and re-graded, and each response
+// evaluated (unless its Evaluation is given). Cancellation stops the export.
+func ExportDataset(ctx context.Context, w io.Writer, examples []DatasetExample, opts DatasetOptions) (DatasetSummary, error) {
+	var summary DatasetSummary
+	switch opts.Format {
+	case "":
+		opts.Format = DatasetRecords
+	case DatasetRecords, DatasetChat:
+	default:
+		return summary, fmt.Errorf("unknown dataset format %q (expected %q or %q)", opts.Format, DatasetRecords, DatasetChat)
+	}
+	if opts.Rewriter == nil {
+		opts.Rewriter = NewRuleBasedRewriter()
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	for _, ex := range examples {
+		if err := ctx.Err(); err != nil {
+			return summary, err
+		}
+		record, err := datasetRecord(ctx, ex, opts.Rewriter)
+		if err != nil {
+			if ctx.Err() != nil {
+				return summary, ctx.Err()
+			}
+			return summary, fmt.Errorf("example %q: %w", ex.ID, err)
+		}
+
+		gain := record.RewriteScore - record.Score
+		improved := record.Rewrite != "" && gain > 0 && gain >= opts.MinImprovement
+		if opts.MinImprovement > 0 && !improved || opts.Format == DatasetChat && !improved {
+			summary.Skipped++
+			continue
+		}
+
+		var line interface{} = record
+		if opts.Format == DatasetChat {
+			line = DatasetChatExample{Messages: []DatasetMessage{
+				{Role: "system", Content: datasetSystemPrompt},
+				{Role: "user", Content: record.Prompt},
+				{Role: "assistant", Content: record.Rewrite},
+			}}
+		}
+		if err := enc.Encode(line); err != nil {
+			return summary, err
+		}
+		summary.Written++
+	}
+	return summary, nil
+}
+
+// datasetRecord grades, rewrites and evaluates one example. A failed
+// rewrite leaves Rewrite empty rather than failing the example.
+func datasetRecord(ctx context.Context, ex DatasetExample, rewriter Rewriter) (DatasetRecord, error) {
+	// The record takes the leading engine's grade; the rewriters work from
+	// the classic one
+	var grade *PromptGrade
+	var summary GradeSummary
+	if ex.Result != nil && len(ex.Result.Grades.Results) > 0 {
+		grade = classicGrade(ex.Result, ex.Prompt)
+		summary = ex.Result.Grades.Results[0]
+	} else {
+		grade = GradePromptText(ex.Prompt)
+		summary = grade.Summary()
+	}
+
+	record := DatasetRecord{
+		ID:          ex.ID,
+		Prompt:      ex.Prompt,
+		PromptType:  summary.PromptType,
+		Score:       summary.Score,
+		Grade:       summary.Grade,
+		Dimensions:  summary.Dimensions,
+		Suggestions: summary.Suggestions,
+		Response:    ex.Response,
+		Tags:        ex.Tags,
+	}
+
+	rewritten, err := rewriter.Rewrite(ctx, ex.Prompt, grade)
+	if err != nil && ctx.Err() != nil {
+		return DatasetRecord{}, ctx.Err()
+	}
+	if err == nil && strings.TrimSpace(rewritten) != "" {
+		record.Rewrite = rewritten
+		record.RewriteScore = math.Round(GradePromptText(rewritten).OverallGrade.Score*10) / 10
+	}
+
+	if ex.Response != "" {
+		eval := ex.Evaluation
+		if eval == nil {
+			e := EvaluateResponse(ex.Prompt, ex.Response)
+			eval = &e
+		}
+		record.ResponseScores = &DatasetResponseScores{
+			Score:            eval.Score,
+			Grade:            eval.Grade,
+			TaskCoverage:     eval.TaskCoverage,
+			QuestionCoverage: eval.QuestionCoverage,
+			FormatCompliance: eval.FormatCompliance,
+		}
+	}
+	return record, nil
+}
+
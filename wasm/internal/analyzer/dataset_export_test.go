@@ -0,0 +1,83 @@
+package analyzer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// stubRewriter returns the same rewrite, or error, for every prompt
+type stubRewriter struct {
+	text string
+	err  error
+}
+
+func (stubRewriter) Name() string { return "stub" }
+
+func (s stubRewriter) Rewrite(ctx context.Context, text string, grade *PromptGrade) (string, error) {
+	return s.text, s.err
+}
+
+func TestExportDataset(t *testing.T) {
+	examples := []DatasetExample{
+		{ID: "sales", Prompt: evalPrompt, Response: "Revenue dropped in March because a large renewal slipped.", Tags: AnalysisTags{Project: "reports"}},
+		{ID: "vague", Prompt: "make it better"},
+	}
+
+	var buf bytes.Buffer
+	summary, err := ExportDataset(context.Background(), &buf, examples, DatasetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if summary.Written != 2 || summary.Skipped != 0 || len(lines) != 2 {
+		t.Fatalf("summary = %+v, %d lines", summary, len(lines))
+	}
+	var record DatasetRecord
+	if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+		t.Fatal(err)
+	}
+	if record.ID != "sales" || record.Prompt != evalPrompt || record.Grade == "" || record.Tags.Project != "reports" {
+		t.Errorf("record = %+v", record)
+	}
+	if len(record.Dimensions) != 8 || record.Dimensions["task_complexity"] == 0 {
+		t.Errorf("dimensions = %v", record.Dimensions)
+	}
+	if record.Rewrite == "" || record.RewriteScore == 0 {
+		t.Errorf("rewrite = %q (%.1f)", record.Rewrite, record.RewriteScore)
+	}
+	if record.ResponseScores == nil || record.ResponseScores.QuestionCoverage != 100 {
+		t.Errorf("response scores = %+v", record.ResponseScores)
+	}
+
+	buf.Reset()
+	summary, err = ExportDataset(context.Background(), &buf, examples, DatasetOptions{Format: DatasetChat, Rewriter: stubRewriter{err: errors.New("model unavailable")}})
+	if err != nil || summary.Written != 0 || summary.Skipped != 2 || buf.Len() != 0 {
+		t.Errorf("failed rewrites: summary %+v, err %v, output %q", summary, err, buf.String())
+	}
+
+	buf.Reset()
+	summary, err = ExportDataset(context.Background(), &buf, examples[1:], DatasetOptions{Format: DatasetChat, Rewriter: stubRewriter{text: evalPrompt}})
+	if err != nil || summary.Written != 1 {
+		t.Fatalf("chat: summary %+v, err %v", summary, err)
+	}
+	var chat DatasetChatExample
+	if err := json.Unmarshal(buf.Bytes(), &chat); err != nil {
+		t.Fatal(err)
+	}
+	if len(chat.Messages) != 3 || chat.Messages[1].Content != "make it better" || chat.Messages[2].Role != "assistant" {
+		t.Errorf("chat example = %+v", chat)
+	}
+
+	if _, err := ExportDataset(context.Background(), &buf, examples, DatasetOptions{Format: "csv"}); err == nil {
+		t.Error("unknown format accepted")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := ExportDataset(ctx, &buf, examples, DatasetOptions{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("canceled export err = %v", err)
+	}
+}
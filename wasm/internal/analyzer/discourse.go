@@ -0,0 +1,163 @@
+package analyzer
+
+import (
+	"math"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Discourse marker categories
+const (
+	DiscourseContrast = "contrast"
+	DiscourseCausal   = "causal"
+	DiscourseAdditive = "additive"
+	DiscourseTemporal = "temporal"
+)
+
+// discourseMarkers maps each transition word or phrase to its category
+var discourseMarkers = map[string]string{
+	"however": DiscourseContrast, "but": DiscourseContrast, "although": DiscourseContrast, "though": DiscourseContrast,
+	"yet": DiscourseContrast, "whereas": DiscourseContrast, "on the other hand": DiscourseContrast, "in contrast": DiscourseContrast,
+	"nevertheless": DiscourseContrast, "nonetheless": DiscourseContrast, "instead": DiscourseContrast, "otherwise": DiscourseContrast,
+	"conversely": DiscourseContrast, "despite": DiscourseContrast, "even so": DiscourseContrast, "rather than": DiscourseContrast,
+
+	"because": DiscourseCausal, "therefore": DiscourseCausal, "thus": DiscourseCausal, "hence": DiscourseCausal,
+	"consequently": DiscourseCausal, "as a result": DiscourseCausal, "so that": DiscourseCausal, "since": DiscourseCausal,
+	"due to": DiscourseCausal, "accordingly": DiscourseCausal, "for this reason": DiscourseCausal, "so": DiscourseCausal,
+
+	"also": DiscourseAdditive, "additionally": DiscourseAdditive, "furthermore": DiscourseAdditive, "moreover": DiscourseAdditive,
+	"in addition": DiscourseAdditive, "besides": DiscourseAdditive, "as well as": DiscourseAdditive, "likewise": DiscourseAdditive,
+	"similarly": DiscourseAdditive, "for example": DiscourseAdditive, "for instance": DiscourseAdditive, "in particular": DiscourseAdditive,
+
+	"first": DiscourseTemporal, "firstly": DiscourseTemporal, "second": DiscourseTemporal, "secondly": DiscourseTemporal,
+	"third": DiscourseTemporal, "then": DiscourseTemporal, "next": DiscourseTemporal, "after that": DiscourseTemporal,
+	"afterwards": DiscourseTemporal, "before": DiscourseTemporal, "finally": DiscourseTemporal, "meanwhile": DiscourseTemporal,
+	"subsequently": DiscourseTemporal, "later": DiscourseTemporal, "once": DiscourseTemporal, "lastly": DiscourseTemporal,
+}
+
+// sentenceInitialOnly are markers that are usually something else mid-sentence
+// ("the first item", "so many", "since 2020"), so they only count at the start
+var sentenceInitialOnly = map[string]bool{
+	"first": true, "second": true, "third": true, "next": true, "then": true, "so": true,
+	"yet": true, "since": true, "once": true, "before": true, "later": true, "instead": true,
+}
+
+// discourseMarkerPattern matches any marker, longest phrases first
+var discourseMarkerPattern = func() *regexp.Regexp {
+	markers := make([]string, 0, len(discourseMarkers))
+	for m := range discourseMarkers {
+		markers = append(markers, m)
+	}
+	return phrasePattern(markers)
+}()
+
+// DiscourseMarker is one transition word found in the text
+type DiscourseMarker struct {
+	Marker    string `json:"marker"`
+	Category  string `json:"category"`
+	Position  int    `json:"position"`  // Byte offset in the original text
+	Sentence  int    `json:"sentence"`  // Index of the containing sentence
+	Placement string `json:"placement"` // "initial" when it opens the sentence, else "medial"
+}
+
+// DiscourseAnalysis catalogs transitions between sentences and ideas
+type DiscourseAnalysis struct {
+	Markers              []DiscourseMarker `json:"markers"`
+	CategoryCounts       map[string]int    `json:"category_counts"`
+	TotalSentences       int               `json:"total_sentences"`
+	SentencesWithMarkers int               `json:"sentences_with_markers"`
+	Density              float64           `json:"density"`          // Markers per sentence
+	InitialRatio         float64           `json:"initial_ratio"`    // Share of markers that open their sentence
+	Coverage             float64           `json:"coverage"`         // Share of sentences after the first that carry a marker
+	Variety              float64           `json:"variety"`          // Share of the four categories used
+	TransitionScore      float64           `json:"transition_score"` // 0-100; 75 for texts under three sentences
+}
+
+// AnalyzeDiscourse finds discourse markers sentence by sentence and scores how
+// well the text signals the relations between its sentences. About 40% of
+// sentences carrying a marker, spread over several categories, scores best.
+func AnalyzeDiscourse(text string) DiscourseAnalysis {
+	analysis := DiscourseAnalysis{
+		Markers:        []DiscourseMarker{},
+		CategoryCounts: map[string]int{DiscourseContrast: 0, DiscourseCausal: 0, DiscourseAdditive: 0, DiscourseTemporal: 0},
+	}
+
+	initial := 0
+	linked := 0 // Sentences after the first with a marker
+	for _, s := range sentenceSpanPattern.FindAllStringIndex(text, -1) {
+		sentence := text[s[0]:s[1]]
+		if !strings.ContainsFunc(sentence, unicode.IsLetter) {
+			continue // Blank or a bare list number such as "1."
+		}
+		index := analysis.TotalSentences
+		analysis.TotalSentences++
+		opening := len(sentence) - len(strings.TrimLeft(sentence, " \t-*•>0123456789.)"))
+
+		found := false
+		for _, m := range discourseMarkerPattern.FindAllStringIndex(sentence, -1) {
+			marker := phraseKey(sentence[m[0]:m[1]])
+			placement := "medial"
+			if m[0] <= opening {
+				placement = "initial"
+			}
+			if sentenceInitialOnly[marker] && placement != "initial" {
+				continue
+			}
+			analysis.Markers = append(analysis.Markers, DiscourseMarker{
+				Marker:    marker,
+				Category:  discourseMarkers[marker],
+				Position:  s[0] + m[0],
+				Sentence:  index,
+				Placement: placement,
+			})
+			analysis.CategoryCounts[discourseMarkers[marker]]++
+			if placement == "initial" {
+				initial++
+			}
+			found = true
+		}
+		if found {
+			analysis.SentencesWithMarkers++
+			if index > 0 {
+				linked++
+			}
+		}
+	}
+
+	if analysis.TotalSentences > 0 {
+		analysis.Density = float64(len(analysis.Markers)) / float64(analysis.TotalSentences)
+	}
+	if len(analysis.Markers) > 0 {
+		analysis.InitialRatio = float64(initial) / float64(len(analysis.Markers))
+	}
+	if analysis.TotalSentences > 1 {
+		analysis.Coverage = float64(linked) / float64(analysis.TotalSentences-1)
+	}
+	used := 0
+	for _, n := range analysis.CategoryCounts {
+		if n > 0 {
+			used++
+		}
+	}
+	analysis.Variety = float64(used) / float64(len(analysis.CategoryCounts))
+
+	analysis.TransitionScore = 75
+	if analysis.TotalSentences >= 3 {
+		analysis.TransitionScore = 50 + 40*math.Min(analysis.Coverage/0.4, 1) + 10*analysis.Variety
+		// Markers on nearly every sentence read as padding
+		if analysis.Coverage > 0.8 {
+			analysis.TransitionScore -= 10
+		}
+	}
+	return analysis
+}
+
+// discourseCoherence turns the analysis into the 0-1 coherence score used by
+// quality assessment; a single sentence needs no transitions
+func discourseCoherence(d DiscourseAnalysis) float64 {
+	if d.TotalSentences <= 1 {
+		return 1.0
+	}
+	return 0.7*math.Min(d.Coverage/0.4, 1) + 0.3*d.Variety
+}
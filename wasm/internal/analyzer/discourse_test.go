@@ -0,0 +1,63 @@
+package analyzer
+
+import (
+	"math"
+	"testing"
+)
+
+func TestAnalyzeDiscourse(t *testing.T) {
+	text := "First, load the orders table. The first row is a header, so skip it. " +
+		"However, keep the totals row because finance needs it. " +
+		"Then export the result. In addition, write a short summary."
+	got := AnalyzeDiscourse(text)
+
+	want := []struct{ marker, category, placement string }{
+		{"first", DiscourseTemporal, "initial"},
+		{"however", DiscourseContrast, "initial"},
+		{"because", DiscourseCausal, "medial"},
+		{"then", DiscourseTemporal, "initial"},
+		{"in addition", DiscourseAdditive, "initial"},
+	}
+	if len(got.Markers) != len(want) {
+		t.Fatalf("expected %d markers, got %+v", len(want), got.Markers)
+	}
+	for i, w := range want {
+		m := got.Markers[i]
+		if m.Marker != w.marker || m.Category != w.category || m.Placement != w.placement {
+			t.Errorf("marker %d: expected %+v, got %+v", i, w, m)
+		}
+	}
+	if got.TotalSentences != 5 || got.SentencesWithMarkers != 4 {
+		t.Errorf("expected markers in 4 of 5 sentences, got %d of %d", got.SentencesWithMarkers, got.TotalSentences)
+	}
+	if got.Coverage != 0.75 || got.Variety != 1 || got.Density != 1 || got.InitialRatio != 0.8 {
+		t.Errorf("unexpected ratios %+v", got)
+	}
+	if got.TransitionScore != 100 {
+		t.Errorf("expected full marks for varied transitions, got %v", got.TransitionScore)
+	}
+
+	spaced := AnalyzeDiscourse("The cache warmed up. As a\tresult,  on the  other hand, latency fell.")
+	if len(spaced.Markers) != 2 || spaced.Markers[0].Marker != "as a result" || spaced.Markers[1].Marker != "on the other hand" {
+		t.Errorf("expected phrases split by extra whitespace to match, got %+v", spaced.Markers)
+	}
+}
+
+func TestDiscourseFeedsScores(t *testing.T) {
+	flat := "Load the orders table. Skip the header row. Export the result. Write a summary."
+	linked := "Load the orders table. Then skip the header row. Export the result. Finally, write a summary because finance needs it."
+
+	flatScore, linkedScore := AnalyzeDiscourse(flat).TransitionScore, AnalyzeDiscourse(linked).TransitionScore
+	if flatScore != 50 || linkedScore <= flatScore {
+		t.Errorf("expected linked text to score above 50, got %v and %v", flatScore, linkedScore)
+	}
+	if got := calculateCoherenceScore(linked); math.Abs(got-(0.7+0.3*0.5)) > 1e-9 {
+		t.Errorf("expected coherence from coverage and variety, got %v", got)
+	}
+	if got := calculateCoherenceScore("One sentence only."); got != 1 {
+		t.Errorf("expected a single sentence to be fully coherent, got %v", got)
+	}
+	if got := AnalyzeDiscourse("1. Parse the file.\n2. Validate it.").TotalSentences; got != 2 {
+		t.Errorf("expected list numbers not to count as sentences, got %d", got)
+	}
+}
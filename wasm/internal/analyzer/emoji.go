@@ -0,0 +1,369 @@
+package analyzer
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Emoji is a Unicode emoji sequence or ASCII emoticon found in the text
+type Emoji struct {
+	Span
+	Name      string  `json:"name,omitempty"` // CLDR short name, e.g. "thumbs up"; empty for unlisted emoji
+	Category  string  `json:"category"`       // One of the EmojiCategory* values
+	Sentiment float64 `json:"sentiment"`      // -1 (negative) to 1 (positive)
+	Emoticon  bool    `json:"emoticon"`       // ASCII emoticon such as :) rather than Unicode emoji
+}
+
+// EmojiCount is how often one emoji appears; skin tones and variation
+// selectors are folded into the base emoji
+type EmojiCount struct {
+	Emoji     string  `json:"emoji"`
+	Name      string  `json:"name,omitempty"`
+	Category  string  `json:"category"`
+	Count     int     `json:"count"`
+	Sentiment float64 `json:"sentiment"`
+}
+
+// EmojiAnalysis groups every emoji occurrence with per-emoji counts and the
+// average sentiment they carry
+type EmojiAnalysis struct {
+	Occurrences []Emoji      `json:"occurrences"`
+	Frequency   []EmojiCount `json:"frequency"`
+	Sentiment   float64      `json:"sentiment"` // Mean sentiment of all occurrences; 0 when there are none
+}
+
+// Emoji categories, following the Unicode emoji groups
+const (
+	EmojiCategorySmileys    = "smileys_emotion"
+	EmojiCategoryPeople     = "people_body"
+	EmojiCategoryNature     = "animals_nature"
+	EmojiCategoryFood       = "food_drink"
+	EmojiCategoryTravel     = "travel_places"
+	EmojiCategoryActivities = "activities"
+	EmojiCategoryObjects    = "objects"
+	EmojiCategorySymbols    = "symbols"
+	EmojiCategoryFlags      = "flags"
+	EmojiCategoryEmoticon   = "emoticon"
+)
+
+type emojiInfo struct {
+	name      string
+	category  string
+	sentiment float64
+}
+
+// emojiTable names the emoji most common in prompts and chat, with a
+// sentiment weight for the ones that carry tone
+var emojiTable = map[string]emojiInfo{
+	"😀": {"grinning face", EmojiCategorySmileys, 0.8}, "😃": {"grinning face with big eyes", EmojiCategorySmileys, 0.8},
+	"😄": {"grinning face with smiling eyes", EmojiCategorySmileys, 0.8}, "😁": {"beaming face with smiling eyes", EmojiCategorySmileys, 0.8},
+	"😆": {"grinning squinting face", EmojiCategorySmileys, 0.7}, "😅": {"grinning face with sweat", EmojiCategorySmileys, 0.3},
+	"🤣": {"rolling on the floor laughing", EmojiCategorySmileys, 0.7}, "😂": {"face with tears of joy", EmojiCategorySmileys, 0.6},
+	"🙂": {"slightly smiling face", EmojiCategorySmileys, 0.4}, "😉": {"winking face", EmojiCategorySmileys, 0.4},
+	"😊": {"smiling face with smiling eyes", EmojiCategorySmileys, 0.8}, "😇": {"smiling face with halo", EmojiCategorySmileys, 0.6},
+	"🥰": {"smiling face with hearts", EmojiCategorySmileys, 0.9}, "😍": {"smiling face with heart-eyes", EmojiCategorySmileys, 0.9},
+	"🤩": {"star-struck", EmojiCategorySmileys, 0.9}, "😘": {"face blowing a kiss", EmojiCategorySmileys, 0.7},
+	"☺": {"smiling face", EmojiCategorySmileys, 0.6}, "😋": {"face savoring food", EmojiCategorySmileys, 0.6},
+	"😎": {"smiling face with sunglasses", EmojiCategorySmileys, 0.6}, "🤔": {"thinking face", EmojiCategorySmileys, 0},
+	"🤗": {"smiling face with open hands", EmojiCategorySmileys, 0.7}, "😐": {"neutral face", EmojiCategorySmileys, 0},
+	"😑": {"expressionless face", EmojiCategorySmileys, -0.2}, "😶": {"face without mouth", EmojiCategorySmileys, 0},
+	"🙄": {"face with rolling eyes", EmojiCategorySmileys, -0.5}, "😏": {"smirking face", EmojiCategorySmileys, 0.1},
+	"😬": {"grimacing face", EmojiCategorySmileys, -0.3}, "😌": {"relieved face", EmojiCategorySmileys, 0.5},
+	"😴": {"sleeping face", EmojiCategorySmileys, 0}, "😷": {"face with medical mask", EmojiCategorySmileys, -0.2},
+	"🤯": {"exploding head", EmojiCategorySmileys, -0.2}, "🥳": {"partying face", EmojiCategorySmileys, 0.9},
+	"😕": {"confused face", EmojiCategorySmileys, -0.4}, "😟": {"worried face", EmojiCategorySmileys, -0.5},
+	"🙁": {"slightly frowning face", EmojiCategorySmileys, -0.5}, "☹": {"frowning face", EmojiCategorySmileys, -0.6},
+	"😮": {"face with open mouth", EmojiCategorySmileys, 0}, "😲": {"astonished face", EmojiCategorySmileys, 0},
+	"😳": {"flushed face", EmojiCategorySmileys, -0.1}, "🥺": {"pleading face", EmojiCategorySmileys, -0.1},
+	"😢": {"crying face", EmojiCategorySmileys, -0.7}, "😭": {"loudly crying face", EmojiCategorySmileys, -0.7},
+	"😱": {"face screaming in fear", EmojiCategorySmileys, -0.7}, "😞": {"disappointed face", EmojiCategorySmileys, -0.7},
+	"😓": {"downcast face with sweat", EmojiCategorySmileys, -0.5}, "😩": {"weary face", EmojiCategorySmileys, -0.6},
+	"😫": {"tired face", EmojiCategorySmileys, -0.6}, "😤": {"face with steam from nose", EmojiCategorySmileys, -0.4},
+	"😡": {"enraged face", EmojiCategorySmileys, -0.9}, "😠": {"angry face", EmojiCategorySmileys, -0.8},
+	"🤬": {"face with symbols on mouth", EmojiCategorySmileys, -0.9}, "💀": {"skull", EmojiCategorySmileys, -0.3},
+	"💩": {"pile of poo", EmojiCategorySmileys, -0.4}, "🤡": {"clown face", EmojiCategorySmileys, -0.2},
+	"❤": {"red heart", EmojiCategorySmileys, 0.9}, "💔": {"broken heart", EmojiCategorySmileys, -0.8},
+	"💕": {"two hearts", EmojiCategorySmileys, 0.8}, "💖": {"sparkling heart", EmojiCategorySmileys, 0.8},
+	"💯": {"hundred points", EmojiCategorySmileys, 0.7}, "💥": {"collision", EmojiCategorySmileys, 0},
+	"💤": {"zzz", EmojiCategorySmileys, 0},
+
+	"👍": {"thumbs up", EmojiCategoryPeople, 0.7}, "👎": {"thumbs down", EmojiCategoryPeople, -0.7},
+	"👏": {"clapping hands", EmojiCategoryPeople, 0.7}, "🙌": {"raising hands", EmojiCategoryPeople, 0.8},
+	"🙏": {"folded hands", EmojiCategoryPeople, 0.4}, "👋": {"waving hand", EmojiCategoryPeople, 0.3},
+	"👌": {"OK hand", EmojiCategoryPeople, 0.5}, "✌": {"victory hand", EmojiCategoryPeople, 0.5},
+	"🤞": {"crossed fingers", EmojiCategoryPeople, 0.3}, "💪": {"flexed biceps", EmojiCategoryPeople, 0.6},
+	"👀": {"eyes", EmojiCategoryPeople, 0}, "🤷": {"person shrugging", EmojiCategoryPeople, -0.1},
+	"🤦": {"person facepalming", EmojiCategoryPeople, -0.5}, "👉": {"backhand index pointing right", EmojiCategoryPeople, 0},
+	"👇": {"backhand index pointing down", EmojiCategoryPeople, 0}, "☝": {"index pointing up", EmojiCategoryPeople, 0},
+
+	"🔥": {"fire", EmojiCategoryTravel, 0.5}, "⭐": {"star", EmojiCategoryTravel, 0.5},
+	"🌟": {"glowing star", EmojiCategoryTravel, 0.6}, "☀": {"sun", EmojiCategoryTravel, 0.4},
+	"⚡": {"high voltage", EmojiCategoryTravel, 0.1}, "🌈": {"rainbow", EmojiCategoryTravel, 0.5},
+	"🚀": {"rocket", EmojiCategoryTravel, 0.6}, "✈": {"airplane", EmojiCategoryTravel, 0},
+	"⏰": {"alarm clock", EmojiCategoryTravel, 0}, "⌛": {"hourglass done", EmojiCategoryTravel, 0},
+	"⏳": {"hourglass not done", EmojiCategoryTravel, 0}, "🏠": {"house", EmojiCategoryTravel, 0},
+
+	"🐛": {"bug", EmojiCategoryNature, -0.2}, "🌱": {"seedling", EmojiCategoryNature, 0.3},
+	"🐶": {"dog face", EmojiCategoryNature, 0.3}, "🐱": {"cat face", EmojiCategoryNature, 0.3},
+	"🌍": {"globe showing Europe-Africa", EmojiCategoryNature, 0}, "🍀": {"four leaf clover", EmojiCategoryNature, 0.4},
+
+	"☕": {"hot beverage", EmojiCategoryFood, 0.2}, "🍕": {"pizza", EmojiCategoryFood, 0.2},
+	"🍺": {"beer mug", EmojiCategoryFood, 0.3}, "🎂": {"birthday cake", EmojiCategoryFood, 0.6},
+
+	"🎉": {"party popper", EmojiCategoryActivities, 0.9}, "🎊": {"confetti ball", EmojiCategoryActivities, 0.8},
+	"✨": {"sparkles", EmojiCategoryActivities, 0.5}, "🎯": {"bullseye", EmojiCategoryActivities, 0.4},
+	"🏆": {"trophy", EmojiCategoryActivities, 0.7}, "🎁": {"wrapped gift", EmojiCategoryActivities, 0.5},
+
+	"💡": {"light bulb", EmojiCategoryObjects, 0.3}, "📌": {"pushpin", EmojiCategoryObjects, 0},
+	"📝": {"memo", EmojiCategoryObjects, 0}, "📈": {"chart increasing", EmojiCategoryObjects, 0.4},
+	"📉": {"chart decreasing", EmojiCategoryObjects, -0.4}, "🔧": {"wrench", EmojiCategoryObjects, 0},
+	"🔒": {"locked", EmojiCategoryObjects, 0}, "🔑": {"key", EmojiCategoryObjects, 0},
+	"💻": {"laptop", EmojiCategoryObjects, 0}, "📅": {"calendar", EmojiCategoryObjects, 0},
+	"📎": {"paperclip", EmojiCategoryObjects, 0}, "🔗": {"link", EmojiCategoryObjects, 0},
+	"📦": {"package", EmojiCategoryObjects, 0}, "🛠": {"hammer and wrench", EmojiCategoryObjects, 0},
+
+	"✅": {"check mark button", EmojiCategorySymbols, 0.5}, "✔": {"check mark", EmojiCategorySymbols, 0.4},
+	"❌": {"cross mark", EmojiCategorySymbols, -0.5}, "❗": {"red exclamation mark", EmojiCategorySymbols, -0.1},
+	"❓": {"red question mark", EmojiCategorySymbols, 0}, "⚠": {"warning", EmojiCategorySymbols, -0.3},
+	"🚫": {"prohibited", EmojiCategorySymbols, -0.4}, "⛔": {"no entry", EmojiCategorySymbols, -0.4},
+	"🔴": {"red circle", EmojiCategorySymbols, 0}, "🟢": {"green circle", EmojiCategorySymbols, 0},
+	"➡": {"right arrow", EmojiCategorySymbols, 0}, "🆕": {"NEW button", EmojiCategorySymbols, 0.1},
+	"🆗": {"OK button", EmojiCategorySymbols, 0.3}, "♻": {"recycling symbol", EmojiCategorySymbols, 0},
+}
+
+// emoticonTable gives ASCII emoticons a name and sentiment; forms with a
+// nose ("-") or a ";" wink are folded into these by emoticonKey
+var emoticonTable = map[string]emojiInfo{
+	":)": {"smile", EmojiCategoryEmoticon, 0.6}, ":]": {"smile", EmojiCategoryEmoticon, 0.6},
+	":D": {"grin", EmojiCategoryEmoticon, 0.8}, "xD": {"laughing", EmojiCategoryEmoticon, 0.7},
+	";)": {"wink", EmojiCategoryEmoticon, 0.4}, ":P": {"tongue out", EmojiCategoryEmoticon, 0.3},
+	":(": {"frown", EmojiCategoryEmoticon, -0.6}, ":[": {"frown", EmojiCategoryEmoticon, -0.6},
+	":'(": {"crying", EmojiCategoryEmoticon, -0.7}, ":/": {"skeptical", EmojiCategoryEmoticon, -0.3},
+	":|": {"straight face", EmojiCategoryEmoticon, 0}, ":O": {"surprise", EmojiCategoryEmoticon, 0},
+	"<3": {"heart", EmojiCategoryEmoticon, 0.8}, "</3": {"broken heart", EmojiCategoryEmoticon, -0.7},
+	"^_^": {"happy", EmojiCategoryEmoticon, 0.6},
+}
+
+// emoticonAnalysisPattern matches the emoticons in emoticonTable; emoticons
+// must follow a space or bracket, so "http://" and "a:b" lists do not match
+var emoticonAnalysisPattern = regexp.MustCompile(`(?:^|[\s(])([:;]'?-?[)\]DPpOo(\[/|\\]|[xX]D|</?3|\^_\^)`)
+
+const (
+	variationSelector = '\uFE0F'
+	zeroWidthJoiner   = '\u200D'
+	keycapMark        = '\u20E3'
+)
+
+// isPictographic reports runes that are emoji on their own: the supplementary
+// emoji blocks always, and BMP symbols when listed in emojiTable. Other BMP
+// symbols such as ★ only count when followed by the emoji variation selector.
+func isPictographic(r rune) bool {
+	return (r >= 0x1F000 && r <= 0x1FAFF && !isSkinTone(r)) || emojiTable[string(r)].category != ""
+}
+
+func isSkinTone(r rune) bool { return r >= 0x1F3FB && r <= 0x1F3FF }
+
+func isRegionalIndicator(r rune) bool { return r >= 0x1F1E6 && r <= 0x1F1FF }
+
+func isEmojiTag(r rune) bool { return r >= 0xE0020 && r <= 0xE007F }
+
+// emojiSequenceEnd returns the end of the emoji sequence starting at i, or i
+// when none starts there. Sequences keep their variation selectors, skin
+// tones, tags and zero-width-joined parts, so 👩🏽‍💻 is one emoji.
+func emojiSequenceEnd(text string, i int) int {
+	r, size := utf8.DecodeRuneInString(text[i:])
+	next := func(at int) (rune, int) {
+		if at >= len(text) {
+			return utf8.RuneError, 0
+		}
+		return utf8.DecodeRuneInString(text[at:])
+	}
+
+	if r == '#' || r == '*' || (r >= '0' && r <= '9') {
+		end := i + size
+		if v, n := next(end); v == variationSelector {
+			end += n
+		}
+		if k, n := next(end); k == keycapMark {
+			return end + n
+		}
+		return i
+	}
+	if isRegionalIndicator(r) {
+		if r2, n := next(i + size); isRegionalIndicator(r2) {
+			return i + size + n
+		}
+		return i
+	}
+
+	end := i + size
+	if v, _ := next(end); !isPictographic(r) && (r < 0x2000 || v != variationSelector) {
+		return i
+	}
+	for {
+		c, n := next(end)
+		switch {
+		case c == variationSelector || isSkinTone(c) || isEmojiTag(c):
+			end += n
+			continue
+		case c == zeroWidthJoiner:
+			if j, m := next(end + n); m > 0 && (isPictographic(j) || j >= 0x2000 && j <= 0x2BFF) {
+				end += n + m
+				continue
+			}
+		}
+		return end
+	}
+}
+
+// emojiKey folds skin tones and variation selectors so 👍🏽 counts as 👍
+func emojiKey(seq string) string {
+	return strings.Map(func(r rune) rune {
+		if r == variationSelector || isSkinTone(r) {
+			return -1
+		}
+		return r
+	}, seq)
+}
+
+// emojiSpans returns the byte ranges of Unicode emoji sequences in text
+func emojiSpans(text string) [][2]int {
+	var spans [][2]int
+	for i := 0; i < len(text); {
+		if end := emojiSequenceEnd(text, i); end > i {
+			spans = append(spans, [2]int{i, end})
+			i = end
+			continue
+		}
+		_, size := utf8.DecodeRuneInString(text[i:])
+		i += size
+	}
+	return spans
+}
+
+// lookupEmoji names a sequence, falling back to its first emoji for ZWJ
+// sequences and to the Unicode block for emoji not in the table
+func lookupEmoji(key string) emojiInfo {
+	if info, ok := emojiTable[key]; ok {
+		return info
+	}
+	first, _ := utf8.DecodeRuneInString(key)
+	if info, ok := emojiTable[string(first)]; ok {
+		return emojiInfo{category: info.category, sentiment: info.sentiment}
+	}
+	switch {
+	case isRegionalIndicator(first) || first == 0x1F3F4:
+		return emojiInfo{category: EmojiCategoryFlags}
+	case first >= 0x1F600 && first <= 0x1F64F:
+		return emojiInfo{category: EmojiCategorySmileys}
+	case first >= 0x1F400 && first <= 0x1F43F, first >= 0x1F330 && first <= 0x1F344:
+		return emojiInfo{category: EmojiCategoryNature}
+	case first >= 0x1F345 && first <= 0x1F37F:
+		return emojiInfo{category: EmojiCategoryFood}
+	case first >= 0x1F680 && first <= 0x1F6FF:
+		return emojiInfo{category: EmojiCategoryTravel}
+	case first >= 0x1F380 && first <= 0x1F3FA:
+		return emojiInfo{category: EmojiCategoryActivities}
+	case first >= 0x1F466 && first <= 0x1F487, first >= 0x1F90C && first <= 0x1F9DF:
+		return emojiInfo{category: EmojiCategoryPeople}
+	case first >= 0x1F4A0 && first <= 0x1F5FF:
+		return emojiInfo{category: EmojiCategoryObjects}
+	}
+	return emojiInfo{category: EmojiCategorySymbols}
+}
+
+// emoticonKey folds a nose and the lowercase forms: ":-)" → ":)", ":p" → ":P"
+func emoticonKey(emoticon string) string {
+	key := strings.Replace(emoticon, "-", "", 1)
+	switch key {
+	case ":p", ";p", ";P":
+		return ":P"
+	case ":o":
+		return ":O"
+	case "XD":
+		return "xD"
+	case ";(":
+		return ":("
+	case ":\\":
+		return ":/"
+	}
+	return key
+}
+
+// AnalyzeEmoji finds Unicode emoji (including skin-tone, flag, keycap and
+// ZWJ sequences) and ASCII emoticons, counting each and scoring their tone
+func AnalyzeEmoji(text string) EmojiAnalysis {
+	found := []Emoji{}
+	for _, s := range emojiSpans(text) {
+		info := lookupEmoji(emojiKey(text[s[0]:s[1]]))
+		found = append(found, Emoji{Span: newSpan(text, s[0], s[1]), Name: info.name, Category: info.category, Sentiment: info.sentiment})
+	}
+	for _, m := range emoticonAnalysisPattern.FindAllStringSubmatchIndex(text, -1) {
+		info, ok := emoticonTable[emoticonKey(text[m[2]:m[3]])]
+		if !ok || (m[3] < len(text) && isWordByte(text[m[3]])) {
+			continue
+		}
+		found = append(found, Emoji{Span: newSpan(text, m[2], m[3]), Name: info.name, Category: info.category, Sentiment: info.sentiment, Emoticon: true})
+	}
+	sort.SliceStable(found, func(i, j int) bool { return found[i].Start < found[j].Start })
+
+	analysis := EmojiAnalysis{Occurrences: found, Frequency: []EmojiCount{}}
+	index := map[string]int{}
+	total := 0.0
+	for _, e := range found {
+		key := emojiKey(e.Text)
+		if e.Emoticon {
+			key = emoticonKey(e.Text)
+		}
+		i, ok := index[key]
+		if !ok {
+			i = len(analysis.Frequency)
+			index[key] = i
+			analysis.Frequency = append(analysis.Frequency, EmojiCount{Emoji: key, Name: e.Name, Category: e.Category, Sentiment: e.Sentiment})
+		}
+		analysis.Frequency[i].Count++
+		total += e.Sentiment
+	}
+	sort.SliceStable(analysis.Frequency, func(i, j int) bool { return analysis.Frequency[i].Count > analysis.Frequency[j].Count })
+	if len(found) > 0 {
+		analysis.Sentiment = total / float64(len(found))
+	}
+	return analysis
+}
+
+// outsideEmoji applies f to the text between emoji sequences, copying the
+// sequences through untouched so their joiners and selectors survive cleaning
+func outsideEmoji(text string, f func(string) string) string {
+	spans := emojiSpans(text)
+	if len(spans) == 0 {
+		return f(text)
+	}
+	var b strings.Builder
+	last := 0
+	for _, s := range spans {
+		b.WriteString(f(text[last:s[0]]))
+		b.WriteString(text[s[0]:s[1]])
+		last = s[1]
+	}
+	b.WriteString(f(text[last:]))
+	return b.String()
+}
+
+// stripEmoji removes Unicode emoji sequences, leaving ASCII emoticons; the
+// collapse_whitespace cleaning step closes the gaps they leave
+func stripEmoji(text string) string {
+	spans := emojiSpans(text)
+	if len(spans) == 0 {
+		return text
+	}
+	var b strings.Builder
+	last := 0
+	for _, s := range spans {
+		b.WriteString(text[last:s[0]])
+		last = s[1]
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
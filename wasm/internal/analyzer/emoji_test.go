@@ -0,0 +1,57 @@
+package analyzer
+
+import "testing"
+
+func TestAnalyzeEmoji(t *testing.T) {
+	text := "Shipped it 🎉🎉 great work 👍🏽 :-) but the build broke 😭. Dev: 👩🏽‍💻, flag 🇯🇵, key 1️⃣, ★ and http://x.io/a:b"
+	got := AnalyzeEmoji(text)
+	want := []struct {
+		text, name, category string
+		emoticon             bool
+	}{
+		{"🎉", "party popper", EmojiCategoryActivities, false},
+		{"🎉", "party popper", EmojiCategoryActivities, false},
+		{"👍🏽", "thumbs up", EmojiCategoryPeople, false},
+		{":-)", "smile", EmojiCategoryEmoticon, true},
+		{"😭", "loudly crying face", EmojiCategorySmileys, false},
+		{"👩🏽‍💻", "", EmojiCategoryPeople, false},
+		{"🇯🇵", "", EmojiCategoryFlags, false},
+		{"1️⃣", "", EmojiCategorySymbols, false},
+	}
+	if len(got.Occurrences) != len(want) {
+		t.Fatalf("got %+v, want %d emoji", got.Occurrences, len(want))
+	}
+	for i, e := range got.Occurrences {
+		w := want[i]
+		if e.Text != w.text || e.Name != w.name || e.Category != w.category || e.Emoticon != w.emoticon {
+			t.Errorf("got %+v, want %+v", e, w)
+		}
+		if text[e.Start:e.End] != e.Text {
+			t.Errorf("span %d-%d does not cover %q", e.Start, e.End, e.Text)
+		}
+	}
+	if f := got.Frequency[0]; f.Emoji != "🎉" || f.Count != 2 {
+		t.Errorf("most frequent = %+v", f)
+	}
+	if got.Sentiment <= 0 {
+		t.Errorf("sentiment = %v, want positive", got.Sentiment)
+	}
+
+	tokens := TokenizeText("Thanks 😡😡")
+	if s := tokens.SemanticFeatures.SentimentScores; s.Emoji >= 0 || s.Overall >= 0 {
+		t.Errorf("angry emoji did not pull sentiment down: %+v", s)
+	}
+}
+
+func TestCleanTextEmoji(t *testing.T) {
+	text := "Deploy  👩🏽‍💻 now 🚀\x07!"
+	if got := cleanText(text, nil); got != "Deploy 👩🏽‍💻 now 🚀!" {
+		t.Errorf("preserved = %q", got)
+	}
+	if got := cleanText(text, (AnalysisOptions{StripEmoji: true}).cleaningSteps()); got != "Deploy now !" {
+		t.Errorf("stripped = %q", got)
+	}
+	if got := normalizeText("Deploy 👩🏽‍💻"); got != "Deploy 👩🏽‍💻" {
+		t.Errorf("normalization broke the ZWJ sequence: %q", got)
+	}
+}
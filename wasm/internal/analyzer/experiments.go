@@ -0,0 +1,408 @@
+package analyzer
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Common outcome metrics; any other metric name is accepted too
+const (
+	MetricTaskSuccess = "task_success" // 1 when the task succeeded, 0 when it failed
+	MetricUserRating  = "user_rating"  // e.g. a 1-5 star rating
+)
+
+// Experiment limits
+const (
+	maxExperimentVariants  = 26 // Variants are lettered A-Z
+	minCorrelationSamples  = 3
+	overallMetricDimension = "overall"
+)
+
+// ErrExperimentNotFound is returned for unknown experiment IDs
+var ErrExperimentNotFound = errors.New("experiment not found")
+
+// ExperimentVariant is one prompt version under test, graded when registered
+type ExperimentVariant struct {
+	ID         string             `json:"id"` // "A", "B", ...
+	Name       string             `json:"name"`
+	Prompt     string             `json:"prompt"`
+	Score      float64            `json:"score"`
+	Grade      string             `json:"grade"`
+	Dimensions map[string]float64 `json:"dimensions"`
+	PromptType PromptType         `json:"prompt_type"` // As classified by the modern grader
+
+	modern map[string]float64 // Modern grader dimension scores, for weight learning
+}
+
+// Experiment compares prompt variants against outcomes measured in production
+type Experiment struct {
+	ID          string              `json:"id"`
+	Name        string              `json:"name"`
+	Description string              `json:"description,omitempty"`
+	CreatedAt   time.Time           `json:"created_at"`
+	Variants    []ExperimentVariant `json:"variants"`
+	Outcomes    int                 `json:"outcomes"` // Number recorded so far
+}
+
+// ExperimentVariantRequest names and supplies one variant's prompt
+type ExperimentVariantRequest struct {
+	Name   string `json:"name"`
+	Prompt string `json:"prompt"`
+}
+
+// CreateExperimentRequest registers an experiment and its variants
+type CreateExperimentRequest struct {
+	Name        string                     `json:"name"`
+	Description string                     `json:"description,omitempty"`
+	Variants    []ExperimentVariantRequest `json:"variants"`
+}
+
+// ExperimentOutcome is one external measurement of a variant, e.g. whether a
+// conversation using it succeeded or how a user rated it
+type ExperimentOutcome struct {
+	Variant    string    `json:"variant"` // Variant ID
+	Metric     string    `json:"metric"`
+	Value      float64   `json:"value"`
+	RecordedAt time.Time `json:"recorded_at,omitempty"` // Defaults to the time it is recorded
+}
+
+// OutcomesRequest records a batch of outcomes for one experiment
+type OutcomesRequest struct {
+	Outcomes []ExperimentOutcome `json:"outcomes"`
+}
+
+// MetricSummary aggregates one metric's outcomes
+type MetricSummary struct {
+	Count int     `json:"count"`
+	Mean  float64 `json:"mean"`
+}
+
+// VariantResults summarizes the outcomes of one variant
+type VariantResults struct {
+	Variant string                   `json:"variant"`
+	Name    string                   `json:"name"`
+	Score   float64                  `json:"score"`
+	Metrics map[string]MetricSummary `json:"metrics"`
+}
+
+// DimensionCorrelation is the Pearson correlation between a grade dimension
+// (or "overall") and an outcome metric across recorded outcomes
+type DimensionCorrelation struct {
+	Metric      string  `json:"metric"`
+	Dimension   string  `json:"dimension"`
+	Correlation float64 `json:"correlation"` // -1 to 1
+	Samples     int     `json:"samples"`
+}
+
+// ExperimentReport shows how each variant performed and which dimensions
+// predict the outcomes. Correlations are grouped by metric, strongest first.
+type ExperimentReport struct {
+	Experiment   string                 `json:"experiment,omitempty"` // Empty when pooled across experiments
+	Variants     []VariantResults       `json:"variants,omitempty"`
+	Correlations []DimensionCorrelation `json:"correlations"`
+	Predictors   map[string]string      `json:"predictors"` // Strongest dimension per metric
+}
+
+// Experiments is an in-memory registry of experiments and their outcomes,
+// safe for concurrent use
+type Experiments struct {
+	mu          sync.Mutex
+	next        int
+	order       []string
+	experiments map[string]*experimentState
+}
+
+// experimentState is an experiment with its recorded outcomes
+type experimentState struct {
+	experiment Experiment
+	outcomes   []ExperimentOutcome
+}
+
+// NewExperiments creates an empty experiment registry
+func NewExperiments() *Experiments {
+	return &Experiments{experiments: make(map[string]*experimentState)}
+}
+
+// Create grades each variant and registers the experiment
+func (e *Experiments) Create(req CreateExperimentRequest) (Experiment, error) {
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return Experiment{}, errors.New("experiment name is required")
+	}
+	if len(req.Variants) < 2 || len(req.Variants) > maxExperimentVariants {
+		return Experiment{}, fmt.Errorf("an experiment needs 2 to %d variants, got %d", maxExperimentVariants, len(req.Variants))
+	}
+
+	grader := NewModernPromptGrader()
+	variants := make([]ExperimentVariant, len(req.Variants))
+	for i, v := range req.Variants {
+		if strings.TrimSpace(v.Prompt) == "" {
+			return Experiment{}, fmt.Errorf("variant %d has no prompt", i+1)
+		}
+		id := string(rune('A' + i))
+		if v.Name == "" {
+			v.Name = "Variant " + id
+		}
+		in := AnalyzeForGrading(v.Prompt, NewPromptClassifier())
+		grade := gradeClassic(in, AnalysisOptions{})
+		modern := grader.grade(in)
+		variants[i] = ExperimentVariant{
+			ID:         id,
+			Name:       v.Name,
+			Prompt:     v.Prompt,
+			Score:      grade.OverallGrade.Score,
+			Grade:      grade.OverallGrade.Grade,
+			Dimensions: dimensionScores(grade),
+			PromptType: modern.Classification.GradingType(),
+			modern:     modernDimensionScores(modern.Dimensions),
+		}
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.next++
+	exp := Experiment{
+		ID:          fmt.Sprintf("exp-%d", e.next),
+		Name:        req.Name,
+		Description: req.Description,
+		CreatedAt:   time.Now().UTC(),
+		Variants:    variants,
+	}
+	e.experiments[exp.ID] = &experimentState{experiment: exp}
+	e.order = append(e.order, exp.ID)
+	return exp, nil
+}
+
+// Get returns an experiment, or ErrExperimentNotFound
+func (e *Experiments) Get(id string) (Experiment, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	state, ok := e.experiments[id]
+	if !ok {
+		return Experiment{}, ErrExperimentNotFound
+	}
+	return state.experiment, nil
+}
+
+// List returns every experiment in creation order
+func (e *Experiments) List() []Experiment {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	list := make([]Experiment, 0, len(e.order))
+	for _, id := range e.order {
+		list = append(list, e.experiments[id].experiment)
+	}
+	return list
+}
+
+// Record validates and stores outcomes for an experiment; a batch with any
+// invalid outcome records nothing
+func (e *Experiments) Record(id string, outcomes []ExperimentOutcome) error {
+	if len(outcomes) == 0 {
+		return errors.New("no outcomes given")
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	state, ok := e.experiments[id]
+	if !ok {
+		return ErrExperimentNotFound
+	}
+
+	now := time.Now().UTC()
+	valid := make([]ExperimentOutcome, len(outcomes))
+	for i, o := range outcomes {
+		o.Variant = strings.ToUpper(strings.TrimSpace(o.Variant))
+		o.Metric = strings.TrimSpace(o.Metric)
+		if state.variant(o.Variant) == nil {
+			return fmt.Errorf("outcome %d: unknown variant %q", i+1, o.Variant)
+		}
+		if o.Metric == "" {
+			return fmt.Errorf("outcome %d: metric is required", i+1)
+		}
+		if math.IsNaN(o.Value) || math.IsInf(o.Value, 0) {
+			return fmt.Errorf("outcome %d: value must be a finite number", i+1)
+		}
+		if o.RecordedAt.IsZero() {
+			o.RecordedAt = now
+		}
+		valid[i] = o
+	}
+	state.outcomes = append(state.outcomes, valid...)
+	state.experiment.Outcomes = len(state.outcomes)
+	return nil
+}
+
+// Report summarizes an experiment's variants and correlates their dimension
+// scores with its outcomes
+func (e *Experiments) Report(id string) (ExperimentReport, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	state, ok := e.experiments[id]
+	if !ok {
+		return ExperimentReport{}, ErrExperimentNotFound
+	}
+
+	report := ExperimentReport{Experiment: id}
+	for _, v := range state.experiment.Variants {
+		results := VariantResults{Variant: v.ID, Name: v.Name, Score: v.Score, Metrics: map[string]MetricSummary{}}
+		for _, o := range state.outcomes {
+			if o.Variant == v.ID {
+				s := results.Metrics[o.Metric]
+				s.Mean = (s.Mean*float64(s.Count) + o.Value) / float64(s.Count+1)
+				s.Count++
+				results.Metrics[o.Metric] = s
+			}
+		}
+		for metric, s := range results.Metrics {
+			s.Mean = math.Round(s.Mean*1000) / 1000
+			results.Metrics[metric] = s
+		}
+		report.Variants = append(report.Variants, results)
+	}
+	report.Correlations, report.Predictors = correlateOutcomes([]*experimentState{state})
+	return report, nil
+}
+
+// PooledReport correlates dimension scores with outcomes across every
+// experiment, the broadest view of which dimensions predict performance
+func (e *Experiments) PooledReport() ExperimentReport {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	states := make([]*experimentState, 0, len(e.order))
+	for _, id := range e.order {
+		states = append(states, e.experiments[id])
+	}
+	var report ExperimentReport
+	report.Correlations, report.Predictors = correlateOutcomes(states)
+	return report
+}
+
+// WeightSamples pairs every outcome of metric, across all experiments, with
+// its variant's modern dimension scores and prompt type
+func (e *Experiments) WeightSamples(metric string) []WeightSample {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	var samples []WeightSample
+	for _, id := range e.order {
+		state := e.experiments[id]
+		for _, o := range state.outcomes {
+			if o.Metric == metric {
+				v := state.variant(o.Variant)
+				samples = append(samples, WeightSample{PromptType: v.PromptType, Dimensions: v.modern, Outcome: o.Value})
+			}
+		}
+	}
+	return samples
+}
+
+// LearnWeights calibrates the modern grader's dimension weights on the
+// outcomes recorded for metric
+func (e *Experiments) LearnWeights(metric string, opts WeightLearningOptions) (LearnedWeights, error) {
+	learned, err := LearnDimensionWeights(e.WeightSamples(metric), opts)
+	learned.Metric = metric
+	return learned, err
+}
+
+// variant returns the variant with the given ID, or nil
+func (s *experimentState) variant(id string) *ExperimentVariant {
+	for i := range s.experiment.Variants {
+		if s.experiment.Variants[i].ID == id {
+			return &s.experiment.Variants[i]
+		}
+	}
+	return nil
+}
+
+// correlateOutcomes pairs each outcome with its variant's scores and computes
+// the Pearson correlation per metric and dimension. Pairs with fewer than
+// minCorrelationSamples outcomes or no variation are left out.
+func correlateOutcomes(states []*experimentState) ([]DimensionCorrelation, map[string]string) {
+	type sample struct {
+		scores map[string]float64
+		value  float64
+	}
+	byMetric := map[string][]sample{}
+	for _, state := range states {
+		for _, o := range state.outcomes {
+			v := state.variant(o.Variant)
+			scores := map[string]float64{overallMetricDimension: v.Score}
+			for name, score := range v.Dimensions {
+				scores[name] = score
+			}
+			byMetric[o.Metric] = append(byMetric[o.Metric], sample{scores, o.Value})
+		}
+	}
+
+	correlations := []DimensionCorrelation{}
+	for metric, samples := range byMetric {
+		if len(samples) < minCorrelationSamples {
+			continue
+		}
+		ys := make([]float64, len(samples))
+		for i, s := range samples {
+			ys[i] = s.value
+		}
+		for dim := range samples[0].scores {
+			xs := make([]float64, len(samples))
+			for i, s := range samples {
+				xs[i] = s.scores[dim]
+			}
+			if r, ok := pearson(xs, ys); ok {
+				correlations = append(correlations, DimensionCorrelation{
+					Metric:      metric,
+					Dimension:   dim,
+					Correlation: math.Round(r*1000) / 1000,
+					Samples:     len(samples),
+				})
+			}
+		}
+	}
+	sort.Slice(correlations, func(i, j int) bool {
+		a, b := correlations[i], correlations[j]
+		if a.Metric != b.Metric {
+			return a.Metric < b.Metric
+		}
+		if math.Abs(a.Correlation) != math.Abs(b.Correlation) {
+			return math.Abs(a.Correlation) > math.Abs(b.Correlation)
+		}
+		return a.Dimension < b.Dimension
+	})
+
+	predictors := map[string]string{}
+	for _, c := range correlations {
+		if _, ok := predictors[c.Metric]; !ok {
+			predictors[c.Metric] = c.Dimension
+		}
+	}
+	return correlations, predictors
+}
+
+// pearson returns the correlation coefficient of xs and ys, or false when
+// either has no variance
+func pearson(xs, ys []float64) (float64, bool) {
+	n := float64(len(xs))
+	var meanX, meanY float64
+	for i := range xs {
+		meanX += xs[i]
+		meanY += ys[i]
+	}
+	meanX /= n
+	meanY /= n
+
+	var cov, varX, varY float64
+	for i := range xs {
+		dx, dy := xs[i]-meanX, ys[i]-meanY
+		cov += dx * dy
+		varX += dx * dx
+		varY += dy * dy
+	}
+	if varX < 1e-12 || varY < 1e-12 {
+		return 0, false
+	}
+	return cov / math.Sqrt(varX*varY), true
+}
@@ -0,0 +1,91 @@
+package analyzer
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestPearson(t *testing.T) {
+	if r, ok := pearson([]float64{1, 2, 3}, []float64{2, 4, 6}); !ok || math.Abs(r-1) > 1e-9 {
+		t.Errorf("perfect positive: %v %v", r, ok)
+	}
+	if r, ok := pearson([]float64{1, 2, 3}, []float64{3, 2, 1}); !ok || math.Abs(r+1) > 1e-9 {
+		t.Errorf("perfect negative: %v %v", r, ok)
+	}
+	if _, ok := pearson([]float64{5, 5, 5}, []float64{1, 2, 3}); ok {
+		t.Error("constant input should have no correlation")
+	}
+}
+
+func TestExperiments(t *testing.T) {
+	exps := NewExperiments()
+	if _, err := exps.Create(CreateExperimentRequest{Name: "one", Variants: []ExperimentVariantRequest{{Prompt: "hi"}}}); err == nil {
+		t.Error("single-variant experiment accepted")
+	}
+	exp, err := exps.Create(CreateExperimentRequest{
+		Name: "support reply",
+		Variants: []ExperimentVariantRequest{
+			{Name: "terse", Prompt: "answer the ticket"},
+			{Name: "structured", Prompt: "## Goal\nReply to the customer's support ticket.\n\n## Context\nThe customer uses the Pro plan and reported a billing error.\n\n## Tasks\n1. Apologize for the error.\n2. Explain the refund steps.\n\n## Constraints\n- Under 120 words, friendly tone."},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp.ID != "exp-1" || exp.Variants[0].ID != "A" || exp.Variants[1].ID != "B" || len(exp.Variants[1].Dimensions) != 8 {
+		t.Fatalf("experiment = %+v", exp)
+	}
+	a, b := exp.Variants[0], exp.Variants[1]
+	if a.Score >= b.Score {
+		t.Fatalf("expected the structured variant to score higher: %.1f vs %.1f", a.Score, b.Score)
+	}
+
+	if err := exps.Record(exp.ID, []ExperimentOutcome{{Variant: "a", Metric: MetricTaskSuccess, Value: 1}, {Variant: "C", Metric: MetricTaskSuccess}}); err == nil {
+		t.Error("unknown variant accepted")
+	}
+	if err := exps.Record("exp-9", []ExperimentOutcome{{Variant: "A", Metric: MetricTaskSuccess}}); !errors.Is(err, ErrExperimentNotFound) {
+		t.Errorf("unknown experiment err = %v", err)
+	}
+	outcomes := []ExperimentOutcome{
+		{Variant: "a", Metric: MetricTaskSuccess, Value: 0},
+		{Variant: "A", Metric: MetricTaskSuccess, Value: 1},
+		{Variant: "A", Metric: MetricTaskSuccess, Value: 0},
+		{Variant: "B", Metric: MetricTaskSuccess, Value: 1},
+		{Variant: "B", Metric: MetricTaskSuccess, Value: 1},
+		{Variant: "B", Metric: MetricUserRating, Value: 5},
+	}
+	if err := exps.Record(exp.ID, outcomes); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := exps.Report(exp.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := report.Variants[0].Metrics[MetricTaskSuccess]; got.Count != 3 || got.Mean != 0.333 {
+		t.Errorf("variant A task success = %+v", got)
+	}
+	if got := report.Variants[1].Metrics[MetricTaskSuccess]; got.Count != 2 || got.Mean != 1 {
+		t.Errorf("variant B task success = %+v", got)
+	}
+	var overall *DimensionCorrelation
+	for i, c := range report.Correlations {
+		if c.Metric == MetricUserRating {
+			t.Errorf("user_rating has too few samples to correlate: %+v", c)
+		}
+		if c.Dimension == overallMetricDimension {
+			overall = &report.Correlations[i]
+		}
+	}
+	if overall == nil || overall.Correlation <= 0 || overall.Samples != 5 {
+		t.Errorf("overall correlation = %+v", overall)
+	}
+	if report.Predictors[MetricTaskSuccess] == "" {
+		t.Errorf("predictors = %v", report.Predictors)
+	}
+
+	if pooled := exps.PooledReport(); len(pooled.Correlations) != len(report.Correlations) || pooled.Experiment != "" {
+		t.Errorf("pooled report = %+v", pooled)
+	}
+}
@@ -0,0 +1,247 @@
+package analyzer
+
+import (
+	"net/netip"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Detection is an operational detail found in the text, such as an IBAN or
+// card number, with where it is and whether it passed validation
+type Detection struct {
+	Span
+	Kind   string `json:"kind"`
+	Valid  bool   `json:"valid"`            // Checksum, range or format check passed
+	Detail string `json:"detail,omitempty"` // Country, card network, address class, path style, UUID version or currency
+}
+
+// Detection kinds
+const (
+	DetectionIBAN       = "iban"
+	DetectionCreditCard = "credit_card"
+	DetectionIPv4       = "ipv4"
+	DetectionIPv6       = "ipv6"
+	DetectionFilePath   = "file_path"
+	DetectionUUID       = "uuid"
+	DetectionCurrency   = "currency"
+)
+
+var (
+	ibanPattern        = regexp.MustCompile(`\b[A-Z]{2}\d{2}(?: ?[A-Z0-9]){11,30}\b`)
+	cardPattern        = regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`)
+	ipv4Pattern        = regexp.MustCompile(`\b\d{1,3}(?:\.\d{1,3}){3}\b`)
+	ipv6Pattern        = regexp.MustCompile(`[0-9A-Fa-f:.]*:[0-9A-Fa-f:.]*`)
+	unixPathPattern    = regexp.MustCompile(`(?:^|[\s("'\x60=])((?:~|\.{1,2})?(?:/[\w.\-@+]+){2,}/?)`)
+	windowsPathPattern = regexp.MustCompile(`\b[A-Za-z]:\\(?:[^\\\s:*?"<>|]+\\?)*`)
+	uuidPattern        = regexp.MustCompile(`\b[0-9A-Fa-f]{8}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{12}\b`)
+	currencyPattern    = regexp.MustCompile(`(?:[$€£¥₹]\s?\d[\d,]*(?:\.\d+)?|\b(?:USD|EUR|GBP|JPY|INR|CAD|AUD|CHF|CNY)\s?\d[\d,]*(?:\.\d+)?|\b\d[\d,]*(?:\.\d+)?\s?(?:USD|EUR|GBP|JPY|INR|CAD|AUD|CHF|CNY))\b`)
+	amountPattern      = regexp.MustCompile(`^\d{1,3}(?:,\d{3})*(?:\.\d+)?$|^\d+(?:\.\d+)?$`)
+)
+
+// ibanLengths is the IBAN length for common countries; other countries are
+// checked against the 15-34 range only
+var ibanLengths = map[string]int{
+	"AT": 20, "BE": 16, "CH": 21, "CZ": 24, "DE": 22, "DK": 18, "ES": 24, "FI": 18,
+	"FR": 27, "GB": 22, "IE": 22, "IT": 27, "LU": 20, "NL": 18, "NO": 15, "PL": 28,
+	"PT": 25, "SE": 24,
+}
+
+var currencySymbols = map[string]string{"$": "USD", "€": "EUR", "£": "GBP", "¥": "JPY", "₹": "INR"}
+
+// detectIBANs finds IBANs and checks their length and mod-97 checksum
+func detectIBANs(text string) []Detection {
+	out := []Detection{}
+	for _, span := range patternSpans(text, ibanPattern) {
+		iban := strings.ReplaceAll(span.Text, " ", "")
+		country := iban[:2]
+		valid := len(iban) >= 15 && len(iban) <= 34
+		if want, ok := ibanLengths[country]; ok {
+			valid = len(iban) == want
+		}
+		out = append(out, Detection{Span: span, Kind: DetectionIBAN, Valid: valid && ibanChecksum(iban), Detail: country})
+	}
+	return out
+}
+
+// ibanChecksum moves the first four characters to the end, reads letters as
+// 10-35 and checks the resulting number is 1 mod 97
+func ibanChecksum(iban string) bool {
+	rem := 0
+	for _, r := range iban[4:] + iban[:4] {
+		if r >= 'A' && r <= 'Z' {
+			rem = (rem*100 + int(r-'A') + 10) % 97
+		} else {
+			rem = (rem*10 + int(r-'0')) % 97
+		}
+	}
+	return rem == 1
+}
+
+// detectCreditCards finds card numbers: 13-19 digits with a known network's
+// prefix and length that pass the Luhn check. Other digit runs, such as order
+// and tracking numbers, and digits inside an IBAN are not reported.
+func detectCreditCards(text string) []Detection {
+	out := []Detection{}
+	ibans := patternSpans(text, ibanPattern)
+	for _, span := range patternSpans(text, cardPattern) {
+		digits := strings.NewReplacer(" ", "", "-", "").Replace(span.Text)
+		network := cardNetwork(digits)
+		if network == "" || !luhnValid(digits) || overlapsAny(span, ibans) {
+			continue
+		}
+		out = append(out, Detection{Span: span, Kind: DetectionCreditCard, Valid: true, Detail: network})
+	}
+	return out
+}
+
+// luhnValid checks the Luhn check digit of a string of digits
+func luhnValid(digits string) bool {
+	sum := 0
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if (len(digits)-i)%2 == 0 {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	return sum%10 == 0
+}
+
+// cardNetwork names the network a card number's prefix and length belong
+// to, or "" when no network issues such numbers
+func cardNetwork(digits string) string {
+	n := len(digits)
+	switch {
+	case strings.HasPrefix(digits, "4") && (n == 13 || n == 16 || n == 19):
+		return "visa"
+	case (strings.HasPrefix(digits, "34") || strings.HasPrefix(digits, "37")) && n == 15:
+		return "amex"
+	case (digits[:2] >= "51" && digits[:2] <= "55" || digits[:4] >= "2221" && digits[:4] <= "2720") && n == 16:
+		return "mastercard"
+	case (strings.HasPrefix(digits, "6011") || strings.HasPrefix(digits, "65")) && n >= 16:
+		return "discover"
+	}
+	return ""
+}
+
+// detectIPAddresses finds IPv4 and IPv6 addresses. IPv4 candidates with an
+// octet over 255 are reported as invalid; IPv6 is only reported when it
+// parses, since colon-separated text is common.
+func detectIPAddresses(text string) []Detection {
+	out := []Detection{}
+	for _, span := range patternSpans(text, ipv4Pattern) {
+		addr, err := netip.ParseAddr(span.Text)
+		d := Detection{Span: span, Kind: DetectionIPv4, Valid: err == nil}
+		if err == nil {
+			d.Detail = addressClass(addr)
+		}
+		out = append(out, d)
+	}
+	for _, m := range ipv6Pattern.FindAllStringIndex(text, -1) {
+		start, end := m[0], m[1]
+		candidate := text[start:end]
+		if (start > 0 && isWordByte(text[start-1])) || (end < len(text) && isWordByte(text[end])) {
+			continue
+		}
+		// Trailing sentence punctuation is not part of the address
+		for strings.HasSuffix(candidate, ".") || (strings.HasSuffix(candidate, ":") && !strings.HasSuffix(candidate, "::")) {
+			candidate, end = candidate[:len(candidate)-1], end-1
+		}
+		if !strings.Contains(candidate, "::") && strings.Count(candidate, ":") < 7 {
+			continue
+		}
+		if addr, err := netip.ParseAddr(candidate); err == nil && addr.Is6() {
+			out = append(out, Detection{Span: newSpan(text, start, end), Kind: DetectionIPv6, Valid: true, Detail: addressClass(addr)})
+		}
+	}
+	return out
+}
+
+func isWordByte(b byte) bool {
+	return b == '_' || b >= '0' && b <= '9' || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z'
+}
+
+// addressClass tells private and loopback addresses from public ones
+func addressClass(addr netip.Addr) string {
+	switch {
+	case addr.IsLoopback():
+		return "loopback"
+	case addr.IsPrivate():
+		return "private"
+	case addr.IsLinkLocalUnicast():
+		return "link_local"
+	case addr.IsUnspecified():
+		return "unspecified"
+	}
+	return "public"
+}
+
+// windowsReservedNames cannot be used as file names on Windows
+var windowsReservedNames = map[string]bool{"CON": true, "PRN": true, "AUX": true, "NUL": true, "COM1": true, "LPT1": true}
+
+// detectFilePaths finds Unix paths with at least two components and Windows
+// drive paths. Windows paths naming a reserved device such as NUL are
+// reported as invalid.
+func detectFilePaths(text string) []Detection {
+	out := []Detection{}
+	for _, m := range unixPathPattern.FindAllStringSubmatchIndex(text, -1) {
+		span := newSpan(text, m[2], m[2]+len(strings.TrimRight(text[m[2]:m[3]], ".")))
+		style := "unix_absolute"
+		if !strings.HasPrefix(span.Text, "/") {
+			style = "unix_relative"
+		}
+		out = append(out, Detection{Span: span, Kind: DetectionFilePath, Valid: true, Detail: style})
+	}
+	for _, m := range windowsPathPattern.FindAllStringIndex(text, -1) {
+		span := newSpan(text, m[0], m[0]+len(strings.TrimRight(text[m[0]:m[1]], ".,;)")))
+		valid := true
+		for _, part := range strings.Split(span.Text[3:], `\`) {
+			name, _, _ := strings.Cut(part, ".")
+			if windowsReservedNames[strings.ToUpper(name)] {
+				valid = false
+			}
+		}
+		out = append(out, Detection{Span: span, Kind: DetectionFilePath, Valid: valid, Detail: "windows"})
+	}
+	return out
+}
+
+// detectUUIDs finds UUIDs, checking the version and RFC 9562 variant
+func detectUUIDs(text string) []Detection {
+	out := []Detection{}
+	for _, span := range patternSpans(text, uuidPattern) {
+		lower := strings.ToLower(span.Text)
+		version, variant := lower[14], lower[19]
+		valid := version >= '1' && version <= '8' && strings.ContainsRune("89ab", rune(variant))
+		detail := "v" + string(version)
+		if strings.Trim(lower, "0-") == "" {
+			valid, detail = true, "nil"
+		}
+		out = append(out, Detection{Span: span, Kind: DetectionUUID, Valid: valid, Detail: detail})
+	}
+	return out
+}
+
+// detectCurrencyAmounts finds amounts with a currency symbol or ISO code;
+// amounts with misplaced thousands separators are reported as invalid
+func detectCurrencyAmounts(text string) []Detection {
+	out := []Detection{}
+	for _, span := range patternSpans(text, currencyPattern) {
+		amount, code := span.Text, ""
+		for symbol, iso := range currencySymbols {
+			if strings.HasPrefix(amount, symbol) {
+				amount, code = amount[len(symbol):], iso
+			}
+		}
+		if code == "" {
+			amount = strings.TrimFunc(amount, unicode.IsUpper)
+			code = strings.TrimSpace(strings.Replace(span.Text, amount, "", 1))
+		}
+		amount = strings.TrimSpace(amount)
+		out = append(out, Detection{Span: span, Kind: DetectionCurrency, Valid: amountPattern.MatchString(amount), Detail: code})
+	}
+	return out
+}
@@ -0,0 +1,60 @@
+package analyzer
+
+import (
+	"testing"
+)
+
+func TestExtractionDetectors(t *testing.T) {
+	tests := []struct {
+		name   string
+		detect func(string) []Detection
+		text   string
+		want   []Detection
+	}{
+		{"iban", detectIBANs, "Pay to DE89 3704 0044 0532 0130 00 or GB82WEST12345698765433.", []Detection{
+			{Span: Span{Text: "DE89 3704 0044 0532 0130 00"}, Valid: true, Detail: "DE"},
+			{Span: Span{Text: "GB82WEST12345698765433"}, Valid: false, Detail: "GB"},
+		}},
+		{"card", detectCreditCards, "Test with 4111 1111 1111 1111 and 3782-822463-10005, not 5500-0000-0000-0005 or 1234567890123.", []Detection{
+			{Span: Span{Text: "4111 1111 1111 1111"}, Valid: true, Detail: "visa"},
+			{Span: Span{Text: "3782-822463-10005"}, Valid: true, Detail: "amex"},
+		}},
+		// Order and tracking numbers and the digits inside IBANs are not cards,
+		// even when the digits happen to pass the Luhn check
+		{"card lookalikes", detectCreditCards, "Order 4111111111111112 ships as 9400111899223344556677. Refund to FR47 4111 1111 1111 1111 0123 456 or DE27 4000 0000 0000 0000 10.", []Detection{}},
+		{"ip", detectIPAddresses, "Call 10.0.0.12 or 999.1.1.1, then ::1 and 2001:db8::8a2e:370:7334. Meet at 10:30.", []Detection{
+			{Span: Span{Text: "10.0.0.12"}, Valid: true, Detail: "private"},
+			{Span: Span{Text: "999.1.1.1"}, Valid: false},
+			{Span: Span{Text: "::1"}, Valid: true, Detail: "loopback"},
+			{Span: Span{Text: "2001:db8::8a2e:370:7334"}, Valid: true, Detail: "public"},
+		}},
+		{"path", detectFilePaths, "Read /etc/nginx/nginx.conf and ./src/main.go, then C:\\Users\\me\\NUL.txt, not https://x.io/a/b or and/or.", []Detection{
+			{Span: Span{Text: "/etc/nginx/nginx.conf"}, Valid: true, Detail: "unix_absolute"},
+			{Span: Span{Text: "./src/main.go"}, Valid: true, Detail: "unix_relative"},
+			{Span: Span{Text: "C:\\Users\\me\\NUL.txt"}, Valid: false, Detail: "windows"},
+		}},
+		{"uuid", detectUUIDs, "Job 123e4567-e89b-42d3-a456-426614174000 and 123e4567-e89b-02d3-c456-426614174000.", []Detection{
+			{Span: Span{Text: "123e4567-e89b-42d3-a456-426614174000"}, Valid: true, Detail: "v4"},
+			{Span: Span{Text: "123e4567-e89b-02d3-c456-426614174000"}, Valid: false, Detail: "v0"},
+		}},
+		{"currency", detectCurrencyAmounts, "Budget $1,250.50, €30, 200 EUR and USD 1,00.", []Detection{
+			{Span: Span{Text: "$1,250.50"}, Valid: true, Detail: "USD"},
+			{Span: Span{Text: "€30"}, Valid: true, Detail: "EUR"},
+			{Span: Span{Text: "200 EUR"}, Valid: true, Detail: "EUR"},
+			{Span: Span{Text: "USD 1,00"}, Valid: false, Detail: "USD"},
+		}},
+	}
+	for _, tt := range tests {
+		got := tt.detect(tt.text)
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: got %+v, want %d detections", tt.name, got, len(tt.want))
+			continue
+		}
+		for i, d := range got {
+			w := tt.want[i]
+			if d.Text != w.Text || d.Valid != w.Valid || d.Detail != w.Detail || tt.text[d.Start:d.End] != d.Text {
+				t.Errorf("%s: got %+v, want %+v", tt.name, d, w)
+			}
+		}
+	}
+}
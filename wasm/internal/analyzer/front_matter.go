@@ -0,0 +1,254 @@
+package analyzer
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// FrontMatter is the YAML block a prompt file may open with, between two
+// "---" lines. The known keys are lifted into fields; Fields keeps every key
+// as written, with lists joined by ", ".
+type FrontMatter struct {
+	Model       string            `json:"model,omitempty"`
+	Temperature *float64          `json:"temperature,omitempty"`
+	Owner       string            `json:"owner,omitempty"`
+	IntendedUse string            `json:"intended_use,omitempty"`
+	Fields      map[string]string `json:"fields"`      // Every key, lower-cased with "-" and " " as "_"
+	BodyOffset  int               `json:"body_offset"` // Bytes before the prompt; add it to result spans to locate them in the file
+	Warnings    []string          `json:"warnings"`
+	// ContextWindow checks the prompt against the declared model's window
+	ContextWindow *ContextWindowCheck `json:"context_window,omitempty"`
+}
+
+// frontMatterKeys maps accepted spellings of the known keys to their field
+var frontMatterKeys = map[string]string{
+	"model": "model", "llm": "model", "engine": "model",
+	"temperature": "temperature", "temp": "temperature",
+	"owner": "owner", "author": "owner", "maintainer": "owner",
+	"intended_use": "intended_use", "use": "intended_use", "purpose": "intended_use", "use_case": "intended_use",
+}
+
+var (
+	// frontMatterPattern matches the block at the start of a file, after an
+	// optional byte order mark
+	frontMatterPattern = regexp.MustCompile(`^(?:\x{FEFF})?---[ \t]*\r?\n((?s:.*?))(?:^|\n)(?:---|\.\.\.)[ \t]*(?:\r?\n|$)`)
+	frontMatterLine    = regexp.MustCompile(`^([ \t]*)([A-Za-z_][\w ,()-]*?\??)[ \t]*:(?:[ \t]+(.*))?$`)
+	frontMatterItem    = regexp.MustCompile(`^[ \t]*-[ \t]+(.*)$`)
+)
+
+// ParseFrontMatter splits a YAML front matter block off text, returning it
+// with the prompt that follows. It reads the subset prompt files use:
+// "key: value" lines, quoted values, "- item" lists under a key, comments,
+// and indented keys under an empty one, stored with dotted paths such as
+// "config.temperature". A block with any other line is treated as part of
+// the prompt, and text without one returns nil and text unchanged.
+func ParseFrontMatter(text string) (*FrontMatter, string) {
+	m := frontMatterPattern.FindStringSubmatchIndex(text)
+	if m == nil {
+		return nil, text
+	}
+	fm := &FrontMatter{Fields: map[string]string{}, BodyOffset: m[1], Warnings: []string{}}
+	type parent struct {
+		indent int
+		key    string
+	}
+	var parents []parent
+	key := "" // Key whose list the next "- item" lines extend
+	for _, line := range strings.Split(text[m[2]:m[3]], "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		if item := frontMatterItem.FindStringSubmatch(line); item != nil && key != "" {
+			if fm.Fields[key] != "" {
+				fm.Fields[key] += ", "
+			}
+			fm.Fields[key] += yamlScalar(item[1])
+			continue
+		}
+		kv := frontMatterLine.FindStringSubmatch(line)
+		if kv == nil {
+			return nil, text
+		}
+		indent := len(kv[1])
+		for len(parents) > 0 && parents[len(parents)-1].indent >= indent {
+			parents = parents[:len(parents)-1]
+		}
+		key = strings.NewReplacer("-", "_", " ", "_").Replace(strings.ToLower(kv[2]))
+		if len(parents) > 0 {
+			up := parents[len(parents)-1].key
+			delete(fm.Fields, up) // A map, not an empty value
+			key = up + "." + key
+		} else if indent > 0 {
+			return nil, text
+		}
+		fm.Fields[key] = yamlScalar(kv[3])
+		if kv[3] == "" {
+			parents = append(parents, parent{indent, key})
+		}
+	}
+	if len(fm.Fields) == 0 {
+		return nil, text
+	}
+
+	keys := make([]string, 0, len(fm.Fields))
+	for k := range fm.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		v := fm.Fields[k]
+		// Dotprompt files nest the model settings under config
+		switch frontMatterKeys[strings.TrimPrefix(k, "config.")] {
+		case "model":
+			fm.Model = v
+		case "owner":
+			fm.Owner = v
+		case "intended_use":
+			fm.IntendedUse = v
+		case "temperature":
+			t, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				fm.Warnings = append(fm.Warnings, fmt.Sprintf("%s %q is not a number", k, v))
+				continue
+			}
+			if t < 0 || t > 2 {
+				fm.Warnings = append(fm.Warnings, fmt.Sprintf("%s %g is outside the usual 0-2 range", k, t))
+			}
+			fm.Temperature = &t
+		}
+	}
+	body := text[m[1]:]
+	if fm.Model != "" {
+		if fm.ContextWindow = CheckContextWindow(body, fm.Model); fm.ContextWindow == nil {
+			fm.Warnings = append(fm.Warnings, fmt.Sprintf("unknown model %q: no context window check", fm.Model))
+		}
+	}
+	return fm, body
+}
+
+// yamlScalar unquotes a YAML value and drops a trailing comment
+func yamlScalar(v string) string {
+	v = strings.TrimSpace(v)
+	if len(v) >= 2 && (v[0] == '"' || v[0] == '\'') {
+		if end := strings.LastIndexByte(v, v[0]); end > 0 {
+			if v[0] == '"' {
+				if s, err := strconv.Unquote(v[:end+1]); err == nil {
+					return s
+				}
+			}
+			return strings.ReplaceAll(v[1:end], "''", "'")
+		}
+	}
+	if i := strings.Index(v, " #"); i >= 0 {
+		v = strings.TrimSpace(v[:i])
+	}
+	return v
+}
+
+// ModelProfile describes a target model family's tokenizer and context window
+type ModelProfile struct {
+	Prefix        string  `json:"prefix"` // Model names starting with this use the profile
+	Tokenizer     string  `json:"tokenizer"`
+	ContextWindow int     `json:"context_window"` // Tokens
+	CharsPerToken float64 `json:"chars_per_token"`
+}
+
+// modelProfiles lists the known model families; the longest matching prefix
+// wins, so "gpt-4o" is not read as "gpt-4". Characters per token are averages
+// for English prose.
+var modelProfiles = []ModelProfile{
+	{"gpt-4.1", "o200k_base", 1047576, 4.2},
+	{"gpt-4o", "o200k_base", 128000, 4.2},
+	{"o1", "o200k_base", 200000, 4.2},
+	{"o3", "o200k_base", 200000, 4.2},
+	{"o4", "o200k_base", 200000, 4.2},
+	{"gpt-4-turbo", "cl100k_base", 128000, 4.0},
+	{"gpt-4", "cl100k_base", 8192, 4.0},
+	{"gpt-3.5-turbo", "cl100k_base", 16385, 4.0},
+	{"claude", "claude", 200000, 3.5},
+	{"gemini", "gemini", 1048576, 4.0},
+	{"llama-3.1", "llama3", 128000, 4.0},
+	{"llama-3", "llama3", 8192, 4.0},
+	{"mistral", "mistral", 32000, 3.7},
+}
+
+// LookupModel returns the profile of a model name, ignoring case and a
+// provider prefix such as "openai/"; nil when the family is unknown
+func LookupModel(model string) *ModelProfile {
+	name := strings.ToLower(strings.TrimSpace(model))
+	if i := strings.LastIndexByte(name, '/'); i >= 0 {
+		name = name[i+1:]
+	}
+	var best *ModelProfile
+	for i, p := range modelProfiles {
+		if strings.HasPrefix(name, p.Prefix) && (best == nil || len(p.Prefix) > len(best.Prefix)) {
+			best = &modelProfiles[i]
+		}
+	}
+	return best
+}
+
+// contextWindowWarnShare is the share of the window past which a prompt
+// leaves little room for the reply
+const contextWindowWarnShare = 0.8
+
+// ContextWindowCheck estimates a prompt's tokens with the declared model's
+// tokenizer and compares them with its context window
+type ContextWindowCheck struct {
+	Model         string  `json:"model"`
+	Tokenizer     string  `json:"tokenizer"`
+	Tokens        int     `json:"tokens"` // Estimated from characters per token
+	ContextWindow int     `json:"context_window"`
+	Usage         float64 `json:"usage"` // Percent of the window the prompt fills
+	Fits          bool    `json:"fits"`
+	Summary       string  `json:"summary"`
+}
+
+// CheckContextWindow estimates the tokens text takes in model; nil when the
+// model is unknown
+func CheckContextWindow(text, model string) *ContextWindowCheck {
+	profile := LookupModel(model)
+	if profile == nil {
+		return nil
+	}
+	tokens := int(math.Ceil(float64(utf8.RuneCountInString(text)) / profile.CharsPerToken))
+	check := &ContextWindowCheck{
+		Model:         model,
+		Tokenizer:     profile.Tokenizer,
+		Tokens:        tokens,
+		ContextWindow: profile.ContextWindow,
+		Usage:         math.Round(float64(tokens)/float64(profile.ContextWindow)*10000) / 100,
+		Fits:          tokens <= profile.ContextWindow,
+	}
+	check.Summary = fmt.Sprintf("About %d %s tokens, %.2f%% of %s's %d-token window", tokens, profile.Tokenizer, check.Usage, model, profile.ContextWindow)
+	switch {
+	case !check.Fits:
+		check.Summary += fmt.Sprintf("; over the window by %d tokens", tokens-profile.ContextWindow)
+	case float64(tokens) > float64(profile.ContextWindow)*contextWindowWarnShare:
+		check.Summary += "; little room is left for the reply"
+	}
+	return check
+}
+
+// promptStart is the offset in the analyzed file where result's prompt
+// begins, past any front matter
+func promptStart(result *CombinedResult) int {
+	if result.FrontMatter == nil {
+		return 0
+	}
+	return result.FrontMatter.BodyOffset
+}
+
+// fileSpan moves a span of result's prompt to its place in the analyzed file
+func fileSpan(result *CombinedResult, span Span) Span {
+	offset := promptStart(result)
+	span.Start += offset
+	span.End += offset
+	return span
+}
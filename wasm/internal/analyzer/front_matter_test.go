@@ -0,0 +1,84 @@
+package analyzer
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+const frontMatterTestFile = `---
+model: openai/gpt-4o-mini
+temperature: 0.2 # low for repeatable answers
+owner: "Billing Team"
+intended-use: Summarize support tickets
+tags:
+  - support
+  - 'billing'
+---
+Summarize the ticket below in three bullet points for the on-call engineer.`
+
+func TestParseFrontMatter(t *testing.T) {
+	fm, body := ParseFrontMatter(frontMatterTestFile)
+	if fm == nil {
+		t.Fatal("expected front matter")
+	}
+	if !strings.HasPrefix(body, "Summarize the ticket") || frontMatterTestFile[fm.BodyOffset:] != body {
+		t.Errorf("body = %q at %d", body, fm.BodyOffset)
+	}
+	if fm.Model != "openai/gpt-4o-mini" || fm.Temperature == nil || *fm.Temperature != 0.2 ||
+		fm.Owner != "Billing Team" || fm.IntendedUse != "Summarize support tickets" {
+		t.Errorf("front matter = %+v", fm)
+	}
+	if fm.Fields["tags"] != "support, billing" || len(fm.Warnings) != 0 {
+		t.Errorf("fields = %v, warnings = %v", fm.Fields, fm.Warnings)
+	}
+	if cw := fm.ContextWindow; cw == nil || cw.Tokenizer != "o200k_base" || cw.ContextWindow != 128000 || !cw.Fits || cw.Tokens == 0 {
+		t.Errorf("context window = %+v", cw)
+	}
+
+	fm, _ = ParseFrontMatter("---\nmodel: my-local-model\ntemp: hot\n---\nHi.")
+	if fm == nil || len(fm.Warnings) != 2 || fm.ContextWindow != nil || fm.Temperature != nil {
+		t.Errorf("front matter = %+v", fm)
+	}
+
+	for _, text := range []string{
+		"No front matter here.",
+		"---\nThis is a horizontal rule, not metadata.\n---\nText.",
+		"---\nmodel: gpt-4\nText without a closing line.",
+	} {
+		if fm, body := ParseFrontMatter(text); fm != nil || body != text {
+			t.Errorf("ParseFrontMatter(%q) = %+v", text, fm)
+		}
+	}
+}
+
+func TestCheckContextWindow(t *testing.T) {
+	if LookupModel("GPT-4o").Prefix != "gpt-4o" || LookupModel("gpt-4-0613").ContextWindow != 8192 || LookupModel("claude-3-5-sonnet").Tokenizer != "claude" {
+		t.Error("wrong model profile")
+	}
+	if CheckContextWindow("text", "unknown") != nil {
+		t.Error("expected no check for an unknown model")
+	}
+	long := strings.Repeat("word ", 8000)
+	if cw := CheckContextWindow(long, "gpt-4"); cw.Fits || !strings.Contains(cw.Summary, "over the window") {
+		t.Errorf("check = %+v", cw)
+	}
+	if cw := CheckContextWindow(strings.Repeat("word ", 6000), "gpt-4"); !cw.Fits || !strings.Contains(cw.Summary, "little room") {
+		t.Errorf("check = %+v", cw)
+	}
+}
+
+func TestAnalyzeStripsFrontMatter(t *testing.T) {
+	result, err := Analyze(context.Background(), frontMatterTestFile, AnalysisOptions{}, AnalysisRun{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.FrontMatter == nil || result.FrontMatter.Model != "openai/gpt-4o-mini" {
+		t.Fatalf("front matter = %+v", result.FrontMatter)
+	}
+	for _, tok := range result.Tokens.Tokens {
+		if tok.Text == "temperature" || tok.Text == "owner" {
+			t.Errorf("front matter key %q was analyzed as prompt text", tok.Text)
+		}
+	}
+}
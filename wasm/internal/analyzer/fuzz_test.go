@@ -0,0 +1,88 @@
+package analyzer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fuzzSeeds are inputs that have tripped text handling before: malformed and
+// multibyte UTF-8, empty structure, deep nesting, runs of punctuation and
+// overlong words, which used to make tokenizing and spell checking quadratic
+var fuzzSeeds = []string{
+	strings.Repeat(".", 2000),
+	strings.Repeat("a", 5000),
+	"",
+	" ",
+	"\n\n\n",
+	"\xff\xfe\xfd",
+	"caf\xc3 au lait. Fix the \xe2\x82 bug.",
+	"日本語のテキスト。もう一つの文。",
+	"Ünïcödé wörds — and “quotes”… ½ ² ✓ 🙂🙂🙂",
+	"...!!!???;;;:::",
+	"1.\n2.\n3.",
+	"- a\n  - b\n    - c\n      - d\n        - e",
+	"# \n## \n### ",
+	"```\nunterminated code",
+	"Fix it. Fix it. Fix it. Fix it.",
+	"Dr. Smith vs. Mr. Jones, e.g. U.S.A. i.e. etc.",
+	"https://example.com/a?b=c&d=e ops@example.com @user #tag",
+	"We need to fix the bug before we deploy, then test it, after we build it.",
+	"Why? Because. How? Like this: 1) one 2) two 3) three",
+	"\t\r\n\v\f   ",
+	"a\x00b\x00c",
+}
+
+// addFuzzSeeds seeds a fuzz target with fuzzSeeds and the golden prompts
+func addFuzzSeeds(f *testing.F) {
+	for _, s := range fuzzSeeds {
+		f.Add(s)
+	}
+	inputs, _ := filepath.Glob(filepath.Join("testdata", "golden", "*.txt"))
+	for _, input := range inputs {
+		if text, err := os.ReadFile(input); err == nil {
+			f.Add(string(text))
+		}
+	}
+}
+
+func FuzzPreprocessText(f *testing.F) {
+	addFuzzSeeds(f)
+	f.Fuzz(func(t *testing.T, text string) {
+		data := PreprocessText(text)
+		for _, step := range data.TransformationLog.Value[1:] {
+			before, after, _ := data.StepText(step.Step)
+			if got, err := ApplyEdits(before, step.Diff); err != nil || got != after {
+				t.Fatalf("%s diff does not rebuild the step output: %v", step.Step, err)
+			}
+		}
+	})
+}
+
+func FuzzAnalyzeComplexity(f *testing.F) {
+	addFuzzSeeds(f)
+	f.Fuzz(func(t *testing.T, text string) {
+		AnalyzeComplexity(text)
+	})
+}
+
+func FuzzAnalyzeIdeas(f *testing.F) {
+	addFuzzSeeds(f)
+	f.Fuzz(func(t *testing.T, text string) {
+		AnalyzeIdeas(text)
+	})
+}
+
+func FuzzExtractTaskGraph(f *testing.F) {
+	addFuzzSeeds(f)
+	f.Fuzz(func(t *testing.T, text string) {
+		graph := ExtractTaskGraphFromText(text)
+		for _, task := range graph.Tasks {
+			pos := task.TextPosition
+			if pos.StartChar < 0 || pos.StartChar > pos.EndChar || pos.EndChar > len(text) {
+				t.Errorf("task %s spans [%d,%d) outside text of length %d", task.ID, pos.StartChar, pos.EndChar, len(text))
+			}
+		}
+	})
+}
@@ -0,0 +1,183 @@
+package analyzer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// GlossaryTerm is one domain term the caller has defined
+type GlossaryTerm struct {
+	Term       string   `json:"term"`
+	Definition string   `json:"definition,omitempty"`
+	Aliases    []string `json:"aliases,omitempty"` // Other spellings, e.g. the acronym of a phrase
+}
+
+// LoadGlossary parses a JSON array of glossary terms
+func LoadGlossary(data []byte) ([]GlossaryTerm, error) {
+	var terms []GlossaryTerm
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&terms); err != nil {
+		return nil, fmt.Errorf("invalid glossary: %w", err)
+	}
+	if err := validateGlossary(terms); err != nil {
+		return nil, err
+	}
+	return terms, nil
+}
+
+// validateGlossary rejects blank terms and aliases
+func validateGlossary(terms []GlossaryTerm) error {
+	for i, t := range terms {
+		if strings.TrimSpace(t.Term) == "" {
+			return fmt.Errorf("glossary term %d is empty", i)
+		}
+		for _, alias := range t.Aliases {
+			if strings.TrimSpace(alias) == "" {
+				return fmt.Errorf("glossary term %q has an empty alias", t.Term)
+			}
+		}
+	}
+	return nil
+}
+
+// TermUsage records where a term appears in the prompt
+type TermUsage struct {
+	Term       string `json:"term"`
+	Definition string `json:"definition,omitempty"`
+	Count      int    `json:"count"`
+	Spans      []Span `json:"spans"`
+}
+
+// TerminologyAnalysis scores how well the prompt's domain terms are defined
+type TerminologyAnalysis struct {
+	GlossaryTerms   []TermUsage `json:"glossary_terms"`   // Glossary terms the prompt uses
+	InlineDefined   []string    `json:"inline_defined"`   // Jargon the prompt defines itself, e.g. "SLA (service level agreement)"
+	UndefinedJargon []TermUsage `json:"undefined_jargon"` // Acronyms and identifiers with no definition
+	Score           float64     `json:"score"`            // 0-100; 75 when the prompt has no domain terms
+}
+
+var (
+	// Acronyms and code-style identifiers such as SLA, KPIs, p95_latency or OrderService
+	jargonPattern = regexp.MustCompile(`\b(?:[A-Z][A-Z0-9]{1,5}s?|[a-z]+(?:_[a-z0-9]+)+|[A-Z][a-z]+(?:[A-Z][a-z0-9]+)+)\b`)
+	// "SLA means ...", "SLA stands for ...", "SLA: the ..." define the term in place
+	inlineDefinitionPattern = regexp.MustCompile(`^\s*(?:\(|:|,?\s*(?:means|stands for|refers to|is short for|is defined as|i\.e\.))`)
+)
+
+// wellKnownAcronyms need no definition for a general audience
+var wellKnownAcronyms = map[string]bool{
+	"AI": true, "API": true, "APIs": true, "CSS": true, "CSV": true, "CLI": true, "CPU": true,
+	"DB": true, "FAQ": true, "GPU": true, "HTML": true, "HTTP": true, "HTTPS": true, "ID": true,
+	"IDs": true, "JSON": true, "OK": true, "PDF": true, "REST": true, "SQL": true, "UI": true,
+	"URL": true, "URLs": true, "USA": true, "UK": true, "EU": true, "UX": true, "XML": true,
+	"YAML": true, "TODO": true, "FYI": true, "ASAP": true, "AM": true, "PM": true, "CEO": true,
+}
+
+// emphasisWords are ordinary words written in capitals for emphasis
+var emphasisWords = map[string]bool{
+	"MUST": true, "NOT": true, "NEVER": true, "ALWAYS": true, "ONLY": true, "DO": true, "DON": true,
+	"NO": true, "YES": true, "ALL": true, "AND": true, "OR": true, "IMPORTANT": true, "NOTE": true,
+	"SHOULD": true, "SHALL": true, "MAY": true, "IF": true, "THE": true, "A": true, "I": true,
+}
+
+// AnalyzeTerminology finds glossary terms and undefined jargon in text. A
+// glossary term counts as defined wherever it appears; other acronyms and
+// identifiers count as defined only when the prompt explains them in place.
+func AnalyzeTerminology(text string, glossary []GlossaryTerm) TerminologyAnalysis {
+	analysis := TerminologyAnalysis{GlossaryTerms: []TermUsage{}, InlineDefined: []string{}, UndefinedJargon: []TermUsage{}}
+
+	covered := map[string]bool{} // Lowercased glossary spellings
+	for _, g := range glossary {
+		usage := TermUsage{Term: g.Term, Definition: g.Definition, Spans: []Span{}}
+		for _, spelling := range append([]string{g.Term}, g.Aliases...) {
+			covered[strings.ToLower(spelling)] = true
+			usage.Spans = append(usage.Spans, termSpans(text, spelling)...)
+		}
+		if len(usage.Spans) > 0 {
+			sort.Slice(usage.Spans, func(i, j int) bool { return usage.Spans[i].Start < usage.Spans[j].Start })
+			usage.Count = len(usage.Spans)
+			analysis.GlossaryTerms = append(analysis.GlossaryTerms, usage)
+		}
+	}
+
+	undefined := map[string]*TermUsage{}
+	order := []string{}
+	inline := map[string]bool{}
+	for _, m := range jargonPattern.FindAllStringIndex(text, -1) {
+		word := text[m[0]:m[1]]
+		if wellKnownAcronyms[word] || emphasisWords[word] || covered[strings.ToLower(word)] || isEmphasisRun(text, m[0], m[1]) {
+			continue
+		}
+		if inline[word] || inlineDefinitionPattern.MatchString(text[m[1]:]) || strings.HasSuffix(strings.TrimRight(text[:m[0]], " "), "(") {
+			if !inline[word] {
+				inline[word] = true
+				analysis.InlineDefined = append(analysis.InlineDefined, word)
+			}
+			continue
+		}
+		usage, ok := undefined[word]
+		if !ok {
+			usage = &TermUsage{Term: word, Spans: []Span{}}
+			undefined[word] = usage
+			order = append(order, word)
+		}
+		usage.Count++
+		usage.Spans = append(usage.Spans, newSpan(text, m[0], m[1]))
+	}
+	for _, word := range order {
+		// A term defined later in the prompt still counts as defined
+		if !inline[word] {
+			analysis.UndefinedJargon = append(analysis.UndefinedJargon, *undefined[word])
+		}
+	}
+
+	defined := len(analysis.GlossaryTerms) + len(analysis.InlineDefined)
+	total := defined + len(analysis.UndefinedJargon)
+	analysis.Score = 75
+	if total > 0 {
+		analysis.Score = 40 + 60*float64(defined)/float64(total)
+	}
+	return analysis
+}
+
+// termSpans finds whole-word, case-insensitive occurrences of term. Glossaries
+// come from callers, so the pattern is not kept in the shared regex cache.
+func termSpans(text, term string) []Span {
+	re, err := regexp.Compile(`(?i)\b` + regexp.QuoteMeta(strings.TrimSpace(term)) + `\b`)
+	if err != nil {
+		return nil
+	}
+	return patternSpans(text, re)
+}
+
+// isEmphasisRun reports whether the capitalized word at text[start:end] is part
+// of shouted text ("DO NOT CHANGE THIS"): a run of three or more capitalized
+// words, or one next to an emphasis word. Pairs such as "AWS EC2" stay jargon.
+func isEmphasisRun(text string, start, end int) bool {
+	if strings.ToUpper(text[start:end]) != text[start:end] {
+		return false
+	}
+	shouted := func(word string) bool {
+		w := strings.Trim(word, ".,;:!?\"'()")
+		return len(w) > 1 && strings.ToUpper(w) == w && strings.ToLower(w) != w
+	}
+	// Neighbouring words only; a bounded window keeps this linear on long prompts
+	before := strings.Fields(text[max(0, start-80):start])
+	after := strings.Fields(text[end:min(len(text), end+80)])
+	run := 1
+	for i := len(before) - 1; i >= 0 && shouted(before[i]); i-- {
+		run++
+	}
+	for i := 0; i < len(after) && shouted(after[i]); i++ {
+		run++
+	}
+	if run >= 3 {
+		return true
+	}
+	return (len(before) > 0 && emphasisWords[strings.Trim(before[len(before)-1], ".,;:!?\"'()")]) ||
+		(len(after) > 0 && emphasisWords[strings.Trim(after[0], ".,;:!?\"'()")])
+}
@@ -0,0 +1,73 @@
+package analyzer
+
+import (
+	"context"
+	"testing"
+)
+
+func TestAnalyzeTerminology(t *testing.T) {
+	text := "Keep the SLA above target and report the KPIs in the OrderService dashboard. " +
+		"RPO (recovery point objective) is one hour. Do NOT CHANGE THIS. Return JSON."
+	glossary := []GlossaryTerm{{Term: "service level agreement", Definition: "Uptime commitment", Aliases: []string{"SLA"}}}
+
+	got := AnalyzeTerminology(text, glossary)
+	if len(got.GlossaryTerms) != 1 || got.GlossaryTerms[0].Count != 1 || got.GlossaryTerms[0].Spans[0].Text != "SLA" {
+		t.Errorf("expected the SLA alias to match the glossary, got %+v", got.GlossaryTerms)
+	}
+	if len(got.InlineDefined) != 1 || got.InlineDefined[0] != "RPO" {
+		t.Errorf("expected RPO to be defined inline, got %v", got.InlineDefined)
+	}
+	undefined := map[string]bool{}
+	for _, j := range got.UndefinedJargon {
+		undefined[j.Term] = true
+	}
+	if len(undefined) != 2 || !undefined["KPIs"] || !undefined["OrderService"] {
+		t.Errorf("expected KPIs and OrderService to be undefined, got %v", undefined)
+	}
+	if got.Score != 70 {
+		t.Errorf("expected 2 of 4 terms defined to score 70, got %.1f", got.Score)
+	}
+
+	if plain := AnalyzeTerminology("Write a short poem about the sea.", nil); plain.Score != 75 {
+		t.Errorf("expected the neutral score without domain terms, got %.1f", plain.Score)
+	}
+}
+
+func TestGlossaryFeedsContextSufficiency(t *testing.T) {
+	text := "Reduce MTTR for the ingest pipeline and alert on SLO burn."
+	without, err := Analyze(context.Background(), text, AnalysisOptions{}, AnalysisRun{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	glossary := []GlossaryTerm{{Term: "MTTR", Definition: "Mean time to recovery"}, {Term: "SLO", Definition: "Service level objective"}}
+	with, err := Analyze(context.Background(), text, AnalysisOptions{Glossary: glossary}, AnalysisRun{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if with.PromptGrade.ContextSufficiency.Score <= without.PromptGrade.ContextSufficiency.Score {
+		t.Errorf("expected the glossary to raise context sufficiency (%.1f -> %.1f)",
+			without.PromptGrade.ContextSufficiency.Score, with.PromptGrade.ContextSufficiency.Score)
+	}
+	hasRule := func(result *CombinedResult) bool {
+		for _, s := range result.PromptGrade.Suggestions {
+			if s.Rule == "FUL019" {
+				return true
+			}
+		}
+		return false
+	}
+	if !hasRule(without) || hasRule(with) {
+		t.Error("expected FUL019 only when jargon is undefined")
+	}
+}
+
+func TestLoadGlossaryRejectsBlankTerms(t *testing.T) {
+	if _, err := LoadGlossary([]byte(`[{"term":" "}]`)); err == nil {
+		t.Error("expected a blank term to be rejected")
+	}
+	terms, err := LoadGlossary([]byte(`[{"term":"SKU","definition":"Stock keeping unit"}]`))
+	if err != nil || len(terms) != 1 {
+		t.Errorf("unexpected result %v, %v", terms, err)
+	}
+}
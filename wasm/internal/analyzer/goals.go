@@ -0,0 +1,209 @@
+package analyzer
+
+import (
+	"math"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Goal sources, from strongest evidence to weakest
+const (
+	GoalFromHeading     = "heading"     // Under or after a "Goal:" style label
+	GoalFromStatement   = "statement"   // "The goal is...", "I want you to..."
+	GoalFromInstruction = "instruction" // Inferred from the first concrete instruction
+)
+
+// GoalStatement is one objective, secondary goal or non-goal in a prompt
+type GoalStatement struct {
+	Span   Span   `json:"span"`
+	Source string `json:"source"`           // heading, statement or instruction
+	Intent string `json:"intent,omitempty"` // create, explain, analyze, fix, transform, decide or find
+}
+
+// GoalExtraction summarizes what a prompt is for and what it is not for
+type GoalExtraction struct {
+	Primary      []GoalStatement `json:"primary"`   // Main objective sentence(s)
+	Secondary    []GoalStatement `json:"secondary"` // Stretch, optional and additional goals
+	NonGoals     []GoalStatement `json:"non_goals"` // What the prompt rules out of scope
+	Intent       string          `json:"intent"`    // Intent of the first primary goal, "" when unknown
+	HasClearGoal bool            `json:"has_clear_goal"`
+	Score        float64         `json:"score"` // 0-100 goal clarity
+}
+
+// Goal clarity scores: a stated goal beats one inferred from an instruction,
+// and bounding the scope with secondary goals or non-goals adds a bonus
+const (
+	statedGoalScore   = 90.0
+	inferredGoalScore = 70.0
+	noGoalScore       = 40.0
+	goalScopeBonus    = 10.0
+)
+
+// goalKinds are the sections a goal heading opens
+const (
+	goalKindPrimary   = "primary"
+	goalKindSecondary = "secondary"
+	goalKindNonGoal   = "non-goal"
+)
+
+var (
+	// goalHeadingPattern matches "Goal:", "## Objectives:", "Non-goals:", "Out of scope:"
+	goalHeadingPattern = regexp.MustCompile(`(?i)^\s*(?:#+\s*)?(?:\*\*)?((?:primary |main |overall |end )?(?:goals?|objectives?|aims?|purpose|task|mission)|(?:secondary|stretch|bonus|optional) (?:goals?|objectives?)|nice[ -]to[ -]haves?|non[ -]?goals?|out[ -]of[ -]scope|not in scope)(?:\*\*)?\s*:\s*(?:\*\*)?\s*(.*)$`)
+	// goalStatementPattern marks a sentence that states the objective
+	goalStatementPattern = regexp.MustCompile(`(?i)\b(?:(?:my|our|the|your|this|main|primary|overall|end|ultimate|key)\s+)+(?:goal|objective|aim|purpose|task|mission)\s+(?:here\s+)?(?:is|will be)\b|\b(?:i|we)(?:'m| am|'re| are) (?:trying|looking|hoping) to\b|\b(?:i|we) (?:want|need|would like|'d like) (?:you )?to\b|\byour (?:job|role) is to\b`)
+	// secondaryGoalPattern marks optional or additional goals
+	secondaryGoalPattern = regexp.MustCompile(`(?i)\b(?:secondary|stretch|bonus) (?:goal|objective)s?\b|\b(?:nice to have|if (?:possible|time permits|you can)|optionally|as a bonus|bonus points|ideally)\b|^\s*(?:also|additionally|in addition),?\s`)
+	// nonGoalPattern marks what the prompt rules out of scope
+	nonGoalPattern = regexp.MustCompile(`(?i)\b(?:non-goals?|out of scope|outside (?:of )?(?:the )?scope|(?:goal|aim|point|objective) (?:is|isn't) not to|(?:goal|aim|point|objective) isn't to|not (?:a|the|our|my) (?:goal|focus|priority|concern)|(?:there's |there is )?no need to|(?:you )?(?:don't|do not) (?:need|have) to|(?:is |are )?not (?:necessary|required|needed)|(?:don't|do not) worry about|(?:don't|do not) bother|(?:you can|feel free to) (?:ignore|skip))\b`)
+)
+
+// goalIntents maps the verb of a goal to its intent
+var goalIntents = map[string]string{
+	"write": "create", "build": "create", "create": "create", "generate": "create", "draft": "create",
+	"design": "create", "implement": "create", "develop": "create", "make": "create", "compose": "create", "add": "create",
+	"explain": "explain", "describe": "explain", "teach": "explain", "clarify": "explain", "understand": "explain", "learn": "explain",
+	"analyze": "analyze", "analyse": "analyze", "compare": "analyze", "evaluate": "analyze", "assess": "analyze",
+	"review": "analyze", "audit": "analyze", "investigate": "analyze", "measure": "analyze",
+	"fix": "fix", "debug": "fix", "resolve": "fix", "repair": "fix", "troubleshoot": "fix", "solve": "fix",
+	"convert": "transform", "translate": "transform", "rewrite": "transform", "refactor": "transform",
+	"summarize": "transform", "format": "transform", "migrate": "transform", "edit": "transform", "shorten": "transform",
+	"choose": "decide", "decide": "decide", "recommend": "decide", "pick": "decide", "select": "decide", "prioritize": "decide",
+	"find": "find", "research": "find", "list": "find", "identify": "find", "search": "find", "locate": "find", "extract": "find",
+}
+
+// ExtractGoals pulls the main objective, secondary goals and non-goals out of
+// a prompt. Labeled sections ("Goal:", "Non-goals:" and the list items under
+// them) and goal statements ("The goal is...", "I want you to...") count as
+// stated goals. When nothing is stated, the first instruction with a concrete
+// object stands in as the inferred primary goal.
+func ExtractGoals(text string) GoalExtraction {
+	goals := GoalExtraction{Primary: []GoalStatement{}, Secondary: []GoalStatement{}, NonGoals: []GoalStatement{}}
+	add := func(kind string, span Span, source string) {
+		g := GoalStatement{Span: span, Source: source, Intent: goalIntent(span.Text)}
+		switch kind {
+		case goalKindPrimary:
+			goals.Primary = append(goals.Primary, g)
+		case goalKindSecondary:
+			goals.Secondary = append(goals.Secondary, g)
+		case goalKindNonGoal:
+			goals.NonGoals = append(goals.NonGoals, g)
+		}
+	}
+
+	section := "" // Kind of the heading whose list we are in
+	offset := 0
+	for _, line := range strings.SplitAfter(text, "\n") {
+		lineStart := offset
+		offset += len(line)
+		line = strings.TrimRight(line, "\r\n")
+
+		if m := goalHeadingPattern.FindStringSubmatchIndex(line); m != nil {
+			section = goalHeadingKind(line[m[2]:m[3]])
+			if m[4] < m[5] {
+				add(section, trimmedSpan(text, lineStart+m[4], lineStart+m[5]), GoalFromHeading)
+				section = "" // "Goal: ..." on one line does not open a list
+			}
+			continue
+		}
+		if section != "" {
+			if strings.TrimSpace(line) == "" {
+				continue // Allow a blank line between a heading and its list
+			}
+			if marker := listItemPattern.FindString(line); marker != "" {
+				add(section, trimmedSpan(text, lineStart+len(marker), lineStart+len(line)), GoalFromHeading)
+				continue
+			}
+			section = ""
+		}
+
+		for _, s := range sentenceSpanPattern.FindAllStringIndex(line, -1) {
+			span := trimmedSpan(text, lineStart+s[0], lineStart+s[1])
+			if !strings.ContainsFunc(span.Text, unicode.IsLetter) {
+				continue
+			}
+			switch {
+			case nonGoalPattern.MatchString(span.Text):
+				add(goalKindNonGoal, span, GoalFromStatement)
+			case secondaryGoalPattern.MatchString(span.Text):
+				add(goalKindSecondary, span, GoalFromStatement)
+			case goalStatementPattern.MatchString(span.Text):
+				if len(goals.Primary) == 0 {
+					add(goalKindPrimary, span, GoalFromStatement)
+				} else {
+					add(goalKindSecondary, span, GoalFromStatement) // Later goal statements support the first
+				}
+			}
+		}
+	}
+
+	goals.Score = statedGoalScore
+	if len(goals.Primary) == 0 {
+		goals.Score = noGoalScore
+		if q, ok := firstGoalInstruction(text, goals); ok {
+			add(goalKindPrimary, q.Span, GoalFromInstruction)
+			goals.Score = inferredGoalScore
+		}
+	}
+	goals.HasClearGoal = len(goals.Primary) > 0
+	if goals.HasClearGoal {
+		goals.Intent = goals.Primary[0].Intent
+		if len(goals.Secondary) > 0 || len(goals.NonGoals) > 0 {
+			goals.Score = math.Min(100, goals.Score+goalScopeBonus)
+		}
+	}
+	return goals
+}
+
+// goalHeadingKind maps a heading label to the section it opens
+func goalHeadingKind(label string) string {
+	label = strings.ToLower(label)
+	switch {
+	case strings.HasPrefix(label, "non") || strings.Contains(label, "scope"):
+		return goalKindNonGoal
+	case strings.HasPrefix(label, "secondary"), strings.HasPrefix(label, "stretch"),
+		strings.HasPrefix(label, "bonus"), strings.HasPrefix(label, "optional"), strings.HasPrefix(label, "nice"):
+		return goalKindSecondary
+	}
+	return goalKindPrimary
+}
+
+// firstGoalInstruction returns the first instruction that names what to act
+// on and is not itself a secondary goal or non-goal
+func firstGoalInstruction(text string, goals GoalExtraction) (InstructionQuality, bool) {
+	taken := append(append([]GoalStatement{}, goals.Secondary...), goals.NonGoals...)
+	for _, q := range AnalyzeInstructions(text).Instructions {
+		if q.Object == "" {
+			continue
+		}
+		inside := false
+		for _, g := range taken {
+			inside = inside || (q.Span.Start >= g.Span.Start && q.Span.Start < g.Span.End)
+		}
+		if !inside {
+			return q, true
+		}
+	}
+	return InstructionQuality{}, false
+}
+
+// goalIntent classifies a goal by its first intent verb
+func goalIntent(goal string) string {
+	for _, w := range extractWords(goal) {
+		if intent, ok := goalIntents[w]; ok {
+			return intent
+		}
+	}
+	return ""
+}
+
+// trimmedSpan is the span of text[start:end] without surrounding whitespace
+func trimmedSpan(text string, start, end int) Span {
+	for start < end && unicode.IsSpace(rune(text[start])) {
+		start++
+	}
+	for end > start && unicode.IsSpace(rune(text[end-1])) {
+		end--
+	}
+	return newSpan(text, start, end)
+}
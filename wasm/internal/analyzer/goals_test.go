@@ -0,0 +1,72 @@
+package analyzer
+
+import "testing"
+
+func TestExtractGoalsFromStatements(t *testing.T) {
+	text := "Our goal is to cut checkout latency below 200ms. Profile the payment service first. " +
+		"If possible, also add a dashboard for p99 latency. Rewriting the frontend is out of scope."
+	goals := ExtractGoals(text)
+
+	if !goals.HasClearGoal || len(goals.Primary) != 1 {
+		t.Fatalf("expected one primary goal, got %+v", goals.Primary)
+	}
+	primary := goals.Primary[0]
+	if primary.Span.Text != "Our goal is to cut checkout latency below 200ms." || primary.Source != GoalFromStatement {
+		t.Errorf("unexpected primary goal %+v", primary)
+	}
+	if text[primary.Span.Start:primary.Span.End] != primary.Span.Text {
+		t.Errorf("primary span does not point into the text")
+	}
+	if len(goals.Secondary) != 1 || goals.Secondary[0].Intent != "create" {
+		t.Errorf("expected one secondary create goal, got %+v", goals.Secondary)
+	}
+	if len(goals.NonGoals) != 1 || goals.NonGoals[0].Span.Text != "Rewriting the frontend is out of scope." {
+		t.Errorf("expected the out of scope sentence as a non-goal, got %+v", goals.NonGoals)
+	}
+	if goals.Score != 100 {
+		t.Errorf("expected a stated, bounded goal to score 100, got %.0f", goals.Score)
+	}
+}
+
+func TestExtractGoalsFromHeadings(t *testing.T) {
+	text := "## Goals:\n- Migrate the billing tables to Postgres\n- Keep downtime under five minutes\n\n" +
+		"Non-goals:\n\n- Changing the invoice format\n\nTask: write the migration plan."
+	goals := ExtractGoals(text)
+
+	if len(goals.Primary) != 3 {
+		t.Fatalf("expected the two listed goals and the task line, got %+v", goals.Primary)
+	}
+	if goals.Primary[0].Span.Text != "Migrate the billing tables to Postgres" || goals.Primary[0].Source != GoalFromHeading {
+		t.Errorf("unexpected first goal %+v", goals.Primary[0])
+	}
+	if goals.Intent != "transform" {
+		t.Errorf("expected transform intent, got %q", goals.Intent)
+	}
+	if goals.Primary[2].Span.Text != "write the migration plan." {
+		t.Errorf("expected the text after Task: as a goal, got %q", goals.Primary[2].Span.Text)
+	}
+	if len(goals.NonGoals) != 1 || goals.NonGoals[0].Span.Text != "Changing the invoice format" {
+		t.Errorf("expected one non-goal, got %+v", goals.NonGoals)
+	}
+}
+
+func TestExtractGoalsInferredAndMissing(t *testing.T) {
+	goals := ExtractGoals("Here is some context about our app. Write unit tests for the parser in Go.")
+	if !goals.HasClearGoal || goals.Primary[0].Source != GoalFromInstruction || goals.Score != inferredGoalScore {
+		t.Fatalf("expected a goal inferred from the instruction, got %+v", goals)
+	}
+	if goals.Primary[0].Span.Text != "Write unit tests for the parser in Go" {
+		t.Errorf("unexpected inferred goal %q", goals.Primary[0].Span.Text)
+	}
+
+	goals = ExtractGoals("The weather has been strange lately. Things keep changing.")
+	if goals.HasClearGoal || goals.Score != noGoalScore || goals.Intent != "" {
+		t.Errorf("expected no goal, got %+v", goals)
+	}
+
+	// Mentioning a goal word is not a goal
+	goals = ExtractGoals("Goals matter to everyone on the team.")
+	if goals.HasClearGoal {
+		t.Errorf("expected no goal from keyword presence, got %+v", goals.Primary)
+	}
+}
@@ -0,0 +1,191 @@
+package analyzer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Run `go test ./internal/analyzer -run TestGolden -update` to rewrite the
+// golden files after an intended change, then review the diff
+var updateGolden = flag.Bool("update", false, "rewrite testdata/golden/*.json from the current analyzer")
+
+// goldenDropKeys are left out of golden snapshots wherever they appear: the
+// help text is copy, not a metric
+var goldenDropKeys = map[string]bool{"help_text": true, "scale": true, "practical_application": true}
+
+// goldenDropPaths are sections left out of golden snapshots: timings vary by
+// machine, and token lists and transformation logs bury metric changes
+var goldenDropPaths = map[string]bool{
+	"performance_metrics":               true,
+	"tokens.tokens":                     true,
+	"tokens.ngrams":                     true,
+	"preprocessing.original_text":       true,
+	"preprocessing.normalization_steps": true,
+	"preprocessing.transformation_log":  true,
+}
+
+// goldenMaxDiffs is how many drifted values a failure lists
+const goldenMaxDiffs = 20
+
+// TestGolden analyzes each testdata/golden/*.txt prompt and compares the
+// result with the .json file beside it, listing every metric that drifted
+func TestGolden(t *testing.T) {
+	inputs, err := filepath.Glob(filepath.Join("testdata", "golden", "*.txt"))
+	if err != nil || len(inputs) == 0 {
+		t.Fatalf("no golden inputs found: %v", err)
+	}
+	for _, input := range inputs {
+		name := strings.TrimSuffix(filepath.Base(input), ".txt")
+		t.Run(name, func(t *testing.T) {
+			text, err := os.ReadFile(input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := goldenSnapshot(string(text))
+			if err != nil {
+				t.Fatal(err)
+			}
+			path := strings.TrimSuffix(input, ".txt") + ".json"
+			if *updateGolden {
+				if err := os.WriteFile(path, got, 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("missing golden file (run with -update to create it): %v", err)
+			}
+			if bytes.Equal(got, want) {
+				return
+			}
+			var gotValue, wantValue interface{}
+			if err := json.Unmarshal(got, &gotValue); err != nil {
+				t.Fatal(err)
+			}
+			if err := json.Unmarshal(want, &wantValue); err != nil {
+				t.Fatalf("corrupt golden file %s: %v", path, err)
+			}
+			diffs := goldenDiff("", wantValue, gotValue, nil)
+			if len(diffs) == 0 {
+				t.Fatalf("%s differs only in formatting; run with -update", path)
+			}
+			if len(diffs) > goldenMaxDiffs {
+				diffs = append(diffs[:goldenMaxDiffs], fmt.Sprintf("... and %d more", len(diffs)-goldenMaxDiffs))
+			}
+			t.Errorf("output drifted from %s (run with -update if intended):\n%s", path, strings.Join(diffs, "\n"))
+		})
+	}
+}
+
+// goldenSnapshot is the deterministic analysis of text as indented JSON, with
+// goldenDropKeys and goldenDropPaths removed and floats rounded to 4 places so that last-digit
+// differences between platforms do not count as drift
+func goldenSnapshot(text string) ([]byte, error) {
+	opts := AnalysisOptions{Deterministic: true}
+	result, err := Analyze(context.Background(), text, opts, AnalysisRun{RequestID: "golden"})
+	if err != nil {
+		return nil, err
+	}
+	b, err := MarshalResult(result, opts)
+	if err != nil {
+		return nil, err
+	}
+	var value interface{}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if err := dec.Decode(&value); err != nil {
+		return nil, err
+	}
+	out, err := json.MarshalIndent(goldenClean("", value), "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(out, '\n'), nil
+}
+
+// goldenClean drops goldenDropKeys and goldenDropPaths and rounds floats
+// throughout a decoded value
+func goldenClean(path string, v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, child := range v {
+			childPath := strings.TrimPrefix(path+"."+k, ".")
+			if goldenDropKeys[k] || goldenDropPaths[childPath] {
+				delete(v, k)
+			} else {
+				v[k] = goldenClean(childPath, child)
+			}
+		}
+	case []interface{}:
+		for i, child := range v {
+			v[i] = goldenClean(path, child)
+		}
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		if f, err := v.Float64(); err == nil {
+			return math.Round(f*1e4) / 1e4
+		}
+	}
+	return v
+}
+
+// goldenDiff lists the paths whose values differ between two decoded snapshots
+func goldenDiff(path string, want, got interface{}, diffs []string) []string {
+	switch w := want.(type) {
+	case map[string]interface{}:
+		g, ok := got.(map[string]interface{})
+		if !ok {
+			break
+		}
+		keys := map[string]bool{}
+		for k := range w {
+			keys[k] = true
+		}
+		for k := range g {
+			keys[k] = true
+		}
+		for _, k := range sortedKeys(keys) {
+			diffs = goldenDiff(strings.TrimPrefix(path+"."+k, "."), w[k], g[k], diffs)
+		}
+		return diffs
+	case []interface{}:
+		g, ok := got.([]interface{})
+		if !ok {
+			break
+		}
+		if len(w) != len(g) {
+			return append(diffs, fmt.Sprintf("%s: length %d, was %d", path, len(g), len(w)))
+		}
+		for i := range w {
+			diffs = goldenDiff(fmt.Sprintf("%s[%d]", path, i), w[i], g[i], diffs)
+		}
+		return diffs
+	}
+	if fmt.Sprint(want) != fmt.Sprint(got) {
+		diffs = append(diffs, fmt.Sprintf("%s: %s, was %s", path, goldenValue(got), goldenValue(want)))
+	}
+	return diffs
+}
+
+// goldenValue prints a snapshot value compactly for a diff line
+func goldenValue(v interface{}) string {
+	if v == nil {
+		return "missing"
+	}
+	b, _ := json.Marshal(v)
+	if len(b) > 80 {
+		return string(b[:77]) + "..."
+	}
+	return string(b)
+}
@@ -0,0 +1,323 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Grading engines, selected with AnalysisOptions.Grader
+const (
+	GraderClassic = "classic" // PromptGrade: eight dimensions (default)
+	GraderModern  = "modern"  // ModernPromptGrade: six dimensions weighted by prompt type
+	GraderBoth    = "both"    // Both engines; the classic one leads the envelope
+)
+
+// Graders lists the accepted AnalysisOptions.Grader values
+var Graders = []string{GraderClassic, GraderModern, GraderBoth}
+
+// validateGrader rejects unknown engine names; "" means classic
+func validateGrader(engine string) error {
+	if engine != "" && !contains(Graders, engine) {
+		return fmt.Errorf("unknown grader %q (expected one of %v)", engine, Graders)
+	}
+	return nil
+}
+
+// Grader scores a prompt from the analysis stages. Both engines implement it,
+// and so can custom scorers.
+type Grader interface {
+	Name() string
+	Grade(in GradingInputs) GradedPrompt
+}
+
+// GradedPrompt is an engine's full grade, which summarizes into the shape
+// every engine shares
+type GradedPrompt interface {
+	Summary() GradeSummary
+}
+
+// GradingInputs are the analysis results a grade is computed from. The goals,
+// components and classification are derived once here rather than by each
+// engine.
+type GradingInputs struct {
+	Text           string
+	Complexity     ComplexityMetrics
+	Tokens         TokenData
+	Preprocessing  PreprocessingData
+	Ideas          IdeaAnalysisMetrics
+	TaskGraph      TaskGraph
+	Goals          GoalExtraction
+	Components     PromptComponents
+	Classification PromptClassification
+}
+
+// GradeSummary is one engine's grade in the shape every engine shares
+type GradeSummary struct {
+	Engine      string             `json:"engine"`
+	Score       float64            `json:"score"` // 0-100
+	Grade       string             `json:"grade"`
+	PromptType  string             `json:"prompt_type"`
+	Dimensions  map[string]float64 `json:"dimensions"`  // Dimension scores by snake_case name
+	Suggestions []string           `json:"suggestions"` // Rule IDs, highest priority first
+}
+
+// GradeEnvelope reports the grade of every engine that ran. Score and Grade
+// come from the first, the one selected by AnalysisOptions.Grader; consumers
+// that read prompt_grade can move here and switch engines without changing
+// shape.
+type GradeEnvelope struct {
+	Engine  string         `json:"engine"`
+	Score   float64        `json:"score"`
+	Grade   string         `json:"grade"`
+	Results []GradeSummary `json:"results"`
+}
+
+// NewGradingInputs bundles the analysis results and derives the goals,
+// components and classification both engines use
+func NewGradingInputs(text string, complexity ComplexityMetrics, tokens TokenData, preprocessing PreprocessingData, ideas IdeaAnalysisMetrics, taskGraph TaskGraph, classifier *PromptClassifier) GradingInputs {
+	return GradingInputs{
+		Text:           text,
+		Complexity:     complexity,
+		Tokens:         tokens,
+		Preprocessing:  preprocessing,
+		Ideas:          ideas,
+		TaskGraph:      taskGraph,
+		Goals:          ExtractGoals(text),
+		Components:     DetectPromptComponents(text),
+		Classification: classifier.ClassifyPrompt(text),
+	}
+}
+
+// AnalyzeForGrading runs the analysis stages a grade is computed from
+func AnalyzeForGrading(text string, classifier *PromptClassifier) GradingInputs {
+	complexity := AnalyzeComplexity(text)
+	tokens := TokenizeText(text)
+	preprocessing := PreprocessText(text)
+	ideas := AnalyzeIdeas(text)
+
+	sentences := []string{}
+	for _, cluster := range ideas.SemanticClusters.Value {
+		sentences = append(sentences, cluster.Sentences...)
+	}
+	if len(sentences) == 0 {
+		sentences = extractSentences(text)
+	}
+	taskGraph := ExtractTaskGraph(text, sentences, ideas.SemanticClusters.Value)
+	return NewGradingInputs(text, complexity, tokens, preprocessing, ideas, *taskGraph, classifier)
+}
+
+// ClassicGrader grades with the eight-dimension PromptGrade engine
+type ClassicGrader struct {
+	Options AnalysisOptions // Rules, glossary, classifier and explain mode
+}
+
+// Name identifies the engine in grade envelopes
+func (ClassicGrader) Name() string { return GraderClassic }
+
+// Grade scores the inputs as a *PromptGrade
+func (g ClassicGrader) Grade(in GradingInputs) GradedPrompt {
+	return gradeClassic(in, g.Options)
+}
+
+// Name identifies the engine in grade envelopes
+func (grader *ModernPromptGrader) Name() string { return GraderModern }
+
+// Grade scores the inputs as a *ModernPromptGrade
+func (grader *ModernPromptGrader) Grade(in GradingInputs) GradedPrompt {
+	return grader.grade(in)
+}
+
+// GradersFor returns the engines opts selects, the leading one first
+func GradersFor(opts AnalysisOptions) []Grader {
+	modern := func() Grader {
+		grader := NewModernPromptGrader()
+		if opts.Rubric != nil {
+			// Options.Validate rejects an invalid rubric
+			if g, err := NewModernPromptGraderFromConfig(*opts.Rubric); err == nil {
+				grader = g
+			}
+		}
+		grader.rules = opts.Rules.over(grader.rules)
+		if model := opts.classifierModel(); len(model.Categories) > 0 {
+			grader.classifier = NewPromptClassifierWithModel(model)
+		}
+		return grader
+	}
+	engine := opts.Grader
+	if engine == "" && opts.Rubric != nil {
+		engine = GraderModern
+	}
+	switch engine {
+	case GraderModern:
+		return []Grader{modern()}
+	case GraderBoth:
+		return []Grader{ClassicGrader{Options: opts}, modern()}
+	default:
+		return []Grader{ClassicGrader{Options: opts}}
+	}
+}
+
+// GradeAll runs each grader on the inputs and returns the envelope along with
+// each engine's full grade, in grader order
+func GradeAll(graders []Grader, in GradingInputs) (GradeEnvelope, []GradedPrompt) {
+	envelope := GradeEnvelope{Results: []GradeSummary{}}
+	graded := make([]GradedPrompt, 0, len(graders))
+	for i, g := range graders {
+		grade := g.Grade(in)
+		summary := grade.Summary()
+		summary.Engine = g.Name()
+		if i == 0 {
+			envelope.Engine, envelope.Score, envelope.Grade = summary.Engine, summary.Score, summary.Grade
+		}
+		envelope.Results = append(envelope.Results, summary)
+		graded = append(graded, grade)
+	}
+	return envelope, graded
+}
+
+// Summary reduces the classic grade to the shared shape
+func (g *PromptGrade) Summary() GradeSummary {
+	summary := GradeSummary{
+		Engine:      GraderClassic,
+		Score:       g.OverallGrade.Score,
+		Grade:       g.OverallGrade.Grade,
+		PromptType:  g.SuggestionMeta.PromptType,
+		Dimensions:  dimensionScores(g),
+		Suggestions: []string{},
+	}
+	for _, s := range g.Suggestions {
+		summary.Suggestions = append(summary.Suggestions, s.Rule)
+	}
+	return summary
+}
+
+// Summary reduces the modern grade to the shared shape
+func (g *ModernPromptGrade) Summary() GradeSummary {
+	summary := GradeSummary{
+		Engine:      GraderModern,
+		Score:       g.OverallGrade.Score,
+		Grade:       g.OverallGrade.Grade,
+		PromptType:  string(g.Classification.PrimaryType),
+		Dimensions:  modernDimensionScores(g.Dimensions),
+		Suggestions: []string{},
+	}
+	for _, s := range g.Suggestions {
+		summary.Suggestions = append(summary.Suggestions, s.Rule)
+	}
+	return summary
+}
+
+// dimensionScores maps each classic grade dimension's snake_case name, e.g.
+// "task_complexity", to its score
+func dimensionScores(grade *PromptGrade) map[string]float64 {
+	scores := map[string]float64{}
+	for _, dim := range gradeDimensionsByName(grade) {
+		scores[strings.ToLower(strings.ReplaceAll(dim.name, " ", "_"))] = dim.dimension.Score
+	}
+	return scores
+}
+
+// modernDimensionScores maps each modern dimension's name to its score
+func modernDimensionScores(d ModernDimensions) map[string]float64 {
+	scores := [6]float64{d.Clarity.Score, d.Specificity.Score, d.Completeness.Score, d.Actionability.Score, d.ContextProvision.Score, d.StructureQuality.Score}
+	out := make(map[string]float64, len(scores))
+	for i, name := range modernDimensionNames {
+		out[name] = scores[i]
+	}
+	return out
+}
+
+// leadingGrade is the full grade of the engine the envelope leads with, in
+// the shape the report, corpus and PR review render
+type leadingGrade struct {
+	Overall     OverallGrade
+	Dimensions  []reportDimension
+	Strengths   []string
+	WeakAreas   []string
+	Suggestions []Suggestion
+}
+
+// leadingGradeOf reads the grade of the engine result.Grades leads with,
+// falling back to the classic grade when the modern one is missing
+func leadingGradeOf(result *CombinedResult) leadingGrade {
+	if g := result.ModernGrade; g != nil && result.Grades.Engine == GraderModern {
+		lead := leadingGrade{
+			Overall: OverallGrade{
+				Score:      g.OverallGrade.Score,
+				Grade:      g.OverallGrade.Grade,
+				GradeColor: g.OverallGrade.GradeColor,
+				Summary:    g.OverallGrade.Summary,
+				Percentile: g.OverallGrade.Percentile,
+			},
+			Strengths:   g.Strengths,
+			WeakAreas:   g.ImprovementAreas,
+			Suggestions: modernSuggestions(g.Suggestions),
+		}
+		for _, d := range []struct {
+			name      string
+			dimension ModernDimension
+		}{
+			{"Clarity", g.Dimensions.Clarity},
+			{"Specificity", g.Dimensions.Specificity},
+			{"Completeness", g.Dimensions.Completeness},
+			{"Actionability", g.Dimensions.Actionability},
+			{"Context", g.Dimensions.ContextProvision},
+			{"Structure", g.Dimensions.StructureQuality},
+		} {
+			lead.Dimensions = append(lead.Dimensions, reportDimension{Name: d.name, Score: d.dimension.Score, Grade: d.dimension.Grade, Label: d.dimension.Label})
+		}
+		return lead
+	}
+	g := &result.PromptGrade
+	lead := leadingGrade{Overall: g.OverallGrade, Strengths: g.Strengths, WeakAreas: g.WeakAreas, Suggestions: g.Suggestions}
+	for _, d := range gradeDimensionsByName(g) {
+		lead.Dimensions = append(lead.Dimensions, reportDimension{Name: d.name, Score: d.dimension.Score, Grade: d.dimension.Grade, Label: d.dimension.Label})
+	}
+	return lead
+}
+
+// gradeSuggestions lists the suggestions of every engine that ran, the
+// leading engine's first and each rule once. Modern suggestions carry no
+// spans.
+func gradeSuggestions(result *CombinedResult) []Suggestion {
+	engines := [][]Suggestion{result.PromptGrade.Suggestions}
+	if result.ModernGrade != nil {
+		modern := modernSuggestions(result.ModernGrade.Suggestions)
+		if result.Grades.Engine == GraderModern {
+			engines = [][]Suggestion{modern, result.PromptGrade.Suggestions}
+		} else {
+			engines = append(engines, modern)
+		}
+	}
+	suggestions := []Suggestion{}
+	seen := map[string]bool{}
+	for _, list := range engines {
+		for _, s := range list {
+			if !seen[s.Rule] {
+				suggestions = append(suggestions, s)
+				seen[s.Rule] = true
+			}
+		}
+	}
+	return suggestions
+}
+
+// modernSuggestions converts modern suggestions to the classic shape
+func modernSuggestions(in []ModernSuggestion) []Suggestion {
+	out := make([]Suggestion, 0, len(in))
+	for _, s := range in {
+		out = append(out, Suggestion{Rule: s.Rule, Dimension: s.Category, Priority: s.Priority, Message: s.Title, Impact: s.Description, Example: s.Example})
+	}
+	return out
+}
+
+// classicGrade returns the result's classic grade, grading text when the
+// classic engine did not run. The rewriters work from the classic grade.
+func classicGrade(result *CombinedResult, text string) *PromptGrade {
+	for _, r := range result.Grades.Results {
+		if r.Engine == GraderClassic {
+			return &result.PromptGrade
+		}
+	}
+	return GradePromptText(text)
+}
@@ -0,0 +1,98 @@
+package analyzer
+
+import (
+	"context"
+	"testing"
+)
+
+const graderTestText = "Write a Go function that parses RFC 3339 timestamps. Return an error for invalid input and add table-driven tests."
+
+// TestGraders checks both engines grade the same inputs and share one envelope
+func TestGraders(t *testing.T) {
+	in := AnalyzeForGrading(graderTestText, NewPromptClassifier())
+	envelope, graded := GradeAll(GradersFor(AnalysisOptions{Grader: GraderBoth}), in)
+	if envelope.Engine != GraderClassic || len(envelope.Results) != 2 || len(graded) != 2 {
+		t.Fatalf("envelope = %+v", envelope)
+	}
+	classic, ok := graded[0].(*PromptGrade)
+	if !ok || envelope.Score != classic.OverallGrade.Score || envelope.Grade != classic.OverallGrade.Grade {
+		t.Errorf("envelope does not lead with the classic grade: %+v", envelope)
+	}
+	if classic.OverallGrade != GradePromptText(graderTestText).OverallGrade {
+		t.Errorf("classic grade differs from GradePromptText")
+	}
+	modern := envelope.Results[1]
+	if modern.Engine != GraderModern || len(modern.Dimensions) != 6 || modern.PromptType == "" {
+		t.Errorf("modern summary = %+v", modern)
+	}
+	if got := NewModernPromptGrader().GradeText(graderTestText).OverallGrade.Score; got != modern.Score {
+		t.Errorf("GradeText score %.2f, envelope %.2f", got, modern.Score)
+	}
+
+	if err := (AnalysisOptions{Grader: "legacy"}).Validate(); err == nil {
+		t.Error("unknown grader accepted")
+	}
+}
+
+// TestAnalyzeModernGrader checks the modern engine fills modern_grade and leads grades
+func TestAnalyzeModernGrader(t *testing.T) {
+	result, err := Analyze(context.Background(), graderTestText, AnalysisOptions{Grader: GraderModern}, AnalysisRun{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ModernGrade == nil || result.PromptGrade.OverallGrade.Grade != "" {
+		t.Fatalf("modern_grade %v, prompt_grade %+v", result.ModernGrade != nil, result.PromptGrade.OverallGrade)
+	}
+	if result.Grades.Engine != GraderModern || result.Grades.Score != result.ModernGrade.OverallGrade.Score {
+		t.Errorf("grades = %+v", result.Grades)
+	}
+	if out, ok := outcomeOf(result); !ok || out.Grades.Grade != result.ModernGrade.OverallGrade.Grade {
+		t.Errorf("webhook outcome = %+v", out)
+	}
+}
+
+// TestConsumersReadLeadingGrade checks the PR review, report, issues and
+// dataset read the modern grade when it leads the envelope
+func TestConsumersReadLeadingGrade(t *testing.T) {
+	opts := AnalysisOptions{Grader: GraderModern}
+	result, err := Analyze(context.Background(), graderTestText, opts, AnalysisRun{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	modern := result.ModernGrade.OverallGrade
+
+	review, err := ReviewPrompts(context.Background(), []CorpusDocument{{Name: "parse.prompt", Text: graderTestText}}, opts, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	graded, err := Analyze(context.Background(), graderTestText, AnalysisOptions{Grader: GraderModern, Stages: []string{StageGrade}}, AnalysisRun{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if file, want := review.Files[0], graded.ModernGrade.OverallGrade; file.Score != want.Score || file.Grade != want.Grade || !file.Passed || len(file.Suggestions) == 0 {
+		t.Errorf("review file = %+v, want score %.1f", file, want.Score)
+	}
+
+	view := newReportView(result, "Prompt")
+	if view.Overall.Score != modern.Score || len(view.Dimensions) != len(modernDimensionNames) || len(view.Suggestions) != len(result.ModernGrade.Suggestions) {
+		t.Errorf("report view overall %+v with %d dimensions", view.Overall, len(view.Dimensions))
+	}
+
+	suggestions := 0
+	for _, issue := range BuildIssues(graderTestText, result) {
+		if issue.Category == AnnotationSuggestion {
+			suggestions++
+		}
+	}
+	if suggestions != len(result.ModernGrade.Suggestions) {
+		t.Errorf("%d suggestion issues, want %d", suggestions, len(result.ModernGrade.Suggestions))
+	}
+
+	record, err := datasetRecord(context.Background(), DatasetExample{Prompt: graderTestText, Result: result}, NewRuleBasedRewriter())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if record.Score != modern.Score || record.Grade != modern.Grade || len(record.Dimensions) != len(modernDimensionNames) {
+		t.Errorf("dataset record score %.1f grade %s", record.Score, record.Grade)
+	}
+}
@@ -0,0 +1,125 @@
+//go:build !js
+
+package analyzer
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// Version is the analyzer release, set at build time with
+// -ldflags "-X fulcrum-wasm/internal/analyzer.Version=v1.4.0"
+var Version = "dev"
+
+// HealthCheckFunc reports whether a dependency such as the result store is reachable
+type HealthCheckFunc func(ctx context.Context) error
+
+// HealthConfig describes what the health endpoints report
+type HealthConfig struct {
+	Started      time.Time                  // Process start, for uptime
+	Checks       map[string]HealthCheckFunc // Readiness checks by name, e.g. "storage"
+	CheckTimeout time.Duration              // Per check; 0 means 2s
+}
+
+// CurrentBuildInfo reads the commit the binary was built from, when Go
+// recorded it
+func CurrentBuildInfo() BuildInfo {
+	info := BuildInfo{}
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	info.GoVersion = bi.GoVersion
+	for _, s := range bi.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			info.GitCommit = s.Value
+		case "vcs.time":
+			info.BuildTime = s.Value
+		case "vcs.modified":
+			info.Modified = s.Value == "true"
+		}
+	}
+	return info
+}
+
+// CurrentHealth describes the process, running the readiness checks
+// concurrently when ready is set. Status is "degraded" if any check fails.
+func CurrentHealth(ctx context.Context, cfg HealthConfig, ready bool) HealthResponse {
+	resp := HealthResponse{
+		Status:          "ok",
+		AnalyzerVersion: Version,
+		SchemaVersion:   ResultSchemaVersion,
+		Build:           CurrentBuildInfo(),
+		Workers:         WorkerPoolStats(),
+		Cache:           RegexCacheStats(),
+	}
+	if !cfg.Started.IsZero() {
+		resp.UptimeSeconds = time.Since(cfg.Started).Seconds()
+	}
+	if !ready {
+		return resp
+	}
+
+	timeout := cfg.CheckTimeout
+	if timeout <= 0 {
+		timeout = 2 * time.Second
+	}
+	names := sortedKeys(cfg.Checks)
+	resp.Checks = make([]HealthCheck, len(names))
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			start := time.Now()
+			err := cfg.Checks[name](checkCtx)
+			resp.Checks[i] = HealthCheck{Name: name, OK: err == nil, LatencyMs: float64(time.Since(start).Microseconds()) / 1000}
+			if err != nil {
+				resp.Checks[i].Error = err.Error()
+			}
+		}(i, name)
+	}
+	wg.Wait()
+	for _, c := range resp.Checks {
+		if !c.OK {
+			resp.Status = "degraded"
+		}
+	}
+	return resp
+}
+
+// LivenessHandler serves GET /health. It never runs dependency checks, so an
+// outage elsewhere does not get healthy processes restarted.
+func LivenessHandler(cfg HealthConfig) http.Handler {
+	return healthHandler(cfg, false)
+}
+
+// ReadinessHandler serves GET /health/ready, responding 503 while any check
+// fails so orchestrators stop routing traffic to the process
+func ReadinessHandler(cfg HealthConfig) http.Handler {
+	return healthHandler(cfg, true)
+}
+
+func healthHandler(cfg HealthConfig, ready bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			writeAPIError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		resp := CurrentHealth(r.Context(), cfg, ready)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		if resp.Status != "ok" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		json.NewEncoder(w).Encode(resp)
+	})
+}
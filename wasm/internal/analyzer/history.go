@@ -0,0 +1,251 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Limits on the tags an analysis can carry
+const (
+	maxTagLength  = 100
+	maxLabels     = 20
+	maxLabelChars = 50
+)
+
+// History page sizes
+const (
+	DefaultHistoryLimit = 20
+	MaxHistoryLimit     = 100
+)
+
+// Tag names reported in HistoryTagStats
+const (
+	TagProject = "project"
+	TagAuthor  = "author"
+	TagLabel   = "label"
+)
+
+// AnalysisTags organizes stored analyses, e.g. project "support-bot" with
+// labels "tone" and "escalation". They are set at submit time and returned
+// with each history entry.
+type AnalysisTags struct {
+	Project string   `json:"project,omitempty"`
+	Author  string   `json:"author,omitempty"`
+	Labels  []string `json:"labels,omitempty"`
+}
+
+// Normalize trims the tags and lowercases, dedupes and sorts the labels, so
+// "Tone" and "tone " are one label
+func (t AnalysisTags) Normalize() AnalysisTags {
+	out := AnalysisTags{Project: strings.TrimSpace(t.Project), Author: strings.TrimSpace(t.Author)}
+	seen := map[string]bool{}
+	for _, label := range t.Labels {
+		label = strings.ToLower(strings.TrimSpace(label))
+		if label != "" && !seen[label] {
+			seen[label] = true
+			out.Labels = append(out.Labels, label)
+		}
+	}
+	sort.Strings(out.Labels)
+	return out
+}
+
+// Validate checks tag lengths and the label count
+func (t AnalysisTags) Validate() error {
+	if len(t.Project) > maxTagLength {
+		return fmt.Errorf("project must be at most %d bytes", maxTagLength)
+	}
+	if len(t.Author) > maxTagLength {
+		return fmt.Errorf("author must be at most %d bytes", maxTagLength)
+	}
+	if len(t.Labels) > maxLabels {
+		return fmt.Errorf("at most %d labels are allowed, got %d", maxLabels, len(t.Labels))
+	}
+	for _, label := range t.Labels {
+		if strings.TrimSpace(label) == "" {
+			return fmt.Errorf("labels must not be empty")
+		}
+		if len(label) > maxLabelChars {
+			return fmt.Errorf("label %q is longer than %d bytes", label, maxLabelChars)
+		}
+	}
+	return nil
+}
+
+// HistoryFilter narrows GET /history to one project and author and to
+// entries carrying every listed label. Empty fields match everything.
+type HistoryFilter struct {
+	Project string   `json:"project,omitempty"`
+	Author  string   `json:"author,omitempty"`
+	Labels  []string `json:"labels,omitempty"`
+}
+
+// Matches reports whether tags pass the filter. Projects and authors compare
+// exactly; labels ignore case, as Normalize stores them.
+func (f HistoryFilter) Matches(tags AnalysisTags) bool {
+	if f.Project != "" && tags.Project != f.Project {
+		return false
+	}
+	if f.Author != "" && tags.Author != f.Author {
+		return false
+	}
+	for _, want := range f.Labels {
+		found := false
+		for _, label := range tags.Labels {
+			if strings.EqualFold(label, want) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// Query encodes the filter as /history parameters: project, author and one
+// label per label
+func (f HistoryFilter) Query() url.Values {
+	query := url.Values{}
+	if f.Project != "" {
+		query.Set("project", f.Project)
+	}
+	if f.Author != "" {
+		query.Set("author", f.Author)
+	}
+	for _, label := range f.Labels {
+		query.Add("label", label)
+	}
+	return query
+}
+
+// HistoryQuery is one GET /history request
+type HistoryQuery struct {
+	Limit  int
+	Cursor string // ID of the last entry of the previous page
+	Filter HistoryFilter
+}
+
+// ParseHistoryQuery reads limit, cursor, project, author and label
+// parameters; label may repeat
+func ParseHistoryQuery(query url.Values) (HistoryQuery, error) {
+	q := HistoryQuery{
+		Limit:  DefaultHistoryLimit,
+		Cursor: query.Get("cursor"),
+		Filter: HistoryFilter{
+			Project: strings.TrimSpace(query.Get("project")),
+			Author:  strings.TrimSpace(query.Get("author")),
+		},
+	}
+	if v := query.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > MaxHistoryLimit {
+			return HistoryQuery{}, fmt.Errorf("limit must be a whole number from 1 to %d, got %q", MaxHistoryLimit, v)
+		}
+		q.Limit = n
+	}
+	for _, label := range query["label"] {
+		if label = strings.TrimSpace(label); label != "" {
+			q.Filter.Labels = append(q.Filter.Labels, label)
+		}
+	}
+	return q, nil
+}
+
+// HistoryLister serves a history query from a result store
+type HistoryLister func(ctx context.Context, q HistoryQuery) (HistoryResponse, error)
+
+// HistoryTagStats aggregates the entries sharing one tag value
+type HistoryTagStats struct {
+	Tag          string         `json:"tag"` // project, author or label
+	Value        string         `json:"value"`
+	Count        int            `json:"count"`
+	AverageScore float64        `json:"average_score"`
+	Grades       map[string]int `json:"grades"` // Entries per letter grade
+}
+
+// TagStats aggregates entries by project, author and label, ordered by tag
+// then value. An entry counts once under each of its labels.
+func TagStats(entries []HistoryEntry) []HistoryTagStats {
+	type key struct{ tag, value string }
+	groups := map[key]*HistoryTagStats{}
+	totals := map[key]float64{}
+	add := func(tag, value string, e HistoryEntry) {
+		if value == "" {
+			return
+		}
+		k := key{tag, value}
+		s := groups[k]
+		if s == nil {
+			s = &HistoryTagStats{Tag: tag, Value: value, Grades: map[string]int{}}
+			groups[k] = s
+		}
+		s.Count++
+		totals[k] += e.Score
+		if e.Grade != "" {
+			s.Grades[e.Grade]++
+		}
+	}
+	for _, e := range entries {
+		add(TagProject, e.Tags.Project, e)
+		add(TagAuthor, e.Tags.Author, e)
+		for _, label := range e.Tags.Labels {
+			add(TagLabel, label, e)
+		}
+	}
+
+	stats := make([]HistoryTagStats, 0, len(groups))
+	for k, s := range groups {
+		s.AverageScore = math.Round(totals[k]/float64(s.Count)*10) / 10
+		stats = append(stats, *s)
+	}
+	tagOrder := map[string]int{TagProject: 0, TagAuthor: 1, TagLabel: 2}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Tag != stats[j].Tag {
+			return tagOrder[stats[i].Tag] < tagOrder[stats[j].Tag]
+		}
+		return stats[i].Value < stats[j].Value
+	})
+	return stats
+}
+
+// PageHistory answers q from entries already ordered newest first, for
+// stores that keep history in memory: it filters, computes TagStats over every
+// matching entry and returns the page after the cursor
+func PageHistory(entries []HistoryEntry, q HistoryQuery) (HistoryResponse, error) {
+	matched := []HistoryEntry{}
+	for _, e := range entries {
+		if q.Filter.Matches(e.Tags) {
+			matched = append(matched, e)
+		}
+	}
+	start := 0
+	if q.Cursor != "" {
+		start = -1
+		for i, e := range matched {
+			if e.ID == q.Cursor {
+				start = i + 1
+				break
+			}
+		}
+		if start < 0 {
+			return HistoryResponse{}, fmt.Errorf("unknown cursor %q", q.Cursor)
+		}
+	}
+	limit := q.Limit
+	if limit <= 0 {
+		limit = DefaultHistoryLimit
+	}
+	end := min(len(matched), start+limit)
+	resp := HistoryResponse{Entries: matched[start:end], Stats: TagStats(matched)}
+	if end < len(matched) {
+		resp.NextCursor = matched[end-1].ID
+	}
+	return resp, nil
+}
@@ -0,0 +1,56 @@
+package analyzer
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestAnalysisTagsNormalize(t *testing.T) {
+	tags := AnalysisTags{Project: " support-bot ", Labels: []string{"Tone", "tone ", "escalation", " "}}.Normalize()
+	want := AnalysisTags{Project: "support-bot", Labels: []string{"escalation", "tone"}}
+	if !reflect.DeepEqual(tags, want) {
+		t.Errorf("Normalize = %+v, want %+v", tags, want)
+	}
+	if err := (AnalysisTags{Labels: make([]string, maxLabels+1)}).Validate(); err == nil {
+		t.Error("expected an error for too many labels")
+	}
+}
+
+func TestPageHistoryFiltersAndAggregates(t *testing.T) {
+	entries := []HistoryEntry{
+		{ID: "e4", Score: 90, Grade: "A", Tags: AnalysisTags{Project: "coding-agent", Author: "sam", Labels: []string{"refactor"}}},
+		{ID: "e3", Score: 70, Grade: "C", Tags: AnalysisTags{Project: "support-bot", Author: "ana", Labels: []string{"escalation", "tone"}}},
+		{ID: "e2", Score: 80, Grade: "B", Tags: AnalysisTags{Project: "support-bot", Author: "sam", Labels: []string{"tone"}}},
+		{ID: "e1", Score: 60, Grade: "D", Tags: AnalysisTags{Project: "support-bot"}},
+	}
+	q, err := ParseHistoryQuery(url.Values{"project": {"support-bot"}, "label": {"Tone"}, "limit": {"1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	page, err := PageHistory(entries, q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Entries) != 1 || page.Entries[0].ID != "e3" || page.NextCursor != "e3" {
+		t.Fatalf("first page = %+v", page)
+	}
+	// Stats cover every match, not just the page
+	project := page.Stats[0]
+	if project.Tag != TagProject || project.Value != "support-bot" || project.Count != 2 || project.AverageScore != 75 || project.Grades["B"] != 1 {
+		t.Errorf("project stats = %+v", project)
+	}
+
+	q.Cursor = page.NextCursor
+	page, err = PageHistory(entries, q)
+	if err != nil || len(page.Entries) != 1 || page.Entries[0].ID != "e2" || page.NextCursor != "" {
+		t.Errorf("second page = %+v, %v", page, err)
+	}
+
+	if _, err := ParseHistoryQuery(url.Values{"limit": {"500"}}); err == nil {
+		t.Error("expected an error for a limit above MaxHistoryLimit")
+	}
+	if _, err := PageHistory(entries, HistoryQuery{Cursor: "gone"}); err == nil {
+		t.Error("expected an error for an unknown cursor")
+	}
+}
@@ -0,0 +1,50 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHyphenate(t *testing.T) {
+	cases := map[string]string{
+		"hyphenation":   "hy-phen-a-tion", // Exception list
+		"documentation": "doc-u-men-ta-tion",
+		"creation":      "cre-ation",
+		"algorithm":     "al-go-rithm",
+		"step":          "step",
+	}
+	for word, want := range cases {
+		parts, _ := englishHyphenator().Hyphenate(word)
+		if got := strings.Join(parts, "-"); got != want {
+			t.Errorf("Hyphenate(%q) = %q, want %q", word, got, want)
+		}
+	}
+}
+
+func TestEstimateSyllables(t *testing.T) {
+	cases := map[string]int{
+		"creation": 3, "queue": 1, "cooperate": 4, "museum": 3, "violin": 3, "typically": 4,
+		"played": 1, "wanted": 2, "houses": 2, "makes": 1, "table": 2, "lovely": 2,
+		"nineteen": 2, "readability": 5, "the": 1, "rhythm": 1, "JSON": 1,
+	}
+	for word, want := range cases {
+		if got := estimateSyllables(word); got.Count != want {
+			t.Errorf("estimateSyllables(%q) = %d (%s), want %d", word, got.Count, got.Method, want)
+		}
+	}
+
+	if got := estimateSyllables("queue"); got.Method != "dictionary" || got.Confidence != 1 {
+		t.Errorf("expected an exception-dictionary estimate, got %+v", got)
+	}
+	if got := estimateSyllables("naïveté"); got.Method != "heuristic" || got.Confidence >= 0.7 {
+		t.Errorf("expected a low-confidence fallback outside the patterns, got %+v", got)
+	}
+}
+
+func TestSyllableConfidenceMetric(t *testing.T) {
+	english := AnalyzeComplexity("The queue was created quickly.").SyllableStats.Confidence.Value
+	other := AnalyzeComplexity("Ça coûte très cher à Zürich.").SyllableStats.Confidence.Value
+	if english <= other || english < 0.8 {
+		t.Errorf("expected English text to be estimated with more confidence, got %.2f vs %.2f", english, other)
+	}
+}
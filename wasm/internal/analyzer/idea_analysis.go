@@ -17,11 +17,14 @@ type IdeaAnalysisMetrics struct {
 	IdeaComplexity       EnhancedFloatMetric             `json:"idea_complexity"`
 	ConceptualBreadth    EnhancedFloatMetric             `json:"conceptual_breadth"`
 	ThematicConsistency  EnhancedFloatMetric             `json:"thematic_consistency"`
+	Hedging              EnhancedFloatMetric             `json:"hedging"` // Hedging against assertive language
 	IdeaProgression      EnhancedStringMetric            `json:"idea_progression"`
 	KeyConcepts          EnhancedConceptListMetric       `json:"key_concepts"`
 	ThoughtTypeDistribution EnhancedThoughtDistribution  `json:"thought_type_distribution"`
 	QuestionAnalysis     EnhancedQuestionAnalysis        `json:"question_analysis"`
 	FactualContent       EnhancedFactualContent          `json:"factual_content"`
+	TextCoverage         EnhancedFloatMetric             `json:"text_coverage"`
+	Discourse            DiscourseAnalysis               `json:"discourse"`
 }
 
 // EnhancedIdeaClusterMetric for representing clustered ideas
@@ -43,10 +46,13 @@ type EnhancedConceptListMetric struct {
 // IdeaCluster represents a group of related sentences/ideas
 type IdeaCluster struct {
 	ID               int                `json:"id"`
-	MainTopic        string             `json:"main_topic"`
+	MainTopic        string             `json:"main_topic"`  // Label from the top keyphrases, e.g. "Billing Database"
+	KeyPhrases       []string           `json:"key_phrases"` // Most distinctive phrases, best first
+	Description      string             `json:"description"` // One-line summary of the cluster
 	ThoughtType      string             `json:"thought_type"` // "idea", "fact", "question", "opinion", "instruction", "description", "argument", "example"
 	TypeConfidence   float64            `json:"type_confidence"`
 	Sentences        []string           `json:"sentences"`
+	SentenceSpans    []Span             `json:"sentence_spans"` // Offsets of each sentence in the text
 	SentenceTypes    []SentenceType     `json:"sentence_types"` // Type classification for each sentence
 	KeyWords         []string           `json:"key_words"`
 	Coherence        float64            `json:"coherence"`
@@ -102,6 +108,8 @@ type QuestionAnalysis struct {
 	Unanswered       []string          `json:"unanswered"`
 	Rhetorical       []string          `json:"rhetorical"`
 	Actionable       []string          `json:"actionable"`
+	Answered         []string          `json:"answered"` // Questions a later sentence answers
+	Links            []QuestionLink    `json:"links"`    // One per non-rhetorical question
 }
 
 // EnhancedFactualContent provides analysis of factual content
@@ -132,28 +140,58 @@ type KeyConcept struct {
 
 // AnalyzeIdeas performs comprehensive idea extraction and analysis
 func AnalyzeIdeas(text string) IdeaAnalysisMetrics {
+	metrics, _ := AnalyzeIdeasWithBudget(text, DefaultMemoryBudget())
+	return metrics
+}
+
+// AnalyzeIdeasWithBudget runs idea analysis, sampling or skipping the expensive stages
+// so they fit within the budget, and reports which stages were degraded
+func AnalyzeIdeasWithBudget(text string, budget MemoryBudget) (IdeaAnalysisMetrics, []StageDegradation) {
+	return AnalyzeIdeasWithStopwords(text, budget, stopWords)
+}
+
+// AnalyzeIdeasWithStopwords is AnalyzeIdeasWithBudget with a custom stopword
+// set for concept and significant-term extraction
+func AnalyzeIdeasWithStopwords(text string, budget MemoryBudget, stop StopwordSet) (IdeaAnalysisMetrics, []StageDegradation) {
+	return AnalyzeIdeasWithClustering(text, budget, stop, ClusterGreedy)
+}
+
+// AnalyzeIdeasWithClustering is AnalyzeIdeasWithStopwords with a choice of
+// clustering strategy; see ClusteringStrategies
+func AnalyzeIdeasWithClustering(text string, budget MemoryBudget, stop StopwordSet, strategy string) (IdeaAnalysisMetrics, []StageDegradation) {
 	sentences := extractSentences(text)
 	words := extractWords(text)
+	plan := budget.Plan(len(sentences), len(words))
+	
+	// Sample long texts by section, keeping their edges and tasks
+	sample := sampleSentences(text, sentences, plan.ClusterSentences)
+	for i := range plan.Degraded {
+		if plan.Degraded[i].Stage == "idea_clustering" && plan.Degraded[i].Mode == DegradationSampled {
+			plan.Degraded[i].Coverage = sample.coverage
+		}
+	}
 	
 	// Core idea analysis
-	clusters := extractIdeaClusters(sentences)
-	concepts := extractKeyConcepts(sentences, words)
-	transitions := countTopicTransitions(sentences)
+	clusters := extractIdeaClusters(sample.pick(sentences), plan, stop, strategy)
+	attachSentenceSpans(text, clusters)
+	concepts := extractKeyConcepts(sentences, words, stop)
+	transitions := countTopicTransitions(sentences, stop)
 	
 	// Calculate derived metrics
 	ideaDensity := calculateIdeaDensity(clusters, len(sentences))
 	coherence := calculateConceptualCoherence(clusters)
 	complexity := calculateIdeaComplexity(clusters, concepts)
-	breadth := calculateConceptualBreadth(concepts, words)
+	breadth := calculateConceptualBreadth(concepts, words, stop)
 	consistency := calculateThematicConsistency(clusters)
 	progression := analyzeIdeaProgression(clusters)
 	
 	// Analyze thought type distribution
 	thoughtDist := analyzeThoughtTypeDistribution(clusters)
 	questionAnalysis := analyzeQuestions(clusters)
+	linkQuestionAnswers(text, &questionAnalysis)
 	factualContent := analyzeFactualContent(clusters, len(sentences))
 	
-	return IdeaAnalysisMetrics{
+	metrics := IdeaAnalysisMetrics{
 		UniqueIdeas: NewEnhancedIntMetric(
 			len(clusters),
 			"0-∞ (Count)",
@@ -202,6 +240,7 @@ func AnalyzeIdeas(text string) IdeaAnalysisMetrics {
 			"How consistently the text maintains thematic focus across ideas.",
 			"0.7+ indicates strong thematic unity; <0.5 suggests unfocused or scattered content.",
 		),
+		Hedging: calculateHedging(text),
 		IdeaProgression: NewEnhancedStringMetric(
 			progression,
 			"Progression Pattern",
@@ -232,103 +271,91 @@ func AnalyzeIdeas(text string) IdeaAnalysisMetrics {
 			HelpText:            "Analysis of factual claims including verifiable facts and statistical content.",
 			PracticalApplication: "Verify fact density and identify claims that may need citation or verification.",
 		},
+		TextCoverage: NewEnhancedFloatMetric(
+			sample.coverage,
+			"0-100 (% of text)",
+			"Share of the text's characters that idea clustering analyzed; long texts are sampled to stay within the memory budget.",
+			"100 means every sentence was clustered; lower values mean clusters reflect a sample that keeps the opening, closing, tasks and a proportional share of each section.",
+		),
+		Discourse: AnalyzeDiscourse(text),
 	}
+	return metrics, plan.Degraded
 }
 
-// extractIdeaClusters groups sentences into conceptual clusters
-func extractIdeaClusters(sentences []string) []IdeaCluster {
-	if len(sentences) == 0 {
+// extractIdeaClusters groups sentences into conceptual clusters within the plan's limits
+func extractIdeaClusters(sentences []string, plan AnalysisPlan, stop StopwordSet, strategy string) []IdeaCluster {
+	if len(sentences) == 0 || plan.ClusterSentences == 0 {
 		return []IdeaCluster{}
 	}
 	
-	// Limit analysis for very long texts to prevent memory issues
-	maxSentences := 100
-	if len(sentences) > maxSentences {
-		// Sample sentences evenly throughout the text
-		step := len(sentences) / maxSentences
-		sampledSentences := []string{}
-		for i := 0; i < len(sentences); i += step {
-			if i < len(sentences) {
-				sampledSentences = append(sampledSentences, sentences[i])
-			}
-		}
-		sentences = sampledSentences
-	}
-	
-	// Simple clustering based on keyword overlap and semantic similarity
-	clusters := []IdeaCluster{}
-	maxClusters := 20 // Limit maximum clusters to prevent memory issues
+	// Callers sample by section first; this only enforces the limit
+	sentences = sampleEvenly(sentences, plan.ClusterSentences)
 	
-	// Extract key terms from each sentence
+	// Extract key terms and their lookup sets once per sentence
 	sentenceTerms := make([][]string, len(sentences))
+	termSets := make([]map[string]bool, len(sentences))
 	for i, sentence := range sentences {
-		sentenceTerms[i] = extractSignificantTerms(sentence)
+		sentenceTerms[i] = extractSignificantTerms(sentence, stop)
+		termSets[i] = newTermSet(sentenceTerms[i])
 	}
 	
 	// Group sentences with similar terms
-	used := make([]bool, len(sentences))
-	clusterID := 0
-	
-	for i, sentence := range sentences {
-		if used[i] || clusterID >= maxClusters {
-			continue
-		}
-		
+	clusters := []IdeaCluster{}
+	for clusterID, members := range groupSentences(strategy, sentenceTerms, termSets) {
+		first := members[0]
 		cluster := IdeaCluster{
 			ID:        clusterID,
-			Sentences: []string{sentence},
-			KeyWords:  sentenceTerms[i],
-			PositionInText: getPositionLabel(i, len(sentences)),
+			Sentences: []string{sentences[first]},
+			KeyWords:  sentenceTerms[first],
+			PositionInText: getPositionLabel(first, len(sentences)),
 		}
-		
-		used[i] = true
-		
-		// Find related sentences (with a limit to prevent too large clusters)
-		maxClusterSize := 10
-		for j := i + 1; j < len(sentences) && len(cluster.Sentences) < maxClusterSize; j++ {
-			if used[j] {
-				continue
-			}
-			
-			// Lower threshold for longer texts to create fewer, larger clusters
-			threshold := 0.2
-			if len(sentences) > 50 {
-				threshold = 0.15
-			}
-			
-			similarity := calculateTermSimilarity(sentenceTerms[i], sentenceTerms[j])
-			if similarity > threshold {
-				cluster.Sentences = append(cluster.Sentences, sentences[j])
-				cluster.KeyWords = mergeKeyWords(cluster.KeyWords, sentenceTerms[j])
-				used[j] = true
-			}
+		for _, j := range members[1:] {
+			cluster.Sentences = append(cluster.Sentences, sentences[j])
+			cluster.KeyWords = mergeKeyWords(cluster.KeyWords, sentenceTerms[j])
 		}
 		
 		// Calculate cluster properties
-		cluster.MainTopic = identifyMainTopic(cluster.KeyWords)
-		cluster.Coherence = calculateClusterCoherence(cluster.Sentences)
+		cluster.Coherence = calculateClusterCoherence(sampleEvenly(members, plan.CoherenceSentences), sentenceTerms, termSets)
 		cluster.Complexity = calculateClusterComplexity(cluster.Sentences)
 		
 		// Classify the thought type of this cluster
 		classifyClusterThoughtType(&cluster)
 		
 		clusters = append(clusters, cluster)
-		clusterID++
 	}
 	
+	// Label clusters once all exist, since labels favor what sets each apart
+	labelClusters(clusters, stop)
 	return clusters
 }
 
 // extractKeyConcepts identifies the most important concepts in the text
-func extractKeyConcepts(sentences []string, words []string) []KeyConcept {
+func extractKeyConcepts(sentences []string, words []string, stop StopwordSet) []KeyConcept {
 	// Count word frequencies
 	wordFreq := make(map[string]int)
 	for _, word := range words {
-		if len(word) > 3 && !isStopWord(word) { // Filter short words and stop words
+		if len(word) > 3 && !stop.Contains(word) { // Filter short words and stop words
 			wordFreq[word]++
 		}
 	}
 	
+	// Collocations become concepts of their own; their occurrences no longer
+	// count toward their words, so "machine learning" does not also surface
+	// as "machine" and "learning"
+	runs := sentenceWordRuns(sentences)
+	phraseFreq := make(map[string]int)
+	for _, c := range findCollocations(runs, countNGrams(runs, 2), countNGrams(runs, 3), stop) {
+		phraseFreq[c.Phrase] = c.Count
+		for _, w := range c.Words {
+			if _, ok := wordFreq[w]; ok {
+				wordFreq[w] = max(0, wordFreq[w]-c.Count)
+			}
+		}
+	}
+	for phrase, freq := range phraseFreq {
+		wordFreq[phrase] = freq
+	}
+
 	// Calculate importance scores
 	concepts := []KeyConcept{}
 	for word, freq := range wordFreq {
@@ -347,8 +374,12 @@ func extractKeyConcepts(sentences []string, words []string) []KeyConcept {
 			}
 		}
 		
-		// Calculate importance based on frequency and distribution
+		// Calculate importance based on frequency and distribution, weighting
+		// multi-word phrases by their length
 		importance := float64(freq) * math.Log(float64(len(sentenceMatches))+1)
+		if _, ok := phraseFreq[word]; ok {
+			importance *= float64(len(strings.Fields(word)))
+		}
 		
 		concepts = append(concepts, KeyConcept{
 			Concept:    word,
@@ -362,7 +393,10 @@ func extractKeyConcepts(sentences []string, words []string) []KeyConcept {
 	
 	// Sort by importance and take top concepts
 	sort.Slice(concepts, func(i, j int) bool {
-		return concepts[i].Importance > concepts[j].Importance
+		if concepts[i].Importance != concepts[j].Importance {
+			return concepts[i].Importance > concepts[j].Importance
+		}
+		return concepts[i].Concept < concepts[j].Concept
 	})
 	
 	maxConcepts := 10
@@ -375,16 +409,16 @@ func extractKeyConcepts(sentences []string, words []string) []KeyConcept {
 
 // Helper functions
 
-func extractSignificantTerms(sentence string) []string {
+func extractSignificantTerms(sentence string, stop StopwordSet) []string {
 	words := strings.Fields(strings.ToLower(sentence))
 	significant := []string{}
 	
 	for _, word := range words {
 		// Clean word
-		word = regexp.MustCompile(`[^\w]`).ReplaceAllString(word, "")
+		word = nonWordCharPattern.ReplaceAllString(word, "")
 		
 		// Filter significant terms (length > 3, not stop word)
-		if len(word) > 3 && !isStopWord(word) {
+		if len(word) > 3 && !stop.Contains(word) {
 			significant = append(significant, word)
 		}
 	}
@@ -396,21 +430,33 @@ func calculateTermSimilarity(terms1, terms2 []string) float64 {
 	if len(terms1) == 0 || len(terms2) == 0 {
 		return 0
 	}
+	return termSetSimilarity(terms1, newTermSet(terms2), len(terms2))
+}
+
+// newTermSet builds a membership set so repeated comparisons avoid rebuilding it
+func newTermSet(terms []string) map[string]bool {
+	set := make(map[string]bool, len(terms))
+	for _, term := range terms {
+		set[term] = true
+	}
+	return set
+}
+
+// termSetSimilarity is the Jaccard similarity of terms1 against a precomputed set of count2 terms
+func termSetSimilarity(terms1 []string, set2 map[string]bool, count2 int) float64 {
+	if len(terms1) == 0 || count2 == 0 {
+		return 0
+	}
 	
 	// Jaccard similarity
 	intersection := 0
-	termSet2 := make(map[string]bool)
-	for _, term := range terms2 {
-		termSet2[term] = true
-	}
-	
 	for _, term := range terms1 {
-		if termSet2[term] {
+		if set2[term] {
 			intersection++
 		}
 	}
 	
-	union := len(terms1) + len(terms2) - intersection
+	union := len(terms1) + count2 - intersection
 	if union == 0 {
 		return 0
 	}
@@ -418,35 +464,29 @@ func calculateTermSimilarity(terms1, terms2 []string) float64 {
 	return float64(intersection) / float64(union)
 }
 
+// mergeKeyWords returns the distinct words of both lists in first-seen order
 func mergeKeyWords(words1, words2 []string) []string {
 	wordSet := make(map[string]bool)
-	for _, word := range words1 {
-		wordSet[word] = true
-	}
-	for _, word := range words2 {
-		wordSet[word] = true
-	}
-	
 	result := []string{}
-	for word := range wordSet {
-		result = append(result, word)
+	for _, words := range [][]string{words1, words2} {
+		for _, word := range words {
+			if !wordSet[word] {
+				wordSet[word] = true
+				result = append(result, word)
+			}
+		}
 	}
 	
 	return result
 }
 
-func identifyMainTopic(keywords []string) string {
-	if len(keywords) == 0 {
-		return "General"
+// calculateClusterCoherence averages pairwise similarity between the member sentences,
+// using term sets computed once per sentence rather than once per pair
+func calculateClusterCoherence(members []int, terms [][]string, sets []map[string]bool) float64 {
+	if len(members) == 0 {
+		return 0 // Coherence disabled by the memory budget
 	}
-	
-	// Simple heuristic: use the first significant keyword as main topic
-	// In a more sophisticated version, this would use semantic analysis
-	return strings.Title(keywords[0])
-}
-
-func calculateClusterCoherence(sentences []string) float64 {
-	if len(sentences) <= 1 {
+	if len(members) == 1 {
 		return 1.0
 	}
 	
@@ -454,11 +494,10 @@ func calculateClusterCoherence(sentences []string) float64 {
 	totalSimilarity := 0.0
 	comparisons := 0
 	
-	for i := 0; i < len(sentences); i++ {
-		for j := i + 1; j < len(sentences); j++ {
-			terms1 := extractSignificantTerms(sentences[i])
-			terms2 := extractSignificantTerms(sentences[j])
-			totalSimilarity += calculateTermSimilarity(terms1, terms2)
+	for a := 0; a < len(members); a++ {
+		for b := a + 1; b < len(members); b++ {
+			i, j := members[a], members[b]
+			totalSimilarity += termSetSimilarity(terms[i], sets[j], len(terms[j]))
 			comparisons++
 		}
 	}
@@ -505,16 +544,16 @@ func getPositionLabel(index, total int) string {
 	}
 }
 
-func countTopicTransitions(sentences []string) int {
+func countTopicTransitions(sentences []string, stop StopwordSet) int {
 	if len(sentences) <= 1 {
 		return 0
 	}
 	
 	transitions := 0
-	prevTerms := extractSignificantTerms(sentences[0])
+	prevTerms := extractSignificantTerms(sentences[0], stop)
 	
 	for i := 1; i < len(sentences); i++ {
-		currentTerms := extractSignificantTerms(sentences[i])
+		currentTerms := extractSignificantTerms(sentences[i], stop)
 		similarity := calculateTermSimilarity(prevTerms, currentTerms)
 		
 		if similarity < 0.2 { // Threshold for topic change
@@ -572,7 +611,7 @@ func calculateIdeaComplexity(clusters []IdeaCluster, concepts []KeyConcept) floa
 	return avgClusterComplexity * conceptComplexity
 }
 
-func calculateConceptualBreadth(concepts []KeyConcept, allWords []string) float64 {
+func calculateConceptualBreadth(concepts []KeyConcept, allWords []string, stop StopwordSet) float64 {
 	if len(allWords) == 0 {
 		return 0
 	}
@@ -584,7 +623,7 @@ func calculateConceptualBreadth(concepts []KeyConcept, allWords []string) float6
 	
 	uniqueAllWords := make(map[string]bool)
 	for _, word := range allWords {
-		if len(word) > 3 && !isStopWord(word) {
+		if len(word) > 3 && !stop.Contains(word) {
 			uniqueAllWords[word] = true
 		}
 	}
@@ -658,10 +697,11 @@ func extractContext(word string, sentences []string) []string {
 		if strings.Contains(strings.ToLower(sentence), word) && len(contexts) < 3 {
 			// Extract surrounding context
 			words := strings.Fields(sentence)
-			for i, w := range words {
-				if strings.ToLower(w) == word {
+			phrase := strings.Fields(word)
+			for i := 0; i+len(phrase) <= len(words); i++ {
+				if strings.ToLower(strings.Join(words[i:i+len(phrase)], " ")) == word {
 					start := max(0, i-2)
-					end := min(len(words), i+3)
+					end := min(len(words), i+len(phrase)+2)
 					context := strings.Join(words[start:end], " ")
 					contexts = append(contexts, context)
 					break
@@ -778,7 +818,8 @@ func classifyClusterThoughtType(cluster *IdeaCluster) {
 	dominantType := "idea"
 	maxConfidence := 0.0
 
-	for typeName, count := range typeCounts {
+	for _, typeName := range sortedKeys(typeCounts) {
+		count := typeCounts[typeName]
 		avgConfidence := totalConfidence[typeName] / float64(count)
 		weightedScore := float64(count) * avgConfidence
 		
@@ -796,6 +837,9 @@ func classifyClusterThoughtType(cluster *IdeaCluster) {
 	case "fact":
 		cluster.Evidence = extractEvidence(cluster.Sentences)
 		cluster.CertaintyLevel = "certain"
+		if hedging, _ := certaintyMarkers(strings.Join(cluster.Sentences, " ")); hedging > 0 {
+			cluster.CertaintyLevel = determineCertaintyLevel(cluster.Sentences)
+		}
 	case "opinion":
 		cluster.CertaintyLevel = determineCertaintyLevel(cluster.Sentences)
 	case "question":
@@ -890,12 +934,12 @@ func calculateFactScore(sent string) float64 {
 	}
 	
 	// Numeric content suggests facts
-	if regexp.MustCompile(`\d+`).MatchString(sent) {
+	if digitsPattern.MatchString(sent) {
 		score += 0.3
 	}
 	
 	// Dates suggest facts
-	if regexp.MustCompile(`\b(19|20)\d{2}\b`).MatchString(sent) {
+	if yearPattern.MatchString(sent) {
 		score += 0.2
 	}
 	
@@ -913,13 +957,13 @@ func calculateFactScore(sent string) float64 {
 
 func classifyFactType(sent string) string {
 	lower := strings.ToLower(sent)
-	if regexp.MustCompile(`\d+`).MatchString(sent) {
+	if digitsPattern.MatchString(sent) {
 		if strings.Contains(lower, "percent") || strings.Contains(lower, "%") {
 			return "statistical-fact"
 		}
 		return "numerical-fact"
 	}
-	if regexp.MustCompile(`\b(19|20)\d{2}\b`).MatchString(sent) {
+	if yearPattern.MatchString(sent) {
 		return "historical-fact"
 	}
 	if strings.Contains(lower, "located") || strings.Contains(lower, "found in") {
@@ -935,13 +979,13 @@ func getFactIndicators(sent string) []string {
 	indicators := []string{}
 	lower := strings.ToLower(sent)
 	
-	if regexp.MustCompile(`\d+`).MatchString(sent) {
+	if digitsPattern.MatchString(sent) {
 		indicators = append(indicators, "numeric content")
 	}
 	if strings.Contains(lower, " is ") || strings.Contains(lower, " are ") {
 		indicators = append(indicators, "declarative statement")
 	}
-	if regexp.MustCompile(`\b(19|20)\d{2}\b`).MatchString(sent) {
+	if yearPattern.MatchString(sent) {
 		indicators = append(indicators, "date reference")
 	}
 	
@@ -1041,7 +1085,7 @@ func calculateInstructionScore(sent string) float64 {
 	}
 	
 	// Numbered lists suggest instructions
-	if regexp.MustCompile(`^\d+[\.\)]`).MatchString(sent) {
+	if numberedLinePattern.MatchString(sent) {
 		score += 0.3
 	}
 	
@@ -1057,7 +1101,7 @@ func classifyInstructionType(sent string) string {
 	if strings.Contains(lower, "install") || strings.Contains(lower, "configure") || strings.Contains(lower, "setup") {
 		return "setup-instruction"
 	}
-	if regexp.MustCompile(`^\d+[\.\)]`).MatchString(sent) {
+	if numberedLinePattern.MatchString(sent) {
 		return "numbered-step"
 	}
 	
@@ -1080,7 +1124,7 @@ func getInstructionIndicators(sent string) []string {
 		}
 	}
 	
-	if strings.Contains(lower, "step") || regexp.MustCompile(`^\d+[\.\)]`).MatchString(sent) {
+	if strings.Contains(lower, "step") || numberedLinePattern.MatchString(sent) {
 		indicators = append(indicators, "sequential marker")
 	}
 	
@@ -1227,31 +1271,19 @@ func extractEvidence(sentences []string) []string {
 			strings.Contains(lower, "research shows") ||
 			strings.Contains(lower, "studies indicate") ||
 			strings.Contains(lower, "data reveals") ||
-			regexp.MustCompile(`\(\d{4}\)`).MatchString(sent) { // Citation years
+			citationYearPattern.MatchString(sent) { // Citation years
 			evidence = append(evidence, sent)
 		}
 	}
 	return evidence
 }
 
+// determineCertaintyLevel rates a cluster by the average certainty of its
+// sentences, weighing hedging against assertive language
 func determineCertaintyLevel(sentences []string) string {
 	certaintyScore := 0.0
-	
 	for _, sent := range sentences {
-		lower := strings.ToLower(sent)
-		
-		// High certainty
-		if strings.Contains(lower, "definitely") || strings.Contains(lower, "certainly") || strings.Contains(lower, "absolutely") {
-			certaintyScore += 1.0
-		}
-		// Medium certainty
-		if strings.Contains(lower, "probably") || strings.Contains(lower, "likely") {
-			certaintyScore += 0.5
-		}
-		// Low certainty
-		if strings.Contains(lower, "possibly") || strings.Contains(lower, "perhaps") || strings.Contains(lower, "maybe") {
-			certaintyScore += 0.2
-		}
+		certaintyScore += sentenceCertainty(sent)
 	}
 	
 	avgCertainty := certaintyScore / float64(len(sentences))
@@ -1306,8 +1338,8 @@ func analyzeThoughtTypeDistribution(clusters []IdeaCluster) ThoughtDistribution
 		"ideas": dist.Ideas,
 	}
 	
-	for typeName, count := range typeCounts {
-		if count > maxCount {
+	for _, typeName := range sortedKeys(typeCounts) {
+		if count := typeCounts[typeName]; count > maxCount {
 			maxCount = count
 			dist.DominantType = typeName
 		}
@@ -1317,8 +1349,8 @@ func analyzeThoughtTypeDistribution(clusters []IdeaCluster) ThoughtDistribution
 	total := float64(len(clusters))
 	if total > 0 {
 		entropy := 0.0
-		for _, count := range typeCounts {
-			if count > 0 {
+		for _, typeName := range sortedKeys(typeCounts) {
+			if count := typeCounts[typeName]; count > 0 {
 				p := float64(count) / total
 				entropy -= p * math.Log2(p)
 			}
@@ -1432,15 +1464,21 @@ func isRhetorical(question string) bool {
 		strings.Contains(lower, "don't you think")
 }
 
+var (
+	citationYearPattern = regexp.MustCompile(`\(\d{4}\)`)
+	fourDigitPattern    = regexp.MustCompile(`\d{4}`)
+	percentPattern      = regexp.MustCompile(`\d+\s*%`)
+)
+
 func isVerifiableFact(sentence string) bool {
 	lower := strings.ToLower(sentence)
 	// Facts with sources or specific data are verifiable
-	return regexp.MustCompile(`\d{4}`).MatchString(sentence) || // Years
+	return fourDigitPattern.MatchString(sentence) || // Years
 		strings.Contains(lower, "according to") ||
 		strings.Contains(lower, "research") ||
 		strings.Contains(lower, "study") ||
 		strings.Contains(lower, "data") ||
-		regexp.MustCompile(`\d+\s*%`).MatchString(sentence) // Percentages
+		percentPattern.MatchString(sentence) // Percentages
 }
 
 func max(a, b int) int {
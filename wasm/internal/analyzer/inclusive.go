@@ -0,0 +1,190 @@
+package analyzer
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Inclusive language categories
+const (
+	InclusiveGendered     = "gendered"     // Assumes people are men ("chairman", "manpower")
+	InclusiveAbleist      = "ableist"      // Disability used as an insult or metaphor ("crazy", "sanity check")
+	InclusiveExclusionary = "exclusionary" // Racial or slavery connotations ("blacklist", "master/slave")
+	InclusiveAge          = "age"          // Age labels that stereotype ("elderly")
+	InclusiveCustom       = "custom"       // Terms added by configuration without a category
+)
+
+// StyleKindInclusive marks style suggestions from the inclusive language checker
+const StyleKindInclusive = "inclusive_language"
+
+// InclusiveTerm is a phrase to flag and the wording to use instead
+type InclusiveTerm struct {
+	Term         string   `json:"term"`
+	Alternatives []string `json:"alternatives,omitempty"`
+	Category     string   `json:"category,omitempty"` // Defaults to custom
+}
+
+// InclusiveLanguageConfig turns on the inclusive language checker and
+// customizes its term lists
+type InclusiveLanguageConfig struct {
+	Enabled    bool            `json:"enabled,omitempty"`
+	Categories []string        `json:"categories,omitempty"` // Built-in categories to check; empty means all
+	Terms      []InclusiveTerm `json:"terms,omitempty"`      // Added terms, or replacements for built-in ones
+	Ignore     []string        `json:"ignore,omitempty"`     // Terms never reported
+}
+
+// inclusiveCategories are the built-in categories and why each is flagged
+var inclusiveCategories = map[string]string{
+	InclusiveGendered:     "Gendered wording leaves out people it doesn't name",
+	InclusiveAbleist:      "Disability terms used as metaphors or insults can alienate readers",
+	InclusiveExclusionary: "The term carries racial or slavery connotations; neutral equivalents exist",
+	InclusiveAge:          "Age labels stereotype; describe people by what is relevant",
+}
+
+// inclusiveTerms are the built-in terms keyed by lowercase phrase
+var inclusiveTerms = map[string]InclusiveTerm{}
+
+func init() {
+	add := func(category string, alternatives []string, terms ...string) {
+		for _, t := range terms {
+			inclusiveTerms[t] = InclusiveTerm{Term: t, Alternatives: alternatives, Category: category}
+		}
+	}
+
+	add(InclusiveGendered, []string{"chair", "chairperson"}, "chairman", "chairmen")
+	add(InclusiveGendered, []string{"humanity", "people"}, "mankind")
+	add(InclusiveGendered, []string{"workforce", "staff"}, "manpower")
+	add(InclusiveGendered, []string{"person-hours", "work hours"}, "man-hours", "man hours")
+	add(InclusiveGendered, []string{"synthetic", "artificial"}, "man-made")
+	add(InclusiveGendered, []string{"salesperson", "sales representative"}, "salesman", "salesmen")
+	add(InclusiveGendered, []string{"police officer"}, "policeman", "policemen")
+	add(InclusiveGendered, []string{"firefighter"}, "fireman", "firemen")
+	add(InclusiveGendered, []string{"businessperson", "executive"}, "businessman", "businessmen")
+	add(InclusiveGendered, []string{"spokesperson"}, "spokesman", "spokesmen")
+	add(InclusiveGendered, []string{"supervisor"}, "foreman", "foremen")
+	add(InclusiveGendered, []string{"intermediary", "broker"}, "middleman", "middlemen")
+	add(InclusiveGendered, []string{"first-year student"}, "freshman", "freshmen")
+	add(InclusiveGendered, []string{"mail carrier"}, "mailman", "mailmen")
+	add(InclusiveGendered, []string{"flight attendant"}, "stewardess", "stewardesses")
+	add(InclusiveGendered, []string{"everyone", "folks", "team"}, "you guys", "hey guys", "hi guys")
+
+	add(InclusiveAbleist, []string{"surprising", "wild", "unexpected"}, "crazy", "insane")
+	add(InclusiveAbleist, []string{"unconvincing", "weak"}, "lame")
+	add(InclusiveAbleist, []string{"pointless", "silly"}, "dumb")
+	add(InclusiveAbleist, []string{"hindered", "hobbled"}, "crippled", "crippling")
+	add(InclusiveAbleist, []string{"quick check", "confidence check"}, "sanity check", "sanity checks")
+	add(InclusiveAbleist, []string{"gap", "oversight"}, "blind spot", "blind spots")
+	add(InclusiveAbleist, []string{"oblivious", "unresponsive"}, "tone-deaf", "tone deaf")
+	add(InclusiveAbleist, []string{"is ignored"}, "falls on deaf ears")
+	add(InclusiveAbleist, []string{"placeholder", "sample"}, "dummy value", "dummy data")
+	add(InclusiveAbleist, []string{"unreasonable", "erratic"}, "psycho", "retarded")
+
+	add(InclusiveExclusionary, []string{"allowlist"}, "whitelist", "whitelisted", "whitelisting")
+	add(InclusiveExclusionary, []string{"denylist", "blocklist"}, "blacklist", "blacklisted", "blacklisting")
+	add(InclusiveExclusionary, []string{"primary/replica", "leader/follower"}, "master/slave", "master-slave", "master and slave")
+	add(InclusiveExclusionary, []string{"replica", "secondary"}, "slave", "slaves")
+	add(InclusiveExclusionary, []string{"main branch"}, "master branch")
+	add(InclusiveExclusionary, []string{"exempted", "legacy"}, "grandfathered", "grandfathered in")
+	add(InclusiveExclusionary, []string{"malicious hacker"}, "blackhat", "black hat")
+
+	add(InclusiveAge, []string{"older adults"}, "elderly", "senior citizens")
+}
+
+// Validate rejects empty terms and unknown built-in categories
+func (c InclusiveLanguageConfig) Validate() error {
+	for _, cat := range c.Categories {
+		if _, ok := inclusiveCategories[cat]; !ok {
+			return fmt.Errorf("unknown inclusive language category %q (expected one of %v)", cat, sortedKeys(inclusiveCategories))
+		}
+	}
+	for i, t := range c.Terms {
+		if strings.TrimSpace(t.Term) == "" {
+			return fmt.Errorf("inclusive language term %d is empty", i)
+		}
+	}
+	return nil
+}
+
+// InclusiveLanguageChecker flags non-inclusive phrasing with alternatives
+type InclusiveLanguageChecker struct {
+	terms   map[string]InclusiveTerm
+	pattern *regexp.Regexp
+}
+
+// NewInclusiveLanguageChecker builds a checker from the built-in terms in the
+// configured categories plus the configured terms. It returns nil when the
+// checker is disabled or no terms remain; a nil checker reports nothing.
+func NewInclusiveLanguageChecker(cfg InclusiveLanguageConfig) *InclusiveLanguageChecker {
+	if !cfg.Enabled {
+		return nil
+	}
+	c := &InclusiveLanguageChecker{terms: map[string]InclusiveTerm{}}
+	for key, t := range inclusiveTerms {
+		if len(cfg.Categories) == 0 || contains(cfg.Categories, t.Category) {
+			c.terms[key] = t
+		}
+	}
+	for _, t := range cfg.Terms {
+		if t.Category == "" {
+			t.Category = InclusiveCustom
+		}
+		c.terms[phraseKey(t.Term)] = t
+	}
+	for _, term := range cfg.Ignore {
+		delete(c.terms, phraseKey(term))
+	}
+	if len(c.terms) == 0 {
+		return nil
+	}
+
+	phrases := make([]string, 0, len(c.terms))
+	for key := range c.terms {
+		phrases = append(phrases, key)
+	}
+	c.pattern = phrasePattern(phrases)
+	return c
+}
+
+// Check returns a style suggestion for every flagged term in text
+func (c *InclusiveLanguageChecker) Check(text string) []StyleSuggestion {
+	if c == nil {
+		return nil
+	}
+	var suggestions []StyleSuggestion
+	for _, m := range c.pattern.FindAllStringIndex(text, -1) {
+		matched := text[m[0]:m[1]]
+		term := c.terms[phraseKey(matched)]
+		suggestions = append(suggestions, StyleSuggestion{
+			Text:       matched,
+			Position:   m[0],
+			Length:     m[1] - m[0],
+			Suggestion: inclusiveSuggestion(matched, term.Alternatives),
+			Reason:     inclusiveReason(term.Category),
+			Kind:       StyleKindInclusive,
+		})
+	}
+	return suggestions
+}
+
+// inclusiveSuggestion offers the alternatives in the case of the match
+func inclusiveSuggestion(matched string, alternatives []string) string {
+	quoted := make([]string, 0, len(alternatives))
+	for _, alt := range alternatives {
+		if alt != "" {
+			quoted = append(quoted, fmt.Sprintf("%q", matchCase(matched, alt)))
+		}
+	}
+	if len(quoted) == 0 {
+		return fmt.Sprintf("Consider rephrasing %q", matched)
+	}
+	return fmt.Sprintf("Consider %s instead of %q", strings.Join(quoted, " or "), matched)
+}
+
+// inclusiveReason explains why a term in category was flagged
+func inclusiveReason(category string) string {
+	if reason, ok := inclusiveCategories[category]; ok {
+		return reason
+	}
+	return fmt.Sprintf("Listed as %s in the configured inclusive language terms", category)
+}
@@ -0,0 +1,82 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestInclusiveLanguageDisabledByDefault(t *testing.T) {
+	if c := NewInclusiveLanguageChecker(InclusiveLanguageConfig{}); c != nil {
+		t.Fatal("checker built without being enabled")
+	}
+	var c *InclusiveLanguageChecker
+	if got := c.Check("Ask the chairman."); got != nil {
+		t.Errorf("nil checker reported %v", got)
+	}
+}
+
+func TestInclusiveLanguageFlagsBuiltInTerms(t *testing.T) {
+	c := NewInclusiveLanguageChecker(InclusiveLanguageConfig{Enabled: true})
+	text := "The Chairman wants a sanity check before we update the whitelist."
+	got := c.Check(text)
+	if len(got) != 3 {
+		t.Fatalf("got %d suggestions, want 3: %+v", len(got), got)
+	}
+	for i, want := range []string{"Chairman", "sanity check", "whitelist"} {
+		s := got[i]
+		if s.Text != want || text[s.Position:s.Position+s.Length] != want || s.Kind != StyleKindInclusive {
+			t.Errorf("suggestion %d = %+v, want %q", i, s, want)
+		}
+	}
+	if got[0].Suggestion != `Consider "Chair" or "Chairperson" instead of "Chairman"` {
+		t.Errorf("suggestion = %q", got[0].Suggestion)
+	}
+	if got[2].Reason != inclusiveCategories[InclusiveExclusionary] {
+		t.Errorf("reason = %q", got[2].Reason)
+	}
+}
+
+func TestInclusiveLanguageConfiguredTerms(t *testing.T) {
+	c := NewInclusiveLanguageChecker(InclusiveLanguageConfig{
+		Enabled:    true,
+		Categories: []string{InclusiveGendered},
+		Terms:      []InclusiveTerm{{Term: "Rockstar Developer", Alternatives: []string{"skilled developer"}}},
+		Ignore:     []string{"freshman"},
+	})
+	got := c.Check("A crazy rockstar developer and a freshman met the salesman.")
+	if len(got) != 2 {
+		t.Fatalf("got %d suggestions, want 2: %+v", len(got), got)
+	}
+	if got[0].Text != "rockstar developer" || !strings.Contains(got[0].Reason, InclusiveCustom) {
+		t.Errorf("custom term = %+v", got[0])
+	}
+	if got[1].Text != "salesman" {
+		t.Errorf("second suggestion = %+v, want salesman", got[1])
+	}
+}
+
+func TestInclusiveLanguageValidate(t *testing.T) {
+	if err := (InclusiveLanguageConfig{Categories: []string{"slang"}}).Validate(); err == nil {
+		t.Error("unknown category accepted")
+	}
+	if err := (InclusiveLanguageConfig{Terms: []InclusiveTerm{{Term: " "}}}).Validate(); err == nil {
+		t.Error("empty term accepted")
+	}
+	if _, err := ParseAnalysisOptions([]byte(`{"inclusive_language":{"enabled":true,"categories":["ableist"]}}`)); err != nil {
+		t.Errorf("valid options rejected: %v", err)
+	}
+}
+
+func TestInclusiveLanguageStyleSuggestions(t *testing.T) {
+	text := "Basically, the blacklist was updated by the team."
+	without := assessQuality(text, defaultSpellChecker(), nil)
+	with := assessQuality(text, defaultSpellChecker(), NewInclusiveLanguageChecker(InclusiveLanguageConfig{Enabled: true}))
+	if len(with.StyleSuggestions) != len(without.StyleSuggestions)+1 {
+		t.Fatalf("got %d suggestions with the checker and %d without", len(with.StyleSuggestions), len(without.StyleSuggestions))
+	}
+	for i := 1; i < len(with.StyleSuggestions); i++ {
+		if with.StyleSuggestions[i].Position < with.StyleSuggestions[i-1].Position {
+			t.Fatalf("suggestions out of order: %+v", with.StyleSuggestions)
+		}
+	}
+}
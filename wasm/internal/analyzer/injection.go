@@ -0,0 +1,42 @@
+package analyzer
+
+import "regexp"
+
+// injectionRule is one phrasing that tries to take over a model's
+// instructions, as seen when untrusted input is pasted into a prompt
+type injectionRule struct {
+	id       string
+	severity string
+	message  string
+	pattern  *regexp.Regexp
+}
+
+// injectionRules are matched against the original text, so hidden
+// characters are found before preprocessing strips them
+var injectionRules = []injectionRule{
+	{"ignore_instructions", "high", "Tells the model to ignore its earlier instructions; possible prompt injection",
+		regexp.MustCompile(`(?i)\b(ignore|disregard|forget|override)\s+(all\s+|any\s+)?(of\s+)?(the\s+|your\s+|my\s+)?(previous|prior|above|earlier|preceding|system)\s+(instructions?|prompts?|rules|directions|messages)\b`)},
+	{"prompt_leak", "high", "Asks the model to reveal its system prompt or hidden instructions",
+		regexp.MustCompile(`(?i)\b(reveal|print|show|repeat|output|leak)\s+(me\s+)?(your|the)\s+(system\s+prompt|hidden\s+instructions|initial\s+instructions|instructions\s+above)\b`)},
+	{"safety_bypass", "high", "Asks the model to bypass its safety rules",
+		regexp.MustCompile(`(?i)\b(bypass|disable|ignore|turn\s+off)\s+(your\s+|the\s+|all\s+|any\s+)?(safety|content|moderation)\s+(filters?|guidelines|policies|rules)\b`)},
+	{"role_override", "medium", "Reassigns the model's role mid-prompt; make sure this text is not untrusted input",
+		regexp.MustCompile(`(?i)\b(you\s+are\s+now|from\s+now\s+on,?\s+you\s+are|pretend\s+(to\s+be|you\s+are))\b`)},
+	{"role_marker", "medium", "Chat role marker in the text; untrusted input containing one can pose as another speaker",
+		regexp.MustCompile(`(?im)^[ \t]*(system|assistant)[ \t]*:|<\|im_start\|>|<\|im_end\|>|\[/?INST\]|<</?SYS>>`)},
+	{"hidden_text", "medium", "Invisible characters can hide instructions from reviewers",
+		regexp.MustCompile(`[\x{200B}-\x{200D}\x{2060}\x{FEFF}\x{E0000}-\x{E007F}]+`)},
+}
+
+// injectionAnnotations locates every injection risk in text
+func injectionAnnotations(text string) []Annotation {
+	annotations := []Annotation{}
+	for _, rule := range injectionRules {
+		for _, span := range patternSpans(text, rule.pattern) {
+			annotations = append(annotations, Annotation{
+				Source: AnnotationInjection, Rule: rule.id, Severity: rule.severity, Message: rule.message, Span: span,
+			})
+		}
+	}
+	return annotations
+}
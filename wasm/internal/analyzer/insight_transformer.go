@@ -15,6 +15,8 @@ type InsightAnalysis struct {
 	WritingQuality     EnhancedWritingQuality     `json:"writing_quality"`
 	Recommendations    EnhancedRecommendations    `json:"recommendations"`
 	ContentProfile     EnhancedContentProfile     `json:"content_profile"`
+	TitleSuggestions   EnhancedTitleSuggestions   `json:"title_suggestions"`
+	Outline            EnhancedOutline            `json:"outline"`
 }
 
 // EnhancedInsightListMetric for insights
@@ -57,6 +59,22 @@ type EnhancedContentProfile struct {
 	PracticalApplication string         `json:"practical_application"`
 }
 
+// EnhancedTitleSuggestions for candidate titles
+type EnhancedTitleSuggestions struct {
+	Value                []TitleSuggestion `json:"value"`
+	Scale                string            `json:"scale"`
+	HelpText             string            `json:"help_text"`
+	PracticalApplication string            `json:"practical_application"`
+}
+
+// EnhancedOutline for the proposed outline
+type EnhancedOutline struct {
+	Value                Outline `json:"value"`
+	Scale                string  `json:"scale"`
+	HelpText             string  `json:"help_text"`
+	PracticalApplication string  `json:"practical_application"`
+}
+
 // Core insight structures
 
 type Insight struct {
@@ -127,6 +145,9 @@ func TransformToInsights(
 	ideas IdeaAnalysisMetrics,
 	tokens TokenData,
 	preprocessing PreprocessingData,
+	goals GoalExtraction,
+	taskGraph TaskGraph,
+	text string,
 ) InsightAnalysis {
 	
 	// Generate main insights based on all metrics
@@ -146,6 +167,14 @@ func TransformToInsights(
 	
 	// Create summary
 	summary := generateSummary(ideaBreakdown, qualityAssessment, contentProfile)
+
+	// Propose titles and an outline to restructure the text around
+	titles := SuggestTitles(ideas.KeyConcepts.Value, goals)
+	outlineTitle := ""
+	if len(titles) > 0 {
+		outlineTitle = titles[0].Title
+	}
+	outline := GenerateOutline(text, outlineTitle, complexity.Paragraphs, ideas.SemanticClusters.Value, taskGraph)
 	
 	return InsightAnalysis{
 		Summary: NewEnhancedStringMetric(
@@ -184,6 +213,18 @@ func TransformToInsights(
 			HelpText:            "Profile of the content type, purpose, and stylistic characteristics.",
 			PracticalApplication: "Ensure content aligns with intended purpose and audience expectations.",
 		},
+		TitleSuggestions: EnhancedTitleSuggestions{
+			Value:                titles,
+			Scale:                "Candidate Titles",
+			HelpText:             "Three to five candidate titles or subject lines drawn from the primary goal and key concepts.",
+			PracticalApplication: "Start from the closest candidate when titling a draft or writing an email subject line.",
+		},
+		Outline: EnhancedOutline{
+			Value:                outline,
+			Scale:                "Headings and Bullets",
+			HelpText:             "A proposed skeleton built from the introduction, idea clusters, task hierarchy and conclusion, with each sentence placed once.",
+			PracticalApplication: "Restructure a rambling prompt or draft by filling in this outline instead of editing the original in place.",
+		},
 	}
 }
 
@@ -576,7 +617,9 @@ func profileContent(complexity ComplexityMetrics, ideas IdeaAnalysisMetrics, tok
 	// Add characteristics
 	profile.Characteristics["word_count"] = fmt.Sprintf("%d words", complexity.WordStats.TotalWords.Value)
 	profile.Characteristics["sentence_count"] = fmt.Sprintf("%d sentences", complexity.SentenceStats.TotalSentences.Value)
-	profile.Characteristics["reading_time"] = fmt.Sprintf("%.1f minutes", float64(complexity.WordStats.TotalWords.Value)/200.0)
+	profile.Characteristics["reading_time"] = formatMinutes(complexity.ReadingTime.Value)
+	profile.Characteristics["speaking_time"] = formatMinutes(complexity.SpeakingTime.Value)
+	profile.Characteristics["skimming_time"] = formatMinutes(complexity.SkimmingTime.Value)
 	profile.Characteristics["complexity_level"] = determineComplexityLevel(complexity)
 	
 	return profile
@@ -604,6 +647,9 @@ func generateSummary(breakdown IdeaBreakdown, quality WritingQuality, profile Co
 }
 
 func generateIdeaSummary(cluster IdeaCluster) string {
+	if cluster.Description != "" {
+		return fmt.Sprintf("%s: %s", cluster.MainTopic, cluster.Description)
+	}
 	if len(cluster.KeyWords) > 0 {
 		return fmt.Sprintf("%s: %s", cluster.MainTopic, strings.Join(cluster.KeyWords[:min(3, len(cluster.KeyWords))], ", "))
 	}
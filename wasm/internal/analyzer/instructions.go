@@ -0,0 +1,241 @@
+package analyzer
+
+import (
+	"math"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// InstructionQuality rates one imperative instruction on whether it says what
+// to act on, how, in what order and what should come out of it
+type InstructionQuality struct {
+	Span         Span     `json:"span"`
+	Verb         string   `json:"verb"`
+	Object       string   `json:"object,omitempty"`  // What the verb acts on, e.g. "unit tests"
+	Parameters   []string `json:"parameters"`        // Qualifiers such as "in Go", "under 200 words"
+	Order        string   `json:"order,omitempty"`   // Sequence cue such as "2." or "then"
+	Outcome      string   `json:"outcome,omitempty"` // Expected result, e.g. "so that the build passes"
+	Completeness float64  `json:"completeness"`      // 0-1 weighted share of the elements present
+	Missing      []string `json:"missing"`           // Elements not found: object, parameters, order, outcome
+}
+
+// InstructionAnalysis rates every instruction in a prompt
+type InstructionAnalysis struct {
+	Instructions        []InstructionQuality `json:"instructions"`
+	AverageCompleteness float64              `json:"average_completeness"`
+	Incomplete          int                  `json:"incomplete"` // Instructions below half complete
+	Score               float64              `json:"score"`      // 0-100; 60 when the prompt has no instructions
+}
+
+// Instruction element weights. Order only applies when the prompt lays out
+// several steps and at least one carries a sequence cue; otherwise the other
+// weights are rescaled.
+const (
+	instructionObjectWeight     = 0.35
+	instructionParametersWeight = 0.25
+	instructionOrderWeight      = 0.15
+	instructionOutcomeWeight    = 0.25
+)
+
+// instructionVerbs open an imperative sentence
+var instructionVerbs = map[string]bool{
+	"add": true, "analyze": true, "answer": true, "assess": true, "avoid": true, "begin": true, "build": true,
+	"calculate": true, "call": true, "check": true, "choose": true, "cite": true, "classify": true, "compare": true,
+	"compute": true, "configure": true, "convert": true, "create": true, "debug": true, "define": true, "delete": true,
+	"deploy": true, "describe": true, "design": true, "document": true, "draft": true, "edit": true, "ensure": true,
+	"evaluate": true, "explain": true, "extract": true, "fetch": true, "filter": true, "find": true, "fix": true,
+	"focus": true, "format": true, "generate": true, "give": true, "handle": true, "highlight": true, "identify": true,
+	"implement": true, "include": true, "insert": true, "install": true, "investigate": true, "keep": true, "limit": true,
+	"list": true, "load": true, "log": true, "make": true, "merge": true, "migrate": true, "move": true, "optimize": true,
+	"outline": true, "output": true, "parse": true, "plan": true, "print": true, "propose": true, "provide": true,
+	"recommend": true, "refactor": true, "remove": true, "rename": true, "replace": true, "research": true,
+	"respond": true, "return": true, "review": true, "rewrite": true, "run": true, "save": true, "select": true,
+	"send": true, "set": true, "show": true, "sort": true, "split": true, "store": true, "suggest": true,
+	"summarize": true, "tell": true, "test": true, "translate": true, "update": true, "use": true, "validate": true,
+	"verify": true, "write": true,
+}
+
+// deliverableVerbs name the expected output themselves ("return the list")
+var deliverableVerbs = map[string]bool{
+	"output": true, "print": true, "respond": true, "return": true, "answer": true,
+}
+
+// nonImperativeFollowers mark a sentence whose first word is a noun, as in
+// "Test results are attached" or "Review comments should be short"
+var nonImperativeFollowers = map[string]bool{
+	"is": true, "are": true, "was": true, "were": true, "will": true, "can": true, "could": true,
+	"should": true, "has": true, "have": true, "had": true, "results": true, "comments": true,
+}
+
+var (
+	// instructionLeadPattern strips politeness, modals and negation before the verb
+	instructionLeadPattern = regexp.MustCompile(`(?i)^(?:(?:please|kindly|now|also|and|then|next|first|firstly|second|secondly|third|finally|lastly|after that|afterwards)\b,?\s*|(?:you (?:should|must|need to|will)|make sure to|be sure to|remember to|i (?:need|want|would like) you to|can you|could you|would you|do not|don't|never|always)\s+)+`)
+	// instructionParameterPattern marks qualifiers that narrow how to do the task
+	instructionParameterPattern = regexp.MustCompile(`(?i)\b(?:using|with|without|in|into|as|for|from|via|within|under|over|at (?:least|most)|no (?:more|less|longer|shorter) than|up to|only|between|per)\s+`)
+	// instructionLiteralPattern finds numbers and quoted or code values
+	instructionLiteralPattern = regexp.MustCompile("\\d+(?:\\.\\d+)?%?|\"[^\"]+\"|`[^`]+`")
+	// instructionOutcomePattern introduces the result the instruction should produce
+	instructionOutcomePattern = regexp.MustCompile(`(?i)\b(?:so that|so it|in order to|to ensure|to make sure|such that|until|resulting in|which (?:should|must|will)|that (?:returns?|outputs?|prints?|shows?|passes|produces?|lists?)|(?:it|this|they|the \w+) (?:should|must) (?:return|output|print|show|pass|produce|be|contain|include)|and (?:return|output|print|report)|returning|expect(?:ed|ing)?)\b`)
+	// instructionObjectStopPattern ends the object at the first qualifier or clause
+	instructionObjectStopPattern = regexp.MustCompile(`(?i)[,;:(]|\s(?:using|with|without|in|into|as|for|from|via|within|under|over|at|to|so|that|which|and then|then|before|after|until|only|by|on)\s`)
+)
+
+// AnalyzeInstructions finds imperative sentences and rates each for
+// completeness. An instruction is complete when it names a concrete object
+// ("write unit tests", not "fix it"), narrows the task with parameters
+// ("in Go", "under 200 words"), carries a sequence cue when the prompt is
+// written as steps ("2.", "then") and states the expected outcome ("so that the
+// build passes", "return the list").
+func AnalyzeInstructions(text string) InstructionAnalysis {
+	analysis := InstructionAnalysis{Instructions: []InstructionQuality{}, Score: 60}
+
+	for _, s := range sentenceSpanPattern.FindAllStringIndex(text, -1) {
+		start, end := s[0], s[1]
+		lineStart := strings.LastIndexByte(text[:start], '\n') + 1
+		marker := listItemPattern.FindStringSubmatch(text[lineStart:])
+		if marker != nil && start < lineStart+len(marker[0]) {
+			start = lineStart + len(marker[0]) // "- Write tests" starts at the verb
+		}
+		for start < end && unicode.IsSpace(rune(text[start])) {
+			start++
+		}
+		if start >= end {
+			continue // A bare list number such as "1."
+		}
+		sentence := strings.TrimRight(text[start:end], " \t.!?")
+		if sentence == "" || !strings.ContainsFunc(sentence, unicode.IsLetter) {
+			continue
+		}
+
+		lead := instructionLeadPattern.FindString(sentence)
+		// A question counts only as a request ("Could you write ...?")
+		if strings.HasSuffix(strings.TrimSpace(text[start:end]), "?") && !strings.Contains(strings.ToLower(lead), "you") {
+			continue
+		}
+		rest := strings.TrimSpace(sentence[len(lead):])
+		words := strings.Fields(rest)
+		if len(words) == 0 {
+			continue
+		}
+		verb := strings.ToLower(strings.Trim(words[0], ",:;"))
+		if !instructionVerbs[verb] || (len(words) > 1 && nonImperativeFollowers[strings.ToLower(words[1])]) {
+			continue
+		}
+
+		q := InstructionQuality{Span: newSpan(text, start, start+len(sentence)), Verb: verb, Parameters: []string{}, Missing: []string{}}
+		body := strings.TrimSpace(rest[len(words[0]):])
+		q.Object = instructionObject(body)
+		q.Parameters = instructionParameters(body)
+		if m := instructionOutcomePattern.FindStringIndex(body); m != nil {
+			q.Outcome = strings.TrimSpace(body[m[0]:])
+		} else if deliverableVerbs[verb] && q.Object != "" {
+			q.Outcome = verb + " " + q.Object
+		}
+		if marker != nil && (marker[2] != "" || marker[3] != "" || marker[4] != "") && s[0] <= lineStart+len(marker[0]) {
+			q.Order = strings.TrimSpace(marker[0])
+		} else if cue := strings.TrimSpace(strings.TrimRight(strings.ToLower(lead), ", ")); cue != "" && discourseMarkers[firstCue(cue)] == DiscourseTemporal {
+			q.Order = firstCue(cue)
+		}
+		analysis.Instructions = append(analysis.Instructions, q)
+	}
+
+	if len(analysis.Instructions) == 0 {
+		return analysis
+	}
+	// Order matters once the prompt lays out steps; free-standing requests need none
+	sequenced := false
+	for _, q := range analysis.Instructions {
+		sequenced = sequenced || q.Order != ""
+	}
+	sequenced = sequenced && len(analysis.Instructions) > 1
+	total := 0.0
+	for i := range analysis.Instructions {
+		q := &analysis.Instructions[i]
+		q.Completeness = instructionCompleteness(q, sequenced)
+		total += q.Completeness
+		if q.Completeness < 0.5 {
+			analysis.Incomplete++
+		}
+	}
+	analysis.AverageCompleteness = math.Round(total/float64(len(analysis.Instructions))*100) / 100
+	analysis.Score = math.Round(analysis.AverageCompleteness*100*100) / 100
+	return analysis
+}
+
+// firstCue returns the temporal marker a lead phrase opens with, if any
+func firstCue(lead string) string {
+	for _, cue := range []string{"after that", "afterwards", "firstly", "first", "secondly", "second", "third", "then", "next", "finally", "lastly"} {
+		if strings.HasPrefix(lead, cue) {
+			return cue
+		}
+	}
+	return ""
+}
+
+// instructionObject returns the phrase the verb acts on, up to the first
+// qualifier, or "" when it has no content word ("fix it", "do this")
+func instructionObject(body string) string {
+	object := body
+	if m := instructionObjectStopPattern.FindStringIndex(" " + body + " "); m != nil {
+		object = body[:max(m[0]-1, 0)]
+	}
+	object = strings.TrimSpace(object)
+	for _, w := range extractWords(object) {
+		if !isStopWord(w) {
+			return object
+		}
+	}
+	return ""
+}
+
+// instructionParameters lists the qualifier phrases and literal values in an
+// instruction body, stopping each phrase at the next qualifier or clause
+func instructionParameters(body string) []string {
+	params := []string{}
+	if m := instructionOutcomePattern.FindStringIndex(body); m != nil {
+		body = body[:m[0]] // "so that ..." is the outcome, not a parameter
+	}
+	for _, m := range instructionParameterPattern.FindAllStringIndex(body, -1) {
+		phrase := body[m[0]:]
+		if next := instructionParameterPattern.FindStringIndex(phrase[m[1]-m[0]:]); next != nil {
+			phrase = phrase[:m[1]-m[0]+next[0]]
+		}
+		if cut := strings.IndexAny(phrase, ",;:("); cut >= 0 {
+			phrase = phrase[:cut]
+		}
+		if phrase = strings.TrimSpace(phrase); len(strings.Fields(phrase)) > 1 {
+			params = append(params, phrase)
+		}
+	}
+	for _, lit := range instructionLiteralPattern.FindAllString(body, -1) {
+		covered := false
+		for _, p := range params {
+			covered = covered || strings.Contains(p, lit)
+		}
+		if !covered {
+			params = append(params, lit)
+		}
+	}
+	return params
+}
+
+// instructionCompleteness weighs the elements found and records the missing ones
+func instructionCompleteness(q *InstructionQuality, sequenced bool) float64 {
+	score, possible := 0.0, 0.0
+	check := func(name string, present bool, weight float64) {
+		possible += weight
+		if present {
+			score += weight
+		} else {
+			q.Missing = append(q.Missing, name)
+		}
+	}
+	check("object", q.Object != "", instructionObjectWeight)
+	check("parameters", len(q.Parameters) > 0, instructionParametersWeight)
+	if sequenced {
+		check("order", q.Order != "", instructionOrderWeight)
+	}
+	check("outcome", q.Outcome != "", instructionOutcomeWeight)
+	return math.Round(score/possible*100) / 100
+}
@@ -0,0 +1,96 @@
+package analyzer
+
+import "testing"
+
+func TestAnalyzeInstructions(t *testing.T) {
+	text := "You are a senior Go developer.\n\n" +
+		"1. Write unit tests for the parser in Go so that every branch is covered.\n" +
+		"2. Fix it.\n" +
+		"3. Then return the coverage report as JSON."
+
+	got := AnalyzeInstructions(text)
+	if len(got.Instructions) != 3 {
+		t.Fatalf("expected 3 instructions, got %+v", got.Instructions)
+	}
+
+	first := got.Instructions[0]
+	if first.Verb != "write" || first.Object != "unit tests" || first.Order != "1." ||
+		first.Outcome != "so that every branch is covered" || first.Completeness != 1 {
+		t.Errorf("unexpected first instruction: %+v", first)
+	}
+	if len(first.Parameters) != 2 || first.Parameters[0] != "for the parser" || first.Parameters[1] != "in Go" {
+		t.Errorf("expected the parser and language parameters, got %q", first.Parameters)
+	}
+	if text[first.Span.Start:first.Span.End] != first.Span.Text || first.Span.Text[:5] != "Write" {
+		t.Errorf("span should start at the verb, got %+v", first.Span)
+	}
+
+	vague := got.Instructions[1]
+	if vague.Object != "" || len(vague.Missing) != 3 || vague.Completeness >= 0.5 {
+		t.Errorf("expected \"Fix it\" to lack object, parameters and outcome, got %+v", vague)
+	}
+	if last := got.Instructions[2]; last.Outcome == "" || last.Object != "the coverage report" {
+		t.Errorf("expected return to name its deliverable, got %+v", last)
+	}
+	if got.Incomplete != 1 || got.Score != 72 {
+		t.Errorf("expected one incomplete instruction and a score of 72, got %d and %.1f", got.Incomplete, got.Score)
+	}
+}
+
+func TestAnalyzeInstructionsDetection(t *testing.T) {
+	cases := []struct {
+		text  string
+		verbs []string
+	}{
+		{"Please summarize this article in under 200 words. Do not use bullet points.", []string{"summarize", "use"}},
+		{"Could you list the key dates? What is the deadline?", []string{"list"}},
+		{"Test results are attached. Review comments should be short. Explain the tradeoffs.", []string{"explain"}},
+		{"The service is slow.", nil},
+	}
+	for _, tc := range cases {
+		got := AnalyzeInstructions(tc.text)
+		verbs := []string{}
+		for _, q := range got.Instructions {
+			verbs = append(verbs, q.Verb)
+		}
+		if len(verbs) != len(tc.verbs) {
+			t.Errorf("%q: got instructions %q, want %q", tc.text, verbs, tc.verbs)
+			continue
+		}
+		for i := range verbs {
+			if verbs[i] != tc.verbs[i] {
+				t.Errorf("%q: got instructions %q, want %q", tc.text, verbs, tc.verbs)
+				break
+			}
+		}
+		// Free-standing requests are not judged on order
+		for _, q := range got.Instructions {
+			if contains(q.Missing, "order") {
+				t.Errorf("%q: unsequenced instruction %q should not miss order", tc.text, q.Span.Text)
+			}
+		}
+	}
+	if got := AnalyzeInstructions("The service is slow."); got.Score != 60 {
+		t.Errorf("expected the neutral score without instructions, got %.1f", got.Score)
+	}
+}
+
+func TestInstructionCompletenessFeedsActionability(t *testing.T) {
+	factor := func(text string) Factor {
+		for _, f := range GradePromptText(text).Actionability.Factors {
+			if f.Name == "Instruction Completeness" {
+				return f
+			}
+		}
+		t.Fatalf("no Instruction Completeness factor for %q", text)
+		return Factor{}
+	}
+	vague := factor("Fix it. Do this.")
+	precise := factor("Rewrite the login handler in Go so that it returns 401 for expired tokens.")
+	if precise.Value <= vague.Value {
+		t.Errorf("expected a complete instruction to score higher (%.1f vs %.1f)", precise.Value, vague.Value)
+	}
+	if len(vague.Spans) == 0 {
+		t.Error("expected spans on the incomplete instructions")
+	}
+}
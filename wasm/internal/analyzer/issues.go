@@ -0,0 +1,85 @@
+package analyzer
+
+import "sort"
+
+// Issue severities, most severe first. Suggestion priorities, quality
+// severities and injection and PII risks all use this scale.
+const (
+	SeverityCritical = "critical"
+	SeverityHigh     = "high"
+	SeverityMedium   = "medium"
+	SeverityLow      = "low"
+)
+
+// severityRank orders severities for ranking; unknown ones sort last
+var severityRank = map[string]int{SeverityCritical: 0, SeverityHigh: 1, SeverityMedium: 2, SeverityLow: 3}
+
+// issueCategories lists the Issue categories, in the order equal-severity
+// issues are ranked: risks before advice, advice before mechanics
+var issueCategories = []string{AnnotationInjection, AnnotationPII, AnnotationSuggestion, AnnotationGrammar, AnnotationQuality, AnnotationSpelling, AnnotationStyle}
+
+// Issue is one problem with the prompt, whichever stage found it
+type Issue struct {
+	Severity  string `json:"severity"` // One of the Severity* values
+	Category  string `json:"category"` // suggestion, injection, pii, grammar, quality, spelling or style
+	Rule      string `json:"rule"`     // Suggestion rule ID, grammar rule, PII kind, injection rule; the category when the finding has none
+	Message   string `json:"message"`
+	Dimension string `json:"dimension,omitempty"` // Grade dimension a suggestion improves
+	Spans     []Span `json:"spans"`               // Where it occurs; empty for issues with the prompt as a whole
+}
+
+// BuildIssues merges the suggestions of every engine that ran with the
+// located spelling, grammar, style, quality, PII and injection findings into
+// one list, most severe first. Equal severities rank by category, then by
+// position, with issues about the whole prompt ahead of located ones.
+func BuildIssues(text string, result *CombinedResult) []Issue {
+	issues := []Issue{}
+	for _, s := range gradeSuggestions(result) {
+		spans := []Span{}
+		for _, span := range s.Spans {
+			if span.Start >= 0 && span.End <= len(text) && span.Start < span.End {
+				spans = append(spans, span)
+			}
+		}
+		issues = append(issues, Issue{Severity: s.Priority, Category: AnnotationSuggestion, Rule: s.Rule, Message: s.Message, Dimension: s.Dimension, Spans: spans})
+	}
+
+	for _, a := range result.Annotations {
+		if !contains(issueCategories, a.Source) || a.Source == AnnotationSuggestion {
+			continue // Suggestions are merged above, one issue per rule
+		}
+		rule := a.Rule
+		if rule == "" {
+			rule = a.Source
+		}
+		issues = append(issues, Issue{Severity: a.Severity, Category: a.Source, Rule: rule, Message: a.Message, Spans: []Span{a.Span}})
+	}
+
+	rank := func(severity string) int {
+		if r, ok := severityRank[severity]; ok {
+			return r
+		}
+		return len(severityRank)
+	}
+	category := map[string]int{}
+	for i, c := range issueCategories {
+		category[c] = i
+	}
+	start := func(issue Issue) int {
+		if len(issue.Spans) == 0 {
+			return -1
+		}
+		return issue.Spans[0].Start
+	}
+	sort.SliceStable(issues, func(i, j int) bool {
+		a, b := issues[i], issues[j]
+		if rank(a.Severity) != rank(b.Severity) {
+			return rank(a.Severity) < rank(b.Severity)
+		}
+		if category[a.Category] != category[b.Category] {
+			return category[a.Category] < category[b.Category]
+		}
+		return start(a) < start(b)
+	})
+	return issues
+}
@@ -0,0 +1,218 @@
+//go:build !js
+
+package analyzer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// LLMRewriterConfig selects the model endpoint used for rewrites
+type LLMRewriterConfig struct {
+	Provider  string        `json:"provider"` // "openai", "anthropic" or "local" (OpenAI-compatible)
+	Endpoint  string        `json:"endpoint"`
+	APIKey    string        `json:"-"`
+	Model     string        `json:"model"`
+	MaxTokens int           `json:"max_tokens"`
+	Timeout   time.Duration `json:"timeout"`
+}
+
+// LLMRewriter asks a language model to improve a prompt using its grade report
+type LLMRewriter struct {
+	config LLMRewriterConfig
+	client *http.Client
+}
+
+// LLMRewriterConfigFromEnv reads the rewriter configuration from FULCRUM_LLM_* variables
+func LLMRewriterConfigFromEnv() LLMRewriterConfig {
+	cfg := LLMRewriterConfig{
+		Provider: os.Getenv("FULCRUM_LLM_PROVIDER"),
+		Endpoint: os.Getenv("FULCRUM_LLM_ENDPOINT"),
+		APIKey:   os.Getenv("FULCRUM_LLM_API_KEY"),
+		Model:    os.Getenv("FULCRUM_LLM_MODEL"),
+	}
+	if d, err := time.ParseDuration(os.Getenv("FULCRUM_LLM_TIMEOUT")); err == nil {
+		cfg.Timeout = d
+	}
+	return cfg
+}
+
+// NewLLMRewriter validates the configuration and fills in provider defaults
+func NewLLMRewriter(cfg LLMRewriterConfig) (*LLMRewriter, error) {
+	cfg.Provider = strings.ToLower(cfg.Provider)
+	switch cfg.Provider {
+	case "openai":
+		if cfg.Endpoint == "" {
+			cfg.Endpoint = "https://api.openai.com/v1/chat/completions"
+		}
+	case "anthropic":
+		if cfg.Endpoint == "" {
+			cfg.Endpoint = "https://api.anthropic.com/v1/messages"
+		}
+	case "local":
+		if cfg.Endpoint == "" {
+			return nil, fmt.Errorf("local provider requires an endpoint")
+		}
+	default:
+		return nil, fmt.Errorf("unknown LLM provider %q", cfg.Provider)
+	}
+
+	if cfg.Model == "" {
+		return nil, fmt.Errorf("model is required")
+	}
+	if cfg.APIKey == "" && cfg.Provider != "local" {
+		return nil, fmt.Errorf("%s provider requires an API key", cfg.Provider)
+	}
+	if cfg.MaxTokens <= 0 {
+		cfg.MaxTokens = 1024
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 30 * time.Second
+	}
+
+	return &LLMRewriter{config: cfg, client: &http.Client{Timeout: cfg.Timeout}}, nil
+}
+
+// Name returns the rewriter identifier
+func (r *LLMRewriter) Name() string {
+	return "llm_" + r.config.Provider
+}
+
+// Rewrite sends the prompt and its grade report to the configured model
+func (r *LLMRewriter) Rewrite(ctx context.Context, text string, grade *PromptGrade) (string, error) {
+	rewritten, err := r.Complete(ctx, buildRewriteInstruction(text, grade))
+	if err != nil {
+		return "", fmt.Errorf("rewrite: %w", err)
+	}
+	return rewritten, nil
+}
+
+// Complete sends prompt to the configured model as the only user message
+// and returns its reply. With the local provider pointed at Ollama's
+// OpenAI-compatible endpoint, this runs prompts against local models.
+func (r *LLMRewriter) Complete(ctx context.Context, prompt string) (string, error) {
+	// Anthropic messages and OpenAI-compatible chat completions share this request shape
+	payload := map[string]interface{}{
+		"model":      r.config.Model,
+		"max_tokens": r.config.MaxTokens,
+		"messages":   []map[string]string{{"role": "user", "content": prompt}},
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.config.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if r.config.Provider == "anthropic" {
+		req.Header.Set("x-api-key", r.config.APIKey)
+		req.Header.Set("anthropic-version", "2023-06-01")
+	} else if r.config.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+r.config.APIKey)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("model request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("model request returned %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+
+	reply, err := parseRewriteResponse(r.config.Provider, data)
+	if err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(reply) == "" {
+		return "", fmt.Errorf("model returned an empty reply")
+	}
+	return strings.TrimSpace(reply), nil
+}
+
+// EvaluateModel runs prompt against the configured model and evaluates the
+// reply with EvaluateResponse
+func (r *LLMRewriter) EvaluateModel(ctx context.Context, prompt string) (string, ResponseEvaluation, error) {
+	reply, err := r.Complete(ctx, prompt)
+	if err != nil {
+		return "", ResponseEvaluation{}, err
+	}
+	return reply, EvaluateResponse(prompt, reply), nil
+}
+
+// parseRewriteResponse extracts the generated text from a provider response
+func parseRewriteResponse(provider string, data []byte) (string, error) {
+	if provider == "anthropic" {
+		var resp struct {
+			Content []struct {
+				Type string `json:"type"`
+				Text string `json:"text"`
+			} `json:"content"`
+		}
+		if err := json.Unmarshal(data, &resp); err != nil {
+			return "", fmt.Errorf("invalid anthropic response: %w", err)
+		}
+		var b strings.Builder
+		for _, block := range resp.Content {
+			if block.Type == "text" {
+				b.WriteString(block.Text)
+			}
+		}
+		return b.String(), nil
+	}
+
+	var resp struct {
+		Choices []struct {
+			Message struct {
+				Content string `json:"content"`
+			} `json:"message"`
+		} `json:"choices"`
+	}
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return "", fmt.Errorf("invalid chat completion response: %w", err)
+	}
+	if len(resp.Choices) == 0 {
+		return "", fmt.Errorf("chat completion response has no choices")
+	}
+	return resp.Choices[0].Message.Content, nil
+}
+
+// buildRewriteInstruction turns the grade report into instructions for the model
+func buildRewriteInstruction(text string, grade *PromptGrade) string {
+	var b strings.Builder
+	b.WriteString("Rewrite the prompt below so it is clearer, more specific and easier to act on. ")
+	b.WriteString("Keep the author's intent and facts; do not invent requirements. ")
+	b.WriteString("Reply with the rewritten prompt only.\n\n")
+
+	if grade != nil {
+		fmt.Fprintf(&b, "Current grade: %s (%.0f/100).\n", grade.OverallGrade.Grade, grade.OverallGrade.Score)
+		if len(grade.Suggestions) > 0 {
+			b.WriteString("Address these issues:\n")
+			for _, s := range grade.Suggestions {
+				fmt.Fprintf(&b, "- [%s] %s\n", s.Dimension, s.Message)
+			}
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("Prompt:\n\"\"\"\n")
+	b.WriteString(text)
+	b.WriteString("\n\"\"\"\n")
+	return b.String()
+}
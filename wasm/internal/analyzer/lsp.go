@@ -0,0 +1,491 @@
+//go:build !js
+
+package analyzer
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// DefaultLSPExtensions are the files the language server analyzes when none are configured
+var DefaultLSPExtensions = []string{".prompt", ".md"}
+
+// lspAnalysisTimeout bounds one analysis of an open document
+const lspAnalysisTimeout = 30 * time.Second
+
+// lspSpellingFixes is how many spelling suggestions become quick fixes
+const lspSpellingFixes = 3
+
+// LSP diagnostic severities
+const (
+	lspError       = 1
+	lspWarning     = 2
+	lspInformation = 3
+)
+
+// JSON-RPC error codes
+const (
+	rpcParseError     = -32700
+	rpcMethodNotFound = -32601
+	rpcInvalidParams  = -32602
+	rpcRequestFailed  = -32803
+)
+
+// rpcMessage is a JSON-RPC 2.0 request or notification; notifications have no ID
+type rpcMessage struct {
+	ID     *json.RawMessage `json:"id"`
+	Method string           `json:"method"`
+	Params json.RawMessage  `json:"params"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// LSPPosition is a zero-based line and UTF-16 column, as LSP counts them
+type LSPPosition struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+// LSPRange is a half-open range of a document
+type LSPRange struct {
+	Start LSPPosition `json:"start"`
+	End   LSPPosition `json:"end"`
+}
+
+// LSPDiagnostic is one finding published for a document
+type LSPDiagnostic struct {
+	Range    LSPRange `json:"range"`
+	Severity int      `json:"severity"`
+	Code     string   `json:"code,omitempty"`
+	Source   string   `json:"source"`
+	Message  string   `json:"message"`
+}
+
+// LSPTextEdit replaces a range with new text
+type LSPTextEdit struct {
+	Range   LSPRange `json:"range"`
+	NewText string   `json:"newText"`
+}
+
+// LSPCodeAction is a fix or rewrite offered for a range
+type LSPCodeAction struct {
+	Title       string          `json:"title"`
+	Kind        string          `json:"kind"`
+	Diagnostics []LSPDiagnostic `json:"diagnostics,omitempty"`
+	IsPreferred bool            `json:"isPreferred,omitempty"`
+	Edit        struct {
+		Changes map[string][]LSPTextEdit `json:"changes"`
+	} `json:"edit"`
+}
+
+type lspTextDocument struct {
+	URI     string `json:"uri"`
+	Version int    `json:"version"`
+	Text    string `json:"text"`
+}
+
+// lspDocument is an open document and the analysis of its latest version
+type lspDocument struct {
+	uri     string
+	version int
+	text    string
+	cancel  context.CancelFunc
+	done    chan struct{} // Closed when result is set for text
+	result  *CombinedResult
+}
+
+// LSPServer serves diagnostics and code actions for prompt files over the
+// Language Server Protocol. Documents are synced in full; each change
+// cancels the previous analysis of that document.
+type LSPServer struct {
+	options    AnalysisOptions
+	extensions []string
+
+	out     *bufio.Writer
+	writeMu sync.Mutex
+
+	mu      sync.Mutex
+	docs    map[string]*lspDocument
+	pending sync.WaitGroup
+}
+
+// NewLSPServer creates a server that analyzes files ending in one of
+// extensions, or DefaultLSPExtensions when none are given
+func NewLSPServer(opts AnalysisOptions, extensions ...string) *LSPServer {
+	if len(extensions) == 0 {
+		extensions = DefaultLSPExtensions
+	}
+	return &LSPServer{options: opts, extensions: extensions, docs: map[string]*lspDocument{}}
+}
+
+// Serve reads requests from in and writes responses and diagnostics to out
+// until the client sends exit, in closes or ctx is cancelled
+func (s *LSPServer) Serve(ctx context.Context, in io.Reader, out io.Writer) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer func() {
+		cancel()
+		s.pending.Wait()
+	}()
+	s.out = bufio.NewWriter(out)
+	reader := textproto.NewReader(bufio.NewReader(in))
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		body, err := readLSPMessage(reader)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		var msg rpcMessage
+		if err := json.Unmarshal(body, &msg); err != nil {
+			s.reply(nil, nil, &rpcError{Code: rpcParseError, Message: err.Error()})
+			continue
+		}
+		if msg.Method == "exit" {
+			return nil
+		}
+		s.handle(ctx, msg)
+	}
+}
+
+// readLSPMessage reads one Content-Length framed message
+func readLSPMessage(r *textproto.Reader) ([]byte, error) {
+	header, err := r.ReadMIMEHeader()
+	if err != nil {
+		if err == io.EOF && len(header) == 0 {
+			return nil, io.EOF
+		}
+		return nil, fmt.Errorf("read message header: %w", err)
+	}
+	length, err := strconv.Atoi(header.Get("Content-Length"))
+	if err != nil || length < 0 {
+		return nil, fmt.Errorf("invalid Content-Length %q", header.Get("Content-Length"))
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r.R, body); err != nil {
+		return nil, fmt.Errorf("read message body: %w", err)
+	}
+	return body, nil
+}
+
+// handle dispatches one message. Requests get a response; unknown
+// notifications are ignored.
+func (s *LSPServer) handle(ctx context.Context, msg rpcMessage) {
+	var result interface{}
+	var err error
+	switch msg.Method {
+	case "initialize":
+		result = map[string]interface{}{
+			"capabilities": map[string]interface{}{
+				"textDocumentSync":   map[string]interface{}{"openClose": true, "change": 1},
+				"codeActionProvider": map[string]interface{}{"codeActionKinds": []string{"quickfix", "refactor.rewrite"}},
+			},
+			"serverInfo": map[string]string{"name": "fulcrum", "version": Version},
+		}
+	case "shutdown":
+		s.pending.Wait()
+	case "textDocument/didOpen":
+		var p struct {
+			TextDocument lspTextDocument `json:"textDocument"`
+		}
+		if err = json.Unmarshal(msg.Params, &p); err == nil {
+			s.update(ctx, p.TextDocument.URI, p.TextDocument.Version, p.TextDocument.Text)
+		}
+	case "textDocument/didChange":
+		var p struct {
+			TextDocument   lspTextDocument `json:"textDocument"`
+			ContentChanges []struct {
+				Range *LSPRange `json:"range"`
+				Text  string    `json:"text"`
+			} `json:"contentChanges"`
+		}
+		if err = json.Unmarshal(msg.Params, &p); err == nil && len(p.ContentChanges) > 0 {
+			// Full sync: the last change holds the whole document
+			s.update(ctx, p.TextDocument.URI, p.TextDocument.Version, p.ContentChanges[len(p.ContentChanges)-1].Text)
+		}
+	case "textDocument/didClose":
+		var p struct {
+			TextDocument lspTextDocument `json:"textDocument"`
+		}
+		if err = json.Unmarshal(msg.Params, &p); err == nil {
+			s.close(p.TextDocument.URI)
+		}
+	case "textDocument/codeAction":
+		var p struct {
+			TextDocument lspTextDocument `json:"textDocument"`
+			Range        LSPRange        `json:"range"`
+		}
+		if err = json.Unmarshal(msg.Params, &p); err == nil {
+			result, err = s.codeActions(ctx, p.TextDocument.URI, p.Range)
+		}
+	default:
+		if msg.ID != nil {
+			s.reply(msg.ID, nil, &rpcError{Code: rpcMethodNotFound, Message: "method not found: " + msg.Method})
+		}
+		return
+	}
+
+	if msg.ID == nil {
+		return
+	}
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr) || errors.As(err, &typeErr):
+		s.reply(msg.ID, nil, &rpcError{Code: rpcInvalidParams, Message: err.Error()})
+	case err != nil:
+		s.reply(msg.ID, nil, &rpcError{Code: rpcRequestFailed, Message: err.Error()})
+	default:
+		s.reply(msg.ID, result, nil)
+	}
+}
+
+// reply sends a response; a nil result is sent as null
+func (s *LSPServer) reply(id *json.RawMessage, result interface{}, rpcErr *rpcError) {
+	msg := map[string]interface{}{"jsonrpc": "2.0", "id": id}
+	if rpcErr != nil {
+		msg["error"] = rpcErr
+	} else {
+		msg["result"] = result
+	}
+	s.write(msg)
+}
+
+// notify sends a notification
+func (s *LSPServer) notify(method string, params interface{}) {
+	s.write(map[string]interface{}{"jsonrpc": "2.0", "method": method, "params": params})
+}
+
+func (s *LSPServer) write(msg interface{}) {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return
+	}
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	fmt.Fprintf(s.out, "Content-Length: %d\r\n\r\n", len(body))
+	s.out.Write(body)
+	s.out.Flush()
+}
+
+// analyzes reports whether the server handles the document at uri
+func (s *LSPServer) analyzes(uri string) bool {
+	for _, ext := range s.extensions {
+		if strings.HasSuffix(strings.ToLower(uri), ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// update stores a new version of a document and analyzes it in the
+// background, publishing diagnostics unless a newer version arrives first
+func (s *LSPServer) update(ctx context.Context, uri string, version int, text string) {
+	if !s.analyzes(uri) {
+		return
+	}
+	s.mu.Lock()
+	if old := s.docs[uri]; old != nil {
+		old.cancel()
+	}
+	runCtx, cancel := context.WithTimeout(ctx, lspAnalysisTimeout)
+	doc := &lspDocument{uri: uri, version: version, text: text, cancel: cancel, done: make(chan struct{})}
+	s.docs[uri] = doc
+	s.mu.Unlock()
+
+	s.pending.Add(1)
+	go func() {
+		defer s.pending.Done()
+		defer cancel()
+		result, err := Analyze(runCtx, text, s.options, AnalysisRun{})
+		s.mu.Lock()
+		current := s.docs[uri] == doc
+		if err == nil {
+			doc.result = result
+		}
+		s.mu.Unlock()
+		close(doc.done)
+		if current && err == nil {
+			s.notify("textDocument/publishDiagnostics", map[string]interface{}{
+				"uri": uri, "version": version, "diagnostics": LSPDiagnostics(text, result),
+			})
+		}
+	}()
+}
+
+// close forgets a document and clears its diagnostics
+func (s *LSPServer) close(uri string) {
+	s.mu.Lock()
+	doc := s.docs[uri]
+	delete(s.docs, uri)
+	s.mu.Unlock()
+	if doc != nil {
+		doc.cancel()
+		s.notify("textDocument/publishDiagnostics", map[string]interface{}{"uri": uri, "diagnostics": []LSPDiagnostic{}})
+	}
+}
+
+// codeActions waits for the document's analysis and offers spelling fixes
+// in rng plus a rewrite of the whole prompt
+func (s *LSPServer) codeActions(ctx context.Context, uri string, rng LSPRange) ([]LSPCodeAction, error) {
+	s.mu.Lock()
+	doc := s.docs[uri]
+	s.mu.Unlock()
+	if doc == nil {
+		return []LSPCodeAction{}, nil
+	}
+	select {
+	case <-doc.done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if doc.result == nil {
+		return []LSPCodeAction{}, nil
+	}
+	return LSPCodeActions(ctx, uri, doc.text, doc.result, rng), nil
+}
+
+// LSPDiagnostics converts a result's annotations into diagnostics.
+// Suggestions without a span are reported on the first line, as in SARIF;
+// thought-type annotations are informational and left out.
+func LSPDiagnostics(text string, result *CombinedResult) []LSPDiagnostic {
+	diagnostics := []LSPDiagnostic{}
+	add := func(code, severity, message string, span Span) {
+		diagnostics = append(diagnostics, LSPDiagnostic{
+			Range:    lspRange(text, fileSpan(result, span)),
+			Severity: lspSeverity(severity),
+			Code:     code,
+			Source:   "fulcrum",
+			Message:  message,
+		})
+	}
+	for _, s := range gradeSuggestions(result) {
+		if len(s.Spans) == 0 {
+			add(s.Rule, s.Priority, s.Message, firstLineSpan(text[promptStart(result):]))
+		}
+	}
+	for _, a := range result.Annotations {
+		if a.Source == AnnotationThoughtType {
+			continue
+		}
+		code := a.Rule
+		if code == "" {
+			code = a.Source
+		}
+		add(code, a.Severity, a.Message, a.Span)
+	}
+	return diagnostics
+}
+
+// LSPCodeActions offers a quick fix for each misspelling in rng and an
+// action that replaces the prompt, below any front matter, with the
+// rule-based rewrite, titled with the score it would reach
+func LSPCodeActions(ctx context.Context, uri, text string, result *CombinedResult, rng LSPRange) []LSPCodeAction {
+	actions := []LSPCodeAction{}
+	newAction := func(title, kind string, edits ...LSPTextEdit) LSPCodeAction {
+		action := LSPCodeAction{Title: title, Kind: kind}
+		action.Edit.Changes = map[string][]LSPTextEdit{uri: edits}
+		return action
+	}
+
+	start, end := lspOffset(text, rng.Start), lspOffset(text, rng.End)
+	prompt := promptStart(result)
+	for _, e := range result.Preprocessing.QualityMetrics.SpellingErrors.Value {
+		wordStart := prompt + e.Position
+		wordEnd := wordStart + len(e.Word)
+		if wordEnd > len(text) || wordStart > end || wordEnd < start {
+			continue
+		}
+		span := newSpan(text, wordStart, wordEnd)
+		diagnostic := LSPDiagnostic{
+			Range: lspRange(text, span), Severity: lspSeverity("low"), Code: AnnotationSpelling,
+			Source: "fulcrum", Message: fmt.Sprintf("Possible misspelling of %q", e.Word),
+		}
+		for i, suggestion := range e.Suggestions {
+			if i == lspSpellingFixes {
+				break
+			}
+			action := newAction(fmt.Sprintf("Change %q to %q", e.Word, suggestion), "quickfix",
+				LSPTextEdit{Range: diagnostic.Range, NewText: suggestion})
+			action.Diagnostics = []LSPDiagnostic{diagnostic}
+			action.IsPreferred = i == 0
+			actions = append(actions, action)
+		}
+	}
+
+	rewriter := NewRuleBasedRewriter()
+	grade := classicGrade(result, text[prompt:])
+	rewritten, err := rewriter.Rewrite(ctx, text[prompt:], grade)
+	if err == nil && rewritten != text[prompt:] {
+		title := fmt.Sprintf("Rewrite prompt into sections (score %.0f → %.0f)",
+			grade.OverallGrade.Score, GradePromptText(rewritten).OverallGrade.Score)
+		actions = append(actions, newAction(title, "refactor.rewrite",
+			LSPTextEdit{Range: lspRange(text, newSpan(text, prompt, len(text))), NewText: rewritten}))
+	}
+	return actions
+}
+
+// lspSeverity maps a priority or severity to an LSP diagnostic severity
+func lspSeverity(severity string) int {
+	switch severity {
+	case "critical", "high":
+		return lspError
+	case "medium":
+		return lspWarning
+	default:
+		return lspInformation
+	}
+}
+
+// lspRange converts a byte span into LSP positions
+func lspRange(text string, span Span) LSPRange {
+	return LSPRange{Start: lspPosition(text, span.Start), End: lspPosition(text, span.End)}
+}
+
+// lspPosition converts a byte offset into a zero-based line and UTF-16 column
+func lspPosition(text string, offset int) LSPPosition {
+	before := text[:offset]
+	lineStart := strings.LastIndexByte(before, '\n') + 1
+	column := 0
+	for _, r := range before[lineStart:] {
+		column += utf16Len(r)
+	}
+	return LSPPosition{Line: strings.Count(before, "\n"), Character: column}
+}
+
+// lspOffset converts an LSP position back into a byte offset, clamping
+// positions past the end of a line or the text
+func lspOffset(text string, pos LSPPosition) int {
+	offset := 0
+	for line := 0; line < pos.Line; line++ {
+		next := strings.IndexByte(text[offset:], '\n')
+		if next < 0 {
+			return len(text)
+		}
+		offset += next + 1
+	}
+	for units := 0; offset < len(text) && text[offset] != '\n'; {
+		r, size := utf8.DecodeRuneInString(text[offset:])
+		if units += utf16Len(r); units > pos.Character {
+			break
+		}
+		offset += size
+	}
+	return offset
+}
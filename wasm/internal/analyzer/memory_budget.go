@@ -0,0 +1,193 @@
+package analyzer
+
+import (
+	"fmt"
+)
+
+// DefaultMemoryBudgetBytes is the working-memory allowance for the expensive idea stages
+const DefaultMemoryBudgetBytes = 64 << 20
+
+// Rough per-term costs used by the estimator (string header + backing bytes + map overhead)
+const (
+	bytesPerStoredTerm   = 48
+	bytesPerComparedTerm = 64
+	bytesPerPairScan     = 16
+)
+
+// Smallest inputs worth running a degraded stage on; below these the stage is disabled
+const (
+	minClusterSentences   = 10
+	minCoherenceSentences = 2
+	maxClusterSize        = 10
+	maxIdeaClusters       = 20
+)
+
+// Degradation modes reported in StageDegradation.Mode
+const (
+	DegradationSampled  = "sampled"
+	DegradationDisabled = "disabled"
+)
+
+// MemoryBudget bounds the memory the clustering and coherence stages may use
+type MemoryBudget struct {
+	LimitBytes int64 `json:"limit_bytes"`
+}
+
+// StageDegradation records a stage that was sampled or skipped to stay within budget
+type StageDegradation struct {
+	Stage     string `json:"stage"`
+	Mode      string `json:"mode"`
+	Processed int    `json:"processed"`
+	Total     int    `json:"total"`
+	Reason    string `json:"reason"`
+	Coverage  float64 `json:"coverage,omitempty"` // Percent of the text's characters analyzed when sampled
+}
+
+// AnalysisPlan holds the limits chosen for one input under a memory budget
+type AnalysisPlan struct {
+	EstimatedBytes     int64              `json:"estimated_bytes"`
+	ClusterSentences   int                `json:"cluster_sentences"`   // 0 disables clustering
+	CoherenceSentences int                `json:"coherence_sentences"` // Per cluster; 0 disables coherence
+	Degraded           []StageDegradation `json:"degraded_stages"`
+}
+
+// DefaultMemoryBudget returns the budget used when callers do not supply one,
+// which RuntimeConfig.MemoryBudgetBytes can change while the process runs
+func DefaultMemoryBudget() MemoryBudget {
+	return MemoryBudget{LimitBytes: CurrentRuntimeConfig().MemoryBudgetBytes}
+}
+
+// Plan estimates the cost of the idea stages for an input and picks sampling limits.
+// Clustering gets three quarters of the budget and pairwise coherence the rest.
+func (b MemoryBudget) Plan(sentenceCount, wordCount int) AnalysisPlan {
+	plan := AnalysisPlan{
+		ClusterSentences:   sentenceCount,
+		CoherenceSentences: maxClusterSize,
+		Degraded:           []StageDegradation{},
+	}
+	if sentenceCount == 0 {
+		return plan
+	}
+
+	wordsPerSentence := int64(wordCount / sentenceCount)
+	if wordsPerSentence < 1 {
+		wordsPerSentence = 1
+	}
+
+	limit := b.LimitBytes
+	if limit <= 0 {
+		limit = DefaultMemoryBudgetBytes
+	}
+	clusterLimit := limit * 3 / 4
+	coherenceLimit := limit - clusterLimit
+
+	plan.EstimatedBytes = clusteringCost(sentenceCount, wordsPerSentence) +
+		coherenceCost(maxClusterSize, wordsPerSentence)
+
+	if clusteringCost(sentenceCount, wordsPerSentence) > clusterLimit {
+		n := sentenceCount
+		for n > minClusterSentences && clusteringCost(n, wordsPerSentence) > clusterLimit {
+			n = n * 3 / 4
+			if n < minClusterSentences {
+				n = minClusterSentences
+			}
+		}
+		if clusteringCost(n, wordsPerSentence) > clusterLimit {
+			plan.ClusterSentences = 0
+			plan.Degraded = append(plan.Degraded, StageDegradation{
+				Stage:  "idea_clustering",
+				Mode:   DegradationDisabled,
+				Total:  sentenceCount,
+				Reason: fmt.Sprintf("estimated %s exceeds clustering budget of %s", formatBytes(clusteringCost(n, wordsPerSentence)), formatBytes(clusterLimit)),
+			})
+		} else {
+			plan.ClusterSentences = n
+			plan.Degraded = append(plan.Degraded, StageDegradation{
+				Stage:     "idea_clustering",
+				Mode:      DegradationSampled,
+				Processed: n,
+				Total:     sentenceCount,
+				Reason:    fmt.Sprintf("estimated %s exceeds clustering budget of %s", formatBytes(clusteringCost(sentenceCount, wordsPerSentence)), formatBytes(clusterLimit)),
+			})
+		}
+	}
+
+	if plan.ClusterSentences == 0 {
+		plan.CoherenceSentences = 0
+		plan.Degraded = append(plan.Degraded, StageDegradation{
+			Stage:  "cluster_coherence",
+			Mode:   DegradationDisabled,
+			Total:  maxClusterSize,
+			Reason: "clustering was disabled",
+		})
+		return plan
+	}
+
+	for plan.CoherenceSentences > minCoherenceSentences &&
+		maxIdeaClusters*coherenceCost(plan.CoherenceSentences, wordsPerSentence) > coherenceLimit {
+		plan.CoherenceSentences--
+	}
+	if cost := maxIdeaClusters * coherenceCost(plan.CoherenceSentences, wordsPerSentence); cost > coherenceLimit {
+		plan.CoherenceSentences = 0
+		plan.Degraded = append(plan.Degraded, StageDegradation{
+			Stage:  "cluster_coherence",
+			Mode:   DegradationDisabled,
+			Total:  maxClusterSize,
+			Reason: fmt.Sprintf("estimated %s exceeds coherence budget of %s", formatBytes(cost), formatBytes(coherenceLimit)),
+		})
+	} else if plan.CoherenceSentences < maxClusterSize {
+		plan.Degraded = append(plan.Degraded, StageDegradation{
+			Stage:     "cluster_coherence",
+			Mode:      DegradationSampled,
+			Processed: plan.CoherenceSentences,
+			Total:     maxClusterSize,
+			Reason:    fmt.Sprintf("pairwise comparisons limited to %d sentences per cluster", plan.CoherenceSentences),
+		})
+	}
+
+	return plan
+}
+
+// clusteringCost estimates term slices and sets for n sentences plus the transient
+// work of the greedy pairwise scan
+func clusteringCost(n int, wordsPerSentence int64) int64 {
+	sentences := int64(n)
+	stored := sentences * wordsPerSentence * (bytesPerStoredTerm + bytesPerComparedTerm)
+	pairs := sentences * (sentences - 1) / 2
+	return stored + pairs*wordsPerSentence*bytesPerPairScan
+}
+
+// coherenceCost estimates one cluster of n sentences; term sets are shared with clustering,
+// so pairwise comparisons only allocate the member index list
+func coherenceCost(n int, wordsPerSentence int64) int64 {
+	sentences := int64(n)
+	return sentences * (8 + wordsPerSentence*bytesPerStoredTerm/4)
+}
+
+// sampleEvenly keeps at most n items spread evenly across the slice, preserving order
+func sampleEvenly[T any](items []T, n int) []T {
+	if n <= 0 {
+		return []T{}
+	}
+	if len(items) <= n {
+		return items
+	}
+	sampled := make([]T, 0, n)
+	for i := 0; i < n; i++ {
+		sampled = append(sampled, items[i*len(items)/n])
+	}
+	return sampled
+}
+
+// formatBytes renders a byte count with a binary unit suffix
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1<<30:
+		return fmt.Sprintf("%.1f GiB", float64(n)/(1<<30))
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MiB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KiB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", n)
+}
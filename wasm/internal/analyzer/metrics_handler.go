@@ -0,0 +1,25 @@
+//go:build !js
+
+package analyzer
+
+import (
+	"net/http"
+)
+
+// MetricsHandler serves the stage latency registry and worker pool queue
+// metrics for Prometheus at e.g. /metrics
+func MetricsHandler(m *StageMetrics) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		if err := m.WritePrometheus(w); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		WritePoolPrometheus(w)
+	})
+}
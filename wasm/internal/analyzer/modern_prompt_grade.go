@@ -15,6 +15,8 @@ type ModernPromptGrade struct {
 	Strengths         []string             `json:"strengths"`
 	ImprovementAreas  []string             `json:"improvement_areas"`
 	QualityIndicators QualityIndicators    `json:"quality_indicators"`
+	Components        PromptComponents     `json:"components"` // Persona and audience statements
+	Goals             GoalExtraction       `json:"goals"`      // Objectives, secondary goals and non-goals
 }
 
 // ModernOverallGrade with more realistic scoring
@@ -69,6 +71,7 @@ type ModernFactor struct {
 
 // ModernSuggestion - practical, context-aware suggestions
 type ModernSuggestion struct {
+	Rule             string   `json:"rule"`              // Stable rule ID, e.g. "FUL020"
 	Category         string   `json:"category"`          // e.g., "Structure", "Specificity"
 	Priority         string   `json:"priority"`          // "critical", "high", "medium", "low"
 	Title            string   `json:"title"`             // Short, actionable title
@@ -82,6 +85,7 @@ type ModernSuggestion struct {
 // QualityIndicators - measurable quality signals
 type QualityIndicators struct {
 	HasClearGoal        bool    `json:"has_clear_goal"`
+	GoalClarity         float64 `json:"goal_clarity"`         // 0-1, from ExtractGoals
 	HasSpecificContext  bool    `json:"has_specific_context"`
 	HasActionableSteps  bool    `json:"has_actionable_steps"`
 	HasConstraints      bool    `json:"has_constraints"`
@@ -95,16 +99,19 @@ type QualityIndicators struct {
 type ModernPromptGrader struct {
 	classifier   *PromptClassifier
 	dimensionWeights map[PromptType]DimensionWeights
+	gradeThresholds  []GradeThreshold               // Optional override of letter-grade boundaries
+	suggestionPriorities map[PromptType]map[string]string // Optional per-type category -> priority overrides
+	rules                SuggestionRuleConfig             // Disabled rules and per-rule priorities
 }
 
 // DimensionWeights - different weights for different prompt types
 type DimensionWeights struct {
-	Clarity          float64
-	Specificity      float64
-	Completeness     float64
-	Actionability    float64
-	ContextProvision float64
-	StructureQuality float64
+	Clarity          float64 `json:"clarity"`
+	Specificity      float64 `json:"specificity"`
+	Completeness     float64 `json:"completeness"`
+	Actionability    float64 `json:"actionability"`
+	ContextProvision float64 `json:"context_provision"`
+	StructureQuality float64 `json:"structure_quality"`
 }
 
 // NewModernPromptGrader creates a grader calibrated for real-world prompt quality
@@ -181,6 +188,8 @@ func NewModernPromptGrader() *ModernPromptGrader {
 }
 
 // GradePrompt - main grading function with realistic scoring
+//
+// Deprecated: use GradeAll with GradersFor(AnalysisOptions{Grader: GraderModern}).
 func (grader *ModernPromptGrader) GradePrompt(
 	text string,
 	complexity ComplexityMetrics,
@@ -189,24 +198,34 @@ func (grader *ModernPromptGrader) GradePrompt(
 	ideas IdeaAnalysisMetrics,
 	taskGraph TaskGraph,
 ) *ModernPromptGrade {
-	
+	return grader.grade(NewGradingInputs(text, complexity, tokens, preprocessing, ideas, taskGraph, grader.classifier))
+}
+
+// grade computes the modern grade from the shared grading inputs
+func (grader *ModernPromptGrader) grade(in GradingInputs) *ModernPromptGrade {
+	text, complexity, tokens, ideas, taskGraph := in.Text, in.Complexity, in.Tokens, in.Ideas, in.TaskGraph
+
 	// 1. Classify the prompt type
-	classification := grader.classifier.ClassifyPrompt(text)
+	classification := in.Classification
 	
 	// 2. Calculate quality indicators
-	indicators := grader.calculateQualityIndicators(text, tokens, ideas, taskGraph)
+	goals := in.Goals
+	indicators := grader.calculateQualityIndicators(text, tokens, ideas, taskGraph, goals)
 	
 	// 3. Calculate context-aware dimensions
-	dimensions := grader.calculateModernDimensions(text, classification.PrimaryType, complexity, tokens, ideas, taskGraph, indicators)
+	components := in.Components
+	blend := classification.BlendLabels()
+	promptType := classification.GradingType()
+	dimensions := grader.calculateModernDimensions(text, promptType, complexity, tokens, ideas, taskGraph, indicators, components, blend)
 	
-	// 4. Calculate overall grade with realistic scoring
-	overallGrade := grader.realisticOverallGrade(dimensions, classification.PrimaryType)
+	// 4. Calculate overall grade with dimension weights blended across the top labels
+	overallGrade := grader.weightedOverallGrade(dimensions, grader.blendedWeights(blend))
 	
 	// 5. Generate practical suggestions
-	suggestions := grader.practicalSuggestions(dimensions, classification.PrimaryType, text, indicators)
+	suggestions := grader.practicalSuggestions(dimensions, promptType, text, indicators)
 	
 	// 6. Identify strengths and improvement areas
-	strengths, improvementAreas := grader.strengthsAndImprovements(dimensions, classification.PrimaryType)
+	strengths, improvementAreas := grader.strengthsAndImprovements(dimensions, promptType)
 	
 	return &ModernPromptGrade{
 		Classification:    classification,
@@ -216,12 +235,42 @@ func (grader *ModernPromptGrader) GradePrompt(
 		Strengths:         strengths,
 		ImprovementAreas:  improvementAreas,
 		QualityIndicators: indicators,
+		Components:        components,
+		Goals:             goals,
 	}
 }
 
+// GradeText runs the analysis pipeline on text and grades it
+func (grader *ModernPromptGrader) GradeText(text string) *ModernPromptGrade {
+	return grader.grade(AnalyzeForGrading(text, grader.classifier))
+}
+
 // realisticOverallGrade computes the overall grade from dimensions and prompt type
 func (grader *ModernPromptGrader) realisticOverallGrade(dim ModernDimensions, pt PromptType) ModernOverallGrade {
-	w := grader.dimensionWeights[pt]
+	return grader.weightedOverallGrade(dim, grader.dimensionWeights[pt])
+}
+
+// blendedWeights averages the dimension weights of the blended labels by
+// their confidence
+func (grader *ModernPromptGrader) blendedWeights(labels []PromptLabel) DimensionWeights {
+	var w DimensionWeights
+	for _, l := range labels {
+		lw, ok := grader.dimensionWeights[l.Type] // A rubric may weight a trained category directly
+		if !ok {
+			lw = grader.dimensionWeights[l.gradingType()]
+		}
+		w.Clarity += lw.Clarity * l.Confidence
+		w.Specificity += lw.Specificity * l.Confidence
+		w.Completeness += lw.Completeness * l.Confidence
+		w.Actionability += lw.Actionability * l.Confidence
+		w.ContextProvision += lw.ContextProvision * l.Confidence
+		w.StructureQuality += lw.StructureQuality * l.Confidence
+	}
+	return w
+}
+
+// weightedOverallGrade computes the overall grade from dimensions and weights
+func (grader *ModernPromptGrader) weightedOverallGrade(dim ModernDimensions, w DimensionWeights) ModernOverallGrade {
 	weighted := dim.Clarity.Score*w.Clarity +
 		dim.Specificity.Score*w.Specificity +
 		dim.Completeness.Score*w.Completeness +
@@ -268,8 +317,12 @@ func (grader *ModernPromptGrader) realisticOverallGrade(dim ModernDimensions, pt
 // practicalSuggestions generates context-aware suggestions (lightweight initial set)
 func (grader *ModernPromptGrader) practicalSuggestions(dim ModernDimensions, pt PromptType, text string, ind QualityIndicators) []ModernSuggestion {
 	suggestions := []ModernSuggestion{}
-	add := func(cat, prio, title, desc, ex string, impact float64) {
+	add := func(rule, cat, prio, title, desc, ex string, impact float64) {
+		if !grader.rules.enabled(rule) {
+			return
+		}
 		suggestions = append(suggestions, ModernSuggestion{
+			Rule:        rule,
 			Category:    cat,
 			Priority:    prio,
 			Title:       title,
@@ -282,16 +335,24 @@ func (grader *ModernPromptGrader) practicalSuggestions(dim ModernDimensions, pt
 	}
 	
 	if dim.Specificity.Score < 70 {
-		add("Specificity", "high", "Be more specific about inputs/outputs", "Specify exact inputs, outputs, formats, or constraints so the response is unambiguous.", "E.g., 'Return JSON with fields: id, name, status'", 7.5)
+		ex := "E.g., 'Return JSON with fields: id, name, status'"
+		if own, ok := outputSpecExample(text); ok {
+			ex = "E.g., " + own
+		}
+		add("FUL020", "Specificity", "high", "Be more specific about inputs/outputs", "Specify exact inputs, outputs, formats, or constraints so the response is unambiguous.", ex, 7.5)
 	}
 	if dim.Completeness.Score < 70 {
-		add("Completeness", "high", "Fill missing requirements", "List all key requirements and edge cases the solution should handle.", "E.g., 'Handle retries on 5xx with backoff'", 7.0)
+		add("FUL021", "Completeness", "high", "Fill missing requirements", "List all key requirements and edge cases the solution should handle.", "E.g., 'Handle retries on 5xx with backoff'", 7.0)
 	}
 	if pt == TechnicalSpec && dim.ContextProvision.Score < 70 {
-		add("Context", "medium", "Provide technical context and constraints", "Add stack, environment, limits, SLAs, and security expectations.", "E.g., 'Node.js 20, AWS Lambda, 200ms p95'", 6.0)
+		add("FUL022", "Context", "medium", "Provide technical context and constraints", "Add stack, environment, limits, SLAs, and security expectations.", "E.g., 'Node.js 20, AWS Lambda, 200ms p95'", 6.0)
 	}
 	if dim.Actionability.Score < 65 {
-		add("Actionability", "medium", "Add step-by-step deliverables", "Include clear deliverables or steps so the agent can execute easily.", "E.g., '1) Schema, 2) CRUD endpoints, 3) tests'", 6.5)
+		add("FUL023", "Actionability", "medium", "Add step-by-step deliverables", "Include clear deliverables or steps so the agent can execute easily.", "E.g., '1) Schema, 2) CRUD endpoints, 3) tests'", 6.5)
+	}
+	grader.applySuggestionPriorities(suggestions, pt)
+	for i := range suggestions {
+		suggestions[i].Priority = grader.rules.priority(suggestions[i].Rule, suggestions[i].Priority)
 	}
 	return suggestions
 }
@@ -317,19 +378,9 @@ func (grader *ModernPromptGrader) strengthsAndImprovements(dim ModernDimensions,
 }
 
 // calculateQualityIndicators - measurable quality signals
-func (grader *ModernPromptGrader) calculateQualityIndicators(text string, tokens TokenData, ideas IdeaAnalysisMetrics, taskGraph TaskGraph) QualityIndicators {
+func (grader *ModernPromptGrader) calculateQualityIndicators(text string, tokens TokenData, ideas IdeaAnalysisMetrics, taskGraph TaskGraph, goals GoalExtraction) QualityIndicators {
 	lowText := strings.ToLower(text)
 	
-	// Check for clear goals
-	goalWords := []string{"goal", "objective", "need", "want", "should", "must", "create", "build", "implement", "analyze", "write"}
-	hasGoal := false
-	for _, word := range goalWords {
-		if strings.Contains(lowText, word) {
-			hasGoal = true
-			break
-		}
-	}
-	
 	// Check for specific context
 	contextWords := []string{"because", "for", "using", "with", "in the context of", "requirements", "constraints"}
 	hasContext := false
@@ -394,7 +445,8 @@ func (grader *ModernPromptGrader) calculateQualityIndicators(text string, tokens
 	clarityScore = math.Max(0.0, math.Min(1.0, clarityScore))
 	
 	return QualityIndicators{
-		HasClearGoal:       hasGoal,
+		HasClearGoal:       goals.HasClearGoal,
+		GoalClarity:        goals.Score / 100,
 		HasSpecificContext: hasContext,
 		HasActionableSteps: hasSteps,
 		HasConstraints:     hasConstraints,
@@ -414,6 +466,8 @@ func (grader *ModernPromptGrader) calculateModernDimensions(
 	ideas IdeaAnalysisMetrics, 
 	taskGraph TaskGraph,
 	indicators QualityIndicators,
+	components PromptComponents,
+	blend []PromptLabel,
 ) ModernDimensions {
 	
 	return ModernDimensions{
@@ -421,7 +475,7 @@ func (grader *ModernPromptGrader) calculateModernDimensions(
 		Specificity:      grader.modernSpecificity(text, tokens, ideas, indicators, promptType),
 		Completeness:     grader.modernCompleteness(text, taskGraph, ideas, indicators, promptType),
 		Actionability:    grader.modernActionability(text, taskGraph, tokens, indicators, promptType),
-		ContextProvision: grader.modernContextProvision(text, ideas, tokens, indicators, promptType),
+		ContextProvision: grader.modernContextProvision(text, ideas, tokens, indicators, components, blend, promptType),
 		StructureQuality: grader.calculateStructureQuality(ideas, complexity, indicators, promptType),
 	}
 }
@@ -461,11 +515,8 @@ func (grader *ModernPromptGrader) calculateClarity(text string, complexity Compl
 		ContextRelevant: true,
 	})
 	
-	// Clear goal indicator
-	goalScore := 60.0
-	if indicators.HasClearGoal {
-		goalScore = 90.0
-	}
+	// Clear goal indicator - stated goals score above ones inferred from an instruction
+	goalScore := indicators.GoalClarity * 100
 	factors = append(factors, ModernFactor{
 		Name: "Clear Goal",
 		Value: goalScore,
@@ -612,11 +663,13 @@ func (grader *ModernPromptGrader) modernActionability(text string, taskGraph Tas
 	}
 	if indicators.HasActionableSteps { stepsScore = math.Max(stepsScore, 90.0) }
 
+	completeness := AnalyzeInstructions(text).Score
+
 	factors := []ModernFactor{
 		{Name: "Tasks & Sequence", Value: taskScore, Weight: 0.35, Contribution: taskScore * 0.35, IsPositive: true, ContextRelevant: true},
 		{Name: "Action Verb Density", Value: verbScore, Weight: 0.25, Contribution: verbScore * 0.25, IsPositive: true, ContextRelevant: true},
 		{Name: "Steps/Deliverables Mentioned", Value: stepsScore, Weight: 0.25, Contribution: stepsScore * 0.25, IsPositive: true, ContextRelevant: true},
-		{Name: "General Readiness", Value: 70.0, Weight: 0.15, Contribution: 70.0 * 0.15, IsPositive: true, ContextRelevant: true},
+		{Name: "Instruction Completeness", Value: completeness, Weight: 0.15, Contribution: completeness * 0.15, IsPositive: true, ContextRelevant: true},
 	}
 	total := 0.0
 	for _, f := range factors { total += f.Contribution }
@@ -624,7 +677,7 @@ func (grader *ModernPromptGrader) modernActionability(text string, taskGraph Tas
 	return ModernDimension{Score: score, Grade: grader.scoreToRealisticGrade(score), Label: grader.getQualityLabel(score), Description: "Looks for tasks, sequencing, verbs, and deliverables", Factors: factors, Context: grader.getDimensionContext("actionability", pt)}
 }
 
-func (grader *ModernPromptGrader) modernContextProvision(text string, ideas IdeaAnalysisMetrics, tokens TokenData, indicators QualityIndicators, pt PromptType) ModernDimension {
+func (grader *ModernPromptGrader) modernContextProvision(text string, ideas IdeaAnalysisMetrics, tokens TokenData, indicators QualityIndicators, components PromptComponents, blend []PromptLabel, pt PromptType) ModernDimension {
 	lower := strings.ToLower(text)
 	neCount := float64(len(tokens.SemanticFeatures.NamedEntities))
 	namedScore := clamp(neCount*15.0, 0.0, 100.0)
@@ -648,6 +701,14 @@ func (grader *ModernPromptGrader) modernContextProvision(text string, ideas Idea
 		{Name: "Domain Constraints", Value: domainScore, Weight: 0.20, Contribution: domainScore * 0.20, IsPositive: true, ContextRelevant: true},
 		{Name: "General Coherence", Value: ideas.ConceptualCoherence.Value*100.0, Weight: 0.15, Contribution: ideas.ConceptualCoherence.Value*100.0 * 0.15, IsPositive: true, ContextRelevant: true},
 	}
+	// Persona and audience matter most for creative and writing prompts
+	roleWeight := blendedRoleWeight(blend)
+	for i := range factors {
+		factors[i].Weight *= 1 - roleWeight
+		factors[i].Contribution *= 1 - roleWeight
+	}
+	roleScore := roleFactorScore(components)
+	factors = append(factors, ModernFactor{Name: "Role & Audience", Value: roleScore, Weight: roleWeight, Contribution: roleScore * roleWeight, IsPositive: true, ContextRelevant: roleWeight >= 0.10})
 	total := 0.0
 	for _, f := range factors { total += f.Contribution }
 	score := math.Round(total*100) / 100
@@ -666,8 +727,8 @@ func (grader *ModernPromptGrader) calculateStructureQuality(ideas IdeaAnalysisMe
 	progScore := 70.0
 	if progression == "linear" { progScore = 90.0 } else if progression == "branching" { progScore = 80.0 } else if progression == "circular" { progScore = 60.0 }
 
-	varVar := complexity.SentenceStats.SentenceLengthVar.Value
-	varScore := clamp(100.0 - varVar*2.0, 40.0, 95.0)
+	lengthSpread := complexity.SentenceStats.SentenceLengthStd.Value
+	varScore := clamp(100.0 - lengthSpread*2.0, 40.0, 95.0)
 
 	factors := []ModernFactor{
 		{Name: "Coherence", Value: coherence, Weight: 0.40, Contribution: coherence * 0.40, IsPositive: true, ContextRelevant: true},
@@ -683,6 +744,9 @@ func (grader *ModernPromptGrader) calculateStructureQuality(ideas IdeaAnalysisMe
 
 // scoreToRealisticGrade - more generous grade boundaries  
 func (grader *ModernPromptGrader) scoreToRealisticGrade(score float64) string {
+	if len(grader.gradeThresholds) > 0 {
+		return grader.thresholdGrade(score)
+	}
 	if score >= 90 {
 		return "A+"
 	} else if score >= 85 {
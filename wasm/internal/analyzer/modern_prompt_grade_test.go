@@ -457,4 +457,28 @@ func generateBenchmarkReport(t *testing.T, results map[string]TestResult, testCa
 	if avgScoreError > 15.0 {
 		t.Errorf("Average score error too high: %.1f points (expected ≤15)", avgScoreError)
 	}
-}
\ No newline at end of file
+}
+
+func TestBlendedDimensionWeights(t *testing.T) {
+	grader := NewModernPromptGrader()
+	blend := []PromptLabel{{Type: CodeGeneration, Confidence: 0.5}, {Type: DataAnalysis, Confidence: 0.5}}
+	w := grader.blendedWeights(blend)
+	code, data := grader.dimensionWeights[CodeGeneration], grader.dimensionWeights[DataAnalysis]
+	if want := (code.ContextProvision + data.ContextProvision) / 2; w.ContextProvision != want {
+		t.Errorf("expected context weight %.3f, got %.3f", want, w.ContextProvision)
+	}
+
+	dims := ModernDimensions{
+		Clarity:          ModernDimension{Score: 80},
+		Specificity:      ModernDimension{Score: 80},
+		Completeness:     ModernDimension{Score: 80},
+		Actionability:    ModernDimension{Score: 80},
+		ContextProvision: ModernDimension{Score: 20},
+		StructureQuality: ModernDimension{Score: 80},
+	}
+	pure := grader.realisticOverallGrade(dims, CodeGeneration).Score
+	mixed := grader.weightedOverallGrade(dims, w).Score
+	if mixed >= pure {
+		t.Errorf("expected blending in data analysis to weigh missing context more (%.2f vs %.2f)", mixed, pure)
+	}
+}
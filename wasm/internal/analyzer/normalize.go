@@ -0,0 +1,75 @@
+package analyzer
+
+import (
+	"reflect"
+)
+
+// Normalize walks a result value and replaces nil slices and maps with empty ones,
+// so every collection marshals as [] or {} instead of null. v must be a pointer.
+func Normalize(v interface{}) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return
+	}
+	normalizeValue(rv.Elem(), make(map[uintptr]bool))
+}
+
+// normalizeValue fills nil collections in place; visited guards against pointer cycles
+func normalizeValue(v reflect.Value, visited map[uintptr]bool) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() || visited[v.Pointer()] {
+			return
+		}
+		visited[v.Pointer()] = true
+		normalizeValue(v.Elem(), visited)
+
+	case reflect.Interface:
+		if v.IsNil() {
+			return
+		}
+		// Values inside an interface are not addressable; only pointers can be fixed in place
+		if elem := v.Elem(); elem.Kind() == reflect.Ptr {
+			normalizeValue(elem, visited)
+		}
+
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if field := v.Field(i); field.CanSet() {
+				normalizeValue(field, visited)
+			}
+		}
+
+	case reflect.Slice:
+		if v.IsNil() {
+			if v.CanSet() {
+				v.Set(reflect.MakeSlice(v.Type(), 0, 0))
+			}
+			return
+		}
+		for i := 0; i < v.Len(); i++ {
+			normalizeValue(v.Index(i), visited)
+		}
+
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			normalizeValue(v.Index(i), visited)
+		}
+
+	case reflect.Map:
+		if v.IsNil() {
+			if v.CanSet() {
+				v.Set(reflect.MakeMap(v.Type()))
+			}
+			return
+		}
+		// Map values are not addressable, so normalize a copy and store it back
+		iter := v.MapRange()
+		for iter.Next() {
+			elem := reflect.New(iter.Value().Type()).Elem()
+			elem.Set(iter.Value())
+			normalizeValue(elem, visited)
+			v.SetMapIndex(iter.Key(), elem)
+		}
+	}
+}
@@ -0,0 +1,395 @@
+package analyzer
+
+import (
+	"reflect"
+	"strings"
+)
+
+// OpenAPISpec describes the HTTP API as an OpenAPI 3.1 document. result is the
+// analysis result value (the server's combined result type); its schema is used
+// for /analyze responses and batch items.
+func OpenAPISpec(result interface{}) map[string]interface{} {
+	g := newSchemaGenerator("#/components/schemas/")
+	ref := func(v interface{}) map[string]interface{} {
+		return g.schemaFor(reflect.TypeOf(v))
+	}
+
+	resultRef := ref(result)
+	analyzeRequest := ref(AnalyzeRequest{})
+	batchRequest := ref(BatchRequest{})
+	batchResponse := ref(BatchResponse{})
+	compareRequest := ref(CompareRequest{})
+	compareResponse := ref(CompareResponse{})
+	historyResponse := ref(HistoryResponse{})
+	healthResponse := ref(HealthResponse{})
+	wordCloud := ref(WordCloud{})
+	searchResponse := ref(SearchResponse{})
+	prReviewRequest := ref(PRReviewRequest{})
+	prReviewResponse := ref(PRReviewResponse{})
+	evaluateRequest := ref(EvaluateRequest{})
+	evaluationResponse := ref(EvaluationResponse{})
+	whatIfRequest := ref(WhatIfRequest{})
+	whatIfResult := ref(WhatIfResult{})
+	createExperimentRequest := ref(CreateExperimentRequest{})
+	outcomesRequest := ref(OutcomesRequest{})
+	experiment := ref(Experiment{})
+	experimentReport := ref(ExperimentReport{})
+	learnedWeights := ref(LearnedWeights{})
+	runtimeConfig := ref(RuntimeConfig{})
+	apiError := ref(APIError{})
+
+	// json.RawMessage has no shape of its own; batch results are analysis results
+	if item, ok := g.defs["BatchItem"].(map[string]interface{}); ok {
+		item["properties"].(map[string]interface{})["result"] = resultRef
+	}
+
+	adminSecurity := []interface{}{map[string]interface{}{"adminToken": []string{}}}
+
+	// Response shaping for /analyze; each overrides the options in the body
+	outputParameters := []interface{}{
+		queryParameter("fields", "Comma-separated dotted result paths to keep, e.g. prompt_grade,insights", map[string]interface{}{"type": "string"}),
+		queryParameter("max_cluster_sentences", "Sentences returned per idea cluster; 0 returns all", map[string]interface{}{"type": "integer", "minimum": 0}),
+		queryParameter("max_suggestion_examples", "Suggestions that keep their example text; 0 keeps all", map[string]interface{}{"type": "integer", "minimum": 0}),
+	}
+
+	errorResponses := map[string]interface{}{
+		"400": jsonResponse("Invalid request", apiError),
+		"413": jsonResponse("Request body too large", apiError),
+		"503": jsonResponse("Analysis exceeded the request timeout", apiError),
+	}
+	withErrors := func(ok map[string]interface{}) map[string]interface{} {
+		responses := map[string]interface{}{"200": ok}
+		for code, r := range errorResponses {
+			responses[code] = r
+		}
+		return responses
+	}
+
+	paths := map[string]interface{}{
+		"/analyze": map[string]interface{}{
+			"post": map[string]interface{}{
+				"operationId": "analyze",
+				"summary":     "Analyze a prompt",
+				"requestBody": jsonRequestBody(analyzeRequest),
+				"parameters": append([]interface{}{
+					queryParameter("format", "Response encoding; overrides the Accept header", map[string]interface{}{"type": "string", "enum": []string{ResponseJSON, ResponseSSE}}),
+				}, outputParameters...),
+				"responses": withErrors(analyzeResponse(resultRef)),
+			},
+		},
+		"/report": map[string]interface{}{
+			"post": map[string]interface{}{
+				"operationId": "report",
+				"summary":     "Analyze a prompt and render a self-contained report",
+				"requestBody": jsonRequestBody(analyzeRequest),
+				"parameters": []interface{}{
+					queryParameter("format", "Report format", map[string]interface{}{"type": "string", "enum": []string{ReportHTML, ReportMarkdown, ReportPDF}, "default": ReportHTML}),
+					queryParameter("title", "Report heading", map[string]interface{}{"type": "string"}),
+				},
+				"responses": withErrors(reportResponse()),
+			},
+		},
+		"/batch": map[string]interface{}{
+			"post": map[string]interface{}{
+				"operationId": "batch",
+				"summary":     "Analyze several prompts; failures are reported per item",
+				"requestBody": jsonRequestBody(batchRequest),
+				"responses":   withErrors(jsonResponse("One result or error per item", batchResponse)),
+			},
+		},
+		"/compare": map[string]interface{}{
+			"post": map[string]interface{}{
+				"operationId": "compare",
+				"summary":     "Grade two versions of a prompt side by side",
+				"requestBody": jsonRequestBody(compareRequest),
+				"responses":   withErrors(jsonResponse("Both grades and the score change", compareResponse)),
+			},
+		},
+		"/wordcloud": map[string]interface{}{
+			"post": map[string]interface{}{
+				"operationId": "wordCloud",
+				"summary":     "Top TF-IDF weighted terms of a prompt, overall and per idea cluster",
+				"requestBody": jsonRequestBody(analyzeRequest),
+				"parameters": []interface{}{
+					queryParameter("top", "Terms to return", map[string]interface{}{"type": "integer", "minimum": 1, "maximum": MaxWordCloudTerms, "default": DefaultWordCloudTerms}),
+				},
+				"responses": withErrors(jsonResponse("Weighted terms for a word cloud", wordCloud)),
+			},
+		},
+		"/pr-review": map[string]interface{}{
+			"post": map[string]interface{}{
+				"operationId": "prReview",
+				"summary":     "Grade the prompt files a pull request changes and render a PR comment",
+				"requestBody": jsonRequestBody(prReviewRequest),
+				"parameters": []interface{}{
+					queryParameter("format", "markdown returns only the comment", map[string]interface{}{"type": "string", "enum": []string{"json", ReportMarkdown}}),
+				},
+				"responses": withErrors(jsonResponse("Per-file grades and the comment markdown", prReviewResponse)),
+			},
+		},
+		"/evaluate": map[string]interface{}{
+			"post": map[string]interface{}{
+				"operationId": "evaluate",
+				"summary":     "Score an LLM response on task coverage, answered questions and output format",
+				"requestBody": jsonRequestBody(evaluateRequest),
+				"responses":   withErrors(jsonResponse("The response and its evaluation", evaluationResponse)),
+			},
+		},
+		"/what-if": map[string]interface{}{
+			"post": map[string]interface{}{
+				"operationId": "whatIf",
+				"summary":     "Predict the grade after proposed changes, such as splitting long sentences or removing an idea cluster",
+				"requestBody": jsonRequestBody(whatIfRequest),
+				"responses":   withErrors(jsonResponse("The changed text with its grade before and after", whatIfResult)),
+			},
+		},
+		"/experiments": map[string]interface{}{
+			"get": map[string]interface{}{
+				"operationId": "listExperiments",
+				"summary":     "List prompt experiments in creation order",
+				"responses": map[string]interface{}{
+					"200": jsonResponse("Every experiment", map[string]interface{}{"type": "array", "items": experiment}),
+				},
+			},
+			"post": map[string]interface{}{
+				"operationId": "createExperiment",
+				"summary":     "Register an experiment; each prompt variant is graded and lettered A, B, ...",
+				"requestBody": jsonRequestBody(createExperimentRequest),
+				"responses": map[string]interface{}{
+					"201": jsonResponse("The experiment with its graded variants", experiment),
+					"400": errorResponses["400"],
+					"413": errorResponses["413"],
+				},
+			},
+		},
+		"/experiments/report": map[string]interface{}{
+			"get": map[string]interface{}{
+				"operationId": "pooledExperimentReport",
+				"summary":     "Correlate dimension scores with outcomes across every experiment",
+				"responses": map[string]interface{}{
+					"200": jsonResponse("Correlations by metric, strongest first", experimentReport),
+				},
+			},
+		},
+		"/experiments/weights": map[string]interface{}{
+			"get": map[string]interface{}{
+				"operationId": "learnDimensionWeights",
+				"summary":     "Learn per-prompt-type dimension weights from recorded outcomes",
+				"parameters": []interface{}{
+					queryParameter("metric", "Outcome metric to predict, e.g. task_success", map[string]interface{}{"type": "string"}),
+					queryParameter("min_samples", "Outcomes a prompt type needs before its weights are learned", map[string]interface{}{"type": "integer", "minimum": 2, "default": defaultWeightMinSamples}),
+				},
+				"responses": map[string]interface{}{
+					"200": jsonResponse("Learned weights, ready to load as a rubric", learnedWeights),
+					"400": errorResponses["400"],
+					"422": jsonResponse("Too few outcomes to learn from", apiError),
+				},
+			},
+		},
+		"/experiments/{id}": map[string]interface{}{
+			"get": map[string]interface{}{
+				"operationId": "getExperiment",
+				"summary":     "One experiment and its variants",
+				"parameters":  []interface{}{pathParameter("id", "Experiment ID")},
+				"responses": map[string]interface{}{
+					"200": jsonResponse("The experiment", experiment),
+					"404": jsonResponse("No experiment has that ID", apiError),
+				},
+			},
+		},
+		"/experiments/{id}/outcomes": map[string]interface{}{
+			"post": map[string]interface{}{
+				"operationId": "recordOutcomes",
+				"summary":     "Record outcome metrics such as task_success or user_rating for variants",
+				"parameters":  []interface{}{pathParameter("id", "Experiment ID")},
+				"requestBody": jsonRequestBody(outcomesRequest),
+				"responses": map[string]interface{}{
+					"201": jsonResponse("The experiment with its updated outcome count", experiment),
+					"400": errorResponses["400"],
+					"404": jsonResponse("No experiment has that ID", apiError),
+					"413": errorResponses["413"],
+				},
+			},
+		},
+		"/experiments/{id}/report": map[string]interface{}{
+			"get": map[string]interface{}{
+				"operationId": "experimentReport",
+				"summary":     "Per-variant outcomes and which dimensions predict them",
+				"parameters":  []interface{}{pathParameter("id", "Experiment ID")},
+				"responses": map[string]interface{}{
+					"200": jsonResponse("Variant results and correlations", experimentReport),
+					"404": jsonResponse("No experiment has that ID", apiError),
+				},
+			},
+		},
+		"/history": map[string]interface{}{
+			"get": map[string]interface{}{
+				"operationId": "history",
+				"summary":     "List stored analyses, newest first",
+				"parameters": []interface{}{
+					queryParameter("limit", "Maximum entries to return", map[string]interface{}{"type": "integer", "minimum": 1, "maximum": MaxHistoryLimit, "default": DefaultHistoryLimit}),
+					queryParameter("cursor", "next_cursor from the previous page", map[string]interface{}{"type": "string"}),
+					queryParameter("project", "Only entries tagged with this project", map[string]interface{}{"type": "string"}),
+					queryParameter("author", "Only entries tagged with this author", map[string]interface{}{"type": "string"}),
+					queryParameter("label", "Only entries carrying this label; repeat for several", map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}}),
+				},
+				"responses": map[string]interface{}{
+					"200": jsonResponse("A page of history entries", historyResponse),
+					"400": errorResponses["400"],
+				},
+			},
+		},
+		"/analyses/{id}/search": map[string]interface{}{
+			"get": map[string]interface{}{
+				"operationId": "searchAnalysis",
+				"summary":     "Find the clusters, tasks and suggestions of a stored analysis that mention a term",
+				"parameters": []interface{}{
+					pathParameter("id", "Stored analysis ID, as listed by /history"),
+					queryParameter("q", "Term to look for, ignoring case", map[string]interface{}{"type": "string", "minLength": 1, "maxLength": maxSearchQuery}),
+				},
+				"responses": map[string]interface{}{
+					"200": jsonResponse("Matches by kind, in result order", searchResponse),
+					"400": errorResponses["400"],
+					"404": jsonResponse("No stored analysis has that ID", apiError),
+				},
+			},
+		},
+		"/schema": map[string]interface{}{
+			"get": map[string]interface{}{
+				"operationId": "resultSchema",
+				"summary":     "JSON Schema of the analysis result, versioned with schema_version",
+				"responses": map[string]interface{}{
+					"200": map[string]interface{}{
+						"description": "JSON Schema (draft 2020-12)",
+						"content":     map[string]interface{}{"application/schema+json": map[string]interface{}{"schema": map[string]interface{}{"type": "object"}}},
+					},
+				},
+			},
+		},
+		"/metrics": map[string]interface{}{
+			"get": map[string]interface{}{
+				"operationId": "metrics",
+				"summary":     "Stage latency histograms and worker pool queues for Prometheus",
+				"responses": map[string]interface{}{
+					"200": map[string]interface{}{
+						"description": "Prometheus text exposition format",
+						"content":     map[string]interface{}{"text/plain": map[string]interface{}{"schema": map[string]interface{}{"type": "string"}}},
+					},
+				},
+			},
+		},
+		"/health": map[string]interface{}{
+			"get": map[string]interface{}{
+				"operationId": "health",
+				"summary":     "Liveness, version and build check",
+				"responses": map[string]interface{}{
+					"200": jsonResponse("Service is up", healthResponse),
+				},
+			},
+		},
+		"/health/ready": map[string]interface{}{
+			"get": map[string]interface{}{
+				"operationId": "ready",
+				"summary":     "Readiness check, including storage connectivity",
+				"responses": map[string]interface{}{
+					"200": jsonResponse("Service can take traffic", healthResponse),
+					"503": jsonResponse("A dependency check failed", healthResponse),
+				},
+			},
+		},
+		"/admin/config": map[string]interface{}{
+			"get": map[string]interface{}{
+				"operationId": "getRuntimeConfig",
+				"summary":     "Report the worker, cache and analysis limits in effect",
+				"security":    adminSecurity,
+				"responses": map[string]interface{}{
+					"200": jsonResponse("Current runtime limits", runtimeConfig),
+					"401": jsonResponse("Missing or invalid admin token", apiError),
+				},
+			},
+			"patch": map[string]interface{}{
+				"operationId": "updateRuntimeConfig",
+				"summary":     "Change runtime limits; omitted fields keep their values",
+				"security":    adminSecurity,
+				"requestBody": jsonRequestBody(runtimeConfig),
+				"responses": map[string]interface{}{
+					"200": jsonResponse("Runtime limits after the change", runtimeConfig),
+					"400": errorResponses["400"],
+					"401": jsonResponse("Missing or invalid admin token", apiError),
+				},
+			},
+		},
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.1.0",
+		"info": map[string]interface{}{
+			"title":   "Fulcrum prompt analysis API",
+			"version": ResultSchemaVersion,
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": g.defs,
+			"securitySchemes": map[string]interface{}{
+				"adminToken": map[string]interface{}{"type": "http", "scheme": "bearer"},
+			},
+		},
+	}
+}
+
+func jsonRequestBody(schema map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"required": true,
+		"content":  map[string]interface{}{"application/json": map[string]interface{}{"schema": schema}},
+	}
+}
+
+func jsonResponse(description string, schema map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"description": description,
+		"content":     map[string]interface{}{"application/json": map[string]interface{}{"schema": schema}},
+	}
+}
+
+// analyzeResponse offers the result as JSON or as a DataStar SSE event
+func analyzeResponse(resultRef map[string]interface{}) map[string]interface{} {
+	response := jsonResponse("Analysis result", resultRef)
+	response["content"].(map[string]interface{})["text/event-stream"] = map[string]interface{}{
+		"schema": map[string]interface{}{
+			"type":        "string",
+			"description": "event: datastar-patch-signals with data: signals {\"result\": <analysis result>}",
+		},
+	}
+	return response
+}
+
+// reportResponse offers the rendered report as HTML or markdown
+func reportResponse() map[string]interface{} {
+	content := map[string]interface{}{}
+	for format, contentType := range ReportContentTypes {
+		mediaType := strings.SplitN(contentType, ";", 2)[0]
+		content[mediaType] = map[string]interface{}{
+			"schema": map[string]interface{}{"type": "string", "description": "The report as " + format},
+		}
+	}
+	return map[string]interface{}{"description": "Rendered report", "content": content}
+}
+
+func pathParameter(name, description string) map[string]interface{} {
+	return map[string]interface{}{
+		"name":        name,
+		"in":          "path",
+		"required":    true,
+		"description": description,
+		"schema":      map[string]interface{}{"type": "string"},
+	}
+}
+
+func queryParameter(name, description string, schema map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"name":        name,
+		"in":          "query",
+		"description": description,
+		"schema":      schema,
+	}
+}
@@ -0,0 +1,398 @@
+package analyzer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Stage names accepted in AnalysisOptions.Stages
+const (
+	StageComplexity    = "complexity"
+	StageTokens        = "tokens"
+	StagePreprocessing = "preprocessing"
+	StageIdeas         = "ideas"
+	StageInsights      = "insights"
+	StageTaskGraph     = "task_graph"
+	StageGrade         = "grade"
+)
+
+// AllStages lists every selectable stage in pipeline order
+var AllStages = []string{
+	StageComplexity, StageTokens, StagePreprocessing, StageIdeas,
+	StageTaskGraph, StageInsights, StageGrade,
+}
+
+// stageDependencies lists the stages whose output another stage cannot run without.
+// Grading can use ideas and the task graph but falls back without them, so that
+// lightweight callers can skip clustering and task extraction.
+var stageDependencies = map[string][]string{
+	StageInsights: {StageComplexity, StageTokens, StagePreprocessing, StageIdeas},
+	StageGrade:    {StageComplexity, StageTokens, StagePreprocessing},
+}
+
+// StageResultKeys maps each stage to the top-level keys of its output in the analysis result
+var StageResultKeys = map[string][]string{
+	StageComplexity:    {"complexity_metrics"},
+	StageTokens:        {"tokens"},
+	StagePreprocessing: {"preprocessing"},
+	StageIdeas:         {"idea_analysis"},
+	StageInsights:      {"insights"},
+	StageTaskGraph:     {"task_graph"},
+	StageGrade:         {"prompt_grade", "modern_grade", "grades"},
+}
+
+// Result formats accepted in AnalysisOptions.Format
+const (
+	FormatJSON   = "json"   // A JSON string (default)
+	FormatObject = "object" // Structured values the WASM layer converts to JS objects
+)
+
+// AnalysisOptions controls which parts of the analysis run and are returned
+type AnalysisOptions struct {
+	Stages []string `json:"stages,omitempty"` // Empty means every stage
+	Format string   `json:"format,omitempty"` // FormatJSON or FormatObject
+	// Rules disables suggestion rules or overrides their priority by rule ID
+	Rules SuggestionRuleConfig `json:"rules,omitempty"`
+	// Stopwords picks the stopword language and adds domain packs or custom words
+	Stopwords StopwordConfig `json:"stopwords,omitempty"`
+	// InputFormat reads the text as a prompt file, mapping its roles and
+	// template variables into document and grading its system and user
+	// messages: one of InputFormats; "auto" detects it, "" means plain text
+	InputFormat string `json:"input_format,omitempty"`
+	// Glossary defines domain terms so they aren't flagged as jargon
+	Glossary []GlossaryTerm `json:"glossary,omitempty"`
+	// Spelling adds words the spell checker should accept
+	Spelling SpellingConfig `json:"spelling,omitempty"`
+	// Classifier adds or overrides prompt categories learned from labeled examples
+	Classifier ClassifierModel `json:"classifier,omitempty"`
+	// ReadingSpeed sets the words per minute behind reading, speaking and skimming times
+	ReadingSpeed ReadingSpeedConfig `json:"reading_speed,omitempty"`
+	// InclusiveLanguage turns on inclusive language style suggestions and edits their term lists
+	InclusiveLanguage InclusiveLanguageConfig `json:"inclusive_language,omitempty"`
+	// ClusteringStrategy picks how sentences are grouped into ideas, trading
+	// speed for quality; empty means greedy, the fastest
+	ClusteringStrategy string `json:"clustering_strategy,omitempty"`
+	// Grader picks the grading engine: "classic" (the default) fills
+	// prompt_grade, "modern" fills modern_grade, "both" runs the two; either
+	// way grades carries every engine's score in one shape
+	Grader string `json:"grader,omitempty"`
+	// Rubric replaces the modern engine's dimension weights, grade
+	// thresholds and suggestion priorities with a team's own; grading with a
+	// rubric defaults to the modern engine. Rules and Classifier apply over
+	// the rubric's.
+	Rubric *RubricConfig `json:"rubric,omitempty"`
+	// Stability regrades this many sentence subsets of the prompt and reports
+	// the score's confidence interval as stability; 0 turns it off, and each
+	// sample costs about one grade stage
+	Stability int `json:"stability,omitempty"`
+	// Sections grades each headed section of a long document, such as a
+	// spec or PRD, on its own and reports the scoreboard as sections
+	Sections bool `json:"sections,omitempty"`
+	// Explain attaches a trace of every factor's inputs and weights to each grade dimension
+	Explain bool `json:"explain,omitempty"`
+	// Deterministic makes identical text and options produce byte-identical
+	// results for snapshot tests and caches: the request ID is derived from the
+	// text and timings are zeroed
+	Deterministic bool `json:"deterministic,omitempty"`
+	// Fields keeps only these dotted result paths, e.g. "prompt_grade" or
+	// "idea_analysis.semantic_clusters.value.main_topic"; empty returns everything
+	Fields []string `json:"fields,omitempty"`
+	// MaxClusterSentences caps the sentences returned per idea cluster; 0 returns all
+	MaxClusterSentences int `json:"max_cluster_sentences,omitempty"`
+	// MaxSuggestionExamples caps how many suggestions keep their example text; 0 keeps all
+	MaxSuggestionExamples int `json:"max_suggestion_examples,omitempty"`
+	// Compression gzips JSON results from the WASM module, which the JS
+	// wrapper decompresses; the server compresses through Accept-Encoding instead
+	Compression string `json:"compression,omitempty"`
+	// FullTransformationLog keeps each preprocessing step's full input and
+	// output instead of a word diff
+	FullTransformationLog bool `json:"full_transformation_log,omitempty"`
+	// PhoneRegion is the ISO country code for phone numbers written without a
+	// calling code, e.g. "GB"; "" means US
+	PhoneRegion string `json:"phone_region,omitempty"`
+	// ReferenceTime resolves relative dates such as "next Tuesday", as RFC 3339
+	// or YYYY-MM-DD; empty uses the time of analysis, or leaves them
+	// unresolved when Deterministic is set
+	ReferenceTime string `json:"reference_time,omitempty"`
+	// StripEmoji removes Unicode emoji from the cleaned preprocessing text;
+	// they are kept by default and always reported under extraction results
+	StripEmoji bool `json:"strip_emoji,omitempty"`
+	// Cleaning composes the preprocessing cleaning pipeline from named steps,
+	// run in the order given, e.g. ["strip_urls","collapse_whitespace","trim"]
+	// keeps newlines; empty runs DefaultCleaningSteps
+	Cleaning []string `json:"cleaning,omitempty"`
+	// PreserveStructure keeps line and paragraph breaks through cleaning and
+	// normalization, so lists and paragraphs survive, and adds the flat and
+	// structured variants as preprocessing.structured_text
+	PreserveStructure bool `json:"preserve_structure,omitempty"`
+	// TokenStream adds tokens.stream: every non-whitespace token with byte and
+	// UTF-16 offsets, POS tag, lemma, stopword flag and syllables
+	TokenStream bool `json:"token_stream,omitempty"`
+}
+
+// ParseAnalysisOptions decodes options from a JSON object such as {"stages":["tokens","grade"]}
+func ParseAnalysisOptions(data []byte) (AnalysisOptions, error) {
+	var opts AnalysisOptions
+	if len(bytes.TrimSpace(data)) == 0 {
+		return opts, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&opts); err != nil {
+		return AnalysisOptions{}, fmt.Errorf("invalid analysis options: %w", err)
+	}
+	if err := opts.Validate(); err != nil {
+		return AnalysisOptions{}, err
+	}
+	return opts, nil
+}
+
+// WithDefaults fills the options o leaves unset from d, field by field, so a
+// request that sets one option keeps a deployment's other defaults. Rules
+// layer over d's; switches d turns on stay on.
+func (o AnalysisOptions) WithDefaults(d AnalysisOptions) AnalysisOptions {
+	if len(o.Stages) == 0 {
+		o.Stages = d.Stages
+	}
+	if o.Format == "" && o.Compression == "" {
+		o.Format, o.Compression = d.Format, d.Compression
+	}
+	o.Rules = o.Rules.over(d.Rules)
+	if o.Stopwords.Language == "" {
+		o.Stopwords.Language = d.Stopwords.Language
+	}
+	if len(o.Stopwords.Packs) == 0 {
+		o.Stopwords.Packs = d.Stopwords.Packs
+	}
+	if len(o.Stopwords.Add) == 0 {
+		o.Stopwords.Add = d.Stopwords.Add
+	}
+	if len(o.Stopwords.Remove) == 0 {
+		o.Stopwords.Remove = d.Stopwords.Remove
+	}
+	if o.InputFormat == "" {
+		o.InputFormat = d.InputFormat
+	}
+	if len(o.Glossary) == 0 {
+		o.Glossary = d.Glossary
+	}
+	if len(o.Spelling.Allow) == 0 {
+		o.Spelling.Allow = d.Spelling.Allow
+	}
+	if len(o.Classifier.Categories) == 0 {
+		o.Classifier = d.Classifier
+	}
+	if o.ReadingSpeed.ReadingWPM == 0 {
+		o.ReadingSpeed.ReadingWPM = d.ReadingSpeed.ReadingWPM
+	}
+	if o.ReadingSpeed.SpeakingWPM == 0 {
+		o.ReadingSpeed.SpeakingWPM = d.ReadingSpeed.SpeakingWPM
+	}
+	if o.ReadingSpeed.SkimmingWPM == 0 {
+		o.ReadingSpeed.SkimmingWPM = d.ReadingSpeed.SkimmingWPM
+	}
+	o.InclusiveLanguage.Enabled = o.InclusiveLanguage.Enabled || d.InclusiveLanguage.Enabled
+	if len(o.InclusiveLanguage.Categories) == 0 {
+		o.InclusiveLanguage.Categories = d.InclusiveLanguage.Categories
+	}
+	if len(o.InclusiveLanguage.Terms) == 0 {
+		o.InclusiveLanguage.Terms = d.InclusiveLanguage.Terms
+	}
+	if len(o.InclusiveLanguage.Ignore) == 0 {
+		o.InclusiveLanguage.Ignore = d.InclusiveLanguage.Ignore
+	}
+	if o.ClusteringStrategy == "" {
+		o.ClusteringStrategy = d.ClusteringStrategy
+	}
+	// A rubric grades with the modern engine, so neither side's rubric is
+	// paired with the other's classic grader
+	if o.Grader == "" && (o.Rubric == nil || d.Grader != GraderClassic) {
+		o.Grader = d.Grader
+	}
+	if o.Rubric == nil && o.Grader != GraderClassic {
+		o.Rubric = d.Rubric
+	}
+	if o.Stability == 0 {
+		o.Stability = d.Stability
+	}
+	o.Sections = o.Sections || d.Sections
+	o.Explain = o.Explain || d.Explain
+	o.Deterministic = o.Deterministic || d.Deterministic
+	if len(o.Fields) == 0 {
+		o.Fields = d.Fields
+	}
+	if o.MaxClusterSentences == 0 {
+		o.MaxClusterSentences = d.MaxClusterSentences
+	}
+	if o.MaxSuggestionExamples == 0 {
+		o.MaxSuggestionExamples = d.MaxSuggestionExamples
+	}
+	o.FullTransformationLog = o.FullTransformationLog || d.FullTransformationLog
+	if o.PhoneRegion == "" {
+		o.PhoneRegion = d.PhoneRegion
+	}
+	if o.ReferenceTime == "" {
+		o.ReferenceTime = d.ReferenceTime
+	}
+	o.StripEmoji = o.StripEmoji || d.StripEmoji
+	if len(o.Cleaning) == 0 {
+		o.Cleaning = d.Cleaning
+	}
+	o.PreserveStructure = o.PreserveStructure || d.PreserveStructure
+	o.TokenStream = o.TokenStream || d.TokenStream
+	return o
+}
+
+// Validate rejects unknown stage names and formats
+func (o AnalysisOptions) Validate() error {
+	if o.Format != "" && o.Format != FormatJSON && o.Format != FormatObject {
+		return fmt.Errorf("unknown format %q (expected %q or %q)", o.Format, FormatJSON, FormatObject)
+	}
+	for _, stage := range o.Stages {
+		if _, ok := StageResultKeys[stage]; !ok {
+			return fmt.Errorf("unknown stage %q (expected one of %v)", stage, AllStages)
+		}
+	}
+	if err := o.Stopwords.Validate(); err != nil {
+		return err
+	}
+	if err := validateGlossary(o.Glossary); err != nil {
+		return err
+	}
+	if err := o.Classifier.Validate(); err != nil {
+		return err
+	}
+	if err := o.ReadingSpeed.Validate(); err != nil {
+		return err
+	}
+	if err := o.InclusiveLanguage.Validate(); err != nil {
+		return err
+	}
+	if err := validateClusteringStrategy(o.ClusteringStrategy); err != nil {
+		return err
+	}
+	if err := validateInputFormat(o.InputFormat); err != nil {
+		return err
+	}
+	if err := validateGrader(o.Grader); err != nil {
+		return err
+	}
+	if o.Rubric != nil {
+		if o.Grader == GraderClassic {
+			return fmt.Errorf("a rubric grades with the modern engine; set grader to %q or %q", GraderModern, GraderBoth)
+		}
+		if err := o.Rubric.Validate(); err != nil {
+			return err
+		}
+	}
+	if err := validateStability(o.Stability); err != nil {
+		return err
+	}
+	if err := validateCompression(o.Compression, o.Format); err != nil {
+		return err
+	}
+	if err := validatePhoneRegion(o.PhoneRegion); err != nil {
+		return err
+	}
+	if _, err := parseReferenceTime(o.ReferenceTime); err != nil {
+		return err
+	}
+	if err := validateCleaningSteps(o.Cleaning); err != nil {
+		return err
+	}
+	if err := validateResultFields(o.Fields); err != nil {
+		return err
+	}
+	if o.MaxClusterSentences < 0 || o.MaxSuggestionExamples < 0 {
+		return fmt.Errorf("max_cluster_sentences and max_suggestion_examples must not be negative")
+	}
+	return o.Rules.Validate()
+}
+
+// spellingAllowlist combines the spelling allowlist with glossary terms, which
+// are domain words by definition
+func (o AnalysisOptions) spellingAllowlist() []string {
+	allow := append([]string{}, o.Spelling.Allow...)
+	for _, term := range o.Glossary {
+		allow = append(allow, term.Term)
+		allow = append(allow, term.Aliases...)
+	}
+	return allow
+}
+
+// classifierModel is the model prompts are classified with: Classifier, or
+// the rubric's when Classifier has no categories
+func (o AnalysisOptions) classifierModel() ClassifierModel {
+	if len(o.Classifier.Categories) == 0 && o.Rubric != nil {
+		return o.Rubric.Classifier
+	}
+	return o.Classifier
+}
+
+// referenceTime is the time relative dates are resolved against; the zero
+// time leaves them unresolved
+func (o AnalysisOptions) referenceTime() time.Time {
+	ref, _ := parseReferenceTime(o.ReferenceTime)
+	if ref.IsZero() && !o.Deterministic {
+		ref = time.Now().UTC()
+	}
+	return ref
+}
+
+// cleaningSteps is the cleaning pipeline to run: Cleaning or the default for the mode,
+// with strip_emoji first when StripEmoji asks for it
+func (o AnalysisOptions) cleaningSteps() []string {
+	steps := o.Cleaning
+	if len(steps) == 0 && o.PreserveStructure {
+		steps = StructuredCleaningSteps
+	} else if len(steps) == 0 {
+		steps = DefaultCleaningSteps
+	}
+	if o.StripEmoji && !contains(steps, CleanStripEmoji) {
+		steps = append([]string{CleanStripEmoji}, steps...)
+	}
+	return steps
+}
+
+// Wants reports whether the caller asked for a stage's output
+func (o AnalysisOptions) Wants(stage string) bool {
+	return len(o.Stages) == 0 || contains(o.Stages, stage)
+}
+
+// Runs reports whether a stage must execute, either because it was requested
+// or because a requested stage depends on it
+func (o AnalysisOptions) Runs(stage string) bool {
+	if len(o.Stages) == 0 {
+		return true
+	}
+	return o.runs(stage, make(map[string]bool))
+}
+
+func (o AnalysisOptions) runs(stage string, seen map[string]bool) bool {
+	if o.Wants(stage) {
+		return true
+	}
+	for dependent, deps := range stageDependencies {
+		if seen[dependent] || !contains(deps, stage) {
+			continue
+		}
+		seen[dependent] = true
+		if o.runs(dependent, seen) {
+			return true
+		}
+	}
+	return false
+}
+
+// SelectedStages returns the stages that will run, in pipeline order
+func (o AnalysisOptions) SelectedStages() []string {
+	stages := []string{}
+	for _, stage := range AllStages {
+		if o.Runs(stage) {
+			stages = append(stages, stage)
+		}
+	}
+	return stages
+}
@@ -0,0 +1,53 @@
+package analyzer
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestWithDefaults checks that defaults fill only the options a request
+// leaves unset
+func TestWithDefaults(t *testing.T) {
+	defaults := AnalysisOptions{
+		Stages:       []string{StageGrade},
+		Glossary:     []GlossaryTerm{{Term: "SLA"}},
+		Rules:        SuggestionRuleConfig{Disabled: []string{"add_examples"}, Priorities: map[string]string{"define_terms": "low"}},
+		Stopwords:    StopwordConfig{Language: "en", Packs: []string{"legal"}},
+		ReadingSpeed: ReadingSpeedConfig{ReadingWPM: 200, SpeakingWPM: 120},
+		Grader:       GraderModern,
+		Explain:      true,
+	}
+	got := AnalysisOptions{
+		Deterministic: true,
+		Stopwords:     StopwordConfig{Language: "es"},
+		ReadingSpeed:  ReadingSpeedConfig{ReadingWPM: 300},
+		Rules:         SuggestionRuleConfig{Priorities: map[string]string{"define_terms": "high"}},
+	}.WithDefaults(defaults)
+
+	if !got.Deterministic || !got.Explain || got.Grader != GraderModern {
+		t.Errorf("switches and grader = %v %v %q", got.Deterministic, got.Explain, got.Grader)
+	}
+	if !reflect.DeepEqual(got.Stages, defaults.Stages) || !reflect.DeepEqual(got.Glossary, defaults.Glossary) {
+		t.Errorf("stages %v, glossary %v: want the defaults", got.Stages, got.Glossary)
+	}
+	if got.Stopwords.Language != "es" || !reflect.DeepEqual(got.Stopwords.Packs, []string{"legal"}) {
+		t.Errorf("stopwords = %+v", got.Stopwords)
+	}
+	if got.ReadingSpeed != (ReadingSpeedConfig{ReadingWPM: 300, SpeakingWPM: 120}) {
+		t.Errorf("reading speed = %+v", got.ReadingSpeed)
+	}
+	if !reflect.DeepEqual(got.Rules.Disabled, []string{"add_examples"}) || got.Rules.Priorities["define_terms"] != "high" {
+		t.Errorf("rules = %+v", got.Rules)
+	}
+
+	rubric := &RubricConfig{}
+	if got := (AnalysisOptions{Grader: GraderClassic}).WithDefaults(AnalysisOptions{Rubric: rubric}); got.Rubric != nil {
+		t.Error("a classic request inherited the default rubric")
+	}
+	if got := (AnalysisOptions{Rubric: rubric}).WithDefaults(AnalysisOptions{Grader: GraderClassic}); got.Grader != "" {
+		t.Errorf("a rubric request inherited grader %q", got.Grader)
+	}
+	if got := (AnalysisOptions{}).WithDefaults(AnalysisOptions{}); !reflect.DeepEqual(got, AnalysisOptions{}) {
+		t.Errorf("no defaults = %+v", got)
+	}
+}
@@ -0,0 +1,237 @@
+package analyzer
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Outline section sources
+const (
+	OutlineFromContext = "context" // Introduction paragraphs
+	OutlineFromCluster = "cluster" // An idea cluster's sentences
+	OutlineFromTasks   = "tasks"   // The task graph's hierarchy
+	OutlineFromOutput  = "output"  // Conclusion paragraphs
+)
+
+// maxOutlineBulletWords shortens long sentences into outline bullets
+const maxOutlineBulletWords = 14
+
+// OutlineItem is one bullet of a proposed outline
+type OutlineItem struct {
+	Text     string        `json:"text"` // Condensed wording for the bullet
+	Span     Span          `json:"span"` // The sentence or task it came from
+	Children []OutlineItem `json:"children,omitempty"`
+}
+
+// OutlineSection is a heading and the bullets under it
+type OutlineSection struct {
+	Heading string        `json:"heading"`
+	Source  string        `json:"source"` // context, cluster, tasks or output
+	Items   []OutlineItem `json:"items"`
+}
+
+// Outline is a proposed skeleton for restructuring a prompt or document
+type Outline struct {
+	Title    string           `json:"title"`
+	Sections []OutlineSection `json:"sections"`
+	Markdown string           `json:"markdown"` // The outline as markdown headings and bullets
+}
+
+// GenerateOutline proposes a hierarchical outline: a Context section from
+// the introduction, one section per idea cluster in order of first mention,
+// a Tasks section following the task hierarchy, and an Output section from
+// the conclusion. Each sentence appears once, under the first section that
+// claims it, with task sentences reserved for the Tasks section.
+func GenerateOutline(text, title string, paragraphs ParagraphStructure, clusters []IdeaCluster, graph TaskGraph) Outline {
+	outline := Outline{Title: title, Sections: []OutlineSection{}}
+	used := map[int]bool{} // Sentence starts already placed
+
+	// Task sentences belong to the Tasks section
+	var tasks []OutlineItem
+	for _, t := range graph.TopLevelTasks() {
+		tasks = append(tasks, outlineTaskItem(text, &graph, t, used))
+	}
+
+	// place turns the unplaced lines of a sentence into bullets
+	place := func(start, end int) []OutlineItem {
+		items := []OutlineItem{}
+		for _, span := range outlineLines(text, start, end) {
+			if !used[span.Start] && !overlapsUsed(span, used) {
+				used[span.Start] = true
+				items = append(items, outlineBullet(span))
+			}
+		}
+		return items
+	}
+	sentencesIn := func(start, end int) []OutlineItem {
+		items := []OutlineItem{}
+		for _, s := range locateSentences(text[start:end]) {
+			items = append(items, place(start+s.Start, start+s.End)...)
+		}
+		return items
+	}
+
+	var context, output []OutlineItem
+	for _, p := range paragraphs.Paragraphs {
+		switch p.Role {
+		case RoleIntroduction:
+			context = append(context, sentencesIn(p.Start, p.End)...)
+		case RoleConclusion:
+			output = append(output, sentencesIn(p.Start, p.End)...)
+		}
+	}
+	if len(context) > 0 {
+		outline.Sections = append(outline.Sections, OutlineSection{Heading: "Context", Source: OutlineFromContext, Items: context})
+	}
+
+	// One section per cluster in order of first mention, merging clusters
+	// that share a heading
+	topics := []OutlineSection{}
+	for _, c := range clusters {
+		items := []OutlineItem{}
+		for _, s := range c.SentenceSpans {
+			if s.Start >= 0 && s.Start < s.End && s.End <= len(text) {
+				items = append(items, place(s.Start, s.End)...)
+			}
+		}
+		if len(items) > 0 {
+			topics = append(topics, OutlineSection{Heading: clusterHeading(c), Source: OutlineFromCluster, Items: items})
+		}
+	}
+	sort.SliceStable(topics, func(i, j int) bool {
+		return topics[i].Items[0].Span.Start < topics[j].Items[0].Span.Start
+	})
+	byHeading := map[string]int{}
+	for _, s := range topics {
+		if i, ok := byHeading[s.Heading]; ok {
+			outline.Sections[i].Items = append(outline.Sections[i].Items, s.Items...)
+			continue
+		}
+		byHeading[s.Heading] = len(outline.Sections)
+		outline.Sections = append(outline.Sections, s)
+	}
+
+	// Without clusters, body paragraphs stand in as sections
+	if len(clusters) == 0 {
+		for _, p := range paragraphs.Paragraphs {
+			if p.Role != RoleBody && p.Role != RoleList {
+				continue
+			}
+			if items := sentencesIn(p.Start, p.End); len(items) > 0 {
+				heading := titleCase(strings.ReplaceAll(p.Topic, " / ", " and "))
+				if heading == "" {
+					heading = "Details"
+				}
+				outline.Sections = append(outline.Sections, OutlineSection{Heading: heading, Source: OutlineFromCluster, Items: items})
+			}
+		}
+	}
+
+	if len(tasks) > 0 {
+		outline.Sections = append(outline.Sections, OutlineSection{Heading: "Tasks", Source: OutlineFromTasks, Items: tasks})
+	}
+	if len(output) > 0 {
+		outline.Sections = append(outline.Sections, OutlineSection{Heading: "Output", Source: OutlineFromOutput, Items: output})
+	}
+	outline.Markdown = outlineMarkdown(outline)
+	return outline
+}
+
+// outlineTaskItem turns a task and its subtasks into nested bullets and
+// marks their source sentences as placed
+func outlineTaskItem(text string, graph *TaskGraph, task Task, used map[int]bool) OutlineItem {
+	start, end := task.TextPosition.StartChar, task.TextPosition.EndChar
+	span := Span{Text: task.Title}
+	if start >= 0 && start < end && end <= len(text) {
+		span = trimmedSpan(text, start, end)
+		used[span.Start] = true
+	}
+	item := OutlineItem{Text: condenseBullet(task.Title), Span: span}
+	for _, child := range graph.Children(task.ID) {
+		if child.ID != task.ID {
+			item.Children = append(item.Children, outlineTaskItem(text, graph, child, used))
+		}
+	}
+	return item
+}
+
+// overlapsUsed reports whether a placed task sentence starts inside span, so
+// a cluster sentence that merely contains a task is not repeated
+func overlapsUsed(span Span, used map[int]bool) bool {
+	for start := range used {
+		if start > span.Start && start < span.End {
+			return true
+		}
+	}
+	return false
+}
+
+// outlineLines splits text[start:end] at line breaks, since list items that
+// lack final punctuation run together as one sentence, and drops lines with
+// no letters such as a bare list number
+func outlineLines(text string, start, end int) []Span {
+	spans := []Span{}
+	for start < end {
+		lineEnd := end
+		if i := strings.IndexByte(text[start:end], '\n'); i >= 0 {
+			lineEnd = start + i
+		}
+		span := trimmedSpan(text, start, lineEnd)
+		if strings.ContainsFunc(condenseBullet(span.Text), unicode.IsLetter) {
+			spans = append(spans, span)
+		}
+		start = lineEnd + 1
+	}
+	return spans
+}
+
+// outlineBullet condenses a sentence into a bullet
+func outlineBullet(span Span) OutlineItem {
+	return OutlineItem{Text: condenseBullet(span.Text), Span: span}
+}
+
+// condenseBullet drops list markers and final punctuation and cuts long
+// sentences to their first words
+func condenseBullet(sentence string) string {
+	sentence = strings.TrimSpace(sentence)
+	if marker := listItemPattern.FindString(sentence); marker != "" {
+		sentence = sentence[len(marker):]
+	}
+	words := strings.Fields(strings.TrimRight(sentence, ".!;: "))
+	if len(words) > maxOutlineBulletWords {
+		return strings.Join(words[:maxOutlineBulletWords], " ") + "…"
+	}
+	return strings.Join(words, " ")
+}
+
+// clusterHeading names a section after its cluster's label
+func clusterHeading(c IdeaCluster) string {
+	if c.MainTopic == "" || c.MainTopic == "General" {
+		return "Details"
+	}
+	return c.MainTopic
+}
+
+// outlineMarkdown renders the outline as markdown
+func outlineMarkdown(o Outline) string {
+	var b strings.Builder
+	if o.Title != "" {
+		b.WriteString("# " + o.Title + "\n\n")
+	}
+	var writeItems func(items []OutlineItem, depth int)
+	writeItems = func(items []OutlineItem, depth int) {
+		for _, item := range items {
+			b.WriteString(strings.Repeat("  ", depth) + "- " + item.Text + "\n")
+			writeItems(item.Children, depth+1)
+		}
+	}
+	for i, s := range o.Sections {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString("## " + s.Heading + "\n")
+		writeItems(s.Items, 0)
+	}
+	return b.String()
+}
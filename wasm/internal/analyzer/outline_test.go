@@ -0,0 +1,78 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+)
+
+const outlineTestText = "You are a project manager helping our team plan a database migration.\n\n" +
+	"The billing database is slow and the invoices table keeps growing. Payments time out during peak hours.\n\n" +
+	"1. Create a migration plan\n" +
+	"   - Update the schema diagram\n" +
+	"2. Estimate the downtime\n\n" +
+	"Finally, return the plan as a markdown document."
+
+func TestGenerateOutlineSections(t *testing.T) {
+	ideas := AnalyzeIdeas(outlineTestText)
+	clusters := ideas.SemanticClusters.Value
+	graph := ExtractTaskGraph(outlineTestText, extractSentences(outlineTestText), clusters)
+	outline := GenerateOutline(outlineTestText, "Migration Plan", AnalyzeParagraphs(outlineTestText), clusters, *graph)
+
+	sections := outline.Sections
+	if len(sections) < 3 {
+		t.Fatalf("got %d sections, want at least 3: %+v", len(sections), sections)
+	}
+	if sections[0].Source != OutlineFromContext || sections[0].Items[0].Text != "You are a project manager helping our team plan a database migration" {
+		t.Errorf("first section = %+v, want the introduction as context", sections[0])
+	}
+	if last := sections[len(sections)-1]; last.Source != OutlineFromOutput {
+		t.Errorf("last section = %+v, want the conclusion as output", last)
+	}
+
+	// Every sentence is placed once, and bullets point back at the text
+	seen := map[int]bool{}
+	var check func(items []OutlineItem)
+	check = func(items []OutlineItem) {
+		for _, item := range items {
+			if seen[item.Span.Start] {
+				t.Errorf("sentence at %d placed twice", item.Span.Start)
+			}
+			seen[item.Span.Start] = true
+			if outlineTestText[item.Span.Start:item.Span.End] != item.Span.Text {
+				t.Errorf("span %+v does not match the text", item.Span)
+			}
+			check(item.Children)
+		}
+	}
+	for _, s := range sections {
+		check(s.Items)
+	}
+
+	if !strings.HasPrefix(outline.Markdown, "# Migration Plan\n\n## Context\n- You are a project manager") {
+		t.Errorf("markdown = %q", outline.Markdown)
+	}
+}
+
+func TestGenerateOutlineFallsBackToParagraphs(t *testing.T) {
+	text := "Caching helps. Caching reduces load on the database.\n\nLogging matters too. Logging should include request IDs."
+	outline := GenerateOutline(text, "", AnalyzeParagraphs(text), nil, TaskGraph{})
+	var headings []string
+	for _, s := range outline.Sections {
+		headings = append(headings, s.Heading)
+	}
+	if strings.Join(headings, ",") != "Caching,Logging" {
+		t.Errorf("headings = %v, want one per paragraph topic", headings)
+	}
+}
+
+func TestCondenseBullet(t *testing.T) {
+	for in, want := range map[string]string{
+		"  - Check the indexes.": "Check the indexes",
+		"2) Estimate downtime":   "Estimate downtime",
+		"One two three four five six seven eight nine ten eleven twelve thirteen fourteen fifteen.": "One two three four five six seven eight nine ten eleven twelve thirteen fourteen…",
+	} {
+		if got := condenseBullet(in); got != want {
+			t.Errorf("condenseBullet(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
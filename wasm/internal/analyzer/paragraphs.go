@@ -0,0 +1,187 @@
+package analyzer
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Paragraph roles
+const (
+	RoleHeading      = "heading"
+	RoleList         = "list"
+	RoleIntroduction = "introduction"
+	RoleBody         = "body"
+	RoleConclusion   = "conclusion"
+)
+
+// ParagraphMetrics describes one blank-line-separated block of text
+type ParagraphMetrics struct {
+	Index       int     `json:"index"`
+	Start       int     `json:"start"` // Byte offsets in the original text
+	End         int     `json:"end"`
+	Sentences   int     `json:"sentences"`
+	Words       int     `json:"words"`
+	Topic       string  `json:"topic"`       // Most repeated content words, e.g. "api / tests"
+	Readability float64 `json:"readability"` // Flesch reading ease of the paragraph alone
+	Role        string  `json:"role"`        // One of the Role constants
+}
+
+// ParagraphStructure is the paragraph-level map of a document
+type ParagraphStructure struct {
+	Paragraphs      []ParagraphMetrics `json:"paragraphs"`
+	StructureMap    []string           `json:"structure_map"` // Paragraph roles in order
+	HasIntroduction bool               `json:"has_introduction"`
+	HasConclusion   bool               `json:"has_conclusion"`
+}
+
+var (
+	// Openers that set up context before the task
+	introCuePattern = regexp.MustCompile(`(?i)^\s*(?:i am|i'm|we are|we're|you are|you're|act as|as an?\b|context\b|background\b|overview\b|i need|we need|i want|i'd like|our team|this (?:project|document|prompt)|the goal)`)
+	// Closers that wrap up or state the deliverable
+	conclusionCuePattern = regexp.MustCompile(`(?i)\b(?:in summary|to summarize|in conclusion|overall|finally|deliverables?|return (?:the|a|only)|respond (?:with|in)|output (?:should|must|format)|format (?:the|your)|let me know|thanks|thank you)\b`)
+)
+
+// AnalyzeParagraphs splits text on blank lines and measures each paragraph.
+// Roles are guessed from layout and position: markdown headings and bullet
+// blocks are recognized first, then among the prose paragraphs the first is
+// an introduction and the last a conclusion when they carry a cue phrase
+// ("You are...", "Finally...") or the document has at least three of them.
+func AnalyzeParagraphs(text string) ParagraphStructure {
+	structure := ParagraphStructure{Paragraphs: []ParagraphMetrics{}, StructureMap: []string{}}
+
+	bounds := paragraphBounds(text)
+
+	prose := []int{}
+	for i, b := range bounds {
+		para := text[b[0]:b[1]]
+		sentences := extractSentences(para)
+		words := extractWords(para)
+		p := ParagraphMetrics{
+			Index:     i,
+			Start:     b[0],
+			End:       b[1],
+			Sentences: len(sentences),
+			Words:     len(words),
+			Topic:     paragraphTopic(words),
+			Role:      layoutRole(para),
+		}
+		if len(sentences) > 0 && len(words) > 0 {
+			p.Readability = 206.835 - 1.015*float64(len(words))/float64(len(sentences)) -
+				84.6*float64(calculateTotalSyllables(words))/float64(len(words))
+		}
+		if p.Role == RoleBody {
+			prose = append(prose, i)
+		}
+		structure.Paragraphs = append(structure.Paragraphs, p)
+	}
+
+	if len(prose) >= 2 {
+		first, last := prose[0], prose[len(prose)-1]
+		firstText := text[bounds[first][0]:bounds[first][1]]
+		lastText := text[bounds[last][0]:bounds[last][1]]
+		if len(prose) >= 3 || introCuePattern.MatchString(firstText) {
+			structure.Paragraphs[first].Role = RoleIntroduction
+		}
+		if len(prose) >= 3 || conclusionCuePattern.MatchString(lastSentence(lastText)) {
+			structure.Paragraphs[last].Role = RoleConclusion
+		}
+	}
+
+	for _, p := range structure.Paragraphs {
+		structure.StructureMap = append(structure.StructureMap, p.Role)
+		structure.HasIntroduction = structure.HasIntroduction || p.Role == RoleIntroduction
+		structure.HasConclusion = structure.HasConclusion || p.Role == RoleConclusion
+	}
+	// A single block of prose can still open with context and close with the deliverable
+	if len(prose) == 1 {
+		para := text[bounds[prose[0]][0]:bounds[prose[0]][1]]
+		if sentences := extractSentences(para); len(sentences) >= 3 {
+			structure.HasIntroduction = introCuePattern.MatchString(sentences[0])
+			structure.HasConclusion = conclusionCuePattern.MatchString(sentences[len(sentences)-1])
+		}
+	}
+	return structure
+}
+
+// paragraphBounds returns the start and end offsets of each blank-line-separated
+// paragraph, without surrounding whitespace
+func paragraphBounds(text string) [][2]int {
+	start := 0
+	bounds := [][2]int{}
+	for _, m := range append(paragraphBreakPattern.FindAllStringIndex(text, -1), []int{len(text), len(text)}) {
+		block := text[start:m[0]]
+		if trimmed := strings.TrimSpace(block); trimmed != "" {
+			lead := strings.Index(block, trimmed)
+			bounds = append(bounds, [2]int{start + lead, start + lead + len(trimmed)})
+		}
+		start = m[1]
+	}
+	return bounds
+}
+
+// layoutRole recognizes headings and lists; everything else is body prose
+func layoutRole(para string) string {
+	lines := strings.Split(para, "\n")
+	first := strings.TrimSpace(lines[0])
+	if len(lines) == 1 && (strings.HasPrefix(first, "#") || (strings.HasSuffix(first, ":") && len(strings.Fields(first)) <= 6)) {
+		return RoleHeading
+	}
+	items := 0
+	for _, line := range lines {
+		if listItemPattern.MatchString(line) {
+			items++
+		}
+	}
+	if items*2 >= len(lines) {
+		return RoleList
+	}
+	return RoleBody
+}
+
+// topicFillers are skipped when labeling paragraphs ("please", "really")
+var topicFillers = newStopwordSet(StopwordPacks["prompt"])
+
+// paragraphTopic labels a paragraph with its one or two most repeated content
+// words, falling back to the first content word when nothing repeats
+func paragraphTopic(words []string) string {
+	counts := map[string]int{}
+	order := []string{}
+	for _, w := range words {
+		if len(w) < 4 || isStopWord(w) || topicFillers.Contains(w) {
+			continue
+		}
+		if counts[w] == 0 {
+			order = append(order, w)
+		}
+		counts[w]++
+	}
+	if len(order) == 0 {
+		return ""
+	}
+	top := []string{}
+	for len(top) < 2 {
+		best := ""
+		for _, w := range order {
+			if counts[w] > 1 && counts[w] > counts[best] && !contains(top, w) {
+				best = w
+			}
+		}
+		if best == "" {
+			break
+		}
+		top = append(top, best)
+	}
+	if len(top) == 0 {
+		return order[0]
+	}
+	return strings.Join(top, " / ")
+}
+
+// lastSentence returns the final sentence of a paragraph
+func lastSentence(para string) string {
+	sentences := extractSentences(para)
+	if len(sentences) == 0 {
+		return para
+	}
+	return sentences[len(sentences)-1]
+}
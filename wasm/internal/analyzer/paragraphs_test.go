@@ -0,0 +1,61 @@
+package analyzer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAnalyzeParagraphs(t *testing.T) {
+	text := "You are a senior Go reviewer on the payments team.\n\n" +
+		"## Requirements\n\n" +
+		"- Review the refund handler\n- Check error handling in the refund flow\n\n" +
+		"The refund handler retries failed refunds. Each refund retry should be idempotent.\n\n" +
+		"Finally, return the findings as a numbered list."
+
+	got := AnalyzeParagraphs(text)
+	want := []string{RoleIntroduction, RoleHeading, RoleList, RoleBody, RoleConclusion}
+	if !reflect.DeepEqual(got.StructureMap, want) {
+		t.Fatalf("expected structure %v, got %v", want, got.StructureMap)
+	}
+	if !got.HasIntroduction || !got.HasConclusion {
+		t.Errorf("expected an introduction and a conclusion, got %+v", got)
+	}
+
+	body := got.Paragraphs[3]
+	if text[body.Start:body.End] != "The refund handler retries failed refunds. Each refund retry should be idempotent." {
+		t.Errorf("unexpected paragraph span %q", text[body.Start:body.End])
+	}
+	if body.Sentences != 2 || body.Words != 12 || body.Topic != "refund" {
+		t.Errorf("unexpected paragraph metrics %+v", body)
+	}
+	if body.Readability == 0 {
+		t.Error("expected a per-paragraph readability score")
+	}
+}
+
+func TestParagraphStructureFeedsGrade(t *testing.T) {
+	score := func(text string) (intro, conclusion float64) {
+		grade := calculateStructureQuality(IdeaAnalysisMetrics{}, AnalyzeComplexity(text))
+		for _, f := range grade.Factors {
+			switch f.Name {
+			case "Introduction Clarity":
+				intro = f.Value
+			case "Conclusion Clarity":
+				conclusion = f.Value
+			}
+		}
+		return intro, conclusion
+	}
+
+	framed := "You are a data analyst. Load the sales file. Group revenue by region. Respond with a markdown table."
+	if intro, conclusion := score(framed); intro != 90 || conclusion != 90 {
+		t.Errorf("expected a framed prompt to score 90/90, got %v/%v", intro, conclusion)
+	}
+	bare := "Load the sales file. Group revenue by region. Sort the regions."
+	if intro, conclusion := score(bare); intro != 55 || conclusion != 55 {
+		t.Errorf("expected an unframed prompt to score 55/55, got %v/%v", intro, conclusion)
+	}
+	if intro, conclusion := score("Write a poem."); intro != 70 || conclusion != 70 {
+		t.Errorf("expected short prompts to keep the neutral score, got %v/%v", intro, conclusion)
+	}
+}
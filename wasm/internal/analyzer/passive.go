@@ -0,0 +1,189 @@
+package analyzer
+
+import (
+	"regexp"
+	"strings"
+)
+
+// PassiveConstruction is one "be/get + past participle" phrase
+type PassiveConstruction struct {
+	Text       string `json:"text"`
+	Position   int    `json:"position"` // Byte offset in the original text
+	Length     int    `json:"length"`
+	Auxiliary  string `json:"auxiliary"`       // "was", "is being", "got"...
+	Participle string `json:"participle"`      // Past participle, regular or irregular
+	Agent      string `json:"agent,omitempty"` // Noun phrase after "by", when present
+}
+
+// PassiveVoiceAnalysis summarizes passive constructions across the text
+type PassiveVoiceAnalysis struct {
+	Constructions    []PassiveConstruction `json:"constructions"`
+	PassiveSentences int                   `json:"passive_sentences"`
+	TotalSentences   int                   `json:"total_sentences"`
+	Ratio            float64               `json:"ratio"` // Passive sentences / total sentences
+}
+
+// Passive auxiliaries: forms of "be", and "get" for the get-passive ("got deleted")
+var (
+	beForms  = map[string]bool{"am": true, "is": true, "are": true, "was": true, "were": true, "be": true, "been": true, "being": true}
+	getForms = map[string]bool{"get": true, "gets": true, "got": true, "gotten": true, "getting": true}
+)
+
+// irregularParticiples are past participles that don't end in -ed
+var irregularParticiples = map[string]bool{
+	"arisen": true, "awoken": true, "beaten": true, "become": true, "begun": true, "bent": true,
+	"bet": true, "bid": true, "bitten": true, "blown": true, "borne": true, "born": true,
+	"bound": true, "broken": true, "brought": true, "built": true, "burnt": true, "bought": true,
+	"cast": true, "caught": true, "chosen": true, "cost": true, "crept": true, "cut": true,
+	"dealt": true, "done": true, "drawn": true, "dreamt": true, "driven": true, "drunk": true,
+	"dug": true, "eaten": true, "fallen": true, "fed": true, "felt": true, "fought": true,
+	"found": true, "fled": true, "flung": true, "flown": true, "forbidden": true, "forecast": true,
+	"foreseen": true, "forgiven": true, "forgotten": true, "forsaken": true, "frozen": true, "given": true,
+	"ground": true, "grown": true, "hung": true, "heard": true, "hidden": true, "hit": true,
+	"held": true, "hurt": true, "kept": true, "knelt": true, "known": true, "laid": true,
+	"led": true, "left": true, "lent": true, "let": true, "lit": true, "lost": true,
+	"made": true, "meant": true, "met": true, "mistaken": true, "mown": true, "overcome": true,
+	"overdone": true, "overheard": true, "overridden": true, "overrun": true, "overseen": true, "overtaken": true,
+	"overthrown": true, "overwritten": true, "paid": true, "proven": true, "put": true, "quit": true,
+	"read": true, "rebuilt": true, "redone": true, "remade": true, "rewritten": true, "rid": true,
+	"ridden": true, "rung": true, "risen": true, "run": true, "said": true, "seen": true,
+	"sought": true, "sold": true, "sent": true, "set": true, "sewn": true, "shaken": true,
+	"shed": true, "shot": true, "shown": true, "shrunk": true, "shut": true, "slain": true,
+	"slid": true, "slit": true, "sown": true, "spoken": true, "sped": true, "spent": true,
+	"spun": true, "split": true, "spread": true, "sprung": true, "stolen": true, "stuck": true,
+	"stung": true, "struck": true, "strung": true, "sworn": true, "swept": true, "swollen": true,
+	"swung": true, "taken": true, "taught": true, "torn": true, "told": true, "thought": true,
+	"thrown": true, "thrust": true, "trodden": true, "undergone": true, "understood": true, "undertaken": true,
+	"undone": true, "upheld": true, "upset": true, "withdrawn": true, "withheld": true, "woken": true,
+	"won": true, "worn": true, "woven": true, "wound": true, "written": true, "wrung": true,
+}
+
+// edAdjectives end in -ed but after "be" usually describe a state or feeling
+// rather than an action done to the subject ("I am interested")
+var edAdjectives = map[string]bool{
+	"interested": true, "tired": true, "bored": true, "excited": true, "worried": true, "scared": true,
+	"pleased": true, "satisfied": true, "concerned": true, "confused": true, "surprised": true, "amazed": true,
+	"annoyed": true, "ashamed": true, "embarrassed": true, "frustrated": true, "disappointed": true, "relieved": true,
+	"talented": true, "skilled": true, "experienced": true, "qualified": true, "motivated": true, "determined": true,
+	"supposed": true, "used": true, "aged": true, "beloved": true, "crooked": true, "naked": true,
+	"rugged": true, "wicked": true, "sacred": true, "hundred": true, "red": true, "bed": true,
+	"need": true, "seed": true, "feed": true, "speed": true, "indeed": true,
+	"advanced": true, "complicated": true, "sophisticated": true, "dedicated": true, "detailed": true, "limited": true,
+	"related": true, "outdated": true, "unrelated": true, "automated": true, "distributed": true, "varied": true,
+}
+
+// Words that may sit between the auxiliary and the participle
+var passiveFillers = map[string]bool{
+	"not": true, "never": true, "also": true, "already": true, "always": true, "often": true,
+	"still": true, "just": true, "then": true, "now": true, "usually": true, "typically": true,
+	"automatically": true, "currently": true, "only": true, "all": true, "both": true, "each": true,
+}
+
+// intensifiers before an -ed word mark it as an adjective ("is very detailed")
+var intensifiers = map[string]bool{
+	"very": true, "so": true, "too": true, "quite": true, "extremely": true, "really": true,
+	"rather": true, "more": true, "most": true, "less": true, "least": true, "fairly": true, "pretty": true,
+}
+
+var (
+	passiveWordPattern     = regexp.MustCompile(`[A-Za-z]+(?:'[A-Za-z]+)?`)
+	sentenceSpanPattern = regexp.MustCompile(`[^.!?\n]+[.!?]*`)
+	agentWordPattern       = regexp.MustCompile(`^\s+by\s+((?:(?:the|a|an|our|their|your|its|this|that)\s+)?[A-Za-z][\w-]*(?:\s+[A-Za-z][\w-]*)?)`)
+)
+
+// DetectPassiveVoice finds passive constructions: a form of "be" or "get",
+// optionally followed by adverbs or "being", then a past participle. Regular
+// participles are -ed words not known to act as adjectives; irregular ones
+// come from a fixed list. Intensifiers ("is very detailed") and "used to" /
+// "supposed to" rule a match out.
+func DetectPassiveVoice(text string) PassiveVoiceAnalysis {
+	analysis := PassiveVoiceAnalysis{Constructions: []PassiveConstruction{}}
+	words := passiveWordPattern.FindAllStringIndex(text, -1)
+
+	for i := 0; i < len(words); i++ {
+		aux := strings.ToLower(text[words[i][0]:words[i][1]])
+		if !beForms[aux] && !getForms[aux] {
+			continue
+		}
+		auxEnd := i
+		adjectival := false
+		j := i + 1
+		for ; j < len(words) && j <= i+3; j++ {
+			if strings.ContainsAny(text[words[j-1][1]:words[j][0]], ".!?;:\n") {
+				j = len(words) // Don't join words across sentences or clauses
+				break
+			}
+			w := strings.ToLower(text[words[j][0]:words[j][1]])
+			switch {
+			case beForms[w]:
+				auxEnd = j // "is being", "has been"
+				continue
+			case intensifiers[w]:
+				adjectival = true
+				continue
+			case passiveFillers[w] || (strings.HasSuffix(w, "ly") && len(w) > 4):
+				continue
+			}
+			break
+		}
+		if j >= len(words) || adjectival {
+			continue
+		}
+
+		participle := strings.ToLower(text[words[j][0]:words[j][1]])
+		if !isPastParticiple(participle) {
+			continue
+		}
+		rest := text[words[j][1]:]
+		if (participle == "used" || participle == "supposed") && strings.HasPrefix(strings.TrimSpace(rest), "to ") {
+			continue
+		}
+
+		c := PassiveConstruction{
+			Auxiliary:  strings.ToLower(strings.Join(strings.Fields(text[words[i][0]:words[auxEnd][1]]), " ")),
+			Participle: participle,
+			Position:   words[i][0],
+		}
+		end := words[j][1]
+		if m := agentWordPattern.FindStringSubmatchIndex(rest); m != nil {
+			c.Agent = rest[m[2]:m[3]]
+			end += m[1]
+		}
+		c.Text = text[c.Position:end]
+		c.Length = end - c.Position
+		analysis.Constructions = append(analysis.Constructions, c)
+		i = j
+	}
+
+	sentences := sentenceSpanPattern.FindAllStringIndex(text, -1)
+	next := 0
+	for _, s := range sentences {
+		if strings.TrimSpace(text[s[0]:s[1]]) == "" {
+			continue
+		}
+		analysis.TotalSentences++
+		passive := false
+		for next < len(analysis.Constructions) && analysis.Constructions[next].Position < s[1] {
+			passive = passive || analysis.Constructions[next].Position >= s[0]
+			next++
+		}
+		if passive {
+			analysis.PassiveSentences++
+		}
+	}
+	if analysis.TotalSentences > 0 {
+		analysis.Ratio = float64(analysis.PassiveSentences) / float64(analysis.TotalSentences)
+	}
+	return analysis
+}
+
+// isPastParticiple reports whether a lowercase word can be a past participle
+func isPastParticiple(word string) bool {
+	if irregularParticiples[word] {
+		return true
+	}
+	if edAdjectives[word] || len(word) < 4 || !strings.HasSuffix(word, "ed") {
+		return false
+	}
+	return !strings.HasSuffix(word, "eed") // "need", "proceed"
+}
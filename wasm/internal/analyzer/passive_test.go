@@ -0,0 +1,59 @@
+package analyzer
+
+import "testing"
+
+func TestDetectPassiveVoice(t *testing.T) {
+	cases := []struct {
+		text       string
+		participle string // Empty when no passive construction is expected
+		agent      string
+	}{
+		{"The report was written last week.", "written", ""},
+		{"The bridges were built by the city council.", "built", "the city council"},
+		{"The file is being uploaded now.", "uploaded", ""},
+		{"Errors are not always caught.", "caught", ""},
+		{"My account got deleted.", "deleted", ""},
+		{"I am very tired today.", "", ""},
+		{"We are interested in your feedback.", "", ""},
+		{"The plan is more detailed than before.", "", ""},
+		{"You are supposed to reply in JSON.", "", ""},
+		{"The result was. Created later.", "", ""},
+		{"Write the summary in plain English.", "", ""},
+	}
+	for _, tc := range cases {
+		got := DetectPassiveVoice(tc.text)
+		if tc.participle == "" {
+			if len(got.Constructions) != 0 {
+				t.Errorf("%q: expected no passive voice, got %+v", tc.text, got.Constructions)
+			}
+			continue
+		}
+		if len(got.Constructions) != 1 {
+			t.Errorf("%q: expected one passive construction, got %+v", tc.text, got.Constructions)
+			continue
+		}
+		c := got.Constructions[0]
+		if c.Participle != tc.participle || c.Agent != tc.agent {
+			t.Errorf("%q: expected participle %q agent %q, got %+v", tc.text, tc.participle, tc.agent, c)
+		}
+		if tc.text[c.Position:c.Position+c.Length] != c.Text {
+			t.Errorf("%q: span %d+%d does not match %q", tc.text, c.Position, c.Length, c.Text)
+		}
+	}
+}
+
+func TestPassiveVoiceRatio(t *testing.T) {
+	text := "The data was collected by the team. Summarize it. The results were shown to users and were then forgotten. List the risks."
+	got := DetectPassiveVoice(text)
+	if len(got.Constructions) != 3 || got.PassiveSentences != 2 || got.TotalSentences != 4 || got.Ratio != 0.5 {
+		t.Errorf("expected 3 constructions in 2 of 4 sentences, got %+v", got)
+	}
+
+	quality := assessEnhancedQuality(text, defaultSpellChecker(), nil)
+	if quality.PassiveVoiceRatio.Value != 0.5 || len(quality.StyleSuggestions.Value) != 3 {
+		t.Errorf("expected the ratio and suggestions in the quality assessment, got %v and %+v", quality.PassiveVoiceRatio.Value, quality.StyleSuggestions.Value)
+	}
+	if s := quality.StyleSuggestions.Value[0].Suggestion; s != `Consider active voice with "the team" as the subject` {
+		t.Errorf("expected the agent in the suggestion, got %q", s)
+	}
+}
@@ -0,0 +1,371 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// Structured payload formats
+const (
+	PayloadJSON = "json"
+	PayloadYAML = "yaml"
+	PayloadXML  = "xml"
+)
+
+// payloadValidityWeight is the share of Clarity the Payload Validity factor
+// takes when a prompt embeds payloads; the other factors share the rest
+const payloadValidityWeight = 0.15
+
+var (
+	// payloadFencePattern matches a fenced code block and its language tag
+	payloadFencePattern = regexp.MustCompile("(?ms)^[ \t]*```[ \t]*([\\w+-]*)[^\n]*\n(.*?)^[ \t]*```[ \t]*$")
+	// payloadStartPattern matches a line opening an unfenced JSON object or
+	// array: "{" or "[" followed by what JSON puts there, so "[Optional]"
+	// and "{name}" placeholders don't count
+	payloadStartPattern = regexp.MustCompile(`(?m)^[ \t]*(?:\{[ \t]*(?:"|\}|\r?\n)|\[[ \t]*(?:["{\[\]\d-]|\r?\n))`)
+	// xmlPrologPattern matches the declaration opening an unfenced XML document
+	xmlPrologPattern = regexp.MustCompile(`(?m)^[ \t]*<\?xml\b`)
+)
+
+// payloadFenceFormats maps code fence language tags to payload formats
+var payloadFenceFormats = map[string]string{
+	"json": PayloadJSON, "jsonc": PayloadJSON, "json5": PayloadJSON,
+	"yaml": PayloadYAML, "yml": PayloadYAML,
+	"xml": PayloadXML,
+}
+
+// StructuredPayload is a JSON, YAML or XML block embedded in a prompt, such
+// as an example input or the shape of the expected output
+type StructuredPayload struct {
+	Format    string `json:"format"` // One of the Payload* values
+	Span      Span   `json:"span"`   // The payload, without its code fence
+	Fenced    bool   `json:"fenced"`
+	Valid     bool   `json:"valid"`
+	Error     string `json:"error,omitempty"`
+	ErrorSpan *Span  `json:"error_span,omitempty"` // The line the parser stopped at
+
+	block [2]int // Offsets of the whole block, fence included
+}
+
+// PayloadAnalysis is every structured payload in a prompt, behind the
+// Payload Validity factor of Clarity
+type PayloadAnalysis struct {
+	Payloads []StructuredPayload `json:"payloads"`
+	Valid    int                 `json:"valid"`
+	Invalid  int                 `json:"invalid"`
+	Score    float64             `json:"score"` // Percent valid; 100 without payloads
+}
+
+// AnalyzePayloads finds and validates the structured payloads in text.
+// Fenced blocks tagged json, yaml or xml count, as do untagged fences that
+// open like JSON or XML. Outside fences, a line opening a
+// JSON object or array counts up to its closing bracket, or to the end of
+// its paragraph when it never closes, as does an XML document opening with
+// "<?xml". XML tags used as prompt delimiters ("<context>") do not count.
+func AnalyzePayloads(text string) PayloadAnalysis {
+	analysis := PayloadAnalysis{Payloads: []StructuredPayload{}, Score: 100}
+	var fences [][2]int
+	for _, m := range payloadFencePattern.FindAllStringSubmatchIndex(text, -1) {
+		fences = append(fences, [2]int{m[0], m[1]})
+		body := text[m[4]:m[5]]
+		format, ok := payloadFenceFormats[strings.ToLower(text[m[2]:m[3]])]
+		if !ok && m[3] == m[2] {
+			format, ok = sniffPayload(body)
+		}
+		if ok {
+			analysis.add(validatePayload(text, format, m[4], m[5], true, [2]int{m[0], m[1]}))
+		}
+	}
+	inFence := func(pos int) bool {
+		for _, f := range fences {
+			if pos >= f[0] && pos < f[1] {
+				return true
+			}
+		}
+		return false
+	}
+
+	covered := 0 // End of the last unfenced payload, so nested brackets aren't read twice
+	for _, m := range payloadStartPattern.FindAllStringIndex(text, -1) {
+		start := m[0] + len(text[m[0]:m[1]]) - len(strings.TrimLeft(text[m[0]:m[1]], " \t"))
+		if start < covered || inFence(start) {
+			continue
+		}
+		end := jsonBlockEnd(text, start)
+		analysis.add(validatePayload(text, PayloadJSON, start, end, false, [2]int{start, end}))
+		covered = end
+	}
+	for _, m := range xmlPrologPattern.FindAllStringIndex(text, -1) {
+		start := m[1] - len("<?xml")
+		if start < covered || inFence(start) {
+			continue
+		}
+		end := paragraphEnd(text, start)
+		analysis.add(validatePayload(text, PayloadXML, start, end, false, [2]int{start, end}))
+	}
+	sortPayloads(analysis.Payloads)
+
+	if total := analysis.Valid + analysis.Invalid; total > 0 {
+		analysis.Score = float64(analysis.Valid) / float64(total) * 100
+	}
+	return analysis
+}
+
+// add records a payload and counts it
+func (a *PayloadAnalysis) add(p StructuredPayload) {
+	a.Payloads = append(a.Payloads, p)
+	if p.Valid {
+		a.Valid++
+	} else {
+		a.Invalid++
+	}
+}
+
+// sortPayloads orders payloads by position
+func sortPayloads(payloads []StructuredPayload) {
+	for i := 1; i < len(payloads); i++ {
+		for j := i; j > 0 && payloads[j].block[0] < payloads[j-1].block[0]; j-- {
+			payloads[j], payloads[j-1] = payloads[j-1], payloads[j]
+		}
+	}
+}
+
+// sniffPayload guesses the format of an untagged code block from how it opens
+func sniffPayload(body string) (string, bool) {
+	trimmed := strings.TrimSpace(body)
+	switch {
+	case strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "["):
+		return PayloadJSON, true
+	case strings.HasPrefix(trimmed, "<?xml") || (strings.HasPrefix(trimmed, "<") && strings.HasSuffix(trimmed, ">")):
+		return PayloadXML, true
+	}
+	return "", false
+}
+
+// jsonBlockEnd returns the offset just past the bracket closing the JSON
+// value opened at start, skipping brackets inside strings. A value that
+// never closes runs to the end of its paragraph.
+func jsonBlockEnd(text string, start int) int {
+	depth, inString, escaped := 0, false, false
+	for i := start; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case inString:
+			if escaped {
+				escaped = false
+			} else if c == '\\' {
+				escaped = true
+			} else if c == '"' || c == '\n' {
+				inString = false
+			}
+		case c == '"':
+			inString = true
+		case c == '{' || c == '[':
+			depth++
+		case c == '}' || c == ']':
+			if depth--; depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return paragraphEnd(text, start)
+}
+
+// paragraphEnd returns the offset of the blank line ending the paragraph at
+// start, or the end of text
+func paragraphEnd(text string, start int) int {
+	if loc := paragraphBreakPattern.FindStringIndex(text[start:]); loc != nil {
+		return start + loc[0]
+	}
+	return len(strings.TrimRight(text, " \t\r\n"))
+}
+
+// validatePayload parses text[start:end] in format
+func validatePayload(text, format string, start, end int, fenced bool, block [2]int) StructuredPayload {
+	p := StructuredPayload{Format: format, Span: trimmedSpan(text, start, end), Fenced: fenced, Valid: true, block: block}
+	src := text[start:end]
+	var offset int
+	var err error
+	switch format {
+	case PayloadJSON:
+		offset, err = validateJSON(src)
+	case PayloadYAML:
+		offset, err = validateYAML(src)
+	case PayloadXML:
+		offset, err = validateXML(src)
+	}
+	if err != nil {
+		p.Valid = false
+		p.Error = err.Error()
+		line := lineSpan(text, start+min(max(offset, 0), len(src)))
+		p.ErrorSpan = &line
+	}
+	return p
+}
+
+// lineSpan returns the line of text containing pos, trimmed; at the end of
+// text, the last line with content
+func lineSpan(text string, pos int) Span {
+	if pos >= len(text) || (pos > 0 && strings.TrimSpace(text[pos:]) == "") {
+		pos = len(strings.TrimRight(text, " \t\r\n")) - 1
+	}
+	pos = max(pos, 0)
+	start := strings.LastIndexByte(text[:pos], '\n') + 1
+	end := strings.IndexByte(text[pos:], '\n')
+	if end < 0 {
+		end = len(text)
+	} else {
+		end += pos
+	}
+	return trimmedSpan(text, start, end)
+}
+
+// validateJSON parses src as one JSON value, returning where it failed
+func validateJSON(src string) (int, error) {
+	var v interface{}
+	err := json.Unmarshal([]byte(src), &v)
+	if err == nil {
+		return 0, nil
+	}
+	var syntax *json.SyntaxError
+	if errors.As(err, &syntax) {
+		return int(syntax.Offset) - 1, fmt.Errorf("%v; %s", err, jsonHint(err.Error()))
+	}
+	return len(src), err
+}
+
+// jsonHint names the usual cause of a JSON syntax error in a prompt
+func jsonHint(msg string) string {
+	switch {
+	case strings.Contains(msg, "looking for beginning of object key string"):
+		return "keys need double quotes, and no comma may precede }"
+	case strings.Contains(msg, "looking for beginning of value"):
+		return "values need to be JSON: no trailing commas, single quotes, comments or ... placeholders"
+	case strings.Contains(msg, "unexpected end of JSON input"):
+		return "close every { and ["
+	case strings.Contains(msg, "after object key"):
+		return "a colon must follow each key"
+	}
+	return "check the quotes, commas and brackets around it"
+}
+
+// validateXML parses src as XML with at least one element, returning
+// where it failed
+func validateXML(src string) (int, error) {
+	d := xml.NewDecoder(strings.NewReader(src))
+	elements := 0
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return int(d.InputOffset()), err
+		}
+		if _, ok := tok.(xml.StartElement); ok {
+			elements++
+		}
+	}
+	if elements == 0 {
+		return 0, errors.New("no XML element")
+	}
+	return 0, nil
+}
+
+var (
+	yamlKeyPattern      = regexp.MustCompile(`^(?:"[^"]*"|'[^']*'|[^\s#'"\[\]{},][^#]*?)[ \t]*:(?:[ \t]+(.*)|$)`)
+	yamlDocumentMarkers = map[string]bool{"---": true, "...": true}
+)
+
+// validateYAML checks src against the YAML mistakes prompts make: tab
+// indentation, unclosed quotes, unbalanced flow brackets, a mapping indented
+// under a key that already has a value, and top-level lines that are neither
+// keys nor list items. It is not a full YAML parser.
+func validateYAML(src string) (int, error) {
+	type entry struct {
+		indent int
+		scalar bool // The key has an inline value
+		block  bool // The value is a | or > block scalar
+	}
+	var last *entry
+	flowDepth, offset := 0, 0
+	for n, line := range strings.SplitAfter(src, "\n") {
+		lineStart := offset
+		offset += len(line)
+		line = strings.TrimRight(line, "\r\n")
+		content := strings.TrimLeft(line, " \t")
+		if content == "" || strings.HasPrefix(content, "#") || yamlDocumentMarkers[strings.TrimSpace(content)] {
+			continue
+		}
+		indent := len(line) - len(content)
+		if last != nil && last.block && indent > last.indent {
+			continue
+		}
+		if strings.Contains(line[:indent], "\t") {
+			return lineStart, fmt.Errorf("line %d: tabs are not allowed in YAML indentation", n+1)
+		}
+		if flowDepth > 0 {
+			flowDepth += strings.Count(content, "[") + strings.Count(content, "{") - strings.Count(content, "]") - strings.Count(content, "}")
+			continue
+		}
+		item := false
+		for strings.HasPrefix(content, "- ") || content == "-" {
+			content = strings.TrimLeft(strings.TrimPrefix(content, "-"), " ")
+			indent, item = len(line)-len(content), true
+			if last != nil {
+				last.scalar = false // A list item is a new node
+			}
+		}
+
+		m := yamlKeyPattern.FindStringSubmatch(content)
+		value := content
+		if m != nil {
+			if last != nil && last.scalar && indent > last.indent {
+				return lineStart, fmt.Errorf("line %d: mapping indented under a key that already has a value", n+1)
+			}
+			value = strings.TrimSpace(m[1])
+			if i := strings.Index(value, " #"); i >= 0 {
+				value = strings.TrimSpace(value[:i])
+			}
+			last = &entry{indent: indent, scalar: value != "" && !strings.HasPrefix(value, "&"),
+				block: strings.HasPrefix(value, "|") || strings.HasPrefix(value, ">")}
+		} else if indent == 0 && !item && last != nil && !strings.HasPrefix(content, "[") && !strings.HasPrefix(content, "{") {
+			return lineStart, fmt.Errorf("line %d: expected \"key: value\" or a \"- \" list item", n+1)
+		}
+
+		if q := value; strings.HasPrefix(q, `"`) || strings.HasPrefix(q, "'") {
+			if !strings.HasSuffix(q, q[:1]) || len(q) == 1 {
+				return lineStart, fmt.Errorf("line %d: unclosed %s quote", n+1, q[:1])
+			}
+		}
+		if strings.HasPrefix(value, "[") || strings.HasPrefix(value, "{") {
+			flowDepth = strings.Count(value, "[") + strings.Count(value, "{") - strings.Count(value, "]") - strings.Count(value, "}")
+		}
+	}
+	if flowDepth > 0 {
+		return len(src), errors.New("unclosed [ or { at the end of the YAML")
+	}
+	return 0, nil
+}
+
+// maskPayloads blanks the payloads of text, code fences included, keeping
+// newlines so offsets and line breaks stay where they were
+func maskPayloads(text string, payloads []StructuredPayload) string {
+	if len(payloads) == 0 {
+		return text
+	}
+	b := []byte(text)
+	for _, p := range payloads {
+		for i := p.block[0]; i < p.block[1]; i++ {
+			if b[i] != '\n' {
+				b[i] = ' '
+			}
+		}
+	}
+	return string(b)
+}
@@ -0,0 +1,122 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAnalyzePayloadsFencedAndInline(t *testing.T) {
+	text := "Reply with JSON shaped like:\n```json\n{\"name\": \"Ada\", \"tags\": [\"a\",]}\n```\n\n" +
+		"The request looks like this:\n{\n  \"id\": 7,\n  \"items\": [1, 2]\n}\n\n" +
+		"Settings:\n```yaml\nretries: 3\nbackoff:\n  - 1s\n  - 5s\n```"
+	analysis := AnalyzePayloads(text)
+
+	if len(analysis.Payloads) != 3 || analysis.Valid != 2 || analysis.Invalid != 1 {
+		t.Fatalf("expected two valid payloads and one invalid, got %+v", analysis)
+	}
+	bad := analysis.Payloads[0]
+	if bad.Format != PayloadJSON || !bad.Fenced || bad.Valid || !strings.Contains(bad.Error, "trailing commas") {
+		t.Errorf("unexpected fenced payload %+v", bad)
+	}
+	if bad.ErrorSpan == nil || bad.ErrorSpan.Text != `{"name": "Ada", "tags": ["a",]}` {
+		t.Errorf("expected the error on the payload's line, got %+v", bad.ErrorSpan)
+	}
+	inline := analysis.Payloads[1]
+	if inline.Fenced || !inline.Valid || inline.Span.Text != "{\n  \"id\": 7,\n  \"items\": [1, 2]\n}" {
+		t.Errorf("unexpected inline payload %+v", inline)
+	}
+	if text[inline.Span.Start:inline.Span.End] != inline.Span.Text {
+		t.Errorf("inline span does not point into the text")
+	}
+	if analysis.Payloads[2].Format != PayloadYAML || !analysis.Payloads[2].Valid {
+		t.Errorf("expected valid YAML, got %+v", analysis.Payloads[2])
+	}
+	if analysis.Score < 66 || analysis.Score > 67 {
+		t.Errorf("expected a score of two thirds, got %.1f", analysis.Score)
+	}
+}
+
+func TestAnalyzePayloadsIgnoresDelimitersAndPlaceholders(t *testing.T) {
+	text := "<context>\nThe user is on the free plan.\n</context>\n\n[Optional] Greet {name} by name.\n{placeholder}"
+	if analysis := AnalyzePayloads(text); len(analysis.Payloads) != 0 || analysis.Score != 100 {
+		t.Errorf("expected no payloads, got %+v", analysis.Payloads)
+	}
+}
+
+func TestAnalyzePayloadsYAMLAndXMLErrors(t *testing.T) {
+	cases := []struct {
+		name, text, format, err string
+	}{
+		{"indented under a value", "```yaml\nname: report\n  format: pdf\n```", PayloadYAML, "already has a value"},
+		{"tab indentation", "```yml\nsteps:\n\t- lint\n```", PayloadYAML, "tabs"},
+		{"unclosed quote", "```yaml\ntitle: \"Q3 review\n```", PayloadYAML, "unclosed"},
+		{"mismatched tag", "```xml\n<order><item></order>\n```", PayloadXML, "closed by"},
+		{"unclosed object", "Send:\n{\"id\": 1, \"tags\": [\"a\"\n\nThanks.", PayloadJSON, "close every"},
+	}
+	for _, c := range cases {
+		analysis := AnalyzePayloads(c.text)
+		if len(analysis.Payloads) != 1 {
+			t.Errorf("%s: expected one payload, got %+v", c.name, analysis.Payloads)
+			continue
+		}
+		p := analysis.Payloads[0]
+		if p.Format != c.format || p.Valid || !strings.Contains(p.Error, c.err) {
+			t.Errorf("%s: unexpected payload %+v", c.name, p)
+		}
+	}
+
+	valid := "```yaml\nname: report\ndescription: |\n  key: not a key\n    indented\nlimits: {cpu: 2,\n  memory: 4Gi}\n```"
+	if p := AnalyzePayloads(valid).Payloads[0]; !p.Valid {
+		t.Errorf("expected block scalars and multi-line flow to be valid, got %q", p.Error)
+	}
+}
+
+func TestPayloadsLeftOutOfReadability(t *testing.T) {
+	prose := "Summarize the order below. Keep it short."
+	payload := "\n\n```json\n{\"order_identifier\": \"A-100\", \"customer_classification\": \"enterprise\", \"fulfillment_organization\": \"international\"}\n```"
+
+	plain := AnalyzeComplexity(prose)
+	embedded := AnalyzeComplexity(prose + payload)
+	if embedded.SentenceStats.TotalSentences.Value != plain.SentenceStats.TotalSentences.Value {
+		t.Errorf("expected the payload to add no sentences, got %d and %d", embedded.SentenceStats.TotalSentences.Value, plain.SentenceStats.TotalSentences.Value)
+	}
+	if embedded.FleschReadingEase.Value != plain.FleschReadingEase.Value {
+		t.Errorf("expected the payload not to change reading ease, got %.2f and %.2f", embedded.FleschReadingEase.Value, plain.FleschReadingEase.Value)
+	}
+	if embedded.ReadingTime.Value <= plain.ReadingTime.Value {
+		t.Errorf("expected the payload to count toward reading time")
+	}
+}
+
+func TestPayloadValidityFactor(t *testing.T) {
+	text := "Extract the invoice fields and return them as JSON in this shape:\n```json\n{'total': 12.5, \"currency\": \"EUR\"}\n```"
+	grade := GradePromptText(text)
+
+	var factor *Factor
+	for i, f := range grade.Clarity.Factors {
+		if f.Name == "Payload Validity" {
+			factor = &grade.Clarity.Factors[i]
+		}
+	}
+	if factor == nil || factor.Value != 0 || factor.Weight != payloadValidityWeight || len(factor.Spans) != 1 {
+		t.Fatalf("expected a zero Payload Validity factor over the payload, got %+v", factor)
+	}
+	found := false
+	for _, s := range grade.Suggestions {
+		if s.Rule == "FUL025" {
+			found = true
+			if s.Message != "Fix the malformed JSON payload" || !strings.Contains(s.Example, "double quotes") {
+				t.Errorf("unexpected FUL025 suggestion %+v", s)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("expected a FUL025 suggestion, got %+v", grade.Suggestions)
+	}
+
+	for _, f := range GradePromptText("Write a haiku about autumn leaves.").Clarity.Factors {
+		if f.Name == "Payload Validity" {
+			t.Errorf("expected no Payload Validity factor without payloads")
+		}
+	}
+}
@@ -0,0 +1,290 @@
+package analyzer
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+// A4 page geometry in PDF points
+const (
+	pdfPageWidth  = 595.0
+	pdfPageHeight = 842.0
+	pdfMargin     = 50.0
+)
+
+// PDF font resource names
+const (
+	pdfRegular = "F1"
+	pdfBold    = "F2"
+	pdfItalic  = "F3"
+)
+
+var pdfFonts = []struct{ resource, base string }{
+	{pdfRegular, "Helvetica"},
+	{pdfBold, "Helvetica-Bold"},
+	{pdfItalic, "Helvetica-Oblique"},
+}
+
+// helveticaWidths are the Helvetica advance widths (1/1000 em) for ASCII 32-126
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+// winAnsi maps the non-Latin-1 characters the reports use to WinAnsiEncoding bytes
+var winAnsi = map[rune]byte{
+	'•': 0x95, '–': 0x96, '—': 0x97, '‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94, '…': 0x85,
+}
+
+// pdfColor is an RGB color with components in 0-1
+type pdfColor struct{ r, g, b float64 }
+
+// parsePDFColor reads a "#RRGGBB" color, falling back to grey
+func parsePDFColor(hex string) pdfColor {
+	var r, g, b int
+	if _, err := fmt.Sscanf(strings.TrimPrefix(hex, "#"), "%02x%02x%02x", &r, &g, &b); err != nil {
+		return pdfColor{0.6, 0.6, 0.6}
+	}
+	return pdfColor{float64(r) / 255, float64(g) / 255, float64(b) / 255}
+}
+
+// pdfDocument builds a PDF 1.4 file page by page using the standard Helvetica
+// fonts, so no font data needs embedding. Coordinates passed to its drawing
+// methods are measured from the top-left corner of the page.
+type pdfDocument struct {
+	title string
+	pages []*bytes.Buffer
+	page  *bytes.Buffer
+	y     float64 // Layout cursor from the top of the current page
+}
+
+func newPDFDocument(title string) *pdfDocument {
+	doc := &pdfDocument{title: title}
+	doc.newPage()
+	return doc
+}
+
+// newPage starts a page and resets the cursor to the top margin
+func (d *pdfDocument) newPage() {
+	d.page = &bytes.Buffer{}
+	d.pages = append(d.pages, d.page)
+	d.y = pdfMargin
+}
+
+// ensureSpace starts a new page unless height fits above the bottom margin
+func (d *pdfDocument) ensureSpace(height float64) {
+	if d.y+height > pdfPageHeight-pdfMargin {
+		d.newPage()
+	}
+}
+
+// contentWidth is the usable width between the margins
+func (d *pdfDocument) contentWidth() float64 {
+	return pdfPageWidth - 2*pdfMargin
+}
+
+// text draws s with its baseline at (x, y)
+func (d *pdfDocument) text(x, y, size float64, font string, color pdfColor, s string) {
+	fmt.Fprintf(d.page, "BT /%s %.1f Tf %.3f %.3f %.3f rg %.2f %.2f Td (%s) Tj ET\n",
+		font, size, color.r, color.g, color.b, x, pdfPageHeight-y, pdfEscape(s))
+}
+
+// rect fills a rectangle whose top-left corner is (x, y)
+func (d *pdfDocument) rect(x, y, w, h float64, fill pdfColor) {
+	fmt.Fprintf(d.page, "%.3f %.3f %.3f rg %.2f %.2f %.2f %.2f re f\n",
+		fill.r, fill.g, fill.b, x, pdfPageHeight-y-h, w, h)
+}
+
+// strokeRect outlines a rectangle whose top-left corner is (x, y)
+func (d *pdfDocument) strokeRect(x, y, w, h, width float64, stroke pdfColor) {
+	fmt.Fprintf(d.page, "%.3f %.3f %.3f RG %.2f w %.2f %.2f %.2f %.2f re S\n",
+		stroke.r, stroke.g, stroke.b, width, x, pdfPageHeight-y-h, w, h)
+}
+
+// line strokes a straight line, dashed when dashed is set
+func (d *pdfDocument) line(x1, y1, x2, y2, width float64, stroke pdfColor, dashed bool) {
+	dash := "[] 0 d"
+	if dashed {
+		dash = "[3 3] 0 d"
+	}
+	fmt.Fprintf(d.page, "%s %.3f %.3f %.3f RG %.2f w %.2f %.2f m %.2f %.2f l S [] 0 d\n",
+		dash, stroke.r, stroke.g, stroke.b, width, x1, pdfPageHeight-y1, x2, pdfPageHeight-y2)
+}
+
+// polygon draws a closed path, filled and/or stroked
+func (d *pdfDocument) polygon(points [][2]float64, fill *pdfColor, stroke *pdfColor, width float64) {
+	if len(points) == 0 {
+		return
+	}
+	var path strings.Builder
+	for i, p := range points {
+		op := "l"
+		if i == 0 {
+			op = "m"
+		}
+		fmt.Fprintf(&path, "%.2f %.2f %s ", p[0], pdfPageHeight-p[1], op)
+	}
+	path.WriteString("h ")
+	switch {
+	case fill != nil && stroke != nil:
+		fmt.Fprintf(d.page, "%.3f %.3f %.3f rg %.3f %.3f %.3f RG %.2f w %sB\n",
+			fill.r, fill.g, fill.b, stroke.r, stroke.g, stroke.b, width, path.String())
+	case fill != nil:
+		fmt.Fprintf(d.page, "%.3f %.3f %.3f rg %sf\n", fill.r, fill.g, fill.b, path.String())
+	case stroke != nil:
+		fmt.Fprintf(d.page, "%.3f %.3f %.3f RG %.2f w %sS\n", stroke.r, stroke.g, stroke.b, width, path.String())
+	}
+}
+
+// arrow draws a line ending in a filled arrowhead at (x2, y2)
+func (d *pdfDocument) arrow(x1, y1, x2, y2, width float64, color pdfColor, dashed bool) {
+	length := math.Hypot(x2-x1, y2-y1)
+	if length == 0 {
+		return
+	}
+	ux, uy := (x2-x1)/length, (y2-y1)/length
+	const head = 6.0
+	bx, by := x2-ux*head, y2-uy*head
+	d.line(x1, y1, bx, by, width, color, dashed)
+	d.polygon([][2]float64{
+		{x2, y2},
+		{bx - uy*head/2, by + ux*head/2},
+		{bx + uy*head/2, by - ux*head/2},
+	}, &color, nil, 0)
+}
+
+// save and restore bracket a transformed group of drawing operations
+func (d *pdfDocument) save()    { d.page.WriteString("q\n") }
+func (d *pdfDocument) restore() { d.page.WriteString("Q\n") }
+
+// scaleAbout scales later drawing by factor around the top-left point (x, y)
+func (d *pdfDocument) scaleAbout(x, y, factor float64) {
+	py := pdfPageHeight - y
+	fmt.Fprintf(d.page, "%.4f 0 0 %.4f %.2f %.2f cm\n", factor, factor, x-x*factor, py-py*factor)
+}
+
+// textWidth measures s in points
+func textWidth(s string, size float64, font string) float64 {
+	units := 0
+	for _, r := range s {
+		if r >= 32 && r <= 126 {
+			units += helveticaWidths[r-32]
+		} else {
+			units += 556
+		}
+	}
+	width := float64(units) * size / 1000
+	if font == pdfBold {
+		width *= 1.06 // Helvetica-Bold runs about 6% wider
+	}
+	return width
+}
+
+// wrapText splits s into lines no wider than width
+func wrapText(s string, size, width float64, font string) []string {
+	lines := []string{}
+	current := ""
+	for _, word := range strings.Fields(s) {
+		candidate := word
+		if current != "" {
+			candidate = current + " " + word
+		}
+		if current != "" && textWidth(candidate, size, font) > width {
+			lines = append(lines, current)
+			candidate = word
+		}
+		current = candidate
+	}
+	if current != "" {
+		lines = append(lines, current)
+	}
+	return lines
+}
+
+// truncateText shortens s with an ellipsis to fit width
+func truncateText(s string, size, width float64, font string) string {
+	if textWidth(s, size, font) <= width {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && textWidth(string(runes)+"…", size, font) > width {
+		runes = runes[:len(runes)-1]
+	}
+	return strings.TrimSpace(string(runes)) + "…"
+}
+
+// pdfEscape encodes s as WinAnsi bytes for a PDF string literal
+func pdfEscape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r >= 32 && r <= 126:
+			b.WriteRune(r)
+		case r >= 0xA0 && r <= 0xFF:
+			fmt.Fprintf(&b, "\\%03o", r)
+		case winAnsi[r] != 0:
+			fmt.Fprintf(&b, "\\%03o", winAnsi[r])
+		case r == '\t' || r == '\n':
+			b.WriteByte(' ')
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
+
+// WriteTo serializes the document with a cross-reference table
+func (d *pdfDocument) WriteTo(w io.Writer) (int64, error) {
+	var out bytes.Buffer
+	offsets := []int{}
+	object := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	out.WriteString("%PDF-1.4\n%\xE2\xE3\xCF\xD3\n")
+
+	// Objects 1-2 are the catalog and page tree, then fonts, then each page and its content
+	firstPage := 3 + len(pdfFonts)
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+
+	fontRefs := []string{}
+	for i, f := range pdfFonts {
+		object(fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", f.base))
+		fontRefs = append(fontRefs, fmt.Sprintf("/%s %d 0 R", f.resource, 3+i))
+	}
+	resources := fmt.Sprintf("<< /Font << %s >> >>", strings.Join(fontRefs, " "))
+
+	for i, content := range d.pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] /Resources %s /Contents %d 0 R >>",
+			pdfPageWidth, pdfPageHeight, resources, firstPage+2*i+1))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()))
+	}
+	object(fmt.Sprintf("<< /Title (%s) /Producer (fulcrum) >>", pdfEscape(d.title)))
+	info := len(offsets)
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, info, xref)
+
+	n, err := w.Write(out.Bytes())
+	return int64(n), err
+}
@@ -0,0 +1,208 @@
+package analyzer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Input sizes CheckBudgets and the stage benchmarks run, by sentence count
+var BudgetSizes = []BudgetSize{
+	{Name: "small", Sentences: 5},
+	{Name: "medium", Sentences: 50},
+	{Name: "large", Sentences: 200},
+}
+
+// budgetRuns is how many times CheckBudgets analyzes each input; the fastest
+// run counts, so one slow run on a busy machine does not fail a check
+const budgetRuns = 3
+
+// BudgetSize names one generated input size
+type BudgetSize struct {
+	Name      string
+	Sentences int
+}
+
+// PerformanceBudget caps how long each analysis stage may take. Stage names are
+// those in performance_metrics: complexity_analysis, tokenization,
+// preprocessing, idea_analysis, task_graph_extraction, insight_generation and
+// prompt_grade_calculation.
+type PerformanceBudget struct {
+	Stages map[string]StageBudget `json:"stages"`
+}
+
+// StageBudget limits one stage's time per input size and how fast it may grow
+type StageBudget struct {
+	// LimitsMs maps an input size name to the most milliseconds the stage may take
+	LimitsMs map[string]float64 `json:"limits_ms"`
+
+	// MaxGrowth caps the exponent k in time ∝ sentences^k between the medium
+	// and large inputs, which holds whatever the speed of the machine: about
+	// 1 is linear and 2 quadratic, so 1.5 catches an accidental O(n²) pass in
+	// a linear stage. 0 disables the check.
+	MaxGrowth float64 `json:"max_growth"`
+}
+
+// BudgetViolation is one stage that ran over its budget
+type BudgetViolation struct {
+	Stage  string  `json:"stage"`
+	Size   string  `json:"size"`
+	Limit  float64 `json:"limit"`
+	Actual float64 `json:"actual"`
+	Reason string  `json:"reason"`
+}
+
+func (v BudgetViolation) String() string {
+	return fmt.Sprintf("%s (%s): %s", v.Stage, v.Size, v.Reason)
+}
+
+// LoadPerformanceBudget parses a JSON budget, rejecting unknown fields so typos are caught early
+func LoadPerformanceBudget(data []byte) (PerformanceBudget, error) {
+	var b PerformanceBudget
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&b); err != nil {
+		return PerformanceBudget{}, fmt.Errorf("invalid performance budget: %w", err)
+	}
+	if err := b.Validate(); err != nil {
+		return PerformanceBudget{}, err
+	}
+	return b, nil
+}
+
+// Validate rejects unknown sizes, non-positive limits and negative growth
+func (b PerformanceBudget) Validate() error {
+	for _, stage := range sortedKeys(b.Stages) {
+		sb := b.Stages[stage]
+		if sb.MaxGrowth < 0 {
+			return fmt.Errorf("stage %s: max_growth must not be negative, got %g", stage, sb.MaxGrowth)
+		}
+		for _, size := range sortedKeys(sb.LimitsMs) {
+			if _, ok := budgetSize(size); !ok {
+				return fmt.Errorf("stage %s: unknown input size %q", stage, size)
+			}
+			if sb.LimitsMs[size] <= 0 {
+				return fmt.Errorf("stage %s: %s limit must be positive", stage, size)
+			}
+		}
+	}
+	return nil
+}
+
+// CheckBudgets analyzes a generated input of each size and reports every stage
+// that ran over a limit or grew faster than its MaxGrowth allows. Stages the
+// budget does not name are timed but never fail.
+func CheckBudgets(b PerformanceBudget) []BudgetViolation {
+	timings := map[string]map[string]float64{} // Size to stage to fastest ms
+	for _, size := range BudgetSizes {
+		timings[size.Name] = StageTimings(BudgetInput(size.Sentences), budgetRuns)
+	}
+	medium, large := BudgetSizes[len(BudgetSizes)-2], BudgetSizes[len(BudgetSizes)-1]
+
+	violations := []BudgetViolation{}
+	for _, stage := range sortedKeys(b.Stages) {
+		sb := b.Stages[stage]
+		for _, size := range BudgetSizes {
+			limit, ok := sb.LimitsMs[size.Name]
+			if actual := timings[size.Name][stage]; ok && actual > limit {
+				violations = append(violations, BudgetViolation{
+					Stage: stage, Size: size.Name, Limit: limit, Actual: actual,
+					Reason: fmt.Sprintf("took %.2fms, budget is %.2fms", actual, limit),
+				})
+			}
+		}
+		if sb.MaxGrowth == 0 {
+			continue
+		}
+		growth, ok := budgetGrowth(timings[medium.Name][stage], timings[large.Name][stage], medium.Sentences, large.Sentences)
+		if ok && growth > sb.MaxGrowth {
+			violations = append(violations, BudgetViolation{
+				Stage: stage, Size: large.Name, Limit: sb.MaxGrowth, Actual: growth,
+				Reason: fmt.Sprintf("time grows as sentences^%.2f from %s to %s, budget is ^%.2f", growth, medium.Name, large.Name, sb.MaxGrowth),
+			})
+		}
+	}
+	return violations
+}
+
+// budgetGrowth is the exponent k with t2/t1 = (n2/n1)^k. Stages faster than a
+// millisecond are skipped, since their timings are mostly noise.
+func budgetGrowth(t1, t2 float64, n1, n2 int) (float64, bool) {
+	if t1 <= 0 || t2 < 1 || n2 <= n1 {
+		return 0, false
+	}
+	return math.Round(math.Log(t2/t1)/math.Log(float64(n2)/float64(n1))*100) / 100, true
+}
+
+// StageTimings analyzes text runs times on one worker, so stages do not compete
+// for the CPU, and returns each stage's fastest time in milliseconds
+func StageTimings(text string, runs int) map[string]float64 {
+	fastest := map[string]float64{}
+	for i := 0; i < runs; i++ {
+		result, err := Analyze(context.Background(), text, AnalysisOptions{}, AnalysisRun{RequestID: "budget", Workers: 1})
+		if err != nil {
+			continue
+		}
+		for _, s := range result.Performance.stageDurations() {
+			if ms, ok := fastest[s.name]; !ok || s.metric.Value < ms {
+				fastest[s.name] = s.metric.Value
+			}
+		}
+	}
+	return fastest
+}
+
+// budgetSize looks up an input size by name
+func budgetSize(name string) (BudgetSize, bool) {
+	for _, s := range BudgetSizes {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return BudgetSize{}, false
+}
+
+// budgetSentences are varied so generated inputs cluster, grade and extract
+// tasks the way real prompts do rather than collapsing into one idea. {a} and
+// {b} become nouns and {n} a number.
+var budgetSentences = []string{
+	"The {a} team needs to migrate the {b} service before the next release.",
+	"Please review the {a} design and fix the {b} regression by Friday.",
+	"Research shows {n}% of users abandon the {a} flow within a week.",
+	"Why does the {a} job time out when the {b} queue is full?",
+	"For example, the {a} dashboard shows {n} errors per hour on the {b} cluster.",
+	"I think we should cache the {a} results; however, the {b} owners disagree.",
+	"Create a runbook for the {a} outage and share it with the {b} rotation.",
+	"The {a} index stores {n} million rows and grows every night.",
+}
+
+var budgetNouns = []string{
+	"billing", "search", "onboarding", "payments", "analytics", "gateway", "export",
+	"checkout", "reporting", "identity", "storage", "notification", "scheduler",
+}
+
+// BudgetInput generates a prompt of the given number of sentences, with a
+// heading and paragraph break every eight sentences
+func BudgetInput(sentences int) string {
+	var b strings.Builder
+	for i := 0; i < sentences; i++ {
+		if i%8 == 0 {
+			if i > 0 {
+				b.WriteString("\n\n")
+			}
+			fmt.Fprintf(&b, "## Part %d\n\n", i/8+1)
+		} else {
+			b.WriteString(" ")
+		}
+		fill := strings.NewReplacer(
+			"{a}", budgetNouns[i*3%len(budgetNouns)],
+			"{b}", budgetNouns[(i*5+1)%len(budgetNouns)],
+			"{n}", fmt.Sprint(10+i*7%90),
+		)
+		b.WriteString(fill.Replace(budgetSentences[i%len(budgetSentences)]))
+	}
+	return b.String()
+}
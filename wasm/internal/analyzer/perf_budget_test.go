@@ -0,0 +1,114 @@
+package analyzer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// stageBenchmarks run each analyzer stage on its own, preparing the earlier
+// stages' output outside the timed loop
+var stageBenchmarks = []struct {
+	name string
+	run  func(b *testing.B, text string)
+}{
+	{"complexity", func(b *testing.B, text string) {
+		for i := 0; i < b.N; i++ {
+			AnalyzeComplexity(text)
+		}
+	}},
+	{"tokens", func(b *testing.B, text string) {
+		for i := 0; i < b.N; i++ {
+			TokenizeText(text)
+		}
+	}},
+	{"preprocessing", func(b *testing.B, text string) {
+		for i := 0; i < b.N; i++ {
+			PreprocessText(text)
+		}
+	}},
+	{"ideas", func(b *testing.B, text string) {
+		for i := 0; i < b.N; i++ {
+			AnalyzeIdeas(text)
+		}
+	}},
+	{"task_graph", func(b *testing.B, text string) {
+		for i := 0; i < b.N; i++ {
+			ExtractTaskGraphFromText(text)
+		}
+	}},
+	{"insights", func(b *testing.B, text string) {
+		comp, tok, pre, ideas := AnalyzeComplexity(text), TokenizeText(text), PreprocessText(text), AnalyzeIdeas(text)
+		goals, graph := ExtractGoals(text), *ExtractTaskGraphFromText(text)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			TransformToInsights(comp, ideas, tok, pre, goals, graph, text)
+		}
+	}},
+	{"grade", func(b *testing.B, text string) {
+		comp, tok, pre, ideas := AnalyzeComplexity(text), TokenizeText(text), PreprocessText(text), AnalyzeIdeas(text)
+		graph := *ExtractTaskGraphFromText(text)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			CalculatePromptGrade(comp, tok, pre, ideas, graph, text)
+		}
+	}},
+}
+
+// BenchmarkStages times every stage at every BudgetSizes input, as
+// BenchmarkStages/<stage>/<size>
+func BenchmarkStages(b *testing.B) {
+	for _, stage := range stageBenchmarks {
+		for _, size := range BudgetSizes {
+			text := BudgetInput(size.Sentences)
+			b.Run(stage.name+"/"+size.Name, func(b *testing.B) {
+				stage.run(b, text)
+			})
+		}
+	}
+}
+
+// TestPerformanceBudget fails when a stage runs over testdata/perf_budget.json.
+// It takes a few seconds, so -short skips it.
+func TestPerformanceBudget(t *testing.T) {
+	if testing.Short() {
+		t.Skip("timing check skipped in short mode")
+	}
+	data, err := os.ReadFile(filepath.Join("testdata", "perf_budget.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	budget, err := LoadPerformanceBudget(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range CheckBudgets(budget) {
+		t.Error(v)
+	}
+}
+
+func TestLoadPerformanceBudgetRejectsMistakes(t *testing.T) {
+	for _, tc := range []struct{ budget, want string }{
+		{`{"stages":{"tokenization":{"limits_ms":{"huge":10}}}}`, `unknown input size "huge"`},
+		{`{"stages":{"tokenization":{"limits_ms":{"small":0}}}}`, "limit must be positive"},
+		{`{"stages":{"tokenization":{"max_growth":-1}}}`, "must not be negative"},
+		{`{"stages":{"tokenization":{"limit_ms":{"small":10}}}}`, "unknown field"},
+	} {
+		if _, err := LoadPerformanceBudget([]byte(tc.budget)); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: got %v, want error containing %q", tc.budget, err, tc.want)
+		}
+	}
+}
+
+func TestBudgetGrowth(t *testing.T) {
+	if g, ok := budgetGrowth(10, 160, 50, 200); !ok || g != 2 {
+		t.Errorf("quadratic growth = %v, %v; want 2", g, ok)
+	}
+	if g, ok := budgetGrowth(10, 40, 50, 200); !ok || g != 1 {
+		t.Errorf("linear growth = %v, %v; want 1", g, ok)
+	}
+	if _, ok := budgetGrowth(0.1, 0.5, 50, 200); ok {
+		t.Error("sub-millisecond timings should be skipped as noise")
+	}
+}
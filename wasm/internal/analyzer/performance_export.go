@@ -0,0 +1,267 @@
+package analyzer
+
+import (
+	"fmt"
+	"hash/fnv"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OTLPTraceExport is an OTLP/JSON trace payload accepted by OpenTelemetry collectors
+type OTLPTraceExport struct {
+	ResourceSpans []OTLPResourceSpans `json:"resourceSpans"`
+}
+
+// OTLPResourceSpans groups spans emitted by one service
+type OTLPResourceSpans struct {
+	Resource   OTLPResource     `json:"resource"`
+	ScopeSpans []OTLPScopeSpans `json:"scopeSpans"`
+}
+
+// OTLPResource describes the emitting service
+type OTLPResource struct {
+	Attributes []OTLPKeyValue `json:"attributes"`
+}
+
+// OTLPScopeSpans groups spans by instrumentation scope
+type OTLPScopeSpans struct {
+	Scope OTLPScope  `json:"scope"`
+	Spans []OTLPSpan `json:"spans"`
+}
+
+// OTLPScope names the instrumentation library
+type OTLPScope struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// OTLPSpan is a single timed operation
+type OTLPSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	ParentSpanID      string         `json:"parentSpanId,omitempty"`
+	Name              string         `json:"name"`
+	Kind              int            `json:"kind"` // 1 = internal
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []OTLPKeyValue `json:"attributes"`
+}
+
+// OTLPKeyValue is an attribute on a span or resource
+type OTLPKeyValue struct {
+	Key   string       `json:"key"`
+	Value OTLPAnyValue `json:"value"`
+}
+
+// OTLPAnyValue holds a single typed attribute value
+type OTLPAnyValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+}
+
+// Stage names used for the fixed pipeline stages in PerformanceMetrics
+const (
+	stageComplexity    = "complexity_analysis"
+	stageTokenization  = "tokenization"
+	stagePreprocessing = "preprocessing"
+)
+
+// stageDurations lists every timed stage, core stages first and sub-operations sorted by name
+func (p *PerformanceMetrics) stageDurations() []struct {
+	name   string
+	metric EnhancedDurationMetric
+} {
+	stages := []struct {
+		name   string
+		metric EnhancedDurationMetric
+	}{
+		{stageComplexity, p.ComplexityDuration},
+		{stageTokenization, p.TokenizationDuration},
+		{stagePreprocessing, p.PreprocessingDuration},
+	}
+
+	names := make([]string, 0, len(p.SubOperations))
+	for name := range p.SubOperations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		stages = append(stages, struct {
+			name   string
+			metric EnhancedDurationMetric
+		}{name, p.SubOperations[name]})
+	}
+	return stages
+}
+
+// ToOTLPTraces maps the request and its stages onto an OTLP trace with one child span per stage
+func (p *PerformanceMetrics) ToOTLPTraces(serviceName string) OTLPTraceExport {
+	traceID := hashHex(p.RequestID, 16)
+	rootID := hashHex(p.RequestID+"/analyze", 8)
+
+	start := p.StartTime
+	end := start.Add(p.TotalDuration.duration)
+
+	spans := []OTLPSpan{{
+		TraceID:           traceID,
+		SpanID:            rootID,
+		Name:              "analyze",
+		Kind:              1,
+		StartTimeUnixNano: unixNano(start),
+		EndTimeUnixNano:   unixNano(end),
+		Attributes: []OTLPKeyValue{
+			stringAttr("fulcrum.request_id", p.RequestID),
+			doubleAttr("fulcrum.duration_ms", p.TotalDuration.Value),
+		},
+	}}
+
+	for _, stage := range p.stageDurations() {
+		stageStart := stage.metric.started
+		if stageStart.IsZero() {
+			stageStart = start
+		}
+		spans = append(spans, OTLPSpan{
+			TraceID:           traceID,
+			SpanID:            hashHex(p.RequestID+"/"+stage.name, 8),
+			ParentSpanID:      rootID,
+			Name:              stage.name,
+			Kind:              1,
+			StartTimeUnixNano: unixNano(stageStart),
+			EndTimeUnixNano:   unixNano(stageStart.Add(stage.metric.duration)),
+			Attributes: []OTLPKeyValue{
+				stringAttr("fulcrum.stage", stage.name),
+				doubleAttr("fulcrum.duration_ms", stage.metric.Value),
+			},
+		})
+	}
+
+	return OTLPTraceExport{ResourceSpans: []OTLPResourceSpans{{
+		Resource: OTLPResource{Attributes: []OTLPKeyValue{stringAttr("service.name", serviceName)}},
+		ScopeSpans: []OTLPScopeSpans{{
+			Scope: OTLPScope{Name: "fulcrum-analyzer", Version: ResultSchemaVersion},
+			Spans: spans,
+		}},
+	}}}
+}
+
+// defaultLatencyBuckets are histogram upper bounds in seconds
+var defaultLatencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// StageMetrics aggregates per-stage latency across requests for Prometheus scraping
+type StageMetrics struct {
+	mu       sync.Mutex
+	buckets  []float64
+	requests uint64
+	stages   map[string]*latencyHistogram
+}
+
+// latencyHistogram is a cumulative Prometheus-style histogram
+type latencyHistogram struct {
+	counts []uint64 // One per bucket, cumulative on output
+	count  uint64
+	sum    float64
+}
+
+// NewStageMetrics creates an empty stage latency registry
+func NewStageMetrics() *StageMetrics {
+	return &StageMetrics{
+		buckets: defaultLatencyBuckets,
+		stages:  make(map[string]*latencyHistogram),
+	}
+}
+
+// Observe records every stage duration from one analysis request
+func (m *StageMetrics) Observe(p *PerformanceMetrics) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.requests++
+	m.observe("total", p.TotalDuration.Value/1000)
+	for _, stage := range p.stageDurations() {
+		m.observe(stage.name, stage.metric.Value/1000)
+	}
+}
+
+// observe adds a single sample in seconds; callers hold the lock
+func (m *StageMetrics) observe(stage string, seconds float64) {
+	h, ok := m.stages[stage]
+	if !ok {
+		h = &latencyHistogram{counts: make([]uint64, len(m.buckets))}
+		m.stages[stage] = h
+	}
+	for i, bound := range m.buckets {
+		if seconds <= bound {
+			h.counts[i]++
+			break
+		}
+	}
+	h.count++
+	h.sum += seconds
+}
+
+// WritePrometheus writes the registry in the Prometheus text exposition format
+func (m *StageMetrics) WritePrometheus(w io.Writer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var b strings.Builder
+	b.WriteString("# HELP fulcrum_analysis_requests_total Number of analysis requests observed.\n")
+	b.WriteString("# TYPE fulcrum_analysis_requests_total counter\n")
+	fmt.Fprintf(&b, "fulcrum_analysis_requests_total %d\n", m.requests)
+
+	b.WriteString("# HELP fulcrum_analysis_stage_duration_seconds Latency of each analysis stage.\n")
+	b.WriteString("# TYPE fulcrum_analysis_stage_duration_seconds histogram\n")
+
+	names := make([]string, 0, len(m.stages))
+	for name := range m.stages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		h := m.stages[name]
+		cumulative := uint64(0)
+		for i, bound := range m.buckets {
+			cumulative += h.counts[i]
+			fmt.Fprintf(&b, "fulcrum_analysis_stage_duration_seconds_bucket{stage=%q,le=%q} %d\n",
+				name, strconv.FormatFloat(bound, 'g', -1, 64), cumulative)
+		}
+		fmt.Fprintf(&b, "fulcrum_analysis_stage_duration_seconds_bucket{stage=%q,le=\"+Inf\"} %d\n", name, h.count)
+		fmt.Fprintf(&b, "fulcrum_analysis_stage_duration_seconds_sum{stage=%q} %s\n", name, strconv.FormatFloat(h.sum, 'g', -1, 64))
+		fmt.Fprintf(&b, "fulcrum_analysis_stage_duration_seconds_count{stage=%q} %d\n", name, h.count)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// hashHex derives a stable lowercase hex identifier of n bytes from a seed
+func hashHex(seed string, n int) string {
+	out := make([]byte, 0, n)
+	for i := 0; len(out) < n; i++ {
+		h := fnv.New64a()
+		fmt.Fprintf(h, "%d:%s", i, seed)
+		sum := h.Sum64()
+		for shift := 56; shift >= 0 && len(out) < n; shift -= 8 {
+			out = append(out, byte(sum>>uint(shift)))
+		}
+	}
+	return fmt.Sprintf("%x", out)
+}
+
+// unixNano formats a timestamp as OTLP expects (decimal nanoseconds in a string)
+func unixNano(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
+
+func stringAttr(key, value string) OTLPKeyValue {
+	return OTLPKeyValue{Key: key, Value: OTLPAnyValue{StringValue: &value}}
+}
+
+func doubleAttr(key string, value float64) OTLPKeyValue {
+	return OTLPKeyValue{Key: key, Value: OTLPAnyValue{DoubleValue: &value}}
+}
@@ -0,0 +1,49 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestPerformanceExport checks the OTLP span mapping and Prometheus output
+func TestPerformanceExport(t *testing.T) {
+	perf := NewPerformanceMetrics("req_test")
+	start := perf.StartTime
+	perf.AddSubOperationAt("task_graph_extraction", start.Add(20*time.Millisecond), 5*time.Millisecond)
+	perf.Finalize(10*time.Millisecond, 3*time.Millisecond, 2*time.Millisecond)
+
+	trace := perf.ToOTLPTraces("fulcrum-test")
+	spans := trace.ResourceSpans[0].ScopeSpans[0].Spans
+	if len(spans) != 5 {
+		t.Fatalf("expected root plus four stage spans, got %d", len(spans))
+	}
+	root := spans[0]
+	for _, span := range spans[1:] {
+		if span.ParentSpanID != root.SpanID || span.TraceID != root.TraceID {
+			t.Errorf("span %s is not a child of the root span", span.Name)
+		}
+		if span.Name == "task_graph_extraction" && span.StartTimeUnixNano != unixNano(start.Add(20*time.Millisecond)) {
+			t.Errorf("expected task graph span to keep its recorded start time")
+		}
+	}
+	if len(root.TraceID) != 32 || len(root.SpanID) != 16 {
+		t.Errorf("unexpected id lengths: trace %q span %q", root.TraceID, root.SpanID)
+	}
+
+	metrics := NewStageMetrics()
+	metrics.Observe(perf)
+	var b strings.Builder
+	if err := metrics.WritePrometheus(&b); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	for _, want := range []string{
+		"fulcrum_analysis_requests_total 1",
+		`fulcrum_analysis_stage_duration_seconds_bucket{stage="complexity_analysis",le="0.01"} 1`,
+		`fulcrum_analysis_stage_duration_seconds_count{stage="task_graph_extraction"} 1`,
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("expected %q in metrics output:\n%s", want, b.String())
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package analyzer
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// PhoneNumber is a phone number parsed from the text. Numbers written with +
+// or 00 are read by their calling code; others by the default region.
+type PhoneNumber struct {
+	Span
+	E164          string `json:"e164,omitempty"`    // e.g. +14155552671; empty when invalid
+	Country       string `json:"country,omitempty"` // ISO 3166 code from the calling code or default region
+	Valid         bool   `json:"valid"`             // Length and leading digits fit the country's plan
+	International bool   `json:"international"`     // Written with + or 00
+}
+
+// phoneRegion is the part of a country's numbering plan needed to validate
+// and normalize numbers
+type phoneRegion struct {
+	callingCode          string
+	minLength, maxLength int    // National significant number length
+	trunk                string // Dialed before national numbers at home and dropped in E.164
+	leading              string // Digits a national number may start with; "" allows any
+}
+
+// phoneRegions covers common countries; numbers with other calling codes are
+// reported as invalid
+var phoneRegions = map[string]phoneRegion{
+	"US": {"1", 10, 10, "1", "23456789"},
+	"CA": {"1", 10, 10, "1", "23456789"},
+	"GB": {"44", 9, 10, "0", "123789"},
+	"IE": {"353", 7, 9, "0", ""},
+	"DE": {"49", 6, 13, "0", "123456789"},
+	"FR": {"33", 9, 9, "0", "123456789"},
+	"ES": {"34", 9, 9, "", "6789"},
+	"IT": {"39", 6, 11, "", "03"},
+	"NL": {"31", 9, 9, "0", "123456789"},
+	"CH": {"41", 9, 9, "0", "123456789"},
+	"SE": {"46", 7, 9, "0", "123456789"},
+	"IN": {"91", 10, 10, "0", "123456789"},
+	"CN": {"86", 10, 11, "0", "123456789"},
+	"JP": {"81", 9, 10, "0", "123456789"},
+	"SG": {"65", 8, 8, "", "3689"},
+	"AU": {"61", 9, 9, "0", "23478"},
+	"BR": {"55", 10, 11, "0", "123456789"},
+	"MX": {"52", 10, 10, "", "123456789"},
+}
+
+// PhoneRegions lists the region codes accepted as AnalysisOptions.PhoneRegion
+var PhoneRegions = func() []string {
+	regions := make([]string, 0, len(phoneRegions))
+	for r := range phoneRegions {
+		regions = append(regions, r)
+	}
+	sort.Strings(regions)
+	return regions
+}()
+
+// DefaultPhoneRegion reads numbers without a calling code
+const DefaultPhoneRegion = "US"
+
+// validatePhoneRegion rejects regions without a numbering plan; "" means US
+func validatePhoneRegion(region string) error {
+	if _, ok := phoneRegions[region]; region != "" && !ok {
+		return fmt.Errorf("unknown phone region %q (expected one of %v)", region, PhoneRegions)
+	}
+	return nil
+}
+
+var (
+	phoneCandidatePattern = regexp.MustCompile(`\+?\(?\d[\d\s().\-]{5,}\d\b`)
+
+	// callingCodeRegions names one region per calling code for numbers
+	// outside the default region; +1 is read as the US
+	callingCodeRegions = func() map[string]string {
+		regions := map[string]string{}
+		for _, r := range PhoneRegions {
+			if _, ok := regions[phoneRegions[r].callingCode]; !ok {
+				regions[phoneRegions[r].callingCode] = r
+			}
+		}
+		regions["1"] = "US"
+		return regions
+	}()
+)
+
+// ParsePhoneNumbers finds phone numbers in text, reading numbers without a
+// calling code as region's (DefaultPhoneRegion when empty). Digit runs that
+// are national-format but fit no plan, such as years or order numbers, are
+// left out; international numbers are kept and flagged when invalid.
+func ParsePhoneNumbers(text, region string) []PhoneNumber {
+	if _, ok := phoneRegions[region]; !ok {
+		region = DefaultPhoneRegion
+	}
+	out := []PhoneNumber{}
+	for _, m := range phoneCandidatePattern.FindAllStringIndex(text, -1) {
+		raw := strings.TrimRight(text[m[0]:m[1]], " -.")
+		if ipv4Pattern.FindString(raw) == raw || numericDatePattern.FindString(raw) == raw {
+			continue
+		}
+		digits := strings.Map(func(r rune) rune {
+			if r >= '0' && r <= '9' {
+				return r
+			}
+			return -1
+		}, raw)
+		if len(digits) < 7 || len(digits) > 15 {
+			continue
+		}
+		phone := PhoneNumber{Span: newSpan(text, m[0], m[0]+len(raw))}
+		if strings.HasPrefix(raw, "+") || strings.HasPrefix(raw, "00") {
+			phone.International = true
+			if strings.HasPrefix(raw, "00") {
+				digits = digits[2:]
+			}
+			phone.Country, phone.E164, phone.Valid = parseInternational(digits, raw, region)
+		} else {
+			phone.Country = region
+			phone.E164, phone.Valid = parseNational(digits, phoneRegions[region])
+			if !phone.Valid {
+				continue
+			}
+		}
+		out = append(out, phone)
+	}
+	return out
+}
+
+// parseInternational matches the longest known calling code and validates
+// the rest; "+44 (0)20 ..." style trunk digits in brackets are dropped
+func parseInternational(digits, raw, region string) (country, e164 string, valid bool) {
+	for n := 3; n >= 1; n-- {
+		if len(digits) <= n {
+			continue
+		}
+		country = callingCodeRegion(digits[:n], region)
+		if country == "" {
+			continue
+		}
+		plan := phoneRegions[country]
+		national := digits[n:]
+		if plan.trunk != "" && strings.Contains(raw, "("+plan.trunk+")") {
+			national = strings.TrimPrefix(national, plan.trunk)
+		}
+		if !planAccepts(plan, national) {
+			return country, "", false
+		}
+		return country, "+" + plan.callingCode + national, true
+	}
+	return "", "", false
+}
+
+// callingCodeRegion names the region for a calling code, preferring the
+// default region when several share it (US and CA share +1)
+func callingCodeRegion(code, region string) string {
+	if phoneRegions[region].callingCode == code {
+		return region
+	}
+	return callingCodeRegions[code]
+}
+
+// parseNational strips the trunk prefix and validates against the region's plan
+func parseNational(digits string, plan phoneRegion) (string, bool) {
+	if plan.trunk != "" && strings.HasPrefix(digits, plan.trunk) && len(digits)-len(plan.trunk) >= plan.minLength {
+		digits = digits[len(plan.trunk):]
+	}
+	if !planAccepts(plan, digits) {
+		return "", false
+	}
+	return "+" + plan.callingCode + digits, true
+}
+
+// planAccepts checks a national significant number's length and leading
+// digit, plus the NANP rule that exchange codes cannot start with 0 or 1
+func planAccepts(plan phoneRegion, national string) bool {
+	if len(national) < plan.minLength || len(national) > plan.maxLength {
+		return false
+	}
+	if plan.leading != "" && !strings.ContainsRune(plan.leading, rune(national[0])) {
+		return false
+	}
+	if plan.callingCode == "1" && (national[3] == '0' || national[3] == '1') {
+		return false
+	}
+	return true
+}
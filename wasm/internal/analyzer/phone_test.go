@@ -0,0 +1,35 @@
+package analyzer
+
+import "testing"
+
+func TestParsePhoneNumbers(t *testing.T) {
+	text := "Call (415) 555-2671 or +44 (0)20 7946 0958, fax 0049 30 901820. " +
+		"Order 20240512, invoice 1234567890, years 1990-2000, 10.0.0.12, and +999 1234 5678."
+	got := ParsePhoneNumbers(text, "")
+	want := []PhoneNumber{
+		{Span: Span{Text: "(415) 555-2671"}, E164: "+14155552671", Country: "US", Valid: true},
+		{Span: Span{Text: "+44 (0)20 7946 0958"}, E164: "+442079460958", Country: "GB", Valid: true, International: true},
+		{Span: Span{Text: "0049 30 901820"}, E164: "+4930901820", Country: "DE", Valid: true, International: true},
+		{Span: Span{Text: "+999 1234 5678"}, Valid: false, International: true},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %+v, want %d numbers", got, len(want))
+	}
+	for i, p := range got {
+		w := want[i]
+		if p.Text != w.Text || p.E164 != w.E164 || p.Country != w.Country || p.Valid != w.Valid || p.International != w.International {
+			t.Errorf("got %+v, want %+v", p, w)
+		}
+		if text[p.Start:p.End] != p.Text {
+			t.Errorf("span %d-%d does not cover %q", p.Start, p.End, p.Text)
+		}
+	}
+
+	uk := ParsePhoneNumbers("Ring 020 7946 0958 or 07700 900123.", "GB")
+	if len(uk) != 2 || uk[0].E164 != "+442079460958" || uk[1].E164 != "+447700900123" || uk[1].Country != "GB" {
+		t.Errorf("GB national numbers = %+v", uk)
+	}
+	if err := (AnalysisOptions{PhoneRegion: "XX"}).Validate(); err == nil {
+		t.Error("unknown phone region accepted")
+	}
+}
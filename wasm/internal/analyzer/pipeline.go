@@ -0,0 +1,509 @@
+package analyzer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CombinedResult is the full analysis result returned by both the WASM module and the server
+type CombinedResult struct {
+	SchemaVersion string              `json:"schema_version"`
+	Stages        []string            `json:"stages"`
+	Complexity    ComplexityMetrics   `json:"complexity_metrics"`
+	Tokens        TokenData           `json:"tokens"`
+	Preprocessing PreprocessingData   `json:"preprocessing"`
+	Performance   PerformanceMetrics  `json:"performance_metrics"`
+	Ideas         IdeaAnalysisMetrics `json:"idea_analysis"`
+	Insights      InsightAnalysis     `json:"insights"`
+	TaskGraph     TaskGraph           `json:"task_graph"`
+	// Deprecated: PromptGrade holds the classic engine's grade only, and is
+	// empty with the modern grader; read Grades instead.
+	PromptGrade    PromptGrade         `json:"prompt_grade"`
+	ModernGrade    *ModernPromptGrade  `json:"modern_grade,omitempty"` // With the modern or both graders
+	Grades         GradeEnvelope       `json:"grades"`                 // Every engine's grade in one shape
+	Baseline       *BaselineComparison `json:"baseline,omitempty"`     // The leading grade among the reference prompts of its type
+	Stability      *ScoreStability     `json:"stability,omitempty"`    // Confidence interval of the leading score, with options.stability
+	Sections       *SectionReport      `json:"sections,omitempty"`     // Per-section grades of a long document, with options.sections
+	FrontMatter    *FrontMatter        `json:"front_matter,omitempty"` // Metadata the text opened with; the rest of the result covers the prompt after it
+	Document       *PromptDocument     `json:"document,omitempty"`     // The prompt file's messages and variables, with options.input_format; the rest of the result covers document.text
+	DegradedStages []StageDegradation  `json:"degraded_stages"`
+	PartialFailure []StageFailure      `json:"partial_failure"`     // Stages that failed; their sections hold zero values
+	Truncated      []Truncation        `json:"truncated,omitempty"` // Lists cut short by per-request limits
+	Annotations    []Annotation        `json:"annotations"`         // Located findings from every stage, by position
+	Issues         []Issue             `json:"issues"`              // Suggestions and findings from every stage, most severe first
+}
+
+// AnalysisRun carries optional per-request hooks for Analyze
+type AnalysisRun struct {
+	RequestID string // Generated when empty
+	Workers   int    // Concurrent stages; defaults to RuntimeConfig.Workers, else 2, which suits WASM
+	// Yield, when set, is called between stages so single-threaded hosts can
+	// run other work (and deliver cancellation) mid-analysis
+	Yield func()
+	// OnProgress, when set, receives stage start and completion events
+	OnProgress func(ProgressEvent)
+}
+
+// StageFailure records a stage that panicked, or that was skipped because a
+// stage it depends on failed. The rest of the result is still filled in.
+type StageFailure struct {
+	Stage   string `json:"stage"`
+	Error   string `json:"error"`
+	Skipped bool   `json:"skipped"` // Not run because a dependency failed
+}
+
+// stageFault, when set by tests, runs at the start of every stage so failures
+// can be injected
+var stageFault func(stage string)
+
+// stageFailures collects failed stages from concurrently running stages
+type stageFailures struct {
+	mu   sync.Mutex
+	list []StageFailure
+}
+
+// run calls fn as the named stage, recording a panic as a failure instead of
+// losing the other stages' results
+func (f *stageFailures) run(stage string, fn func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			f.mu.Lock()
+			f.list = append(f.list, StageFailure{Stage: stage, Error: fmt.Sprint(r)})
+			f.mu.Unlock()
+		}
+	}()
+	if stageFault != nil {
+		stageFault(stage)
+	}
+	fn()
+}
+
+// blocked reports whether a stage it cannot run without has failed, and
+// records the stage as skipped if so
+func (f *stageFailures) blocked(stage string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, dep := range stageDependencies[stage] {
+		for _, failed := range f.list {
+			if failed.Stage == dep {
+				f.list = append(f.list, StageFailure{Stage: stage, Error: fmt.Sprintf("skipped because %s failed", dep), Skipped: true})
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Progress event types
+const (
+	ProgressStageStart    = "stage_start"
+	ProgressStageComplete = "stage_complete"
+)
+
+// ProgressEvent reports a stage starting or completing, with request-relative timings
+type ProgressEvent struct {
+	RequestID  string
+	Type       string
+	Stage      string
+	Completed  int     // Stages finished so far
+	Total      int     // Stages this request will run
+	ElapsedMs  float64 // Since the request started
+	DurationMs float64 // Stage duration; set on completion
+}
+
+// progressTracker counts finished stages and forwards events to the run's callback
+type progressTracker struct {
+	run       *AnalysisRun
+	mu        sync.Mutex
+	started   time.Time
+	completed int
+	total     int
+}
+
+func (p *progressTracker) start(stage string) {
+	p.emit(ProgressStageStart, stage, 0)
+}
+
+func (p *progressTracker) complete(stage string, dur time.Duration) {
+	p.emit(ProgressStageComplete, stage, dur)
+}
+
+func (p *progressTracker) emit(eventType, stage string, dur time.Duration) {
+	if p.run.OnProgress == nil {
+		return
+	}
+	p.mu.Lock()
+	if eventType == ProgressStageComplete {
+		p.completed++
+	}
+	event := ProgressEvent{
+		RequestID:  p.run.RequestID,
+		Type:       eventType,
+		Stage:      stage,
+		Completed:  p.completed,
+		Total:      p.total,
+		ElapsedMs:  float64(time.Since(p.started).Microseconds()) / 1000,
+		DurationMs: float64(dur.Microseconds()) / 1000,
+	}
+	p.mu.Unlock()
+	p.run.OnProgress(event)
+}
+
+// Analyze runs the selected analysis stages on text. Cancellation is checked
+// before each stage; a stage already running finishes first. The result is
+// normalized so every collection marshals as [] or {} rather than null.
+func Analyze(ctx context.Context, text string, opts AnalysisOptions, run AnalysisRun) (result *CombinedResult, err error) {
+	yield := run.Yield
+	// A panic outside any stage is returned as an error rather than crashing
+	defer func() {
+		if r := recover(); r != nil {
+			result, err = nil, fmt.Errorf("analysis panicked: %v", r)
+		}
+	}()
+
+	checkpoint := func() error {
+		if yield != nil {
+			yield()
+		}
+		return ctx.Err()
+	}
+	if err := checkpoint(); err != nil {
+		return nil, err
+	}
+	frontMatter, text := ParseFrontMatter(text)
+	document, text := ParsePromptDocument(text, frontMatter, opts.InputFormat)
+
+	// Force garbage collection before heavy analysis
+	runtime.GC()
+
+	// Initialize performance tracking
+	if run.RequestID == "" && opts.Deterministic {
+		run.RequestID = deterministicRequestID(text)
+	} else if run.RequestID == "" {
+		run.RequestID = fmt.Sprintf("req_%d", time.Now().UnixNano())
+	}
+	ref := opts.referenceTime()
+	progress := &progressTracker{run: &run, started: time.Now(), total: len(opts.SelectedStages())}
+	perf := NewPerformanceMetrics(run.RequestID)
+
+	// Create worker pool with limited goroutines (2 for WASM environment)
+	pool := NewWorkerPool(run.Workers)
+	defer pool.Close()
+
+	stop, stopLanguage := opts.Stopwords.Resolve(text)
+	speller := NewSpellChecker(stopLanguage, opts.spellingAllowlist())
+
+	var comp ComplexityMetrics
+	var tok TokenData
+	var pre PreprocessingData
+	var ideas IdeaAnalysisMetrics
+	degraded := []StageDegradation{}
+	failures := &stageFailures{}
+
+	// Track individual operation durations
+	var complexityDur, tokenDur, preprocessDur, ideaDur time.Duration
+	var ideaStart time.Time
+	var mu sync.Mutex // Protect concurrent writes
+
+	// Submit tasks to worker pool instead of creating unlimited goroutines
+	if opts.Runs(StageComplexity) {
+		pool.Submit(func() {
+			failures.run(StageComplexity, func() {
+				if checkpoint() != nil {
+					return
+				}
+				progress.start(StageComplexity)
+				timer := NewTimer("complexity_analysis")
+				result := AnalyzeComplexityWithSpeeds(text, opts.ReadingSpeed)
+				dur := timer.Stop()
+				progress.complete(StageComplexity, dur)
+				mu.Lock()
+				comp = result
+				complexityDur = dur
+				mu.Unlock()
+			})
+		})
+	}
+
+	if opts.Runs(StageTokens) {
+		pool.Submit(func() {
+			failures.run(StageTokens, func() {
+				if checkpoint() != nil {
+					return
+				}
+				progress.start(StageTokens)
+				timer := NewTimer("tokenization")
+				result := TokenizeTextWithStopwords(text, stop)
+				result.StopwordLanguage = stopLanguage
+				if opts.TokenStream {
+					result.Stream = tokenStream(text, result.Tokens)
+				}
+				dur := timer.Stop()
+				progress.complete(StageTokens, dur)
+				mu.Lock()
+				tok = result
+				tokenDur = dur
+				mu.Unlock()
+			})
+		})
+	}
+
+	if opts.Runs(StagePreprocessing) {
+		pool.Submit(func() {
+			failures.run(StagePreprocessing, func() {
+				if checkpoint() != nil {
+					return
+				}
+				progress.start(StagePreprocessing)
+				timer := NewTimer("preprocessing")
+				result := PreprocessTextWithOptions(text, PreprocessOptions{Stopwords: stop, Speller: speller, Inclusive: NewInclusiveLanguageChecker(opts.InclusiveLanguage), FullLog: opts.FullTransformationLog, PhoneRegion: opts.PhoneRegion, ReferenceTime: ref, Cleaning: opts.cleaningSteps(), PreserveStructure: opts.PreserveStructure})
+				dur := timer.Stop()
+				progress.complete(StagePreprocessing, dur)
+				mu.Lock()
+				pre = result
+				preprocessDur = dur
+				mu.Unlock()
+			})
+		})
+	}
+
+	if opts.Runs(StageIdeas) {
+		pool.Submit(func() {
+			failures.run(StageIdeas, func() {
+				if checkpoint() != nil {
+					return
+				}
+				progress.start(StageIdeas)
+				timer := NewTimer("idea_analysis")
+				result, skipped := AnalyzeIdeasWithClustering(text, DefaultMemoryBudget(), stop, opts.ClusteringStrategy)
+				dur := timer.Stop()
+				progress.complete(StageIdeas, dur)
+				mu.Lock()
+				ideas = result
+				degraded = append(degraded, skipped...)
+				ideaDur = dur
+				ideaStart = timer.StartedAt()
+				mu.Unlock()
+			})
+		})
+	} else if opts.Runs(StageGrade) {
+		degraded = append(degraded, StageDegradation{
+			Stage:  "idea_clustering",
+			Mode:   DegradationDisabled,
+			Reason: "not in requested stages; prompt grade computed without idea analysis",
+		})
+	}
+
+	// Wait for all tasks to complete
+	pool.Wait()
+
+	// Force GC after parallel processing
+	runtime.GC()
+
+	if err := checkpoint(); err != nil {
+		return nil, err
+	}
+
+	taskGraph := &TaskGraph{}
+	var taskGraphDur time.Duration
+	taskGraphTimer := NewTimer("task_graph_extraction")
+	if opts.Runs(StageTaskGraph) {
+		failures.run(StageTaskGraph, func() {
+			progress.start(StageTaskGraph)
+			// Extract sentences from existing idea clusters
+			var sentences []string
+			for _, cluster := range ideas.SemanticClusters.Value {
+				sentences = append(sentences, cluster.Sentences...)
+			}
+
+			// If no sentences from clusters, use a simple split as fallback
+			if len(sentences) == 0 {
+				sentences = strings.Split(text, ". ")
+				for i := range sentences {
+					sentences[i] = strings.TrimSpace(sentences[i])
+				}
+			}
+
+			taskGraph = ExtractTaskGraph(text, sentences, ideas.SemanticClusters.Value)
+			taskGraph.resolveDeadlines(ref)
+			taskGraphDur = taskGraphTimer.Stop()
+			progress.complete(StageTaskGraph, taskGraphDur)
+			if taskGraph.sampling != nil {
+				degraded = append(degraded, *taskGraph.sampling)
+			}
+		})
+	} else {
+		if opts.Runs(StageGrade) {
+			degraded = append(degraded, StageDegradation{
+				Stage:  "task_extraction",
+				Mode:   DegradationDisabled,
+				Reason: "not in requested stages; prompt grade computed without a task graph",
+			})
+		}
+	}
+
+	if err := checkpoint(); err != nil {
+		return nil, err
+	}
+
+	// Generate insights from all metrics (after all analysis is complete)
+	var insights InsightAnalysis
+	var insightDur time.Duration
+	insightTimer := NewTimer("insight_generation")
+	if opts.Runs(StageInsights) && !failures.blocked(StageInsights) {
+		failures.run(StageInsights, func() {
+			progress.start(StageInsights)
+			insights = TransformToInsights(comp, ideas, tok, pre, ExtractGoals(text), *taskGraph, text)
+			insightDur = insightTimer.Stop()
+			progress.complete(StageInsights, insightDur)
+		})
+	}
+
+	// Calculate prompt grade
+	promptGrade := &PromptGrade{}
+	var modernGrade *ModernPromptGrade
+	grades := GradeEnvelope{Results: []GradeSummary{}}
+	var stability *ScoreStability
+	var sections *SectionReport
+	var gradeDur time.Duration
+	gradeTimer := NewTimer("prompt_grade_calculation")
+	if opts.Runs(StageGrade) && !failures.blocked(StageGrade) {
+		failures.run(StageGrade, func() {
+			progress.start(StageGrade)
+			in := NewGradingInputs(text, comp, tok, pre, ideas, *taskGraph, NewPromptClassifierWithModel(opts.classifierModel()))
+			var graded []GradedPrompt
+			grades, graded = GradeAll(GradersFor(opts), in)
+			for _, g := range graded {
+				switch g := g.(type) {
+				case *PromptGrade:
+					promptGrade = g
+				case *ModernPromptGrade:
+					modernGrade = g
+				}
+			}
+			if opts.Stability > 0 {
+				// A canceled run stops sampling; the checkpoint after the stage reports it
+				stability, _ = AnalyzeScoreStability(ctx, text, grades.Results[0], opts, opts.Stability)
+			}
+			if opts.Sections {
+				sections = GradeSections(text, grades.Results[0], opts)
+			}
+			gradeDur = gradeTimer.Stop()
+			progress.complete(StageGrade, gradeDur)
+		})
+	}
+
+	if err := checkpoint(); err != nil {
+		return nil, err
+	}
+
+	// Finalize performance metrics
+	perf.Finalize(complexityDur, tokenDur, preprocessDur)
+	if opts.Runs(StageIdeas) {
+		perf.AddSubOperationAt("idea_analysis", ideaStart, ideaDur)
+	}
+	if opts.Runs(StageTaskGraph) {
+		perf.AddSubOperationAt("task_graph_extraction", taskGraphTimer.StartedAt(), taskGraphDur)
+	}
+	if opts.Runs(StageInsights) {
+		perf.AddSubOperationAt("insight_generation", insightTimer.StartedAt(), insightDur)
+	}
+	if opts.Runs(StageGrade) {
+		perf.AddSubOperationAt("prompt_grade_calculation", gradeTimer.StartedAt(), gradeDur)
+	}
+
+	result = &CombinedResult{
+		SchemaVersion:  ResultSchemaVersion,
+		Stages:         opts.SelectedStages(),
+		Complexity:     comp,
+		Tokens:         tok,
+		Preprocessing:  pre,
+		Performance:    *perf,
+		Ideas:          ideas,
+		Insights:       insights,
+		TaskGraph:      *taskGraph,
+		PromptGrade:    *promptGrade,
+		ModernGrade:    modernGrade,
+		Grades:         grades,
+		Stability:      stability,
+		Sections:       sections,
+		FrontMatter:    frontMatter,
+		Document:       document,
+		DegradedStages: degraded,
+		PartialFailure: failures.list,
+	}
+	result.Annotations = BuildAnnotations(text, result)
+	result.Issues = BuildIssues(text, result)
+	if len(grades.Results) > 0 {
+		result.Baseline = CompareToBaseline(grades.Results[0])
+	}
+	truncateResult(result, opts)
+	if opts.Deterministic {
+		result.Performance.clearTimings()
+	}
+
+	// Make every collection marshal as []/{} rather than null
+	Normalize(result)
+	return result, nil
+}
+
+// deterministicRequestID names a request after a hash of its text, so
+// deterministic runs on the same text share an ID
+func deterministicRequestID(text string) string {
+	h := fnv.New64a()
+	h.Write([]byte(text))
+	return fmt.Sprintf("req_%016x", h.Sum64())
+}
+
+// MarshalResult encodes the result as JSON, dropping the sections of stages the
+// caller did not request and any fields outside opts.Fields, and records the time taken as the json_marshaling
+// sub-operation
+func MarshalResult(result *CombinedResult, opts AnalysisOptions) ([]byte, error) {
+	// Placeholder so the sub-operation appears in the marshaled timings
+	result.Performance.AddSubOperation("json_marshaling", 0)
+	if opts.Deterministic {
+		result.Performance.clearTimings()
+	}
+	marshalTimer := NewTimer("json_marshaling")
+	var b []byte
+	var err error
+	if len(opts.Fields) > 0 {
+		b, err = json.Marshal(SelectResultFields(ToPlain(*result).(map[string]interface{}), opts))
+	} else {
+		b, err = json.Marshal(result)
+		if err == nil && len(opts.Stages) > 0 {
+			b, err = selectResultSections(b, opts)
+		}
+	}
+	result.Performance.AddSubOperationAt("json_marshaling", marshalTimer.StartedAt(), marshalTimer.Stop())
+	if opts.Deterministic {
+		result.Performance.clearTimings()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal result: %v", err)
+	}
+	return b, nil
+}
+
+// selectResultSections drops the top-level sections of stages the caller did not request.
+// Stages that only ran as dependencies are omitted too.
+func selectResultSections(b []byte, opts AnalysisOptions) ([]byte, error) {
+	var sections map[string]json.RawMessage
+	if err := json.Unmarshal(b, &sections); err != nil {
+		return nil, err
+	}
+	for stage, keys := range StageResultKeys {
+		if !opts.Wants(stage) {
+			for _, key := range keys {
+				delete(sections, key)
+			}
+		}
+	}
+	return json.Marshal(sections)
+}
@@ -0,0 +1,85 @@
+package analyzer
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+)
+
+const determinismTestText = `# Migration plan
+
+We need to migrate the billing database before the quarter ends. Don't forget the refunds table at https://example.com/docs.
+
+1. Create a snapshot of the billing database.
+2. Update the connection strings in the API gateway.
+
+Why does the export fail at night? Because the invoices index is missing. I think we should shard it; however, the team disagrees.`
+
+func TestDeterministicResultsAreByteIdentical(t *testing.T) {
+	for _, strategy := range ClusteringStrategies {
+		opts := AnalysisOptions{Deterministic: true, ClusteringStrategy: strategy}
+		var first []byte
+		for i := 0; i < 5; i++ {
+			result, err := Analyze(context.Background(), determinismTestText, opts, AnalysisRun{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, err := MarshalResult(result, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if first == nil {
+				first = b
+			} else if !bytes.Equal(b, first) {
+				t.Fatalf("%s: run %d differs from the first", strategy, i)
+			}
+		}
+		if !bytes.Contains(first, []byte(`"request_id":"`+deterministicRequestID(determinismTestText)+`"`)) {
+			t.Errorf("%s: request ID not derived from the text", strategy)
+		}
+	}
+}
+
+func TestTokenPatternPrecedence(t *testing.T) {
+	tokens := extractTokens("Don't visit https://example.com today", stopWords)
+	want := map[string]TokenType{"Don't": Contraction, "https://example.com": URL, "today": Word}
+	for _, tok := range tokens {
+		if typ, ok := want[tok.Text]; ok {
+			if tok.Type != typ {
+				t.Errorf("%q typed %s, want %s", tok.Text, tok.Type, typ)
+			}
+			delete(want, tok.Text)
+		}
+	}
+	if len(want) > 0 {
+		t.Errorf("tokens missing: %v", want)
+	}
+}
+
+func TestFailedStageKeepsOtherResults(t *testing.T) {
+	stageFault = func(stage string) {
+		if stage == StageIdeas {
+			panic("injected failure")
+		}
+	}
+	defer func() { stageFault = nil }()
+
+	result, err := Analyze(context.Background(), determinismTestText, AnalysisOptions{}, AnalysisRun{RequestID: "test"})
+	if err != nil {
+		t.Fatalf("Analyze: %v", err)
+	}
+	want := []StageFailure{
+		{Stage: StageIdeas, Error: "injected failure"},
+		{Stage: StageInsights, Error: "skipped because ideas failed", Skipped: true},
+	}
+	if !reflect.DeepEqual(result.PartialFailure, want) {
+		t.Errorf("partial failure = %+v, want %+v", result.PartialFailure, want)
+	}
+	if result.Complexity.ReadingTime.Value == 0 {
+		t.Error("complexity metrics lost after ideas failed")
+	}
+	if result.PromptGrade.OverallGrade.Grade == "" {
+		t.Error("prompt grade lost after ideas failed")
+	}
+}
@@ -0,0 +1,130 @@
+package analyzer
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// ToPlain converts a result into nested map[string]interface{}, []interface{} and
+// scalar values laid out exactly as encoding/json would encode it. The WASM layer
+// hands this straight to js.ValueOf, skipping the marshal and JSON.parse round trip.
+func ToPlain(v interface{}) interface{} {
+	return plainValue(reflect.ValueOf(v))
+}
+
+var textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+func plainValue(v reflect.Value) interface{} {
+	if !v.IsValid() {
+		return nil
+	}
+	if t, ok := v.Interface().(time.Time); ok {
+		return t.Format(time.RFC3339Nano)
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return plainValue(v.Elem())
+
+	case reflect.Struct:
+		out := make(map[string]interface{}, v.NumField())
+		plainStruct(v, out)
+		return out
+
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+		out := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			out[plainMapKey(iter.Key())] = plainValue(iter.Value())
+		}
+		return out
+
+	case reflect.Slice:
+		if v.IsNil() {
+			return nil
+		}
+		fallthrough
+	case reflect.Array:
+		out := make([]interface{}, v.Len())
+		for i := range out {
+			out[i] = plainValue(v.Index(i))
+		}
+		return out
+
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint()
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	}
+	return nil
+}
+
+// plainStruct copies exported fields into out, flattening untagged embedded structs like encoding/json
+func plainStruct(v reflect.Value, out map[string]interface{}) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous && field.Tag.Get("json") == "" && field.Type.Kind() == reflect.Struct {
+			plainStruct(v.Field(i), out)
+			continue
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+		name, omitEmpty, skip := parseJSONTag(field)
+		if skip {
+			continue
+		}
+		fv := v.Field(i)
+		if omitEmpty && isEmptyValue(fv) {
+			continue
+		}
+		out[name] = plainValue(fv)
+	}
+}
+
+// plainMapKey formats a map key the way encoding/json does
+func plainMapKey(k reflect.Value) string {
+	if k.Kind() == reflect.String {
+		return k.String()
+	}
+	if k.Type().Implements(textMarshalerType) {
+		if b, err := k.Interface().(encoding.TextMarshaler).MarshalText(); err == nil {
+			return string(b)
+		}
+	}
+	return fmt.Sprint(k.Interface())
+}
+
+// isEmptyValue matches encoding/json's omitempty rules
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil()
+	}
+	return false
+}
@@ -0,0 +1,207 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// DefaultPromptGlobs picks out prompt files when a review names none
+var DefaultPromptGlobs = []string{"**/*.prompt", "**/*.prompt.md", "**/prompts/**/*.md", "**/prompts/**/*.txt"}
+
+// prReviewSuggestions is how many suggestions a file lists in the PR comment
+const prReviewSuggestions = 5
+
+// PromptReviewFile is the grade of one changed prompt file
+type PromptReviewFile struct {
+	Path        string       `json:"path"`
+	Score       float64      `json:"score"`
+	Grade       string       `json:"grade"`
+	Passed      bool         `json:"passed"` // Score is at least the review's MinScore
+	Suggestions []Suggestion `json:"suggestions"`
+	Error       string       `json:"error,omitempty"`
+}
+
+// PromptReview grades the prompt files a pull request changes
+type PromptReview struct {
+	Files    []PromptReviewFile `json:"files"`
+	MinScore float64            `json:"min_score"` // 0 lets every graded file pass
+	Passed   bool               `json:"passed"`    // Every file graded and passed
+}
+
+// PRReviewRequest is the body of POST /pr-review. Files carry the changed
+// files' contents; with a Diff, only files it touches are graded.
+type PRReviewRequest struct {
+	Diff     string           `json:"diff,omitempty"`
+	Files    []CorpusDocument `json:"files"`
+	Globs    []string         `json:"globs,omitempty"` // Empty uses DefaultPromptGlobs
+	MinScore float64          `json:"min_score,omitempty"`
+	Options  AnalysisOptions  `json:"options,omitempty"`
+}
+
+// PRReviewResponse holds the review and its PR comment
+type PRReviewResponse struct {
+	Review   PromptReview `json:"review"`
+	Markdown string       `json:"markdown"`
+}
+
+// MatchPromptGlob reports whether the slash-separated name matches pattern.
+// A ** segment matches any number of directories, including none; other
+// segments follow path.Match.
+func MatchPromptGlob(pattern, name string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, err := path.Match(pattern[0], name[0]); err != nil || !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// isPromptFile reports whether name matches any of globs, or of
+// DefaultPromptGlobs when globs is empty
+func isPromptFile(name string, globs []string) bool {
+	if len(globs) == 0 {
+		globs = DefaultPromptGlobs
+	}
+	for _, g := range globs {
+		if MatchPromptGlob(g, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// ChangedFiles lists the files a unified diff adds or modifies, in diff
+// order. Deleted files are left out.
+func ChangedFiles(diff string) []string {
+	files := []string{}
+	seen := map[string]bool{}
+	for _, line := range strings.Split(diff, "\n") {
+		name, ok := strings.CutPrefix(strings.TrimRight(line, "\r"), "+++ ")
+		if !ok || name == "/dev/null" {
+			continue
+		}
+		// git prefixes b/; a trailing tab separates a timestamp in plain diff output
+		name, _, _ = strings.Cut(name, "\t")
+		name = strings.TrimPrefix(name, "b/")
+		if !seen[name] {
+			seen[name] = true
+			files = append(files, name)
+		}
+	}
+	return files
+}
+
+// ReviewPrompts grades each document. Files that fail to analyze are
+// reported with their error and fail the review; cancellation stops the run.
+func ReviewPrompts(ctx context.Context, docs []CorpusDocument, opts AnalysisOptions, minScore float64) (PromptReview, error) {
+	review := PromptReview{Files: []PromptReviewFile{}, MinScore: minScore, Passed: true}
+	opts.Stages = []string{StageGrade}
+	opts.Fields = nil
+	for _, doc := range docs {
+		if err := ctx.Err(); err != nil {
+			return PromptReview{}, err
+		}
+		file := PromptReviewFile{Path: doc.Name, Suggestions: []Suggestion{}}
+		result, err := Analyze(ctx, doc.Text, opts, AnalysisRun{})
+		if err != nil {
+			if ctx.Err() != nil {
+				return PromptReview{}, ctx.Err()
+			}
+			file.Error = err.Error()
+		} else {
+			grade := leadingGradeOf(result)
+			file.Score = grade.Overall.Score
+			file.Grade = grade.Overall.Grade
+			file.Passed = file.Score >= minScore
+			file.Suggestions = grade.Suggestions
+		}
+		review.Passed = review.Passed && file.Passed
+		review.Files = append(review.Files, file)
+	}
+	return review, nil
+}
+
+// ReviewPullRequest grades the prompt files of req, keeping only those the
+// diff touches when it has one
+func ReviewPullRequest(ctx context.Context, req PRReviewRequest) (PromptReview, error) {
+	var changed map[string]bool
+	if req.Diff != "" {
+		changed = map[string]bool{}
+		for _, name := range ChangedFiles(req.Diff) {
+			changed[name] = true
+		}
+	}
+	docs := []CorpusDocument{}
+	for _, f := range req.Files {
+		if isPromptFile(f.Name, req.Globs) && (changed == nil || changed[f.Name]) {
+			docs = append(docs, f)
+		}
+	}
+	return ReviewPrompts(ctx, docs, req.Options, req.MinScore)
+}
+
+// RenderPRComment renders a review as markdown for a pull request comment:
+// a table of grades, then each file's top suggestions
+func RenderPRComment(review PromptReview) string {
+	var sb strings.Builder
+	sb.WriteString("## Prompt review\n\n")
+	if len(review.Files) == 0 {
+		sb.WriteString("No prompt files changed.\n")
+		return sb.String()
+	}
+	status := "All prompts pass"
+	if !review.Passed {
+		status = "Some prompts need work"
+	}
+	if review.MinScore > 0 {
+		status += fmt.Sprintf(" (minimum score %.0f)", review.MinScore)
+	}
+	sb.WriteString("**" + status + ".**\n\n")
+
+	sb.WriteString("| File | Grade | Score | Suggestions |\n|---|---|---|---|\n")
+	for _, f := range review.Files {
+		mark := "✅"
+		if !f.Passed {
+			mark = "❌"
+		}
+		if f.Error != "" {
+			fmt.Fprintf(&sb, "| %s `%s` | error | – | %s |\n", mark, f.Path, markdownCell(strings.ReplaceAll(f.Error, "\n", " ")))
+			continue
+		}
+		fmt.Fprintf(&sb, "| %s `%s` | %s | %.1f | %d |\n", mark, f.Path, f.Grade, f.Score, len(f.Suggestions))
+	}
+
+	for _, f := range review.Files {
+		if len(f.Suggestions) == 0 {
+			continue
+		}
+		fmt.Fprintf(&sb, "\n<details><summary><code>%s</code>: %d suggestions</summary>\n\n", f.Path, len(f.Suggestions))
+		for i, s := range f.Suggestions {
+			if i == prReviewSuggestions {
+				fmt.Fprintf(&sb, "- …and %d more\n", len(f.Suggestions)-i)
+				break
+			}
+			fmt.Fprintf(&sb, "- **%s** `%s` %s · %s\n", s.Message, s.Rule, s.Priority, s.Dimension)
+		}
+		sb.WriteString("\n</details>\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,57 @@
+//go:build !js
+
+package analyzer
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// LoadChangedPrompts reads the prompt files under repo that diff changes
+func LoadChangedPrompts(repo, diff string, globs []string) ([]CorpusDocument, error) {
+	docs := []CorpusDocument{}
+	for _, name := range ChangedFiles(diff) {
+		if !isPromptFile(name, globs) {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(repo, filepath.FromSlash(name)))
+		if err != nil {
+			return nil, fmt.Errorf("read changed prompt: %w", err)
+		}
+		docs = append(docs, CorpusDocument{Name: name, Text: string(data)})
+	}
+	return docs, nil
+}
+
+// LoadPromptFiles reads every prompt file under repo, skipping .git and
+// node_modules
+func LoadPromptFiles(repo string, globs []string) ([]CorpusDocument, error) {
+	docs := []CorpusDocument{}
+	err := filepath.WalkDir(repo, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" || d.Name() == "node_modules" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		name, err := filepath.Rel(repo, p)
+		if err != nil || !isPromptFile(filepath.ToSlash(name), globs) {
+			return nil
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		docs = append(docs, CorpusDocument{Name: filepath.ToSlash(name), Text: string(data)})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("load prompts %s: %w", repo, err)
+	}
+	return docs, nil
+}
@@ -0,0 +1,91 @@
+//go:build !js
+
+package analyzer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const prDiff = `diff --git a/prompts/support/triage.md b/prompts/support/triage.md
+--- a/prompts/support/triage.md
++++ b/prompts/support/triage.md
+@@ -1 +1 @@
+-Old text.
++You are a support agent. Classify the ticket as billing, bug or question and reply with the label only.
+diff --git a/main.go b/main.go
+--- a/main.go
++++ b/main.go
+@@ -1 +1 @@
+-package old
++package main
+diff --git a/prompts/old.txt b/prompts/old.txt
+deleted file mode 100644
+--- a/prompts/old.txt
++++ /dev/null
+@@ -1 +0,0 @@
+-Gone.
+`
+
+func TestMatchPromptGlob(t *testing.T) {
+	cases := []struct {
+		pattern, name string
+		want          bool
+	}{
+		{"**/*.prompt", "agent.prompt", true},
+		{"**/*.prompt", "a/b/agent.prompt", true},
+		{"**/prompts/**/*.md", "prompts/support/triage.md", true},
+		{"**/prompts/**/*.md", "docs/prompts.md", false},
+		{"prompts/*.txt", "prompts/a/b.txt", false},
+	}
+	for _, c := range cases {
+		if got := MatchPromptGlob(c.pattern, c.name); got != c.want {
+			t.Errorf("MatchPromptGlob(%q, %q) = %v, want %v", c.pattern, c.name, got, c.want)
+		}
+	}
+}
+
+func TestChangedPrompts(t *testing.T) {
+	if got, want := ChangedFiles(prDiff), []string{"prompts/support/triage.md", "main.go"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ChangedFiles = %v, want %v", got, want)
+	}
+
+	repo := t.TempDir()
+	os.MkdirAll(filepath.Join(repo, "prompts", "support"), 0o755)
+	os.WriteFile(filepath.Join(repo, "prompts", "support", "triage.md"), []byte("Classify the ticket."), 0o644)
+	os.WriteFile(filepath.Join(repo, "main.go"), []byte("package main"), 0o644)
+	docs, err := LoadChangedPrompts(repo, prDiff, nil)
+	if err != nil || len(docs) != 1 || docs[0].Name != "prompts/support/triage.md" {
+		t.Errorf("LoadChangedPrompts = %+v, %v", docs, err)
+	}
+}
+
+func TestReviewPullRequest(t *testing.T) {
+	review, err := ReviewPullRequest(context.Background(), PRReviewRequest{
+		Diff: prDiff,
+		Files: []CorpusDocument{
+			{Name: "prompts/support/triage.md", Text: "You are a support agent. Classify the ticket as billing, bug or question and reply with the label only."},
+			{Name: "prompts/untouched.md", Text: "Not in the diff."},
+		},
+		MinScore: 100,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(review.Files) != 1 || review.Files[0].Grade == "" || review.Passed {
+		t.Fatalf("review = %+v", review)
+	}
+	comment := RenderPRComment(review)
+	for _, want := range []string{"## Prompt review", "Some prompts need work (minimum score 100)", "| ❌ `prompts/support/triage.md` |"} {
+		if !strings.Contains(comment, want) {
+			t.Errorf("comment missing %q:\n%s", want, comment)
+		}
+	}
+	if got := RenderPRComment(PromptReview{Passed: true}); !strings.Contains(got, "No prompt files changed.") {
+		t.Errorf("empty review comment = %q", got)
+	}
+}
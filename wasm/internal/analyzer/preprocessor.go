@@ -1,9 +1,11 @@
 package analyzer
 
 import (
+	"fmt"
 	"regexp"
 	"sort"
 	"strings"
+	"time"
 	"unicode"
 	"unicode/utf8"
 )
@@ -23,6 +25,9 @@ type PreprocessingData struct {
 	ExtractionResults   EnhancedExtractionData    `json:"extraction_results"`
 	QualityMetrics      EnhancedQualityAssessment `json:"quality_metrics"`
 	TransformationLog   EnhancedTransformationLog `json:"transformation_log"`
+	// StructuredText is set in preserve-structure mode, where NormalizedText
+	// keeps line breaks; it holds the flat and structured variants
+	StructuredText *EnhancedTextStructure `json:"structured_text,omitempty"`
 }
 
 type EnhancedTextStats struct {
@@ -86,6 +91,16 @@ type EnhancedExtractionData struct {
 	Mentions        EnhancedStringSliceMetric `json:"mentions"`
 	EmoticonsSmiley EnhancedStringSliceMetric `json:"emoticons_smiley"`
 	SpecialTokens   EnhancedStringSliceMetric `json:"special_tokens"`
+	IBANs           EnhancedDetections        `json:"ibans"`
+	CreditCards     EnhancedDetections        `json:"credit_cards"`
+	IPAddresses     EnhancedDetections        `json:"ip_addresses"`
+	FilePaths       EnhancedDetections        `json:"file_paths"`
+	UUIDs           EnhancedDetections        `json:"uuids"`
+	CurrencyAmounts EnhancedDetections        `json:"currency_amounts"`
+	ParsedPhones    EnhancedPhoneNumbers      `json:"parsed_phone_numbers"`
+	Temporal        EnhancedTemporal          `json:"temporal_expressions"`
+	ParsedURLs      EnhancedURLs              `json:"parsed_urls"`
+	Emoji           EnhancedEmoji             `json:"emoji"`
 }
 
 type EnhancedQualityAssessment struct {
@@ -96,6 +111,8 @@ type EnhancedQualityAssessment struct {
 	SpellingErrors      EnhancedSpellingErrors    `json:"spelling_errors"`
 	GrammarIssues       EnhancedGrammarIssues     `json:"grammar_issues"`
 	StyleSuggestions    EnhancedStyleSuggestions  `json:"style_suggestions"`
+	PassiveVoiceRatio   EnhancedFloatMetric       `json:"passive_voice_ratio"`
+	ConcisenessScore    EnhancedFloatMetric       `json:"conciseness_score"`
 }
 
 type EnhancedQualityIssues struct {
@@ -126,6 +143,41 @@ type EnhancedStyleSuggestions struct {
 	PracticalApplication string            `json:"practical_application"`
 }
 
+type EnhancedEmoji struct {
+	Value                EmojiAnalysis `json:"value"`
+	Scale                string        `json:"scale"`
+	HelpText             string        `json:"help_text"`
+	PracticalApplication string        `json:"practical_application"`
+}
+
+type EnhancedURLs struct {
+	Value                []URLInfo `json:"value"`
+	Scale                string    `json:"scale"`
+	HelpText             string    `json:"help_text"`
+	PracticalApplication string    `json:"practical_application"`
+}
+
+type EnhancedTemporal struct {
+	Value                []TemporalExpression `json:"value"`
+	Scale                string               `json:"scale"`
+	HelpText             string               `json:"help_text"`
+	PracticalApplication string               `json:"practical_application"`
+}
+
+type EnhancedPhoneNumbers struct {
+	Value                []PhoneNumber `json:"value"`
+	Scale                string        `json:"scale"`
+	HelpText             string        `json:"help_text"`
+	PracticalApplication string        `json:"practical_application"`
+}
+
+type EnhancedDetections struct {
+	Value                []Detection `json:"value"`
+	Scale                string      `json:"scale"`
+	HelpText             string      `json:"help_text"`
+	PracticalApplication string      `json:"practical_application"`
+}
+
 type EnhancedTransformationLog struct {
 	Value               []TransformStep `json:"value"`
 	Scale               string          `json:"scale"`
@@ -190,8 +242,18 @@ type ExtractionData struct {
 	Acronyms        []string `json:"acronyms"`
 	Hashtags        []string `json:"hashtags"`
 	Mentions        []string `json:"mentions"`
-	EmoticonsSmiley []string `json:"emoticons_smiley"`
-	SpecialTokens   []string `json:"special_tokens"`
+	EmoticonsSmiley []string    `json:"emoticons_smiley"`
+	SpecialTokens   []string    `json:"special_tokens"`
+	IBANs           []Detection `json:"ibans"`
+	CreditCards     []Detection `json:"credit_cards"`
+	IPAddresses     []Detection `json:"ip_addresses"`
+	FilePaths       []Detection `json:"file_paths"`
+	UUIDs           []Detection `json:"uuids"`
+	CurrencyAmounts []Detection   `json:"currency_amounts"`
+	ParsedPhones    []PhoneNumber          `json:"parsed_phone_numbers"`
+	Temporal        []TemporalExpression `json:"temporal_expressions"`
+	ParsedURLs      []URLInfo            `json:"parsed_urls"`
+	Emoji           EmojiAnalysis        `json:"emoji"`
 }
 
 type QualityAssessment struct {
@@ -202,6 +264,8 @@ type QualityAssessment struct {
 	SpellingErrors      []SpellingError `json:"spelling_errors"`
 	GrammarIssues       []GrammarIssue `json:"grammar_issues"`
 	StyleSuggestions    []StyleSuggestion `json:"style_suggestions"`
+	PassiveVoice        PassiveVoiceAnalysis `json:"passive_voice"`
+	Conciseness         ConcisenessAnalysis  `json:"conciseness"`
 }
 
 type QualityIssue struct {
@@ -214,8 +278,9 @@ type QualityIssue struct {
 
 type SpellingError struct {
 	Word        string   `json:"word"`
-	Position    int      `json:"position"`
+	Position    int      `json:"position"` // Byte offset in the original text
 	Suggestions []string `json:"suggestions"`
+	Distance    int      `json:"distance"` // Edit distance to the first suggestion
 }
 
 type GrammarIssue struct {
@@ -233,13 +298,21 @@ type StyleSuggestion struct {
 	Length      int    `json:"length"`
 	Suggestion  string `json:"suggestion"`
 	Reason      string `json:"reason"`
+	Kind        string `json:"kind,omitempty"` // passive_voice, cliche, filler, wordy or inclusive_language
 }
 
+// TransformStep logs one preprocessing step. By default only the lengths and
+// a word diff are kept; Before and After hold the full texts when
+// PreprocessOptions.FullLog is set, and PreprocessingData.StepText returns
+// them on demand otherwise.
 type TransformStep struct {
-	Step        string `json:"step"`
-	Before      string `json:"before"`
-	After       string `json:"after"`
-	Description string `json:"description"`
+	Step         string     `json:"step"`
+	Before       string     `json:"before,omitempty"`
+	After        string     `json:"after,omitempty"`
+	BeforeLength int        `json:"before_length"` // Bytes
+	AfterLength  int        `json:"after_length"`  // Bytes
+	Diff         []TextEdit `json:"diff,omitempty"`
+	Description  string     `json:"description"`
 }
 
 func calculateEnhancedTextStats(original, cleaned string) EnhancedTextStats {
@@ -369,15 +442,18 @@ func performEnhancedNormalizationSteps(text string) EnhancedNormalizationSteps {
 	}
 }
 
-func extractEnhancedInformation(text string) EnhancedExtractionData {
-	base := extractInformation(text)
+func extractEnhancedInformation(text string, opts PreprocessOptions) EnhancedExtractionData {
+	base := extractInformation(text, opts)
 	wrap := func(v []string, help string) EnhancedStringSliceMetric {
 		return NewEnhancedStringSliceMetric(v, "List", help, "Use for link detection, contact extraction, and PII handling.")
 	}
+	detections := func(v []Detection, help, practical string) EnhancedDetections {
+		return EnhancedDetections{Value: v, Scale: "List (start/end byte offsets, valid flag)", HelpText: help, PracticalApplication: practical}
+	}
 	return EnhancedExtractionData{
 		URLs:            wrap(base.URLs, "Detected URLs in the text."),
 		EmailAddresses:  wrap(base.EmailAddresses, "Detected email addresses."),
-		PhoneNumbers:    wrap(base.PhoneNumbers, "Detected phone numbers (heuristic)."),
+		PhoneNumbers:    wrap(base.PhoneNumbers, "Phone numbers that fit a known numbering plan, as written."),
 		Dates:           wrap(base.Dates, "Date-like tokens."),
 		Times:           wrap(base.Times, "Time-like tokens."),
 		Numbers:         wrap(base.Numbers, "Numeric tokens."),
@@ -387,19 +463,31 @@ func extractEnhancedInformation(text string) EnhancedExtractionData {
 		Mentions:        wrap(base.Mentions, "@mentions from social text."),
 		EmoticonsSmiley: wrap(base.EmoticonsSmiley, "ASCII emoticons."),
 		SpecialTokens:   wrap(base.SpecialTokens, "Other special tokens."),
+		IBANs:           detections(base.IBANs, "IBANs with their country; valid when the length matches the country and the mod-97 checksum holds.", "Mask bank details before sharing the prompt."),
+		CreditCards:     detections(base.CreditCards, "13-19 digit card numbers with their network; valid when the Luhn check digit matches.", "Valid card numbers should never reach a model; remove them."),
+		IPAddresses:     detections(base.IPAddresses, "IPv4 and IPv6 addresses classed as public, private, loopback or link-local; IPv4 with an octet over 255 is invalid.", "Check internal addresses are meant to be shared."),
+		FilePaths:       detections(base.FilePaths, "Unix and Windows file paths; Windows paths naming reserved devices such as NUL are invalid.", "Confirm paths exist in the model's environment or replace them with placeholders."),
+		UUIDs:           detections(base.UUIDs, "UUIDs with their version; valid when the version is 1-8 and the variant bits follow RFC 9562.", "Identifiers may point at real records; use placeholders in shared prompts."),
+		ParsedPhones:    EnhancedPhoneNumbers{Value: base.ParsedPhones, Scale: "List (start/end byte offsets, E.164)", HelpText: "Phone numbers normalized to E.164 with a country guess. Numbers without a calling code are read in the default region (phone_region, US unless set); international numbers that fit no plan are kept with valid=false.", PracticalApplication: "Mask personal numbers before sharing; use e164 to deduplicate differently formatted numbers."},
+		Temporal:        EnhancedTemporal{Value: base.Temporal, Scale: "List (start/end byte offsets, ISO 8601)", HelpText: "Dates, times and durations such as \"March 3rd, 2025\", \"next Tuesday at 3pm\" or \"for 2 hours\", normalized to ISO 8601. Relative expressions are resolved against reference_time (the time of analysis unless set) and left without a value in deterministic runs.", PracticalApplication: "Check deadlines resolve to the dates you mean; state absolute dates when the prompt may be read later."},
+		ParsedURLs:      EnhancedURLs{Value: base.ParsedURLs, Scale: "List (start/end byte offsets, components)", HelpText: "URLs split into scheme, host, path, query and fragment, classified as docs, repo, tracker, social or other. duplicate marks a URL seen earlier; credentials names the userinfo or query parameter carrying a secret.", PracticalApplication: "Remove tokens and passwords from URLs before sharing, and link each resource once."},
+		Emoji:           EnhancedEmoji{Value: base.Emoji, Scale: "Occurrences (start/end byte offsets) and counts; sentiment -1 to 1", HelpText: "Unicode emoji, including skin-tone, flag and joined sequences, and ASCII emoticons, with names, categories, counts and the sentiment they carry.", PracticalApplication: "Emoji rarely help a model follow instructions; set strip_emoji to drop them from the cleaned text."},
+		CurrencyAmounts: detections(base.CurrencyAmounts, "Amounts with a currency symbol or ISO code; invalid when thousands separators are misplaced.", "State the currency explicitly when amounts matter to the task."),
 	}
 }
 
-func assessEnhancedQuality(text string) EnhancedQualityAssessment {
-	base := assessQuality(text)
+func assessEnhancedQuality(text string, speller *SpellChecker, inclusive *InclusiveLanguageChecker) EnhancedQualityAssessment {
+	base := assessQuality(text, speller, inclusive)
 	return EnhancedQualityAssessment{
 		ReadabilityScore:  NewEnhancedFloatMetric(base.ReadabilityScore, "0-1 (Higher = Easier)", "Heuristic readability based on sentence length.", "Target 0.6-0.8 for general audiences."),
 		CoherenceScore:    NewEnhancedFloatMetric(base.CoherenceScore, "0-1", "Heuristic coherence based on discourse markers.", "Use to identify transitions and logical flow."),
 		CompletenessScore: NewEnhancedFloatMetric(base.CompletenessScore, "0-1", "Heuristic completeness based on length/sentences.", "Flag very short inputs for insufficiency."),
 		QualityIssues: EnhancedQualityIssues{Value: base.QualityIssues, Scale: "List", HelpText: "Detected issues in formatting/punctuation.", PracticalApplication: "Address medium/high severity issues first."},
-		SpellingErrors: EnhancedSpellingErrors{Value: base.SpellingErrors, Scale: "List", HelpText: "Common misspellings detected.", PracticalApplication: "Offer corrections or auto-fix in UI."},
+		SpellingErrors: EnhancedSpellingErrors{Value: base.SpellingErrors, Scale: "List", HelpText: "Words not in the dictionary that are close to a known word, with suggested corrections.", PracticalApplication: "Offer corrections or auto-fix in UI."},
 		GrammarIssues:  EnhancedGrammarIssues{Value: base.GrammarIssues, Scale: "List", HelpText: "Detected grammar patterns (heuristic).", PracticalApplication: "Highlight for user review."},
 		StyleSuggestions: EnhancedStyleSuggestions{Value: base.StyleSuggestions, Scale: "List", HelpText: "Suggestions to improve style.", PracticalApplication: "Guide users toward clearer, more active writing."},
+		PassiveVoiceRatio: NewEnhancedFloatMetric(base.PassiveVoice.Ratio, "0-1 (Lower = More Direct)", "Share of sentences with a passive construction, including irregular participles such as \"was written\".", "Keep below 0.2 for instructions; passive steps hide who should act."),
+		ConcisenessScore:  NewEnhancedFloatMetric(base.Conciseness.Score, "0-100 (Higher = Tighter)", "How much of the text is clichés, filler phrases and wordy constructions such as \"in order to\".", "Aim for 90+; each style suggestion with a replacement shows the tighter wording.").WithMethodology("Formula: 100 - 4 × (percent of words the replacements save) - 5 × clichés"),
 	}
 }
 
@@ -407,69 +495,75 @@ func createEnhancedTransformationLog(steps []TransformStep) EnhancedTransformati
 	return EnhancedTransformationLog{
 		Value:               steps,
 		Scale:               "Ordered Steps",
-		HelpText:            "Sequence of transformations applied to the text.",
+		HelpText:            "Sequence of transformations applied to the text, each with its input and output length and a diff: insert, delete and replace ops with start/end byte offsets into the input, the new text and where it lands in the output (after_start).",
 		PracticalApplication: "Audit trail for explainability; helps debug preprocessing effects.",
 	}
 }
 
 func PreprocessText(text string) PreprocessingData {
+	return PreprocessTextWithOptions(text, PreprocessOptions{})
+}
+
+// PreprocessOptions replaces the English defaults used by preprocessing
+type PreprocessOptions struct {
+	Stopwords StopwordSet   // Words removed from the text; nil uses English
+	Speller   *SpellChecker // Spell checker for quality metrics; nil uses English
+	// Inclusive adds inclusive language style suggestions; nil skips the check
+	Inclusive *InclusiveLanguageChecker
+	// PhoneRegion reads phone numbers without a calling code; "" uses US
+	PhoneRegion string
+	// ReferenceTime resolves relative dates such as "next Tuesday"; the zero
+	// time leaves them unresolved
+	ReferenceTime time.Time
+	// Cleaning lists the cleaning steps to run in order, each logged as
+	// "cleaning:<step>"; nil runs DefaultCleaningSteps
+	Cleaning []string
+	// PreserveStructure keeps line and paragraph breaks through normalization
+	// and reports the flat and structured texts in StructuredText
+	PreserveStructure bool
+	// FullLog keeps every step's full input and output in the transformation
+	// log instead of a diff
+	FullLog bool
+}
+
+// PreprocessTextWithOptions preprocesses text with custom stopwords and spell checking
+func PreprocessTextWithOptions(text string, opts PreprocessOptions) PreprocessingData {
+	if opts.Stopwords == nil {
+		opts.Stopwords = stopWords
+	}
+	if opts.Speller == nil {
+		opts.Speller = defaultSpellChecker()
+	}
 	var transformationLog []TransformStep
 
 	originalText := text
-	transformationLog = append(transformationLog, TransformStep{
-		Step:        "original",
-		Before:      "",
-		After:       text,
-		Description: "Original input text",
-	})
+	transformationLog = append(transformationLog, newTransformStep("original", "Original input text", "", text, opts.FullLog))
 
-	cleanedText := cleanText(text)
-	transformationLog = append(transformationLog, TransformStep{
-		Step:        "cleaning",
-		Before:      text,
-		After:       cleanedText,
-		Description: "Removed unwanted characters and normalized whitespace",
+	cleanedText := runCleaningSteps(text, opts.Cleaning, func(name, description, before, after string) {
+		transformationLog = append(transformationLog, newTransformStep(cleaningStepName(name), description, before, after, opts.FullLog))
 	})
 
 	normalizedText := normalizeText(cleanedText)
-	transformationLog = append(transformationLog, TransformStep{
-		Step:        "normalization",
-		Before:      cleanedText,
-		After:       normalizedText,
-		Description: "Applied Unicode normalization and character standardization",
-	})
+	var structure *EnhancedTextStructure
+	if opts.PreserveStructure {
+		normalizedText = normalizeStructuredText(cleanedText)
+		structure = newEnhancedTextStructure(normalizedText)
+		transformationLog = append(transformationLog, newTransformStep("normalization", "Applied Unicode normalization and character standardization, keeping line and paragraph breaks", cleanedText, normalizedText, opts.FullLog))
+	} else {
+		transformationLog = append(transformationLog, newTransformStep("normalization", "Applied Unicode normalization and character standardization", cleanedText, normalizedText, opts.FullLog))
+	}
 
 	lowercaseText := strings.ToLower(normalizedText)
-	transformationLog = append(transformationLog, TransformStep{
-		Step:        "lowercase",
-		Before:      normalizedText,
-		After:       lowercaseText,
-		Description: "Converted to lowercase",
-	})
+	transformationLog = append(transformationLog, newTransformStep("lowercase", "Converted to lowercase", normalizedText, lowercaseText, opts.FullLog))
 
-	withoutStopWords := removeStopWords(lowercaseText)
-	transformationLog = append(transformationLog, TransformStep{
-		Step:        "stop_words_removal",
-		Before:      lowercaseText,
-		After:       withoutStopWords,
-		Description: "Removed common stop words",
-	})
+	withoutStopWords := removeStopWords(lowercaseText, opts.Stopwords)
+	transformationLog = append(transformationLog, newTransformStep("stop_words_removal", "Removed common stop words", lowercaseText, withoutStopWords, opts.FullLog))
 
 	stemmedText := stemText(withoutStopWords)
-	transformationLog = append(transformationLog, TransformStep{
-		Step:        "stemming",
-		Before:      withoutStopWords,
-		After:       stemmedText,
-		Description: "Applied word stemming",
-	})
+	transformationLog = append(transformationLog, newTransformStep("stemming", "Applied word stemming", withoutStopWords, stemmedText, opts.FullLog))
 
 	lemmatizedText := lemmatizeText(withoutStopWords)
-	transformationLog = append(transformationLog, TransformStep{
-		Step:        "lemmatization",
-		Before:      withoutStopWords,
-		After:       lemmatizedText,
-		Description: "Applied word lemmatization",
-	})
+	transformationLog = append(transformationLog, newTransformStep("lemmatization", "Applied word lemmatization", withoutStopWords, lemmatizedText, opts.FullLog))
 
 	return PreprocessingData{
 		OriginalText: NewEnhancedStringMetric(
@@ -518,44 +612,53 @@ func PreprocessText(text string) PreprocessingData {
 		LanguageDetection:   detectEnhancedLanguage(originalText),
 		EncodingInfo:        analyzeEnhancedEncoding(originalText),
 		TextNormalization:   performEnhancedNormalizationSteps(originalText),
-		ExtractionResults:   extractEnhancedInformation(originalText),
-		QualityMetrics:      assessEnhancedQuality(originalText),
+		ExtractionResults:   extractEnhancedInformation(originalText, opts),
+		QualityMetrics:      assessEnhancedQuality(originalText, opts.Speller, opts.Inclusive),
 		TransformationLog:   createEnhancedTransformationLog(transformationLog),
+		StructuredText:      structure,
 	}
 }
 
-func cleanText(text string) string {
-	text = regexp.MustCompile(`\r\n|\r|\n`).ReplaceAllString(text, " ")
-	text = regexp.MustCompile(`\s+`).ReplaceAllString(text, " ")
-	text = regexp.MustCompile(`[^\p{L}\p{N}\p{P}\p{S}\s]`).ReplaceAllString(text, "")
-	text = strings.TrimSpace(text)
-	return text
-}
+var (
+	lineBreakPattern      = regexp.MustCompile(`\r\n|\r|\n`)
+	nonPrintablePattern   = regexp.MustCompile(`[^\p{L}\p{N}\p{P}\p{S}\s]`)
+	paragraphBreakPattern = regexp.MustCompile(`\n\s*\n`)
+	fancyQuotePattern     = regexp.MustCompile(`[''"""''‚‛""„‟‹›«»]`)
+	dashVariantPattern    = regexp.MustCompile(`[–—−]`)
+	numericDatePattern    = regexp.MustCompile(`\d{1,2}[/-]\d{1,2}[/-]\d{2,4}`)
+	clockTimePattern      = regexp.MustCompile(`\d{1,2}:\d{2}(?::\d{2})?(?:\s?[AaPp][Mm])?`)
+	decimalNumberPattern  = regexp.MustCompile(`\b\d+(?:\.\d+)?\b`)
+	upperAbbrevPattern    = regexp.MustCompile(`\b[A-Z]{2,}\b`)
+	emoticonPattern       = regexp.MustCompile(`[:;]-?[)(\[\]{}|\\\/pP]`)
+	terminalPunctPattern  = regexp.MustCompile(`[.!?]\s*$`)
+	doubleNegativePattern = regexp.MustCompile(`\b(don't|won't|can't|shouldn't)\s+(no|nothing|nobody|never)\b`)
+)
 
 func normalizeText(text string) string {
-	var result strings.Builder
-
-	for _, char := range text {
-		if unicode.IsSpace(char) {
-			result.WriteRune(' ')
-		} else if unicode.IsPrint(char) {
-			result.WriteRune(char)
+	normalized := outsideEmoji(text, func(s string) string {
+		var result strings.Builder
+		for _, char := range s {
+			if unicode.IsSpace(char) {
+				result.WriteRune(' ')
+			} else if unicode.IsPrint(char) {
+				result.WriteRune(char)
+			}
 		}
-	}
+		return result.String()
+	})
 
-	normalized := result.String()
-	normalized = regexp.MustCompile(`\s+`).ReplaceAllString(normalized, " ")
+	normalized = whitespaceRunPattern.ReplaceAllString(normalized, " ")
 	normalized = strings.TrimSpace(normalized)
 
 	return normalized
 }
 
-func removeStopWords(text string) string {
+func removeStopWords(text string, stop StopwordSet) string {
 	words := strings.Fields(text)
 	var filtered []string
 
 	for _, word := range words {
-		if !stopWords[strings.ToLower(word)] {
+		if !stop.Contains(word) {
 			filtered = append(filtered, word)
 		}
 	}
@@ -638,7 +741,7 @@ func calculateTextStats(original, cleaned string) TextStats {
 	}
 
 	lines := strings.Count(original, "\n") + 1
-	paragraphs := len(regexp.MustCompile(`\n\s*\n`).Split(original, -1))
+	paragraphs := len(paragraphBreakPattern.Split(original, -1))
 
 	var compressionRatio float64
 	if originalLen > 0 {
@@ -695,8 +798,8 @@ func detectLanguage(text string) LanguageInfo {
 
 	primaryLang := "en"
 	maxCount := 0
-	for lang, count := range langCount {
-		if count > maxCount {
+	for _, lang := range sortedKeys(langCount) {
+		if count := langCount[lang]; count > maxCount {
 			maxCount = count
 			primaryLang = lang
 		}
@@ -714,7 +817,10 @@ func detectLanguage(text string) LanguageInfo {
 	}
 
 	sort.Slice(alternatives, func(i, j int) bool {
-		return alternatives[i].Confidence > alternatives[j].Confidence
+		if alternatives[i].Confidence != alternatives[j].Confidence {
+			return alternatives[i].Confidence > alternatives[j].Confidence
+		}
+		return alternatives[i].Language < alternatives[j].Language
 	})
 
 	confidence := float64(maxCount) / float64(len(words))
@@ -760,15 +866,15 @@ func analyzeEncoding(text string) EncodingAnalysis {
 func performNormalizationSteps(text string) NormalizationSteps {
 	unicodeNormalized := text
 
-	whitespaceNormalized := regexp.MustCompile(`\s+`).ReplaceAllString(text, " ")
+	whitespaceNormalized := whitespaceRunPattern.ReplaceAllString(text, " ")
 	whitespaceNormalized = strings.TrimSpace(whitespaceNormalized)
 
 	caseNormalized := strings.ToLower(text)
 
-	punctuationNormalized := regexp.MustCompile(`[''"""''‚‛""„‟‹›«»]`).ReplaceAllString(text, "'")
-	punctuationNormalized = regexp.MustCompile(`[–—−]`).ReplaceAllString(punctuationNormalized, "-")
+	punctuationNormalized := fancyQuotePattern.ReplaceAllString(text, "'")
+	punctuationNormalized = dashVariantPattern.ReplaceAllString(punctuationNormalized, "-")
 
-	numbersNormalized := regexp.MustCompile(`\d+`).ReplaceAllString(text, "<NUM>")
+	numbersNormalized := digitsPattern.ReplaceAllString(text, "<NUM>")
 
 	accentsRemoved := text
 	accentMap := map[rune]rune{
@@ -800,22 +906,29 @@ func performNormalizationSteps(text string) NormalizationSteps {
 	}
 }
 
-func extractInformation(text string) ExtractionData {
-	urlRegex := regexp.MustCompile(`https?://[^\s]+`)
-	emailRegex := regexp.MustCompile(`[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}`)
-	phoneRegex := regexp.MustCompile(`\+?[\d\s\-\(\)]{10,}`)
-	dateRegex := regexp.MustCompile(`\d{1,2}[/-]\d{1,2}[/-]\d{2,4}`)
-	timeRegex := regexp.MustCompile(`\d{1,2}:\d{2}(?::\d{2})?(?:\s?[AaPp][Mm])?`)
-	numberRegex := regexp.MustCompile(`\b\d+(?:\.\d+)?\b`)
-	abbreviationRegex := regexp.MustCompile(`\b[A-Z]{2,}\b`)
-	hashtagRegex := regexp.MustCompile(`#\w+`)
-	mentionRegex := regexp.MustCompile(`@\w+`)
-	emoticonRegex := regexp.MustCompile(`[:;]-?[)(\[\]{}|\\\/pP]`)
+func extractInformation(text string, opts PreprocessOptions) ExtractionData {
+	urlRegex := urlPattern
+	emailRegex := emailPattern
+	dateRegex := numericDatePattern
+	timeRegex := clockTimePattern
+	numberRegex := decimalNumberPattern
+	abbreviationRegex := upperAbbrevPattern
+	hashtagRegex := hashtagPattern
+	mentionRegex := atMentionPattern
+	emoticonRegex := emoticonPattern
+
+	phones := ParsePhoneNumbers(text, opts.PhoneRegion)
+	phoneTexts := []string{}
+	for _, p := range phones {
+		if p.Valid {
+			phoneTexts = append(phoneTexts, p.Text)
+		}
+	}
 
 	return ExtractionData{
 		URLs:            urlRegex.FindAllString(text, -1),
 		EmailAddresses:  emailRegex.FindAllString(text, -1),
-		PhoneNumbers:    phoneRegex.FindAllString(text, -1),
+		PhoneNumbers:    phoneTexts,
 		Dates:           dateRegex.FindAllString(text, -1),
 		Times:           timeRegex.FindAllString(text, -1),
 		Numbers:         numberRegex.FindAllString(text, -1),
@@ -825,10 +938,20 @@ func extractInformation(text string) ExtractionData {
 		Mentions:        mentionRegex.FindAllString(text, -1),
 		EmoticonsSmiley: emoticonRegex.FindAllString(text, -1),
 		SpecialTokens:   []string{},
+		IBANs:           detectIBANs(text),
+		CreditCards:     detectCreditCards(text),
+		IPAddresses:     detectIPAddresses(text),
+		FilePaths:       detectFilePaths(text),
+		UUIDs:           detectUUIDs(text),
+		CurrencyAmounts: detectCurrencyAmounts(text),
+		ParsedPhones:    phones,
+		Temporal:        ExtractTemporalExpressions(text, opts.ReferenceTime),
+		ParsedURLs:      AnalyzeURLs(text),
+		Emoji:           AnalyzeEmoji(text),
 	}
 }
 
-func assessQuality(text string) QualityAssessment {
+func assessQuality(text string, speller *SpellChecker, inclusive *InclusiveLanguageChecker) QualityAssessment {
 	words := strings.Fields(text)
 	sentences := extractSentences(text)
 
@@ -837,9 +960,11 @@ func assessQuality(text string) QualityAssessment {
 	completenessScore := calculateCompletenessScore(text)
 
 	qualityIssues := findQualityIssues(text)
-	spellingErrors := findSpellingErrors(words)
+	spellingErrors := speller.Check(text)
 	grammarIssues := findGrammarIssues(text)
-	styleSuggestions := findStyleSuggestions(text)
+	passiveVoice := DetectPassiveVoice(text)
+	conciseness := AnalyzeConciseness(text)
+	styleSuggestions := findStyleSuggestions(passiveVoice, conciseness, inclusive.Check(text))
 
 	return QualityAssessment{
 		ReadabilityScore:  readabilityScore,
@@ -849,6 +974,8 @@ func assessQuality(text string) QualityAssessment {
 		SpellingErrors:    spellingErrors,
 		GrammarIssues:     grammarIssues,
 		StyleSuggestions:  styleSuggestions,
+		PassiveVoice:      passiveVoice,
+		Conciseness:       conciseness,
 	}
 }
 
@@ -870,25 +997,10 @@ func calculateReadabilityScore(words []string, sentences []string) float64 {
 	}
 }
 
+// calculateCoherenceScore rates how well sentences are linked by discourse
+// markers; see AnalyzeDiscourse
 func calculateCoherenceScore(text string) float64 {
-	sentences := extractSentences(text)
-	if len(sentences) <= 1 {
-		return 1.0
-	}
-
-	coherenceIndicators := []string{"however", "therefore", "moreover", "furthermore", "additionally", "consequently"}
-	indicatorCount := 0
-
-	for _, sentence := range sentences {
-		for _, indicator := range coherenceIndicators {
-			if strings.Contains(strings.ToLower(sentence), indicator) {
-				indicatorCount++
-				break
-			}
-		}
-	}
-
-	return float64(indicatorCount) / float64(len(sentences))
+	return discourseCoherence(AnalyzeDiscourse(text))
 }
 
 func calculateCompletenessScore(text string) float64 {
@@ -921,7 +1033,7 @@ func findQualityIssues(text string) []QualityIssue {
 		})
 	}
 
-	if !regexp.MustCompile(`[.!?]\s*$`).MatchString(text) {
+	if !terminalPunctPattern.MatchString(text) {
 		issues = append(issues, QualityIssue{
 			Type:        "punctuation",
 			Description: "Text does not end with proper punctuation",
@@ -934,36 +1046,10 @@ func findQualityIssues(text string) []QualityIssue {
 	return issues
 }
 
-func findSpellingErrors(words []string) []SpellingError {
-	var errors []SpellingError
-	commonMisspellings := map[string][]string{
-		"recieve":    {"receive"},
-		"seperate":   {"separate"},
-		"definately": {"definitely"},
-		"occured":    {"occurred"},
-		"neccessary": {"necessary"},
-	}
-
-	position := 0
-	for _, word := range words {
-		cleanWord := strings.ToLower(regexp.MustCompile(`[^\w]`).ReplaceAllString(word, ""))
-		if suggestions, exists := commonMisspellings[cleanWord]; exists {
-			errors = append(errors, SpellingError{
-				Word:        word,
-				Position:    position,
-				Suggestions: suggestions,
-			})
-		}
-		position += len(word) + 1
-	}
-
-	return errors
-}
-
 func findGrammarIssues(text string) []GrammarIssue {
 	var issues []GrammarIssue
 
-	doubleNegatives := regexp.MustCompile(`\b(don't|won't|can't|shouldn't)\s+(no|nothing|nobody|never)\b`)
+	doubleNegatives := doubleNegativePattern
 	matches := doubleNegatives.FindAllStringIndex(text, -1)
 
 	for _, match := range matches {
@@ -980,21 +1066,32 @@ func findGrammarIssues(text string) []GrammarIssue {
 	return issues
 }
 
-func findStyleSuggestions(text string) []StyleSuggestion {
+// findStyleSuggestions merges passive voice, conciseness and inclusive
+// language suggestions in text order
+func findStyleSuggestions(passive PassiveVoiceAnalysis, conciseness ConcisenessAnalysis, inclusive []StyleSuggestion) []StyleSuggestion {
 	var suggestions []StyleSuggestion
 
-	passiveVoice := regexp.MustCompile(`\b(was|were|is|are)\s+\w+ed\b`)
-	matches := passiveVoice.FindAllStringIndex(text, -1)
-
-	for _, match := range matches {
+	for _, c := range passive.Constructions {
+		suggestion := "Consider using active voice"
+		if c.Agent != "" {
+			suggestion = fmt.Sprintf("Consider active voice with %q as the subject", c.Agent)
+		}
 		suggestions = append(suggestions, StyleSuggestion{
-			Text:       text[match[0]:match[1]],
-			Position:   match[0],
-			Length:     match[1] - match[0],
-			Suggestion: "Consider using active voice",
+			Text:       c.Text,
+			Position:   c.Position,
+			Length:     c.Length,
+			Suggestion: suggestion,
 			Reason:     "Active voice is generally more direct and engaging",
+			Kind:       "passive_voice",
 		})
 	}
+	for _, f := range conciseness.Findings {
+		suggestions = append(suggestions, concisenessSuggestion(f))
+	}
+	suggestions = append(suggestions, inclusive...)
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return suggestions[i].Position < suggestions[j].Position
+	})
 
 	return suggestions
 }
@@ -1,7 +1,9 @@
 package analyzer
 
 import (
+	"math"
 	"regexp"
+	"sort"
 	"strings"
 )
 
@@ -19,18 +21,55 @@ const (
 	General        PromptType = "general"
 )
 
+// promptTypeOrder ranks prompt types on equal scores, most specific first
+var promptTypeOrder = []PromptType{CodeGeneration, TechnicalSpec, DataAnalysis, CreativeTask, Writing, ProblemSolving, Learning, General}
+
+// Multi-label blending keeps up to this many labels holding at least this
+// share of the classification evidence
+const (
+	blendLabelLimit    = 3
+	blendMinConfidence = 0.2
+)
+
 // PromptClassification contains the detected prompt type and confidence
 type PromptClassification struct {
-	PrimaryType   PromptType `json:"primary_type"`
-	SecondaryType PromptType `json:"secondary_type,omitempty"`
-	Confidence    float64    `json:"confidence"`
-	Reasoning     string     `json:"reasoning"`
-	Keywords      []string   `json:"keywords"`
+	PrimaryType   PromptType    `json:"primary_type"`
+	SecondaryType PromptType    `json:"secondary_type,omitempty"`
+	Confidence    float64       `json:"confidence"`
+	Reasoning     string        `json:"reasoning"`
+	Keywords      []string      `json:"keywords"`
+	Labels        []PromptLabel `json:"labels"`              // Every matching type, highest score first
+	BaseType      PromptType    `json:"base_type,omitempty"` // Built-in type a trained primary type is graded as
+}
+
+// GradingType is the built-in type whose grading rules apply to the prompt
+func (c PromptClassification) GradingType() PromptType {
+	if c.BaseType != "" {
+		return c.BaseType
+	}
+	return c.PrimaryType
+}
+
+// PromptLabel is one prompt type with its share of the classification evidence
+type PromptLabel struct {
+	Type       PromptType `json:"type"`
+	Base       PromptType `json:"base,omitempty"` // Built-in type a trained category is graded as
+	Score      float64    `json:"score"`          // Raw pattern score
+	Confidence float64    `json:"confidence"`     // Share of the summed scores, 0-1
+}
+
+// gradingType is the built-in type whose grading rules apply to the label
+func (l PromptLabel) gradingType() PromptType {
+	if l.Base != "" {
+		return l.Base
+	}
+	return l.Type
 }
 
 // PromptClassifier analyzes prompts to determine their type and context
 type PromptClassifier struct {
 	patterns map[PromptType][]ClassificationPattern
+	trained  []TrainedCategory // User categories from TrainClassifier
 }
 
 // ClassificationPattern defines keywords and rules for identifying prompt types
@@ -45,8 +84,8 @@ type ClassificationPattern struct {
 // containsWord checks if a word appears as a whole token (case-insensitive)
 func containsWord(text, word string) bool {
 	if word == "" { return false }
-	pattern := `(?i)\b` + regexp.QuoteMeta(word) + `\b`
-	matched, _ := regexp.MatchString(pattern, text)
+	re := cachedRegexp(`(?i)\b` + regexp.QuoteMeta(word) + `\b`)
+	matched := re != nil && re.MatchString(text)
 	return matched
 }
 
@@ -133,6 +172,18 @@ func NewPromptClassifier() *PromptClassifier {
 	}
 }
 
+// NewPromptClassifierWithModel creates the built-in classifier extended with
+// trained categories. A category named after a built-in type replaces that
+// type's patterns; any other label becomes a new type graded as its base.
+func NewPromptClassifierWithModel(model ClassifierModel) *PromptClassifier {
+	pc := NewPromptClassifier()
+	for _, c := range model.Categories {
+		delete(pc.patterns, PromptType(c.Label))
+		pc.trained = append(pc.trained, c)
+	}
+	return pc
+}
+
 // ClassifyPrompt analyzes a prompt and determines its primary type
 func (pc *PromptClassifier) ClassifyPrompt(text string) PromptClassification {
 	text = strings.ToLower(text)
@@ -164,7 +215,7 @@ func (pc *PromptClassifier) ClassifyPrompt(text string) PromptClassification {
 			
 			// Check regex patterns
 			for _, regexPattern := range pattern.RegexList {
-				if matched, _ := regexp.MatchString(regexPattern, text); matched {
+				if re := cachedRegexp(regexPattern); re != nil && re.MatchString(text) {
 					patternScore += 3.0 // Regex matches are most significant
 				}
 			}
@@ -174,27 +225,65 @@ func (pc *PromptClassifier) ClassifyPrompt(text string) PromptClassification {
 		
 		scores[promptType] = totalScore
 	}
+
+	// Trained categories score by similarity to their examples
+	bases := map[PromptType]PromptType{}
+	if len(pc.trained) > 0 {
+		terms := classifierTerms(text)
+		for _, c := range pc.trained {
+			score, hits := trainedScore(terms, c)
+			scores[PromptType(c.Label)] = score
+			if !builtinPromptTypes[PromptType(c.Label)] {
+				bases[PromptType(c.Label)] = c.Base
+			}
+			for _, k := range hits {
+				allKeywords[k] = true
+			}
+		}
+	}
 	
-	// Find primary and secondary types
-	var primaryType, secondaryType PromptType
-	var primaryScore, secondaryScore float64
-	
+	// Rank types by score; ties go to trained categories, then follow
+	// promptTypeOrder so the result is stable
+	rank := map[PromptType]int{}
+	for i, pt := range promptTypeOrder {
+		rank[pt] = i + 1
+	}
+	labels := []PromptLabel{}
+	total := 0.0
 	for promptType, score := range scores {
-		if score > primaryScore {
-			secondaryType = primaryType
-			secondaryScore = primaryScore
-			primaryType = promptType
-			primaryScore = score
-		} else if score > secondaryScore {
-			secondaryType = promptType
-			secondaryScore = score
+		if score > 0 {
+			labels = append(labels, PromptLabel{Type: promptType, Score: score})
+			total += score
+		}
+	}
+	sort.Slice(labels, func(i, j int) bool {
+		if labels[i].Score != labels[j].Score {
+			return labels[i].Score > labels[j].Score
 		}
+		if rank[labels[i].Type] != rank[labels[j].Type] {
+			return rank[labels[i].Type] < rank[labels[j].Type]
+		}
+		return labels[i].Type < labels[j].Type
+	})
+	for i := range labels {
+		labels[i].Confidence = math.Round(labels[i].Score/total*1000) / 1000
+		labels[i].Base = bases[labels[i].Type]
+	}
+
+	var primaryType, secondaryType PromptType
+	var primaryScore, secondaryScore float64
+	if len(labels) > 0 {
+		primaryType, primaryScore = labels[0].Type, labels[0].Score
+	}
+	if len(labels) > 1 {
+		secondaryType, secondaryScore = labels[1].Type, labels[1].Score
 	}
 	
 	// Default to general if no clear classification
 	if primaryScore == 0 {
 		primaryType = General
 		primaryScore = 1.0
+		labels = []PromptLabel{{Type: General, Score: 1, Confidence: 1}}
 	}
 	
 	// Calculate confidence based on score separation
@@ -212,6 +301,7 @@ func (pc *PromptClassifier) ClassifyPrompt(text string) PromptClassification {
 	for keyword := range allKeywords {
 		keywordsList = append(keywordsList, keyword)
 	}
+	sort.Strings(keywordsList)
 	
 	// Generate reasoning
 	reasoning := pc.generateReasoning(primaryType, primaryScore, keywordsList)
@@ -222,7 +312,32 @@ func (pc *PromptClassifier) ClassifyPrompt(text string) PromptClassification {
 		Confidence:    confidence,
 		Reasoning:     reasoning,
 		Keywords:      keywordsList,
+		Labels:        labels,
+		BaseType:      bases[primaryType],
+	}
+}
+
+// BlendLabels returns the labels grading should blend, with confidences
+// renormalized to sum to 1: the primary type plus up to two more that each
+// hold at least a fifth of the evidence. A prompt that is mostly code but
+// also asks for analysis is graded partly as data analysis.
+func (c PromptClassification) BlendLabels() []PromptLabel {
+	blend := []PromptLabel{}
+	total := 0.0
+	for i, l := range c.Labels {
+		if i >= blendLabelLimit || (i > 0 && l.Confidence < blendMinConfidence) {
+			break
+		}
+		blend = append(blend, l)
+		total += l.Confidence
 	}
+	if len(blend) == 0 || total == 0 {
+		return []PromptLabel{{Type: c.PrimaryType, Base: c.BaseType, Score: 1, Confidence: 1}}
+	}
+	for i := range blend {
+		blend[i].Confidence /= total
+	}
+	return blend
 }
 
 // generateReasoning creates human-readable explanation for the classification
@@ -238,7 +353,10 @@ func (pc *PromptClassifier) generateReasoning(promptType PromptType, score float
 		General:        "General-purpose prompt without specific domain focus",
 	}
 	
-	reason := baseReasons[promptType]
+	reason, ok := baseReasons[promptType]
+	if !ok {
+		reason = "Resembles the examples of the trained \"" + string(promptType) + "\" category"
+	}
 	if len(keywords) > 0 {
 		reason += " (detected keywords: " + strings.Join(keywords[:minInt(3, len(keywords))], ", ") + ")"
 	}
@@ -266,7 +384,10 @@ func GetPromptTypeDisplayName(pt PromptType) string {
 		Learning:       "Learning & Education",
 		General:        "General Purpose",
 	}
-	return names[pt]
+	if name, ok := names[pt]; ok {
+		return name
+	}
+	return string(pt) // Trained categories are shown by label
 }
 
 // GetPromptTypeIcon returns emoji icon for prompt type
@@ -281,5 +402,8 @@ func GetPromptTypeIcon(pt PromptType) string {
 		Learning:       "🎓",
 		General:        "📝",
 	}
-	return icons[pt]
+	if icon, ok := icons[pt]; ok {
+		return icon
+	}
+	return icons[General]
 }
\ No newline at end of file
@@ -0,0 +1,43 @@
+package analyzer
+
+import "testing"
+
+func TestMultiLabelClassification(t *testing.T) {
+	classifier := NewPromptClassifier()
+	text := "Write a Python function that loads the sales data, computes monthly metrics and trends, and plots a dashboard."
+	c := classifier.ClassifyPrompt(text)
+
+	if len(c.Labels) < 2 || c.Labels[0].Type != c.PrimaryType || c.Labels[1].Type != c.SecondaryType {
+		t.Fatalf("expected ranked labels led by the primary and secondary types, got %+v", c.Labels)
+	}
+	sum := 0.0
+	for i, l := range c.Labels {
+		sum += l.Confidence
+		if i > 0 && l.Score > c.Labels[i-1].Score {
+			t.Errorf("labels out of order: %+v", c.Labels)
+		}
+	}
+	if sum < 0.99 || sum > 1.01 {
+		t.Errorf("expected label confidences to sum to 1, got %.3f", sum)
+	}
+	types := map[PromptType]bool{}
+	for _, l := range c.Labels {
+		types[l.Type] = true
+	}
+	if !types[CodeGeneration] || !types[DataAnalysis] {
+		t.Errorf("expected both code generation and data analysis labels, got %+v", c.Labels)
+	}
+
+	blend := c.BlendLabels()
+	total := 0.0
+	for _, l := range blend {
+		total += l.Confidence
+	}
+	if len(blend) < 2 || len(blend) > blendLabelLimit || total < 0.99 || total > 1.01 {
+		t.Errorf("expected 2-%d blended labels summing to 1, got %+v", blendLabelLimit, blend)
+	}
+
+	if general := classifier.ClassifyPrompt("Hello there."); len(general.Labels) != 1 || general.Labels[0].Type != General {
+		t.Errorf("expected a lone general label, got %+v", general.Labels)
+	}
+}
@@ -0,0 +1,150 @@
+package analyzer
+
+import (
+	"regexp"
+	"strings"
+)
+
+// RoleStatement is a persona or audience the prompt sets up
+type RoleStatement struct {
+	Span    Span   `json:"span"`
+	Role    string `json:"role"`    // e.g. "senior tax lawyer", "non-technical executives"
+	Generic bool   `json:"generic"` // Persona adds nothing specific, e.g. "a helpful assistant"
+}
+
+// PromptComponents are the framing parts of a prompt beyond the task itself
+type PromptComponents struct {
+	Personas    []RoleStatement `json:"personas"`  // Who the model should be
+	Audiences   []RoleStatement `json:"audiences"` // Who the output is for
+	HasPersona  bool            `json:"has_persona"`
+	HasAudience bool            `json:"has_audience"`
+	RoleScore   float64         `json:"role_score"` // 0-100 credit for a specific persona and audience
+}
+
+var (
+	// "You are a senior tax lawyer", "Act as my editor", "Role: data engineer"
+	personaPattern = regexp.MustCompile(`(?im)(?:\b(?:you are|you're|act as|acting as|pretend (?:to be|you are)|imagine you are|(?:play|take on|assume) the role of|your role is(?: that of)?)\s+(?:an?|the|my|our|a seasoned|an experienced)\s+|^\s*(?:role|persona)\s*:\s*(?:an?\s+|the\s+)?)([^.,;:!?\n]+)`)
+	// "written for non-technical executives", "Audience: new hires"
+	audiencePattern = regexp.MustCompile(`(?im)(?:\b(?:(?:written|aimed|intended|tailored|geared|targeted|pitched) (?:for|at|towards?)|(?:target )?audience (?:is|are|of|will be)|readers? (?:is|are|will be))\s+(?:an?\s+|the\s+|our\s+|my\s+)?|^\s*(?:target )?(?:audience|readers?)\s*:\s*)([^.,;:!?\n]+)`)
+	// "explain recursion to a ten-year-old", "pitch it to our investors"
+	audienceToPattern = regexp.MustCompile(`(?i)\b(?:explain|describe|present|pitch|teach)\b(?:\s+[\w'-]+){0,4}?\s+to\s+(?:an?|the|my|our|your)\s+([^.,;:!?\n]+)`)
+	// "for beginners", "for a non-technical audience" name readers without a cue verb
+	audienceForPattern = regexp.MustCompile(`(?i)\bfor\s+(?:an?\s+|the\s+|our\s+)?((?:[\w-]+\s+){0,3}?(?:beginners|novices|experts|executives|stakeholders|managers|developers|engineers|students|children|kids|customers|clients|users|readers|audiences?|newcomers|investors|recruiters|non-experts|laypeople|teenagers|parents|teachers|doctors|patients))\b`)
+	// roleVerbPhrasePattern finds "and <verb> <determiner>" after the role
+	roleVerbPhrasePattern = regexp.MustCompile(`(?i)\s+and\s+\w+\s+(?:this|that|these|the|my|our|your|a|an|it|them)\b`)
+	// roleClausePattern trims a trailing relative clause from a role
+	roleClausePattern = regexp.MustCompile(`(?i)\s+(?:who|that|which|with|specializing|working|helping|and you|to help)\b.*$`)
+)
+
+// genericPersonas are assistant personas that add no expertise
+var genericPersonas = map[string]bool{
+	"assistant": true, "helpful assistant": true, "ai": true, "ai assistant": true, "chatbot": true,
+	"language model": true, "large language model": true, "helpful ai assistant": true, "bot": true,
+}
+
+// roleWeights is how much a persona and audience count toward context for
+// each prompt type: voice and reader shape creative and writing work, while
+// code and analysis are judged on the spec
+var roleWeights = map[PromptType]float64{
+	CreativeTask:   0.20,
+	Writing:        0.20,
+	Learning:       0.15,
+	General:        0.10,
+	ProblemSolving: 0.10,
+	DataAnalysis:   0.05,
+	TechnicalSpec:  0.05,
+	CodeGeneration: 0.05,
+}
+
+// blendedRoleWeight averages roleWeights over blended classification labels
+func blendedRoleWeight(labels []PromptLabel) float64 {
+	w := 0.0
+	for _, l := range labels {
+		w += roleWeights[l.gradingType()] * l.Confidence
+	}
+	return w
+}
+
+// DetectPromptComponents finds persona assignments and audience statements.
+// RoleScore gives a specific persona 60 points (a generic assistant persona
+// 35) and an audience 40.
+func DetectPromptComponents(text string) PromptComponents {
+	components := PromptComponents{Personas: []RoleStatement{}, Audiences: []RoleStatement{}}
+
+	for _, m := range personaPattern.FindAllStringSubmatchIndex(text, -1) {
+		if role, ok := roleText(text[m[2]:m[3]]); ok {
+			lower := strings.ToLower(role)
+			components.Personas = append(components.Personas, RoleStatement{
+				Span:    newSpan(text, skipBlanks(text, m[0]), m[2]+len(role)),
+				Role:    role,
+				Generic: genericPersonas[lower] || genericPersonas[strings.TrimPrefix(lower, "helpful ")],
+			})
+		}
+	}
+
+	seen := map[int]bool{} // Audience starts already reported
+	for _, re := range []*regexp.Regexp{audiencePattern, audienceToPattern, audienceForPattern} {
+		for _, m := range re.FindAllStringSubmatchIndex(text, -1) {
+			role, ok := roleText(text[m[2]:m[3]])
+			if !ok || seen[m[2]] {
+				continue
+			}
+			seen[m[2]] = true
+			components.Audiences = append(components.Audiences, RoleStatement{
+				Span: newSpan(text, skipBlanks(text, m[0]), m[2]+len(role)),
+				Role: role,
+			})
+		}
+	}
+
+	components.HasPersona = len(components.Personas) > 0
+	components.HasAudience = len(components.Audiences) > 0
+	for _, p := range components.Personas {
+		if !p.Generic {
+			components.RoleScore = 60
+			break
+		}
+		components.RoleScore = 35
+	}
+	if components.HasAudience {
+		components.RoleScore += 40
+	}
+	return components
+}
+
+// nonRoleHeads open captures that are quantities, not roles ("you are a bit
+// too terse", "act as a set of checks")
+var nonRoleHeads = map[string]bool{
+	"bit": true, "little": true, "lot": true, "few": true, "couple": true, "number": true,
+	"set": true, "series": true, "piece": true, "part": true, "list": true,
+}
+
+// roleText trims a captured role to its noun phrase, dropping relative
+// clauses ("who explains...") and rejecting captures that are not roles
+func roleText(capture string) (string, bool) {
+	role := strings.TrimSpace(roleClausePattern.ReplaceAllString(capture, ""))
+	// "my editor and tighten this draft": "and" plus a verb phrase ends the role
+	if m := roleVerbPhrasePattern.FindStringIndex(role); m != nil {
+		role = role[:m[0]]
+	}
+	words := strings.Fields(role)
+	if len(words) == 0 || len(words) > 8 || nonRoleHeads[strings.ToLower(words[0])] {
+		return "", false
+	}
+	return role, true
+}
+
+// skipBlanks moves a match start past the whitespace a multiline pattern
+// may include before a "Role:" label
+func skipBlanks(text string, start int) int {
+	for start < len(text) && (text[start] == ' ' || text[start] == '\t' || text[start] == '\n') {
+		start++
+	}
+	return start
+}
+
+// roleFactorScore blends the role score with a floor so prompts without a
+// persona are not zeroed on a factor that matters little for their type
+func roleFactorScore(components PromptComponents) float64 {
+	return 40 + components.RoleScore*0.6
+}
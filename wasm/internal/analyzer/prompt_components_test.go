@@ -0,0 +1,69 @@
+package analyzer
+
+import "testing"
+
+func TestDetectPromptComponents(t *testing.T) {
+	text := "You are a senior tax lawyer who explains rules plainly. Write a memo for non-technical executives."
+	got := DetectPromptComponents(text)
+	if len(got.Personas) != 1 || got.Personas[0].Role != "senior tax lawyer" || got.Personas[0].Generic {
+		t.Fatalf("expected the tax lawyer persona, got %+v", got.Personas)
+	}
+	if span := got.Personas[0].Span; span.Text != "You are a senior tax lawyer" || text[span.Start:span.End] != span.Text {
+		t.Errorf("unexpected persona span %+v", span)
+	}
+	if len(got.Audiences) != 1 || got.Audiences[0].Role != "non-technical executives" {
+		t.Errorf("expected the executives audience, got %+v", got.Audiences)
+	}
+	if !got.HasPersona || !got.HasAudience || got.RoleScore != 100 {
+		t.Errorf("expected full role credit, got %+v", got)
+	}
+
+	cases := []struct {
+		text              string
+		persona, audience string
+		generic           bool
+	}{
+		{"Act as my editor and tighten this draft.", "editor", "", false},
+		{"You are a product and design lead.", "product and design lead", "", false},
+		{"Role: data engineer\nAudience: new hires\nDescribe the pipeline.", "data engineer", "new hires", false},
+		{"You are a helpful assistant. Explain recursion to a ten-year-old.", "helpful assistant", "ten-year-old", true},
+		{"You are a bit too verbose. You are going to fix that.", "", "", false},
+		{"Write a parser for the config format.", "", "", false},
+	}
+	for _, tc := range cases {
+		got := DetectPromptComponents(tc.text)
+		persona, audience := "", ""
+		if len(got.Personas) > 0 {
+			persona = got.Personas[0].Role
+			if got.Personas[0].Generic != tc.generic {
+				t.Errorf("%q: generic = %v, want %v", tc.text, got.Personas[0].Generic, tc.generic)
+			}
+		}
+		if len(got.Audiences) > 0 {
+			audience = got.Audiences[0].Role
+		}
+		if persona != tc.persona || audience != tc.audience {
+			t.Errorf("%q: got persona %q audience %q, want %q and %q", tc.text, persona, audience, tc.persona, tc.audience)
+		}
+	}
+}
+
+func TestRoleAudienceWeightedByPromptType(t *testing.T) {
+	roleFactor := func(text string) Factor {
+		for _, f := range GradePromptText(text).ContextSufficiency.Factors {
+			if f.Name == "Role & Audience" {
+				return f
+			}
+		}
+		t.Fatalf("no Role & Audience factor for %q", text)
+		return Factor{}
+	}
+	creative := roleFactor("You are an award-winning novelist. Write a short story about a lighthouse keeper for young adult readers.")
+	code := roleFactor("Implement a Go function that parses RFC 3339 timestamps and returns an error for invalid input.")
+	if creative.Weight <= code.Weight {
+		t.Errorf("expected personas to weigh more for creative prompts (%.2f vs %.2f)", creative.Weight, code.Weight)
+	}
+	if creative.Value <= code.Value || len(creative.Spans) != 2 {
+		t.Errorf("expected the persona and audience to be credited, got %+v", creative)
+	}
+}
@@ -0,0 +1,551 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Prompt file formats accepted in AnalysisOptions.InputFormat
+const (
+	InputText       = "text"        // Plain prompt text (the default)
+	InputAuto       = "auto"        // Detect one of the formats below, falling back to text
+	InputOpenAIChat = "openai_chat" // JSON chat messages, bare or under "messages"
+	InputLangChain  = "langchain"   // Saved PromptTemplate or ChatPromptTemplate JSON
+	InputCrewAI     = "crewai"      // agents.yaml or tasks.yaml
+	InputDotprompt  = "dotprompt"   // .prompt files: front matter and a Handlebars template
+)
+
+// InputFormats lists the accepted AnalysisOptions.InputFormat values
+var InputFormats = []string{InputText, InputAuto, InputOpenAIChat, InputLangChain, InputCrewAI, InputDotprompt}
+
+// validateInputFormat rejects unknown formats; "" means text
+func validateInputFormat(format string) error {
+	if format != "" && !contains(InputFormats, format) {
+		return fmt.Errorf("unknown input format %q (expected one of %v)", format, InputFormats)
+	}
+	return nil
+}
+
+// Message roles. Formats name them differently ("human", "ai",
+// "HumanMessagePromptTemplate"); each parser maps its names onto these.
+const (
+	MessageSystem      = "system"
+	MessageUser        = "user"
+	MessageAssistant   = "assistant"
+	MessagePlaceholder = "placeholder" // A slot filled with conversation history at run time
+)
+
+// PromptMessage is one message or template part of a prompt file
+type PromptMessage struct {
+	Role    string `json:"role"`
+	Name    string `json:"name,omitempty"` // CrewAI "agent.field" or "task.field"
+	Content string `json:"content"`        // For placeholders, the variable that fills the slot
+	Offset  int    `json:"offset"`         // Where the content starts in PromptDocument.Text; -1 when not graded
+}
+
+// TemplateVariable is a variable a prompt template fills in at run time
+type TemplateVariable struct {
+	Name     string `json:"name"`
+	Uses     int    `json:"uses"`
+	Declared bool   `json:"declared"` // Listed among the file's input variables
+}
+
+// PromptDocument is a prompt file read in its own format. The analysis
+// grades Text, the system and user messages separated by blank lines;
+// assistant messages are few-shot examples and are listed but not graded.
+type PromptDocument struct {
+	Format    string             `json:"format"`
+	Messages  []PromptMessage    `json:"messages"`
+	Variables []TemplateVariable `json:"variables"` // By name
+	Settings  map[string]string  `json:"settings"`  // Model settings and other scalar fields, e.g. "model", "temperature"
+	Warnings  []string           `json:"warnings"`
+	Text      string             `json:"text"`
+}
+
+var (
+	// fStringVariablePattern matches {name}, skipping the {{ and }} escapes
+	fStringVariablePattern = regexp.MustCompile(`\{\{|\}\}|\{([A-Za-z_][\w.]*)\}`)
+	// mustacheVariablePattern matches {{name}} and {{ name | filter }}; the
+	// first group holds a block or helper sigil such as "#" or "/"
+	mustacheVariablePattern = regexp.MustCompile(`\{\{-?\s*([#/^>&!@]?)\s*([A-Za-z_][\w.]*)\s*(\|[^}]*)?-?\}\}`)
+	// dotpromptRolePattern matches the {{role "system"}} and {{history}} markers
+	dotpromptRolePattern = regexp.MustCompile(`\{\{\s*(?:role\s+["'](\w+)["']|(history))\s*\}\}`)
+)
+
+// ParsePromptDocument reads text as a prompt file of the given format and
+// returns the document with the text to analyze. Text and "" return nil and
+// text unchanged, as does auto when no format matches. A file that isn't
+// valid in an explicitly requested format is analyzed as text, with a warning
+// saying why. Dotprompt reads the model settings and input schema from fm,
+// the file's front matter.
+func ParsePromptDocument(text string, fm *FrontMatter, format string) (*PromptDocument, string) {
+	if format == InputAuto {
+		format = detectInputFormat(text, fm)
+	}
+	var doc *PromptDocument
+	var err error
+	switch format {
+	case InputOpenAIChat:
+		doc, err = parseOpenAIChat(text)
+	case InputLangChain:
+		doc, err = parseLangChain(text)
+	case InputCrewAI:
+		doc, err = parseCrewAI(text)
+	case InputDotprompt:
+		doc = parseDotprompt(text, fm)
+	default:
+		return nil, text
+	}
+	if err != nil {
+		doc = &PromptDocument{Format: InputText, Messages: []PromptMessage{}, Variables: []TemplateVariable{}, Settings: map[string]string{},
+			Warnings: []string{fmt.Sprintf("not a valid %s file, analyzed as text: %v", format, err)}, Text: text}
+		return doc, text
+	}
+	doc.Format = format
+	doc.finish()
+	return doc, doc.Text
+}
+
+// detectInputFormat picks the format text looks like, or text
+func detectInputFormat(text string, fm *FrontMatter) string {
+	var v interface{}
+	if trimmed := strings.TrimSpace(text); json.Unmarshal([]byte(trimmed), &v) == nil {
+		if obj, ok := v.(map[string]interface{}); ok {
+			for _, key := range []string{"lc", "_type", "input_variables", "template"} {
+				if _, ok := obj[key]; ok {
+					return InputLangChain
+				}
+			}
+		}
+		if _, err := parseOpenAIChat(text); err == nil {
+			return InputOpenAIChat
+		}
+		return InputText
+	}
+	if dotpromptRolePattern.MatchString(text) {
+		return InputDotprompt
+	}
+	if fm != nil {
+		for key := range fm.Fields {
+			if strings.HasPrefix(key, "input.") || strings.HasPrefix(key, "config.") {
+				return InputDotprompt
+			}
+		}
+	}
+	if _, err := parseCrewAI(text); err == nil {
+		return InputCrewAI
+	}
+	return InputText
+}
+
+// finish assembles Text from the graded messages, counts the variables they
+// use and warns about declared variables that are never used and used ones
+// that are never declared. Formats that declare nothing get no warnings.
+func (d *PromptDocument) finish() {
+	graded := map[string]bool{MessageSystem: true, MessageUser: true}
+	hasGraded := false
+	for _, m := range d.Messages {
+		hasGraded = hasGraded || graded[m.Role]
+	}
+	var b strings.Builder
+	for i, m := range d.Messages {
+		d.Messages[i].Offset = -1
+		if m.Role == MessagePlaceholder || strings.TrimSpace(m.Content) == "" || (hasGraded && !graded[m.Role]) {
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteString("\n\n")
+		}
+		d.Messages[i].Offset = b.Len()
+		b.WriteString(m.Content)
+	}
+	d.Text = b.String()
+	if strings.TrimSpace(d.Text) == "" {
+		d.Warnings = append(d.Warnings, "the file has no message text to grade")
+	}
+
+	declared := false
+	for _, v := range d.Variables {
+		declared = declared || v.Declared
+	}
+	sort.Slice(d.Variables, func(i, j int) bool { return d.Variables[i].Name < d.Variables[j].Name })
+	for _, v := range d.Variables {
+		if v.Declared && v.Uses == 0 {
+			d.Warnings = append(d.Warnings, fmt.Sprintf("input variable %q is declared but never used", v.Name))
+		}
+		if declared && !v.Declared {
+			d.Warnings = append(d.Warnings, fmt.Sprintf("variable %q is used but not declared", v.Name))
+		}
+	}
+}
+
+// declare records the file's input variables, before any use is counted
+func (d *PromptDocument) declare(names ...string) {
+	for _, name := range names {
+		if d.variable(name) == nil {
+			d.Variables = append(d.Variables, TemplateVariable{Name: name, Declared: true})
+		}
+	}
+}
+
+// use counts the variables in content, written in the given template style
+func (d *PromptDocument) use(content string, mustache bool) {
+	var names []string
+	if mustache {
+		for _, m := range mustacheVariablePattern.FindAllStringSubmatch(content, -1) {
+			if m[1] == "" && m[2] != "else" {
+				names = append(names, m[2])
+			}
+		}
+	} else {
+		for _, m := range fStringVariablePattern.FindAllStringSubmatch(content, -1) {
+			if m[1] != "" {
+				names = append(names, m[1])
+			}
+		}
+	}
+	for _, name := range names {
+		v := d.variable(name)
+		if v == nil {
+			d.Variables = append(d.Variables, TemplateVariable{Name: name})
+			v = &d.Variables[len(d.Variables)-1]
+		}
+		v.Uses++
+	}
+}
+
+// variable returns the named variable, or nil
+func (d *PromptDocument) variable(name string) *TemplateVariable {
+	for i := range d.Variables {
+		if d.Variables[i].Name == name {
+			return &d.Variables[i]
+		}
+	}
+	return nil
+}
+
+// newPromptDocument returns an empty document ready to fill
+func newPromptDocument() *PromptDocument {
+	return &PromptDocument{Messages: []PromptMessage{}, Variables: []TemplateVariable{}, Settings: map[string]string{}, Warnings: []string{}}
+}
+
+// chatRoles maps role names used by chat formats to message roles
+var chatRoles = map[string]string{
+	"system": MessageSystem, "developer": MessageSystem,
+	"user": MessageUser, "human": MessageUser,
+	"assistant": MessageAssistant, "ai": MessageAssistant, "model": MessageAssistant,
+}
+
+// parseOpenAIChat reads a JSON array of {"role","content"} messages or an
+// object holding one under "messages", such as a chat completions request.
+// Content may be a string or a list of parts, of which the text parts are
+// read. Scalar fields of the object, such as model, become settings.
+func parseOpenAIChat(text string) (*PromptDocument, error) {
+	var raw json.RawMessage = []byte(text)
+	doc := newPromptDocument()
+	var request map[string]json.RawMessage
+	if json.Unmarshal(raw, &request) == nil {
+		messages, ok := request["messages"]
+		if !ok {
+			return nil, fmt.Errorf("no messages")
+		}
+		raw = messages
+		for key, value := range request {
+			if s, ok := jsonScalar(value); ok {
+				doc.Settings[key] = s
+			}
+		}
+	}
+	var messages []struct {
+		Role    string          `json:"role"`
+		Name    string          `json:"name"`
+		Content json.RawMessage `json:"content"`
+	}
+	if err := json.Unmarshal(raw, &messages); err != nil {
+		return nil, fmt.Errorf("messages must be a list of {\"role\", \"content\"} objects")
+	}
+	if len(messages) == 0 {
+		return nil, fmt.Errorf("no messages")
+	}
+	for i, m := range messages {
+		role, ok := chatRoles[strings.ToLower(m.Role)]
+		if !ok {
+			return nil, fmt.Errorf("message %d has unknown role %q", i+1, m.Role)
+		}
+		var content string
+		if json.Unmarshal(m.Content, &content) != nil {
+			var parts []struct {
+				Type string `json:"type"`
+				Text string `json:"text"`
+			}
+			if err := json.Unmarshal(m.Content, &parts); err != nil && len(m.Content) > 0 && string(m.Content) != "null" {
+				return nil, fmt.Errorf("message %d content must be a string or a list of parts", i+1)
+			}
+			var texts []string
+			for _, p := range parts {
+				if p.Type == "text" || p.Type == "input_text" {
+					texts = append(texts, p.Text)
+				} else {
+					doc.Warnings = append(doc.Warnings, fmt.Sprintf("message %d: skipped a %s part", i+1, p.Type))
+				}
+			}
+			content = strings.Join(texts, "\n\n")
+		}
+		doc.Messages = append(doc.Messages, PromptMessage{Role: role, Name: m.Name, Content: content})
+		doc.use(content, true)
+	}
+	return doc, nil
+}
+
+// jsonScalar returns a JSON string, number or boolean as text
+func jsonScalar(raw json.RawMessage) (string, bool) {
+	var v interface{}
+	if json.Unmarshal(raw, &v) != nil {
+		return "", false
+	}
+	switch v := v.(type) {
+	case string:
+		return v, true
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64), true
+	case bool:
+		return strconv.FormatBool(v), true
+	}
+	return "", false
+}
+
+// langChainRoles maps message template classes to message roles
+var langChainRoles = map[string]string{
+	"SystemMessagePromptTemplate": MessageSystem, "SystemMessage": MessageSystem,
+	"HumanMessagePromptTemplate": MessageUser, "HumanMessage": MessageUser,
+	"AIMessagePromptTemplate": MessageAssistant, "AIMessage": MessageAssistant,
+	"MessagesPlaceholder": MessagePlaceholder,
+}
+
+// parseLangChain reads a prompt saved by LangChain: the legacy
+// {"_type": "prompt", "template": ...} form, or the serialized
+// {"lc": 1, "id": [..., "ChatPromptTemplate"], "kwargs": ...} form of a
+// PromptTemplate or ChatPromptTemplate. Templates use f-string variables
+// unless template_format says jinja2 or mustache.
+func parseLangChain(text string) (*PromptDocument, error) {
+	var obj map[string]interface{}
+	if err := json.Unmarshal([]byte(text), &obj); err != nil {
+		return nil, fmt.Errorf("expected a JSON object")
+	}
+	doc := newPromptDocument()
+	if _, ok := obj["lc"]; ok {
+		kwargs, _ := obj["kwargs"].(map[string]interface{})
+		doc.declare(stringList(kwargs["input_variables"])...)
+		if err := doc.addLangChain(obj, MessageUser); err != nil {
+			return nil, err
+		}
+		return doc, nil
+	}
+
+	doc.declare(stringList(obj["input_variables"])...)
+	template, _ := obj["template"].(string)
+	if template == "" {
+		// A few-shot template keeps its instructions around the examples
+		prefix, _ := obj["prefix"].(string)
+		suffix, _ := obj["suffix"].(string)
+		template = strings.TrimSpace(prefix + "\n\n" + suffix)
+	}
+	if template == "" {
+		return nil, fmt.Errorf("no template")
+	}
+	doc.Messages = append(doc.Messages, PromptMessage{Role: MessageUser, Content: template})
+	doc.use(template, langChainMustache(obj))
+	return doc, nil
+}
+
+// addLangChain appends the messages of a serialized LangChain object
+func (d *PromptDocument) addLangChain(obj map[string]interface{}, role string) error {
+	ids := stringList(obj["id"])
+	if len(ids) == 0 {
+		return fmt.Errorf("a serialized object has no id")
+	}
+	class := ids[len(ids)-1]
+	kwargs, _ := obj["kwargs"].(map[string]interface{})
+	if r, ok := langChainRoles[class]; ok {
+		role = r
+	}
+	switch {
+	case class == "ChatPromptTemplate":
+		messages, _ := kwargs["messages"].([]interface{})
+		if len(messages) == 0 {
+			return fmt.Errorf("ChatPromptTemplate has no messages")
+		}
+		for _, m := range messages {
+			msg, _ := m.(map[string]interface{})
+			if err := d.addLangChain(msg, MessageUser); err != nil {
+				return err
+			}
+		}
+	case class == "MessagesPlaceholder":
+		name, _ := kwargs["variable_name"].(string)
+		d.Messages = append(d.Messages, PromptMessage{Role: MessagePlaceholder, Content: name})
+		if v := d.variable(name); v != nil {
+			v.Uses++
+		}
+	case class == "ChatMessagePromptTemplate":
+		if r, ok := chatRoles[strings.ToLower(fmt.Sprint(kwargs["role"]))]; ok {
+			role = r
+		}
+		fallthrough
+	case strings.HasSuffix(class, "MessagePromptTemplate"):
+		prompt, _ := kwargs["prompt"].(map[string]interface{})
+		return d.addLangChain(prompt, role)
+	case class == "PromptTemplate":
+		template, _ := kwargs["template"].(string)
+		d.Messages = append(d.Messages, PromptMessage{Role: role, Content: template})
+		d.use(template, langChainMustache(kwargs))
+	case strings.HasSuffix(class, "Message"):
+		content, _ := kwargs["content"].(string)
+		d.Messages = append(d.Messages, PromptMessage{Role: role, Content: content})
+	default:
+		return fmt.Errorf("unsupported LangChain class %s", class)
+	}
+	return nil
+}
+
+// langChainMustache reports whether a template uses {{name}} variables
+func langChainMustache(fields map[string]interface{}) bool {
+	format, _ := fields["template_format"].(string)
+	return format == "jinja2" || format == "mustache"
+}
+
+// stringList returns the strings of a decoded JSON list
+func stringList(v interface{}) []string {
+	items, _ := v.([]interface{})
+	out := make([]string, 0, len(items))
+	for _, item := range items {
+		if s, ok := item.(string); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// crewAIRoles maps CrewAI agent and task fields to message roles: an agent's
+// description sets up the model, a task's asks something of it
+var crewAIRoles = map[string]string{
+	"role": MessageSystem, "goal": MessageSystem, "backstory": MessageSystem,
+	"description": MessageUser, "expected_output": MessageUser,
+}
+
+// crewAIYAMLLine matches "key: value" at any indentation
+var crewAIYAMLLine = regexp.MustCompile(`^([ \t]*)([A-Za-z_][\w-]*)[ \t]*:(?:[ \t]+(.*))?$`)
+
+// parseCrewAI reads a CrewAI agents.yaml or tasks.yaml file: top-level agent
+// or task names, each with fields written inline or as ">" and "|" block
+// scalars. Role, goal and backstory become system messages; description
+// and expected output, user messages; other scalar fields, settings such as
+// "research_task.agent". Variables are written {name}.
+func parseCrewAI(text string) (*PromptDocument, error) {
+	doc := newPromptDocument()
+	lines := strings.Split(text, "\n")
+	entry := ""
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimRight(lines[i], " \t\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		m := crewAIYAMLLine.FindStringSubmatch(line)
+		if m == nil {
+			if entry != "" && strings.HasPrefix(strings.TrimSpace(line), "- ") {
+				continue // List values such as tools
+			}
+			return nil, fmt.Errorf("line %d is not a YAML key", i+1)
+		}
+		if m[1] == "" {
+			if m[3] != "" {
+				return nil, fmt.Errorf("line %d: expected an agent or task name", i+1)
+			}
+			entry = m[2]
+			continue
+		}
+		if entry == "" {
+			return nil, fmt.Errorf("line %d is indented outside an agent or task", i+1)
+		}
+
+		value := strings.TrimSpace(m[3])
+		if strings.HasPrefix(value, ">") || strings.HasPrefix(value, "|") {
+			// Block scalar: the following lines indented deeper than the key
+			folded := value[0] == '>'
+			var block []string
+			for i+1 < len(lines) {
+				next := strings.TrimRight(lines[i+1], " \t\r")
+				if strings.TrimSpace(next) != "" && len(next)-len(strings.TrimLeft(next, " \t")) <= len(m[1]) {
+					break
+				}
+				block = append(block, strings.TrimSpace(next))
+				i++
+			}
+			if folded {
+				value = strings.Join(strings.Fields(strings.Join(block, " ")), " ")
+			} else {
+				value = strings.TrimSpace(strings.Join(block, "\n"))
+			}
+		} else {
+			value = yamlScalar(value)
+		}
+
+		name := entry + "." + m[2]
+		if role, ok := crewAIRoles[m[2]]; ok {
+			doc.Messages = append(doc.Messages, PromptMessage{Role: role, Name: name, Content: value})
+			doc.use(value, false)
+		} else if value != "" {
+			doc.Settings[name] = value
+		}
+	}
+	if len(doc.Messages) == 0 {
+		return nil, fmt.Errorf("no agent or task fields")
+	}
+	return doc, nil
+}
+
+// parseDotprompt reads the template of a .prompt file, whose front matter
+// ParseFrontMatter has already split off. {{role "system"}} markers start
+// messages, text before the first is a user message, and {{history}} is a
+// placeholder. Variables are declared under input.schema in the front
+// matter, in Picoschema ("topic?: string, what to write about").
+func parseDotprompt(text string, fm *FrontMatter) *PromptDocument {
+	doc := newPromptDocument()
+	if fm != nil {
+		for key, value := range fm.Fields {
+			if name, ok := strings.CutPrefix(key, "input.schema."); ok {
+				name, _, _ = strings.Cut(name, ".")
+				name, _, _ = strings.Cut(name, "(")
+				doc.declare(strings.TrimSuffix(name, "?"))
+			} else if !strings.HasPrefix(key, "input.") && !strings.HasPrefix(key, "output.") {
+				doc.Settings[strings.TrimPrefix(key, "config.")] = value
+			}
+		}
+	}
+
+	role, cursor := MessageUser, 0
+	add := func(end int) {
+		if content := strings.TrimSpace(text[cursor:end]); content != "" {
+			doc.Messages = append(doc.Messages, PromptMessage{Role: role, Content: content})
+			doc.use(content, true)
+		}
+	}
+	for _, m := range dotpromptRolePattern.FindAllStringSubmatchIndex(text, -1) {
+		add(m[0])
+		cursor = m[1]
+		if m[4] >= 0 {
+			doc.Messages = append(doc.Messages, PromptMessage{Role: MessagePlaceholder, Content: "history"})
+			continue
+		}
+		role = chatRoles[strings.ToLower(text[m[2]:m[3]])]
+		if role == "" {
+			role = MessageUser
+		}
+	}
+	add(len(text))
+	return doc
+}
@@ -0,0 +1,157 @@
+package analyzer
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestParseOpenAIChat(t *testing.T) {
+	file := `{"model": "gpt-4o", "temperature": 0.3, "messages": [
+		{"role": "system", "content": "You are a support assistant for {{product}}."},
+		{"role": "user", "content": [{"type": "text", "text": "Summarize ticket {{ticket_id}} in three bullets."}, {"type": "image_url", "image_url": {"url": "x"}}]},
+		{"role": "assistant", "content": "- Customer cannot log in"}
+	]}`
+	doc, text := ParsePromptDocument(file, nil, InputOpenAIChat)
+	if doc == nil || doc.Format != InputOpenAIChat || len(doc.Messages) != 3 {
+		t.Fatalf("document = %+v", doc)
+	}
+	if text != "You are a support assistant for {{product}}.\n\nSummarize ticket {{ticket_id}} in three bullets." || doc.Text != text {
+		t.Errorf("text = %q", text)
+	}
+	if doc.Messages[1].Offset != strings.Index(text, "Summarize") || doc.Messages[2].Offset != -1 {
+		t.Errorf("messages = %+v", doc.Messages)
+	}
+	if doc.Settings["model"] != "gpt-4o" || doc.Settings["temperature"] != "0.3" {
+		t.Errorf("settings = %v", doc.Settings)
+	}
+	if len(doc.Variables) != 2 || doc.Variables[0].Name != "product" || doc.Variables[1].Name != "ticket_id" {
+		t.Errorf("variables = %+v", doc.Variables)
+	}
+	// Nothing is declared, so only the skipped image part is worth a warning
+	if len(doc.Warnings) != 1 || !strings.Contains(doc.Warnings[0], "image_url") {
+		t.Errorf("warnings = %v", doc.Warnings)
+	}
+
+	doc, text = ParsePromptDocument(`[{"role": "wizard", "content": "hi"}]`, nil, InputOpenAIChat)
+	if doc.Format != InputText || text != `[{"role": "wizard", "content": "hi"}]` || !strings.Contains(doc.Warnings[0], "unknown role") {
+		t.Errorf("document = %+v", doc)
+	}
+}
+
+func TestParseLangChain(t *testing.T) {
+	legacy := `{"_type": "prompt", "input_variables": ["topic", "tone"], "template": "Write a {tone} haiku about {topic}. Use {{braces}} literally.", "template_format": "f-string"}`
+	doc, text := ParsePromptDocument(legacy, nil, InputAuto)
+	if doc == nil || doc.Format != InputLangChain || !strings.HasPrefix(text, "Write a {tone} haiku") {
+		t.Fatalf("document = %+v", doc)
+	}
+	if len(doc.Variables) != 2 || doc.Variables[0].Uses != 1 || !doc.Variables[0].Declared || len(doc.Warnings) != 0 {
+		t.Errorf("variables = %+v, warnings = %v", doc.Variables, doc.Warnings)
+	}
+
+	chat := `{"lc": 1, "type": "constructor", "id": ["langchain", "prompts", "chat", "ChatPromptTemplate"], "kwargs": {
+		"input_variables": ["question", "history", "unused"],
+		"messages": [
+			{"lc": 1, "type": "constructor", "id": ["langchain", "prompts", "chat", "SystemMessagePromptTemplate"], "kwargs": {"prompt": {"lc": 1, "type": "constructor", "id": ["langchain", "prompts", "prompt", "PromptTemplate"], "kwargs": {"template": "You answer questions about our API docs.", "input_variables": []}}}},
+			{"lc": 1, "type": "constructor", "id": ["langchain", "prompts", "chat", "MessagesPlaceholder"], "kwargs": {"variable_name": "history"}},
+			{"lc": 1, "type": "constructor", "id": ["langchain", "prompts", "chat", "HumanMessagePromptTemplate"], "kwargs": {"prompt": {"lc": 1, "type": "constructor", "id": ["langchain", "prompts", "prompt", "PromptTemplate"], "kwargs": {"template": "{question} Cite the {section}.", "input_variables": ["question"]}}}}
+		]}}`
+	doc, _ = ParsePromptDocument(chat, nil, InputLangChain)
+	var roles []string
+	for _, m := range doc.Messages {
+		roles = append(roles, m.Role)
+	}
+	if got := strings.Join(roles, ","); got != "system,placeholder,user" {
+		t.Fatalf("roles = %s", got)
+	}
+	want := []string{`variable "section" is used but not declared`, `input variable "unused" is declared but never used`}
+	if strings.Join(doc.Warnings, "|") != strings.Join(want, "|") {
+		t.Errorf("warnings = %v", doc.Warnings)
+	}
+}
+
+func TestParseCrewAI(t *testing.T) {
+	file := `# agents.yaml
+researcher:
+  role: >
+    {topic} Senior Data Researcher
+  goal: >
+    Uncover cutting-edge developments
+    in {topic}
+  backstory: |
+    You're a seasoned researcher.
+    You find the most relevant information.
+  tools:
+    - search
+report_task:
+  description: Review the context and expand each topic into a full section.
+  expected_output: A markdown report with one section per topic.
+  agent: researcher
+`
+	doc, _ := ParsePromptDocument(file, nil, InputAuto)
+	if doc == nil || doc.Format != InputCrewAI || len(doc.Messages) != 5 {
+		t.Fatalf("document = %+v", doc)
+	}
+	if m := doc.Messages[1]; m.Name != "researcher.goal" || m.Role != MessageSystem || m.Content != "Uncover cutting-edge developments in {topic}" {
+		t.Errorf("goal = %+v", m)
+	}
+	if m := doc.Messages[2]; m.Content != "You're a seasoned researcher.\nYou find the most relevant information." {
+		t.Errorf("backstory = %q", m.Content)
+	}
+	if doc.Messages[3].Role != MessageUser || doc.Settings["report_task.agent"] != "researcher" {
+		t.Errorf("messages = %+v, settings = %v", doc.Messages, doc.Settings)
+	}
+	if len(doc.Variables) != 1 || doc.Variables[0].Uses != 2 {
+		t.Errorf("variables = %+v", doc.Variables)
+	}
+}
+
+func TestParseDotprompt(t *testing.T) {
+	file := `---
+model: googleai/gemini-1.5-flash
+config:
+  temperature: 0.4
+input:
+  schema:
+    topic: string
+    style?: string, the writing style
+---
+{{role "system"}}
+You are a travel writer. Write in a {{style}} voice.
+{{history}}
+{{role "user"}}
+Describe {{topic}} in two paragraphs.{{#if extra}} Mention {{extra}}.{{/if}}`
+	result, err := Analyze(context.Background(), file, AnalysisOptions{InputFormat: InputAuto}, AnalysisRun{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fm, doc := result.FrontMatter, result.Document
+	if fm == nil || fm.Temperature == nil || *fm.Temperature != 0.4 || fm.ContextWindow == nil {
+		t.Fatalf("front matter = %+v", fm)
+	}
+	if doc == nil || doc.Format != InputDotprompt || len(doc.Messages) != 3 || doc.Messages[1].Role != MessagePlaceholder {
+		t.Fatalf("document = %+v", doc)
+	}
+	if doc.Settings["temperature"] != "0.4" || doc.Settings["model"] != "googleai/gemini-1.5-flash" {
+		t.Errorf("settings = %v", doc.Settings)
+	}
+	if len(doc.Warnings) != 1 || doc.Warnings[0] != `variable "extra" is used but not declared` {
+		t.Errorf("warnings = %v", doc.Warnings)
+	}
+	for _, tok := range result.Tokens.Tokens {
+		if tok.Text == "role" || tok.Text == "schema" {
+			t.Errorf("%q from the file's markup was analyzed as prompt text", tok.Text)
+		}
+	}
+}
+
+func TestPlainTextIsNotAPromptFile(t *testing.T) {
+	for _, text := range []string{"Summarize the report in {three} bullets.", `{"not": "a prompt"}`} {
+		if doc, out := ParsePromptDocument(text, nil, InputAuto); doc != nil || out != text {
+			t.Errorf("ParsePromptDocument(%q) = %+v", text, doc)
+		}
+	}
+	if err := (AnalysisOptions{InputFormat: "xml"}).Validate(); err == nil {
+		t.Error("expected an unknown input format to be rejected")
+	}
+}
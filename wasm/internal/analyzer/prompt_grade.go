@@ -1,8 +1,8 @@
 package analyzer
 
 import (
+	"fmt"
 	"math"
-	"regexp"
 	"strings"
 	"unicode"
 )
@@ -22,6 +22,11 @@ type PromptGrade struct {
 	SuggestionMeta      SuggestionMeta   `json:"suggestion_meta,omitempty"`
 	Strengths           []string         `json:"strengths"`
 	WeakAreas           []string         `json:"weak_areas"`
+	Terminology         TerminologyAnalysis `json:"terminology"` // Glossary and jargon usage behind Domain Terminology
+	Instructions        InstructionAnalysis `json:"instructions"` // Per-instruction completeness behind Instruction Completeness
+	Components          PromptComponents    `json:"components"`   // Persona and audience statements behind Role & Audience
+	Goals               GoalExtraction      `json:"goals"`        // Objectives and non-goals behind Clear Goals
+	Payloads            PayloadAnalysis     `json:"payloads"`     // Embedded JSON, YAML and XML behind Payload Validity
 }
 
 // GradeDimension represents a single grading dimension
@@ -31,6 +36,9 @@ type GradeDimension struct {
 	Label       string   `json:"label"`        // Quality label
 	Description string   `json:"description"`  // Brief explanation
 	Factors     []Factor `json:"factors"`      // Contributing factors
+	Trace       *ScoreTrace `json:"trace,omitempty"` // How the score was computed, in explain mode
+
+	adjustments []TraceAdjustment // Weight changes applied after the factors were scored
 }
 
 // Factor represents a contributing factor to a grade dimension
@@ -39,6 +47,10 @@ type Factor struct {
 	Value       float64 `json:"value"`
 	Weight      float64 `json:"weight"`
 	Contribution float64 `json:"contribution"`
+	Spans       []Span  `json:"spans,omitempty"` // Text regions behind the value, when locatable
+
+	inputs        map[string]float64 // Raw measurements behind Value, reported in explain mode
+	normalization string             // How the inputs became Value
 }
 
 // OverallGrade represents the composite grade
@@ -48,15 +60,18 @@ type OverallGrade struct {
 	GradeColor  string  `json:"grade_color"` // Color for UI display
 	Summary     string  `json:"summary"`     // Overall assessment
 	Percentile  int     `json:"percentile"`  // Compared to typical prompts
+	Trace       *ScoreTrace `json:"trace,omitempty"` // Dimension weights behind the score, in explain mode
 }
 
 // Suggestion represents an improvement suggestion
 type Suggestion struct {
+	Rule        string `json:"rule"`        // Stable rule ID, e.g. "FUL001"
 	Dimension   string `json:"dimension"`
 	Priority    string `json:"priority"`    // "high", "medium", "low"
 	Message     string `json:"message"`
 	Impact      string `json:"impact"`      // Expected improvement
 	Example     string `json:"example,omitempty"`
+	Spans       []Span `json:"spans,omitempty"` // Text regions the suggestion refers to
 }
 
 // SuggestionMeta provides context for why suggestions were generated
@@ -68,6 +83,9 @@ type SuggestionMeta struct {
 }
 
 // CalculatePromptGrade analyzes all metrics and generates a comprehensive grade
+//
+// Deprecated: grade with GradeAll(GradersFor(opts), inputs), which returns
+// every selected engine's grade in one envelope.
 func CalculatePromptGrade(
 	complexity ComplexityMetrics,
 	tokens TokenData,
@@ -76,27 +94,72 @@ func CalculatePromptGrade(
 	taskGraph TaskGraph,
 	text string,
 ) *PromptGrade {
+	return CalculatePromptGradeWithRules(complexity, tokens, preprocessing, ideas, taskGraph, text, SuggestionRuleConfig{})
+}
+
+// CalculatePromptGradeWithRules grades the prompt, generating suggestions only from enabled rules
+//
+// Deprecated: use GradeAll with GradersFor(AnalysisOptions{Rules: rules}).
+func CalculatePromptGradeWithRules(
+	complexity ComplexityMetrics,
+	tokens TokenData,
+	preprocessing PreprocessingData,
+	ideas IdeaAnalysisMetrics,
+	taskGraph TaskGraph,
+	text string,
+	rules SuggestionRuleConfig,
+) *PromptGrade {
+	return CalculatePromptGradeWithOptions(complexity, tokens, preprocessing, ideas, taskGraph, text, AnalysisOptions{Rules: rules})
+}
+
+// CalculatePromptGradeWithOptions grades the prompt using the suggestion rules
+// and domain glossary from opts
+//
+// Deprecated: use GradeAll with GradersFor(opts).
+func CalculatePromptGradeWithOptions(
+	complexity ComplexityMetrics,
+	tokens TokenData,
+	preprocessing PreprocessingData,
+	ideas IdeaAnalysisMetrics,
+	taskGraph TaskGraph,
+	text string,
+	opts AnalysisOptions,
+) *PromptGrade {
+	in := NewGradingInputs(text, complexity, tokens, preprocessing, ideas, taskGraph, NewPromptClassifierWithModel(opts.classifierModel()))
+	return gradeClassic(in, opts)
+}
+
+// gradeClassic computes the classic grade from the shared grading inputs
+func gradeClassic(in GradingInputs, opts AnalysisOptions) *PromptGrade {
+	text, complexity, tokens, preprocessing, ideas, taskGraph := in.Text, in.Complexity, in.Tokens, in.Preprocessing, in.Ideas, in.TaskGraph
 	grade := &PromptGrade{}
+	grade.Terminology = AnalyzeTerminology(text, opts.Glossary)
+	grade.Instructions = AnalyzeInstructions(text)
+	grade.Components = in.Components
+	grade.Goals = in.Goals
+	grade.Payloads = AnalyzePayloads(text)
+	cls := in.Classification
 	
 	// Calculate each dimension
 	grade.Understandability = calculateUnderstandability(complexity, tokens)
 	grade.Specificity = calculateSpecificity(text, tokens, ideas)
 	grade.TaskComplexity = calculateTaskComplexity(taskGraph, ideas)
-	grade.Clarity = calculateClarity(complexity, ideas, preprocessing)
-	grade.Actionability = calculateActionability(taskGraph, tokens)
+	grade.Clarity = calculateClarity(complexity, ideas, preprocessing, grade.Payloads)
+	grade.Actionability = calculateActionability(taskGraph, tokens, grade.Instructions)
 	grade.StructureQuality = calculateStructureQuality(ideas, complexity)
-	grade.ContextSufficiency = calculateContextSufficiency(ideas, tokens)
+	grade.ContextSufficiency = calculateContextSufficiency(ideas, tokens, grade.Terminology, grade.Components, grade.Goals, cls.BlendLabels())
 	grade.ScopeManagement = calculateScopeManagement(taskGraph, ideas, tokens)
 	
 	// Calculate overall grade
 	grade.OverallGrade = calculateOverallGrade(grade)
+	if opts.Explain {
+		attachScoreTraces(grade)
+	}
 	
 	// Generate suggestions based on scores and context
-	grade.Suggestions = generateSuggestions(grade, text, tokens, ideas, taskGraph)
+	grade.Suggestions = generateSuggestions(grade, text, tokens, ideas, taskGraph, cls.GradingType(), opts.Rules)
 
 	// Why these suggestions? Add meta context
-	classifier := NewPromptClassifier()
-	cls := classifier.ClassifyPrompt(text)
 	grade.SuggestionMeta = SuggestionMeta{
 		PromptType:      string(cls.PrimaryType),
 		PromptTypeLabel: GetPromptTypeDisplayName(cls.PrimaryType),
@@ -110,6 +173,11 @@ func CalculatePromptGrade(
 	return grade
 }
 
+// GradePromptText runs the full analysis pipeline on text and grades it
+func GradePromptText(text string) *PromptGrade {
+	return gradeClassic(AnalyzeForGrading(text, NewPromptClassifier()), AnalysisOptions{})
+}
+
 // calculateUnderstandability evaluates how easy the prompt is to understand
 func calculateUnderstandability(complexity ComplexityMetrics, tokens TokenData) GradeDimension {
 	factors := []Factor{}
@@ -123,6 +191,8 @@ func calculateUnderstandability(complexity ComplexityMetrics, tokens TokenData)
 		Value:        normalizedFlesch,
 		Weight:       0.30,
 		Contribution: normalizedFlesch * 0.30,
+		inputs:        map[string]float64{"flesch_reading_ease": fleschScore},
+		normalization: "Flesch reading ease clamped to 0-100",
 	})
 	totalScore += normalizedFlesch * 0.30
 	
@@ -137,6 +207,8 @@ func calculateUnderstandability(complexity ComplexityMetrics, tokens TokenData)
 		Value:        sentLengthScore,
 		Weight:       0.20,
 		Contribution: sentLengthScore * 0.20,
+		inputs:        map[string]float64{"average_words_per_sentence": avgSentLength},
+		normalization: "100, minus 3 per word above 20 words per sentence, floored at 0",
 	})
 	totalScore += sentLengthScore * 0.20
 	
@@ -148,6 +220,8 @@ func calculateUnderstandability(complexity ComplexityMetrics, tokens TokenData)
 		Value:        complexityScore,
 		Weight:       0.20,
 		Contribution: complexityScore * 0.20,
+		inputs:        map[string]float64{"average_sentence_complexity": sentComplexity},
+		normalization: "100 - complexity*10, floored at 0",
 	})
 	totalScore += complexityScore * 0.20
 	
@@ -162,6 +236,8 @@ func calculateUnderstandability(complexity ComplexityMetrics, tokens TokenData)
 		Value:        lexicalScore,
 		Weight:       0.15,
 		Contribution: lexicalScore * 0.15,
+		inputs:        map[string]float64{"lexical_diversity": lexicalDiv},
+		normalization: "diversity*100; above 0.7 diversity, 70 + (diversity-0.7)*100",
 	})
 	totalScore += lexicalScore * 0.15
 	
@@ -179,6 +255,8 @@ func calculateUnderstandability(complexity ComplexityMetrics, tokens TokenData)
 		Value:        wordComplexityScore,
 		Weight:       0.15,
 		Contribution: wordComplexityScore * 0.15,
+		inputs:        map[string]float64{"simple_words": float64(simple), "total_words": float64(total)},
+		normalization: "simple words / total words * 100",
 	})
 	totalScore += wordComplexityScore * 0.15
 	
@@ -206,6 +284,9 @@ func calculateSpecificity(text string, tokens TokenData, ideas IdeaAnalysisMetri
 		Value:        pronounScore,
 		Weight:       0.25,
 		Contribution: pronounScore * 0.25,
+		Spans:        wordSpans(text, vaguePronouns),
+		inputs:        map[string]float64{"pronouns": float64(pronounCount), "words": float64(len(words))},
+		normalization: "100 - pronouns/words*500, floored at 0",
 	})
 	totalScore += pronounScore * 0.25
 	
@@ -219,6 +300,8 @@ func calculateSpecificity(text string, tokens TokenData, ideas IdeaAnalysisMetri
 		Value:        entityScore,
 		Weight:       0.20,
 		Contribution: entityScore * 0.20,
+		inputs:        map[string]float64{"capitalized_words": float64(namedEntities), "words": float64(len(words))},
+		normalization: "capitalized words/words*1000, capped at 100",
 	})
 	totalScore += entityScore * 0.20
 	
@@ -231,6 +314,9 @@ func calculateSpecificity(text string, tokens TokenData, ideas IdeaAnalysisMetri
 		Value:        concreteScore,
 		Weight:       0.20,
 		Contribution: concreteScore * 0.20,
+		Spans:        wordSpans(text, abstractWords),
+		inputs:        map[string]float64{"abstract_words": float64(abstractCount), "words": float64(len(words))},
+		normalization: "100 - abstract words/words*300, floored at 0",
 	})
 	totalScore += concreteScore * 0.20
 	
@@ -247,6 +333,9 @@ func calculateSpecificity(text string, tokens TokenData, ideas IdeaAnalysisMetri
 		Value:        questionScore,
 		Weight:       0.15,
 		Contribution: questionScore * 0.15,
+		Spans:        fragmentSpans(text, ideas.QuestionAnalysis.Value.Unanswered),
+		inputs:        map[string]float64{"questions": float64(ideas.QuestionAnalysis.Value.TotalQuestions), "actionable_questions": float64(len(ideas.QuestionAnalysis.Value.Actionable))},
+		normalization: "actionable questions/questions*100; 70 without questions",
 	})
 	totalScore += questionScore * 0.15
 	
@@ -258,6 +347,8 @@ func calculateSpecificity(text string, tokens TokenData, ideas IdeaAnalysisMetri
 		Value:        numericScore,
 		Weight:       0.10,
 		Contribution: numericScore * 0.10,
+		inputs:        map[string]float64{"numbers": float64(numericCount)},
+		normalization: "20 per number, capped at 100",
 	})
 	totalScore += numericScore * 0.10
 	
@@ -269,6 +360,8 @@ func calculateSpecificity(text string, tokens TokenData, ideas IdeaAnalysisMetri
 		Value:        temporalScore,
 		Weight:       0.10,
 		Contribution: temporalScore * 0.10,
+		inputs:        map[string]float64{"temporal_markers": float64(temporalCount)},
+		normalization: "25 per temporal marker, capped at 100",
 	})
 	totalScore += temporalScore * 0.10
 	
@@ -305,6 +398,8 @@ func calculateTaskComplexity(taskGraph TaskGraph, ideas IdeaAnalysisMetrics) Gra
 		Value:        taskCountScore,
 		Weight:       0.25,
 		Contribution: taskCountScore * 0.25,
+		inputs:        map[string]float64{"tasks": taskCount},
+		normalization: "20 up to 2 tasks, 40 up to 5, 60 up to 10, 80 up to 15, else 100",
 	})
 	totalScore += taskCountScore * 0.25
 	
@@ -327,6 +422,8 @@ func calculateTaskComplexity(taskGraph TaskGraph, ideas IdeaAnalysisMetrics) Gra
 		Value:        depthScore,
 		Weight:       0.25,
 		Contribution: depthScore * 0.25,
+		inputs:        map[string]float64{"critical_path_length": float64(len(taskGraph.CriticalPath))},
+		normalization: "20 up to 2 steps on the critical path, 50 up to 4, 75 up to 6, else 100",
 	})
 	totalScore += depthScore * 0.25
 	
@@ -337,6 +434,8 @@ func calculateTaskComplexity(taskGraph TaskGraph, ideas IdeaAnalysisMetrics) Gra
 		Value:        graphComplexityScore,
 		Weight:       0.20,
 		Contribution: graphComplexityScore * 0.20,
+		inputs:        map[string]float64{"graph_complexity": taskGraph.GraphComplexity},
+		normalization: "graph complexity*20, capped at 100",
 	})
 	totalScore += graphComplexityScore * 0.20
 	
@@ -352,6 +451,8 @@ func calculateTaskComplexity(taskGraph TaskGraph, ideas IdeaAnalysisMetrics) Gra
 		Value:        parallelScore,
 		Weight:       0.15,
 		Contribution: parallelScore * 0.15,
+		inputs:        map[string]float64{"root_tasks": float64(len(taskGraph.RootTasks)), "tasks": float64(taskGraph.TotalTasks)},
+		normalization: "root tasks/tasks*100; 50 without tasks",
 	})
 	totalScore += parallelScore * 0.15
 	
@@ -366,6 +467,8 @@ func calculateTaskComplexity(taskGraph TaskGraph, ideas IdeaAnalysisMetrics) Gra
 		Value:        diversityScore,
 		Weight:       0.15,
 		Contribution: diversityScore * 0.15,
+		inputs:        map[string]float64{"task_types": float64(len(taskTypes))},
+		normalization: "25 per task type, capped at 100",
 	})
 	totalScore += diversityScore * 0.15
 	
@@ -379,18 +482,21 @@ func calculateTaskComplexity(taskGraph TaskGraph, ideas IdeaAnalysisMetrics) Gra
 }
 
 // calculateClarity evaluates how clearly the prompt expresses its intent
-func calculateClarity(complexity ComplexityMetrics, ideas IdeaAnalysisMetrics, preprocessing PreprocessingData) GradeDimension {
+func calculateClarity(complexity ComplexityMetrics, ideas IdeaAnalysisMetrics, preprocessing PreprocessingData, payloads PayloadAnalysis) GradeDimension {
 	factors := []Factor{}
 	totalScore := 0.0
 	
-	// Sentence structure consistency (25% weight)
-	sentenceVariance := complexity.SentenceStats.SentenceLengthVar.Value
-	consistencyScore := math.Max(0, 100-sentenceVariance*2)
+	// Sentence structure consistency (25% weight), from the spread of sentence
+	// lengths in words; the variance itself grows too fast to score directly
+	sentenceSpread := complexity.SentenceStats.SentenceLengthStd.Value
+	consistencyScore := math.Max(0, 100-sentenceSpread*2)
 	factors = append(factors, Factor{
 		Name:         "Structure Consistency",
 		Value:        consistencyScore,
 		Weight:       0.25,
 		Contribution: consistencyScore * 0.25,
+		inputs:        map[string]float64{"sentence_length_std": sentenceSpread},
+		normalization: "100 - sentence length std dev*2, floored at 0",
 	})
 	totalScore += consistencyScore * 0.25
 	
@@ -407,6 +513,8 @@ func calculateClarity(complexity ComplexityMetrics, ideas IdeaAnalysisMetrics, p
 		Value:        ambiguityScore,
 		Weight:       0.20,
 		Contribution: ambiguityScore * 0.20,
+		inputs:        map[string]float64{"lexical_diversity": complexity.LexicalDiversity.Value},
+		normalization: "60 above 0.8 lexical diversity, 90 below 0.3, else 80",
 	})
 	totalScore += ambiguityScore * 0.20
 	
@@ -420,6 +528,8 @@ func calculateClarity(complexity ComplexityMetrics, ideas IdeaAnalysisMetrics, p
 		Value:        transitionScore,
 		Weight:       0.20,
 		Contribution: transitionScore * 0.20,
+		inputs:        map[string]float64{"topic_transitions": float64(ideas.TopicTransitions.Value)},
+		normalization: "100, minus 10 per topic transition above 5, floored at 40",
 	})
 	totalScore += transitionScore * 0.20
 	
@@ -431,6 +541,8 @@ func calculateClarity(complexity ComplexityMetrics, ideas IdeaAnalysisMetrics, p
 		Value:        contradictionScore,
 		Weight:       0.15,
 		Contribution: contradictionScore * 0.15,
+		inputs:        map[string]float64{"thematic_consistency": ideas.ThematicConsistency.Value},
+		normalization: "thematic consistency*100",
 	})
 	totalScore += contradictionScore * 0.15
 	
@@ -441,6 +553,7 @@ func calculateClarity(complexity ComplexityMetrics, ideas IdeaAnalysisMetrics, p
 		Value:        modalScore,
 		Weight:       0.10,
 		Contribution: modalScore * 0.10,
+		normalization: "fixed default of 85",
 	})
 	totalScore += modalScore * 0.10
 	
@@ -451,20 +564,54 @@ func calculateClarity(complexity ComplexityMetrics, ideas IdeaAnalysisMetrics, p
 		Value:        punctuationScore,
 		Weight:       0.10,
 		Contribution: punctuationScore * 0.10,
+		normalization: "fixed default of 90",
 	})
 	totalScore += punctuationScore * 0.10
 	
+	// Payload validity - only when the prompt embeds JSON, YAML or XML, the
+	// other factors share the rest
+	var adjustments []TraceAdjustment
+	if len(payloads.Payloads) > 0 {
+		for i := range factors {
+			factors[i].Weight *= 1 - payloadValidityWeight
+			factors[i].Contribution *= 1 - payloadValidityWeight
+		}
+		totalScore *= 1 - payloadValidityWeight
+		invalidSpans := []Span{}
+		for _, p := range payloads.Payloads {
+			if !p.Valid {
+				invalidSpans = append(invalidSpans, p.Span)
+			}
+		}
+		factors = append(factors, Factor{
+			Name:         "Payload Validity",
+			Value:        payloads.Score,
+			Weight:       payloadValidityWeight,
+			Contribution: payloads.Score * payloadValidityWeight,
+			Spans:        invalidSpans,
+			inputs:        map[string]float64{"valid_payloads": float64(payloads.Valid), "invalid_payloads": float64(payloads.Invalid)},
+			normalization: "percent of embedded JSON, YAML and XML payloads that parse",
+		})
+		totalScore += payloads.Score * payloadValidityWeight
+		adjustments = append(adjustments, TraceAdjustment{
+			Name:       "Payload Validity share",
+			Detail:     "Weights of the other factors scaled by 1 - the payload validity weight",
+			Multiplier: 1 - payloadValidityWeight,
+		})
+	}
+	
 	return GradeDimension{
 		Score:       math.Round(totalScore*100) / 100,
 		Grade:       scoreToGrade(totalScore),
 		Label:       getQualityLabel(totalScore),
 		Description: getClarityDescription(totalScore),
 		Factors:     factors,
+		adjustments: adjustments,
 	}
 }
 
 // calculateActionability evaluates how actionable the prompt is
-func calculateActionability(taskGraph TaskGraph, tokens TokenData) GradeDimension {
+func calculateActionability(taskGraph TaskGraph, tokens TokenData, instructions InstructionAnalysis) GradeDimension {
 	factors := []Factor{}
 	totalScore := 0.0
 	
@@ -479,27 +626,29 @@ func calculateActionability(taskGraph TaskGraph, tokens TokenData) GradeDimensio
 		Value:        actionVerbScore,
 		Weight:       0.25,
 		Contribution: actionVerbScore * 0.25,
+		inputs:        map[string]float64{"action_verbs": float64(actionVerbCount)},
+		normalization: "15 per action verb, capped at 100",
 	})
 	totalScore += actionVerbScore * 0.25
 	
-	// Clear outcome specification (20% weight)
-	outcomeScore := 60.0 // Default moderate score
-	if taskGraph.TotalTasks > 0 {
-		tasksWithClearOutcomes := 0
-		for _, task := range taskGraph.Tasks {
-			if task.Priority != "" && task.EstimatedEffort != "" {
-				tasksWithClearOutcomes++
-			}
+	// Instruction completeness (20% weight) - object, parameters, order and outcome per instruction
+	completenessScore := instructions.Score
+	incompleteSpans := []Span{}
+	for _, q := range instructions.Instructions {
+		if q.Completeness < 0.5 {
+			incompleteSpans = append(incompleteSpans, q.Span)
 		}
-		outcomeScore = float64(tasksWithClearOutcomes) / float64(taskGraph.TotalTasks) * 100
 	}
 	factors = append(factors, Factor{
-		Name:         "Clear Outcomes",
-		Value:        outcomeScore,
+		Name:         "Instruction Completeness",
+		Value:        completenessScore,
 		Weight:       0.20,
-		Contribution: outcomeScore * 0.20,
+		Contribution: completenessScore * 0.20,
+		Spans:        incompleteSpans,
+		inputs:        map[string]float64{"instructions": float64(len(instructions.Instructions)), "incomplete": float64(instructions.Incomplete), "average_completeness": instructions.AverageCompleteness},
+		normalization: "average completeness*100; 60 without instructions",
 	})
-	totalScore += outcomeScore * 0.20
+	totalScore += completenessScore * 0.20
 	
 	// Measurable criteria (20% weight)
 	measurableScore := 50.0 // Default score
@@ -511,6 +660,8 @@ func calculateActionability(taskGraph TaskGraph, tokens TokenData) GradeDimensio
 		Value:        measurableScore,
 		Weight:       0.20,
 		Contribution: measurableScore * 0.20,
+		inputs:        map[string]float64{"tasks": float64(taskGraph.TotalTasks)},
+		normalization: "20 per task, capped at 100; 50 without tasks",
 	})
 	totalScore += measurableScore * 0.20
 	
@@ -524,6 +675,8 @@ func calculateActionability(taskGraph TaskGraph, tokens TokenData) GradeDimensio
 		Value:        sequencingScore,
 		Weight:       0.15,
 		Contribution: sequencingScore * 0.15,
+		inputs:        map[string]float64{"critical_path_length": float64(len(taskGraph.CriticalPath))},
+		normalization: "90 with a critical path, else 70",
 	})
 	totalScore += sequencingScore * 0.15
 	
@@ -534,6 +687,7 @@ func calculateActionability(taskGraph TaskGraph, tokens TokenData) GradeDimensio
 		Value:        resourceScore,
 		Weight:       0.10,
 		Contribution: resourceScore * 0.10,
+		normalization: "fixed default of 60",
 	})
 	totalScore += resourceScore * 0.10
 	
@@ -544,6 +698,7 @@ func calculateActionability(taskGraph TaskGraph, tokens TokenData) GradeDimensio
 		Value:        successScore,
 		Weight:       0.10,
 		Contribution: successScore * 0.10,
+		normalization: "fixed default of 65",
 	})
 	totalScore += successScore * 0.10
 	
@@ -575,6 +730,7 @@ func calculateStructureQuality(ideas IdeaAnalysisMetrics, complexity ComplexityM
 		Value:        progressionScore,
 		Weight:       0.25,
 		Contribution: progressionScore * 0.25,
+		normalization: "linear 90, branching 75, circular 50, else 70 (progression: " + ideas.IdeaProgression.Value + ")",
 	})
 	totalScore += progressionScore * 0.25
 	
@@ -585,13 +741,15 @@ func calculateStructureQuality(ideas IdeaAnalysisMetrics, complexity ComplexityM
 		Value:        coherenceScore,
 		Weight:       0.20,
 		Contribution: coherenceScore * 0.20,
+		inputs:        map[string]float64{"conceptual_coherence": ideas.ConceptualCoherence.Value},
+		normalization: "conceptual coherence*100",
 	})
 	totalScore += coherenceScore * 0.20
 	
 	// Organization (20% weight)
 	organizationScore := 75.0 // Default good score
+	avgCoherence := 0.0
 	if len(ideas.SemanticClusters.Value) > 0 {
-		avgCoherence := 0.0
 		for _, cluster := range ideas.SemanticClusters.Value {
 			avgCoherence += cluster.Coherence
 		}
@@ -603,39 +761,59 @@ func calculateStructureQuality(ideas IdeaAnalysisMetrics, complexity ComplexityM
 		Value:        organizationScore,
 		Weight:       0.20,
 		Contribution: organizationScore * 0.20,
+		inputs:        map[string]float64{"clusters": float64(len(ideas.SemanticClusters.Value)), "average_cluster_coherence": avgCoherence},
+		normalization: "average cluster coherence*100; 75 without clusters",
 	})
 	totalScore += organizationScore * 0.20
 	
-	// Transition usage (15% weight)
-	transitionScore := math.Max(0, 100-float64(ideas.TopicTransitions.Value)*15)
-	if ideas.TopicTransitions.Value >= 2 && ideas.TopicTransitions.Value <= 5 {
-		transitionScore = 85.0 // Optimal range
+	// Transition usage (15% weight), from discourse markers between sentences;
+	// many topic shifts still cost points when the markers don't bridge them
+	transitionScore := 75.0 // Neutral when idea analysis didn't run
+	if ideas.Discourse.TotalSentences > 0 {
+		transitionScore = ideas.Discourse.TransitionScore
+	}
+	if ideas.TopicTransitions.Value > 5 && ideas.Discourse.Coverage < 0.4 {
+		transitionScore = math.Max(0, transitionScore-float64(ideas.TopicTransitions.Value-5)*5)
 	}
 	factors = append(factors, Factor{
 		Name:         "Smooth Transitions",
 		Value:        transitionScore,
 		Weight:       0.15,
 		Contribution: transitionScore * 0.15,
+		inputs:        map[string]float64{"discourse_transition_score": ideas.Discourse.TransitionScore, "discourse_coverage": ideas.Discourse.Coverage, "topic_transitions": float64(ideas.TopicTransitions.Value)},
+		normalization: "discourse transition score (75 without idea analysis), minus 5 per topic transition above 5 when markers link under 40% of sentences",
 	})
 	totalScore += transitionScore * 0.15
 	
-	// Conclusion presence (10% weight)
-	conclusionScore := 70.0 // Default moderate score
+	// Conclusion and introduction presence (10% weight each). Prompts of a
+	// sentence or two aren't expected to have either and keep a neutral score.
+	conclusionScore, introScore := 70.0, 70.0
+	if complexity.SentenceStats.TotalSentences.Value >= 3 {
+		conclusionScore, introScore = 55.0, 55.0
+		if complexity.Paragraphs.HasConclusion {
+			conclusionScore = 90.0
+		}
+		if complexity.Paragraphs.HasIntroduction {
+			introScore = 90.0
+		}
+	}
 	factors = append(factors, Factor{
 		Name:         "Conclusion Clarity",
 		Value:        conclusionScore,
 		Weight:       0.10,
 		Contribution: conclusionScore * 0.10,
+		inputs:        map[string]float64{"sentences": float64(complexity.SentenceStats.TotalSentences.Value), "has_conclusion": boolInput(complexity.Paragraphs.HasConclusion)},
+		normalization: "90 with a conclusion, else 55; 70 under 3 sentences",
 	})
 	totalScore += conclusionScore * 0.10
 	
-	// Introduction clarity (10% weight)
-	introScore := 70.0 // Default moderate score
 	factors = append(factors, Factor{
 		Name:         "Introduction Clarity",
 		Value:        introScore,
 		Weight:       0.10,
 		Contribution: introScore * 0.10,
+		inputs:        map[string]float64{"sentences": float64(complexity.SentenceStats.TotalSentences.Value), "has_introduction": boolInput(complexity.Paragraphs.HasIntroduction)},
+		normalization: "90 with an introduction, else 55; 70 under 3 sentences",
 	})
 	totalScore += introScore * 0.10
 	
@@ -649,7 +827,7 @@ func calculateStructureQuality(ideas IdeaAnalysisMetrics, complexity ComplexityM
 }
 
 // calculateContextSufficiency evaluates if enough context is provided
-func calculateContextSufficiency(ideas IdeaAnalysisMetrics, tokens TokenData) GradeDimension {
+func calculateContextSufficiency(ideas IdeaAnalysisMetrics, tokens TokenData, terminology TerminologyAnalysis, components PromptComponents, goals GoalExtraction, blend []PromptLabel) GradeDimension {
 	factors := []Factor{}
 	totalScore := 0.0
 	
@@ -663,6 +841,8 @@ func calculateContextSufficiency(ideas IdeaAnalysisMetrics, tokens TokenData) Gr
 		Value:        backgroundScore,
 		Weight:       0.25,
 		Contribution: backgroundScore * 0.25,
+		inputs:        map[string]float64{"facts": float64(ideas.FactualContent.Value.TotalFacts)},
+		normalization: "10 per fact when there are more than 3, capped at 100; else 60",
 	})
 	totalScore += backgroundScore * 0.25
 	
@@ -673,16 +853,24 @@ func calculateContextSufficiency(ideas IdeaAnalysisMetrics, tokens TokenData) Gr
 		Value:        assumptionScore,
 		Weight:       0.20,
 		Contribution: assumptionScore * 0.20,
+		normalization: "fixed default of 70",
 	})
 	totalScore += assumptionScore * 0.20
 	
-	// Domain terminology (20% weight)
-	termScore := 75.0 // Default score
+	// Domain terminology (20% weight) - share of glossary and jargon terms that are defined
+	termScore := terminology.Score
+	jargonSpans := []Span{}
+	for _, j := range terminology.UndefinedJargon {
+		jargonSpans = append(jargonSpans, j.Spans...)
+	}
 	factors = append(factors, Factor{
 		Name:         "Domain Terminology",
 		Value:        termScore,
 		Weight:       0.20,
 		Contribution: termScore * 0.20,
+		Spans:        jargonSpans,
+		inputs:        map[string]float64{"glossary_terms": float64(len(terminology.GlossaryTerms)), "inline_defined": float64(len(terminology.InlineDefined)), "undefined_jargon": float64(len(terminology.UndefinedJargon))},
+		normalization: "share of glossary and jargon terms that are defined*100",
 	})
 	totalScore += termScore * 0.20
 	
@@ -693,6 +881,7 @@ func calculateContextSufficiency(ideas IdeaAnalysisMetrics, tokens TokenData) Gr
 		Value:        referenceScore,
 		Weight:       0.15,
 		Contribution: referenceScore * 0.15,
+		normalization: "fixed default of 70",
 	})
 	totalScore += referenceScore * 0.15
 	
@@ -703,25 +892,61 @@ func calculateContextSufficiency(ideas IdeaAnalysisMetrics, tokens TokenData) Gr
 		Value:        constraintScore,
 		Weight:       0.10,
 		Contribution: constraintScore * 0.10,
+		normalization: "fixed default of 65",
 	})
 	totalScore += constraintScore * 0.10
 	
-	// Goal clarity (10% weight)
-	goalScore := 75.0 // Default score
+	// Goal clarity (10% weight) - stated or inferred objectives and non-goals
+	goalScore := goals.Score
+	goalSpans := []Span{}
+	for _, g := range goals.Primary {
+		goalSpans = append(goalSpans, g.Span)
+	}
 	factors = append(factors, Factor{
 		Name:         "Clear Goals",
 		Value:        goalScore,
 		Weight:       0.10,
 		Contribution: goalScore * 0.10,
+		Spans:        goalSpans,
+		inputs:        map[string]float64{"primary_goals": float64(len(goals.Primary)), "secondary_goals": float64(len(goals.Secondary)), "non_goals": float64(len(goals.NonGoals))},
+		normalization: "90 for a stated goal, 70 inferred from an instruction, 40 without; +10 with secondary goals or non-goals",
 	})
 	totalScore += goalScore * 0.10
 	
+	// Role and audience - weighted by prompt type, the other factors share the rest
+	roleWeight := blendedRoleWeight(blend)
+	for i := range factors {
+		factors[i].Weight *= 1 - roleWeight
+		factors[i].Contribution *= 1 - roleWeight
+	}
+	totalScore *= 1 - roleWeight
+	roleScore := roleFactorScore(components)
+	roleSpans := []Span{}
+	for _, r := range append(components.Personas, components.Audiences...) {
+		roleSpans = append(roleSpans, r.Span)
+	}
+	factors = append(factors, Factor{
+		Name:         "Role & Audience",
+		Value:        roleScore,
+		Weight:       roleWeight,
+		Contribution: roleScore * roleWeight,
+		Spans:        roleSpans,
+		inputs:        map[string]float64{"personas": float64(len(components.Personas)), "audiences": float64(len(components.Audiences)), "role_score": components.RoleScore},
+		normalization: "40 + role score*0.6; weight set by prompt type",
+	})
+	totalScore += roleScore * roleWeight
+	
 	return GradeDimension{
 		Score:       math.Round(totalScore*100) / 100,
 		Grade:       scoreToGrade(totalScore),
 		Label:       getQualityLabel(totalScore),
 		Description: getContextDescription(totalScore),
 		Factors:     factors,
+		adjustments: []TraceAdjustment{{
+			Name:       "Role & Audience share",
+			Detail:     "Weights of the other factors scaled by 1 - the role weight for this prompt type",
+			Multiplier: 1 - roleWeight,
+		}},
 	}
 }
 
@@ -748,6 +973,8 @@ func calculateScopeManagement(taskGraph TaskGraph, ideas IdeaAnalysisMetrics, to
 		Value:        ratioScore,
 		Weight:       0.25,
 		Contribution: ratioScore * 0.25,
+		inputs:        map[string]float64{"words": float64(tokens.TokenCounts.Words), "tasks": float64(taskGraph.TotalTasks), "words_per_task": wordsPerTask},
+		normalization: "90 for 20-100 words per task, 30 under 10, 40 over 200, else 50",
 	})
 	totalScore += ratioScore * 0.25
 	
@@ -758,6 +985,8 @@ func calculateScopeManagement(taskGraph TaskGraph, ideas IdeaAnalysisMetrics, to
 		Value:        breadthScore,
 		Weight:       0.20,
 		Contribution: breadthScore * 0.20,
+		inputs:        map[string]float64{"conceptual_breadth": ideas.ConceptualBreadth.Value},
+		normalization: "(1 - conceptual breadth)*100",
 	})
 	totalScore += breadthScore * 0.20
 	
@@ -773,6 +1002,8 @@ func calculateScopeManagement(taskGraph TaskGraph, ideas IdeaAnalysisMetrics, to
 		Value:        depthScore,
 		Weight:       0.20,
 		Contribution: depthScore * 0.20,
+		inputs:        map[string]float64{"idea_complexity": ideas.IdeaComplexity.Value},
+		normalization: "90 for idea complexity 3-6, 50 above 8, else 75",
 	})
 	totalScore += depthScore * 0.20
 	
@@ -783,6 +1014,8 @@ func calculateScopeManagement(taskGraph TaskGraph, ideas IdeaAnalysisMetrics, to
 		Value:        focusScore,
 		Weight:       0.15,
 		Contribution: focusScore * 0.15,
+		inputs:        map[string]float64{"thematic_consistency": ideas.ThematicConsistency.Value},
+		normalization: "thematic consistency*100",
 	})
 	totalScore += focusScore * 0.15
 	
@@ -796,13 +1029,15 @@ func calculateScopeManagement(taskGraph TaskGraph, ideas IdeaAnalysisMetrics, to
 		Value:        creepScore,
 		Weight:       0.10,
 		Contribution: creepScore * 0.10,
+		inputs:        map[string]float64{"topic_transitions": float64(ideas.TopicTransitions.Value)},
+		normalization: "40 above 7 topic transitions, else 80",
 	})
 	totalScore += creepScore * 0.10
 	
 	// Priority specification (10% weight)
 	priorityScore := 60.0
+	highPriorityCount := 0
 	if taskGraph.TotalTasks > 0 {
-		highPriorityCount := 0
 		for _, task := range taskGraph.Tasks {
 			if task.Priority == "high" {
 				highPriorityCount++
@@ -817,6 +1052,8 @@ func calculateScopeManagement(taskGraph TaskGraph, ideas IdeaAnalysisMetrics, to
 		Value:        priorityScore,
 		Weight:       0.10,
 		Contribution: priorityScore * 0.10,
+		inputs:        map[string]float64{"tasks": float64(taskGraph.TotalTasks), "high_priority_tasks": float64(highPriorityCount)},
+		normalization: "85 when 1 to a third of tasks are high priority, else 60",
 	})
 	totalScore += priorityScore * 0.10
 	
@@ -1009,15 +1246,26 @@ func getScopeDescription(score float64) string {
 }
 
 // Utility counting functions
+
+// vaguePronouns are references that force the reader to resolve an antecedent
+var vaguePronouns = map[string]bool{
+	"it": true, "this": true, "that": true, "these": true, "those": true,
+	"they": true, "them": true, "their": true, "theirs": true,
+	"he": true, "she": true, "him": true, "her": true, "his": true, "hers": true,
+}
+
+// abstractWords stand in for a concrete noun
+var abstractWords = map[string]bool{
+	"thing": true, "stuff": true, "concept": true, "idea": true,
+	"notion": true, "aspect": true, "element": true, "factor": true,
+	"component": true, "part": true, "piece": true, "item": true,
+	"something": true, "anything": true, "everything": true,
+}
+
 func countPronouns(words []string) int {
-	pronouns := map[string]bool{
-		"it": true, "this": true, "that": true, "these": true, "those": true,
-		"they": true, "them": true, "their": true, "theirs": true,
-		"he": true, "she": true, "him": true, "her": true, "his": true, "hers": true,
-	}
 	count := 0
 	for _, word := range words {
-		if pronouns[word] {
+		if vaguePronouns[word] {
 			count++
 		}
 	}
@@ -1025,15 +1273,9 @@ func countPronouns(words []string) int {
 }
 
 func countAbstractWords(words []string) int {
-	abstract := map[string]bool{
-		"thing": true, "stuff": true, "concept": true, "idea": true,
-		"notion": true, "aspect": true, "element": true, "factor": true,
-		"component": true, "part": true, "piece": true, "item": true,
-		"something": true, "anything": true, "everything": true,
-	}
 	count := 0
 	for _, word := range words {
-		if abstract[word] {
+		if abstractWords[word] {
 			count++
 		}
 	}
@@ -1054,22 +1296,24 @@ func countCapitalizedWords(text string) int {
 }
 
 func countNumericContent(text string) int {
-	re := regexp.MustCompile(`\d+`)
+	re := digitsPattern
 	matches := re.FindAllString(text, -1)
 	return len(matches)
 }
 
+// temporalMarkers sequence steps or place them in time
+var temporalMarkers = map[string]bool{
+	"first": true, "then": true, "next": true, "after": true,
+	"before": true, "finally": true, "subsequently": true,
+	"meanwhile": true, "during": true, "while": true,
+	"afterwards": true, "previously": true, "later": true,
+	"now": true, "today": true, "tomorrow": true, "yesterday": true,
+}
+
 func countTemporalMarkers(words []string) int {
-	temporal := map[string]bool{
-		"first": true, "then": true, "next": true, "after": true,
-		"before": true, "finally": true, "subsequently": true,
-		"meanwhile": true, "during": true, "while": true,
-		"afterwards": true, "previously": true, "later": true,
-		"now": true, "today": true, "tomorrow": true, "yesterday": true,
-	}
 	count := 0
 	for _, word := range words {
-		if temporal[word] {
+		if temporalMarkers[word] {
 			count++
 		}
 	}
@@ -1077,16 +1321,33 @@ func countTemporalMarkers(words []string) int {
 }
 
 // calculateOverallGrade computes the composite grade
+// overallDimension is a dimension and its weight in the overall score
+type overallDimension struct {
+	name   string
+	dim    *GradeDimension
+	weight float64
+}
+
+// overallDimensions lists the dimensions in the order they are summed
+func overallDimensions(grade *PromptGrade) []overallDimension {
+	return []overallDimension{
+		{"Understandability", &grade.Understandability, 0.20},
+		{"Specificity", &grade.Specificity, 0.15},
+		{"Task Complexity", &grade.TaskComplexity, 0.15},
+		{"Clarity", &grade.Clarity, 0.15},
+		{"Actionability", &grade.Actionability, 0.15},
+		{"Structure Quality", &grade.StructureQuality, 0.10},
+		{"Context Sufficiency", &grade.ContextSufficiency, 0.05},
+		{"Scope Management", &grade.ScopeManagement, 0.05},
+	}
+}
+
 func calculateOverallGrade(grade *PromptGrade) OverallGrade {
 	// Weighted average as per design doc
-	overallScore := grade.Understandability.Score*0.20 +
-		grade.Specificity.Score*0.15 +
-		grade.TaskComplexity.Score*0.15 +
-		grade.Clarity.Score*0.15 +
-		grade.Actionability.Score*0.15 +
-		grade.StructureQuality.Score*0.10 +
-		grade.ContextSufficiency.Score*0.05 +
-		grade.ScopeManagement.Score*0.05
+	overallScore := 0.0
+	for _, d := range overallDimensions(grade) {
+		overallScore += d.dim.Score * d.weight
+	}
 	
 	letterGrade := scoreToGrade(overallScore)
 	
@@ -1130,28 +1391,31 @@ func calculateOverallGrade(grade *PromptGrade) OverallGrade {
 }
 
 // generateSuggestions creates actionable, context-aware improvement suggestions
-func generateSuggestions(grade *PromptGrade, text string, tokens TokenData, ideas IdeaAnalysisMetrics, taskGraph TaskGraph) []Suggestion {
+func generateSuggestions(grade *PromptGrade, text string, tokens TokenData, ideas IdeaAnalysisMetrics, taskGraph TaskGraph, pt PromptType, rules SuggestionRuleConfig) []Suggestion {
 	suggestions := []Suggestion{}
-	add := func(dim, prio, msg, impact, ex string) {
-		suggestions = append(suggestions, Suggestion{Dimension: dim, Priority: prio, Message: msg, Impact: impact, Example: ex})
+	add := func(rule, dim, prio, msg, impact, ex string, spans ...Span) {
+		if !rules.enabled(rule) {
+			return
+		}
+		suggestions = append(suggestions, Suggestion{Rule: rule, Dimension: dim, Priority: rules.priority(rule, prio), Message: msg, Impact: impact, Example: ex, Spans: spans})
 	}
 
-	// Classify prompt type to tailor suggestions
-	classifier := NewPromptClassifier()
-	pt := classifier.ClassifyPrompt(text).PrimaryType
-
 	// Common gaps across types
 	if grade.Specificity.Score < 72 {
-		add("Specificity", "high", "Specify exact inputs, outputs, and success criteria", "Reduces ambiguity and makes the response unambiguous", "Example: 'Input: JSON {id, name}. Output: CSV with columns user_id, status.'")
+		ex := "Example: 'Input: JSON {id, name}. Output: CSV with columns user_id, status.'"
+		if own, ok := outputSpecExample(text); ok {
+			ex = "Example: " + own
+		}
+		add("FUL001", "Specificity", "high", "Specify exact inputs, outputs, and success criteria", "Reduces ambiguity and makes the response unambiguous", ex)
 	}
 	if grade.Actionability.Score < 70 {
-		add("Actionability", "high", "List concrete deliverables or step-by-step tasks", "Increases executability and alignment", "Example: 'Deliver: schema.sql, API spec (OpenAPI), unit tests, README with run steps.'")
+		add("FUL002", "Actionability", "high", "List concrete deliverables or step-by-step tasks", "Increases executability and alignment", "Example: 'Deliver: schema.sql, API spec (OpenAPI), unit tests, README with run steps.'")
 	}
 	if grade.StructureQuality.Score < 68 {
-		add("Structure", "medium", "Organize prompt into sections (Context, Requirements, Constraints, Deliverables)", "Improves readability and agent understanding", "Use bullet points and headings for each section.")
+		add("FUL003", "Structure", "medium", "Organize prompt into sections (Context, Requirements, Constraints, Deliverables)", "Improves readability and agent understanding", "Use bullet points and headings for each section.")
 	}
 	if grade.ContextSufficiency.Score < 68 {
-		add("Context", "medium", "Provide domain context, constraints, and environment details", "Improves relevance and feasibility of results", "Example: 'Runtime: Node.js 20; DB: Postgres 15; Hosting: AWS Lambda; p95 latency: 200ms.'")
+		add("FUL004", "Context", "medium", "Provide domain context, constraints, and environment details", "Improves relevance and feasibility of results", "Example: 'Runtime: Node.js 20; DB: Postgres 15; Hosting: AWS Lambda; p95 latency: 200ms.'")
 	}
 
 	// Type-specific rules
@@ -1159,47 +1423,83 @@ func generateSuggestions(grade *PromptGrade, text string, tokens TokenData, idea
 	case TechnicalSpec, CodeGeneration:
 		// Security/infra suggestions when absent
 		if ideas.FactualContent.Value.TotalFacts < 2 && grade.ContextSufficiency.Score < 75 {
-			add("Context", "medium", "State non-functional requirements (security, performance, SLAs)", "Prevents rework and ensures completeness", "Example: 'Auth: OAuth2; Rate limit: 100 rps; Availability: 99.9%.'")
+			add("FUL005", "Context", "medium", "State non-functional requirements (security, performance, SLAs)", "Prevents rework and ensures completeness", "Example: 'Auth: OAuth2; Rate limit: 100 rps; Availability: 99.9%.'")
 		}
 		// Low named entities or interfaces -> request API/interface shapes
 		if len(tokens.SemanticFeatures.NamedEntities) < 2 || grade.Specificity.Score < 70 {
-			add("Specificity", "high", "Define interface shapes, schemas, or endpoint contracts", "Eliminates guesswork in implementation", "Example: 'POST /webhooks/order-created {id:string, amount:number, currency:string}'")
+			add("FUL006", "Specificity", "high", "Define interface shapes, schemas, or endpoint contracts", "Eliminates guesswork in implementation", "Example: 'POST /webhooks/order-created {id:string, amount:number, currency:string}'")
 		}
 		// Scope vs complexity
 		if grade.TaskComplexity.Score > 75 && grade.ScopeManagement.Score < 70 {
-			add("Scope", "high", "Split into phases or separate prompts", "Reduces cognitive load and improves quality", "Phase 1: ingestion; Phase 2: validation; Phase 3: persistence")
+			add("FUL007", "Scope", "high", "Split into phases or separate prompts", "Reduces cognitive load and improves quality", "Phase 1: ingestion; Phase 2: validation; Phase 3: persistence")
 		}
 		// Testing & observability
-		add("Quality", "medium", "Ask for tests, examples, and observability hooks", "Raises reliability and ease of maintenance", "Include unit tests, example payloads, and logging/metrics points.")
+		add("FUL008", "Quality", "medium", "Ask for tests, examples, and observability hooks", "Raises reliability and ease of maintenance", "Include unit tests, example payloads, and logging/metrics points.")
 
 	case DataAnalysis:
-		add("Data", "high", "List dataset fields, time window, and filters", "Enables targeted analysis and correct joins", "Columns: user_id, plan, mrr, events; Window: 2024-01..2024-12; Filter: active customers.")
-		add("Methodology", "medium", "Specify analysis methods and output artifacts", "Sets expectations and saves iteration time", "EDA + cohort + predictive (logit). Output: notebook, dashboard, executive summary.")
+		add("FUL009", "Data", "high", "List dataset fields, time window, and filters", "Enables targeted analysis and correct joins", "Columns: user_id, plan, mrr, events; Window: 2024-01..2024-12; Filter: active customers.")
+		add("FUL010", "Methodology", "medium", "Specify analysis methods and output artifacts", "Sets expectations and saves iteration time", "EDA + cohort + predictive (logit). Output: notebook, dashboard, executive summary.")
 		if ideas.QuestionAnalysis.Value.TotalQuestions > 0 && len(ideas.QuestionAnalysis.Value.Actionable) == 0 {
-			add("Clarity", "medium", "Convert open questions into specific analytical tasks", "Improves actionability and outcomes", "Example: 'Quantify churn uplift from onboarding email within 30 days.'")
+			add("FUL011", "Clarity", "medium", "Convert open questions into specific analytical tasks", "Improves actionability and outcomes", "Example: 'Quantify churn uplift from onboarding email within 30 days.'",
+				fragmentSpans(text, ideas.QuestionAnalysis.Value.Unanswered)...)
 		}
 
 	case CreativeTask, Writing:
-		add("Brief", "high", "Define audience, tone, style, and 'do/don't' lists", "Aligns creative output with brand and goals", "Audience: SMB founders; Tone: practical; Do: concise; Don't: clichés.")
-		add("Examples", "medium", "Provide 2-3 reference examples or links", "Guides taste and reduces revisions", "Reference: 'Basecamp marketing tone', 'Stripe docs voice'.")
+		add("FUL012", "Brief", "high", "Define audience, tone, style, and 'do/don't' lists", "Aligns creative output with brand and goals", "Audience: SMB founders; Tone: practical; Do: concise; Don't: clichés.")
+		add("FUL013", "Examples", "medium", "Provide 2-3 reference examples or links", "Guides taste and reduces revisions", "Reference: 'Basecamp marketing tone', 'Stripe docs voice'.")
 
 	case Learning:
-		add("Objectives", "high", "Set explicit learning objectives and timeline", "Ensures scope matches learning goals", "Objective: build ML model; Timeline: 4 weeks; Hours: 10/wk")
-		add("Format", "medium", "Request step-by-step curriculum with exercises and assessments", "Makes learning practical and measurable", "Each topic: 15-min theory, code demo, 1 exercise, quiz.")
+		add("FUL014", "Objectives", "high", "Set explicit learning objectives and timeline", "Ensures scope matches learning goals", "Objective: build ML model; Timeline: 4 weeks; Hours: 10/wk")
+		add("FUL015", "Format", "medium", "Request step-by-step curriculum with exercises and assessments", "Makes learning practical and measurable", "Each topic: 15-min theory, code demo, 1 exercise, quiz.")
 
 	default: // General / ProblemSolving
-		add("Clarification", "high", "Add 3-5 clarifying questions the model should answer before proceeding", "Avoids misinterpretation and rework", "Example questions: constraints, success criteria, examples, dependencies.")
+		add("FUL016", "Clarification", "high", "Add 3-5 clarifying questions the model should answer before proceeding", "Avoids misinterpretation and rework", "Example questions: constraints, success criteria, examples, dependencies.")
 	}
 
 	// Additional signals-driven suggestions
 	if tokens.TokenCounts.Words > 0 {
 		pronouns := len(tokens.PartOfSpeech.Pronouns)
 		if float64(pronouns)/float64(tokens.TokenCounts.Words) > 0.05 {
-			add("Specificity", "medium", "Replace pronouns (it/this/that) with specific nouns", "Reduces ambiguity in references", "'Update it' -> 'Update the authentication service'.")
+			ex := "'Update it' -> 'Update the authentication service'."
+			if own, ok := pronounExample(text); ok {
+				ex = own
+			}
+			add("FUL017", "Specificity", "medium", "Replace pronouns (it/this/that) with specific nouns", "Reduces ambiguity in references", ex,
+				wordSpans(text, vaguePronouns)...)
+		}
+	}
+	for _, p := range grade.Payloads.Payloads {
+		if !p.Valid {
+			add("FUL025", "Clarity", "high", fmt.Sprintf("Fix the malformed %s payload", strings.ToUpper(p.Format)), "The model copies the shape of examples, errors included", fmt.Sprintf("'%s': %s.", p.ErrorSpan.Text, p.Error),
+				*p.ErrorSpan)
+		}
+	}
+	if long := longSentenceSpans(maskPayloads(text, grade.Payloads.Payloads), longSentenceWords); len(long) > 0 {
+		ex := "Keep one requirement per sentence; split at 'and', a comma or a semicolon."
+		if own, ok := splitSentenceExample(long); ok {
+			ex = own
+		}
+		add("FUL024", "Clarity", "medium", fmt.Sprintf("Break up sentences over %d words", longSentenceWords), "Long sentences bury requirements the model may skip", ex,
+			long...)
+	}
+	if jargon := grade.Terminology.UndefinedJargon; len(jargon) > 0 {
+		terms := []string{}
+		spans := []Span{}
+		for i, j := range jargon {
+			if i < 3 {
+				terms = append(terms, j.Term)
+			}
+			spans = append(spans, j.Spans[0])
+		}
+		ex := "'SLA (service level agreement): 99.9% monthly uptime.' Or register the terms in a glossary."
+		if own, ok := jargonExample(terms); ok {
+			ex = own
 		}
+		add("FUL019", "Context", "medium", fmt.Sprintf("Define jargon and acronyms such as %s", strings.Join(terms, ", ")), "The model may guess the wrong meaning of undefined terms", ex,
+			spans...)
 	}
 	if taskGraph.TotalTasks == 0 && (pt == TechnicalSpec || pt == CodeGeneration) {
-		add("Actionability", "medium", "Ask the model to extract a task list first", "Creates a clear execution plan", "'List tasks with estimates and dependencies before implementation.'")
+		add("FUL018", "Actionability", "medium", "Ask the model to extract a task list first", "Creates a clear execution plan", "'List tasks with estimates and dependencies before implementation.'")
 	}
 
 	// Sort by priority and trim
@@ -0,0 +1,196 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Rewriter produces an improved version of a prompt from its grade report
+type Rewriter interface {
+	Name() string
+	Rewrite(ctx context.Context, text string, grade *PromptGrade) (string, error)
+}
+
+// RewriteResult is one rewritten prompt together with its new grade
+type RewriteResult struct {
+	Rewriter   string       `json:"rewriter"`
+	Text       string       `json:"text"`
+	Grade      *PromptGrade `json:"grade,omitempty"`
+	ScoreDelta float64      `json:"score_delta"` // Overall score change versus the original
+	Error      string       `json:"error,omitempty"`
+}
+
+// RewriteComparison holds the original prompt and each rewrite side by side
+type RewriteComparison struct {
+	Original      string          `json:"original"`
+	OriginalGrade *PromptGrade    `json:"original_grade"`
+	Rewrites      []RewriteResult `json:"rewrites"`
+}
+
+// CompareRewrites grades the original, runs every rewriter and re-grades each result
+func CompareRewrites(ctx context.Context, text string, rewriters ...Rewriter) RewriteComparison {
+	original := GradePromptText(text)
+	comparison := RewriteComparison{
+		Original:      text,
+		OriginalGrade: original,
+		Rewrites:      []RewriteResult{},
+	}
+
+	for _, rw := range rewriters {
+		result := RewriteResult{Rewriter: rw.Name()}
+		rewritten, err := rw.Rewrite(ctx, text, original)
+		if err != nil {
+			result.Error = err.Error()
+			comparison.Rewrites = append(comparison.Rewrites, result)
+			continue
+		}
+
+		result.Text = rewritten
+		result.Grade = GradePromptText(rewritten)
+		result.ScoreDelta = result.Grade.OverallGrade.Score - original.OverallGrade.Score
+		comparison.Rewrites = append(comparison.Rewrites, result)
+	}
+
+	return comparison
+}
+
+// RuleBasedRewriter restructures a prompt into sections without calling a model
+type RuleBasedRewriter struct{}
+
+// NewRuleBasedRewriter creates a rule-based rewriter
+func NewRuleBasedRewriter() *RuleBasedRewriter {
+	return &RuleBasedRewriter{}
+}
+
+// Name returns the rewriter identifier
+func (r *RuleBasedRewriter) Name() string {
+	return "rule_based"
+}
+
+// Rewrite sorts sentences into Goal, Context, Tasks and Constraints sections and
+// adds placeholders for whatever the grade reports as missing
+func (r *RuleBasedRewriter) Rewrite(ctx context.Context, text string, grade *PromptGrade) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(text) == "" {
+		return "", fmt.Errorf("nothing to rewrite")
+	}
+
+	sentences := splitListSentences(extractSentences(text))
+	graph := ExtractTaskGraph(text, sentences, nil)
+
+	taskSentences := make(map[string]bool)
+	for _, task := range graph.Tasks {
+		taskSentences[task.SourceText] = true
+	}
+
+	goal := ""
+	contextLines := []string{}
+	for _, sentence := range sentences {
+		if listNumberPattern.MatchString(sentence) || taskSentences[sentence] {
+			continue
+		}
+		if goal == "" && isGoalSentence(sentence) {
+			goal = sentence
+			continue
+		}
+		contextLines = append(contextLines, sentence)
+	}
+	if goal == "" && len(graph.Tasks) > 0 {
+		goal = graph.Tasks[0].Title
+	}
+
+	var b strings.Builder
+	b.WriteString("## Goal\n")
+	if goal != "" {
+		b.WriteString(ensureSentenceEnd(goal) + "\n")
+	} else {
+		b.WriteString("<State the outcome you want in one sentence.>\n")
+	}
+
+	b.WriteString("\n## Context\n")
+	if len(contextLines) > 0 {
+		for _, line := range contextLines {
+			b.WriteString(ensureSentenceEnd(line) + " ")
+		}
+		b.WriteString("\n")
+	}
+	if grade != nil && grade.ContextSufficiency.Score < 68 {
+		b.WriteString("<Add the environment, audience and background the reader needs.>\n")
+	}
+
+	b.WriteString("\n## Tasks\n")
+	if len(graph.Tasks) > 0 {
+		graph.WalkTree(func(task Task, depth int) {
+			fmt.Fprintf(&b, "%s1. %s\n", strings.Repeat("   ", depth), ensureSentenceEnd(task.Title))
+		})
+	} else {
+		b.WriteString("1. <List the concrete steps or deliverables.>\n")
+	}
+
+	constraints := []string{}
+	for _, task := range graph.Tasks {
+		for _, c := range task.Constraints {
+			if c.Type == "deadline" || c.Type == "duration" {
+				constraints = append(constraints, fmt.Sprintf("%s: %s", task.Title, c.Text))
+			}
+		}
+	}
+	if len(constraints) > 0 || (grade != nil && grade.Specificity.Score < 72) {
+		b.WriteString("\n## Constraints\n")
+		for _, c := range constraints {
+			b.WriteString("- " + c + "\n")
+		}
+		if grade != nil && grade.Specificity.Score < 72 {
+			b.WriteString("- <Inputs, output format and success criteria.>\n")
+		}
+	}
+
+	return strings.TrimSpace(renumberTaskList(b.String())) + "\n", nil
+}
+
+// isGoalSentence reports whether a sentence states an overall objective
+func isGoalSentence(sentence string) bool {
+	lower := strings.ToLower(sentence)
+	for _, marker := range []string{"goal", "objective", "purpose", "i want", "we want", "aim is", "looking to"} {
+		if strings.Contains(lower, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// ensureSentenceEnd appends a period when a sentence has no terminal punctuation
+func ensureSentenceEnd(sentence string) string {
+	sentence = strings.TrimSpace(sentence)
+	if sentence == "" || strings.ContainsAny(sentence[len(sentence)-1:], ".!?:") {
+		return sentence
+	}
+	return sentence + "."
+}
+
+// renumberTaskList rewrites numbered lines so each nesting level counts from 1
+func renumberTaskList(text string) string {
+	lines := strings.Split(text, "\n")
+	counters := map[int]int{}
+	for i, line := range lines {
+		m := listItemPattern.FindStringSubmatch(line)
+		if m == nil || m[2] == "" {
+			if !strings.HasPrefix(line, " ") {
+				counters = map[int]int{}
+			}
+			continue
+		}
+		indent := len(m[1])
+		for level := range counters {
+			if level > indent {
+				delete(counters, level)
+			}
+		}
+		counters[indent]++
+		lines[i] = fmt.Sprintf("%s%d. %s", m[1], counters[indent], line[len(m[0]):])
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,76 @@
+//go:build !js
+
+package analyzer
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const rewriteSamplePrompt = "I want a dashboard for our sales team. We need to create a chart of monthly revenue. " +
+	"Then add a filter by region. Fix it by Friday."
+
+// TestRuleBasedRewriter checks that the rewrite is sectioned and keeps the tasks
+func TestRuleBasedRewriter(t *testing.T) {
+	rewritten, err := NewRuleBasedRewriter().Rewrite(context.Background(), rewriteSamplePrompt, GradePromptText(rewriteSamplePrompt))
+	if err != nil {
+		t.Fatalf("rewrite failed: %v", err)
+	}
+	for _, want := range []string{"## Goal", "## Tasks", "1. ", "monthly revenue", "by Friday"} {
+		if !strings.Contains(rewritten, want) {
+			t.Errorf("expected %q in rewrite:\n%s", want, rewritten)
+		}
+	}
+}
+
+// TestLLMRewriterComparison runs an OpenAI-compatible stub next to the rule-based rewriter
+func TestLLMRewriterComparison(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Model    string `json:"model"`
+			Messages []struct {
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || len(req.Messages) != 1 {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		if !strings.Contains(req.Messages[0].Content, "monthly revenue") {
+			http.Error(w, "prompt missing", http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{
+				{"message": map[string]string{"content": "Build a sales dashboard with a monthly revenue chart and a region filter."}},
+			},
+		})
+	}))
+	defer server.Close()
+
+	llm, err := NewLLMRewriter(LLMRewriterConfig{Provider: "local", Endpoint: server.URL, Model: "test"})
+	if err != nil {
+		t.Fatalf("unexpected config error: %v", err)
+	}
+
+	comparison := CompareRewrites(context.Background(), rewriteSamplePrompt, NewRuleBasedRewriter(), llm)
+	if len(comparison.Rewrites) != 2 {
+		t.Fatalf("expected two rewrites, got %d", len(comparison.Rewrites))
+	}
+	for _, rw := range comparison.Rewrites {
+		if rw.Error != "" || rw.Grade == nil {
+			t.Errorf("%s: expected a graded rewrite, got error %q", rw.Rewriter, rw.Error)
+		}
+	}
+	if comparison.Rewrites[1].Rewriter != "llm_local" {
+		t.Errorf("unexpected rewriter name %q", comparison.Rewrites[1].Rewriter)
+	}
+
+	if _, err := NewLLMRewriter(LLMRewriterConfig{Provider: "anthropic", Model: "m"}); err == nil {
+		t.Errorf("expected an error for a missing API key")
+	}
+}
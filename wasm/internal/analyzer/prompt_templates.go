@@ -0,0 +1,355 @@
+package analyzer
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// TemplateSection is one expected part of a prompt skeleton
+type TemplateSection struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Required    bool     `json:"required"`
+	Aliases     []string `json:"aliases"`     // Alternative heading names
+	Cues        []string `json:"cues"`        // Phrases that show the section is covered without a heading
+	Placeholder string   `json:"placeholder"` // Text rendered in the skeleton
+}
+
+// PromptTemplate is a built-in prompt skeleton for a prompt type
+type PromptTemplate struct {
+	Type        PromptType        `json:"type"`
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Sections    []TemplateSection `json:"sections"`
+}
+
+// LintFinding reports a structural problem found by the prompt linter
+type LintFinding struct {
+	Section    string `json:"section"`
+	Severity   string `json:"severity"` // "warning" for required sections, "info" otherwise
+	Message    string `json:"message"`
+	Suggestion string `json:"suggestion"`
+}
+
+// LintReport is the result of checking a prompt against its template
+type LintReport struct {
+	PromptType      PromptType    `json:"prompt_type"`
+	Template        string        `json:"template"`
+	PresentSections []string      `json:"present_sections"`
+	MissingSections []string      `json:"missing_sections"`
+	Coverage        float64       `json:"coverage"` // Share of required sections present, 0-1
+	Findings        []LintFinding `json:"findings"`
+}
+
+// headingPatterns match markdown headings, bold lines and "Label:" prefixes
+var headingPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?m)^\s*#{1,6}\s+(.+?)\s*#*\s*$`),
+	regexp.MustCompile(`(?m)^\s*\*\*(.+?)\*\*:?\s*$`),
+	regexp.MustCompile(`(?m)^\s*([A-Za-z][A-Za-z /&-]{1,40}):`),
+}
+
+// Shared sections reused by several templates
+var (
+	sectionContext = TemplateSection{
+		Name: "Context", Description: "Background, environment and who the result is for", Required: true,
+		Aliases:     []string{"background", "situation", "overview"},
+		Cues:        []string{"currently", "we have", "our team", "the project", "background", "environment", "existing"},
+		Placeholder: "<Describe the current situation, environment and audience.>",
+	}
+	sectionGoal = TemplateSection{
+		Name: "Goal", Description: "The outcome the prompt should achieve", Required: true,
+		Aliases:     []string{"objective", "purpose", "task", "ask"},
+		Cues:        []string{"goal", "objective", "i want", "we want", "i need", "we need", "the aim", "so that"},
+		Placeholder: "<State the desired outcome in one or two sentences.>",
+	}
+	sectionRequirements = TemplateSection{
+		Name: "Requirements", Description: "Functional requirements the result must satisfy", Required: true,
+		Aliases:     []string{"specification", "features", "scope"},
+		Cues:        []string{"must", "should", "requirement", "needs to", "required"},
+		Placeholder: "- <Requirement 1>\n- <Requirement 2>",
+	}
+	sectionConstraints = TemplateSection{
+		Name: "Constraints", Description: "Limits on technology, time, budget or style", Required: false,
+		Aliases:     []string{"limitations", "non-functional requirements", "assumptions"},
+		Cues:        []string{"constraint", "must not", "don't", "do not", "limit", "within", "no more than", "at most", "budget", "deadline"},
+		Placeholder: "- <Technology, performance, time or budget limits>",
+	}
+	sectionOutput = TemplateSection{
+		Name: "Output Format", Description: "Shape of the expected answer or deliverables", Required: true,
+		Aliases:     []string{"deliverables", "output", "format", "response format", "expected output"},
+		Cues:        []string{"return", "output", "deliver", "format", "respond with", "provide a", "as a table", "as json", "in markdown"},
+		Placeholder: "<Describe the deliverables and their format.>",
+	}
+	sectionExamples = TemplateSection{
+		Name: "Examples", Description: "Sample inputs, outputs or references", Required: false,
+		Aliases:     []string{"example", "samples", "references"},
+		Cues:        []string{"for example", "e.g.", "such as", "example", "sample", "like this"},
+		Placeholder: "<Add one or two examples of good output.>",
+	}
+	sectionAcceptance = TemplateSection{
+		Name: "Acceptance Criteria", Description: "How to tell the result is done and correct", Required: false,
+		Aliases:     []string{"success criteria", "definition of done", "tests"},
+		Cues:        []string{"acceptance", "success criteria", "done when", "should pass", "test", "verify", "validate"},
+		Placeholder: "- <Criterion that proves the work is complete>",
+	}
+)
+
+// promptTemplates holds the built-in skeleton for each prompt type
+var promptTemplates = map[PromptType]PromptTemplate{
+	TechnicalSpec: {
+		Type: TechnicalSpec, Name: "Technical Specification",
+		Description: "System or feature specification with requirements and constraints",
+		Sections: []TemplateSection{sectionContext, sectionGoal, sectionRequirements, sectionConstraints,
+			{Name: "Architecture", Description: "Components, interfaces and data flow", Required: false,
+				Aliases:     []string{"design", "components", "system design"},
+				Cues:        []string{"architecture", "component", "service", "api", "database", "schema", "endpoint"},
+				Placeholder: "<Components, APIs and data stores involved.>"},
+			sectionAcceptance, sectionOutput},
+	},
+	CodeGeneration: {
+		Type: CodeGeneration, Name: "Code Generation",
+		Description: "Request for code with language, interface and test expectations",
+		Sections: []TemplateSection{sectionContext, sectionGoal,
+			{Name: "Language & Environment", Description: "Language, framework and runtime versions", Required: true,
+				Aliases:     []string{"environment", "stack", "tech stack", "language"},
+				Cues:        []string{"python", "go", "golang", "javascript", "typescript", "java", "rust", "node", "react", "version", "framework", "library"},
+				Placeholder: "<Language, framework and versions.>"},
+			sectionRequirements,
+			{Name: "Interface", Description: "Function signatures, inputs and outputs", Required: false,
+				Aliases:     []string{"signature", "api", "inputs and outputs"},
+				Cues:        []string{"function", "input", "returns", "signature", "parameter", "argument", "endpoint"},
+				Placeholder: "<Function signatures or API contract.>"},
+			sectionExamples, sectionAcceptance, sectionOutput},
+	},
+	DataAnalysis: {
+		Type: DataAnalysis, Name: "Data Analysis",
+		Description: "Analysis request with dataset, questions and methodology",
+		Sections: []TemplateSection{sectionContext,
+			{Name: "Dataset", Description: "Source, fields, time window and filters", Required: true,
+				Aliases:     []string{"data", "data source", "inputs"},
+				Cues:        []string{"dataset", "data", "table", "column", "field", "csv", "records", "rows"},
+				Placeholder: "<Data source, fields, time window and filters.>"},
+			{Name: "Questions", Description: "The specific questions to answer", Required: true,
+				Aliases:     []string{"research questions", "hypotheses", "goal"},
+				Cues:        []string{"?", "hypothesis", "question", "determine", "find out", "identify"},
+				Placeholder: "1. <Question to answer>"},
+			{Name: "Methodology", Description: "Analysis methods and metrics", Required: false,
+				Aliases:     []string{"method", "approach", "metrics"},
+				Cues:        []string{"regression", "cohort", "correlation", "statistical", "metric", "segment", "trend", "method"},
+				Placeholder: "<Methods, metrics and statistical tests to use.>"},
+			sectionOutput},
+	},
+	CreativeTask: {
+		Type: CreativeTask, Name: "Creative Brief",
+		Description: "Creative request with audience, tone and references",
+		Sections: []TemplateSection{sectionGoal,
+			{Name: "Audience", Description: "Who the work is for", Required: true,
+				Aliases:     []string{"target audience", "readers", "users"},
+				Cues:        []string{"audience", "for users", "customers", "readers", "viewers", "target"},
+				Placeholder: "<Who will see this and what they care about.>"},
+			{Name: "Tone & Style", Description: "Voice, mood and style", Required: true,
+				Aliases:     []string{"tone", "style", "voice"},
+				Cues:        []string{"tone", "style", "voice", "playful", "formal", "casual", "mood"},
+				Placeholder: "<Tone, voice and style guidelines.>"},
+			sectionConstraints, sectionExamples, sectionOutput},
+	},
+	Writing: {
+		Type: Writing, Name: "Writing Brief",
+		Description: "Document or content request with audience, structure and length",
+		Sections: []TemplateSection{sectionGoal,
+			{Name: "Audience", Description: "Who will read the text", Required: true,
+				Aliases:     []string{"target audience", "readers"},
+				Cues:        []string{"audience", "readers", "for developers", "for beginners", "stakeholders", "customers"},
+				Placeholder: "<Intended readers and their background.>"},
+			{Name: "Key Points", Description: "What the text must cover", Required: true,
+				Aliases:     []string{"outline", "topics", "content"},
+				Cues:        []string{"cover", "include", "explain", "discuss", "mention", "outline"},
+				Placeholder: "- <Point to cover>"},
+			{Name: "Tone & Length", Description: "Voice and target length", Required: false,
+				Aliases:     []string{"tone", "length", "style"},
+				Cues:        []string{"words", "pages", "tone", "concise", "formal", "informal", "length"},
+				Placeholder: "<Tone and approximate length.>"},
+			sectionOutput},
+	},
+	ProblemSolving: {
+		Type: ProblemSolving, Name: "Problem Report",
+		Description: "Troubleshooting request with symptoms, attempts and environment",
+		Sections: []TemplateSection{
+			{Name: "Problem", Description: "What is going wrong", Required: true,
+				Aliases:     []string{"issue", "bug", "symptoms"},
+				Cues:        []string{"error", "fails", "broken", "issue", "problem", "doesn't work", "not working", "crash"},
+				Placeholder: "<What happens and what you expected instead.>"},
+			sectionContext,
+			{Name: "What I Tried", Description: "Steps already attempted", Required: false,
+				Aliases:     []string{"attempts", "tried", "steps taken"},
+				Cues:        []string{"tried", "attempted", "already", "checked", "restarted"},
+				Placeholder: "- <Attempt and its result>"},
+			{Name: "Reproduction", Description: "Steps, logs or inputs that reproduce the problem", Required: false,
+				Aliases:     []string{"steps to reproduce", "logs", "repro"},
+				Cues:        []string{"reproduce", "steps", "log", "stack trace", "when i"},
+				Placeholder: "1. <Step to reproduce>"},
+			sectionOutput},
+	},
+	Learning: {
+		Type: Learning, Name: "Learning Request",
+		Description: "Educational request with level, objectives and format",
+		Sections: []TemplateSection{
+			{Name: "Topic", Description: "What you want to learn", Required: true,
+				Aliases:     []string{"subject", "goal"},
+				Cues:        []string{"learn", "understand", "explain", "teach", "how does", "what is"},
+				Placeholder: "<Topic and why you want to learn it.>"},
+			{Name: "Current Level", Description: "What you already know", Required: true,
+				Aliases:     []string{"background", "experience", "level"},
+				Cues:        []string{"beginner", "intermediate", "advanced", "i know", "familiar with", "experience", "new to"},
+				Placeholder: "<Your current knowledge and experience.>"},
+			{Name: "Learning Objectives", Description: "What you should be able to do afterwards", Required: false,
+				Aliases:     []string{"objectives", "outcomes"},
+				Cues:        []string{"be able to", "objective", "by the end", "so that i can"},
+				Placeholder: "- <Skill or outcome>"},
+			sectionOutput},
+	},
+	General: {
+		Type: General, Name: "General Prompt",
+		Description: "Minimal structure that suits most requests",
+		Sections:    []TemplateSection{sectionContext, sectionGoal, sectionConstraints, sectionOutput},
+	},
+}
+
+// GetPromptTemplate returns the built-in template for a prompt type, falling back to General
+func GetPromptTemplate(pt PromptType) PromptTemplate {
+	if tpl, ok := promptTemplates[pt]; ok {
+		return tpl
+	}
+	return promptTemplates[General]
+}
+
+// GetPromptTemplates returns every built-in template in a stable order
+func GetPromptTemplates() []PromptTemplate {
+	order := []PromptType{TechnicalSpec, CodeGeneration, DataAnalysis, CreativeTask, Writing, ProblemSolving, Learning, General}
+	templates := make([]PromptTemplate, 0, len(order))
+	for _, pt := range order {
+		templates = append(templates, promptTemplates[pt])
+	}
+	return templates
+}
+
+// Render returns the template as a markdown skeleton
+func (t PromptTemplate) Render() string {
+	var b strings.Builder
+	for i, section := range t.Sections {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "## %s\n%s\n", section.Name, section.Placeholder)
+	}
+	return b.String()
+}
+
+// LintPromptStructure checks a prompt against the template for its type.
+// An empty prompt type classifies the prompt first.
+func LintPromptStructure(text string, pt PromptType) LintReport {
+	if pt == "" {
+		pt = NewPromptClassifier().ClassifyPrompt(text).PrimaryType
+	}
+	tpl := GetPromptTemplate(pt)
+
+	report := LintReport{
+		PromptType:      tpl.Type,
+		Template:        tpl.Name,
+		PresentSections: []string{},
+		MissingSections: []string{},
+		Findings:        []LintFinding{},
+	}
+
+	headings := extractHeadings(text)
+	lower := strings.ToLower(text)
+	required, requiredPresent := 0, 0
+
+	for _, section := range tpl.Sections {
+		if section.Required {
+			required++
+		}
+
+		byHeading := sectionHasHeading(section, headings)
+		present := byHeading || sectionHasCue(section, lower)
+		if present {
+			report.PresentSections = append(report.PresentSections, section.Name)
+			if section.Required {
+				requiredPresent++
+			}
+			if !byHeading && section.Required && len(headings) > 0 {
+				report.Findings = append(report.Findings, LintFinding{
+					Section:    section.Name,
+					Severity:   "info",
+					Message:    fmt.Sprintf("%s is covered but has no heading", section.Name),
+					Suggestion: fmt.Sprintf("Add a \"## %s\" heading so the section is easy to find", section.Name),
+				})
+			}
+			continue
+		}
+
+		report.MissingSections = append(report.MissingSections, section.Name)
+		severity := "info"
+		if section.Required {
+			severity = "warning"
+		}
+		report.Findings = append(report.Findings, LintFinding{
+			Section:    section.Name,
+			Severity:   severity,
+			Message:    fmt.Sprintf("Missing %s section: %s", section.Name, strings.ToLower(section.Description)),
+			Suggestion: fmt.Sprintf("## %s\n%s", section.Name, section.Placeholder),
+		})
+	}
+
+	if required > 0 {
+		report.Coverage = float64(requiredPresent) / float64(required)
+	} else {
+		report.Coverage = 1
+	}
+
+	return report
+}
+
+// extractHeadings returns normalized markdown headings and "Label:" lines
+func extractHeadings(text string) []string {
+	headings := []string{}
+	for _, re := range headingPatterns {
+		for _, m := range re.FindAllStringSubmatch(text, -1) {
+			heading := strings.ToLower(strings.TrimSpace(strings.TrimSuffix(m[1], ":")))
+			if heading != "" {
+				headings = append(headings, heading)
+			}
+		}
+	}
+	return headings
+}
+
+// sectionHasHeading reports whether one of the headings names the section
+func sectionHasHeading(section TemplateSection, headings []string) bool {
+	names := append([]string{strings.ToLower(section.Name)}, section.Aliases...)
+	for _, heading := range headings {
+		for _, name := range names {
+			if heading == name || strings.HasPrefix(heading, name+" ") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// sectionHasCue reports whether the lowercased text contains one of the section cues
+func sectionHasCue(section TemplateSection, lower string) bool {
+	for _, cue := range section.Cues {
+		if cue == "?" || strings.Contains(cue, " ") || strings.Contains(cue, ".") {
+			if strings.Contains(lower, cue) {
+				return true
+			}
+			continue
+		}
+		if containsWord(lower, cue) {
+			return true
+		}
+	}
+	return false
+}
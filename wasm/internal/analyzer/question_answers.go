@@ -0,0 +1,185 @@
+package analyzer
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// QuestionLink pairs a question with the later sentence that answers it, if any
+type QuestionLink struct {
+	Question   Span     `json:"question"`
+	Answer     *Span    `json:"answer,omitempty"`
+	Answered   bool     `json:"answered"`
+	Confidence float64  `json:"confidence"` // 0-1 score of the best candidate answer
+	Evidence   []string `json:"evidence"`   // Shared words and matched answer pattern
+}
+
+// questionAnswerWindow is how many following sentences may hold the answer
+const questionAnswerWindow = 3
+
+// answeredThreshold is the minimum candidate score that counts as an answer
+const answeredThreshold = 0.4
+
+// answerPatterns are the shapes an answer to each kind of question tends to
+// take. Strong patterns are specific enough to count on their own; weak ones
+// ("is", "in") only back up lexical overlap.
+var answerPatterns = map[string]struct {
+	pattern *regexp.Regexp
+	strong  bool
+}{
+	"yes-no":   {regexp.MustCompile(`(?i)^\W*(?:yes|no|yeah|nope|correct|absolutely|definitely|not really|it does|it doesn't|it is|it isn't)\b`), true},
+	"why":      {regexp.MustCompile(`(?i)\b(?:because|since|due to|the reason|so that|in order to|caused by)\b`), true},
+	"quantity": {regexp.MustCompile(`(?i)\d|\b(?:one|two|three|four|five|six|seven|eight|nine|ten|dozen|hundred|thousand|million|several|a few)\b`), true},
+	"when":     {regexp.MustCompile(`(?i)\b(?:\d{1,2}(?::\d{2})?\s*(?:am|pm)|\d{4}|today|tomorrow|yesterday|tonight|monday|tuesday|wednesday|thursday|friday|saturday|sunday|january|february|march|april|may|june|july|august|september|october|november|december|morning|afternoon|evening|next (?:week|month|year|quarter)|last (?:week|month|year|quarter)|by the end of)\b`), true},
+	"how":      {regexp.MustCompile(`(?i)\b(?:by|using|via|through|first|step|start by)\b`), false},
+	"where":    {regexp.MustCompile(`(?i)\b(?:in|at|on|from|inside|under|near|located)\b`), false},
+	"who":      {regexp.MustCompile(`(?i)\b(?:i|we|he|she|they|team|owner|responsible)\b`), false},
+	"what":     {regexp.MustCompile(`(?i)\b(?:is|are|was|were|means|refers to|called|includes?)\b`), false},
+}
+
+// questionKind names the answer shape a question asks for from its first
+// interrogative word; questions without one are yes/no questions
+func questionKind(question string) string {
+	words := extractWords(question)
+	for i, w := range words {
+		switch w {
+		case "why":
+			return "why"
+		case "when":
+			return "when"
+		case "where":
+			return "where"
+		case "who", "whom", "whose":
+			return "who"
+		case "what", "which":
+			return "what"
+		case "how":
+			if i+1 < len(words) && (words[i+1] == "many" || words[i+1] == "much" || words[i+1] == "long" || words[i+1] == "often") {
+				return "quantity"
+			}
+			return "how"
+		}
+	}
+	return "yes-no"
+}
+
+// answerContentWords lemmatizes the words that carry a sentence's topic
+func answerContentWords(sentence string) map[string]bool {
+	set := map[string]bool{}
+	for _, w := range extractWords(sentence) {
+		if len(w) < 3 || isStopWord(w) || topicFillers.Contains(w) {
+			continue
+		}
+		set[getLemma(w)] = true
+	}
+	return set
+}
+
+// linkQuestionAnswers looks for the answer to each non-rhetorical question in
+// the next few sentences, stopping at the next question. A candidate scores
+// on the share of the question's content words it repeats plus whether it
+// has the shape the question asks for ("because" for why, a number for how
+// many); the immediately following sentence gets a small bonus. Answered
+// questions are dropped from Unanswered; actionable ones stay where they are.
+func linkQuestionAnswers(text string, analysis *QuestionAnalysis) {
+	analysis.Links = []QuestionLink{}
+	analysis.Answered = []string{}
+
+	pending := map[string]int{}
+	for _, q := range analysis.Unanswered {
+		pending[q]++
+	}
+	for _, q := range analysis.Actionable {
+		pending[q]++
+	}
+	if len(pending) == 0 {
+		return
+	}
+
+	answered := map[string]int{}
+	sentences := locateSentences(text)
+	for i, question := range sentences {
+		if pending[question.Text] == 0 {
+			continue
+		}
+		pending[question.Text]--
+
+		link := QuestionLink{Question: question, Evidence: []string{}}
+		kind := questionKind(question.Text)
+		keywords := answerContentWords(question.Text)
+		for j := i + 1; j < len(sentences) && j <= i+questionAnswerWindow; j++ {
+			candidate := sentences[j]
+			sentType := classifySentenceType(candidate.Text).Type
+			if sentType == "question" || (candidate.End < len(text) && text[candidate.End] == '?') {
+				break
+			}
+			if sentType == "instruction" {
+				continue
+			}
+
+			score, evidence := scoreAnswer(kind, keywords, candidate.Text)
+			if j == i+1 && score > 0 {
+				score += 0.1
+			}
+			score = math.Min(score, 1)
+			if score > link.Confidence {
+				answer := candidate
+				link.Answer = &answer
+				link.Confidence = math.Round(score*100) / 100
+				link.Evidence = evidence
+			}
+		}
+
+		link.Answered = link.Confidence >= answeredThreshold
+		if !link.Answered {
+			link.Answer = nil
+			link.Evidence = []string{}
+		} else {
+			analysis.Answered = append(analysis.Answered, question.Text)
+			answered[question.Text]++
+		}
+		analysis.Links = append(analysis.Links, link)
+	}
+
+	unanswered := []string{}
+	for _, q := range analysis.Unanswered {
+		if answered[q] > 0 {
+			answered[q]--
+			continue
+		}
+		unanswered = append(unanswered, q)
+	}
+	analysis.Unanswered = unanswered
+}
+
+// scoreAnswer rates how well candidate answers a question of kind whose
+// content words are keywords: up to 0.6 for the share of keywords it repeats
+// plus 0.2 or 0.4 for having the answer shape the kind asks for
+func scoreAnswer(kind string, keywords map[string]bool, candidate string) (float64, []string) {
+	score := 0.0
+	evidence := []string{}
+	if len(keywords) > 0 {
+		shared := []string{}
+		for w := range answerContentWords(candidate) {
+			if keywords[w] {
+				shared = append(shared, w)
+			}
+		}
+		if len(shared) > 0 {
+			sort.Strings(shared)
+			score += 0.6 * float64(len(shared)) / float64(len(keywords))
+			evidence = append(evidence, "shared words: "+strings.Join(shared, ", "))
+		}
+	}
+	if p := answerPatterns[kind]; p.pattern.MatchString(candidate) {
+		weight := 0.2
+		if p.strong {
+			weight = 0.4
+		}
+		score += weight
+		evidence = append(evidence, kind+" answer pattern: "+strings.ToLower(p.pattern.FindString(candidate)))
+	}
+	return score, evidence
+}
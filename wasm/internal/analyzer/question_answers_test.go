@@ -0,0 +1,64 @@
+package analyzer
+
+import "testing"
+
+func TestLinkQuestionAnswers(t *testing.T) {
+	cases := []struct {
+		name     string
+		text     string
+		answered bool
+		answer   string
+	}{
+		{"why with because", "Why does the build fail? The build fails because the cache is stale.", true, "The build fails because the cache is stale."},
+		{"yes-no", "Does the parser support Unicode? Yes, it handles every script.", true, "Yes, it handles every script."},
+		{"how many", "How many retries should the client make? Three attempts are enough.", true, "Three attempts are enough."},
+		{"overlap on a later sentence", "What is the deadline for the report? I am busy. The report deadline is Friday.", true, "The report deadline is Friday."},
+		{"unrelated follow-up", "What is the deadline? The weather is nice today.", false, ""},
+		{"next question stops the search", "Where is the config file? Why is it missing? The config file is in the repo root.", false, ""},
+	}
+	for _, tc := range cases {
+		analysis := QuestionAnalysis{Unanswered: []string{}, Actionable: []string{}}
+		for _, s := range extractSentences(tc.text) {
+			if classifySentenceType(s).Type == "question" {
+				analysis.Unanswered = append(analysis.Unanswered, s)
+			}
+		}
+		linkQuestionAnswers(tc.text, &analysis)
+		if len(analysis.Links) == 0 {
+			t.Fatalf("%s: expected a link per question", tc.name)
+		}
+		link := analysis.Links[0]
+		if link.Answered != tc.answered {
+			t.Errorf("%s: answered = %v (confidence %.2f, evidence %q), want %v", tc.name, link.Answered, link.Confidence, link.Evidence, tc.answered)
+			continue
+		}
+		if !tc.answered {
+			if link.Answer != nil {
+				t.Errorf("%s: unanswered link should have no answer span", tc.name)
+			}
+			continue
+		}
+		if link.Answer == nil || link.Answer.Text != tc.answer {
+			t.Errorf("%s: answer = %+v, want %q", tc.name, link.Answer, tc.answer)
+			continue
+		}
+		if tc.text[link.Question.Start:link.Question.End] != link.Question.Text ||
+			tc.text[link.Answer.Start:link.Answer.End] != link.Answer.Text {
+			t.Errorf("%s: spans do not match the text", tc.name)
+		}
+		if contains(analysis.Unanswered, link.Question.Text) {
+			t.Errorf("%s: answered question still listed as unanswered", tc.name)
+		}
+	}
+}
+
+func TestAnalyzeIdeasLinksQuestions(t *testing.T) {
+	metrics := AnalyzeIdeas("Why is the dashboard slow? The dashboard is slow because every widget queries the database.")
+	qa := metrics.QuestionAnalysis.Value
+	if len(qa.Links) != 1 || !qa.Links[0].Answered {
+		t.Fatalf("expected one answered link, got %+v", qa.Links)
+	}
+	if len(qa.Unanswered) != 0 || len(qa.Answered) != 1 {
+		t.Errorf("expected the question to move to answered, got unanswered=%q answered=%q", qa.Unanswered, qa.Answered)
+	}
+}
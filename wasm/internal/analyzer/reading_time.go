@@ -0,0 +1,85 @@
+package analyzer
+
+import (
+	"fmt"
+	"math"
+)
+
+// Default words per minute behind the time estimates
+const (
+	DefaultReadingWPM  = 200.0 // Careful silent reading
+	DefaultSpeakingWPM = 150.0 // Presentation pace read aloud
+	DefaultSkimmingWPM = 450.0 // Skimming for the gist
+)
+
+// maxWPM bounds configured speeds to something a person could manage
+const maxWPM = 2000.0
+
+// ReadingSpeedConfig sets the words per minute for the reading, speaking and
+// skimming time estimates; zero keeps the default
+type ReadingSpeedConfig struct {
+	ReadingWPM  float64 `json:"reading_wpm,omitempty"`
+	SpeakingWPM float64 `json:"speaking_wpm,omitempty"`
+	SkimmingWPM float64 `json:"skimming_wpm,omitempty"`
+}
+
+// Validate rejects negative and implausibly fast speeds
+func (c ReadingSpeedConfig) Validate() error {
+	for _, s := range []struct {
+		name string
+		wpm  float64
+	}{{"reading_wpm", c.ReadingWPM}, {"speaking_wpm", c.SpeakingWPM}, {"skimming_wpm", c.SkimmingWPM}} {
+		if math.IsNaN(s.wpm) || s.wpm < 0 || s.wpm > maxWPM {
+			return fmt.Errorf("%s must be between 0 and %.0f, got %g", s.name, maxWPM, s.wpm)
+		}
+	}
+	return nil
+}
+
+// withDefaults fills unset speeds with the defaults
+func (c ReadingSpeedConfig) withDefaults() ReadingSpeedConfig {
+	if c.ReadingWPM == 0 {
+		c.ReadingWPM = DefaultReadingWPM
+	}
+	if c.SpeakingWPM == 0 {
+		c.SpeakingWPM = DefaultSpeakingWPM
+	}
+	if c.SkimmingWPM == 0 {
+		c.SkimmingWPM = DefaultSkimmingWPM
+	}
+	return c
+}
+
+// setTimeEstimates fills the reading, speaking and skimming times in minutes
+func (m *ComplexityMetrics) setTimeEstimates(words int, speeds ReadingSpeedConfig) {
+	speeds = speeds.withDefaults()
+	minutes := func(wpm float64) float64 {
+		return math.Round(float64(words)/wpm*100) / 100
+	}
+
+	m.ReadingTime = NewEnhancedFloatMetric(
+		minutes(speeds.ReadingWPM),
+		fmt.Sprintf("Minutes at %.0f words per minute", speeds.ReadingWPM),
+		"Estimated time to read the text carefully once.",
+		"Long prompts take a reviewer real time to check. Over two minutes, consider moving reference material into attachments or examples.",
+	).WithMethodology("Formula: words / reading WPM")
+
+	m.SpeakingTime = NewEnhancedFloatMetric(
+		minutes(speeds.SpeakingWPM),
+		fmt.Sprintf("Minutes at %.0f words per minute", speeds.SpeakingWPM),
+		"Estimated time to read the text aloud, as in a voice prompt or presentation.",
+		"Use for voice assistants and scripts. Keep spoken instructions under a minute where possible.",
+	).WithMethodology("Formula: words / speaking WPM")
+
+	m.SkimmingTime = NewEnhancedFloatMetric(
+		minutes(speeds.SkimmingWPM),
+		fmt.Sprintf("Minutes at %.0f words per minute", speeds.SkimmingWPM),
+		"Estimated time to skim the text for its gist.",
+		"A lower bound on how long a reader spends before deciding whether to read closely. Headings and lists make skimming effective.",
+	).WithMethodology("Formula: words / skimming WPM")
+}
+
+// formatMinutes renders a time estimate for content characteristics
+func formatMinutes(m float64) string {
+	return fmt.Sprintf("%.1f minutes", m)
+}
@@ -0,0 +1,46 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReadingTimeEstimates(t *testing.T) {
+	text := strings.Repeat("Write clear tests for every parser branch. ", 60) // 420 words
+	metrics := AnalyzeComplexity(text)
+	if got := metrics.WordStats.TotalWords.Value; got != 420 {
+		t.Fatalf("expected 420 words, got %d", got)
+	}
+	if metrics.ReadingTime.Value != 2.1 || metrics.SpeakingTime.Value != 2.8 || metrics.SkimmingTime.Value != 0.93 {
+		t.Errorf("unexpected default times: reading %.2f, speaking %.2f, skimming %.2f",
+			metrics.ReadingTime.Value, metrics.SpeakingTime.Value, metrics.SkimmingTime.Value)
+	}
+	if !strings.Contains(metrics.ReadingTime.Scale, "200 words per minute") {
+		t.Errorf("expected the scale to name the speed, got %q", metrics.ReadingTime.Scale)
+	}
+
+	metrics = AnalyzeComplexityWithSpeeds(text, ReadingSpeedConfig{ReadingWPM: 300, SpeakingWPM: 140})
+	if metrics.ReadingTime.Value != 1.4 || metrics.SpeakingTime.Value != 3 || metrics.SkimmingTime.Value != 0.93 {
+		t.Errorf("unexpected configured times: reading %.2f, speaking %.2f, skimming %.2f",
+			metrics.ReadingTime.Value, metrics.SpeakingTime.Value, metrics.SkimmingTime.Value)
+	}
+
+	profile := profileContent(metrics, IdeaAnalysisMetrics{}, TokenData{})
+	if profile.Characteristics["reading_time"] != "1.4 minutes" || profile.Characteristics["speaking_time"] != "3.0 minutes" {
+		t.Errorf("expected characteristics to follow the metrics, got %v", profile.Characteristics)
+	}
+}
+
+func TestReadingSpeedValidation(t *testing.T) {
+	for _, c := range []ReadingSpeedConfig{{ReadingWPM: -1}, {SpeakingWPM: 5000}} {
+		if err := c.Validate(); err == nil {
+			t.Errorf("expected %+v to be rejected", c)
+		}
+	}
+	if _, err := ParseAnalysisOptions([]byte(`{"reading_speed": {"reading_wpm": -10}}`)); err == nil {
+		t.Error("expected options to validate the reading speed")
+	}
+	if err := (ReadingSpeedConfig{ReadingWPM: 250}).Validate(); err != nil {
+		t.Errorf("expected a valid speed, got %v", err)
+	}
+}
@@ -0,0 +1,201 @@
+package analyzer
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// referencePromptData holds the built-in reference prompts with their grades.
+// Regenerate the grades after a grading change with
+// `go test ./internal/analyzer -run TestReferencePromptGrades -update`.
+//
+//go:embed references/prompts.json
+var referencePromptData []byte
+
+// ReferencePrompt is a built-in prompt of known quality, stored with the
+// grade each engine gives it
+type ReferencePrompt struct {
+	ID      string           `json:"id"`
+	Type    PromptType       `json:"type"`
+	Quality string           `json:"quality"` // "good", "average" or "poor"
+	Text    string           `json:"text"`
+	Grades  []ReferenceGrade `json:"grades"`
+}
+
+// ReferenceGrade is one engine's grade of a reference prompt
+type ReferenceGrade struct {
+	Engine     string             `json:"engine"`
+	Score      float64            `json:"score"`
+	Dimensions map[string]float64 `json:"dimensions"`
+}
+
+var (
+	referencePromptsOnce sync.Once
+	referencePrompts     []ReferencePrompt
+)
+
+// ReferencePrompts returns the built-in reference set, six prompts per
+// built-in type ranging from poor to good
+func ReferencePrompts() []ReferencePrompt {
+	referencePromptsOnce.Do(func() {
+		if err := json.Unmarshal(referencePromptData, &referencePrompts); err != nil {
+			panic(fmt.Sprintf("analyzer: invalid embedded reference prompts: %v", err))
+		}
+	})
+	return referencePrompts
+}
+
+// GradeReferencePrompts grades each prompt with both engines, replacing its
+// stored grades
+func GradeReferencePrompts(refs []ReferencePrompt) []ReferencePrompt {
+	graders := GradersFor(AnalysisOptions{Grader: GraderBoth})
+	out := make([]ReferencePrompt, len(refs))
+	for i, ref := range refs {
+		envelope, _ := GradeAll(graders, AnalyzeForGrading(ref.Text, NewPromptClassifier()))
+		ref.Grades = make([]ReferenceGrade, 0, len(envelope.Results))
+		for _, r := range envelope.Results {
+			ref.Grades = append(ref.Grades, ReferenceGrade{Engine: r.Engine, Score: r.Score, Dimensions: r.Dimensions})
+		}
+		out[i] = ref
+	}
+	return out
+}
+
+// BaselineDimension compares one dimension with the reference prompts
+type BaselineDimension struct {
+	Name            string  `json:"name"`
+	Score           float64 `json:"score"`
+	ReferenceMedian float64 `json:"reference_median"`
+	Percentile      int     `json:"percentile"` // Share of references scoring lower, 0-100
+}
+
+// BaselineComparison places a grade among the reference prompts of its type
+type BaselineComparison struct {
+	Engine          string              `json:"engine"`
+	PromptType      PromptType          `json:"prompt_type"` // Type of the references compared against
+	References      int                 `json:"references"`
+	Percentile      int                 `json:"percentile"` // Share of references scoring lower, 0-100
+	ReferenceMedian float64             `json:"reference_median"`
+	Dimensions      []BaselineDimension `json:"dimensions"` // In dimension name order
+	Weakest         string              `json:"weakest"`    // Dimension with the lowest percentile
+	Strongest       string              `json:"strongest"`  // Dimension with the highest percentile
+	Summary         string              `json:"summary"`
+}
+
+// CompareToBaseline ranks a grade against the reference prompts of its
+// type, graded by the same engine. Prompts of a type without references,
+// such as a trained category, are compared with the whole set. It returns
+// nil when no reference was graded by the engine.
+func CompareToBaseline(grade GradeSummary) *BaselineComparison {
+	pt := PromptType(grade.PromptType)
+	var scores []float64
+	dims := map[string][]float64{}
+	collect := func(match func(ReferencePrompt) bool) {
+		for _, ref := range ReferencePrompts() {
+			if !match(ref) {
+				continue
+			}
+			for _, g := range ref.Grades {
+				if g.Engine != grade.Engine {
+					continue
+				}
+				scores = append(scores, g.Score)
+				for name, v := range g.Dimensions {
+					dims[name] = append(dims[name], v)
+				}
+			}
+		}
+	}
+	collect(func(ref ReferencePrompt) bool { return ref.Type == pt })
+	label := GetPromptTypeDisplayName(pt) + " references"
+	if len(scores) == 0 {
+		pt = ""
+		label = "reference prompts"
+		collect(func(ReferencePrompt) bool { return true })
+	}
+	if len(scores) == 0 {
+		return nil
+	}
+
+	cmp := &BaselineComparison{
+		Engine:          grade.Engine,
+		PromptType:      pt,
+		References:      len(scores),
+		Percentile:      percentileAmong(grade.Score, scores),
+		ReferenceMedian: median(scores),
+		Dimensions:      []BaselineDimension{},
+	}
+	names := make([]string, 0, len(grade.Dimensions))
+	for name := range grade.Dimensions {
+		if len(dims[name]) > 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		d := BaselineDimension{
+			Name:            name,
+			Score:           grade.Dimensions[name],
+			ReferenceMedian: median(dims[name]),
+			Percentile:      percentileAmong(grade.Dimensions[name], dims[name]),
+		}
+		cmp.Dimensions = append(cmp.Dimensions, d)
+		if cmp.Weakest == "" || d.Percentile < cmp.dimension(cmp.Weakest).Percentile {
+			cmp.Weakest = name
+		}
+		if cmp.Strongest == "" || d.Percentile > cmp.dimension(cmp.Strongest).Percentile {
+			cmp.Strongest = name
+		}
+	}
+
+	cmp.Summary = fmt.Sprintf("Scores in the %s percentile of %d %s (median %.0f)", ordinal(cmp.Percentile), cmp.References, label, cmp.ReferenceMedian)
+	if cmp.Weakest != "" {
+		cmp.Summary += "; weakest vs reference: " + dimensionLabel(cmp.Weakest)
+	}
+	return cmp
+}
+
+// dimension returns the named entry of c.Dimensions
+func (c *BaselineComparison) dimension(name string) BaselineDimension {
+	for _, d := range c.Dimensions {
+		if d.Name == name {
+			return d
+		}
+	}
+	return BaselineDimension{}
+}
+
+// percentileAmong is the share of values below v, counting ties as half, 0-100
+func percentileAmong(v float64, values []float64) int {
+	below := 0.0
+	for _, x := range values {
+		if x < v {
+			below++
+		} else if x == v {
+			below += 0.5
+		}
+	}
+	return int(math.Round(below / float64(len(values)) * 100))
+}
+
+// median of values, rounded to two decimals
+func median(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	return math.Round(percentile(sorted, 50)*100) / 100
+}
+
+// dimensionLabel turns a snake_case dimension name into its display form,
+// e.g. "task_complexity" into "Task Complexity"
+func dimensionLabel(name string) string {
+	words := strings.Split(name, "_")
+	for i, w := range words {
+		words[i] = capitalizeFirst(w)
+	}
+	return strings.Join(words, " ")
+}
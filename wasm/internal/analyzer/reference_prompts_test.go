@@ -0,0 +1,120 @@
+package analyzer
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"os"
+	"testing"
+)
+
+// TestReferencePromptGrades checks the stored reference grades match the
+// engines. Run with -update to regrade the set after a grading change.
+func TestReferencePromptGrades(t *testing.T) {
+	refs := ReferencePrompts()
+	graded := GradeReferencePrompts(refs)
+	if *updateGolden {
+		data, err := json.MarshalIndent(graded, "", "  ")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile("references/prompts.json", append(data, '\n'), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	perType := map[PromptType]int{}
+	for i, ref := range refs {
+		perType[ref.Type]++
+		if len(ref.Grades) != len(graded[i].Grades) {
+			t.Fatalf("%s: stored %d grades, engines give %d (run with -update)", ref.ID, len(ref.Grades), len(graded[i].Grades))
+		}
+		for j, g := range ref.Grades {
+			if want := graded[i].Grades[j]; g.Engine != want.Engine || math.Abs(g.Score-want.Score) > 0.01 {
+				t.Errorf("%s: stored %s score %.2f, engine gives %.2f (run with -update)", ref.ID, g.Engine, g.Score, want.Score)
+			}
+		}
+	}
+	for _, pt := range []PromptType{TechnicalSpec, CodeGeneration, DataAnalysis, Writing, CreativeTask, ProblemSolving, Learning, General} {
+		if perType[pt] < 5 {
+			t.Errorf("%s has %d reference prompts", pt, perType[pt])
+		}
+	}
+}
+
+// TestReferencePromptsRankByQuality checks the modern engine scores every
+// good reference above every poor one of the same type. The classic engine's
+// scores sit within a few points of each other, which is why its results are
+// better read as percentiles of the references.
+func TestReferencePromptsRankByQuality(t *testing.T) {
+	lowestGood, highestPoor := map[PromptType]float64{}, map[PromptType]float64{}
+	for _, ref := range ReferencePrompts() {
+		for _, g := range ref.Grades {
+			if g.Engine != GraderModern {
+				continue
+			}
+			switch ref.Quality {
+			case "good":
+				if v, ok := lowestGood[ref.Type]; !ok || g.Score < v {
+					lowestGood[ref.Type] = g.Score
+				}
+			case "poor":
+				highestPoor[ref.Type] = math.Max(highestPoor[ref.Type], g.Score)
+			}
+		}
+	}
+	for pt, good := range lowestGood {
+		if good <= highestPoor[pt] {
+			t.Errorf("%s: a good reference scores %.1f, a poor one %.1f", pt, good, highestPoor[pt])
+		}
+	}
+}
+
+func TestCompareToBaseline(t *testing.T) {
+	var poor, good ReferencePrompt
+	for _, ref := range ReferencePrompts() {
+		if ref.Type == TechnicalSpec && ref.Quality == "poor" && poor.ID == "" {
+			poor = ref
+		}
+		if ref.Type == TechnicalSpec && ref.Quality == "good" && good.ID == "" {
+			good = ref
+		}
+	}
+	grade := func(text string) GradeSummary {
+		envelope, _ := GradeAll(GradersFor(AnalysisOptions{}), AnalyzeForGrading(text, NewPromptClassifier()))
+		return envelope.Results[0]
+	}
+
+	low, high := CompareToBaseline(grade(poor.Text)), CompareToBaseline(grade(good.Text))
+	if low == nil || high == nil {
+		t.Fatal("expected comparisons")
+	}
+	if low.Percentile >= high.Percentile {
+		t.Errorf("poor spec in the %d percentile, good spec in the %d", low.Percentile, high.Percentile)
+	}
+	if low.Engine != GraderClassic || low.References == 0 || len(low.Dimensions) != 8 || low.Weakest == "" {
+		t.Errorf("comparison = %+v", low)
+	}
+	if !bytes.Contains([]byte(low.Summary), []byte("weakest vs reference: "+dimensionLabel(low.Weakest))) {
+		t.Errorf("summary = %q", low.Summary)
+	}
+
+	// A trained category is compared with the whole set
+	all := CompareToBaseline(GradeSummary{Engine: GraderClassic, PromptType: "legal_review", Score: 50})
+	if all == nil || all.PromptType != "" || all.References != len(ReferencePrompts()) {
+		t.Errorf("fallback comparison = %+v", all)
+	}
+	if CompareToBaseline(GradeSummary{Engine: "custom", Score: 50}) != nil {
+		t.Error("compared an engine no reference was graded by")
+	}
+}
+
+func TestPercentileAmong(t *testing.T) {
+	values := []float64{10, 20, 30, 40}
+	for v, want := range map[float64]int{5: 0, 25: 50, 30: 63, 50: 100} {
+		if got := percentileAmong(v, values); got != want {
+			t.Errorf("percentileAmong(%v) = %d, want %d", v, got, want)
+		}
+	}
+}
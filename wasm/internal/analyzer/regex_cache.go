@@ -0,0 +1,73 @@
+package analyzer
+
+import (
+	"regexp"
+	"sync"
+	"sync/atomic"
+)
+
+// Patterns shared by several analyzers, compiled once at package init
+var (
+	nonWordCharPattern   = regexp.MustCompile(`[^\w]`)
+	digitsPattern        = regexp.MustCompile(`\d+`)
+	whitespaceRunPattern = regexp.MustCompile(`\s+`)
+	yearPattern          = regexp.MustCompile(`\b(19|20)\d{2}\b`)
+	numberedLinePattern  = regexp.MustCompile(`^\d+[\.\)]`)
+	alphaWordPattern     = regexp.MustCompile(`\b[a-zA-Z]+\b`)
+	urlPattern           = regexp.MustCompile(`https?://[^\s]+`)
+	emailPattern         = regexp.MustCompile(`[a-zA-Z0-9._%+-]{1,64}@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}`)
+	hashtagPattern       = regexp.MustCompile(`#\w+`)
+	atMentionPattern     = regexp.MustCompile(`@\w+`)
+)
+
+// regexCache holds patterns that are only known at runtime, such as keyword
+// boundaries and classifier rules; invalid patterns are cached as nil
+var regexCache sync.Map
+
+// regexCacheCounters back RegexCacheStats
+var regexCacheCounters struct {
+	entries, hits, misses atomic.Int64
+}
+
+// RegexCacheStats reports the size and hit rate of the runtime pattern cache
+func RegexCacheStats() CacheStats {
+	return CacheStats{
+		Entries: regexCacheCounters.entries.Load(),
+		Hits:    regexCacheCounters.hits.Load(),
+		Misses:  regexCacheCounters.misses.Load(),
+		Limit:   CurrentRuntimeConfig().RegexCacheEntries,
+	}
+}
+
+// trimRegexCache empties the cache once it holds more than limit patterns; hot
+// patterns are recompiled on their next use. A limit of 0 never trims.
+func trimRegexCache(limit int64) {
+	if limit <= 0 || regexCacheCounters.entries.Load() <= limit {
+		return
+	}
+	regexCache.Range(func(key, _ interface{}) bool {
+		if _, loaded := regexCache.LoadAndDelete(key); loaded {
+			regexCacheCounters.entries.Add(-1)
+		}
+		return true
+	})
+}
+
+// cachedRegexp compiles a runtime pattern once and reuses it, returning nil if it is invalid
+func cachedRegexp(pattern string) *regexp.Regexp {
+	if re, ok := regexCache.Load(pattern); ok {
+		regexCacheCounters.hits.Add(1)
+		return re.(*regexp.Regexp)
+	}
+	regexCacheCounters.misses.Add(1)
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		re = nil
+	}
+	if _, loaded := regexCache.LoadOrStore(pattern, re); !loaded {
+		if regexCacheCounters.entries.Add(1) > CurrentRuntimeConfig().RegexCacheEntries {
+			trimRegexCache(CurrentRuntimeConfig().RegexCacheEntries)
+		}
+	}
+	return re
+}
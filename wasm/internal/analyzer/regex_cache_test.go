@@ -0,0 +1,77 @@
+package analyzer
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var benchmarkText = strings.Repeat("In 2023 the platform team shipped 12 services. "+
+	"Please review the API design and fix the login bug by Friday. "+
+	"Research shows 40% of users never finish onboarding (2021). "+
+	"Contact ops@example.com or visit https://example.com/docs for details. ", 40)
+
+// BenchmarkContainsWordUncached compiles the keyword pattern on every call, as the code used to
+func BenchmarkContainsWordUncached(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		regexp.MatchString(`(?i)\b`+regexp.QuoteMeta("review")+`\b`, benchmarkText)
+	}
+}
+
+// BenchmarkContainsWordCached reuses the compiled pattern from the cache
+func BenchmarkContainsWordCached(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		containsWord(benchmarkText, "review")
+	}
+}
+
+func BenchmarkClassifyPrompt(b *testing.B) {
+	pc := NewPromptClassifier()
+	for i := 0; i < b.N; i++ {
+		pc.ClassifyPrompt(benchmarkText)
+	}
+}
+
+func BenchmarkAnalyzeIdeas(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		AnalyzeIdeas(benchmarkText)
+	}
+}
+
+func BenchmarkPreprocessText(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		PreprocessText(benchmarkText)
+	}
+}
+
+func TestCachedRegexp(t *testing.T) {
+	re := cachedRegexp(`\bfoo\b`)
+	if re == nil || !re.MatchString("a foo b") {
+		t.Fatal("expected valid pattern to compile and match")
+	}
+	if cachedRegexp(`\bfoo\b`) != re {
+		t.Error("expected the same compiled pattern to be reused")
+	}
+	if cachedRegexp(`(unclosed`) != nil {
+		t.Error("expected invalid pattern to return nil")
+	}
+}
+
+func TestRegexCacheStaysUnderLimit(t *testing.T) {
+	defer UpdateRuntimeConfig(func(c *RuntimeConfig) error {
+		*c = DefaultRuntimeConfig()
+		return nil
+	})
+	if _, err := PatchRuntimeConfig([]byte(`{"regex_cache_entries": 8}`)); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 50; i++ {
+		if re := cachedRegexp(fmt.Sprintf(`\bword%d\b`, i)); re == nil || !re.MatchString(fmt.Sprintf("a word%d b", i)) {
+			t.Fatalf("pattern %d broken", i)
+		}
+		if n := RegexCacheStats().Entries; n > 8 {
+			t.Fatalf("cache holds %d entries, limit is 8", n)
+		}
+	}
+}
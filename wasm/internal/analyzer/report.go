@@ -0,0 +1,385 @@
+package analyzer
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"math"
+	"strings"
+)
+
+// Report formats accepted by RenderReport
+const (
+	ReportMarkdown = "markdown"
+	ReportHTML     = "html"
+	ReportPDF      = "pdf"
+)
+
+// ReportContentTypes maps each report format to its MIME type
+var ReportContentTypes = map[string]string{
+	ReportMarkdown: "text/markdown; charset=utf-8",
+	ReportHTML:     "text/html; charset=utf-8",
+	ReportPDF:      "application/pdf",
+}
+
+// RenderReport writes a self-contained report of an analysis: overall grade,
+// dimension scores, suggestions and the task graph
+func RenderReport(w io.Writer, result *CombinedResult, format, title string) error {
+	if title == "" {
+		title = "Prompt quality report"
+	}
+	switch format {
+	case ReportMarkdown, "":
+		_, err := io.WriteString(w, RenderMarkdownReport(result, title))
+		return err
+	case ReportHTML:
+		return htmlReportTemplate.Execute(w, newReportView(result, title))
+	case ReportPDF:
+		return RenderPDFReport(w, result, title)
+	default:
+		return fmt.Errorf("unknown report format %q (expected %q, %q or %q)", format, ReportMarkdown, ReportHTML, ReportPDF)
+	}
+}
+
+// RenderMarkdownReport renders the report as GitHub-flavored markdown, with the
+// task graph as a Mermaid flowchart
+func RenderMarkdownReport(result *CombinedResult, title string) string {
+	view := newReportView(result, title)
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "# %s\n\n", view.Title)
+	fmt.Fprintf(&sb, "**Overall grade: %s** (%.1f/100, ~%s percentile)  \n%s\n\n",
+		view.Overall.Grade, view.Overall.Score, ordinal(view.Overall.Percentile), view.Overall.Summary)
+
+	sb.WriteString("## Dimensions\n\n")
+	sb.WriteString("| Dimension | Score | Grade | Assessment | |\n")
+	sb.WriteString("|---|---:|:---:|---|---|\n")
+	for _, d := range view.Dimensions {
+		fmt.Fprintf(&sb, "| %s | %.1f | %s | %s | `%s` |\n",
+			d.Name, d.Score, markdownCell(d.Grade), markdownCell(d.Label), scoreBar(d.Score))
+	}
+	sb.WriteString("\n")
+
+	if len(view.Strengths) > 0 {
+		sb.WriteString("**Strengths:** " + strings.Join(view.Strengths, "; ") + "  \n")
+	}
+	if len(view.WeakAreas) > 0 {
+		sb.WriteString("**Weak areas:** " + strings.Join(view.WeakAreas, "; ") + "\n")
+	}
+	sb.WriteString("\n")
+
+	sb.WriteString("## Suggestions\n\n")
+	if len(view.Suggestions) == 0 {
+		sb.WriteString("No suggestions.\n\n")
+	}
+	for i, s := range view.Suggestions {
+		fmt.Fprintf(&sb, "%d. **%s** `%s` %s · %s  \n", i+1, s.Message, s.Rule, s.Priority, s.Dimension)
+		fmt.Fprintf(&sb, "   _%s_", s.Impact)
+		if s.Example != "" {
+			fmt.Fprintf(&sb, "  \n   %s", s.Example)
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString("\n")
+
+	sb.WriteString("## Task graph\n\n")
+	graph := result.TaskGraph
+	if graph.TotalTasks == 0 {
+		sb.WriteString("No tasks were extracted.\n\n")
+	} else {
+		fmt.Fprintf(&sb, "%d tasks, %d relationships, critical path of %d tasks (%.1f effort points).\n\n",
+			graph.TotalTasks, len(graph.Relationships), len(graph.CriticalPath), graph.CriticalPathEffort)
+		sb.WriteString("```mermaid\n" + TaskGraphMermaid(&graph) + "```\n\n")
+		for _, t := range view.Tasks {
+			fmt.Fprintf(&sb, "%s- **%s** (%s, %s effort)", strings.Repeat("  ", t.Depth), t.Title, t.Priority, t.Effort)
+			if t.Actor != "" {
+				fmt.Fprintf(&sb, " — %s", t.Actor)
+			}
+			sb.WriteString("\n")
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(result.DegradedStages) > 0 {
+		sb.WriteString("## Degraded stages\n\n")
+		for _, d := range result.DegradedStages {
+			fmt.Fprintf(&sb, "- %s (%s): %s\n", d.Stage, d.Mode, d.Reason)
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(result.PartialFailure) > 0 {
+		sb.WriteString("## Failed stages\n\n")
+		for _, f := range result.PartialFailure {
+			fmt.Fprintf(&sb, "- %s: %s\n", f.Stage, f.Error)
+		}
+		sb.WriteString("\n")
+	}
+
+	fmt.Fprintf(&sb, "---\n_Generated by fulcrum, result schema %s._\n", result.SchemaVersion)
+	return sb.String()
+}
+
+// TaskGraphMermaid renders the task graph as a Mermaid flowchart. Dependencies
+// and subtask links are drawn as arrows; other relationships as dotted lines.
+func TaskGraphMermaid(graph *TaskGraph) string {
+	var sb strings.Builder
+	sb.WriteString("flowchart TD\n")
+	for _, t := range graph.Tasks {
+		fmt.Fprintf(&sb, "    %s[\"%s\"]\n", mermaidID(t.ID), mermaidLabel(t.Title))
+	}
+	for _, r := range graph.Relationships {
+		arrow := "-.->"
+		switch r.RelationType {
+		case "depends_on", "blocks":
+			arrow = "-->"
+		case "subtask":
+			arrow = "==>"
+		}
+		fmt.Fprintf(&sb, "    %s %s|%s| %s\n", mermaidID(r.FromTaskID), arrow, r.RelationType, mermaidID(r.ToTaskID))
+	}
+	if len(graph.CriticalPath) > 0 {
+		sb.WriteString("    classDef critical stroke:#F44336,stroke-width:2px\n")
+		for _, id := range graph.CriticalPath {
+			fmt.Fprintf(&sb, "    class %s critical\n", mermaidID(id))
+		}
+	}
+	return sb.String()
+}
+
+// mermaidID keeps task IDs to characters Mermaid accepts in node names
+func mermaidID(id string) string {
+	return "t_" + nonWordCharPattern.ReplaceAllString(id, "_")
+}
+
+// mermaidLabel escapes characters that would end a quoted Mermaid label
+func mermaidLabel(s string) string {
+	return strings.NewReplacer(`"`, "#quot;", "\n", " ").Replace(s)
+}
+
+// ordinal formats n as 1st, 2nd, 3rd, 4th, ...
+func ordinal(n int) string {
+	suffix := "th"
+	if n%100 < 11 || n%100 > 13 {
+		switch n % 10 {
+		case 1:
+			suffix = "st"
+		case 2:
+			suffix = "nd"
+		case 3:
+			suffix = "rd"
+		}
+	}
+	return fmt.Sprintf("%d%s", n, suffix)
+}
+
+// markdownCell escapes pipes so text stays inside its table cell
+func markdownCell(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
+
+// scoreBar draws a 0-100 score as a ten-character bar
+func scoreBar(score float64) string {
+	filled := int(math.Round(math.Max(0, math.Min(100, score)) / 10))
+	return strings.Repeat("█", filled) + strings.Repeat("░", 10-filled)
+}
+
+// reportView is the data shared by the markdown and HTML renderers
+type reportView struct {
+	Title         string
+	Overall       OverallGrade
+	Dimensions    []reportDimension
+	Strengths     []string
+	WeakAreas     []string
+	Suggestions   []Suggestion
+	Tasks         []reportTask
+	TaskCount     int
+	Degraded      []StageDegradation
+	Failed        []StageFailure
+	SchemaVersion string
+	Radar         radarChart
+}
+
+type reportDimension struct {
+	Name  string
+	Score float64
+	Grade string
+	Label string
+}
+
+type reportTask struct {
+	Depth    int
+	Indent   int // Depth in pixels for HTML
+	Title    string
+	Priority string
+	Effort   string
+	Actor    string
+	Critical bool
+}
+
+func newReportView(result *CombinedResult, title string) reportView {
+	grade := leadingGradeOf(result)
+	view := reportView{
+		Title:         title,
+		Overall:       grade.Overall,
+		Dimensions:    grade.Dimensions,
+		Strengths:     grade.Strengths,
+		WeakAreas:     grade.WeakAreas,
+		Suggestions:   grade.Suggestions,
+		TaskCount:     result.TaskGraph.TotalTasks,
+		Degraded:      result.DegradedStages,
+		Failed:        result.PartialFailure,
+		SchemaVersion: result.SchemaVersion,
+	}
+	critical := make(map[string]bool)
+	for _, id := range result.TaskGraph.CriticalPath {
+		critical[id] = true
+	}
+	result.TaskGraph.WalkTree(func(t Task, depth int) {
+		view.Tasks = append(view.Tasks, reportTask{
+			Depth: depth, Indent: depth * 20, Title: t.Title, Priority: t.Priority,
+			Effort: t.EstimatedEffort, Actor: t.Actor, Critical: critical[t.ID],
+		})
+	})
+	view.Radar = newRadarChart(view.Dimensions)
+	return view
+}
+
+// Radar chart geometry, in SVG user units
+const (
+	radarSize   = 360.0
+	radarRadius = 120.0
+)
+
+// radarChart holds precomputed SVG coordinates for the dimension radar
+type radarChart struct {
+	Size    float64
+	Center  float64
+	Rings   []string // Polygon points at 25, 50, 75 and 100
+	Axes    []radarAxis
+	Polygon string // Polygon points for the scores
+}
+
+type radarAxis struct {
+	X, Y           float64 // Axis end
+	LabelX, LabelY float64
+	Anchor         string
+	Label          string
+}
+
+func newRadarChart(dims []reportDimension) radarChart {
+	chart := radarChart{Size: radarSize, Center: radarSize / 2}
+	if len(dims) == 0 {
+		return chart
+	}
+	center := chart.Center
+	point := func(i int, fraction float64) (float64, float64) {
+		angle := 2*math.Pi*float64(i)/float64(len(dims)) - math.Pi/2
+		x := center + math.Cos(angle)*radarRadius*fraction
+		y := center + math.Sin(angle)*radarRadius*fraction
+		return math.Round(x*10) / 10, math.Round(y*10) / 10
+	}
+	polygon := func(fraction func(i int) float64) string {
+		points := make([]string, len(dims))
+		for i := range dims {
+			x, y := point(i, fraction(i))
+			points[i] = fmt.Sprintf("%.1f,%.1f", x, y)
+		}
+		return strings.Join(points, " ")
+	}
+
+	for _, ring := range []float64{0.25, 0.5, 0.75, 1} {
+		chart.Rings = append(chart.Rings, polygon(func(int) float64 { return ring }))
+	}
+	for i, d := range dims {
+		x, y := point(i, 1)
+		lx, ly := point(i, 1.15)
+		anchor := "middle"
+		if lx < center-1 {
+			anchor = "end"
+		} else if lx > center+1 {
+			anchor = "start"
+		}
+		chart.Axes = append(chart.Axes, radarAxis{X: x, Y: y, LabelX: lx, LabelY: ly + 4, Anchor: anchor, Label: d.Name})
+	}
+	chart.Polygon = polygon(func(i int) float64 { return math.Max(0, math.Min(100, dims[i].Score)) / 100 })
+	return chart
+}
+
+var htmlReportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"score": func(f float64) string { return fmt.Sprintf("%.1f", f) },
+	"bar":   func(f float64) string { return fmt.Sprintf("%.0f%%", math.Max(0, math.Min(100, f))) },
+	"inc":   func(i int) int { return i + 1 },
+	"ord":   ordinal,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Roboto, sans-serif; color: #222; max-width: 860px; margin: 2em auto; padding: 0 1em; }
+h1 { margin-bottom: 0.2em; }
+.overall { display: flex; align-items: center; gap: 1em; margin-bottom: 1.5em; }
+.badge { font-size: 2.4em; font-weight: 700; color: #fff; border-radius: 8px; padding: 0.2em 0.5em; }
+table { border-collapse: collapse; width: 100%; margin-bottom: 1.5em; }
+th, td { text-align: left; padding: 0.4em 0.6em; border-bottom: 1px solid #eee; }
+.meter { background: #eee; border-radius: 4px; height: 8px; width: 120px; }
+.meter span { display: block; height: 100%; border-radius: 4px; background: #4CAF50; }
+.radar { display: block; margin: 0 auto 1.5em; }
+.suggestion { border-left: 4px solid #999; padding: 0.3em 0.8em; margin-bottom: 0.8em; }
+.suggestion.critical, .suggestion.high { border-color: #F44336; }
+.suggestion.medium { border-color: #FF9800; }
+.rule { font-family: monospace; color: #666; }
+.critical-task { font-weight: 700; }
+footer { color: #888; font-size: 0.85em; margin-top: 2em; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<div class="overall">
+<span class="badge" style="background: {{.Overall.GradeColor}}">{{.Overall.Grade}}</span>
+<div><strong>{{score .Overall.Score}}/100</strong> · ~{{ord .Overall.Percentile}} percentile<br>{{.Overall.Summary}}</div>
+</div>
+
+<h2>Dimensions</h2>
+<svg class="radar" width="{{.Radar.Size}}" height="{{.Radar.Size}}" viewBox="0 0 {{.Radar.Size}} {{.Radar.Size}}" role="img" aria-label="Dimension scores">
+{{range .Radar.Rings}}<polygon points="{{.}}" fill="none" stroke="#ddd"/>
+{{end}}{{range .Radar.Axes}}<line x1="{{$.Radar.Center}}" y1="{{$.Radar.Center}}" x2="{{.X}}" y2="{{.Y}}" stroke="#ddd"/>
+<text x="{{.LabelX}}" y="{{.LabelY}}" text-anchor="{{.Anchor}}" font-size="11">{{.Label}}</text>
+{{end}}<polygon points="{{.Radar.Polygon}}" fill="rgba(33,150,243,0.25)" stroke="#2196F3" stroke-width="2"/>
+</svg>
+<table>
+<tr><th>Dimension</th><th>Score</th><th></th><th>Grade</th><th>Assessment</th></tr>
+{{range .Dimensions}}<tr><td>{{.Name}}</td><td>{{score .Score}}</td><td><div class="meter"><span style="width: {{bar .Score}}"></span></div></td><td>{{.Grade}}</td><td>{{.Label}}</td></tr>
+{{end}}</table>
+{{if .Strengths}}<p><strong>Strengths:</strong> {{range $i, $s := .Strengths}}{{if $i}}; {{end}}{{$s}}{{end}}</p>{{end}}
+{{if .WeakAreas}}<p><strong>Weak areas:</strong> {{range $i, $s := .WeakAreas}}{{if $i}}; {{end}}{{$s}}{{end}}</p>{{end}}
+
+<h2>Suggestions</h2>
+{{range $i, $s := .Suggestions}}<div class="suggestion {{$s.Priority}}">
+<strong>{{inc $i}}. {{$s.Message}}</strong> <span class="rule">{{$s.Rule}}</span> · {{$s.Priority}} · {{$s.Dimension}}<br>
+<em>{{$s.Impact}}</em>{{if $s.Example}}<br>{{$s.Example}}{{end}}
+</div>
+{{else}}<p>No suggestions.</p>
+{{end}}
+<h2>Task graph</h2>
+{{if .Tasks}}<p>{{.TaskCount}} tasks; the critical path is in bold.</p>
+<ul>
+{{range .Tasks}}<li style="margin-left: {{.Indent}}px"{{if .Critical}} class="critical-task"{{end}}>{{.Title}} ({{.Priority}}, {{.Effort}} effort){{if .Actor}} — {{.Actor}}{{end}}</li>
+{{end}}</ul>
+{{else}}<p>No tasks were extracted.</p>
+{{end}}
+{{if .Degraded}}<h2>Degraded stages</h2>
+<ul>
+{{range .Degraded}}<li>{{.Stage}} ({{.Mode}}): {{.Reason}}</li>
+{{end}}</ul>
+{{end}}
+{{if .Failed}}<h2>Failed stages</h2>
+<ul>
+{{range .Failed}}<li>{{.Stage}}: {{.Error}}</li>
+{{end}}</ul>
+{{end}}
+<footer>Generated by fulcrum, result schema {{.SchemaVersion}}.</footer>
+</body>
+</html>
+`))
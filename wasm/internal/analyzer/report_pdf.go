@@ -0,0 +1,365 @@
+package analyzer
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Report palette
+var (
+	pdfText    = pdfColor{0.13, 0.13, 0.13}
+	pdfMuted   = pdfColor{0.45, 0.45, 0.45}
+	pdfRule    = pdfColor{0.87, 0.87, 0.87}
+	pdfAccent  = pdfColor{0.13, 0.59, 0.95}
+	pdfWhite   = pdfColor{1, 1, 1}
+	pdfRed     = parsePDFColor("#F44336")
+	pdfOrange  = parsePDFColor("#FF9800")
+	pdfGreen   = parsePDFColor("#4CAF50")
+	pdfBoxFill = pdfColor{0.96, 0.97, 0.99}
+)
+
+// RenderPDFReport writes the report as a PDF with the same sections as the HTML
+// report. The task graph is drawn as the flowchart TaskGraphMermaid describes,
+// laid out in dependency order and scaled to fit a page.
+func RenderPDFReport(w io.Writer, result *CombinedResult, title string) error {
+	view := newReportView(result, title)
+	doc := newPDFDocument(title)
+	width := doc.contentWidth()
+
+	// Title and overall grade
+	for _, line := range wrapText(view.Title, 20, width, pdfBold) {
+		doc.y += 22
+		doc.text(pdfMargin, doc.y, 20, pdfBold, pdfText, line)
+	}
+	doc.y += 18
+	badge := parsePDFColor(view.Overall.GradeColor)
+	doc.rect(pdfMargin, doc.y, 64, 44, badge)
+	doc.text(pdfMargin+32-textWidth(view.Overall.Grade, 24, pdfBold)/2, doc.y+31, 24, pdfBold, pdfWhite, view.Overall.Grade)
+	doc.text(pdfMargin+78, doc.y+17, 12, pdfBold, pdfText,
+		fmt.Sprintf("%.1f/100 · ~%s percentile", view.Overall.Score, ordinal(view.Overall.Percentile)))
+	doc.text(pdfMargin+78, doc.y+34, 10, pdfRegular, pdfMuted, truncateText(view.Overall.Summary, 10, width-78, pdfRegular))
+	doc.y += 64
+
+	// Dimensions: radar on the left, score bars on the right
+	pdfHeading(doc, "Dimensions")
+	doc.ensureSpace(radarSize * 0.6)
+	drawPDFRadar(doc, view.Radar, pdfMargin, doc.y, 0.6)
+	barX := pdfMargin + radarSize*0.6 + 10
+	rowY := doc.y + 26
+	for _, d := range view.Dimensions {
+		doc.text(barX, rowY, 9, pdfBold, pdfText, d.Name)
+		doc.text(barX+100, rowY, 9, pdfRegular, pdfText, fmt.Sprintf("%.1f %s", d.Score, d.Grade))
+		doc.rect(barX+150, rowY-7, 100, 7, pdfRule)
+		doc.rect(barX+150, rowY-7, clampScore(d.Score), 7, scoreColor(d.Score))
+		doc.text(barX+150, rowY+10, 7, pdfRegular, pdfMuted, d.Label)
+		rowY += 24
+	}
+	doc.y += radarSize*0.6 + 10
+	pdfParagraph(doc, "Strengths: "+strings.Join(view.Strengths, "; "), 10, pdfRegular, pdfText)
+	pdfParagraph(doc, "Weak areas: "+strings.Join(view.WeakAreas, "; "), 10, pdfRegular, pdfText)
+
+	// Suggestions
+	pdfHeading(doc, "Suggestions")
+	if len(view.Suggestions) == 0 {
+		pdfParagraph(doc, "No suggestions.", 10, pdfRegular, pdfMuted)
+	}
+	for i, s := range view.Suggestions {
+		color := pdfMuted
+		switch s.Priority {
+		case "critical", "high":
+			color = pdfRed
+		case "medium":
+			color = pdfOrange
+		}
+		parts := []pdfTextBlock{
+			{fmt.Sprintf("%d. %s", i+1, s.Message), 10, pdfBold, pdfText},
+			{fmt.Sprintf("%s · %s · %s", s.Rule, s.Priority, s.Dimension), 8, pdfRegular, pdfMuted},
+			{s.Impact, 9, pdfItalic, pdfText},
+		}
+		if s.Example != "" {
+			parts = append(parts, pdfTextBlock{s.Example, 9, pdfRegular, pdfMuted})
+		}
+		// Keep each suggestion on one page so its priority bar stays beside it
+		height := 0.0
+		for _, part := range parts {
+			height += float64(len(wrapText(part.text, part.size, doc.contentWidth(), part.font))) * part.size * 1.4
+		}
+		doc.ensureSpace(height)
+		start := doc.y
+		for _, part := range parts {
+			pdfParagraph(doc, part.text, part.size, part.font, part.color)
+		}
+		doc.rect(pdfMargin-8, start+4, 3, doc.y-start, color)
+		doc.y += 6
+	}
+
+	// Task graph
+	pdfHeading(doc, "Task graph")
+	graph := result.TaskGraph
+	if graph.TotalTasks == 0 {
+		pdfParagraph(doc, "No tasks were extracted.", 10, pdfRegular, pdfMuted)
+	} else {
+		pdfParagraph(doc, fmt.Sprintf("%d tasks, %d relationships, critical path of %d tasks (%.1f effort points). Critical tasks are outlined in red.",
+			graph.TotalTasks, len(graph.Relationships), len(graph.CriticalPath), graph.CriticalPathEffort), 9, pdfRegular, pdfMuted)
+		drawPDFTaskGraph(doc, &graph)
+		for _, t := range view.Tasks {
+			line := fmt.Sprintf("%s• %s (%s, %s effort)", strings.Repeat("    ", t.Depth), t.Title, t.Priority, t.Effort)
+			if t.Actor != "" {
+				line += " — " + t.Actor
+			}
+			font := pdfRegular
+			if t.Critical {
+				font = pdfBold
+			}
+			pdfParagraph(doc, line, 9, font, pdfText)
+		}
+	}
+
+	if len(view.Degraded) > 0 {
+		pdfHeading(doc, "Degraded stages")
+		for _, d := range view.Degraded {
+			pdfParagraph(doc, fmt.Sprintf("%s (%s): %s", d.Stage, d.Mode, d.Reason), 9, pdfRegular, pdfText)
+		}
+	}
+
+	if len(view.Failed) > 0 {
+		pdfHeading(doc, "Failed stages")
+		for _, f := range view.Failed {
+			pdfParagraph(doc, fmt.Sprintf("%s: %s", f.Stage, f.Error), 9, pdfRegular, pdfText)
+		}
+	}
+
+	// Footers go on last, once the page count is known
+	current := doc.page
+	for i, page := range doc.pages {
+		doc.page = page
+		footer := fmt.Sprintf("Generated by fulcrum, result schema %s · page %d of %d", view.SchemaVersion, i+1, len(doc.pages))
+		doc.text(pdfMargin, pdfPageHeight-pdfMargin/2, 7, pdfRegular, pdfMuted, footer)
+	}
+	doc.page = current
+
+	_, err := doc.WriteTo(w)
+	return err
+}
+
+// pdfTextBlock is a run of text in one style
+type pdfTextBlock struct {
+	text  string
+	size  float64
+	font  string
+	color pdfColor
+}
+
+// pdfHeading starts a section, moving to a new page if the heading would be orphaned
+func pdfHeading(doc *pdfDocument, title string) {
+	doc.ensureSpace(60)
+	doc.y += 24
+	doc.text(pdfMargin, doc.y, 14, pdfBold, pdfText, title)
+	doc.y += 6
+	doc.line(pdfMargin, doc.y, pdfMargin+doc.contentWidth(), doc.y, 0.5, pdfRule, false)
+	doc.y += 4
+}
+
+// pdfParagraph writes wrapped text at the cursor, breaking pages between lines
+func pdfParagraph(doc *pdfDocument, s string, size float64, font string, color pdfColor) {
+	for _, line := range wrapText(s, size, doc.contentWidth(), font) {
+		doc.ensureSpace(size * 1.4)
+		doc.y += size * 1.4
+		doc.text(pdfMargin, doc.y, size, font, color, line)
+	}
+}
+
+func clampScore(score float64) float64 {
+	if score < 0 {
+		return 0
+	}
+	if score > 100 {
+		return 100
+	}
+	return score
+}
+
+func scoreColor(score float64) pdfColor {
+	switch {
+	case score >= 80:
+		return pdfGreen
+	case score >= 60:
+		return pdfOrange
+	default:
+		return pdfRed
+	}
+}
+
+// drawPDFRadar draws the report's radar chart with its top-left corner at (x, y)
+func drawPDFRadar(doc *pdfDocument, chart radarChart, x, y, scale float64) {
+	at := func(px, py float64) [2]float64 { return [2]float64{x + px*scale, y + py*scale} }
+	parse := func(points string) [][2]float64 {
+		out := [][2]float64{}
+		for _, p := range strings.Fields(points) {
+			var px, py float64
+			if _, err := fmt.Sscanf(p, "%f,%f", &px, &py); err == nil {
+				out = append(out, at(px, py))
+			}
+		}
+		return out
+	}
+	for _, ring := range chart.Rings {
+		doc.polygon(parse(ring), nil, &pdfRule, 0.5)
+	}
+	center := at(chart.Center, chart.Center)
+	for _, axis := range chart.Axes {
+		end := at(axis.X, axis.Y)
+		doc.line(center[0], center[1], end[0], end[1], 0.5, pdfRule, false)
+		label := at(axis.LabelX, axis.LabelY)
+		lx := label[0]
+		switch axis.Anchor {
+		case "middle":
+			lx -= textWidth(axis.Label, 7, pdfRegular) / 2
+		case "end":
+			lx -= textWidth(axis.Label, 7, pdfRegular)
+		}
+		doc.text(lx, label[1], 7, pdfRegular, pdfMuted, axis.Label)
+	}
+	fill := pdfColor{0.78, 0.89, 0.98}
+	doc.polygon(parse(chart.Polygon), &fill, &pdfAccent, 1.2)
+}
+
+// Task graph box geometry
+const (
+	taskBoxHeight   = 34.0
+	taskBoxGapX     = 14.0
+	taskBoxGapY     = 28.0
+	taskBoxesPerRow = 4
+)
+
+type taskBox struct {
+	x, y, w, h float64
+}
+
+// layoutTaskRows assigns tasks to rows: each task sits below the tasks it is
+// linked from by a dependency or subtask edge, and wide layers wrap into
+// several rows
+func layoutTaskRows(graph *TaskGraph) [][]int {
+	index := make(map[string]int, len(graph.Tasks))
+	for i, t := range graph.Tasks {
+		index[t.ID] = i
+	}
+	layer := make([]int, len(graph.Tasks))
+	// Longest-path layering; the pass limit stops cycles from looping forever
+	for pass := 0; pass < len(graph.Tasks); pass++ {
+		changed := false
+		for _, r := range graph.Relationships {
+			if r.RelationType == "related" || r.RelationType == "parallel" {
+				continue
+			}
+			from, okFrom := index[r.FromTaskID]
+			to, okTo := index[r.ToTaskID]
+			if okFrom && okTo && layer[to] < layer[from]+1 {
+				layer[to] = layer[from] + 1
+				changed = true
+			}
+		}
+		if !changed {
+			break
+		}
+	}
+
+	maxLayer := 0
+	for _, l := range layer {
+		if l > maxLayer {
+			maxLayer = l
+		}
+	}
+	rows := [][]int{}
+	for l := 0; l <= maxLayer; l++ {
+		members := []int{}
+		for i := range graph.Tasks {
+			if layer[i] == l {
+				members = append(members, i)
+			}
+		}
+		for len(members) > taskBoxesPerRow {
+			rows = append(rows, members[:taskBoxesPerRow])
+			members = members[taskBoxesPerRow:]
+		}
+		if len(members) > 0 {
+			rows = append(rows, members)
+		}
+	}
+	return rows
+}
+
+// drawPDFTaskGraph draws tasks as boxes joined by arrows, shrinking the whole
+// diagram if it is taller than a page
+func drawPDFTaskGraph(doc *pdfDocument, graph *TaskGraph) {
+	rows := layoutTaskRows(graph)
+	width := doc.contentWidth()
+	height := float64(len(rows))*(taskBoxHeight+taskBoxGapY) - taskBoxGapY
+
+	available := pdfPageHeight - 2*pdfMargin - 20
+	scale := 1.0
+	if height > available {
+		scale = available / height
+	}
+	doc.ensureSpace(height*scale + 20)
+	top := doc.y + 12
+
+	boxes := make(map[string]taskBox, len(graph.Tasks))
+	for r, row := range rows {
+		boxWidth := (width - taskBoxGapX*float64(taskBoxesPerRow-1)) / taskBoxesPerRow
+		rowWidth := float64(len(row))*boxWidth + float64(len(row)-1)*taskBoxGapX
+		x := pdfMargin + (width-rowWidth)/2
+		for _, i := range row {
+			boxes[graph.Tasks[i].ID] = taskBox{x: x, y: top + float64(r)*(taskBoxHeight+taskBoxGapY), w: boxWidth, h: taskBoxHeight}
+			x += boxWidth + taskBoxGapX
+		}
+	}
+
+	critical := make(map[string]bool, len(graph.CriticalPath))
+	for _, id := range graph.CriticalPath {
+		critical[id] = true
+	}
+
+	doc.save()
+	doc.scaleAbout(pdfMargin+width/2, top, scale)
+	for _, r := range graph.Relationships {
+		from, okFrom := boxes[r.FromTaskID]
+		to, okTo := boxes[r.ToTaskID]
+		if !okFrom || !okTo {
+			continue
+		}
+		x1, y1, x2, y2 := from.x+from.w/2, from.y+from.h, to.x+to.w/2, to.y
+		switch {
+		case to.y < from.y:
+			y1, y2 = from.y, to.y+to.h
+		case to.y == from.y && to.x > from.x:
+			x1, y1, x2, y2 = from.x+from.w, from.y+from.h/2, to.x, to.y+to.h/2
+		case to.y == from.y:
+			x1, y1, x2, y2 = from.x, from.y+from.h/2, to.x+to.w, to.y+to.h/2
+		}
+		color := pdfMuted
+		if critical[r.FromTaskID] && critical[r.ToTaskID] {
+			color = pdfRed
+		}
+		doc.arrow(x1, y1, x2, y2, 0.8, color, r.RelationType == "related" || r.RelationType == "parallel")
+	}
+	for _, t := range graph.Tasks {
+		box := boxes[t.ID]
+		doc.rect(box.x, box.y, box.w, box.h, pdfBoxFill)
+		stroke, strokeWidth := pdfMuted, 0.6
+		if critical[t.ID] {
+			stroke, strokeWidth = pdfRed, 1.5
+		}
+		doc.strokeRect(box.x, box.y, box.w, box.h, strokeWidth, stroke)
+		lines := wrapText(t.Title, 8, box.w-8, pdfRegular)
+		if len(lines) > 2 {
+			lines = []string{lines[0], truncateText(strings.Join(lines[1:], " "), 8, box.w-8, pdfRegular)}
+		}
+		for i, line := range lines {
+			doc.text(box.x+4, box.y+13+float64(i)*10, 8, pdfRegular, pdfText, truncateText(line, 8, box.w-8, pdfRegular))
+		}
+	}
+	doc.restore()
+	doc.y = top + height*scale + 12
+}
@@ -0,0 +1,67 @@
+package analyzer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestRenderPDFReport(t *testing.T) {
+	text := "Build a REST API for orders.\n1. Design the schema\n2. Implement endpoints after the schema\n3. Write tests."
+	result, err := Analyze(context.Background(), text, AnalysisOptions{}, AnalysisRun{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := RenderReport(&out, result, ReportPDF, "Orders (v2) — review"); err != nil {
+		t.Fatal(err)
+	}
+	pdf := out.String()
+	if !strings.HasPrefix(pdf, "%PDF-1.4\n") || !strings.HasSuffix(pdf, "%%EOF\n") {
+		t.Fatal("expected a complete PDF file")
+	}
+	if !strings.Contains(pdf, `/Title (Orders \(v2\) \227 review)`) {
+		t.Error("expected the title to be escaped into the document info")
+	}
+	if !strings.Contains(pdf, "(Task graph) Tj") {
+		t.Error("expected a task graph section")
+	}
+
+	// The xref offsets must point at their objects for readers to open the file
+	xref := strings.LastIndex(pdf, "xref\n")
+	for i, line := range strings.Split(pdf[xref:], "\n")[3:] {
+		if !strings.HasSuffix(line, " n ") {
+			break
+		}
+		var offset int
+		if _, err := fmt.Sscan(line, &offset); err != nil || !strings.HasPrefix(pdf[offset:], strconv.Itoa(i+1)+" 0 obj") {
+			t.Fatalf("xref entry %d does not point at its object", i+1)
+		}
+	}
+}
+
+func TestLayoutTaskRows(t *testing.T) {
+	graph := &TaskGraph{
+		Tasks: []Task{{ID: "a"}, {ID: "b"}, {ID: "c"}, {ID: "d"}},
+		Relationships: []TaskRelationship{
+			{FromTaskID: "a", ToTaskID: "b", RelationType: "depends_on"},
+			{FromTaskID: "b", ToTaskID: "c", RelationType: "depends_on"},
+			{FromTaskID: "a", ToTaskID: "c", RelationType: "depends_on"},
+			{FromTaskID: "a", ToTaskID: "d", RelationType: "related"},
+		},
+	}
+	rows := layoutTaskRows(graph)
+	want := [][]int{{0, 3}, {1}, {2}}
+	if len(rows) != len(want) {
+		t.Fatalf("expected rows %v, got %v", want, rows)
+	}
+	for i := range want {
+		if len(rows[i]) != len(want[i]) || rows[i][0] != want[i][0] {
+			t.Fatalf("expected rows %v, got %v", want, rows)
+		}
+	}
+}
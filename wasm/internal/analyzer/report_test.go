@@ -0,0 +1,50 @@
+package analyzer
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestRenderReport(t *testing.T) {
+	text := "Build a REST API for orders.\n1. Design the schema\n2. Implement endpoints after the schema\n3. Write tests."
+	result, err := Analyze(context.Background(), text, AnalysisOptions{}, AnalysisRun{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	md := RenderMarkdownReport(result, "Orders <API>")
+	for _, want := range []string{"# Orders <API>", "## Dimensions", "| Specificity |", "## Suggestions", "```mermaid\nflowchart TD"} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown report missing %q", want)
+		}
+	}
+
+	var html bytes.Buffer
+	if err := RenderReport(&html, result, ReportHTML, "Orders <API>"); err != nil {
+		t.Fatal(err)
+	}
+	out := html.String()
+	if !strings.Contains(out, "<title>Orders &lt;API&gt;</title>") {
+		t.Error("expected the title to be escaped in HTML")
+	}
+	if !strings.Contains(out, "<svg class=\"radar\"") || strings.Count(out, "<text ") != 8 {
+		t.Error("expected a radar chart with a label per dimension")
+	}
+
+	if err := RenderReport(&html, result, "docx", ""); err == nil {
+		t.Error("expected an unknown format to fail")
+	}
+}
+
+func TestTaskGraphMermaidEscapesLabels(t *testing.T) {
+	graph := &TaskGraph{
+		Tasks:         []Task{{ID: "task-1", Title: `Say "hi"`}, {ID: "task-2", Title: "Wave"}},
+		Relationships: []TaskRelationship{{FromTaskID: "task-1", ToTaskID: "task-2", RelationType: "depends_on"}},
+	}
+	got := TaskGraphMermaid(graph)
+	if !strings.Contains(got, `t_task_1["Say #quot;hi#quot;"]`) || !strings.Contains(got, "t_task_1 -->|depends_on| t_task_2") {
+		t.Errorf("unexpected mermaid output:\n%s", got)
+	}
+}
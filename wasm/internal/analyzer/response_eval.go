@@ -0,0 +1,437 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// taskAddressedThreshold is the share of a task's content words a response
+// must mention for the task to count as addressed
+const taskAddressedThreshold = 0.5
+
+// Weights of the parts of a response evaluation; parts with nothing to
+// check are left out and the rest renormalized
+const (
+	taskCoverageWeight     = 0.5
+	questionCoverageWeight = 0.25
+	formatComplianceWeight = 0.25
+)
+
+// Output format requirements a prompt can state
+const (
+	OutputJSON         = "json"
+	OutputJSONFields   = "json_fields"
+	OutputBulletList   = "bullet_list"
+	OutputNumberedList = "numbered_list"
+	OutputList         = "list"
+	OutputTable        = "table"
+	OutputCodeBlock    = "code_block"
+	OutputLength       = "length"
+)
+
+// TaskCoverage reports whether a response addresses one task of the prompt
+type TaskCoverage struct {
+	TaskID    string   `json:"task_id,omitempty"` // Empty for instructions the task graph missed
+	Text      Span     `json:"text"`              // The task or instruction in the prompt
+	Keywords  []string `json:"keywords"`          // Content words of the task, lemmatized
+	Mentioned []string `json:"mentioned"`
+	Coverage  float64  `json:"coverage"` // Share of Keywords the response mentions
+	Addressed bool     `json:"addressed"`
+	Evidence  *Span    `json:"evidence,omitempty"` // Response sentence mentioning the most keywords
+}
+
+// QuestionCoverage reports whether a response answers one prompt question
+type QuestionCoverage struct {
+	Question   Span     `json:"question"`
+	Answered   bool     `json:"answered"`
+	Confidence float64  `json:"confidence"`
+	Answer     *Span    `json:"answer,omitempty"` // Best answering sentence of the response
+	Evidence   []string `json:"evidence"`
+}
+
+// FormatRequirement is one output format instruction and whether the
+// response follows it
+type FormatRequirement struct {
+	Kind        string `json:"kind"`        // One of the Output* requirements
+	Instruction Span   `json:"instruction"` // Where the prompt asks for it
+	Met         bool   `json:"met"`
+	Detail      string `json:"detail"`
+}
+
+// ResponseEvaluation scores an LLM response against the prompt that produced
+// it. Each part is 0-100; Score blends the parts that had something to check.
+type ResponseEvaluation struct {
+	Tasks            []TaskCoverage      `json:"tasks"`
+	Questions        []QuestionCoverage  `json:"questions"`
+	Format           []FormatRequirement `json:"format"`
+	TaskCoverage     float64             `json:"task_coverage"`     // Share of tasks addressed
+	QuestionCoverage float64             `json:"question_coverage"` // Share of questions answered
+	FormatCompliance float64             `json:"format_compliance"` // Share of format requirements met
+	Score            float64             `json:"score"`
+	Grade            string              `json:"grade"`
+}
+
+// EvaluateRequest is the body of POST /evaluate. Without a Response, the
+// server's model answers the prompt first.
+type EvaluateRequest struct {
+	Prompt   string `json:"prompt"`
+	Response string `json:"response,omitempty"`
+}
+
+// EvaluationResponse holds the evaluated response and its evaluation
+type EvaluationResponse struct {
+	Response   string             `json:"response"`
+	Evaluation ResponseEvaluation `json:"evaluation"`
+}
+
+var (
+	jsonFormatPattern     = regexp.MustCompile(`(?i)\b(?:return|respond|reply|output|answer|format|formatted|give|produce|provide)\b[^.\n]{0,40}?\bjson\b|\bjson\s+(?:format|output|object|array|response)\b|\b(?:as|in)\s+(?:valid\s+)?json\b`)
+	jsonFieldsPattern     = regexp.MustCompile(`(?i)\b(?:with|containing|including|having)\s+(?:the\s+)?(?:fields|keys|properties)\s*:?\s*([^.\n]+)`)
+	fieldNamePattern      = regexp.MustCompile(`^[A-Za-z_][\w.-]*$`)
+	bulletFormatPattern   = regexp.MustCompile(`(?i)\bbullet(?:ed|s)?\b`)
+	numberedFormatPattern = regexp.MustCompile(`(?i)\bnumbered\s+(?:list|steps|points|items)\b`)
+	listFormatPattern     = regexp.MustCompile(`(?i)\b(?:as|in)\s+a\s+list\b`)
+	tableFormatPattern    = regexp.MustCompile(`(?i)\b(?:as|in|into)\s+a\s+(?:markdown\s+)?table\b|\bmarkdown\s+table\b|\btabular\b`)
+	codeFormatPattern     = regexp.MustCompile(`(?i)\b(?:code|fenced)\s+blocks?\b`)
+	lengthFormatPattern   = regexp.MustCompile(`(?i)\b(at most|no more than|fewer than|less than|under|within|a maximum of|up to|exactly|in)\s+(\d+|one|two|three|four|five|six|seven|eight|nine|ten)\s+(words?|sentences?|bullet points?|bullets|items|paragraphs?|lines?)\b`)
+	tableSeparatorPattern = regexp.MustCompile(`^\s*\|?\s*:?-{3,}`)
+	fencedBlockPattern    = regexp.MustCompile("(?s)```[\\w-]*\\n(.*?)```")
+	fieldSeparatorPattern = regexp.MustCompile(`,|\s+and\s+|\s+or\s+`)
+)
+
+// formatVerbs open instructions about the shape of the output, which the
+// format checks cover
+var formatVerbs = map[string]bool{"format": true, "keep": true, "limit": true, "use": true, "avoid": true}
+
+// smallNumbers spells out the counts lengthFormatPattern accepts as words
+var smallNumbers = map[string]int{"one": 1, "two": 2, "three": 3, "four": 4, "five": 5, "six": 6, "seven": 7, "eight": 8, "nine": 9, "ten": 10}
+
+// EvaluateResponse checks whether response addresses each task extracted
+// from prompt, answers its questions and follows the output format it asks
+// for. Imperative instructions the task graph misses count as tasks, except
+// those about the output's shape; tasks without content words and
+// rhetorical questions are not checked.
+func EvaluateResponse(prompt, response string) ResponseEvaluation {
+	eval := ResponseEvaluation{
+		Tasks:     []TaskCoverage{},
+		Questions: []QuestionCoverage{},
+		Format:    []FormatRequirement{},
+	}
+	sentences := locateSentences(response)
+	mentioned := answerContentWords(response)
+
+	taskSpans := []Span{}
+	for _, task := range ExtractTaskGraphFromText(prompt).Tasks {
+		start, end := task.TextPosition.StartChar, min(task.TextPosition.EndChar, len(prompt))
+		if start < 0 || start >= end {
+			continue
+		}
+		span := newSpan(prompt, start, end)
+		taskSpans = append(taskSpans, span)
+		if c, ok := coverTask(span, task.ActionVerbs, sentences, mentioned); ok {
+			c.TaskID = task.ID
+			eval.Tasks = append(eval.Tasks, c)
+		}
+	}
+	for _, ins := range AnalyzeInstructions(prompt).Instructions {
+		if deliverableVerbs[ins.Verb] || formatVerbs[ins.Verb] || overlapsAny(ins.Span, taskSpans) {
+			continue
+		}
+		if c, ok := coverTask(ins.Span, []string{ins.Verb}, sentences, mentioned); ok {
+			eval.Tasks = append(eval.Tasks, c)
+		}
+	}
+	sort.SliceStable(eval.Tasks, func(i, j int) bool { return eval.Tasks[i].Text.Start < eval.Tasks[j].Text.Start })
+
+	for _, q := range locateSentences(prompt) {
+		if !strings.HasSuffix(q.Text, "?") && (q.End >= len(prompt) || prompt[q.End] != '?') || isRhetorical(q.Text) {
+			continue
+		}
+		coverage := QuestionCoverage{Question: q, Evidence: []string{}}
+		kind, keywords := questionKind(q.Text), answerContentWords(q.Text)
+		for _, candidate := range sentences {
+			score, evidence := scoreAnswer(kind, keywords, candidate.Text)
+			if score = math.Round(math.Min(score, 1)*100) / 100; score > coverage.Confidence {
+				answer := candidate
+				coverage.Answer, coverage.Confidence, coverage.Evidence = &answer, score, evidence
+			}
+		}
+		if coverage.Answered = coverage.Confidence >= answeredThreshold; !coverage.Answered {
+			coverage.Answer, coverage.Evidence = nil, []string{}
+		}
+		eval.Questions = append(eval.Questions, coverage)
+	}
+
+	eval.Format = checkFormat(prompt, response)
+
+	total, weights := 0.0, 0.0
+	if n := len(eval.Tasks); n > 0 {
+		addressed := 0
+		for _, t := range eval.Tasks {
+			if t.Addressed {
+				addressed++
+			}
+		}
+		eval.TaskCoverage = math.Round(float64(addressed)/float64(n)*1000) / 10
+		total += eval.TaskCoverage * taskCoverageWeight
+		weights += taskCoverageWeight
+	}
+	if n := len(eval.Questions); n > 0 {
+		answered := 0
+		for _, q := range eval.Questions {
+			if q.Answered {
+				answered++
+			}
+		}
+		eval.QuestionCoverage = math.Round(float64(answered)/float64(n)*1000) / 10
+		total += eval.QuestionCoverage * questionCoverageWeight
+		weights += questionCoverageWeight
+	}
+	if n := len(eval.Format); n > 0 {
+		met := 0
+		for _, f := range eval.Format {
+			if f.Met {
+				met++
+			}
+		}
+		eval.FormatCompliance = math.Round(float64(met)/float64(n)*1000) / 10
+		total += eval.FormatCompliance * formatComplianceWeight
+		weights += formatComplianceWeight
+	}
+	// A prompt with nothing to check can only be failed by an empty response
+	eval.Score = 100
+	if weights > 0 {
+		eval.Score = math.Round(total/weights*10) / 10
+	} else if strings.TrimSpace(response) == "" {
+		eval.Score = 0
+	}
+	eval.Grade = scoreToGrade(eval.Score)
+	return eval
+}
+
+// coverTask measures how many of a task's content words the response
+// mentions. The task's action verbs and other instruction verbs are left
+// out, since answers rarely repeat "write" or "list". It reports false for
+// tasks with no content words.
+func coverTask(text Span, actionVerbs []string, sentences []Span, mentioned map[string]bool) (TaskCoverage, bool) {
+	verbs := map[string]bool{}
+	for _, v := range actionVerbs {
+		verbs[getLemma(v)] = true
+	}
+	keywords := []string{}
+	for w := range answerContentWords(text.Text) {
+		if !verbs[w] && !instructionVerbs[w] {
+			keywords = append(keywords, w)
+		}
+	}
+	if len(keywords) == 0 {
+		return TaskCoverage{}, false
+	}
+	sort.Strings(keywords)
+
+	c := TaskCoverage{Text: text, Keywords: keywords, Mentioned: []string{}}
+	for _, w := range keywords {
+		if mentioned[w] {
+			c.Mentioned = append(c.Mentioned, w)
+		}
+	}
+	c.Coverage = math.Round(float64(len(c.Mentioned))/float64(len(keywords))*100) / 100
+	c.Addressed = c.Coverage >= taskAddressedThreshold
+
+	best := 0
+	for _, sentence := range sentences {
+		hits := 0
+		words := answerContentWords(sentence.Text)
+		for _, w := range c.Mentioned {
+			if words[w] {
+				hits++
+			}
+		}
+		if hits > best {
+			evidence := sentence
+			c.Evidence, best = &evidence, hits
+		}
+	}
+	return c, true
+}
+
+// overlapsAny reports whether span overlaps any of spans
+func overlapsAny(span Span, spans []Span) bool {
+	for _, s := range spans {
+		if span.Start < s.End && s.Start < span.End {
+			return true
+		}
+	}
+	return false
+}
+
+// checkFormat finds the output format instructions in prompt and checks the
+// response against each, in prompt order
+func checkFormat(prompt, response string) []FormatRequirement {
+	reqs := []FormatRequirement{}
+	add := func(kind string, loc []int, met bool, detail string) {
+		reqs = append(reqs, FormatRequirement{Kind: kind, Instruction: newSpan(prompt, loc[0], loc[1]), Met: met, Detail: detail})
+	}
+
+	if loc := jsonFormatPattern.FindStringIndex(prompt); loc != nil {
+		payload, ok := responseJSON(response)
+		detail := "response is valid JSON"
+		if !ok {
+			detail = "no valid JSON found in the response"
+		}
+		add(OutputJSON, loc, ok, detail)
+
+		if m := jsonFieldsPattern.FindStringSubmatchIndex(prompt); m != nil {
+			fields := fieldNames(prompt[m[2]:m[3]])
+			if len(fields) > 0 {
+				missing := missingJSONFields(payload, fields)
+				detail := fmt.Sprintf("has fields %s", strings.Join(fields, ", "))
+				if len(missing) > 0 {
+					detail = "missing fields " + strings.Join(missing, ", ")
+				}
+				add(OutputJSONFields, m[:2], ok && len(missing) == 0, detail)
+			}
+		}
+	}
+
+	bullets, numbered := listLines(response)
+	if loc := bulletFormatPattern.FindStringIndex(prompt); loc != nil {
+		add(OutputBulletList, loc, bullets >= 2, fmt.Sprintf("%d bulleted lines", bullets))
+	}
+	if loc := numberedFormatPattern.FindStringIndex(prompt); loc != nil {
+		add(OutputNumberedList, loc, numbered >= 2, fmt.Sprintf("%d numbered lines", numbered))
+	}
+	if loc := listFormatPattern.FindStringIndex(prompt); loc != nil {
+		add(OutputList, loc, bullets+numbered >= 2, fmt.Sprintf("%d list lines", bullets+numbered))
+	}
+	if loc := tableFormatPattern.FindStringIndex(prompt); loc != nil {
+		rows, separator := 0, false
+		for _, line := range strings.Split(response, "\n") {
+			if tableSeparatorPattern.MatchString(line) && strings.Contains(line, "|") {
+				separator = true
+			} else if strings.HasPrefix(strings.TrimSpace(line), "|") {
+				rows++
+			}
+		}
+		add(OutputTable, loc, separator && rows >= 2, fmt.Sprintf("%d table rows", rows))
+	}
+	if loc := codeFormatPattern.FindStringIndex(prompt); loc != nil {
+		blocks := len(fencedBlockPattern.FindAllString(response, -1))
+		add(OutputCodeBlock, loc, blocks > 0, fmt.Sprintf("%d fenced code blocks", blocks))
+	}
+	for _, m := range lengthFormatPattern.FindAllStringSubmatchIndex(prompt, -1) {
+		bound, unit := strings.ToLower(prompt[m[2]:m[3]]), strings.ToLower(prompt[m[6]:m[7]])
+		limit, err := strconv.Atoi(prompt[m[4]:m[5]])
+		if err != nil {
+			limit = smallNumbers[strings.ToLower(prompt[m[4]:m[5]])]
+		}
+		count, noun := responseLength(response, unit, bullets+numbered)
+		met := count <= limit
+		switch bound {
+		case "exactly":
+			met = count == limit
+		case "fewer than", "less than", "under":
+			met = count < limit
+		}
+		add(OutputLength, m[:2], met, fmt.Sprintf("%d %s for a limit of %s %d", count, noun, bound, limit))
+	}
+	return reqs
+}
+
+// responseJSON finds a JSON value in a response: the whole text, a fenced
+// block or the outermost braces or brackets
+func responseJSON(response string) (interface{}, bool) {
+	candidates := []string{strings.TrimSpace(response)}
+	for _, m := range fencedBlockPattern.FindAllStringSubmatch(response, -1) {
+		candidates = append(candidates, strings.TrimSpace(m[1]))
+	}
+	for _, pair := range []string{"{}", "[]"} {
+		start, end := strings.IndexByte(response, pair[0]), strings.LastIndexByte(response, pair[1])
+		if start >= 0 && end > start {
+			candidates = append(candidates, response[start:end+1])
+		}
+	}
+	for _, c := range candidates {
+		var v interface{}
+		if c != "" && json.Unmarshal([]byte(c), &v) == nil {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+// fieldNames splits "id, name and `status`" into field names
+func fieldNames(list string) []string {
+	names := []string{}
+	for _, part := range fieldSeparatorPattern.Split(list, -1) {
+		name := strings.Trim(strings.TrimSpace(part), "\"'`")
+		if fieldNamePattern.MatchString(name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// missingJSONFields lists the fields absent from a JSON object, or from the
+// first element of an array of objects
+func missingJSONFields(payload interface{}, fields []string) []string {
+	if list, ok := payload.([]interface{}); ok && len(list) > 0 {
+		payload = list[0]
+	}
+	object, _ := payload.(map[string]interface{})
+	missing := []string{}
+	for _, f := range fields {
+		if _, ok := object[f]; !ok {
+			missing = append(missing, f)
+		}
+	}
+	return missing
+}
+
+// listLines counts bulleted and numbered lines of text
+func listLines(text string) (bullets, numbered int) {
+	for _, line := range strings.Split(text, "\n") {
+		m := listItemPattern.FindStringSubmatch(line)
+		switch {
+		case m == nil:
+		case m[2] != "" || m[3] != "" || m[4] != "":
+			numbered++
+		default:
+			bullets++
+		}
+	}
+	return bullets, numbered
+}
+
+// responseLength counts the response in the unit a length limit names
+func responseLength(response, unit string, items int) (int, string) {
+	switch {
+	case strings.HasPrefix(unit, "word"):
+		return len(extractWords(response)), "words"
+	case strings.HasPrefix(unit, "sentence"):
+		return len(locateSentences(response)), "sentences"
+	case strings.HasPrefix(unit, "paragraph"):
+		n := 0
+		for _, p := range paragraphBreakPattern.Split(response, -1) {
+			if strings.TrimSpace(p) != "" {
+				n++
+			}
+		}
+		return n, "paragraphs"
+	case strings.HasPrefix(unit, "line"):
+		n := 0
+		for _, line := range strings.Split(response, "\n") {
+			if strings.TrimSpace(line) != "" {
+				n++
+			}
+		}
+		return n, "lines"
+	default:
+		return items, "items"
+	}
+}
@@ -0,0 +1,69 @@
+package analyzer
+
+import "testing"
+
+const evalPrompt = "Write a summary of the quarterly sales report and list the three biggest risks. Why did revenue drop in March? Return JSON with fields summary, risks and cause. Keep it under 80 words."
+
+func TestEvaluateResponse(t *testing.T) {
+	good := "```json\n{\"summary\": \"Quarterly sales grew 4% on enterprise deals.\", \"risks\": [\"churn\", \"pricing\", \"supply\"], \"cause\": \"renewal slipped\"}\n```\nRevenue dropped in March because a large renewal slipped to April."
+	eval := EvaluateResponse(evalPrompt, good)
+	if len(eval.Tasks) != 1 || !eval.Tasks[0].Addressed {
+		t.Errorf("tasks = %+v", eval.Tasks)
+	}
+	if len(eval.Questions) != 1 || !eval.Questions[0].Answered {
+		t.Errorf("questions = %+v", eval.Questions)
+	}
+	kinds := map[string]bool{}
+	for _, f := range eval.Format {
+		kinds[f.Kind] = f.Met
+	}
+	for _, kind := range []string{OutputJSON, OutputJSONFields, OutputLength} {
+		if met, ok := kinds[kind]; !ok || !met {
+			t.Errorf("format %s: found %v, met %v (%+v)", kind, ok, met, eval.Format)
+		}
+	}
+	if eval.Score != 100 || eval.Grade != "A+" {
+		t.Errorf("score = %.1f %s", eval.Score, eval.Grade)
+	}
+
+	off := "Thanks for asking! Sales reports are useful documents that many teams read every quarter."
+	bad := EvaluateResponse(evalPrompt, off)
+	if bad.QuestionCoverage != 0 || bad.FormatCompliance >= 50 || bad.Score >= eval.Score {
+		t.Errorf("off-topic response scored %+v", bad)
+	}
+}
+
+func TestCheckFormat(t *testing.T) {
+	cases := []struct {
+		prompt, response, kind string
+		met                    bool
+	}{
+		{"Answer in bullet points.", "- one\n- two", OutputBulletList, true},
+		{"Answer in bullet points.", "One. Two.", OutputBulletList, false},
+		{"Give a numbered list of steps.", "1. Install\n2. Run", OutputNumberedList, true},
+		{"Compare them in a markdown table.", "| a | b |\n|---|---|\n| 1 | 2 |", OutputTable, true},
+		{"Put the code in a code block.", "```go\nfmt.Println()\n```", OutputCodeBlock, true},
+		{"Reply in exactly two sentences.", "One. Two. Three.", OutputLength, false},
+		{"Use at most 3 bullets.", "- a\n- b", OutputLength, true},
+		{"Respond in JSON.", "Sure: {\"ok\": true}", OutputJSON, true},
+		{"Respond in JSON.", "{ok: true}", OutputJSON, false},
+	}
+	for _, c := range cases {
+		reqs := checkFormat(c.prompt, c.response)
+		found := false
+		for _, r := range reqs {
+			if r.Kind == c.kind {
+				found = true
+				if r.Met != c.met {
+					t.Errorf("%q / %q: %s met = %v (%s), want %v", c.prompt, c.response, c.kind, r.Met, r.Detail, c.met)
+				}
+			}
+		}
+		if !found {
+			t.Errorf("%q: no %s requirement in %+v", c.prompt, c.kind, reqs)
+		}
+	}
+	if reqs := checkFormat("Explain how the JSON parser handles errors.", "It returns them."); len(reqs) != 0 {
+		t.Errorf("mentioning JSON is not a format requirement: %+v", reqs)
+	}
+}
@@ -0,0 +1,201 @@
+package analyzer
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Truncation records a list cut short by a per-request limit, e.g. the
+// sentences of idea clusters under AnalysisOptions.MaxClusterSentences
+type Truncation struct {
+	Field string `json:"field"` // Dotted result path; [] marks every element of a list
+	Kept  int    `json:"kept"`
+	Total int    `json:"total"`
+}
+
+// alwaysKeptFields survive field selection so clients can still tell which
+// result shape they received and what was cut
+var alwaysKeptFields = []string{"schema_version", "truncated"}
+
+// validateResultFields checks that each dotted path names a field of the
+// analysis result, e.g. "prompt_grade.overall_grade"
+func validateResultFields(fields []string) error {
+	for _, f := range fields {
+		if !resultPathExists(reflect.TypeOf(CombinedResult{}), strings.Split(f, ".")) {
+			return fmt.Errorf("unknown result field %q", f)
+		}
+	}
+	return nil
+}
+
+// resultPathExists walks JSON field names through structs, list elements and
+// map values
+func resultPathExists(t reflect.Type, path []string) bool {
+	for len(path) > 0 {
+		switch t.Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Array:
+			t = t.Elem()
+			continue
+		case reflect.Map:
+			// Map keys are data, so any key is accepted
+			t, path = t.Elem(), path[1:]
+			continue
+		case reflect.Struct:
+			field, ok := jsonField(t, path[0])
+			if !ok {
+				return false
+			}
+			t, path = field.Type, path[1:]
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+// jsonField finds the struct field encoding/json writes under name, looking
+// through embedded structs
+func jsonField(t reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := strings.Split(f.Tag.Get("json"), ",")[0]
+		if tag == "-" || !f.IsExported() {
+			continue
+		}
+		if f.Anonymous && tag == "" {
+			if inner, ok := jsonField(f.Type, name); ok {
+				return inner, true
+			}
+			continue
+		}
+		if tag == name || (tag == "" && f.Name == name) {
+			return f, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// fieldNode is one level of the requested field paths; all keeps the whole value
+type fieldNode struct {
+	all      bool
+	children map[string]*fieldNode
+}
+
+func newFieldTree(paths []string) *fieldNode {
+	root := &fieldNode{children: map[string]*fieldNode{}}
+	for _, p := range append(append([]string{}, alwaysKeptFields...), paths...) {
+		node := root
+		for _, part := range strings.Split(p, ".") {
+			if node.all {
+				break
+			}
+			child, ok := node.children[part]
+			if !ok {
+				child = &fieldNode{children: map[string]*fieldNode{}}
+				node.children[part] = child
+			}
+			node = child
+		}
+		node.all, node.children = true, nil
+	}
+	return root
+}
+
+// pick keeps only the requested parts of a plain value; a path through a list
+// applies to every element
+func (n *fieldNode) pick(v interface{}) interface{} {
+	if n.all {
+		return v
+	}
+	switch v := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(n.children))
+		for key, child := range n.children {
+			if value, ok := v[key]; ok {
+				out[key] = child.pick(value)
+			}
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, elem := range v {
+			out[i] = n.pick(elem)
+		}
+		return out
+	}
+	return v
+}
+
+// SelectResultFields trims a result converted with ToPlain to what the caller
+// asked for: sections of stages outside opts.Stages are dropped, and when
+// opts.Fields is set only those paths (plus the schema version and truncation
+// notes) remain
+func SelectResultFields(plain map[string]interface{}, opts AnalysisOptions) map[string]interface{} {
+	for stage, keys := range StageResultKeys {
+		if !opts.Wants(stage) {
+			for _, key := range keys {
+				delete(plain, key)
+			}
+		}
+	}
+	if len(opts.Fields) == 0 {
+		return plain
+	}
+	return newFieldTree(opts.Fields).pick(plain).(map[string]interface{})
+}
+
+// truncateResult applies the per-request list limits, recording what was cut
+func truncateResult(result *CombinedResult, opts AnalysisOptions) {
+	if n := opts.MaxClusterSentences; n > 0 {
+		kept, total := 0, 0
+		for i := range result.Ideas.SemanticClusters.Value {
+			c := &result.Ideas.SemanticClusters.Value[i]
+			total += len(c.Sentences)
+			if len(c.Sentences) > n {
+				c.Sentences = c.Sentences[:n]
+				c.SentenceSpans = c.SentenceSpans[:min(n, len(c.SentenceSpans))]
+				c.SentenceTypes = c.SentenceTypes[:min(n, len(c.SentenceTypes))]
+			}
+			kept += len(c.Sentences)
+		}
+		if kept < total {
+			result.Truncated = append(result.Truncated, Truncation{Field: "idea_analysis.semantic_clusters.value[].sentences", Kept: kept, Total: total})
+		}
+	}
+	if n := opts.MaxSuggestionExamples; n > 0 {
+		examples := make([]*string, len(result.PromptGrade.Suggestions))
+		for i := range result.PromptGrade.Suggestions {
+			examples[i] = &result.PromptGrade.Suggestions[i].Example
+		}
+		if kept, total := capExamples(examples, n); kept < total {
+			result.Truncated = append(result.Truncated, Truncation{Field: "prompt_grade.suggestions[].example", Kept: kept, Total: total})
+		}
+		if result.ModernGrade != nil {
+			examples = make([]*string, len(result.ModernGrade.Suggestions))
+			for i := range result.ModernGrade.Suggestions {
+				examples[i] = &result.ModernGrade.Suggestions[i].Example
+			}
+			if kept, total := capExamples(examples, n); kept < total {
+				result.Truncated = append(result.Truncated, Truncation{Field: "modern_grade.suggestions[].example", Kept: kept, Total: total})
+			}
+		}
+	}
+}
+
+// capExamples clears every example after the first n that are set,
+// returning how many were kept out of how many were set
+func capExamples(examples []*string, n int) (kept, total int) {
+	for _, example := range examples {
+		if *example == "" {
+			continue
+		}
+		total++
+		if kept < n {
+			kept++
+		} else {
+			*example = ""
+		}
+	}
+	return kept, total
+}
@@ -0,0 +1,136 @@
+package analyzer
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestMarshalResultKeepsOnlyRequestedFields(t *testing.T) {
+	opts := AnalysisOptions{Fields: []string{"prompt_grade.overall_grade", "idea_analysis.semantic_clusters.value.main_topic"}}
+	result, err := Analyze(context.Background(), determinismTestText, opts, AnalysisRun{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := MarshalResult(result, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]interface{}
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+
+	keys := []string{}
+	for k := range got {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	if want := []string{"idea_analysis", "prompt_grade", "schema_version"}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("top-level keys = %v, want %v", keys, want)
+	}
+	grade := got["prompt_grade"].(map[string]interface{})
+	if _, ok := grade["overall_grade"]; !ok || len(grade) != 1 {
+		t.Errorf("prompt_grade = %v, want only overall_grade", grade)
+	}
+	clusters := got["idea_analysis"].(map[string]interface{})["semantic_clusters"].(map[string]interface{})["value"].([]interface{})
+	for _, c := range clusters {
+		if cluster := c.(map[string]interface{}); len(cluster) != 1 || cluster["main_topic"] == "" {
+			t.Errorf("cluster = %v, want only main_topic", cluster)
+		}
+	}
+}
+
+func TestTruncateResultLimitsClustersAndExamples(t *testing.T) {
+	text := strings.Repeat("The billing database stores invoices and payments for customers. ", 12) + determinismTestText
+	opts := AnalysisOptions{MaxClusterSentences: 2, MaxSuggestionExamples: 1}
+	result, err := Analyze(context.Background(), text, opts, AnalysisRun{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range result.Ideas.SemanticClusters.Value {
+		if len(c.Sentences) > 2 || len(c.SentenceSpans) != len(c.Sentences) {
+			t.Errorf("cluster %d keeps %d sentences and %d spans", c.ID, len(c.Sentences), len(c.SentenceSpans))
+		}
+	}
+	examples := 0
+	for _, s := range result.PromptGrade.Suggestions {
+		if s.Example != "" {
+			examples++
+		}
+	}
+	if examples > 1 {
+		t.Errorf("%d suggestions kept examples, want at most 1", examples)
+	}
+	fields := map[string]bool{}
+	for _, tr := range result.Truncated {
+		fields[tr.Field] = tr.Kept < tr.Total
+	}
+	if !fields["idea_analysis.semantic_clusters.value[].sentences"] {
+		t.Errorf("cluster truncation not reported: %+v", result.Truncated)
+	}
+}
+
+// TestTruncateResultLimitsExamplesPerEngine checks that the example cap
+// applies to each grading engine's suggestions and is reported
+func TestTruncateResultLimitsExamplesPerEngine(t *testing.T) {
+	for _, grader := range []string{GraderClassic, GraderModern} {
+		result, err := Analyze(context.Background(), "Write code.", AnalysisOptions{Grader: grader, MaxSuggestionExamples: 1}, AnalysisRun{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var examples []string
+		field := "prompt_grade.suggestions[].example"
+		if grader == GraderModern {
+			field = "modern_grade.suggestions[].example"
+			for _, s := range result.ModernGrade.Suggestions {
+				examples = append(examples, s.Example)
+			}
+		} else {
+			for _, s := range result.PromptGrade.Suggestions {
+				examples = append(examples, s.Example)
+			}
+		}
+		kept := 0
+		for _, example := range examples {
+			if example != "" {
+				kept++
+			}
+		}
+		var reported *Truncation
+		for i, tr := range result.Truncated {
+			if tr.Field == field {
+				reported = &result.Truncated[i]
+			}
+		}
+		if kept != 1 || reported == nil || reported.Kept != 1 || reported.Total < 2 {
+			t.Errorf("%s: %d examples kept, truncation %+v", grader, kept, reported)
+		}
+	}
+}
+
+// TestSelectResultFieldsDropsGradeOutput checks that deselecting the grade
+// stage drops every engine's grade, not just prompt_grade
+func TestSelectResultFieldsDropsGradeOutput(t *testing.T) {
+	plain := map[string]interface{}{"tokens": 1, "prompt_grade": 1, "modern_grade": 1, "grades": 1}
+	got := SelectResultFields(plain, AnalysisOptions{Stages: []string{StageTokens}})
+	if len(got) != 1 || got["tokens"] == nil {
+		t.Errorf("selected = %v, want only tokens", got)
+	}
+}
+
+func TestValidateResultFields(t *testing.T) {
+	for _, f := range []string{"prompt_grade", "tokens.token_counts", "task_graph.tasks.title"} {
+		if err := validateResultFields([]string{f}); err != nil {
+			t.Errorf("%s: %v", f, err)
+		}
+	}
+	for _, f := range []string{"grade", "prompt_grade.nope", "schema_version.value"} {
+		if err := validateResultFields([]string{f}); err == nil {
+			t.Errorf("%s accepted", f)
+		}
+	}
+}
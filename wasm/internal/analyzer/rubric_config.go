@@ -0,0 +1,162 @@
+package analyzer
+
+import (
+	"fmt"
+	"sort"
+)
+
+// RubricConfig lets teams encode their own grading standards
+type RubricConfig struct {
+	Name string `json:"name"`
+
+	// DimensionWeights replaces the default weights for the listed prompt types
+	DimensionWeights map[PromptType]DimensionWeights `json:"dimension_weights"`
+
+	// GradeThresholds replaces the default letter-grade boundaries
+	GradeThresholds []GradeThreshold `json:"grade_thresholds"`
+
+	// SuggestionPriorities maps prompt type -> suggestion category -> priority.
+	// The "*" prompt type applies to every type without its own entry.
+	SuggestionPriorities map[PromptType]map[string]string `json:"suggestion_priorities"`
+
+	// Rules disables suggestion rules or sets their priority by rule ID (FUL0xx).
+	// Rule priorities win over SuggestionPriorities.
+	Rules SuggestionRuleConfig `json:"rules"`
+
+	// Classifier adds or overrides prompt categories with a model trained by
+	// TrainClassifier. DimensionWeights may name its labels.
+	Classifier ClassifierModel `json:"classifier"`
+}
+
+// GradeThreshold is the minimum score needed for a letter grade
+type GradeThreshold struct {
+	Grade    string  `json:"grade"`
+	MinScore float64 `json:"min_score"`
+}
+
+// allPromptTypes is used to apply "*" overrides to every prompt type
+const allPromptTypes PromptType = "*"
+
+var validPriorities = map[string]bool{"critical": true, "high": true, "medium": true, "low": true}
+
+// LoadRubricConfig parses a JSON or YAML rubric, rejecting unknown fields so typos are caught early
+func LoadRubricConfig(data []byte) (RubricConfig, error) {
+	var cfg RubricConfig
+	if err := DecodeConfig(data, &cfg); err != nil {
+		return RubricConfig{}, fmt.Errorf("invalid rubric config: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return RubricConfig{}, err
+	}
+	return cfg, nil
+}
+
+// Validate checks weights, thresholds and priorities for obvious mistakes
+func (cfg RubricConfig) Validate() error {
+	for pt, w := range cfg.DimensionWeights {
+		values := []float64{w.Clarity, w.Specificity, w.Completeness, w.Actionability, w.ContextProvision, w.StructureQuality}
+		total := 0.0
+		for _, v := range values {
+			if v < 0 {
+				return fmt.Errorf("rubric %q: negative dimension weight for %s", cfg.Name, pt)
+			}
+			total += v
+		}
+		if total == 0 {
+			return fmt.Errorf("rubric %q: dimension weights for %s sum to zero", cfg.Name, pt)
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, t := range cfg.GradeThresholds {
+		if t.Grade == "" {
+			return fmt.Errorf("rubric %q: grade threshold without a grade", cfg.Name)
+		}
+		if t.MinScore < 0 || t.MinScore > 100 {
+			return fmt.Errorf("rubric %q: threshold for %s must be between 0 and 100", cfg.Name, t.Grade)
+		}
+		if seen[t.Grade] {
+			return fmt.Errorf("rubric %q: duplicate threshold for grade %s", cfg.Name, t.Grade)
+		}
+		seen[t.Grade] = true
+	}
+
+	for pt, priorities := range cfg.SuggestionPriorities {
+		for category, priority := range priorities {
+			if !validPriorities[priority] {
+				return fmt.Errorf("rubric %q: invalid priority %q for %s/%s", cfg.Name, priority, pt, category)
+			}
+		}
+	}
+
+	if err := cfg.Rules.Validate(); err != nil {
+		return fmt.Errorf("rubric %q: %w", cfg.Name, err)
+	}
+	if err := cfg.Classifier.Validate(); err != nil {
+		return fmt.Errorf("rubric %q: %w", cfg.Name, err)
+	}
+
+	return nil
+}
+
+// NewModernPromptGraderFromConfig creates a grader with the rubric applied over the defaults
+func NewModernPromptGraderFromConfig(cfg RubricConfig) (*ModernPromptGrader, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	grader := NewModernPromptGrader()
+	for pt, w := range cfg.DimensionWeights {
+		grader.dimensionWeights[pt] = w
+	}
+
+	if len(cfg.GradeThresholds) > 0 {
+		thresholds := append([]GradeThreshold{}, cfg.GradeThresholds...)
+		sort.SliceStable(thresholds, func(i, j int) bool {
+			return thresholds[i].MinScore > thresholds[j].MinScore
+		})
+		grader.gradeThresholds = thresholds
+	}
+
+	if len(cfg.SuggestionPriorities) > 0 {
+		grader.suggestionPriorities = make(map[PromptType]map[string]string, len(cfg.SuggestionPriorities))
+		for pt, priorities := range cfg.SuggestionPriorities {
+			copied := make(map[string]string, len(priorities))
+			for category, priority := range priorities {
+				copied[category] = priority
+			}
+			grader.suggestionPriorities[pt] = copied
+		}
+	}
+
+	grader.rules = cfg.Rules
+	if len(cfg.Classifier.Categories) > 0 {
+		grader.classifier = NewPromptClassifierWithModel(cfg.Classifier)
+	}
+
+	return grader, nil
+}
+
+// thresholdGrade maps a score onto the configured grade thresholds
+func (grader *ModernPromptGrader) thresholdGrade(score float64) string {
+	for _, t := range grader.gradeThresholds {
+		if score >= t.MinScore {
+			return t.Grade
+		}
+	}
+	return "F"
+}
+
+// applySuggestionPriorities overrides suggestion priorities from the rubric
+func (grader *ModernPromptGrader) applySuggestionPriorities(suggestions []ModernSuggestion, pt PromptType) {
+	if len(grader.suggestionPriorities) == 0 {
+		return
+	}
+	for i := range suggestions {
+		if priority, ok := grader.suggestionPriorities[pt][suggestions[i].Category]; ok {
+			suggestions[i].Priority = priority
+		} else if priority, ok := grader.suggestionPriorities[allPromptTypes][suggestions[i].Category]; ok {
+			suggestions[i].Priority = priority
+		}
+	}
+}
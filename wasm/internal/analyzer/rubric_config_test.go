@@ -0,0 +1,110 @@
+package analyzer
+
+import (
+	"math"
+	"testing"
+)
+
+// TestRubricConfig checks that a custom rubric overrides weights, thresholds and priorities
+func TestRubricConfig(t *testing.T) {
+	data := []byte(`{
+		"name": "strict",
+		"dimension_weights": {"general": {"clarity": 1}},
+		"grade_thresholds": [{"grade": "Pass", "min_score": 95}, {"grade": "Review", "min_score": 50}],
+		"suggestion_priorities": {"*": {"Specificity": "critical"}}
+	}`)
+
+	cfg, err := LoadRubricConfig(data)
+	if err != nil {
+		t.Fatalf("failed to load rubric: %v", err)
+	}
+	grader, err := NewModernPromptGraderFromConfig(cfg)
+	if err != nil {
+		t.Fatalf("failed to build grader: %v", err)
+	}
+
+	if g := grader.scoreToRealisticGrade(70); g != "Review" {
+		t.Errorf("expected custom grade Review, got %s", g)
+	}
+	if g := grader.scoreToRealisticGrade(20); g != "F" {
+		t.Errorf("expected F below every threshold, got %s", g)
+	}
+
+	dims := ModernDimensions{Clarity: ModernDimension{Score: 80}}
+	if overall := grader.realisticOverallGrade(dims, General); overall.Score != 80 {
+		t.Errorf("expected clarity-only weighting to give 80, got %.2f", overall.Score)
+	}
+
+	suggestions := grader.practicalSuggestions(ModernDimensions{Specificity: ModernDimension{Score: 10}}, General, "", QualityIndicators{})
+	for _, s := range suggestions {
+		if s.Category == "Specificity" && s.Priority != "critical" {
+			t.Errorf("expected Specificity priority override, got %s", s.Priority)
+		}
+	}
+
+	for _, bad := range []string{
+		`{"grade_thresholds": [{"grade": "A", "min_score": 120}]}`,
+		`{"suggestion_priorities": {"general": {"Clarity": "urgent"}}}`,
+		`{"weights": {}}`,
+	} {
+		if _, err := LoadRubricConfig([]byte(bad)); err == nil {
+			t.Errorf("expected error for %s", bad)
+		}
+	}
+}
+
+// TestRubricOption checks a YAML rubric loads and that AnalysisOptions.Rubric
+// grades with it
+func TestRubricOption(t *testing.T) {
+	rubric, err := LoadRubricConfig([]byte(`# Team standard
+name: strict
+dimension_weights:
+  general: {clarity: 1}
+  code_generation: {clarity: 1}
+  technical_spec: {clarity: 1}
+  creative_task: {clarity: 1}
+  data_analysis: {clarity: 1}
+  writing: {clarity: 1}
+  problem_solving: {clarity: 1}
+  learning: {clarity: 1}
+grade_thresholds:
+  - grade: Pass
+    min_score: 99
+  - grade: Review
+    min_score: 0
+rules:
+  disabled: [FUL020]
+`))
+	if err != nil {
+		t.Fatalf("failed to load YAML rubric: %v", err)
+	}
+	if rubric.Name != "strict" || len(rubric.GradeThresholds) != 2 || rubric.GradeThresholds[0].MinScore != 99 {
+		t.Fatalf("rubric = %+v", rubric)
+	}
+
+	text := "Write a Go function that parses RFC 3339 timestamps. Return an error for invalid input and add table-driven tests."
+	opts := AnalysisOptions{Rubric: &rubric}
+	if err := opts.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	envelope, graded := GradeAll(GradersFor(opts), AnalyzeForGrading(text, NewPromptClassifier()))
+	modern, ok := graded[0].(*ModernPromptGrade)
+	if !ok || envelope.Engine != GraderModern {
+		t.Fatalf("expected a rubric to grade with the modern engine, got %s", envelope.Engine)
+	}
+	if envelope.Grade != "Review" || math.Abs(modern.OverallGrade.Score-modern.Dimensions.Clarity.Score) > 0.1 {
+		t.Errorf("expected the rubric's clarity-only weights and thresholds, got %s at %.1f", envelope.Grade, modern.OverallGrade.Score)
+	}
+	for _, s := range modern.Suggestions {
+		if s.Rule == "FUL020" {
+			t.Error("expected the rubric to disable FUL020")
+		}
+	}
+
+	if err := (AnalysisOptions{Grader: GraderClassic, Rubric: &rubric}).Validate(); err == nil {
+		t.Error("expected a rubric with the classic grader to be rejected")
+	}
+	if err := (AnalysisOptions{Rubric: &RubricConfig{GradeThresholds: []GradeThreshold{{Grade: "A", MinScore: 120}}}}).Validate(); err == nil {
+		t.Error("expected an invalid rubric to be rejected")
+	}
+}
@@ -0,0 +1,102 @@
+package analyzer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// RuntimeConfig holds the limits that can change while the process runs,
+// through PATCH /admin/config on servers and setRuntimeConfig in WASM
+type RuntimeConfig struct {
+	// Workers is the number of concurrent stages per analysis when the caller
+	// sets none; 0 means 2 in WASM and one per CPU on servers
+	Workers int `json:"workers"`
+	// RegexCacheEntries caps the compiled runtime pattern cache, which is
+	// emptied when it fills; 0 leaves it unbounded
+	RegexCacheEntries int64 `json:"regex_cache_entries"`
+	// MemoryBudgetBytes is the idea stage allowance when the caller supplies no budget
+	MemoryBudgetBytes int64 `json:"memory_budget_bytes"`
+	// MaxTaskSentences bounds how many sentences task extraction reads before sampling
+	MaxTaskSentences int `json:"max_task_sentences"`
+}
+
+// Upper bounds accepted by RuntimeConfig.Validate
+const (
+	maxRuntimeWorkers     = 256
+	minRuntimeMemoryBytes = 1 << 20
+)
+
+// DefaultRuntimeConfig returns the limits a process starts with
+func DefaultRuntimeConfig() RuntimeConfig {
+	return RuntimeConfig{
+		RegexCacheEntries: 4096,
+		MemoryBudgetBytes: DefaultMemoryBudgetBytes,
+		MaxTaskSentences:  defaultMaxTaskSentences,
+	}
+}
+
+// Validate rejects negative or unusable limits
+func (c RuntimeConfig) Validate() error {
+	if c.Workers < 0 || c.Workers > maxRuntimeWorkers {
+		return fmt.Errorf("workers must be between 0 and %d, got %d", maxRuntimeWorkers, c.Workers)
+	}
+	if c.RegexCacheEntries < 0 {
+		return fmt.Errorf("regex_cache_entries must not be negative, got %d", c.RegexCacheEntries)
+	}
+	if c.MemoryBudgetBytes < minRuntimeMemoryBytes {
+		return fmt.Errorf("memory_budget_bytes must be at least %d, got %d", minRuntimeMemoryBytes, c.MemoryBudgetBytes)
+	}
+	if c.MaxTaskSentences < 1 {
+		return fmt.Errorf("max_task_sentences must be at least 1, got %d", c.MaxTaskSentences)
+	}
+	return nil
+}
+
+var (
+	runtimeConfig   atomic.Pointer[RuntimeConfig]
+	runtimeConfigMu sync.Mutex // Serializes updates; reads go through the pointer
+)
+
+func init() {
+	c := DefaultRuntimeConfig()
+	runtimeConfig.Store(&c)
+}
+
+// CurrentRuntimeConfig returns the limits in effect
+func CurrentRuntimeConfig() RuntimeConfig {
+	return *runtimeConfig.Load()
+}
+
+// UpdateRuntimeConfig applies change to a copy of the current limits and
+// stores the result if it is valid. Analyses already running keep the limits
+// they started with.
+func UpdateRuntimeConfig(change func(*RuntimeConfig) error) (RuntimeConfig, error) {
+	runtimeConfigMu.Lock()
+	defer runtimeConfigMu.Unlock()
+	c := CurrentRuntimeConfig()
+	if err := change(&c); err != nil {
+		return CurrentRuntimeConfig(), err
+	}
+	if err := c.Validate(); err != nil {
+		return CurrentRuntimeConfig(), err
+	}
+	runtimeConfig.Store(&c)
+	trimRegexCache(c.RegexCacheEntries)
+	return c, nil
+}
+
+// PatchRuntimeConfig merges a partial RuntimeConfig in JSON into the current
+// limits; fields the patch leaves out keep their values
+func PatchRuntimeConfig(patch []byte) (RuntimeConfig, error) {
+	return UpdateRuntimeConfig(func(c *RuntimeConfig) error {
+		dec := json.NewDecoder(bytes.NewReader(patch))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(c); err != nil {
+			return fmt.Errorf("invalid runtime config: %w", err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,184 @@
+package analyzer
+
+import (
+	"math"
+	"sort"
+	"strings"
+)
+
+// Shares of a sentence sample reserved for the opening and closing paragraphs
+// and for task sentences; the rest is spread across sections
+const (
+	sampleEdgeShare = 0.25 // Each of the first and last paragraphs
+	sampleTaskShare = 0.5  // Task sentences, after the edges
+)
+
+// sentenceSample is the subset of sentences a sampled stage analyzes
+type sentenceSample struct {
+	indexes  []int   // Kept sentence indexes in text order
+	coverage float64 // Percent of the sentences' characters kept
+}
+
+// pick returns the kept sentences in order
+func (s sentenceSample) pick(sentences []string) []string {
+	kept := make([]string, len(s.indexes))
+	for i, idx := range s.indexes {
+		kept[i] = sentences[idx]
+	}
+	return kept
+}
+
+// sampleSentences keeps at most n sentences so long texts lose as little as
+// possible: the first and last paragraphs are kept first, then every
+// sentence that reads as a task, and the remaining budget is spread over the
+// document's sections in proportion to their length. Sections start at
+// headings; without headings each paragraph is a section.
+func sampleSentences(text string, sentences []string, n int) sentenceSample {
+	if len(sentences) <= n {
+		all := make([]int, len(sentences))
+		for i := range all {
+			all[i] = i
+		}
+		return sentenceSample{indexes: all, coverage: 100}
+	}
+	if n <= 0 {
+		return sentenceSample{indexes: []int{}}
+	}
+
+	section, paragraph := sentenceSections(text, sentences)
+	kept := map[int]bool{}
+
+	// Opening and closing paragraphs frame the whole text
+	edge := max(1, int(float64(n)*sampleEdgeShare))
+	first, last := paragraph[0], paragraph[0]
+	for _, p := range paragraph {
+		first, last = min(first, p), max(last, p)
+	}
+	for i := 0; i < len(sentences) && len(kept) < edge; i++ {
+		if paragraph[i] == first {
+			kept[i] = true
+		}
+	}
+	closing := 0
+	for i := len(sentences) - 1; i >= 0 && closing < edge; i-- {
+		if paragraph[i] == last && !kept[i] {
+			kept[i] = true
+			closing++
+		}
+	}
+
+	// Task sentences drive the task graph and grading
+	taskLimit := min(n, len(kept)+max(1, int(float64(n)*sampleTaskShare)))
+	for i, s := range sentences {
+		if len(kept) >= taskLimit {
+			break
+		}
+		if !kept[i] && extractTaskFromSentence(s, i, 0, 0) != nil {
+			kept[i] = true
+		}
+	}
+
+	// Spread what is left over the sections by their unsampled length
+	remaining := map[int][]int{}
+	sections := []int{}
+	for i := range sentences {
+		if kept[i] {
+			continue
+		}
+		if _, ok := remaining[section[i]]; !ok {
+			sections = append(sections, section[i])
+		}
+		remaining[section[i]] = append(remaining[section[i]], i)
+	}
+	budget := n - len(kept)
+	unkept := len(sentences) - len(kept)
+	type quota struct {
+		section int
+		count   int
+		rest    float64
+	}
+	quotas := make([]quota, len(sections))
+	assigned := 0
+	for j, s := range sections {
+		share := float64(budget) * float64(len(remaining[s])) / float64(unkept)
+		quotas[j] = quota{s, int(share), share - math.Floor(share)}
+		assigned += int(share)
+	}
+	// Largest remainders get the leftover sentences
+	order := make([]int, len(quotas))
+	for j := range order {
+		order[j] = j
+	}
+	sort.SliceStable(order, func(a, b int) bool { return quotas[order[a]].rest > quotas[order[b]].rest })
+	for _, j := range order {
+		if assigned >= budget {
+			break
+		}
+		if quotas[j].count < len(remaining[quotas[j].section]) {
+			quotas[j].count++
+			assigned++
+		}
+	}
+	for _, q := range quotas {
+		for _, i := range sampleEvenly(remaining[q.section], q.count) {
+			kept[i] = true
+		}
+	}
+
+	sample := sentenceSample{indexes: make([]int, 0, len(kept))}
+	keptChars, totalChars := 0, 0
+	for i, s := range sentences {
+		totalChars += len(s)
+		if kept[i] {
+			sample.indexes = append(sample.indexes, i)
+			keptChars += len(s)
+		}
+	}
+	if totalChars > 0 {
+		sample.coverage = math.Round(float64(keptChars)/float64(totalChars)*10000) / 100
+	}
+	return sample
+}
+
+// sentenceSections finds the section and paragraph of each sentence. Sentences
+// are located in order, falling back to their first occurrence for lists that
+// arrive out of text order.
+func sentenceSections(text string, sentences []string) (section, paragraph []int) {
+	bounds := paragraphBounds(text)
+	headings := make([]bool, len(bounds))
+	hasHeadings := false
+	for p, b := range bounds {
+		headings[p] = layoutRole(text[b[0]:b[1]]) == RoleHeading
+		hasHeadings = hasHeadings || headings[p]
+	}
+	sectionOf := make([]int, len(bounds))
+	current := 0
+	for p := range bounds {
+		if headings[p] || !hasHeadings {
+			current = p
+		}
+		sectionOf[p] = current
+	}
+
+	section = make([]int, len(sentences))
+	paragraph = make([]int, len(sentences))
+	cursor := 0
+	for i, s := range sentences {
+		pos := strings.Index(text[cursor:], s)
+		if pos >= 0 {
+			pos += cursor
+			cursor = pos + len(s)
+		} else {
+			pos = strings.Index(text, s)
+		}
+		p := sort.Search(len(bounds), func(k int) bool { return bounds[k][1] > pos })
+		if pos < 0 || p == len(bounds) {
+			p = max(0, len(bounds)-1)
+		}
+		if len(bounds) > 0 {
+			section[i] = sectionOf[p]
+		}
+		paragraph[i] = p
+	}
+	return section, paragraph
+}
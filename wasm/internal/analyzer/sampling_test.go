@@ -0,0 +1,93 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// samplingTestText has an intro, two sections of unequal length and a
+// closing paragraph, with one task sentence buried mid-way
+func samplingTestText() (string, []string) {
+	var b strings.Builder
+	b.WriteString("Opening context sentence one. Opening context sentence two.\n\n# Alpha\n\n")
+	for i := 0; i < 30; i++ {
+		fmt.Fprintf(&b, "Alpha note number %d is here. ", i)
+		if i == 17 {
+			b.WriteString("Please update the deployment script. ")
+		}
+	}
+	b.WriteString("\n\n# Beta\n\n")
+	for i := 0; i < 10; i++ {
+		fmt.Fprintf(&b, "Beta note number %d is here. ", i)
+	}
+	b.WriteString("\n\nClosing summary sentence one. Closing summary sentence two.")
+	text := b.String()
+	return text, extractSentences(text)
+}
+
+func TestSampleSentencesKeepsEdgesAndTasks(t *testing.T) {
+	text, sentences := samplingTestText()
+	sample := sampleSentences(text, sentences, 12)
+	if len(sample.indexes) != 12 {
+		t.Fatalf("kept %d sentences, want 12", len(sample.indexes))
+	}
+	kept := strings.Join(sample.pick(sentences), " | ")
+	for _, want := range []string{"Opening context sentence one", "Closing summary sentence two", "update the deployment script"} {
+		if !strings.Contains(kept, want) {
+			t.Errorf("sample lost %q: %s", want, kept)
+		}
+	}
+	for i := 1; i < len(sample.indexes); i++ {
+		if sample.indexes[i] <= sample.indexes[i-1] {
+			t.Fatalf("indexes out of order: %v", sample.indexes)
+		}
+	}
+}
+
+func TestSampleSentencesSplitsSectionsProportionally(t *testing.T) {
+	text, sentences := samplingTestText()
+	sample := sampleSentences(text, sentences, 20)
+	alpha, beta := 0, 0
+	for _, s := range sample.pick(sentences) {
+		switch {
+		case strings.HasPrefix(s, "Alpha"):
+			alpha++
+		case strings.HasPrefix(s, "Beta"):
+			beta++
+		}
+	}
+	if beta == 0 || alpha <= 2*beta {
+		t.Errorf("alpha %d, beta %d: want the longer section sampled about three times as much", alpha, beta)
+	}
+}
+
+func TestSampleSentencesCoverage(t *testing.T) {
+	text, sentences := samplingTestText()
+	if got := sampleSentences(text, sentences, len(sentences)).coverage; got != 100 {
+		t.Errorf("full coverage = %v, want 100", got)
+	}
+	sample := sampleSentences(text, sentences, 10)
+	kept, total := 0, 0
+	for _, s := range sentences {
+		total += len(s)
+	}
+	for _, s := range sample.pick(sentences) {
+		kept += len(s)
+	}
+	want := float64(kept) / float64(total) * 100
+	if sample.coverage <= 0 || sample.coverage >= 100 || sample.coverage-want > 0.01 || want-sample.coverage > 0.01 {
+		t.Errorf("coverage = %v, want %.2f", sample.coverage, want)
+	}
+}
+
+func TestExtractTaskGraphReportsSampling(t *testing.T) {
+	text := strings.Repeat("The weather was calm that day. ", defaultMaxTaskSentences) + "We need to fix the login page."
+	graph := ExtractTaskGraphFromText(text)
+	if graph.TotalTasks != 1 {
+		t.Errorf("tasks = %d, want the trailing task kept", graph.TotalTasks)
+	}
+	if graph.sampling == nil || graph.sampling.Stage != "task_extraction" || graph.sampling.Coverage >= 100 {
+		t.Errorf("sampling = %+v, want a task_extraction degradation below full coverage", graph.sampling)
+	}
+}
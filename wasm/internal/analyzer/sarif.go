@@ -0,0 +1,263 @@
+package analyzer
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// SARIF 2.1.0 identifiers
+const (
+	SARIFVersion = "2.1.0"
+	SARIFSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+// SARIFLog is the top-level SARIF document consumed by GitHub code scanning and IDEs
+type SARIFLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []SARIFRun `json:"runs"`
+}
+
+// SARIFRun holds the results of one fulcrum invocation
+type SARIFRun struct {
+	Tool       SARIFTool     `json:"tool"`
+	ColumnKind string        `json:"columnKind"`
+	Results    []SARIFResult `json:"results"`
+}
+
+// SARIFTool describes fulcrum and the rules its results refer to
+type SARIFTool struct {
+	Driver SARIFDriver `json:"driver"`
+}
+
+// SARIFDriver is the analysis tool component
+type SARIFDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Version        string      `json:"version"`
+	Rules          []SARIFRule `json:"rules"`
+}
+
+// SARIFRule is a reportingDescriptor for one rule ID
+type SARIFRule struct {
+	ID                   string       `json:"id"`
+	Name                 string       `json:"name"`
+	ShortDescription     SARIFMessage `json:"shortDescription"`
+	DefaultConfiguration SARIFConfig  `json:"defaultConfiguration"`
+	Properties           SARIFProps   `json:"properties"`
+}
+
+// SARIFConfig sets a rule's default level
+type SARIFConfig struct {
+	Level string `json:"level"`
+}
+
+// SARIFProps carries tags shown as categories by code scanning
+type SARIFProps struct {
+	Tags []string `json:"tags"`
+}
+
+// SARIFMessage is a plain-text message
+type SARIFMessage struct {
+	Text string `json:"text"`
+}
+
+// SARIFResult is a single finding
+type SARIFResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     string          `json:"level"` // "error", "warning" or "note"
+	Message   SARIFMessage    `json:"message"`
+	Locations []SARIFLocation `json:"locations"`
+}
+
+// SARIFLocation points a result at a file region
+type SARIFLocation struct {
+	PhysicalLocation SARIFPhysicalLocation `json:"physicalLocation"`
+}
+
+// SARIFPhysicalLocation is a region within an artifact
+type SARIFPhysicalLocation struct {
+	ArtifactLocation SARIFArtifactLocation `json:"artifactLocation"`
+	Region           SARIFRegion           `json:"region"`
+}
+
+// SARIFArtifactLocation identifies the analyzed file
+type SARIFArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+// SARIFRegion is 1-based; columns count Unicode code points
+type SARIFRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn"`
+	EndLine     int `json:"endLine"`
+	EndColumn   int `json:"endColumn"`
+	CharOffset  int `json:"charOffset"`
+	CharLength  int `json:"charLength"`
+}
+
+// SARIFDocument is one analyzed prompt file to include in a SARIF log
+type SARIFDocument struct {
+	URI    string // Path relative to the repository root
+	Text   string
+	Result *CombinedResult
+}
+
+// Rule IDs for findings that do not come from suggestion rules
+const (
+	RuleWeakFactor = "FUL100"
+	RuleSpelling   = "FUL101"
+	RuleGrammar    = "FUL102"
+	RuleStyle      = "FUL103"
+	RuleQuality    = "FUL104"
+	RulePII        = "FUL105"
+	RuleInjection  = "FUL106"
+)
+
+// findingRules describes the annotation sources exported alongside suggestion rules
+var findingRules = []SuggestionRule{
+	{RuleWeakFactor, "Grade", "low", "Text region lowers a grade factor"},
+	{RuleSpelling, "Quality", "low", "Possible misspelling"},
+	{RuleGrammar, "Quality", "medium", "Grammar issue"},
+	{RuleStyle, "Quality", "low", "Style suggestion"},
+	{RuleQuality, "Quality", "medium", "Formatting or punctuation issue"},
+	{RulePII, "Privacy", "medium", "Personal data in the prompt"},
+	{RuleInjection, "Security", "high", "Possible prompt injection"},
+}
+
+// annotationRuleIDs maps annotation sources other than suggestions to rule IDs
+var annotationRuleIDs = map[string]string{
+	AnnotationFactor:    RuleWeakFactor,
+	AnnotationSpelling:  RuleSpelling,
+	AnnotationGrammar:   RuleGrammar,
+	AnnotationStyle:     RuleStyle,
+	AnnotationQuality:   RuleQuality,
+	AnnotationPII:       RulePII,
+	AnnotationInjection: RuleInjection,
+}
+
+// ExportSARIF converts suggestions and located findings into a SARIF log.
+// Suggestions without a span are reported on the first line of the prompt,
+// below any front matter;
+// thought-type annotations are informational and left out.
+func ExportSARIF(docs []SARIFDocument) *SARIFLog {
+	catalog := append(append([]SuggestionRule{}, SuggestionRules...), findingRules...)
+	rules := make([]SARIFRule, len(catalog))
+	ruleIndex := make(map[string]int, len(catalog))
+	for i, r := range catalog {
+		rules[i] = SARIFRule{
+			ID:                   r.ID,
+			Name:                 ruleName(r.Summary),
+			ShortDescription:     SARIFMessage{Text: r.Summary},
+			DefaultConfiguration: SARIFConfig{Level: sarifLevel(r.Priority)},
+			Properties:           SARIFProps{Tags: []string{"prompt-quality", strings.ToLower(r.Dimension)}},
+		}
+		ruleIndex[r.ID] = i
+	}
+
+	results := []SARIFResult{}
+	for _, doc := range docs {
+		if doc.Result == nil {
+			continue
+		}
+		addResult := func(ruleID, severity, message string, span Span) {
+			index, ok := ruleIndex[ruleID]
+			if !ok {
+				return
+			}
+			results = append(results, SARIFResult{
+				RuleID:    ruleID,
+				RuleIndex: index,
+				Level:     sarifLevel(severity),
+				Message:   SARIFMessage{Text: message},
+				Locations: []SARIFLocation{{PhysicalLocation: SARIFPhysicalLocation{
+					ArtifactLocation: SARIFArtifactLocation{URI: doc.URI},
+					Region:           sarifRegion(doc.Text, fileSpan(doc.Result, span)),
+				}}},
+			})
+		}
+
+		for _, s := range gradeSuggestions(doc.Result) {
+			if len(s.Spans) == 0 {
+				addResult(s.Rule, s.Priority, s.Message, firstLineSpan(doc.Text[promptStart(doc.Result):]))
+			}
+		}
+		for _, a := range doc.Result.Annotations {
+			ruleID := a.Rule
+			if a.Source != AnnotationSuggestion {
+				if ruleID = annotationRuleIDs[a.Source]; ruleID == "" {
+					continue
+				}
+			}
+			addResult(ruleID, a.Severity, a.Message, a.Span)
+		}
+	}
+
+	return &SARIFLog{
+		Version: SARIFVersion,
+		Schema:  SARIFSchema,
+		Runs: []SARIFRun{{
+			Tool: SARIFTool{Driver: SARIFDriver{
+				Name:           "fulcrum",
+				InformationURI: "https://github.com/imran31415/fulcrum",
+				Version:        ResultSchemaVersion,
+				Rules:          rules,
+			}},
+			ColumnKind: "unicodeCodePoints",
+			Results:    results,
+		}},
+	}
+}
+
+// sarifLevel maps a priority or severity to a SARIF level
+func sarifLevel(severity string) string {
+	switch severity {
+	case "critical", "high":
+		return "error"
+	case "medium":
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+// ruleName turns a rule summary into a PascalCase SARIF rule name
+func ruleName(summary string) string {
+	var sb strings.Builder
+	for _, word := range alphaWordPattern.FindAllString(summary, -1) {
+		sb.WriteString(strings.ToUpper(word[:1]) + strings.ToLower(word[1:]))
+	}
+	return sb.String()
+}
+
+// firstLineSpan covers the first line of text, for findings about the whole prompt
+func firstLineSpan(text string) Span {
+	end := strings.IndexByte(text, '\n')
+	if end < 0 {
+		end = len(text)
+	}
+	return newSpan(text, 0, end)
+}
+
+// sarifRegion converts a byte span into 1-based lines and code-point columns
+func sarifRegion(text string, span Span) SARIFRegion {
+	startLine, startCol := lineColumn(text, span.Start)
+	endLine, endCol := lineColumn(text, span.End)
+	return SARIFRegion{
+		StartLine:   startLine,
+		StartColumn: startCol,
+		EndLine:     endLine,
+		EndColumn:   endCol,
+		CharOffset:  utf8.RuneCountInString(text[:span.Start]),
+		CharLength:  utf8.RuneCountInString(text[span.Start:span.End]),
+	}
+}
+
+// lineColumn returns the 1-based line and code-point column of a byte offset
+func lineColumn(text string, offset int) (int, int) {
+	before := text[:offset]
+	line := strings.Count(before, "\n") + 1
+	lineStart := strings.LastIndexByte(before, '\n') + 1
+	return line, utf8.RuneCountInString(before[lineStart:]) + 1
+}
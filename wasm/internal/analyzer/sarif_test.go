@@ -0,0 +1,71 @@
+package analyzer
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestExportSARIF(t *testing.T) {
+	text := "Fix it now.\nWe definately need this, contact ops@example.com today."
+	result, err := Analyze(context.Background(), text, AnalysisOptions{}, AnalysisRun{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := ExportSARIF([]SARIFDocument{{URI: "prompts/fix.md", Text: text, Result: result}})
+
+	b, err := json.Marshal(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(b, &decoded); err != nil || decoded["version"] != SARIFVersion {
+		t.Fatalf("expected a SARIF %s document, got %s", SARIFVersion, b)
+	}
+
+	run := log.Runs[0]
+	found := map[string]SARIFResult{}
+	for _, r := range run.Results {
+		if run.Tool.Driver.Rules[r.RuleIndex].ID != r.RuleID {
+			t.Errorf("result %s points at rule %s", r.RuleID, run.Tool.Driver.Rules[r.RuleIndex].ID)
+		}
+		if r.Locations[0].PhysicalLocation.ArtifactLocation.URI != "prompts/fix.md" {
+			t.Errorf("unexpected artifact %+v", r.Locations[0])
+		}
+		found[r.RuleID] = r
+	}
+	spelling, ok := found[RuleSpelling]
+	if !ok {
+		t.Fatalf("expected a spelling result, got %v", run.Results)
+	}
+	if region := spelling.Locations[0].PhysicalLocation.Region; region.StartLine != 2 || region.StartColumn != 4 {
+		t.Errorf("expected misspelling at 2:4, got %d:%d", region.StartLine, region.StartColumn)
+	}
+	if _, ok := found[RulePII]; !ok {
+		t.Error("expected the email address to be reported")
+	}
+}
+
+func TestLineColumnCountsCodePoints(t *testing.T) {
+	text := "héllo\nwörld"
+	if line, col := lineColumn(text, len("héllo\nw")); line != 2 || col != 2 {
+		t.Errorf("expected 2:2, got %d:%d", line, col)
+	}
+}
+
+func TestExportSARIFSkipsFrontMatter(t *testing.T) {
+	text := "---\nmodel: gpt-4o\n---\nFix it now.\nWe definately need this."
+	result, err := Analyze(context.Background(), text, AnalysisOptions{}, AnalysisRun{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range ExportSARIF([]SARIFDocument{{URI: "fix.prompt.md", Text: text, Result: result}}).Runs[0].Results {
+		region := r.Locations[0].PhysicalLocation.Region
+		if region.StartLine < 4 {
+			t.Errorf("%s reported at line %d, inside the front matter", r.RuleID, region.StartLine)
+		}
+		if r.RuleID == RuleSpelling && (region.StartLine != 5 || region.StartColumn != 4) {
+			t.Errorf("expected misspelling at 5:4, got %d:%d", region.StartLine, region.StartColumn)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// ResultSchemaVersion is bumped whenever the shape of the analysis result changes.
+// Minor versions add fields; major versions rename or remove them.
+const ResultSchemaVersion = "3.0.0"
+
+// GenerateJSONSchema builds a JSON Schema (draft 2020-12) from a Go value's type.
+// Named struct types are emitted once under $defs and referenced elsewhere.
+func GenerateJSONSchema(v interface{}, title string) map[string]interface{} {
+	g := newSchemaGenerator("#/$defs/")
+	root := g.schemaFor(reflect.TypeOf(v))
+
+	schema := map[string]interface{}{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"title":   title,
+		"version": ResultSchemaVersion,
+	}
+	for k, val := range root {
+		schema[k] = val
+	}
+	if len(g.defs) > 0 {
+		schema["$defs"] = g.defs
+	}
+	return schema
+}
+
+// schemaGenerator tracks named types already emitted into $defs
+type schemaGenerator struct {
+	defs      map[string]interface{}
+	refPrefix string // "#/$defs/" for JSON Schema, "#/components/schemas/" for OpenAPI
+}
+
+func newSchemaGenerator(refPrefix string) *schemaGenerator {
+	return &schemaGenerator{defs: make(map[string]interface{}), refPrefix: refPrefix}
+}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+// schemaFor returns the schema for a type, registering named structs as definitions
+func (g *schemaGenerator) schemaFor(t reflect.Type) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == timeType {
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	}
+	if t == rawMessageType {
+		return map[string]interface{}{} // Any JSON value
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": g.schemaFor(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": g.schemaFor(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		name := t.Name()
+		if _, ok := g.defs[name]; !ok {
+			g.defs[name] = map[string]interface{}{} // Placeholder guards recursive types
+			g.defs[name] = g.structSchema(t)
+		}
+		return map[string]interface{}{"$ref": g.refPrefix + name}
+	default:
+		return map[string]interface{}{}
+	}
+}
+
+// structSchema describes the exported JSON fields of a struct
+func (g *schemaGenerator) structSchema(t reflect.Type) map[string]interface{} {
+	properties := make(map[string]interface{})
+	required := []string{}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		name, omitEmpty, skip := parseJSONTag(field)
+		if skip {
+			continue
+		}
+
+		properties[name] = g.schemaFor(field.Type)
+		if !omitEmpty {
+			required = append(required, name)
+		}
+	}
+
+	schema := map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// parseJSONTag returns the JSON name of a field and whether it is optional or skipped
+func parseJSONTag(field reflect.StructField) (string, bool, bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false, true
+	}
+
+	parts := strings.Split(tag, ",")
+	name := parts[0]
+	if name == "" {
+		name = field.Name
+	}
+
+	omitEmpty := false
+	for _, opt := range parts[1:] {
+		if opt == "omitempty" {
+			omitEmpty = true
+		}
+	}
+	return name, omitEmpty, false
+}
@@ -0,0 +1,87 @@
+package analyzer
+
+import "math"
+
+// ScoreTrace records how a score was computed: each factor's raw inputs, how
+// they were normalized to 0-100, and how its weight was applied
+type ScoreTrace struct {
+	Steps       []TraceStep       `json:"steps"`
+	Adjustments []TraceAdjustment `json:"adjustments"`  // Weight changes applied after the factors were scored
+	WeightedSum float64           `json:"weighted_sum"` // Sum of the contributions
+	TotalWeight float64           `json:"total_weight"`
+	Score       float64           `json:"score"` // WeightedSum rounded to two decimals
+}
+
+// TraceStep is one factor's path from raw inputs to its contribution
+type TraceStep struct {
+	Factor        string             `json:"factor"`
+	Inputs        map[string]float64 `json:"inputs"`        // Raw measurements; empty for fixed defaults
+	Normalization string             `json:"normalization"` // How the inputs became Value
+	Value         float64            `json:"value"`         // Normalized 0-100 score
+	Weight        float64            `json:"weight"`        // Weight after adjustments
+	Contribution  float64            `json:"contribution"`  // Value * Weight
+	RunningTotal  float64            `json:"running_total"` // Sum of contributions so far
+}
+
+// TraceAdjustment is a change to factor weights applied across a dimension
+type TraceAdjustment struct {
+	Name       string  `json:"name"`
+	Detail     string  `json:"detail"`
+	Multiplier float64 `json:"multiplier"`
+}
+
+// traceDimension builds the trace for a scored dimension from its factors
+func traceDimension(dim GradeDimension) *ScoreTrace {
+	trace := &ScoreTrace{Steps: []TraceStep{}, Adjustments: []TraceAdjustment{}}
+	trace.Adjustments = append(trace.Adjustments, dim.adjustments...)
+	for _, f := range dim.Factors {
+		inputs := f.inputs
+		if inputs == nil {
+			inputs = map[string]float64{}
+		}
+		trace.WeightedSum += f.Contribution
+		trace.TotalWeight += f.Weight
+		trace.Steps = append(trace.Steps, TraceStep{
+			Factor:        f.Name,
+			Inputs:        inputs,
+			Normalization: f.normalization,
+			Value:         f.Value,
+			Weight:        f.Weight,
+			Contribution:  f.Contribution,
+			RunningTotal:  trace.WeightedSum,
+		})
+	}
+	trace.Score = math.Round(trace.WeightedSum*100) / 100
+	return trace
+}
+
+// attachScoreTraces sets the trace of every dimension and of the overall
+// grade, whose steps are the dimensions themselves
+func attachScoreTraces(grade *PromptGrade) {
+	overall := &ScoreTrace{Steps: []TraceStep{}, Adjustments: []TraceAdjustment{}}
+	for _, d := range overallDimensions(grade) {
+		d.dim.Trace = traceDimension(*d.dim)
+		contribution := d.dim.Score * d.weight
+		overall.WeightedSum += contribution
+		overall.TotalWeight += d.weight
+		overall.Steps = append(overall.Steps, TraceStep{
+			Factor:        d.name,
+			Inputs:        map[string]float64{"dimension_score": d.dim.Score},
+			Normalization: "dimension score, rounded to two decimals",
+			Value:         d.dim.Score,
+			Weight:        d.weight,
+			Contribution:  contribution,
+			RunningTotal:  overall.WeightedSum,
+		})
+	}
+	overall.Score = math.Round(overall.WeightedSum*100) / 100
+	grade.OverallGrade.Trace = overall
+}
+
+// boolInput records a yes/no measurement as 1 or 0
+func boolInput(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
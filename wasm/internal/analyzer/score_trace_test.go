@@ -0,0 +1,84 @@
+package analyzer
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+)
+
+const tracePrompt = "You are a senior Go engineer. Write unit tests for the tokenizer in tokenizer.go. " +
+	"Cover empty input and Unicode text. Rewriting the tokenizer is out of scope."
+
+func TestExplainModeTracesEveryDimension(t *testing.T) {
+	result, err := Analyze(context.Background(), tracePrompt, AnalysisOptions{Explain: true}, AnalysisRun{})
+	if err != nil {
+		t.Fatalf("Analyze: %v", err)
+	}
+	grade := &result.PromptGrade
+	for _, d := range overallDimensions(grade) {
+		trace := d.dim.Trace
+		if trace == nil {
+			t.Fatalf("%s: expected a trace in explain mode", d.name)
+		}
+		if len(trace.Steps) != len(d.dim.Factors) {
+			t.Errorf("%s: expected a step per factor, got %d for %d", d.name, len(trace.Steps), len(d.dim.Factors))
+		}
+		if trace.Score != d.dim.Score {
+			t.Errorf("%s: trace score %.2f does not match dimension score %.2f", d.name, trace.Score, d.dim.Score)
+		}
+		last := trace.Steps[len(trace.Steps)-1]
+		if math.Abs(last.RunningTotal-trace.WeightedSum) > 1e-9 || math.Abs(trace.TotalWeight-1) > 1e-9 {
+			t.Errorf("%s: running total %.4f, weighted sum %.4f, total weight %.4f", d.name, last.RunningTotal, trace.WeightedSum, trace.TotalWeight)
+		}
+		for _, step := range trace.Steps {
+			if step.Normalization == "" {
+				t.Errorf("%s/%s: expected a normalization", d.name, step.Factor)
+			}
+			if math.Abs(step.Contribution-step.Value*step.Weight) > 1e-9 {
+				t.Errorf("%s/%s: contribution is not value * weight", d.name, step.Factor)
+			}
+		}
+	}
+
+	reading := grade.Understandability.Trace.Steps[0]
+	if reading.Factor != "Reading Ease" || reading.Inputs["flesch_reading_ease"] == 0 {
+		t.Errorf("expected the raw Flesch score as an input, got %+v", reading)
+	}
+	goals := grade.ContextSufficiency.Trace.Steps[5]
+	if goals.Factor != "Clear Goals" || goals.Inputs["primary_goals"] != 1 || goals.Inputs["non_goals"] != 1 {
+		t.Errorf("expected goal counts as inputs, got %+v", goals)
+	}
+	adjustments := grade.ContextSufficiency.Trace.Adjustments
+	if len(adjustments) != 1 || adjustments[0].Multiplier >= 1 {
+		t.Errorf("expected the role weight rescale as an adjustment, got %+v", adjustments)
+	}
+
+	overall := grade.OverallGrade.Trace
+	if overall == nil || len(overall.Steps) != 8 || overall.Score != grade.OverallGrade.Score {
+		t.Fatalf("expected an overall trace over the 8 dimensions matching the score, got %+v", overall)
+	}
+}
+
+func TestTraceOmittedWithoutExplain(t *testing.T) {
+	result, err := Analyze(context.Background(), tracePrompt, AnalysisOptions{}, AnalysisRun{})
+	if err != nil {
+		t.Fatalf("Analyze: %v", err)
+	}
+	if result.PromptGrade.Clarity.Trace != nil || result.PromptGrade.OverallGrade.Trace != nil {
+		t.Error("expected no traces without explain mode")
+	}
+	data, err := json.Marshal(result.PromptGrade)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), `"trace"`) || strings.Contains(string(data), `"normalization"`) {
+		t.Error("expected trace details to stay out of the default JSON")
+	}
+
+	opts, err := ParseAnalysisOptions([]byte(`{"explain": true}`))
+	if err != nil || !opts.Explain {
+		t.Errorf("expected explain to parse from options JSON, got %+v, %v", opts, err)
+	}
+}
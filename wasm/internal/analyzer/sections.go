@@ -0,0 +1,196 @@
+package analyzer
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// minSectionWords keeps a heading with a line or two under it out of the
+// scoreboard; such sections are too short for a grade to mean anything
+const minSectionWords = 10
+
+// markdownHeadingPattern matches an ATX heading line ("## Rollout plan")
+var markdownHeadingPattern = regexp.MustCompile(`(?m)^[ \t]{0,3}#{1,6}[ \t]+\S.*$`)
+
+// SectionGrade is one section of a long document graded on its own
+type SectionGrade struct {
+	Heading    string             `json:"heading"` // Without markdown markers; "" for text before the first heading
+	Span       Span               `json:"span"`    // The heading and everything up to the next one
+	Words      int                `json:"words"`
+	Score      float64            `json:"score"`
+	Grade      string             `json:"grade"`
+	Dimensions map[string]float64 `json:"dimensions"`
+	Weakest    string             `json:"weakest"` // The section's lowest dimension
+	Impact     float64            `json:"impact"`  // Points the rollup would gain without this section; negative when it lifts the rollup
+}
+
+// SectionReport grades each section of a multi-section document, such as a
+// spec or PRD, so the section that drags the document down stands out
+type SectionReport struct {
+	Engine   string         `json:"engine"`
+	Document float64        `json:"document"` // Score of the whole text graded at once
+	Score    float64        `json:"score"`    // Section scores averaged by word count
+	Grade    string         `json:"grade"`
+	Sections []SectionGrade `json:"sections"` // In document order
+	Weakest  int            `json:"weakest"`  // Index in Sections of the largest impact
+	Summary  string         `json:"summary"`
+}
+
+// GradeSections splits text at its headings and grades each section with the
+// engines opts selects, reporting the leading engine's scores. The rollup
+// weights each section by its words, and a section's impact is how far the
+// rollup would rise without it. It returns nil unless at least two sections
+// have minSectionWords words.
+func GradeSections(text string, document GradeSummary, opts AnalysisOptions) *SectionReport {
+	graders := GradersFor(opts)
+	classifier := NewPromptClassifierWithModel(opts.classifierModel())
+	report := &SectionReport{Engine: document.Engine, Document: document.Score, Sections: []SectionGrade{}}
+	for _, s := range splitSections(text) {
+		body := s.span.Text
+		if s.heading != "" {
+			body = text[s.headingEnd:s.span.End]
+		}
+		words := len(extractWords(body))
+		if words < minSectionWords {
+			continue
+		}
+		envelope, _ := GradeAll(graders, AnalyzeForGrading(s.span.Text, classifier))
+		summary := envelope.Results[0]
+		section := SectionGrade{
+			Heading:    s.heading,
+			Span:       s.span,
+			Words:      words,
+			Score:      summary.Score,
+			Grade:      summary.Grade,
+			Dimensions: summary.Dimensions,
+		}
+		for name, v := range summary.Dimensions {
+			if section.Weakest == "" || v < summary.Dimensions[section.Weakest] || (v == summary.Dimensions[section.Weakest] && name < section.Weakest) {
+				section.Weakest = name
+			}
+		}
+		report.Sections = append(report.Sections, section)
+	}
+	if len(report.Sections) < 2 {
+		return nil
+	}
+
+	rollup := func(skip int) float64 {
+		total, words := 0.0, 0
+		for i, s := range report.Sections {
+			if i != skip {
+				total += s.Score * float64(s.Words)
+				words += s.Words
+			}
+		}
+		return total / float64(words)
+	}
+	score := rollup(-1)
+	report.Score = math.Round(score*10) / 10
+	report.Grade = letterGrade(report.Engine, report.Score)
+	for i := range report.Sections {
+		report.Sections[i].Impact = math.Round((rollup(i)-score)*10) / 10
+		if report.Sections[i].Impact > report.Sections[report.Weakest].Impact {
+			report.Weakest = i
+		}
+	}
+
+	weakest := report.Sections[report.Weakest]
+	report.Summary = fmt.Sprintf("%d sections average %.1f (%s)", len(report.Sections), report.Score, report.Grade)
+	if weakest.Impact > 0 {
+		report.Summary += fmt.Sprintf("; %s drags it down most at %.1f (%s), costing %.1f points", sectionLabel(weakest.Heading), weakest.Score, weakest.Grade, weakest.Impact)
+	}
+	return report
+}
+
+// documentSection is a stretch of text from one heading to the next
+type documentSection struct {
+	heading    string
+	headingEnd int // Offset just past the heading line
+	span       Span
+}
+
+// splitSections cuts text at markdown headings and at short label lines
+// standing alone as a paragraph ("Rollout plan:"). Headings inside code
+// fences don't count. Text before the first heading is a section without one.
+func splitSections(text string) []documentSection {
+	fenced := patternSpans(text, inlineCodePattern)
+	inFence := func(pos int) bool {
+		for _, f := range fenced {
+			if pos >= f.Start && pos < f.End {
+				return true
+			}
+		}
+		return false
+	}
+	headings := map[int]int{} // Start offset to end offset
+	for _, m := range markdownHeadingPattern.FindAllStringIndex(text, -1) {
+		if !inFence(m[0]) {
+			headings[m[0]] = m[1]
+		}
+	}
+	for _, b := range paragraphBounds(text) {
+		if layoutRole(text[b[0]:b[1]]) == RoleHeading && !inFence(b[0]) {
+			headings[b[0]] = b[1]
+		}
+	}
+	starts := make([]int, 0, len(headings))
+	for start := range headings {
+		starts = append(starts, start)
+	}
+	sort.Ints(starts)
+
+	var sections []documentSection
+	add := func(start, end, headingEnd int) {
+		span := trimmedSpan(text, start, end)
+		if span.Text == "" {
+			return
+		}
+		s := documentSection{span: span}
+		if headingEnd > 0 {
+			s.heading = headingText(text[start:headingEnd])
+			s.headingEnd = headingEnd
+		}
+		sections = append(sections, s)
+	}
+	if len(starts) == 0 || starts[0] > 0 {
+		end := len(text)
+		if len(starts) > 0 {
+			end = starts[0]
+		}
+		add(0, end, 0)
+	}
+	for i, start := range starts {
+		end := len(text)
+		if i+1 < len(starts) {
+			end = starts[i+1]
+		}
+		add(start, end, headings[start])
+	}
+	return sections
+}
+
+// headingText strips markdown markers and a trailing colon from a heading line
+func headingText(line string) string {
+	line = strings.TrimSuffix(strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line), "#")), ":")
+	return strings.TrimSpace(strings.TrimSuffix(strings.Trim(line, "*_"), ":"))
+}
+
+// sectionLabel names a section in a summary
+func sectionLabel(heading string) string {
+	if heading == "" {
+		return "the opening text"
+	}
+	return fmt.Sprintf("%q", heading)
+}
+
+// letterGrade converts a score to a letter on the engine's own scale
+func letterGrade(engine string, score float64) string {
+	if engine == GraderModern {
+		return (&ModernPromptGrader{}).scoreToRealisticGrade(score)
+	}
+	return scoreToGrade(score)
+}
@@ -0,0 +1,86 @@
+package analyzer
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+const sectionsTestDoc = `This spec describes the new invoice export for the billing dashboard and who owns each part of it.
+
+## Goals
+Export every invoice for a selected month as a CSV file with one row per line item. Include the customer name, plan, amount and tax columns. The export must finish in under ten seconds for accounts with five thousand invoices.
+
+## Rollout plan
+Do it soon. Maybe ship it to some people and see what happens with things.
+
+` + "```" + `
+# not a heading
+` + "```" + `
+
+Notes:
+
+Keep it short.`
+
+func TestSplitSections(t *testing.T) {
+	sections := splitSections(sectionsTestDoc)
+	var headings []string
+	for _, s := range sections {
+		headings = append(headings, s.heading)
+		if !strings.HasPrefix(sectionsTestDoc[s.span.Start:], s.span.Text) {
+			t.Errorf("span %+v does not match the text", s.span)
+		}
+	}
+	if got := strings.Join(headings, "|"); got != "|Goals|Rollout plan|Notes" {
+		t.Errorf("headings = %q", got)
+	}
+	if got := headingText("## **Open questions**:"); got != "Open questions" {
+		t.Errorf("headingText = %q", got)
+	}
+}
+
+func TestGradeSections(t *testing.T) {
+	opts := AnalysisOptions{}
+	envelope, _ := GradeAll(GradersFor(opts), AnalyzeForGrading(sectionsTestDoc, NewPromptClassifier()))
+	report := GradeSections(sectionsTestDoc, envelope.Results[0], opts)
+	if report == nil {
+		t.Fatal("expected a section report")
+	}
+	// "Notes" has too few words to grade
+	if len(report.Sections) != 3 || report.Sections[1].Heading != "Goals" || report.Sections[2].Heading != "Rollout plan" {
+		t.Fatalf("sections = %+v", report.Sections)
+	}
+	if report.Document != envelope.Score || report.Grade != scoreToGrade(report.Score) || report.Engine != GraderClassic {
+		t.Errorf("report = %+v", report)
+	}
+	goals, rollout := report.Sections[1], report.Sections[2]
+	if rollout.Score >= goals.Score {
+		t.Errorf("vague rollout plan scored %.1f, goals %.1f", rollout.Score, goals.Score)
+	}
+	weakest := report.Sections[report.Weakest]
+	for _, s := range report.Sections {
+		if s.Impact > weakest.Impact {
+			t.Errorf("%q has impact %.1f above the weakest %.1f", s.Heading, s.Impact, weakest.Impact)
+		}
+		if s.Weakest == "" || len(s.Dimensions) == 0 {
+			t.Errorf("section %+v", s)
+		}
+	}
+	if !strings.HasPrefix(report.Summary, "3 sections average") {
+		t.Errorf("summary = %q", report.Summary)
+	}
+
+	if GradeSections("One paragraph without any headings at all, long enough to grade on its own.", envelope.Results[0], opts) != nil {
+		t.Error("expected no report for a single section")
+	}
+}
+
+func TestSectionsOption(t *testing.T) {
+	result, err := Analyze(context.Background(), sectionsTestDoc, AnalysisOptions{Sections: true}, AnalysisRun{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Sections == nil || len(result.Sections.Sections) != 3 {
+		t.Errorf("sections = %+v", result.Sections)
+	}
+}
@@ -0,0 +1,109 @@
+package analyzer
+
+import (
+	"math"
+	"strings"
+)
+
+// Sentence difficulty bands, from Flesch reading ease
+const (
+	DifficultyEasy     = "easy"      // 70 and up
+	DifficultyStandard = "standard"  // 50-70
+	DifficultyHard     = "hard"      // 30-50
+	DifficultyVeryHard = "very_hard" // Below 30
+)
+
+// longSentenceWords is the length past which a sentence counts as long
+const longSentenceWords = 25
+
+// sentenceComplexityIndicators are the clause markers behind sentence
+// complexity, with the names reported for each sentence
+var sentenceComplexityIndicators = []struct{ marker, name string }{
+	{",", "comma"},
+	{";", "semicolon"},
+	{":", "colon"},
+	{" and ", "and"},
+	{" or ", "or"},
+	{" because ", "because"},
+	{" although ", "although"},
+	{" however ", "however"},
+}
+
+// SentenceReadability scores one sentence on its own, so a UI can shade the
+// text as a heatmap of where it gets hard to read
+type SentenceReadability struct {
+	Index             int      `json:"index"`
+	Start             int      `json:"start"` // Byte offsets in the original text
+	End               int      `json:"end"`
+	Words             int      `json:"words"`
+	Syllables         int      `json:"syllables"`
+	ComplexWords      int      `json:"complex_words"`       // Words of 3+ syllables
+	FleschReadingEase float64  `json:"flesch_reading_ease"` // Higher is easier
+	GradeLevel        float64  `json:"grade_level"`         // Flesch-Kincaid
+	Complexity        int      `json:"complexity"`          // 1 plus one per indicator, as in sentence_complexity_average
+	Indicators        []string `json:"indicators"`          // Clause markers found, plus "long" past 25 words
+	Difficulty        string   `json:"difficulty"`          // One of the Difficulty constants
+	Heat              float64  `json:"heat"`                // 0 (easiest) to 1 (hardest), for coloring
+}
+
+// AnalyzeSentenceReadability scores every sentence of text in order
+func AnalyzeSentenceReadability(text string) []SentenceReadability {
+	out := []SentenceReadability{}
+	for i, span := range locateSentences(text) {
+		words := extractWords(span.Text)
+		if len(words) == 0 {
+			continue
+		}
+		syllables := calculateTotalSyllables(words)
+		wordsPerSentence := float64(len(words))
+		syllablesPerWord := float64(syllables) / float64(len(words))
+		ease := 206.835 - 1.015*wordsPerSentence - 84.6*syllablesPerWord
+		indicators := complexityIndicators(span.Text)
+		complexity := 1 + len(indicators)
+		if len(words) > longSentenceWords {
+			indicators = append(indicators, "long")
+		}
+
+		out = append(out, SentenceReadability{
+			Index:             i,
+			Start:             span.Start,
+			End:               span.End,
+			Words:             len(words),
+			Syllables:         syllables,
+			ComplexWords:      countComplexWords(words),
+			FleschReadingEase: math.Round(ease*10) / 10,
+			GradeLevel:        math.Round((0.39*wordsPerSentence+11.8*syllablesPerWord-15.59)*10) / 10,
+			Complexity:        complexity,
+			Indicators:        indicators,
+			Difficulty:        sentenceDifficulty(ease),
+			Heat:              math.Round(clamp((100-ease)/100, 0, 1)*100) / 100,
+		})
+	}
+	return out
+}
+
+// complexityIndicators names the clause markers in a sentence
+func complexityIndicators(sentence string) []string {
+	found := []string{}
+	ls := " " + strings.ToLower(sentence) + " "
+	for _, ind := range sentenceComplexityIndicators {
+		if strings.Contains(ls, ind.marker) {
+			found = append(found, ind.name)
+		}
+	}
+	return found
+}
+
+// sentenceDifficulty bands a Flesch reading ease score
+func sentenceDifficulty(ease float64) string {
+	switch {
+	case ease >= 70:
+		return DifficultyEasy
+	case ease >= 50:
+		return DifficultyStandard
+	case ease >= 30:
+		return DifficultyHard
+	default:
+		return DifficultyVeryHard
+	}
+}
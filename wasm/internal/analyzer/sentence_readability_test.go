@@ -0,0 +1,33 @@
+package analyzer
+
+import "testing"
+
+func TestAnalyzeSentenceReadability(t *testing.T) {
+	text := "Fix the bug. The organizational infrastructure modernization initiative necessitates comprehensive interdepartmental coordination, considerable documentation, and substantial administrative reconfiguration because regulatory requirements are continuously evolving across multiple jurisdictions worldwide today."
+	got := AnalyzeSentenceReadability(text)
+	if len(got) != 2 {
+		t.Fatalf("expected 2 sentences, got %+v", got)
+	}
+	easy, hard := got[0], got[1]
+	if text[easy.Start:easy.End] != "Fix the bug" || hard.End != len(text) || hard.Index != 1 {
+		t.Errorf("offsets = [%d,%d) and [%d,%d)", easy.Start, easy.End, hard.Start, hard.End)
+	}
+	if easy.Difficulty != DifficultyEasy || hard.Difficulty != DifficultyVeryHard {
+		t.Errorf("difficulty = %s, %s", easy.Difficulty, hard.Difficulty)
+	}
+	if easy.Heat >= hard.Heat || hard.Heat != 1 || hard.GradeLevel <= easy.GradeLevel {
+		t.Errorf("heat %.2f vs %.2f, grade %.1f vs %.1f", easy.Heat, hard.Heat, easy.GradeLevel, hard.GradeLevel)
+	}
+	want := []string{"comma", "and", "because", "long"}
+	if len(hard.Indicators) != len(want) || hard.Complexity != 4 {
+		t.Fatalf("indicators = %v, complexity %d", hard.Indicators, hard.Complexity)
+	}
+	for i, name := range want {
+		if hard.Indicators[i] != name {
+			t.Errorf("indicator %d = %s, want %s", i, hard.Indicators[i], name)
+		}
+	}
+	if len(AnalyzeSentenceReadability("   ")) != 0 {
+		t.Error("blank text produced sentences")
+	}
+}
@@ -0,0 +1,1021 @@
+//go:build !js
+
+package analyzer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// ServerConfig bounds request size and duration for a server build
+type ServerConfig struct {
+	Addr            string        `json:"addr"`
+	MaxBodyBytes    int64         `json:"max_body_bytes"`
+	ReadTimeout     time.Duration `json:"read_timeout"`
+	WriteTimeout    time.Duration `json:"write_timeout"`
+	RequestTimeout  time.Duration `json:"request_timeout"`  // Deadline handed to the analyzer
+	ShutdownTimeout time.Duration `json:"shutdown_timeout"` // Grace period for in-flight requests
+	// Webhooks is notified when batches finish and analyses fail the quality
+	// gate; nil sends nothing
+	Webhooks *WebhookNotifier `json:"-"`
+	// Model answers /evaluate requests that carry only a prompt; nil
+	// requires every request to include the response
+	Model *LLMRewriter `json:"-"`
+	// Defaults fill the analysis options each request leaves unset
+	Defaults AnalysisOptions `json:"-"`
+}
+
+// DefaultServerConfig returns limits suited to interactive prompt analysis
+func DefaultServerConfig() ServerConfig {
+	return ServerConfig{
+		Addr:            ":8080",
+		MaxBodyBytes:    1 << 20,
+		ReadTimeout:     10 * time.Second,
+		WriteTimeout:    35 * time.Second,
+		RequestTimeout:  30 * time.Second,
+		ShutdownTimeout: 15 * time.Second,
+	}
+}
+
+// AnalyzeFunc runs an analysis and should return promptly once ctx is done
+type AnalyzeFunc func(ctx context.Context, req AnalyzeRequest) (interface{}, error)
+
+// FullAnalysis is the AnalyzeFunc for server builds. It runs the same pipeline as
+// the WASM module, returning the full CombinedResult, with one worker per CPU
+// unless RuntimeConfig.Workers is set.
+// Stage latencies are recorded in metrics when it is non-nil.
+func FullAnalysis(metrics *StageMetrics) AnalyzeFunc {
+	return RecordedAnalysis(metrics, nil)
+}
+
+// AnalysisRecorder keeps a finished analysis, e.g. for /history; req carries
+// the text and tags it was submitted with
+type AnalysisRecorder func(ctx context.Context, req AnalyzeRequest, result *CombinedResult)
+
+// RecordedAnalysis is FullAnalysis handing each result to record, when it
+// is non-nil, before the result is cut down to the requested fields
+func RecordedAnalysis(metrics *StageMetrics, record AnalysisRecorder) AnalyzeFunc {
+	return func(ctx context.Context, req AnalyzeRequest) (interface{}, error) {
+		result, err := Analyze(ctx, req.Text, req.Options, AnalysisRun{Workers: serverWorkers()})
+		if err != nil {
+			return nil, err
+		}
+		if record != nil {
+			record(ctx, req, result)
+		}
+		b, err := MarshalResult(result, req.Options)
+		if metrics != nil {
+			metrics.Observe(&result.Performance)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return json.RawMessage(b), nil
+	}
+}
+
+// QueuedAnalysis runs fn as a job on queue, a pool shared by every request, so
+// interactive requests and batch items compete for workers by priority. A full
+// queue holds the request until a slot frees or its deadline passes.
+func QueuedAnalysis(queue *WorkerPool, priority Priority, fn AnalyzeFunc) AnalyzeFunc {
+	type outcome struct {
+		result interface{}
+		err    error
+	}
+	return func(ctx context.Context, req AnalyzeRequest) (interface{}, error) {
+		done := make(chan outcome, 1)
+		err := queue.SubmitContext(ctx, priority, func() {
+			result, err := fn(ctx, req)
+			done <- outcome{result, err}
+		})
+		if err != nil {
+			return nil, err
+		}
+		select {
+		case o := <-done:
+			return o.result, o.err
+		case <-ctx.Done():
+			// A job still queued sees the canceled context and returns at once
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// serverWorkers is RuntimeConfig.Workers, or one worker per CPU when unset
+func serverWorkers() int {
+	if n := CurrentRuntimeConfig().Workers; n > 0 {
+		return n
+	}
+	return runtime.NumCPU()
+}
+
+// AnalyzeHandler reads an AnalyzeRequest (or, without a JSON content type, the
+// raw prompt text) and responds with fn's result as JSON. Bodies over
+// MaxBodyBytes get 413, and analyses that outlive RequestTimeout get 503 with
+// the request's context canceled.
+func AnalyzeHandler(cfg ServerConfig, fn AnalyzeFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", "POST")
+			writeAPIError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		req, ok := readAnalyzeRequest(w, r, cfg)
+		if !ok {
+			return
+		}
+
+		ctx := r.Context()
+		if cfg.RequestTimeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, cfg.RequestTimeout)
+			defer cancel()
+		}
+
+		result, err := fn(ctx, req)
+		switch {
+		case errors.Is(err, context.DeadlineExceeded):
+			writeAPIError(w, http.StatusServiceUnavailable, "analysis exceeded "+cfg.RequestTimeout.String())
+			return
+		case errors.Is(err, context.Canceled):
+			// The client went away; nobody is left to read a response
+			return
+		case err != nil:
+			writeAPIError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		cfg.Webhooks.AnalysisCompleted(req, result)
+		if NegotiateResponse(r) == ResponseSSE {
+			writeSSESignals(w, map[string]interface{}{"result": result})
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(result)
+	})
+}
+
+// BatchHandler analyzes every item of a BatchRequest with fn and responds with
+// a BatchResponse in item order. Items run as batch jobs on queue, so a large
+// batch waits behind interactive requests instead of starving them. Invalid
+// options and failed analyses are reported per item.
+func BatchHandler(cfg ServerConfig, queue *WorkerPool, fn AnalyzeFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", "POST")
+			writeAPIError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		if cfg.MaxBodyBytes > 0 {
+			r.Body = http.MaxBytesReader(w, r.Body, cfg.MaxBodyBytes)
+		}
+		var batch BatchRequest
+		if err := json.NewDecoder(r.Body).Decode(&batch); err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				writeAPIError(w, http.StatusRequestEntityTooLarge, "request body exceeds "+formatBytes(cfg.MaxBodyBytes))
+				return
+			}
+			writeAPIError(w, http.StatusBadRequest, "invalid request: "+err.Error())
+			return
+		}
+
+		ctx := r.Context()
+		if cfg.RequestTimeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, cfg.RequestTimeout)
+			defer cancel()
+		}
+		analyze := QueuedAnalysis(queue, PriorityBatch, fn)
+		resp := BatchResponse{Results: make([]BatchItem, len(batch.Items))}
+		var wg sync.WaitGroup
+		for i, item := range batch.Items {
+			resp.Results[i].Index = i
+			item.Options = item.Options.WithDefaults(cfg.Defaults)
+			if err := item.Options.Validate(); err != nil {
+				resp.Results[i].Error = err.Error()
+				continue
+			}
+			wg.Add(1)
+			go func(out *BatchItem, item AnalyzeRequest) {
+				defer wg.Done()
+				result, err := analyze(ctx, item)
+				if err == nil {
+					out.Result, err = rawJSON(result)
+				}
+				if err != nil {
+					out.Error = err.Error()
+				}
+			}(&resp.Results[i], item)
+		}
+		wg.Wait()
+		cfg.Webhooks.BatchCompleted(batch, resp)
+		if errors.Is(ctx.Err(), context.Canceled) {
+			// The client went away; nobody is left to read a response
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	})
+}
+
+// rawJSON passes through results FullAnalysis already encoded and marshals the rest
+func rawJSON(result interface{}) (json.RawMessage, error) {
+	if raw, ok := result.(json.RawMessage); ok {
+		return raw, nil
+	}
+	return json.Marshal(result)
+}
+
+// readAnalyzeRequest reads an AnalyzeRequest, or without a JSON content type the
+// raw prompt text, writing an error response and returning false on failure
+func readAnalyzeRequest(w http.ResponseWriter, r *http.Request, cfg ServerConfig) (AnalyzeRequest, bool) {
+	if cfg.MaxBodyBytes > 0 {
+		r.Body = http.MaxBytesReader(w, r.Body, cfg.MaxBodyBytes)
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeAPIError(w, http.StatusRequestEntityTooLarge, "request body exceeds "+formatBytes(cfg.MaxBodyBytes))
+			return AnalyzeRequest{}, false
+		}
+		writeAPIError(w, http.StatusBadRequest, "failed to read request body")
+		return AnalyzeRequest{}, false
+	}
+
+	req := AnalyzeRequest{Text: string(body)}
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		req = AnalyzeRequest{}
+		if err := json.Unmarshal(body, &req); err != nil {
+			writeAPIError(w, http.StatusBadRequest, "invalid request: "+err.Error())
+			return AnalyzeRequest{}, false
+		}
+	}
+	if err := applyOutputQuery(&req.Options, r.URL.Query()); err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return AnalyzeRequest{}, false
+	}
+	req.Options = req.Options.WithDefaults(cfg.Defaults)
+	if err := req.Options.Validate(); err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return AnalyzeRequest{}, false
+	}
+	req.Tags = req.Tags.Normalize()
+	if err := req.Tags.Validate(); err != nil {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return AnalyzeRequest{}, false
+	}
+	return req, true
+}
+
+// applyOutputQuery lets ?fields=prompt_grade,insights, ?max_cluster_sentences=
+// and ?max_suggestion_examples= shape the response, overriding the body's options,
+// so plain text requests can trim huge results too
+func applyOutputQuery(opts *AnalysisOptions, query url.Values) error {
+	if fields := query.Get("fields"); fields != "" {
+		opts.Fields = nil
+		for _, f := range strings.Split(fields, ",") {
+			if f = strings.TrimSpace(f); f != "" {
+				opts.Fields = append(opts.Fields, f)
+			}
+		}
+	}
+	limits := map[string]*int{
+		"max_cluster_sentences":   &opts.MaxClusterSentences,
+		"max_suggestion_examples": &opts.MaxSuggestionExamples,
+	}
+	for _, name := range sortedKeys(limits) {
+		v := query.Get(name)
+		if v == "" {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("%s must be a whole number, got %q", name, v)
+		}
+		*limits[name] = n
+	}
+	return nil
+}
+
+// ReportHandler analyzes a prompt like AnalyzeHandler and responds with a
+// rendered report. ?format=markdown, ?format=pdf or ?format=html (the default)
+// picks the renderer and ?title= sets the heading.
+func ReportHandler(cfg ServerConfig) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", "POST")
+			writeAPIError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		format := strings.ToLower(r.URL.Query().Get("format"))
+		if format == "" {
+			format = ReportHTML
+		}
+		contentType, known := ReportContentTypes[format]
+		if !known {
+			writeAPIError(w, http.StatusBadRequest, fmt.Sprintf("unknown report format %q", format))
+			return
+		}
+		req, ok := readAnalyzeRequest(w, r, cfg)
+		if !ok {
+			return
+		}
+
+		ctx := r.Context()
+		if cfg.RequestTimeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, cfg.RequestTimeout)
+			defer cancel()
+		}
+		result, err := Analyze(ctx, req.Text, req.Options, AnalysisRun{Workers: serverWorkers()})
+		switch {
+		case errors.Is(err, context.DeadlineExceeded):
+			writeAPIError(w, http.StatusServiceUnavailable, "analysis exceeded "+cfg.RequestTimeout.String())
+			return
+		case errors.Is(err, context.Canceled):
+			return
+		case err != nil:
+			writeAPIError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		var buf bytes.Buffer
+		if err := RenderReport(&buf, result, format, r.URL.Query().Get("title")); err != nil {
+			writeAPIError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.Write(buf.Bytes())
+	})
+}
+
+// WordCloudHandler analyzes a prompt like AnalyzeHandler and responds with
+// its WordCloud. ?top= sets how many terms to list.
+func WordCloudHandler(cfg ServerConfig) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", "POST")
+			writeAPIError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		top := 0
+		if v := r.URL.Query().Get("top"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 || n > MaxWordCloudTerms {
+				writeAPIError(w, http.StatusBadRequest, fmt.Sprintf("top must be a whole number from 1 to %d, got %q", MaxWordCloudTerms, v))
+				return
+			}
+			top = n
+		}
+		req, ok := readAnalyzeRequest(w, r, cfg)
+		if !ok {
+			return
+		}
+
+		ctx := r.Context()
+		if cfg.RequestTimeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, cfg.RequestTimeout)
+			defer cancel()
+		}
+		cloud, err := BuildWordCloud(ctx, req.Text, req.Options, top)
+		switch {
+		case errors.Is(err, context.DeadlineExceeded):
+			writeAPIError(w, http.StatusServiceUnavailable, "analysis exceeded "+cfg.RequestTimeout.String())
+			return
+		case errors.Is(err, context.Canceled):
+			return
+		case err != nil:
+			writeAPIError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(cloud)
+	})
+}
+
+// HistoryHandler serves GET /history, parsing the page and tag filter and
+// handing them to list
+func HistoryHandler(list HistoryLister) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", "GET")
+			writeAPIError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		q, err := ParseHistoryQuery(r.URL.Query())
+		if err != nil {
+			writeAPIError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		resp, err := list(r.Context(), q)
+		if err != nil {
+			writeAPIError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	})
+}
+
+// AnalysisSearchHandler serves GET /analyses/{id}/search?q=, searching the
+// stored analysis load returns for clusters, tasks and suggestions that
+// mention q
+func AnalysisSearchHandler(load AnalysisLoader) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", "GET")
+			writeAPIError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		id, ok := strings.CutPrefix(r.URL.Path, "/analyses/")
+		if ok {
+			id, ok = strings.CutSuffix(id, "/search")
+		}
+		if !ok || id == "" || strings.Contains(id, "/") {
+			writeAPIError(w, http.StatusNotFound, "not found")
+			return
+		}
+		query := r.URL.Query().Get("q")
+		if err := validateSearchQuery(query); err != nil {
+			writeAPIError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		result, err := load(r.Context(), id)
+		switch {
+		case errors.Is(err, ErrAnalysisNotFound):
+			writeAPIError(w, http.StatusNotFound, fmt.Sprintf("analysis %q not found", id))
+			return
+		case err != nil:
+			writeAPIError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(SearchAnalysis(result, query))
+	})
+}
+
+// NegotiateResponse picks the response encoding. ?format=json or ?format=sse wins;
+// otherwise an Accept header asking for text/event-stream (and not
+// application/json) selects SSE, and everything else gets JSON.
+func NegotiateResponse(r *http.Request) string {
+	switch strings.ToLower(r.URL.Query().Get("format")) {
+	case ResponseJSON:
+		return ResponseJSON
+	case ResponseSSE:
+		return ResponseSSE
+	}
+	accept := r.Header.Get("Accept")
+	if strings.Contains(accept, "text/event-stream") && !strings.Contains(accept, "application/json") {
+		return ResponseSSE
+	}
+	return ResponseJSON
+}
+
+// writeSSESignals sends signals as a single DataStar patch-signals event
+func writeSSESignals(w http.ResponseWriter, signals interface{}) {
+	data, err := json.Marshal(signals)
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	fmt.Fprintf(w, "event: datastar-patch-signals\ndata: signals %s\n\n", data)
+	if f, ok := w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// writeAPIError responds with an APIError body
+func writeAPIError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(APIError{Error: message})
+}
+
+// NewServer builds an http.Server with the configured timeouts, gzipping
+// responses for clients that accept it
+func NewServer(cfg ServerConfig, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              cfg.Addr,
+		Handler:           CompressHandler(handler),
+		ReadTimeout:       cfg.ReadTimeout,
+		ReadHeaderTimeout: cfg.ReadTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+	}
+}
+
+// ListenAndServe serves until SIGINT or SIGTERM, then stops accepting connections
+// and waits up to ShutdownTimeout for in-flight requests to finish
+func ListenAndServe(cfg ServerConfig, handler http.Handler) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return Serve(ctx, NewServer(cfg, handler), cfg.ShutdownTimeout)
+}
+
+// Serve runs srv until ctx is done and then shuts it down gracefully
+func Serve(ctx context.Context, srv *http.Server, shutdownTimeout time.Duration) error {
+	errCh := make(chan error, 1)
+	go func() { errCh <- srv.ListenAndServe() }()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	if err := <-errCh; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// OpenAPIHandler serves the API description at e.g. /openapi.json
+func OpenAPIHandler(result interface{}) http.Handler {
+	spec, err := json.Marshal(OpenAPISpec(result))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(spec)
+	})
+}
+
+// SchemaHandler serves the JSON Schema of result, the analysis result type,
+// at e.g. /schema so clients can validate against the version they expect
+func SchemaHandler(result interface{}) http.Handler {
+	schema, err := json.Marshal(GenerateJSONSchema(result, "Fulcrum analysis result"))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/schema+json")
+		w.Write(schema)
+	})
+}
+
+// CorpusHandler analyzes a prompt library posted as a CorpusRequest, a bare
+// JSON array of CorpusDocument, or a zip archive (Content-Type
+// application/zip) with its AnalysisOptions as JSON in ?options=, and
+// responds with the CorpusReport
+func CorpusHandler(cfg ServerConfig) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", "POST")
+			writeAPIError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		if cfg.MaxBodyBytes > 0 {
+			r.Body = http.MaxBytesReader(w, r.Body, cfg.MaxBodyBytes)
+		}
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				writeAPIError(w, http.StatusRequestEntityTooLarge, "request body exceeds "+formatBytes(cfg.MaxBodyBytes))
+				return
+			}
+			writeAPIError(w, http.StatusBadRequest, "failed to read request body")
+			return
+		}
+
+		var req CorpusRequest
+		switch {
+		case strings.HasPrefix(r.Header.Get("Content-Type"), "application/zip"):
+			// Allow the archive to expand to ten times the body cap
+			req.Documents, err = LoadCorpusZip(bytes.NewReader(body), int64(len(body)), cfg.MaxBodyBytes*10)
+			if err == nil {
+				req.Options, err = ParseAnalysisOptions([]byte(r.URL.Query().Get("options")))
+			}
+		case bytes.HasPrefix(bytes.TrimSpace(body), []byte("[")):
+			err = json.Unmarshal(body, &req.Documents)
+		default:
+			err = json.Unmarshal(body, &req)
+		}
+		if err == nil {
+			req.Options = req.Options.WithDefaults(cfg.Defaults)
+			err = req.Options.Validate()
+		}
+		if err != nil {
+			writeAPIError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		ctx := r.Context()
+		if cfg.RequestTimeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, cfg.RequestTimeout)
+			defer cancel()
+		}
+		report, err := AnalyzeCorpus(ctx, req.Documents, req.Options)
+		switch {
+		case errors.Is(err, context.DeadlineExceeded):
+			writeAPIError(w, http.StatusServiceUnavailable, "corpus analysis exceeded "+cfg.RequestTimeout.String())
+			return
+		case errors.Is(err, context.Canceled):
+			// The client went away; nobody is left to read a response
+			return
+		case err != nil:
+			writeAPIError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(report)
+	})
+}
+
+// PRReviewHandler grades the prompt files of a posted PRReviewRequest and
+// responds with a PRReviewResponse, or with just the comment markdown for
+// ?format=markdown
+func PRReviewHandler(cfg ServerConfig) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", "POST")
+			writeAPIError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		if cfg.MaxBodyBytes > 0 {
+			r.Body = http.MaxBytesReader(w, r.Body, cfg.MaxBodyBytes)
+		}
+		var req PRReviewRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				writeAPIError(w, http.StatusRequestEntityTooLarge, "request body exceeds "+formatBytes(cfg.MaxBodyBytes))
+				return
+			}
+			writeAPIError(w, http.StatusBadRequest, "invalid request: "+err.Error())
+			return
+		}
+		if req.MinScore < 0 || req.MinScore > 100 {
+			writeAPIError(w, http.StatusBadRequest, "min_score must be between 0 and 100")
+			return
+		}
+		req.Options = req.Options.WithDefaults(cfg.Defaults)
+		if err := req.Options.Validate(); err != nil {
+			writeAPIError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		ctx := r.Context()
+		if cfg.RequestTimeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, cfg.RequestTimeout)
+			defer cancel()
+		}
+		review, err := ReviewPullRequest(ctx, req)
+		switch {
+		case errors.Is(err, context.DeadlineExceeded):
+			writeAPIError(w, http.StatusServiceUnavailable, "review exceeded "+cfg.RequestTimeout.String())
+			return
+		case errors.Is(err, context.Canceled):
+			// The client went away; nobody is left to read a response
+			return
+		case err != nil:
+			writeAPIError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		markdown := RenderPRComment(review)
+		if strings.ToLower(r.URL.Query().Get("format")) == ReportMarkdown {
+			w.Header().Set("Content-Type", ReportContentTypes[ReportMarkdown])
+			io.WriteString(w, markdown)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(PRReviewResponse{Review: review, Markdown: markdown})
+	})
+}
+
+// CompareHandler serves POST /compare, grading the original and revised
+// versions of a prompt (CompareRequest) side by side
+func CompareHandler(cfg ServerConfig) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", "POST")
+			writeAPIError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		if cfg.MaxBodyBytes > 0 {
+			r.Body = http.MaxBytesReader(w, r.Body, cfg.MaxBodyBytes)
+		}
+		var req CompareRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				writeAPIError(w, http.StatusRequestEntityTooLarge, "request body exceeds "+formatBytes(cfg.MaxBodyBytes))
+				return
+			}
+			writeAPIError(w, http.StatusBadRequest, "invalid request: "+err.Error())
+			return
+		}
+		if strings.TrimSpace(req.Original) == "" || strings.TrimSpace(req.Revised) == "" {
+			writeAPIError(w, http.StatusBadRequest, "original and revised are required")
+			return
+		}
+		req.Options = req.Options.WithDefaults(cfg.Defaults)
+		if err := req.Options.Validate(); err != nil {
+			writeAPIError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(ComparePrompts(req.Original, req.Revised, req.Options))
+	})
+}
+
+// EvaluateHandler serves POST /evaluate, scoring an LLM response against its
+// prompt. Requests without a response are answered by cfg.Model first.
+func EvaluateHandler(cfg ServerConfig) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", "POST")
+			writeAPIError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		if cfg.MaxBodyBytes > 0 {
+			r.Body = http.MaxBytesReader(w, r.Body, cfg.MaxBodyBytes)
+		}
+		var req EvaluateRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				writeAPIError(w, http.StatusRequestEntityTooLarge, "request body exceeds "+formatBytes(cfg.MaxBodyBytes))
+				return
+			}
+			writeAPIError(w, http.StatusBadRequest, "invalid request: "+err.Error())
+			return
+		}
+		if strings.TrimSpace(req.Prompt) == "" {
+			writeAPIError(w, http.StatusBadRequest, "prompt is required")
+			return
+		}
+		if req.Response != "" {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(EvaluationResponse{Response: req.Response, Evaluation: EvaluateResponse(req.Prompt, req.Response)})
+			return
+		}
+		if cfg.Model == nil {
+			writeAPIError(w, http.StatusBadRequest, "response is required: no model is configured to answer the prompt")
+			return
+		}
+
+		ctx := r.Context()
+		if cfg.RequestTimeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, cfg.RequestTimeout)
+			defer cancel()
+		}
+		reply, eval, err := cfg.Model.EvaluateModel(ctx, req.Prompt)
+		switch {
+		case errors.Is(err, context.DeadlineExceeded):
+			writeAPIError(w, http.StatusServiceUnavailable, "model exceeded "+cfg.RequestTimeout.String())
+			return
+		case errors.Is(err, context.Canceled):
+			return
+		case err != nil:
+			writeAPIError(w, http.StatusBadGateway, err.Error())
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(EvaluationResponse{Response: reply, Evaluation: eval})
+	})
+}
+
+// WhatIfHandler serves POST /what-if, predicting the grade a prompt would earn
+// after the proposed changes (WhatIfRequest)
+func WhatIfHandler(cfg ServerConfig) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", "POST")
+			writeAPIError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		if cfg.MaxBodyBytes > 0 {
+			r.Body = http.MaxBytesReader(w, r.Body, cfg.MaxBodyBytes)
+		}
+		var req WhatIfRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				writeAPIError(w, http.StatusRequestEntityTooLarge, "request body exceeds "+formatBytes(cfg.MaxBodyBytes))
+				return
+			}
+			writeAPIError(w, http.StatusBadRequest, "invalid request: "+err.Error())
+			return
+		}
+		req.Options = req.Options.WithDefaults(cfg.Defaults)
+		result, err := SimulateChanges(req)
+		if err != nil {
+			writeAPIError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(result)
+	})
+}
+
+// TrainClassifierHandler trains prompt categories from a posted JSON array of
+// ClassifierExample and responds with the ClassifierModel, ready to store as
+// analysis.classifier in the config
+func TrainClassifierHandler(cfg ServerConfig) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", "POST")
+			writeAPIError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		if cfg.MaxBodyBytes > 0 {
+			r.Body = http.MaxBytesReader(w, r.Body, cfg.MaxBodyBytes)
+		}
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				writeAPIError(w, http.StatusRequestEntityTooLarge, "request body exceeds "+formatBytes(cfg.MaxBodyBytes))
+				return
+			}
+			writeAPIError(w, http.StatusBadRequest, "failed to read request body")
+			return
+		}
+
+		examples, err := LoadClassifierExamples(body)
+		if err != nil {
+			writeAPIError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		model, err := TrainClassifier(examples)
+		if err != nil {
+			writeAPIError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(model)
+	})
+}
+
+// ExperimentsHandler serves the experiment API on exps:
+//
+//	GET  /experiments                 list experiments
+//	POST /experiments                 register variants (CreateExperimentRequest)
+//	GET  /experiments/report          correlations pooled across experiments
+//	GET  /experiments/weights         dimension weights learned from ?metric= outcomes
+//	GET  /experiments/{id}            one experiment
+//	POST /experiments/{id}/outcomes   record outcome metrics (OutcomesRequest)
+//	GET  /experiments/{id}/report     per-variant results and correlations
+func ExperimentsHandler(cfg ServerConfig, exps *Experiments) http.Handler {
+	// decode reads a JSON body into v, writing the error response on failure
+	decode := func(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+		if cfg.MaxBodyBytes > 0 {
+			r.Body = http.MaxBytesReader(w, r.Body, cfg.MaxBodyBytes)
+		}
+		if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				writeAPIError(w, http.StatusRequestEntityTooLarge, "request body exceeds "+formatBytes(cfg.MaxBodyBytes))
+				return false
+			}
+			writeAPIError(w, http.StatusBadRequest, "invalid request: "+err.Error())
+			return false
+		}
+		return true
+	}
+	// allow rejects methods other than method
+	allow := func(w http.ResponseWriter, r *http.Request, method string) bool {
+		if r.Method != method {
+			w.Header().Set("Allow", method)
+			writeAPIError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return false
+		}
+		return true
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rest, ok := strings.CutPrefix(r.URL.Path, "/experiments")
+		if !ok || rest != "" && !strings.HasPrefix(rest, "/") {
+			writeAPIError(w, http.StatusNotFound, "not found")
+			return
+		}
+		rest = strings.Trim(rest, "/")
+		id, action, _ := strings.Cut(rest, "/")
+
+		var body interface{}
+		status := http.StatusOK
+		var err error
+		switch {
+		case rest == "":
+			switch r.Method {
+			case http.MethodGet:
+				body = exps.List()
+			case http.MethodPost:
+				var req CreateExperimentRequest
+				if !decode(w, r, &req) {
+					return
+				}
+				if body, err = exps.Create(req); err != nil {
+					writeAPIError(w, http.StatusBadRequest, err.Error())
+					return
+				}
+				status = http.StatusCreated
+			default:
+				w.Header().Set("Allow", "GET, POST")
+				writeAPIError(w, http.StatusMethodNotAllowed, "method not allowed")
+				return
+			}
+		case rest == "report":
+			if !allow(w, r, http.MethodGet) {
+				return
+			}
+			body = exps.PooledReport()
+		case rest == "weights":
+			if !allow(w, r, http.MethodGet) {
+				return
+			}
+			query := r.URL.Query()
+			metric := query.Get("metric")
+			if metric == "" {
+				writeAPIError(w, http.StatusBadRequest, "metric is required")
+				return
+			}
+			var opts WeightLearningOptions
+			if v := query.Get("min_samples"); v != "" {
+				if opts.MinSamples, err = strconv.Atoi(v); err != nil || opts.MinSamples < 2 {
+					writeAPIError(w, http.StatusBadRequest, "min_samples must be an integer of at least 2")
+					return
+				}
+			}
+			if body, err = exps.LearnWeights(metric, opts); err != nil {
+				writeAPIError(w, http.StatusUnprocessableEntity, err.Error())
+				return
+			}
+		case action == "":
+			if !allow(w, r, http.MethodGet) {
+				return
+			}
+			body, err = exps.Get(id)
+		case action == "outcomes":
+			if !allow(w, r, http.MethodPost) {
+				return
+			}
+			var req OutcomesRequest
+			if !decode(w, r, &req) {
+				return
+			}
+			if err = exps.Record(id, req.Outcomes); err == nil {
+				body, err = exps.Get(id)
+				status = http.StatusCreated
+			} else if !errors.Is(err, ErrExperimentNotFound) {
+				writeAPIError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+		case action == "report":
+			if !allow(w, r, http.MethodGet) {
+				return
+			}
+			body, err = exps.Report(id)
+		default:
+			writeAPIError(w, http.StatusNotFound, "not found")
+			return
+		}
+		if errors.Is(err, ErrExperimentNotFound) {
+			writeAPIError(w, http.StatusNotFound, fmt.Sprintf("experiment %q not found", id))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(body)
+	})
+}
@@ -0,0 +1,659 @@
+//go:build !js
+
+package analyzer
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestAnalyzeHandlerLimits checks the body cap and that the deadline reaches the analyzer
+func TestAnalyzeHandlerLimits(t *testing.T) {
+	cfg := DefaultServerConfig()
+	cfg.MaxBodyBytes = 16
+	cfg.RequestTimeout = 10 * time.Millisecond
+
+	handler := AnalyzeHandler(cfg, func(ctx context.Context, req AnalyzeRequest) (interface{}, error) {
+		if req.Text == "slow" {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+		return map[string]int{"length": len(req.Text)}, nil
+	})
+
+	cases := []struct {
+		body string
+		want int
+	}{
+		{"hello", http.StatusOK},
+		{strings.Repeat("x", 17), http.StatusRequestEntityTooLarge},
+		{"slow", http.StatusServiceUnavailable},
+	}
+	for _, c := range cases {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/analyze", strings.NewReader(c.body)))
+		if rec.Code != c.want {
+			t.Errorf("body %q: expected status %d, got %d", c.body, c.want, rec.Code)
+		}
+	}
+}
+
+// TestOpenAPISpecRefsResolve checks every endpoint is described and every $ref has a schema
+func TestOpenAPISpecRefsResolve(t *testing.T) {
+	spec := OpenAPISpec(PromptGrade{})
+	paths := spec["paths"].(map[string]interface{})
+	for _, path := range []string{"/analyze", "/report", "/batch", "/compare", "/history", "/wordcloud", "/pr-review", "/evaluate", "/what-if", "/experiments", "/experiments/{id}/outcomes", "/experiments/{id}/report", "/experiments/weights", "/analyses/{id}/search", "/metrics", "/health", "/health/ready", "/admin/config"} {
+		if _, ok := paths[path]; !ok {
+			t.Errorf("expected %s in spec paths", path)
+		}
+	}
+
+	data, err := json.Marshal(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	schemas := spec["components"].(map[string]interface{})["schemas"].(map[string]interface{})
+	for _, m := range regexp.MustCompile(`"\$ref":"#/components/schemas/(\w+)"`).FindAllStringSubmatch(string(data), -1) {
+		if _, ok := schemas[m[1]]; !ok {
+			t.Errorf("unresolved $ref %s", m[1])
+		}
+	}
+}
+
+func TestNegotiateResponse(t *testing.T) {
+	cases := []struct {
+		target, accept, want string
+	}{
+		{"/analyze", "", ResponseJSON},
+		{"/analyze", "application/json", ResponseJSON},
+		{"/analyze", "text/event-stream", ResponseSSE},
+		{"/analyze?format=json", "text/event-stream", ResponseJSON},
+		{"/analyze?format=sse", "application/json", ResponseSSE},
+	}
+	for _, c := range cases {
+		r := httptest.NewRequest(http.MethodPost, c.target, nil)
+		r.Header.Set("Accept", c.accept)
+		if got := NegotiateResponse(r); got != c.want {
+			t.Errorf("%s with Accept %q: expected %s, got %s", c.target, c.accept, c.want, got)
+		}
+	}
+}
+
+// TestFullAnalysisParity checks the server returns every section of the WASM result
+func TestFullAnalysisParity(t *testing.T) {
+	metrics := NewStageMetrics()
+	handler := AnalyzeHandler(DefaultServerConfig(), FullAnalysis(metrics))
+	body := `{"text":"Build a REST API for user accounts. Then write integration tests and deploy it to staging by Friday.", "options": {"grader": "both"}}`
+	r := httptest.NewRequest(http.MethodPost, "/analyze", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, r)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var sections map[string]json.RawMessage
+	if err := json.Unmarshal(rec.Body.Bytes(), &sections); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range append([]string{"schema_version", "performance_metrics"}, mapValues(StageResultKeys)...) {
+		if _, ok := sections[key]; !ok {
+			t.Errorf("expected %q in server result", key)
+		}
+	}
+
+	var sb strings.Builder
+	if err := metrics.WritePrometheus(&sb); err != nil || !strings.Contains(sb.String(), "prompt_grade_calculation") {
+		t.Errorf("expected stage latencies to be recorded, got %v\n%s", err, sb.String())
+	}
+}
+
+func mapValues(m map[string][]string) []string {
+	values := make([]string, 0, len(m))
+	for _, v := range m {
+		values = append(values, v...)
+	}
+	return values
+}
+
+// TestReportHandlerFormats checks each report format's content type and an unknown format
+func TestReportHandlerFormats(t *testing.T) {
+	handler := ReportHandler(DefaultServerConfig())
+	cases := []struct {
+		query       string
+		want        int
+		contentType string
+	}{
+		{"", http.StatusOK, "text/html"},
+		{"?format=markdown&title=Review", http.StatusOK, "text/markdown"},
+		{"?format=pdf", http.StatusOK, "application/pdf"},
+		{"?format=docx", http.StatusBadRequest, "application/json"},
+	}
+	for _, c := range cases {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/report"+c.query, strings.NewReader("Write tests for the API.")))
+		if rec.Code != c.want || !strings.HasPrefix(rec.Header().Get("Content-Type"), c.contentType) {
+			t.Errorf("%q: expected %d %s, got %d %s", c.query, c.want, c.contentType, rec.Code, rec.Header().Get("Content-Type"))
+		}
+	}
+}
+
+// TestWordCloudHandler checks the term limit and its validation
+func TestWordCloudHandler(t *testing.T) {
+	handler := WordCloudHandler(DefaultServerConfig())
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/wordcloud?top=2", strings.NewReader("Review the parser. Test the parser and the lexer.")))
+	var cloud WordCloud
+	if err := json.Unmarshal(rec.Body.Bytes(), &cloud); rec.Code != http.StatusOK || err != nil {
+		t.Fatalf("expected 200 with a word cloud, got %d: %s", rec.Code, rec.Body)
+	}
+	if len(cloud.Terms) != 2 || cloud.Terms[0].Text != "parser" {
+		t.Errorf("terms = %+v", cloud.Terms)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/wordcloud?top=0", strings.NewReader("Review the parser.")))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("top=0: expected 400, got %d", rec.Code)
+	}
+}
+
+// TestEvaluateHandler evaluates a posted response and one from a stub model
+func TestEvaluateHandler(t *testing.T) {
+	model := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{{"message": map[string]string{"content": "- Paris\n- Lyon"}}},
+		})
+	}))
+	defer model.Close()
+	llm, err := NewLLMRewriter(LLMRewriterConfig{Provider: "local", Endpoint: model.URL, Model: "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := DefaultServerConfig()
+	post := func(cfg ServerConfig, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		EvaluateHandler(cfg).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/evaluate", strings.NewReader(body)))
+		return rec
+	}
+	prompt := "List two French cities in bullet points."
+
+	rec := post(cfg, `{"prompt": "`+prompt+`", "response": "Paris and Lyon."}`)
+	var got EvaluationResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); rec.Code != http.StatusOK || err != nil {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	if got.Evaluation.FormatCompliance != 0 {
+		t.Errorf("prose answer met the bullet format: %+v", got.Evaluation.Format)
+	}
+
+	if rec := post(cfg, `{"prompt": "`+prompt+`"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("no response and no model: expected 400, got %d", rec.Code)
+	}
+	cfg.Model = llm
+	rec = post(cfg, `{"prompt": "`+prompt+`"}`)
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); rec.Code != http.StatusOK || err != nil {
+		t.Fatalf("expected 200 from the model, got %d: %s", rec.Code, rec.Body)
+	}
+	if got.Response != "- Paris\n- Lyon" || got.Evaluation.FormatCompliance != 100 {
+		t.Errorf("model evaluation = %+v", got)
+	}
+}
+
+// TestCorpusHandler checks that every body form grades with the request's options
+func TestCorpusHandler(t *testing.T) {
+	handler := CorpusHandler(DefaultServerConfig())
+	post := func(target, contentType string, body io.Reader) (*httptest.ResponseRecorder, CorpusReport) {
+		rec := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, target, body)
+		r.Header.Set("Content-Type", contentType)
+		handler.ServeHTTP(rec, r)
+		var report CorpusReport
+		json.Unmarshal(rec.Body.Bytes(), &report)
+		return rec, report
+	}
+	doc := `{"name": "api.md", "text": "Build a REST API for orders. Add tests for the order API."}`
+
+	rec, report := post("/corpus", "application/json", strings.NewReader(`[`+doc+`]`))
+	if rec.Code != http.StatusOK || len(report.WeakDimensions) != 8 {
+		t.Errorf("array body = %d with %d dimensions: %s", rec.Code, len(report.WeakDimensions), rec.Body)
+	}
+	rec, report = post("/corpus", "application/json", strings.NewReader(`{"documents": [`+doc+`], "options": {"grader": "modern"}}`))
+	if rec.Code != http.StatusOK || len(report.WeakDimensions) != 6 {
+		t.Errorf("modern grader = %d with %d dimensions: %s", rec.Code, len(report.WeakDimensions), rec.Body)
+	}
+
+	var archive bytes.Buffer
+	zw := zip.NewWriter(&archive)
+	f, _ := zw.Create("api.md")
+	io.WriteString(f, "Build a REST API for orders. Add tests for the order API.")
+	zw.Close()
+	rec, report = post("/corpus?options="+url.QueryEscape(`{"grader": "modern"}`), "application/zip", bytes.NewReader(archive.Bytes()))
+	if rec.Code != http.StatusOK || report.DocumentCount != 1 || len(report.WeakDimensions) != 6 {
+		t.Errorf("zip with options = %d: %s", rec.Code, rec.Body)
+	}
+
+	if rec, _ := post("/corpus", "application/json", strings.NewReader(`{"documents": [`+doc+`], "options": {"grader": "fancy"}}`)); rec.Code != http.StatusBadRequest {
+		t.Errorf("unknown grader: expected 400, got %d", rec.Code)
+	}
+}
+
+// TestCompareHandler checks that /compare grades both versions and rejects
+// requests missing one
+func TestCompareHandler(t *testing.T) {
+	handler := CompareHandler(DefaultServerConfig())
+	post := func(body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/compare", strings.NewReader(body)))
+		return rec
+	}
+
+	rec := post(`{"original": "Write code.", "revised": "Write a Go function that parses ISO 8601 dates."}`)
+	var got CompareResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); rec.Code != http.StatusOK || err != nil {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	if got.OriginalGrade == nil || got.RevisedGrade == nil {
+		t.Fatalf("missing grades: %s", rec.Body)
+	}
+	if want := got.RevisedGrade.OverallGrade.Score - got.OriginalGrade.OverallGrade.Score; got.ScoreDelta != want {
+		t.Errorf("score delta = %v, want %v", got.ScoreDelta, want)
+	}
+
+	rec = post(`{"original": "Write code.", "revised": "Write a Go parser.", "options": {"grader": "modern"}}`)
+	got = CompareResponse{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); rec.Code != http.StatusOK || err != nil {
+		t.Fatalf("modern: expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	if got.OriginalGrade != nil || got.RevisedGrades.Engine != GraderModern || got.ScoreDelta != got.RevisedGrades.Score-got.OriginalGrades.Score {
+		t.Errorf("modern compare = %s", rec.Body)
+	}
+	if rec := post(`{"original": "a", "revised": "b", "options": {"grader": "fancy"}}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("unknown grader: expected 400, got %d", rec.Code)
+	}
+
+	if rec := post(`{"original": "Write code."}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("missing revised: expected 400, got %d", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/compare", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET: expected 405, got %d", rec.Code)
+	}
+}
+
+// TestWhatIfHandler predicts a grade and rejects invalid changes
+func TestWhatIfHandler(t *testing.T) {
+	handler := WhatIfHandler(DefaultServerConfig())
+	post := func(body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/what-if", strings.NewReader(body)))
+		return rec
+	}
+
+	rec := post(`{"text": "Summarize the report.", "changes": [{"kind": "append_text", "text": "Respond in three bullet points."}]}`)
+	var got WhatIfResult
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); rec.Code != http.StatusOK || err != nil {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	if got.Text != "Summarize the report.\n\nRespond in three bullet points." || got.Before.Engine != GraderClassic {
+		t.Errorf("result = %+v", got)
+	}
+
+	if rec := post(`{"text": "Summarize the report.", "changes": [{"kind": "rewrite"}]}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("unknown change: expected 400, got %d", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/what-if", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET: expected 405, got %d", rec.Code)
+	}
+}
+
+// TestHandlerDefaults checks that the configured defaults reach every
+// grading handler, under requests that set other options
+func TestHandlerDefaults(t *testing.T) {
+	cfg := DefaultServerConfig()
+	cfg.Defaults = AnalysisOptions{Grader: GraderModern}
+	cases := []struct {
+		path    string
+		handler http.Handler
+		body    string
+	}{
+		{"/analyze", AnalyzeHandler(cfg, FullAnalysis(nil)), `{"text": "Summarize the report.", "options": {"deterministic": true}}`},
+		{"/compare", CompareHandler(cfg), `{"original": "Write code.", "revised": "Write a Go parser.", "options": {"explain": true}}`},
+		{"/what-if", WhatIfHandler(cfg), `{"text": "Summarize the report.", "changes": [{"kind": "append_text", "text": "Use bullets."}], "options": {"explain": true}}`},
+	}
+	for _, tc := range cases {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, tc.path, strings.NewReader(tc.body))
+		req.Header.Set("Content-Type", "application/json")
+		tc.handler.ServeHTTP(rec, req)
+		var got struct {
+			Grades         GradeEnvelope `json:"grades"`
+			OriginalGrades GradeEnvelope `json:"original_grades"`
+			Before         GradeEnvelope `json:"before"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &got); rec.Code != http.StatusOK || err != nil {
+			t.Fatalf("%s: expected 200, got %d: %s", tc.path, rec.Code, rec.Body)
+		}
+		if engine := got.Grades.Engine + got.OriginalGrades.Engine + got.Before.Engine; engine != GraderModern {
+			t.Errorf("%s: graded by %q, want the default %q", tc.path, engine, GraderModern)
+		}
+	}
+}
+
+// TestAnalysisSearchHandler checks routing, query validation and unknown IDs
+func TestAnalysisSearchHandler(t *testing.T) {
+	stored := &CombinedResult{}
+	stored.TaskGraph.Tasks = []Task{{ID: "task_1", Title: "Add parser tests"}}
+	handler := AnalysisSearchHandler(func(ctx context.Context, id string) (*CombinedResult, error) {
+		if id != "a1" {
+			return nil, ErrAnalysisNotFound
+		}
+		return stored, nil
+	})
+	cases := []struct {
+		path string
+		want int
+	}{
+		{"/analyses/a1/search?q=parser", http.StatusOK},
+		{"/analyses/a1/search", http.StatusBadRequest},
+		{"/analyses/missing/search?q=parser", http.StatusNotFound},
+		{"/analyses/a1/other?q=parser", http.StatusNotFound},
+	}
+	for _, c := range cases {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, c.path, nil))
+		if rec.Code != c.want {
+			t.Errorf("%s: expected %d, got %d: %s", c.path, c.want, rec.Code, rec.Body)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/analyses/a1/search?q=parser", nil))
+	var resp SearchResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || len(resp.Matches) != 1 || resp.Matches[0].ID != "task_1" {
+		t.Errorf("search response = %s", rec.Body)
+	}
+}
+
+// TestHistoryHandler checks that the tag filter reaches the lister and bad limits are rejected
+func TestHistoryHandler(t *testing.T) {
+	entries := []HistoryEntry{
+		{ID: "e2", Tags: AnalysisTags{Project: "support-bot"}},
+		{ID: "e1", Tags: AnalysisTags{Project: "coding-agent"}},
+	}
+	handler := HistoryHandler(func(ctx context.Context, q HistoryQuery) (HistoryResponse, error) {
+		return PageHistory(entries, q)
+	})
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/history?project=coding-agent", nil))
+	var resp HistoryResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || len(resp.Entries) != 1 || resp.Entries[0].ID != "e1" {
+		t.Errorf("filtered history = %d %s", rec.Code, rec.Body)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/history?limit=0", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("limit=0: expected 400, got %d", rec.Code)
+	}
+}
+
+// TestHealthHandlers checks that only readiness runs checks and fails with them
+func TestHealthHandlers(t *testing.T) {
+	storageUp := true
+	cfg := HealthConfig{
+		Started: time.Now(),
+		Checks: map[string]HealthCheckFunc{
+			"storage": func(ctx context.Context) error {
+				if !storageUp {
+					return errors.New("connection refused")
+				}
+				return nil
+			},
+		},
+	}
+	get := func(h http.Handler) (int, HealthResponse) {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+		var resp HealthResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+		return rec.Code, resp
+	}
+
+	code, resp := get(ReadinessHandler(cfg))
+	if code != http.StatusOK || resp.Status != "ok" || len(resp.Checks) != 1 || !resp.Checks[0].OK {
+		t.Errorf("ready with storage up: %d %+v", code, resp)
+	}
+	if resp.SchemaVersion != ResultSchemaVersion || resp.AnalyzerVersion == "" || resp.Build.GoVersion == "" {
+		t.Errorf("version info missing: %+v", resp)
+	}
+
+	storageUp = false
+	code, resp = get(ReadinessHandler(cfg))
+	if code != http.StatusServiceUnavailable || resp.Status != "degraded" || resp.Checks[0].Error != "connection refused" {
+		t.Errorf("ready with storage down: %d %+v", code, resp)
+	}
+	code, resp = get(LivenessHandler(cfg))
+	if code != http.StatusOK || len(resp.Checks) != 0 {
+		t.Errorf("liveness should ignore checks: %d %+v", code, resp)
+	}
+}
+
+// TestAdminConfigHandler checks the token gate and that PATCH merges and validates
+func TestAdminConfigHandler(t *testing.T) {
+	defer UpdateRuntimeConfig(func(c *RuntimeConfig) error {
+		*c = DefaultRuntimeConfig()
+		return nil
+	})
+	send := func(h http.Handler, method, token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/admin/config", strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := send(AdminConfigHandler(""), http.MethodGet, "secret", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("no token configured: got %d", rec.Code)
+	}
+	h := AdminConfigHandler("secret")
+	if rec := send(h, http.MethodGet, "wrong", ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("wrong token: got %d", rec.Code)
+	}
+
+	rec := send(h, http.MethodPatch, "secret", `{"workers": 6, "max_task_sentences": 40}`)
+	var got RuntimeConfig
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("patch: %d %s", rec.Code, rec.Body)
+	}
+	want := DefaultRuntimeConfig()
+	want.Workers, want.MaxTaskSentences = 6, 40
+	if got != want || CurrentRuntimeConfig() != want {
+		t.Errorf("after patch: got %+v, want %+v", got, want)
+	}
+	if serverWorkers() != 6 {
+		t.Errorf("server workers = %d, want 6", serverWorkers())
+	}
+
+	for _, body := range []string{`{"workers": -1}`, `{"memory_budget_bytes": 10}`, `{"threads": 2}`} {
+		if rec := send(h, http.MethodPatch, "secret", body); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: got %d, want 400", body, rec.Code)
+		}
+	}
+	if CurrentRuntimeConfig() != want {
+		t.Errorf("rejected patches changed the config: %+v", CurrentRuntimeConfig())
+	}
+}
+
+// TestBatchHandlerReportsPerItem checks item order and per-item errors
+func TestBatchHandlerReportsPerItem(t *testing.T) {
+	queue := NewWorkerPool(2)
+	defer queue.Close()
+	handler := BatchHandler(DefaultServerConfig(), queue, func(ctx context.Context, req AnalyzeRequest) (interface{}, error) {
+		if req.Text == "fail" {
+			return nil, errors.New("analysis failed")
+		}
+		return map[string]int{"length": len(req.Text)}, nil
+	})
+	body := `{"items": [{"text": "hello"}, {"text": "fail"}, {"text": "hi", "options": {"format": "xml"}}]}`
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/batch", strings.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body)
+	}
+	var resp BatchResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Results) != 3 || string(resp.Results[0].Result) != `{"length":5}` ||
+		resp.Results[1].Error != "analysis failed" || resp.Results[2].Error == "" {
+		t.Errorf("results = %+v", resp.Results)
+	}
+	queue.Wait()
+	if got := queue.Stats()["batch"].Completed; got != 2 {
+		t.Errorf("batch jobs completed = %d, want 2", got)
+	}
+}
+
+func TestCompressHandler(t *testing.T) {
+	large := strings.Repeat(`{"sentence":"The billing database stores invoices."}`, 100)
+	handler := CompressHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/large":
+			w.Header().Set("Content-Type", "application/json")
+			io.WriteString(w, large)
+		case "/small":
+			writeAPIError(w, http.StatusBadRequest, "bad request")
+		case "/events":
+			w.Header().Set("Content-Type", "text/event-stream")
+			io.WriteString(w, "event: progress\ndata: {}\n\n")
+			w.(http.Flusher).Flush()
+		}
+	}))
+	get := func(path, acceptEncoding string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := get("/large", "br, gzip")
+	if rec.Header().Get("Content-Encoding") != "gzip" || rec.Body.Len() >= len(large) {
+		t.Fatalf("large JSON not compressed: %v, %d bytes", rec.Header(), rec.Body.Len())
+	}
+	zr, err := gzip.NewReader(rec.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := io.ReadAll(zr); string(b) != large {
+		t.Error("decompressed body differs")
+	}
+
+	if rec := get("/large", "gzip;q=0"); rec.Header().Get("Content-Encoding") != "" || rec.Body.String() != large {
+		t.Errorf("gzip;q=0 still compressed: %v", rec.Header())
+	}
+	if rec := get("/small", "gzip"); rec.Code != http.StatusBadRequest || rec.Header().Get("Content-Encoding") != "" {
+		t.Errorf("small error response: %d %v", rec.Code, rec.Header())
+	}
+
+	rec = get("/events", "gzip")
+	if !rec.Flushed || rec.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("event stream: flushed=%v %v", rec.Flushed, rec.Header())
+	}
+	zr, err = gzip.NewReader(rec.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if line, _ := bufio.NewReader(zr).ReadString('\n'); line != "event: progress\n" {
+		t.Errorf("first event line = %q", line)
+	}
+
+	compressed, err := CompressResult([]byte(large), CompressionGzip)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if zr, err = gzip.NewReader(strings.NewReader(string(compressed))); err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := io.ReadAll(zr); string(b) != large {
+		t.Error("CompressResult did not round-trip")
+	}
+	if err := (AnalysisOptions{Compression: CompressionGzip, Format: FormatObject}).Validate(); err == nil {
+		t.Error("gzip accepted with the object format")
+	}
+}
+
+// TestExperimentsHandler walks an experiment through create, outcomes and report
+func TestExperimentsHandler(t *testing.T) {
+	handler := ExperimentsHandler(DefaultServerConfig(), NewExperiments())
+	do := func(method, target, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(method, target, strings.NewReader(body)))
+		return rec
+	}
+
+	rec := do(http.MethodPost, "/experiments", `{"name": "greeting", "variants": [{"prompt": "say hi"}, {"prompt": "Write a two-sentence greeting for new users of the billing dashboard."}]}`)
+	var exp Experiment
+	if err := json.Unmarshal(rec.Body.Bytes(), &exp); rec.Code != http.StatusCreated || err != nil {
+		t.Fatalf("create: expected 201, got %d: %s", rec.Code, rec.Body)
+	}
+	rec = do(http.MethodPost, "/experiments/"+exp.ID+"/outcomes", `{"outcomes": [{"variant": "A", "metric": "user_rating", "value": 2}, {"variant": "B", "metric": "user_rating", "value": 4}, {"variant": "B", "metric": "user_rating", "value": 5}]}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("outcomes: expected 201, got %d: %s", rec.Code, rec.Body)
+	}
+	rec = do(http.MethodGet, "/experiments/"+exp.ID+"/report", "")
+	var report ExperimentReport
+	if err := json.Unmarshal(rec.Body.Bytes(), &report); rec.Code != http.StatusOK || err != nil {
+		t.Fatalf("report: expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	if len(report.Variants) != 2 || report.Predictors["user_rating"] == "" {
+		t.Errorf("report = %+v", report)
+	}
+
+	cases := []struct {
+		method, target, body string
+		want                 int
+	}{
+		{http.MethodGet, "/experiments", "", http.StatusOK},
+		{http.MethodGet, "/experiments/report", "", http.StatusOK},
+		{http.MethodGet, "/experiments/" + exp.ID, "", http.StatusOK},
+		{http.MethodGet, "/experiments/exp-9", "", http.StatusNotFound},
+		{http.MethodGet, "/experiments/exp-9/report", "", http.StatusNotFound},
+		{http.MethodPost, "/experiments/exp-9/outcomes", `{"outcomes": [{"variant": "A", "metric": "m"}]}`, http.StatusNotFound},
+		{http.MethodPost, "/experiments/" + exp.ID + "/outcomes", `{"outcomes": [{"variant": "Z", "metric": "m"}]}`, http.StatusBadRequest},
+		{http.MethodPost, "/experiments", `{"name": "x", "variants": []}`, http.StatusBadRequest},
+		{http.MethodDelete, "/experiments", "", http.StatusMethodNotAllowed},
+		{http.MethodGet, "/experiments/" + exp.ID + "/outcomes", "", http.StatusMethodNotAllowed},
+		{http.MethodGet, "/experiments/weights?metric=user_rating", "", http.StatusUnprocessableEntity},
+		{http.MethodGet, "/experiments/weights?metric=user_rating&min_samples=2", "", http.StatusOK},
+		{http.MethodGet, "/experiments/weights", "", http.StatusBadRequest},
+		{http.MethodGet, "/experimentsx", "", http.StatusNotFound},
+	}
+	for _, c := range cases {
+		if rec := do(c.method, c.target, c.body); rec.Code != c.want {
+			t.Errorf("%s %s: expected %d, got %d: %s", c.method, c.target, c.want, rec.Code, rec.Body)
+		}
+	}
+}
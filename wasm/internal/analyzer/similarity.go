@@ -0,0 +1,220 @@
+package analyzer
+
+import (
+	"hash/fnv"
+	"math"
+	"sort"
+	"strings"
+)
+
+// CorpusSimilarity compares every pair of documents in a corpus
+type CorpusSimilarity struct {
+	Documents      []string        `json:"documents"`
+	Matrix         [][]float64     `json:"matrix"` // Estimated shingle similarity, indexed like Documents
+	NearDuplicates []DuplicatePair `json:"near_duplicates"`
+	Clusters       []TopicCluster  `json:"clusters"`
+	Assignments    []int           `json:"assignments"` // Cluster ID of each document
+}
+
+// DuplicatePair is two documents whose wording is nearly identical
+type DuplicatePair struct {
+	A          string  `json:"a"`
+	B          string  `json:"b"`
+	Similarity float64 `json:"similarity"`
+}
+
+// TopicCluster groups documents that share vocabulary
+type TopicCluster struct {
+	ID      int      `json:"id"`
+	Label   []string `json:"label"` // Most common content words across members
+	Members []string `json:"members"`
+}
+
+// Similarity settings. Duplicates compare word 3-gram shingles; topics compare
+// content words, which tolerates rewording.
+const (
+	minHashSize            = 128
+	shingleSize            = 3
+	nearDuplicateThreshold = 0.8
+	topicThreshold         = 0.25
+	topicLabelTerms        = 3
+)
+
+// minHashSeeds are fixed so signatures are comparable across runs
+var minHashSeeds = func() []uint64 {
+	seeds := make([]uint64, minHashSize)
+	state := uint64(0x9E3779B97F4A7C15)
+	for i := range seeds {
+		state = splitMix64(state)
+		seeds[i] = state
+	}
+	return seeds
+}()
+
+// CompareCorpus estimates pairwise similarity with MinHash, flags near duplicates
+// and clusters documents by topic
+func CompareCorpus(docs []CorpusDocument) CorpusSimilarity {
+	n := len(docs)
+	result := CorpusSimilarity{
+		Documents:      make([]string, n),
+		Matrix:         make([][]float64, n),
+		NearDuplicates: []DuplicatePair{},
+		Clusters:       []TopicCluster{},
+		Assignments:    make([]int, n),
+	}
+
+	shingleSigs := make([][]uint64, n)
+	topicSigs := make([][]uint64, n)
+	terms := make([][]string, n)
+	for i, doc := range docs {
+		result.Documents[i] = doc.Name
+		words := alphaWordPattern.FindAllString(strings.ToLower(doc.Text), -1)
+		shingleSigs[i] = minHashSignature(wordShingles(words, shingleSize))
+		terms[i] = contentTerms(words)
+		topicSigs[i] = minHashSignature(terms[i])
+	}
+
+	parent := make([]int, n)
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	for i := 0; i < n; i++ {
+		result.Matrix[i] = make([]float64, n)
+		result.Matrix[i][i] = 1
+	}
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			sim := signatureSimilarity(shingleSigs[i], shingleSigs[j])
+			result.Matrix[i][j], result.Matrix[j][i] = sim, sim
+			if sim >= nearDuplicateThreshold {
+				result.NearDuplicates = append(result.NearDuplicates, DuplicatePair{A: docs[i].Name, B: docs[j].Name, Similarity: sim})
+			}
+			if sim >= nearDuplicateThreshold || signatureSimilarity(topicSigs[i], topicSigs[j]) >= topicThreshold {
+				parent[find(i)] = find(j)
+			}
+		}
+	}
+	sort.SliceStable(result.NearDuplicates, func(i, j int) bool {
+		return result.NearDuplicates[i].Similarity > result.NearDuplicates[j].Similarity
+	})
+
+	// Number clusters in order of their first document
+	clusterIDs := make(map[int]int)
+	for i := 0; i < n; i++ {
+		root := find(i)
+		id, ok := clusterIDs[root]
+		if !ok {
+			id = len(result.Clusters)
+			clusterIDs[root] = id
+			result.Clusters = append(result.Clusters, TopicCluster{ID: id, Members: []string{}})
+		}
+		result.Assignments[i] = id
+		result.Clusters[id].Members = append(result.Clusters[id].Members, docs[i].Name)
+	}
+	for id := range result.Clusters {
+		var members [][]string
+		for i, assigned := range result.Assignments {
+			if assigned == id {
+				members = append(members, terms[i])
+			}
+		}
+		result.Clusters[id].Label = clusterLabel(members)
+	}
+	return result
+}
+
+// wordShingles returns every run of k consecutive words, or the words themselves for short texts
+func wordShingles(words []string, k int) []string {
+	if len(words) < k {
+		return words
+	}
+	shingles := make([]string, 0, len(words)-k+1)
+	for i := 0; i+k <= len(words); i++ {
+		shingles = append(shingles, strings.Join(words[i:i+k], " "))
+	}
+	return shingles
+}
+
+// contentTerms drops stop words and very short words
+func contentTerms(words []string) []string {
+	terms := []string{}
+	for _, w := range words {
+		if len(w) > 2 && !isStopWord(w) {
+			terms = append(terms, w)
+		}
+	}
+	return terms
+}
+
+// minHashSignature keeps, for each seed, the smallest hash of any item
+func minHashSignature(items []string) []uint64 {
+	sig := make([]uint64, minHashSize)
+	for i := range sig {
+		sig[i] = math.MaxUint64
+	}
+	for _, item := range items {
+		h := fnv.New64a()
+		h.Write([]byte(item))
+		base := h.Sum64()
+		for i, seed := range minHashSeeds {
+			if v := splitMix64(base ^ seed); v < sig[i] {
+				sig[i] = v
+			}
+		}
+	}
+	return sig
+}
+
+// signatureSimilarity estimates Jaccard similarity as the share of matching minimums
+func signatureSimilarity(a, b []uint64) float64 {
+	if a[0] == math.MaxUint64 || b[0] == math.MaxUint64 {
+		return 0 // An empty document is similar to nothing
+	}
+	matches := 0
+	for i := range a {
+		if a[i] == b[i] {
+			matches++
+		}
+	}
+	return float64(matches) / float64(len(a))
+}
+
+// splitMix64 is a fast, well-distributed 64-bit mixer
+func splitMix64(x uint64) uint64 {
+	x += 0x9E3779B97F4A7C15
+	x = (x ^ (x >> 30)) * 0xBF58476D1CE4E5B9
+	x = (x ^ (x >> 27)) * 0x94D049BB133111EB
+	return x ^ (x >> 31)
+}
+
+// clusterLabel picks the content words found in the most members
+func clusterLabel(members [][]string) []string {
+	counts := make(map[string]int)
+	for _, terms := range members {
+		for term := range newTermSet(terms) {
+			counts[term]++
+		}
+	}
+	label := make([]string, 0, len(counts))
+	for term := range counts {
+		label = append(label, term)
+	}
+	sort.Slice(label, func(i, j int) bool {
+		if counts[label[i]] != counts[label[j]] {
+			return counts[label[i]] > counts[label[j]]
+		}
+		return label[i] < label[j]
+	})
+	if len(label) > topicLabelTerms {
+		label = label[:topicLabelTerms]
+	}
+	return label
+}
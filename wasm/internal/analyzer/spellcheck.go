@@ -0,0 +1,373 @@
+package analyzer
+
+import (
+	_ "embed"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// englishDictionary lists English words with corpus frequencies, one
+// "word count" pair per line, most frequent first
+//
+//go:embed dictionaries/en.txt
+var englishDictionary string
+
+// spellDictionarySources maps language codes to embedded word lists. Text in
+// other languages is not spell checked.
+var spellDictionarySources = map[string]*string{
+	"en": &englishDictionary,
+}
+
+// commonMisspellings are reported even when a corpus happens to contain them
+var commonMisspellings = map[string]string{
+	"accomodate": "accommodate", "acheive": "achieve", "accross": "across", "adress": "address",
+	"agressive": "aggressive", "apparantly": "apparently", "arguement": "argument", "basicly": "basically",
+	"begining": "beginning", "beleive": "believe", "calender": "calendar", "catagory": "category",
+	"commited": "committed", "completly": "completely", "concious": "conscious", "definately": "definitely",
+	"dependancy": "dependency", "enviroment": "environment", "existance": "existence", "explaination": "explanation",
+	"familar": "familiar", "finaly": "finally", "foward": "forward", "goverment": "government",
+	"gaurd": "guard", "happend": "happened", "immediatly": "immediately", "independant": "independent",
+	"knowlege": "knowledge", "lenght": "length", "maintainance": "maintenance", "mispell": "misspell",
+	"neccessary": "necessary", "noticable": "noticeable", "occured": "occurred", "occurence": "occurrence",
+	"paramter": "parameter", "persistant": "persistent", "posible": "possible", "prefered": "preferred",
+	"publically": "publicly", "recieve": "receive", "recomend": "recommend", "refered": "referred",
+	"relevent": "relevant", "repositry": "repository", "responsability": "responsibility", "seperate": "separate",
+	"sucess": "success", "succesful": "successful", "supress": "suppress", "tommorow": "tomorrow",
+	"truely": "truly", "untill": "until", "wierd": "weird", "wich": "which", "teh": "the", "thier": "their",
+}
+
+// spellDictionary is a frequency list indexed for symmetric-delete lookups:
+// deletes maps every one-character deletion of a word back to the word, so
+// candidates within two edits are found without scanning the whole list
+type spellDictionary struct {
+	words   map[string]int
+	deletes map[string][]string
+	longest int // Length of the longest word
+}
+
+var (
+	spellDictionaries   = map[string]*spellDictionary{}
+	spellDictionariesMu sync.Mutex
+)
+
+// loadSpellDictionary parses and indexes a language's word list on first use
+func loadSpellDictionary(language string) *spellDictionary {
+	spellDictionariesMu.Lock()
+	defer spellDictionariesMu.Unlock()
+	if dict, ok := spellDictionaries[language]; ok {
+		return dict
+	}
+	source, ok := spellDictionarySources[language]
+	if !ok {
+		spellDictionaries[language] = nil
+		return nil
+	}
+
+	dict := &spellDictionary{words: map[string]int{}, deletes: map[string][]string{}}
+	for _, line := range strings.Split(*source, "\n") {
+		word, count, ok := strings.Cut(strings.TrimSpace(line), " ")
+		if !ok {
+			continue
+		}
+		n, err := strconv.Atoi(count)
+		if err != nil {
+			continue
+		}
+		dict.words[word] = n
+		dict.longest = max(dict.longest, len(word))
+		for _, d := range singleDeletes(word) {
+			dict.deletes[d] = append(dict.deletes[d], word)
+		}
+	}
+	spellDictionaries[language] = dict
+	return dict
+}
+
+// singleDeletes returns each distinct string formed by deleting one byte of word
+func singleDeletes(word string) []string {
+	if len(word) < 2 {
+		return nil
+	}
+	out := make([]string, 0, len(word))
+	for i := range word {
+		d := word[:i] + word[i+1:]
+		if len(out) == 0 || out[len(out)-1] != d {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+// SpellingConfig customizes spell checking for one analysis
+type SpellingConfig struct {
+	Allow []string `json:"allow,omitempty"` // Words never reported, such as product names
+}
+
+// SpellChecker finds misspelled words using an embedded frequency dictionary
+type SpellChecker struct {
+	language string
+	dict     *spellDictionary // Nil when the language has no dictionary
+	allow    map[string]bool
+}
+
+// NewSpellChecker returns a checker for language. Words in allow, in any case,
+// are never reported; languages without a dictionary report nothing.
+func NewSpellChecker(language string, allow []string) *SpellChecker {
+	c := &SpellChecker{language: language, dict: loadSpellDictionary(language), allow: map[string]bool{}}
+	for _, word := range allow {
+		for _, part := range alphaWordPattern.FindAllString(word, -1) {
+			c.allow[strings.ToLower(part)] = true
+		}
+	}
+	return c
+}
+
+// defaultSpellChecker is the English checker without an allowlist
+var defaultSpellChecker = sync.OnceValue(func() *SpellChecker { return NewSpellChecker("en", nil) })
+
+// Language returns the dictionary language
+func (c *SpellChecker) Language() string {
+	return c.language
+}
+
+// Known reports whether word, or a regular inflection of it, is in the
+// dictionary or allowlist
+func (c *SpellChecker) Known(word string) bool {
+	word = strings.ToLower(word)
+	if c.allow[word] || c.dict == nil {
+		return true
+	}
+	if _, ok := c.dict.words[word]; ok {
+		return true
+	}
+	for _, base := range inflectionBases(word) {
+		if _, ok := c.dict.words[base]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// inflectionBases guesses the stems a word could be inflected from, so the
+// dictionary needn't list every plural, tense and adverb
+func inflectionBases(word string) []string {
+	bases := []string{}
+	add := func(suffix, replacement string) {
+		if strings.HasSuffix(word, suffix) && len(word)-len(suffix) >= 2 {
+			stem := word[:len(word)-len(suffix)]
+			bases = append(bases, stem+replacement)
+			// Doubled final consonant: "running" -> "run"
+			if n := len(stem); replacement == "" && n >= 3 && stem[n-1] == stem[n-2] {
+				bases = append(bases, stem[:n-1])
+			}
+		}
+	}
+	add("s", "")
+	add("es", "")
+	add("ies", "y")
+	add("ed", "")
+	add("ed", "e")
+	add("ied", "y")
+	add("ing", "")
+	add("ing", "e")
+	add("ly", "")
+	add("ily", "y")
+	add("er", "")
+	add("er", "e")
+	add("est", "")
+	add("ness", "")
+	add("ment", "")
+	add("able", "")
+	for _, prefix := range []string{"un", "re", "pre", "non", "sub", "multi"} {
+		if strings.HasPrefix(word, prefix) && len(word)-len(prefix) >= 3 {
+			bases = append(bases, word[len(prefix):])
+		}
+	}
+	return bases
+}
+
+// SpellingCandidate is a possible correction
+type SpellingCandidate struct {
+	Word      string
+	Distance  int // Damerau-Levenshtein (optimal string alignment) distance
+	Frequency int
+}
+
+// minSuggestionFrequency is the corpus count below which a word isn't offered as a correction
+const minSuggestionFrequency = 10
+
+// Suggest returns dictionary words within two edits of word, closest and
+// most frequent first
+func (c *SpellChecker) Suggest(word string, limit int) []SpellingCandidate {
+	word = strings.ToLower(word)
+	if fix, ok := commonMisspellings[word]; ok {
+		return []SpellingCandidate{{Word: fix, Distance: editDistance(word, fix), Frequency: c.frequency(fix)}}
+	}
+	// Nothing is within two edits of a word this long, and building its
+	// deletes would cost the square of its length
+	if c.dict == nil || len(word) > c.dict.longest+2 {
+		return nil
+	}
+
+	seen := map[string]bool{}
+	candidates := []SpellingCandidate{}
+	consider := func(w string) {
+		if seen[w] || w == word {
+			return
+		}
+		seen[w] = true
+		// Rare corpus words still count as known but make poor corrections
+		if d := editDistance(word, w); d <= 2 && c.dict.words[w] >= minSuggestionFrequency {
+			candidates = append(candidates, SpellingCandidate{Word: w, Distance: d, Frequency: c.dict.words[w]})
+		}
+	}
+	for _, key := range append([]string{word}, singleDeletes(word)...) {
+		if _, ok := c.dict.words[key]; ok {
+			consider(key)
+		}
+		for _, w := range c.dict.deletes[key] {
+			consider(w)
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].Distance != candidates[j].Distance {
+			return candidates[i].Distance < candidates[j].Distance
+		}
+		if candidates[i].Frequency != candidates[j].Frequency {
+			return candidates[i].Frequency > candidates[j].Frequency
+		}
+		return candidates[i].Word < candidates[j].Word
+	})
+	if len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+	return candidates
+}
+
+func (c *SpellChecker) frequency(word string) int {
+	if c.dict == nil {
+		return 0
+	}
+	return c.dict.words[word]
+}
+
+// spellWordPattern matches words with an optional contraction ("don't")
+var spellWordPattern = regexp.MustCompile(`[A-Za-z]+(?:'[A-Za-z]+)?`)
+
+// Check reports misspelled words in text with their byte offsets. Words that
+// look like names, acronyms, identifiers or parts of URLs, emails and code
+// are skipped, as are unknown words with no close dictionary match.
+func (c *SpellChecker) Check(text string) []SpellingError {
+	errors := []SpellingError{}
+	if c.dict == nil {
+		return errors
+	}
+	skip := append(patternSpans(text, urlPattern), patternSpans(text, emailPattern)...)
+	skip = append(skip, patternSpans(text, inlineCodePattern)...)
+
+	for _, m := range spellWordPattern.FindAllStringIndex(text, -1) {
+		word := text[m[0]:m[1]]
+		if base, _, ok := strings.Cut(word, "'"); ok {
+			word = base
+		}
+		lower := strings.ToLower(word)
+		if len(word) < 3 || !spellCheckable(text, m[0], m[0]+len(word)) || insideSpans(skip, m[0]) {
+			continue
+		}
+		if _, misspelled := commonMisspellings[lower]; c.allow[lower] || (!misspelled && c.Known(word)) {
+			continue
+		}
+		candidates := c.Suggest(word, 3)
+		if len(candidates) == 0 || (candidates[0].Distance == 2 && len(word) < 6) {
+			continue
+		}
+		suggestions := make([]string, len(candidates))
+		for i, cand := range candidates {
+			suggestions[i] = matchCase(word, cand.Word)
+		}
+		errors = append(errors, SpellingError{
+			Word:        word,
+			Position:    m[0],
+			Suggestions: suggestions,
+			Distance:    candidates[0].Distance,
+		})
+	}
+	return errors
+}
+
+// inlineCodePattern matches `code` and fenced code blocks
+var inlineCodePattern = regexp.MustCompile("(?s)```.*?```|`[^`\n]+`")
+
+// spellCheckable reports whether text[start:end] is an ordinary word: all
+// lowercase or capitalized, and not glued to digits, paths or identifiers
+func spellCheckable(text string, start, end int) bool {
+	word := text[start:end]
+	if strings.ToLower(word[1:]) != word[1:] {
+		return false // Acronym or CamelCase
+	}
+	if start > 0 && strings.ContainsRune("_@/\\.#$-0123456789", rune(text[start-1])) {
+		return false
+	}
+	if end < len(text) {
+		next := text[end]
+		if strings.ContainsRune("_@/\\(0123456789", rune(next)) {
+			return false
+		}
+		// "example.com" but not the full stop ending a sentence
+		if next == '.' && end+1 < len(text) && isASCIILetter(text[end+1]) {
+			return false
+		}
+	}
+	return true
+}
+
+func isASCIILetter(b byte) bool {
+	return (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z')
+}
+
+func insideSpans(spans []Span, offset int) bool {
+	for _, s := range spans {
+		if offset >= s.Start && offset < s.End {
+			return true
+		}
+	}
+	return false
+}
+
+// matchCase capitalizes suggestion like word
+func matchCase(word, suggestion string) string {
+	if word != "" && word[0] >= 'A' && word[0] <= 'Z' {
+		return strings.ToUpper(suggestion[:1]) + suggestion[1:]
+	}
+	return suggestion
+}
+
+// editDistance is the optimal string alignment distance: insertions,
+// deletions, substitutions and adjacent transpositions each cost one
+func editDistance(a, b string) int {
+	prev2 := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(min(prev[j]+1, curr[j-1]+1), prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				curr[j] = min(curr[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, curr = prev, curr, prev2
+	}
+	return prev[len(b)]
+}
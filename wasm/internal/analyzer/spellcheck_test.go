@@ -0,0 +1,77 @@
+package analyzer
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSpellCheckerCheck(t *testing.T) {
+	text := "Please recieve the pakage, explian the resluts and email ops@exmple.com. Run `pritn(x)` at https://exmple.com/docs."
+	got := NewSpellChecker("en", nil).Check(text)
+
+	want := map[string]string{"recieve": "receive", "pakage": "package", "explian": "explain", "resluts": "results"}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d errors, got %+v", len(want), got)
+	}
+	for _, e := range got {
+		if text[e.Position:e.Position+len(e.Word)] != e.Word {
+			t.Errorf("%q has the wrong offset %d", e.Word, e.Position)
+		}
+		if len(e.Suggestions) == 0 || e.Suggestions[0] != want[e.Word] {
+			t.Errorf("expected %q to be corrected to %q, got %v", e.Word, want[e.Word], e.Suggestions)
+		}
+	}
+}
+
+func TestSpellCheckerSkipsKnownAndAllowedWords(t *testing.T) {
+	text := "Deploying the Fulcrum services to Kubernetes, running checks quickly. Ask Acmecorp about the Zorbl rollout."
+	if got := NewSpellChecker("en", []string{"Zorbl"}).Check(text); len(got) != 0 {
+		t.Errorf("expected no errors, got %+v", got)
+	}
+	if got := NewSpellChecker("fr", nil).Check("Il faut recieve le document."); len(got) != 0 {
+		t.Errorf("expected languages without a dictionary to be skipped, got %+v", got)
+	}
+}
+
+func TestSpellCheckerSuggest(t *testing.T) {
+	c := NewSpellChecker("en", nil)
+	got := c.Suggest("Custmer", 1)
+	if len(got) != 1 || got[0].Word != "customer" || got[0].Distance != 1 {
+		t.Errorf("unexpected suggestion %+v", got)
+	}
+	if c.Suggest("seperate", 3)[0].Word != "separate" {
+		t.Error("expected common misspellings to take priority")
+	}
+	if got := c.Suggest(strings.Repeat("a", 10000), 3); got != nil {
+		t.Errorf("expected no suggestions for a word longer than any in the dictionary, got %+v", got)
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	for _, tc := range []struct {
+		a, b string
+		want int
+	}{{"recieve", "receive", 1}, {"kitten", "sitting", 3}, {"", "abc", 3}, {"same", "same", 0}} {
+		if got := editDistance(tc.a, tc.b); got != tc.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
+
+func TestAnalyzeSpellingAllowlist(t *testing.T) {
+	text := "Ask the Snowflak team to recieve the export."
+	opts := AnalysisOptions{Stages: []string{StagePreprocessing}, Spelling: SpellingConfig{Allow: []string{"Snowflak"}}}
+	result, err := Analyze(context.Background(), text, opts, AnalysisRun{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	words := []string{}
+	for _, e := range result.Preprocessing.QualityMetrics.SpellingErrors.Value {
+		words = append(words, e.Word)
+	}
+	if !reflect.DeepEqual(words, []string{"recieve"}) {
+		t.Errorf("expected only recieve to be reported, got %v", words)
+	}
+}
@@ -0,0 +1,160 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+)
+
+// Score stability limits
+const (
+	maxStabilitySamples      = 50  // Resamples AnalysisOptions.Stability may ask for
+	minStabilitySentences    = 3   // Fewer sentences leave no subsets worth grading
+	stabilityDropShare       = 0.1 // Share of sentences each sample drops
+	stabilityStableMargin    = 2.5 // Margins up to this many points count as stable
+	stabilityIntervalPercent = 95  // Confidence of the reported interval
+)
+
+// ScoreStability reports how much the leading grade moves when the prompt
+// loses a few sentences. Each sample drops a random tenth of the sentences
+// (at least one) and is regraded; the spread of those scores is the noise
+// in the full score, so two prompts whose scores differ by less than the
+// margin are not meaningfully different.
+type ScoreStability struct {
+	Engine     string               `json:"engine"`
+	Samples    int                  `json:"samples"`
+	Score      float64              `json:"score"` // The full prompt's score
+	Mean       float64              `json:"mean"`  // Mean resampled score
+	StdDev     float64              `json:"std_dev"`
+	Low        float64              `json:"low"`    // 2.5th percentile of resampled scores
+	High       float64              `json:"high"`   // 97.5th percentile of resampled scores
+	Margin     float64              `json:"margin"` // Half the interval width: report score ± margin
+	Grades     []string             `json:"grades"` // Letter grades of the prompt and its samples, best first
+	Stable     bool                 `json:"stable"` // Margin within 2.5 points
+	Summary    string               `json:"summary"`
+	Dimensions []DimensionStability `json:"dimensions"` // In dimension name order
+}
+
+// DimensionStability is one dimension's spread across the samples
+type DimensionStability struct {
+	Name   string  `json:"name"`
+	Score  float64 `json:"score"`
+	Low    float64 `json:"low"`
+	High   float64 `json:"high"`
+	Margin float64 `json:"margin"`
+}
+
+// validateStability rejects sample counts outside 0..maxStabilitySamples
+func validateStability(samples int) error {
+	if samples < 0 || samples > maxStabilitySamples {
+		return fmt.Errorf("stability must be between 0 and %d samples", maxStabilitySamples)
+	}
+	return nil
+}
+
+// AnalyzeScoreStability regrades samples sentence subsets of text with the
+// engines opts selects and measures the spread of the leading engine's
+// score around full. Each subset cuts the dropped sentences out of the
+// original text, so headings, line breaks and list markers survive. Samples
+// are seeded from the text, so the same prompt always gets the same
+// interval. It returns nil for prompts under three sentences, and ctx's
+// error once ctx is done.
+func AnalyzeScoreStability(ctx context.Context, text string, full GradeSummary, opts AnalysisOptions, samples int) (*ScoreStability, error) {
+	sentences := locateSentences(text)
+	if samples <= 0 || len(sentences) < minStabilitySentences {
+		return nil, nil
+	}
+	drop := max(1, int(math.Round(float64(len(sentences))*stabilityDropShare)))
+
+	h := fnv.New64a()
+	h.Write([]byte(text))
+	rng := rand.New(rand.NewSource(int64(h.Sum64())))
+	graders := GradersFor(opts)
+	classifier := NewPromptClassifierWithModel(opts.classifierModel())
+
+	scores := make([]float64, 0, samples)
+	dims := map[string][]float64{}
+	grades := map[string]bool{}
+	for i := 0; i < samples; i++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		subset, _ := removeSentences(text, rng.Perm(len(sentences))[:drop])
+		envelope, _ := GradeAll(graders, AnalyzeForGrading(subset, classifier))
+		scores = append(scores, envelope.Score)
+		grades[envelope.Grade] = true
+		for name, v := range envelope.Results[0].Dimensions {
+			dims[name] = append(dims[name], v)
+		}
+	}
+
+	low, high := interval(scores)
+	stats := scoreStats(scores)
+	s := &ScoreStability{
+		Engine:     full.Engine,
+		Samples:    samples,
+		Score:      full.Score,
+		Mean:       math.Round(stats.Mean*100) / 100,
+		StdDev:     math.Round(stats.StdDev*100) / 100,
+		Low:        low,
+		High:       high,
+		Margin:     math.Round((high-low)/2*100) / 100,
+		Grades:     []string{},
+		Dimensions: []DimensionStability{},
+	}
+	grades[full.Grade] = true
+	for g := range grades {
+		s.Grades = append(s.Grades, g)
+	}
+	sort.Slice(s.Grades, func(i, j int) bool { return gradeRank(s.Grades[i]) < gradeRank(s.Grades[j]) })
+	s.Stable = s.Margin <= stabilityStableMargin
+
+	names := make([]string, 0, len(full.Dimensions))
+	for name := range full.Dimensions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		lo, hi := interval(dims[name])
+		s.Dimensions = append(s.Dimensions, DimensionStability{
+			Name: name, Score: full.Dimensions[name], Low: lo, High: hi, Margin: math.Round((hi-lo)/2*100) / 100,
+		})
+	}
+
+	s.Summary = fmt.Sprintf("%.1f ± %.1f (%d%% interval %.1f-%.1f over %d samples)", s.Score, s.Margin, stabilityIntervalPercent, s.Low, s.High, samples)
+	if len(s.Grades) > 1 {
+		s.Summary += fmt.Sprintf("; the grade could be %s to %s", s.Grades[0], s.Grades[len(s.Grades)-1])
+	}
+	return s, nil
+}
+
+// interval returns the central 95% range of values
+func interval(values []float64) (float64, float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	tail := float64(100-stabilityIntervalPercent) / 2
+	return math.Round(percentile(sorted, tail)*100) / 100, math.Round(percentile(sorted, 100-tail)*100) / 100
+}
+
+// gradeRank orders letter grades best first: A+ is 0, F is last
+func gradeRank(grade string) int {
+	rank := 0
+	if grade != "" {
+		rank = int(grade[0]-'A') * 3
+	}
+	switch {
+	case strings.HasSuffix(grade, "+"):
+	case strings.HasSuffix(grade, "-"):
+		rank += 2
+	default:
+		rank++
+	}
+	return rank
+}
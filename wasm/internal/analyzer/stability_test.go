@@ -0,0 +1,84 @@
+package analyzer
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+const stabilityTestText = "You are a support assistant for our billing platform. Review the customer's last three invoices. Compare them against the pricing plan they signed up for. Flag any charges that do not match the plan. Write a short explanation of each mismatch. Keep the tone friendly and avoid jargon."
+
+func TestAnalyzeScoreStability(t *testing.T) {
+	opts := AnalysisOptions{}
+	envelope, _ := GradeAll(GradersFor(opts), AnalyzeForGrading(stabilityTestText, NewPromptClassifier()))
+	full := envelope.Results[0]
+
+	got, err := AnalyzeScoreStability(context.Background(), stabilityTestText, full, opts, 12)
+	if err != nil || got == nil {
+		t.Fatal("expected a stability report")
+	}
+	if got.Samples != 12 || got.Score != full.Score || got.Engine != GraderClassic {
+		t.Errorf("report = %+v", got)
+	}
+	if got.Low > got.High || math.Abs(got.Margin-(got.High-got.Low)/2) > 0.01 || got.Margin < 0 {
+		t.Errorf("interval %.2f-%.2f with margin %.2f", got.Low, got.High, got.Margin)
+	}
+	if got.Stable != (got.Margin <= stabilityStableMargin) || len(got.Grades) == 0 || len(got.Dimensions) != len(full.Dimensions) {
+		t.Errorf("report = %+v", got)
+	}
+	for _, d := range got.Dimensions {
+		if d.Low > d.High {
+			t.Errorf("dimension %s interval %.2f-%.2f", d.Name, d.Low, d.High)
+		}
+	}
+	if again, _ := AnalyzeScoreStability(context.Background(), stabilityTestText, full, opts, 12); again.Low != got.Low || again.High != got.High {
+		t.Error("the same prompt got a different interval")
+	}
+
+	if short, _ := AnalyzeScoreStability(context.Background(), "Fix the bug. Add a test.", full, opts, 12); short != nil {
+		t.Error("expected no report for two sentences")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := AnalyzeScoreStability(ctx, stabilityTestText, full, opts, 12); err != context.Canceled {
+		t.Errorf("expected a canceled run to stop sampling, got %v", err)
+	}
+}
+
+func TestScoreStabilityKeepsStructure(t *testing.T) {
+	text := "# Role\nYou are a release manager for a mobile app.\n\n## Steps\n- Read the changelog below.\n- List every user-facing change.\n- Group the changes by platform.\n\n## Output\nReturn a markdown table with one row per change.\nKeep each description under twenty words."
+	opts := AnalysisOptions{}
+	envelope, _ := GradeAll(GradersFor(opts), AnalyzeForGrading(text, NewPromptClassifier()))
+	full := envelope.Results[0]
+
+	got, err := AnalyzeScoreStability(context.Background(), text, full, opts, 12)
+	if err != nil || got == nil {
+		t.Fatalf("expected a stability report, got %v", err)
+	}
+	if full.Score < got.Low || full.Score > got.High {
+		t.Errorf("expected the full score %.2f inside %.2f-%.2f", full.Score, got.Low, got.High)
+	}
+}
+
+func TestStabilityOption(t *testing.T) {
+	if err := (AnalysisOptions{Stability: maxStabilitySamples + 1}).Validate(); err == nil {
+		t.Error("expected too many samples to be rejected")
+	}
+	result, err := Analyze(context.Background(), stabilityTestText, AnalysisOptions{Stability: 5}, AnalysisRun{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Stability == nil || result.Stability.Samples != 5 || result.Stability.Score != result.Grades.Score {
+		t.Errorf("stability = %+v", result.Stability)
+	}
+}
+
+func TestGradeRank(t *testing.T) {
+	order := []string{"A+", "A", "A-", "B+", "B", "C-", "D-", "F"}
+	for i := 1; i < len(order); i++ {
+		if gradeRank(order[i-1]) >= gradeRank(order[i]) {
+			t.Errorf("%s does not rank above %s", order[i-1], order[i])
+		}
+	}
+}
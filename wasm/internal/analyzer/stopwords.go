@@ -0,0 +1,133 @@
+package analyzer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// StopwordSet holds lowercase words ignored by keyword, concept and
+// stop-word-removal steps
+type StopwordSet map[string]bool
+
+// Contains reports whether word, in any case, is a stopword
+func (s StopwordSet) Contains(word string) bool {
+	return s[strings.ToLower(word)]
+}
+
+func newStopwordSet(lists ...[]string) StopwordSet {
+	set := StopwordSet{}
+	for _, list := range lists {
+		for _, word := range list {
+			set[strings.ToLower(word)] = true
+		}
+	}
+	return set
+}
+
+// languageStopwords are the built-in function-word lists, keyed by the
+// language codes detectLanguage reports
+var languageStopwords = map[string][]string{
+	"en": {
+		"a", "an", "and", "are", "as", "at", "be", "by", "for", "from", "has", "he",
+		"in", "is", "it", "its", "of", "on", "that", "the", "to", "was", "will", "with",
+		"but", "or", "so", "if", "when", "where", "why", "how", "what", "who", "which", "this",
+		"these", "those", "they", "them", "their", "we", "us", "our", "you", "your", "i",
+		"me", "my", "can", "could", "should", "would", "do", "does", "did", "have", "had", "been",
+		"being", "am", "were", "said", "say", "says",
+	},
+	"es": {
+		"el", "la", "los", "las", "un", "una", "unos", "unas", "y", "o", "pero", "de",
+		"del", "al", "a", "en", "con", "por", "para", "que", "se", "es", "son", "fue",
+		"ser", "está", "están", "lo", "le", "les", "su", "sus", "mi", "tu", "yo", "él",
+		"ella", "nosotros", "ellos", "este", "esta", "esto", "ese", "esa", "como", "más",
+		"si", "no", "ya", "muy", "también",
+	},
+	"fr": {
+		"le", "la", "les", "un", "une", "des", "du", "de", "et", "ou", "mais", "à",
+		"au", "aux", "en", "dans", "sur", "pour", "par", "avec", "que", "qui", "ce",
+		"cette", "ces", "est", "sont", "être", "il", "elle", "ils", "elles", "je", "tu",
+		"nous", "vous", "se", "son", "sa", "ses", "leur", "ne", "pas", "plus", "comme",
+		"si", "y",
+	},
+	"de": {
+		"der", "die", "das", "den", "dem", "des", "ein", "eine", "einen", "einem", "einer",
+		"und", "oder", "aber", "in", "im", "an", "auf", "mit", "von", "vom", "zu", "zum",
+		"zur", "für", "ist", "sind", "war", "sein", "es", "er", "sie", "wir", "ihr", "ich",
+		"du", "nicht", "auch", "als", "wie", "dass", "so", "noch", "nur", "sich",
+	},
+}
+
+// StopwordPacks are optional domain lists enabled by name through StopwordConfig.Packs
+var StopwordPacks = map[string][]string{
+	// Politeness and filler that says nothing about the task
+	"prompt": {
+		"please", "kindly", "thanks", "thank", "just", "basically", "actually", "simply",
+		"really", "very", "maybe", "perhaps", "want", "like", "need",
+	},
+	// Conversational framing around requests to a model
+	"chat": {
+		"hi", "hello", "hey", "assistant", "ai", "chatbot", "help", "let", "know",
+	},
+}
+
+// stopWords is the default English list used when no configuration applies
+var stopWords = newStopwordSet(languageStopwords["en"])
+
+// StopwordConfig customizes the stopword list for one analysis
+type StopwordConfig struct {
+	// Language picks a built-in list by code ("en", "es", "fr", "de"); empty
+	// uses the language detected in the text, falling back to English
+	Language string   `json:"language,omitempty"`
+	Packs    []string `json:"packs,omitempty"`  // Names from StopwordPacks
+	Add      []string `json:"add,omitempty"`    // Extra stopwords
+	Remove   []string `json:"remove,omitempty"` // Words to keep even if a list includes them
+}
+
+// Validate rejects unknown languages and packs
+func (c StopwordConfig) Validate() error {
+	if c.Language != "" {
+		if _, ok := languageStopwords[c.Language]; !ok {
+			return fmt.Errorf("unknown stopword language %q (expected one of %v)", c.Language, sortedKeys(languageStopwords))
+		}
+	}
+	for _, pack := range c.Packs {
+		if _, ok := StopwordPacks[pack]; !ok {
+			return fmt.Errorf("unknown stopword pack %q (expected one of %v)", pack, sortedKeys(StopwordPacks))
+		}
+	}
+	return nil
+}
+
+// Resolve builds the stopword set for text and returns it with the language used
+func (c StopwordConfig) Resolve(text string) (StopwordSet, string) {
+	language := c.Language
+	if language == "" {
+		language = detectLanguage(text).PrimaryLanguage
+	}
+	if _, ok := languageStopwords[language]; !ok {
+		language = "en"
+	}
+	if language == "en" && len(c.Packs) == 0 && len(c.Add) == 0 && len(c.Remove) == 0 {
+		return stopWords, language
+	}
+
+	lists := [][]string{languageStopwords[language], c.Add}
+	for _, pack := range c.Packs {
+		lists = append(lists, StopwordPacks[pack])
+	}
+	set := newStopwordSet(lists...)
+	for _, word := range c.Remove {
+		delete(set, strings.ToLower(word))
+	}
+	return set, language
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
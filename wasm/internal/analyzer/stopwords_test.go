@@ -0,0 +1,61 @@
+package analyzer
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestStopwordConfigResolve(t *testing.T) {
+	set, lang := StopwordConfig{}.Resolve("The cat and the dog are in the garden.")
+	if lang != "en" || !set.Contains("The") {
+		t.Errorf("expected the English defaults, got %q", lang)
+	}
+
+	_, lang = StopwordConfig{}.Resolve("El perro y la casa que está en el campo.")
+	if lang != "es" {
+		t.Errorf("expected Spanish to be detected, got %q", lang)
+	}
+
+	set, _ = StopwordConfig{Packs: []string{"prompt"}, Add: []string{"Acme"}, Remove: []string{"what"}}.Resolve("Please summarize what Acme does.")
+	for word, want := range map[string]bool{"please": true, "kindly": true, "acme": true, "what": false, "the": true} {
+		if set.Contains(word) != want {
+			t.Errorf("Contains(%q) = %v, want %v", word, !want, want)
+		}
+	}
+	if stopWords.Contains("please") {
+		t.Error("resolving a custom set must not modify the defaults")
+	}
+}
+
+func TestStopwordConfigValidate(t *testing.T) {
+	for _, cfg := range []StopwordConfig{{Language: "xx"}, {Packs: []string{"legal"}}} {
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("expected %+v to be rejected", cfg)
+		}
+	}
+	if _, err := ParseAnalysisOptions([]byte(`{"stopwords":{"language":"de","packs":["prompt","chat"]}}`)); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestAnalyzeAppliesStopwordPacks(t *testing.T) {
+	text := "Please kindly summarize the quarterly revenue report. Please kindly list the risks."
+	opts := AnalysisOptions{Stages: []string{StageTokens, StagePreprocessing}, Stopwords: StopwordConfig{Packs: []string{"prompt"}}}
+	result, err := Analyze(context.Background(), text, opts, AnalysisRun{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Tokens.StopwordLanguage != "en" {
+		t.Errorf("expected stopword language en, got %q", result.Tokens.StopwordLanguage)
+	}
+	stripped := result.Preprocessing.WithoutStopWords.Value
+	if strings.Contains(stripped, "please") || strings.Contains(stripped, "kindly") || !strings.Contains(stripped, "quarterly") {
+		t.Errorf("expected politeness words removed, got %q", stripped)
+	}
+	for _, tok := range result.Tokens.Tokens {
+		if strings.EqualFold(tok.Text, "kindly") && !tok.IsStopWord {
+			t.Error("expected pack words to be flagged as stopwords")
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Suggestion examples drawn from the prompt itself. Each returns ok=false
+// when the text has nothing to show, and the rule keeps its canned example.
+
+// maxExampleWords bounds how much of a user's sentence an example quotes
+const maxExampleWords = 24
+
+// pronounVerbs follow a demonstrative used as a pronoun ("This is slow")
+// rather than as a determiner ("This report is slow")
+var pronounVerbs = newStopwordSet([]string{
+	"is", "was", "are", "were", "will", "would", "should", "must", "can", "could", "may", "might",
+	"has", "have", "had", "does", "do", "did", "means", "needs", "includes", "seems", "looks",
+})
+
+// longSentenceSpans returns the sentences of text over maxWords words
+func longSentenceSpans(text string, maxWords int) []Span {
+	spans := []Span{}
+	for _, span := range locateSentences(text) {
+		if len(segmentWords(span.Text)) > maxWords {
+			spans = append(spans, span)
+		}
+	}
+	return spans
+}
+
+// splitSentenceExample shows the longest of the sentences split where
+// SimulateChanges would split it
+func splitSentenceExample(long []Span) (string, bool) {
+	longest, words := Span{}, 0
+	for _, span := range long {
+		if n := len(segmentWords(span.Text)); n > words {
+			longest, words = span, n
+		}
+	}
+	parts := splitSentence(longest.Text, longSentenceWords)
+	if len(parts) < 2 {
+		return "", false
+	}
+	for i, p := range parts {
+		parts[i] = ensureSentenceEnd(p)
+	}
+	return fmt.Sprintf("Your longest sentence (%d words) reads more easily as %d: '%s'", words, len(parts), strings.Join(parts, " ")), true
+}
+
+// pronounExample rewrites the vaguest pronoun in text with the noun phrase
+// it most likely refers to. Pronouns opening a sentence are the vaguest,
+// since the reader has to look back past a full stop; among equals the first
+// wins. The antecedent is the nearest run of content words before the
+// pronoun in its sentence, or for a pronoun opening a sentence, the
+// subject of the sentence before.
+func pronounExample(text string) (string, bool) {
+	sentences := locateSentences(text)
+	type candidate struct {
+		sentence   int
+		start, end int // Pronoun offsets in text
+		initial    bool
+		antecedent string
+	}
+	var best *candidate
+	for i, sentence := range sentences {
+		words := segmentWords(sentence.Text)
+		for j, w := range words {
+			switch strings.ToLower(sentence.Text[w.Start:w.End]) {
+			case "it", "they", "them":
+			case "this", "that", "these", "those":
+				// Only a pronoun when it opens the sentence and a verb or nothing follows
+				if j > 0 || (j+1 < len(words) && !pronounVerbs.Contains(sentence.Text[words[j+1].Start:words[j+1].End])) {
+					continue
+				}
+			default:
+				continue
+			}
+			start := sentence.Start + w.Start
+			antecedent := nearestNounPhrase(text[sentence.Start:start])
+			if j == 0 && i > 0 {
+				antecedent = subjectNounPhrase(sentences[i-1].Text)
+			}
+			if antecedent == "" {
+				continue
+			}
+			if best == nil || (j == 0 && !best.initial) {
+				best = &candidate{sentence: i, start: start, end: sentence.Start + w.End, initial: j == 0, antecedent: antecedent}
+			}
+		}
+	}
+	if best == nil {
+		return "", false
+	}
+
+	sentence := sentences[best.sentence]
+	replacement := "the " + best.antecedent
+	if best.initial {
+		replacement = capitalizeFirst(replacement)
+	}
+	before := text[sentence.Start:sentence.End]
+	after := text[sentence.Start:best.start] + replacement + text[best.end:sentence.End]
+	return fmt.Sprintf("'%s' -> '%s'", exampleExcerpt(before), exampleExcerpt(after)), true
+}
+
+// determiners open a noun phrase, so a word before one is a verb
+var determiners = newStopwordSet([]string{"the", "a", "an", "our", "your", "my", "their", "its", "this", "that", "these", "those", "all", "each", "every"})
+
+// contentRuns splits text into runs of consecutive content words;
+// punctuation between two words ends a run
+func contentRuns(text string) [][]wordSegment {
+	var runs [][]wordSegment
+	var run []wordSegment
+	for _, seg := range segmentWords(text) {
+		if !isContentWord(text[seg.Start:seg.End]) {
+			if len(run) > 0 {
+				runs, run = append(runs, run), nil
+			}
+			continue
+		}
+		if len(run) > 0 && strings.ContainsAny(text[run[len(run)-1].End:seg.Start], ",.;:!?()\n") {
+			runs, run = append(runs, run), nil
+		}
+		run = append(run, seg)
+	}
+	if len(run) > 0 {
+		runs = append(runs, run)
+	}
+	return runs
+}
+
+// nearestNounPhrase returns up to three words ending the last content run of
+// context, as written: the likely antecedent of a pronoun inside a sentence
+func nearestNounPhrase(context string) string {
+	runs := contentRuns(context)
+	if n := len(runs); n > 0 && strings.TrimSpace(context[runs[n-1][len(runs[n-1])-1].End:]) == "" {
+		// The word right before the pronoun governs it ("validate it")
+		if runs[n-1] = runs[n-1][:len(runs[n-1])-1]; len(runs[n-1]) == 0 {
+			runs = runs[:n-1]
+		}
+	}
+	if len(runs) == 0 {
+		return ""
+	}
+	run := runs[len(runs)-1]
+	if len(run) > 3 {
+		run = run[len(run)-3:]
+	}
+	return context[run[0].Start:run[len(run)-1].End]
+}
+
+// subjectNounPhrase returns up to three words of the first content run of
+// sentence, cut before a word that looks like its verb ("billing service
+// sends invoices"): the likely antecedent of a pronoun opening the next
+// sentence
+func subjectNounPhrase(sentence string) string {
+	runs := contentRuns(sentence)
+	if words := segmentWords(sentence); len(runs) > 0 && len(words) > 1 && runs[0][0] == words[0] &&
+		determiners.Contains(sentence[words[1].Start:words[1].End]) {
+		// An imperative ("Check the deploy script"): its object is the topic
+		if runs[0] = runs[0][1:]; len(runs[0]) == 0 {
+			runs = runs[1:]
+		}
+	}
+	if len(runs) == 0 {
+		return ""
+	}
+	run := runs[0]
+	for i := 1; i < len(run); i++ {
+		word := strings.ToLower(sentence[run[i].Start:run[i].End])
+		if commonVerbs[word] || (i+1 < len(run) && strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss") && !strings.HasSuffix(word, "'s")) {
+			run = run[:i]
+			break
+		}
+	}
+	if len(run) > 3 {
+		run = run[:3]
+	}
+	return sentence[run[0].Start:run[len(run)-1].End]
+}
+
+// isContentWord reports whether word can head a noun phrase: not a
+// stopword, pronoun, intent verb, adverb or participle
+func isContentWord(word string) bool {
+	lower := strings.ToLower(word)
+	_, verb := goalIntents[lower]
+	return len(lower) >= 3 && !isStopWord(lower) && !labelBreakWords.Contains(lower) && !topicFillers.Contains(lower) &&
+		!vaguePronouns[lower] && !pronounVerbs.Contains(lower) && !verb &&
+		!strings.HasSuffix(lower, "ly") && !strings.HasSuffix(lower, "ed") && strings.ContainsFunc(word, unicode.IsLetter)
+}
+
+// outputSpecExample appends an output specification to the prompt's first
+// instruction, the sentence a reader would extend
+func outputSpecExample(text string) (string, bool) {
+	for _, sentence := range locateSentences(text) {
+		words := segmentWords(sentence.Text)
+		if len(words) == 0 {
+			continue
+		}
+		if _, ok := goalIntents[strings.ToLower(sentence.Text[words[0].Start:words[0].End])]; !ok {
+			continue
+		}
+		return fmt.Sprintf("'%s Input: <what you will provide>. Output: <format and length>. Done when: <success criterion>.'", exampleExcerpt(ensureSentenceEnd(sentence.Text))), true
+	}
+	return "", false
+}
+
+// jargonExample shows the first undefined term defined inline
+func jargonExample(terms []string) (string, bool) {
+	if len(terms) == 0 {
+		return "", false
+	}
+	return fmt.Sprintf("'%s (<what it stands for>): <what it means in this prompt>.' Or register the terms in a glossary.", terms[0]), true
+}
+
+// exampleExcerpt shortens a quoted sentence to maxExampleWords words
+func exampleExcerpt(sentence string) string {
+	words := segmentWords(sentence)
+	if len(words) <= maxExampleWords {
+		return sentence
+	}
+	return strings.TrimRight(sentence[:words[maxExampleWords-1].End], " ,;:") + "…"
+}
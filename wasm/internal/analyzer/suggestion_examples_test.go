@@ -0,0 +1,47 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPronounExample(t *testing.T) {
+	for _, tc := range []struct{ text, want string }{
+		{"Our billing service sends invoices every night. It keeps timing out when the queue is large.", "'The billing service keeps timing out when the queue is large.'"},
+		{"Check the deploy script. This is broken on staging.", "'The deploy script is broken on staging.'"},
+		{"Open the config file and validate it before the release.", "'Open the config file and validate the config file before the release.'"},
+	} {
+		got, ok := pronounExample(tc.text)
+		if !ok || !strings.HasSuffix(got, " -> "+tc.want) {
+			t.Errorf("pronounExample(%q) = %q, want ... -> %s", tc.text, got, tc.want)
+		}
+	}
+	// A demonstrative followed by a noun is a determiner, not a pronoun
+	if got, ok := pronounExample("Check the deploy script. This script fails on staging."); ok {
+		t.Errorf("determiner resolved: %q", got)
+	}
+}
+
+func TestSuggestionExamplesQuoteThePrompt(t *testing.T) {
+	text := "Our billing service sends invoices every night. It keeps timing out when the queue is large. Review the customer's last three invoices, compare them against the pricing plan they signed up for, flag any charges that do not match the plan, and write a short explanation of each mismatch that a non-technical customer could follow."
+	examples := map[string]string{}
+	for _, s := range GradePromptText(text).Suggestions {
+		examples[s.Rule] = s.Example
+	}
+	if ex := examples["FUL024"]; !strings.Contains(ex, "(39 words)") || !strings.Contains(ex, "signed up for. Flag any charges") {
+		t.Errorf("FUL024 example = %q", ex)
+	}
+	if ex := examples["FUL001"]; ex != "" && !strings.Contains(ex, "Review the customer's") {
+		t.Errorf("FUL001 example does not quote the instruction: %q", ex)
+	}
+
+	if _, ok := splitSentenceExample(longSentenceSpans("Fix the bug.", longSentenceWords)); ok {
+		t.Error("split example for a text without long sentences")
+	}
+	if _, ok := outputSpecExample("The parser is slow."); ok {
+		t.Error("output example without an instruction")
+	}
+	if got := exampleExcerpt(strings.Repeat("word ", 40)); !strings.HasSuffix(got, "…") || len(segmentWords(got)) != maxExampleWords {
+		t.Errorf("excerpt = %q", got)
+	}
+}
@@ -0,0 +1,122 @@
+package analyzer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// SuggestionRule describes one suggestion generator. IDs are stable across
+// releases so teams can disable or reprioritize rules by code, like a linter.
+type SuggestionRule struct {
+	ID        string `json:"id"`
+	Dimension string `json:"dimension"`
+	Priority  string `json:"priority"` // Default priority
+	Summary   string `json:"summary"`
+}
+
+// SuggestionRules lists every rule in ID order. FUL001-FUL019 come from the
+// prompt grade, as do FUL024 and FUL025; FUL020-FUL023 from the modern grader.
+var SuggestionRules = []SuggestionRule{
+	{"FUL001", "Specificity", "high", "Specify exact inputs, outputs and success criteria"},
+	{"FUL002", "Actionability", "high", "List concrete deliverables or steps"},
+	{"FUL003", "Structure", "medium", "Organize the prompt into sections"},
+	{"FUL004", "Context", "medium", "Provide domain context and environment"},
+	{"FUL005", "Context", "medium", "State non-functional requirements"},
+	{"FUL006", "Specificity", "high", "Define interface shapes or schemas"},
+	{"FUL007", "Scope", "high", "Split complex work into phases"},
+	{"FUL008", "Quality", "medium", "Ask for tests, examples and observability"},
+	{"FUL009", "Data", "high", "List dataset fields, time window and filters"},
+	{"FUL010", "Methodology", "medium", "Specify analysis methods and outputs"},
+	{"FUL011", "Clarity", "medium", "Turn open questions into analytical tasks"},
+	{"FUL012", "Brief", "high", "Define audience, tone and style"},
+	{"FUL013", "Examples", "medium", "Provide reference examples"},
+	{"FUL014", "Objectives", "high", "Set learning objectives and a timeline"},
+	{"FUL015", "Format", "medium", "Request a curriculum with exercises"},
+	{"FUL016", "Clarification", "high", "Add clarifying questions to answer first"},
+	{"FUL017", "Specificity", "medium", "Replace pronouns with specific nouns"},
+	{"FUL018", "Actionability", "medium", "Ask for a task list before implementation"},
+	{"FUL019", "Context", "medium", "Define jargon and acronyms"},
+	{"FUL020", "Specificity", "high", "Be more specific about inputs and outputs"},
+	{"FUL021", "Completeness", "high", "Fill missing requirements"},
+	{"FUL022", "Context", "medium", "Provide technical context and constraints"},
+	{"FUL023", "Actionability", "medium", "Add step-by-step deliverables"},
+	{"FUL024", "Clarity", "medium", "Break up long sentences"},
+	{"FUL025", "Clarity", "high", "Fix malformed JSON, YAML or XML payloads"},
+}
+
+// SuggestionRuleConfig disables rules or overrides their priority by ID
+type SuggestionRuleConfig struct {
+	Disabled   []string          `json:"disabled,omitempty"`
+	Priorities map[string]string `json:"priorities,omitempty"` // Rule ID -> priority
+}
+
+// LoadSuggestionRuleConfig parses a JSON rule config, rejecting unknown fields and rule IDs
+func LoadSuggestionRuleConfig(data []byte) (SuggestionRuleConfig, error) {
+	var cfg SuggestionRuleConfig
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&cfg); err != nil {
+		return SuggestionRuleConfig{}, fmt.Errorf("invalid rule config: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return SuggestionRuleConfig{}, err
+	}
+	return cfg, nil
+}
+
+// Validate rejects unknown rule IDs and priorities
+func (cfg SuggestionRuleConfig) Validate() error {
+	for _, id := range cfg.Disabled {
+		if !isSuggestionRule(id) {
+			return fmt.Errorf("unknown suggestion rule %q", id)
+		}
+	}
+	for id, priority := range cfg.Priorities {
+		if !isSuggestionRule(id) {
+			return fmt.Errorf("unknown suggestion rule %q", id)
+		}
+		if !validPriorities[priority] {
+			return fmt.Errorf("invalid priority %q for rule %s", priority, id)
+		}
+	}
+	return nil
+}
+
+// enabled reports whether a rule may produce suggestions
+func (cfg SuggestionRuleConfig) enabled(id string) bool {
+	return !contains(cfg.Disabled, id)
+}
+
+// priority returns the configured priority for a rule, or fallback
+func (cfg SuggestionRuleConfig) priority(id, fallback string) string {
+	if p, ok := cfg.Priorities[id]; ok {
+		return p
+	}
+	return fallback
+}
+
+// over layers cfg on top of base: base's disabled rules stay disabled, and
+// cfg's priorities win
+func (cfg SuggestionRuleConfig) over(base SuggestionRuleConfig) SuggestionRuleConfig {
+	if len(base.Disabled) == 0 && len(base.Priorities) == 0 {
+		return cfg
+	}
+	merged := SuggestionRuleConfig{Disabled: append(append([]string{}, base.Disabled...), cfg.Disabled...), Priorities: map[string]string{}}
+	for id, p := range base.Priorities {
+		merged.Priorities[id] = p
+	}
+	for id, p := range cfg.Priorities {
+		merged.Priorities[id] = p
+	}
+	return merged
+}
+
+func isSuggestionRule(id string) bool {
+	for _, rule := range SuggestionRules {
+		if rule.ID == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,33 @@
+package analyzer
+
+import "testing"
+
+// TestSuggestionRules checks rule IDs are stable and that rules can be disabled or reprioritized
+func TestSuggestionRules(t *testing.T) {
+	text := "Build an API. It should handle users and it should be fast."
+	for _, s := range GradePromptText(text).Suggestions {
+		if !isSuggestionRule(s.Rule) {
+			t.Errorf("suggestion %q has unknown rule %q", s.Message, s.Rule)
+		}
+	}
+
+	cfg, err := LoadSuggestionRuleConfig([]byte(`{"disabled": ["FUL001"], "priorities": {"FUL002": "low"}}`))
+	if err != nil {
+		t.Fatalf("failed to load rule config: %v", err)
+	}
+	grade := &PromptGrade{Specificity: GradeDimension{Score: 10}, Actionability: GradeDimension{Score: 10}}
+	for _, s := range generateSuggestions(grade, text, TokenData{}, IdeaAnalysisMetrics{}, TaskGraph{}, NewPromptClassifier().ClassifyPrompt(text).PrimaryType, cfg) {
+		if s.Rule == "FUL001" {
+			t.Error("expected FUL001 to be disabled")
+		}
+		if s.Rule == "FUL002" && s.Priority != "low" {
+			t.Errorf("expected FUL002 priority low, got %s", s.Priority)
+		}
+	}
+
+	for _, bad := range []string{`{"disabled": ["FUL999"]}`, `{"priorities": {"FUL001": "urgent"}}`} {
+		if _, err := LoadSuggestionRuleConfig([]byte(bad)); err == nil {
+			t.Errorf("expected error for %s", bad)
+		}
+	}
+}
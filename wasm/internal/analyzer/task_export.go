@@ -0,0 +1,226 @@
+package analyzer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// GitHubIssue is a single issue ready for the GitHub REST API
+type GitHubIssue struct {
+	Title     string   `json:"title"`
+	Body      string   `json:"body"`
+	Labels    []string `json:"labels"`
+	Assignees []string `json:"assignees"`
+}
+
+// JiraBulkImport is the top-level document accepted by Jira's JSON importer
+type JiraBulkImport struct {
+	Projects []JiraProject `json:"projects"`
+	Links    []JiraLink    `json:"links"`
+}
+
+// JiraProject groups imported issues under a project key
+type JiraProject struct {
+	Key    string      `json:"key"`
+	Issues []JiraIssue `json:"issues"`
+}
+
+// JiraIssue is a single issue in a Jira bulk import
+type JiraIssue struct {
+	ExternalID  string   `json:"externalId"`
+	Summary     string   `json:"summary"`
+	Description string   `json:"description"`
+	IssueType   string   `json:"issueType"`
+	Priority    string   `json:"priority"`
+	Status      string   `json:"status"`
+	Labels      []string `json:"labels"`
+	DueDate     string   `json:"duedate,omitempty"`
+}
+
+// JiraLink connects two imported issues
+type JiraLink struct {
+	Name          string `json:"name"`
+	SourceID      string `json:"sourceId"`
+	DestinationID string `json:"destinationId"`
+}
+
+// ExportGitHubIssues converts top-level tasks into issues with subtask checklists
+func ExportGitHubIssues(graph *TaskGraph) []GitHubIssue {
+	issues := []GitHubIssue{}
+	if graph == nil {
+		return issues
+	}
+
+	for _, task := range graph.TopLevelTasks() {
+		var body strings.Builder
+		body.WriteString(task.Description)
+		body.WriteString("\n")
+
+		if descendants := graph.Descendants(task.ID); len(descendants) > 0 {
+			body.WriteString("\n### Subtasks\n")
+			for _, sub := range descendants {
+				depth := len(graph.Ancestors(sub.ID)) - 1
+				fmt.Fprintf(&body, "%s- [ ] %s\n", strings.Repeat("  ", depth), sub.Title)
+			}
+		}
+
+		if len(task.DependsOn) > 0 {
+			body.WriteString("\n### Depends on\n")
+			for _, id := range task.DependsOn {
+				if dep, ok := graph.GetTask(id); ok {
+					fmt.Fprintf(&body, "- %s\n", dep.Title)
+				}
+			}
+		}
+
+		body.WriteString("\n---\n")
+		fmt.Fprintf(&body, "Priority: %s · Effort: %s", task.Priority, task.EstimatedEffort)
+		if deadline := taskDeadline(task); deadline != "" {
+			fmt.Fprintf(&body, " · Due: %s", deadline)
+		}
+		body.WriteString("\n")
+
+		assignees := []string{}
+		if task.ActorType == "named" && !strings.Contains(task.Actor, " ") {
+			assignees = append(assignees, task.Actor)
+		}
+
+		issues = append(issues, GitHubIssue{
+			Title:     task.Title,
+			Body:      body.String(),
+			Labels:    taskLabels(task),
+			Assignees: assignees,
+		})
+	}
+
+	return issues
+}
+
+// ExportJiraBulkJSON converts the task graph into Jira JSON importer format
+func ExportJiraBulkJSON(graph *TaskGraph, projectKey string) ([]byte, error) {
+	doc := JiraBulkImport{
+		Projects: []JiraProject{{Key: projectKey, Issues: []JiraIssue{}}},
+		Links:    []JiraLink{},
+	}
+
+	if graph != nil {
+		for _, task := range graph.Tasks {
+			issueType := "Task"
+			if task.ParentID != "" {
+				issueType = "Sub-task"
+			}
+			doc.Projects[0].Issues = append(doc.Projects[0].Issues, JiraIssue{
+				ExternalID:  task.ID,
+				Summary:     task.Title,
+				Description: task.Description,
+				IssueType:   issueType,
+				Priority:    jiraPriority(task.Priority),
+				Status:      "To Do",
+				Labels:      taskLabels(task),
+				DueDate:     jiraDueDate(task),
+			})
+
+			if task.ParentID != "" {
+				doc.Links = append(doc.Links, JiraLink{Name: "sub-task-link", SourceID: task.ID, DestinationID: task.ParentID})
+			}
+			for _, dep := range task.DependsOn {
+				doc.Links = append(doc.Links, JiraLink{Name: "Blocks", SourceID: dep, DestinationID: task.ID})
+			}
+		}
+	}
+
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// ExportTasksCSV renders one row per task for spreadsheet or tracker import
+func ExportTasksCSV(graph *TaskGraph) (string, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	header := []string{"id", "title", "description", "type", "priority", "status", "effort", "assignee", "parent_id", "depends_on", "deadline"}
+	if err := w.Write(header); err != nil {
+		return "", err
+	}
+
+	if graph != nil {
+		for _, task := range graph.Tasks {
+			row := []string{
+				task.ID,
+				task.Title,
+				task.Description,
+				task.Type,
+				task.Priority,
+				task.Status,
+				task.EstimatedEffort,
+				task.Actor,
+				task.ParentID,
+				strings.Join(task.DependsOn, ";"),
+				taskDeadline(task),
+			}
+			if err := w.Write(row); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// taskLabels builds tracker labels from task type and effort
+func taskLabels(task Task) []string {
+	labels := []string{}
+	if task.Type != "" {
+		labels = append(labels, task.Type)
+	}
+	if task.EstimatedEffort != "" {
+		labels = append(labels, "effort:"+task.EstimatedEffort)
+	}
+	return labels
+}
+
+// taskDeadline returns the first deadline constraint on a task
+func taskDeadline(task Task) string {
+	for _, c := range task.Constraints {
+		if c.Type == "deadline" {
+			return c.Value
+		}
+	}
+	return ""
+}
+
+// jiraPriority maps task priority onto Jira's default priority scheme
+func jiraPriority(priority string) string {
+	switch priority {
+	case "high":
+		return "High"
+	case "low":
+		return "Low"
+	default:
+		return "Medium"
+	}
+}
+
+// jiraDueDate returns the task deadline when it is or resolved to a calendar date
+func jiraDueDate(task Task) string {
+	deadline := taskDeadline(task)
+	for _, c := range task.Constraints {
+		if c.Type == "deadline" {
+			if c.Resolved != "" {
+				deadline = c.Resolved
+			}
+			break
+		}
+	}
+	if _, err := time.Parse("2006-01-02", deadline); err != nil {
+		return ""
+	}
+	return deadline
+}
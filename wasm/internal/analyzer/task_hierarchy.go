@@ -0,0 +1,239 @@
+package analyzer
+
+import (
+	"regexp"
+	"strings"
+)
+
+// listItemPattern matches numbered ("1.", "1.2)", "a.") and bulleted ("-", "*", "•") list items
+var listItemPattern = regexp.MustCompile(`^([ \t]*)(?:(\d+(?:\.\d+)+|\d+)[.)]|(\d+(?:\.\d+)+)|([a-zA-Z])[.)]|[-*+•])\s+`)
+
+// listNumberPattern matches a line holding only a list number such as "2" or "1.2"
+var listNumberPattern = regexp.MustCompile(`^\s*\d+(?:\.\d+)*\.?\s*$`)
+
+// listItem is a single line of a list in the original text
+type listItem struct {
+	start  int    // Offset of the line start in the text
+	end    int    // Offset of the line end in the text
+	indent int    // Indentation width (tabs count as 4)
+	number string // Dotted number for numbered items, e.g. "1.2"
+	parent int    // Index of the parent item, -1 for top level
+}
+
+// parseListItems scans the text line by line and links nested list items to their parents
+func parseListItems(text string) []listItem {
+	var items []listItem
+	var stack []int
+
+	offset := 0
+	for _, line := range strings.Split(text, "\n") {
+		start, end := offset, offset+len(line)
+		offset = end + 1
+
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+
+		m := listItemPattern.FindStringSubmatch(line)
+		if m == nil {
+			// Plain text ends the current list; a trailing colon may introduce the next one
+			stack = stack[:0]
+			if strings.HasSuffix(trimmed, ":") {
+				items = append(items, listItem{start: start, end: end, indent: -1, parent: -1})
+				stack = append(stack, len(items)-1)
+			}
+			continue
+		}
+
+		item := listItem{
+			start:  start,
+			end:    end,
+			indent: strings.Count(m[1], " ") + 4*strings.Count(m[1], "\t"),
+			number: m[2] + m[3],
+			parent: -1,
+		}
+
+		for len(stack) > 0 && !isListAncestor(items[stack[len(stack)-1]], item) {
+			stack = stack[:len(stack)-1]
+		}
+		if len(stack) > 0 {
+			item.parent = stack[len(stack)-1]
+		}
+
+		items = append(items, item)
+		stack = append(stack, len(items)-1)
+	}
+
+	return items
+}
+
+// isListAncestor reports whether candidate can contain item in the list hierarchy
+func isListAncestor(candidate, item listItem) bool {
+	if candidate.indent < item.indent {
+		return true
+	}
+	return candidate.indent == item.indent && candidate.number != "" && item.number != "" &&
+		strings.HasPrefix(item.number, candidate.number+".")
+}
+
+// splitListSentences breaks sentences that span several list lines into one sentence per item
+func splitListSentences(sentences []string) []string {
+	result := make([]string, 0, len(sentences))
+	for _, sentence := range sentences {
+		if !strings.Contains(sentence, "\n") {
+			result = append(result, sentence)
+			continue
+		}
+
+		current := []string{}
+		flush := func() {
+			if piece := strings.TrimSpace(strings.Join(current, "\n")); piece != "" {
+				result = append(result, piece)
+			}
+			current = current[:0]
+		}
+		for _, line := range strings.Split(sentence, "\n") {
+			// Bare list numbers are left behind when sentence splitting cuts at "2. "
+			if listNumberPattern.MatchString(line) {
+				continue
+			}
+			if listItemPattern.MatchString(line) {
+				flush()
+			}
+			current = append(current, line)
+		}
+		flush()
+	}
+	return result
+}
+
+// assignListHierarchy sets ParentID from list structure and returns the subtask relationships
+func assignListHierarchy(text string, tasks []Task) []TaskRelationship {
+	relationships := []TaskRelationship{}
+	items := parseListItems(text)
+	if len(items) == 0 || len(tasks) == 0 {
+		return relationships
+	}
+
+	// Map each list item to the first task that starts on its line
+	itemTask := make([]int, len(items))
+	for i, item := range items {
+		itemTask[i] = -1
+		for t, task := range tasks {
+			if task.TextPosition.StartChar >= item.start && task.TextPosition.StartChar <= item.end {
+				itemTask[i] = t
+				break
+			}
+		}
+	}
+
+	for i, item := range items {
+		child := itemTask[i]
+		if child < 0 || tasks[child].ParentID != "" {
+			continue
+		}
+
+		// Walk up past list items that did not produce a task
+		parent := -1
+		for p := item.parent; p >= 0; p = items[p].parent {
+			if itemTask[p] >= 0 && itemTask[p] != child {
+				parent = itemTask[p]
+				break
+			}
+		}
+		if parent < 0 {
+			continue
+		}
+
+		tasks[child].ParentID = tasks[parent].ID
+		tasks[parent].ChildIDs = append(tasks[parent].ChildIDs, tasks[child].ID)
+		relationships = append(relationships, TaskRelationship{
+			FromTaskID:   tasks[parent].ID,
+			ToTaskID:     tasks[child].ID,
+			RelationType: "subtask",
+			Strength:     0.9,
+			Reason:       "List hierarchy",
+		})
+	}
+
+	return relationships
+}
+
+// GetTask returns the task with the given ID
+func (g *TaskGraph) GetTask(id string) (*Task, bool) {
+	for i := range g.Tasks {
+		if g.Tasks[i].ID == id {
+			return &g.Tasks[i], true
+		}
+	}
+	return nil, false
+}
+
+// TopLevelTasks returns tasks that have no parent in the hierarchy
+func (g *TaskGraph) TopLevelTasks() []Task {
+	tasks := []Task{}
+	for _, task := range g.Tasks {
+		if task.ParentID == "" {
+			tasks = append(tasks, task)
+		}
+	}
+	return tasks
+}
+
+// Children returns the direct subtasks of a task
+func (g *TaskGraph) Children(id string) []Task {
+	children := []Task{}
+	for _, task := range g.Tasks {
+		if task.ParentID == id {
+			children = append(children, task)
+		}
+	}
+	return children
+}
+
+// Ancestors returns the chain of parents of a task, nearest first
+func (g *TaskGraph) Ancestors(id string) []Task {
+	ancestors := []Task{}
+	seen := map[string]bool{id: true}
+	task, ok := g.GetTask(id)
+	for ok && task.ParentID != "" && !seen[task.ParentID] {
+		seen[task.ParentID] = true
+		task, ok = g.GetTask(task.ParentID)
+		if ok {
+			ancestors = append(ancestors, *task)
+		}
+	}
+	return ancestors
+}
+
+// Descendants returns all subtasks below a task in depth-first order
+func (g *TaskGraph) Descendants(id string) []Task {
+	descendants := []Task{}
+	g.walk(id, 0, map[string]bool{id: true}, func(task Task, depth int) {
+		descendants = append(descendants, task)
+	})
+	return descendants
+}
+
+// WalkTree visits every task depth-first starting from the top-level tasks
+func (g *TaskGraph) WalkTree(visit func(task Task, depth int)) {
+	seen := make(map[string]bool)
+	for _, task := range g.TopLevelTasks() {
+		seen[task.ID] = true
+		visit(task, 0)
+		g.walk(task.ID, 1, seen, visit)
+	}
+}
+
+// walk visits the children of id recursively
+func (g *TaskGraph) walk(id string, depth int, seen map[string]bool, visit func(task Task, depth int)) {
+	for _, child := range g.Children(id) {
+		if seen[child.ID] {
+			continue
+		}
+		seen[child.ID] = true
+		visit(child, depth)
+		g.walk(child.ID, depth+1, seen, visit)
+	}
+}
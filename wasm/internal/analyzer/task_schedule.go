@@ -0,0 +1,335 @@
+package analyzer
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TemporalConstraint represents a scheduling hint found in a task's sentence
+type TemporalConstraint struct {
+	Type      string `json:"type"`  // "deadline", "duration", "after", "before"
+	Text      string `json:"text"`  // Matched phrase, e.g. "by Friday"
+	Value     string `json:"value"` // Normalized value, e.g. "friday", "P2W", "3"
+	Resolved  string `json:"resolved,omitempty"` // Deadline as an ISO 8601 date, e.g. "2025-03-07"
+	StartChar int    `json:"start_char"`
+	EndChar   int    `json:"end_char"`
+}
+
+// TaskSchedule holds the computed ordering window for a task
+type TaskSchedule struct {
+	TaskID        string `json:"task_id"`
+	EarliestStart int    `json:"earliest_start"` // Earliest ordering slot (0-based)
+	LatestStart   int    `json:"latest_start"`   // Latest slot without delaying the graph
+	Slack         int    `json:"slack"`
+	Deadline      string `json:"deadline,omitempty"`
+	Duration      string `json:"duration,omitempty"`
+}
+
+var (
+	deadlinePattern = regexp.MustCompile(`(?i)\b(by|until|due(?:\s+(?:on|by))?|no later than)\s+(` +
+		`(?:next\s+)?(?:monday|tuesday|wednesday|thursday|friday|saturday|sunday)|` +
+		`today|tonight|tomorrow|eod|eow|` +
+		`(?:the\s+)?end\s+of\s+(?:the\s+)?(?:day|week|month|quarter|year)|` +
+		`next\s+(?:week|month|quarter|year)|` +
+		`(?:jan|feb|mar|apr|may|jun|jul|aug|sep|sept|oct|nov|dec)[a-z]*\.?\s+\d{1,2}(?:st|nd|rd|th)?|` +
+		`\d{4}-\d{2}-\d{2}|\d{1,2}/\d{1,2}(?:/\d{2,4})?)\b`)
+
+	durationPattern = regexp.MustCompile(`(?i)\b(within|in|for|over|takes?|lasting)\s+` +
+		`(\d+|an?|one|two|three|four|five|six|seven|eight|nine|ten|a\s+couple\s+of|a\s+few)\s+` +
+		`(minute|hour|day|week|month|year)s?\b`)
+
+	orderingPattern = regexp.MustCompile(`(?i)\b(after|before|following|once)\s+(?:completing\s+)?(?:step|task|phase|item)\s+#?(\d+)\b`)
+
+	stepPrefixPattern = regexp.MustCompile(`(?i)^\s*(?:step\s+)?(\d+)[.):]`)
+)
+
+var durationWords = map[string]int{
+	"a": 1, "an": 1, "one": 1, "two": 2, "three": 3, "four": 4, "five": 5,
+	"six": 6, "seven": 7, "eight": 8, "nine": 9, "ten": 10,
+	"a couple of": 2, "a few": 3,
+}
+
+// extractTemporalConstraints finds deadlines, durations and explicit ordering in a sentence
+func extractTemporalConstraints(sentence string, startChar int) []TemporalConstraint {
+	constraints := []TemporalConstraint{}
+
+	for _, m := range orderingPattern.FindAllStringSubmatchIndex(sentence, -1) {
+		kind := strings.ToLower(sentence[m[2]:m[3]])
+		if kind != "before" {
+			kind = "after"
+		}
+		constraints = append(constraints, TemporalConstraint{
+			Type:      kind,
+			Text:      sentence[m[0]:m[1]],
+			Value:     sentence[m[4]:m[5]],
+			StartChar: startChar + m[0],
+			EndChar:   startChar + m[1],
+		})
+	}
+
+	for _, m := range deadlinePattern.FindAllStringSubmatchIndex(sentence, -1) {
+		value := strings.ToLower(strings.Join(strings.Fields(sentence[m[4]:m[5]]), " "))
+		constraints = append(constraints, TemporalConstraint{
+			Type:      "deadline",
+			Text:      sentence[m[0]:m[1]],
+			Value:     value,
+			StartChar: startChar + m[0],
+			EndChar:   startChar + m[1],
+		})
+	}
+
+	for _, m := range durationPattern.FindAllStringSubmatchIndex(sentence, -1) {
+		amount := strings.ToLower(strings.Join(strings.Fields(sentence[m[4]:m[5]]), " "))
+		unit := strings.ToLower(sentence[m[6]:m[7]])
+		constraints = append(constraints, TemporalConstraint{
+			Type:      "duration",
+			Text:      sentence[m[0]:m[1]],
+			Value:     isoDuration(amount, unit),
+			StartChar: startChar + m[0],
+			EndChar:   startChar + m[1],
+		})
+	}
+
+	return constraints
+}
+
+// resolveDeadlines dates each deadline against ref. Absolute dates resolve
+// without one; relative ones such as "by Friday" need a non-zero ref.
+func (g *TaskGraph) resolveDeadlines(ref time.Time) {
+	for i := range g.Tasks {
+		for j := range g.Tasks[i].Constraints {
+			c := &g.Tasks[i].Constraints[j]
+			if c.Type != "deadline" {
+				continue
+			}
+			// The phrase ends with the date; "by", "due on" and the like precede it
+			found := ExtractTemporalExpressions(c.Text, ref)
+			if n := len(found); n > 0 && found[n-1].End == len(c.Text) && !strings.HasPrefix(found[n-1].Value, "--") {
+				c.Resolved = found[n-1].Value
+			}
+		}
+	}
+}
+
+// isoDuration converts an amount and unit into an ISO 8601 duration
+func isoDuration(amount, unit string) string {
+	n, err := strconv.Atoi(amount)
+	if err != nil {
+		n = durationWords[amount]
+	}
+	if n == 0 {
+		n = 1
+	}
+
+	switch unit {
+	case "minute":
+		return fmt.Sprintf("PT%dM", n)
+	case "hour":
+		return fmt.Sprintf("PT%dH", n)
+	case "day":
+		return fmt.Sprintf("P%dD", n)
+	case "week":
+		return fmt.Sprintf("P%dW", n)
+	case "month":
+		return fmt.Sprintf("P%dM", n)
+	default:
+		return fmt.Sprintf("P%dY", n)
+	}
+}
+
+// applyOrderingConstraints turns "after step 3" style constraints into dependencies
+func applyOrderingConstraints(tasks []Task, relationships []TaskRelationship) []TaskRelationship {
+	for i := range tasks {
+		for _, c := range tasks[i].Constraints {
+			if c.Type != "after" && c.Type != "before" {
+				continue
+			}
+
+			n, err := strconv.Atoi(c.Value)
+			if err != nil {
+				continue
+			}
+			j := findStepTask(tasks, n)
+			if j < 0 || j == i {
+				continue
+			}
+
+			from, to := j, i
+			if c.Type == "before" {
+				from, to = i, j
+			}
+			if contains(tasks[to].DependsOn, tasks[from].ID) || dependsTransitively(tasks, from, tasks[to].ID) {
+				continue
+			}
+
+			tasks[to].DependsOn = append(tasks[to].DependsOn, tasks[from].ID)
+			tasks[from].Blocks = append(tasks[from].Blocks, tasks[to].ID)
+			relationships = append(relationships, TaskRelationship{
+				FromTaskID:   tasks[from].ID,
+				ToTaskID:     tasks[to].ID,
+				RelationType: "depends_on",
+				Strength:     0.9,
+				Reason:       fmt.Sprintf("Explicit ordering constraint (%s)", c.Text),
+			})
+		}
+	}
+
+	return relationships
+}
+
+// findStepTask locates the task for "step n", preferring numbered list items
+func findStepTask(tasks []Task, n int) int {
+	for i, task := range tasks {
+		if m := stepPrefixPattern.FindStringSubmatch(task.SourceText); m != nil && m[1] == strconv.Itoa(n) {
+			return i
+		}
+	}
+	if n >= 1 && n <= len(tasks) {
+		return n - 1
+	}
+	return -1
+}
+
+// dependsTransitively reports whether tasks[idx] already depends on targetID
+func dependsTransitively(tasks []Task, idx int, targetID string) bool {
+	index := make(map[string]int, len(tasks))
+	for i, task := range tasks {
+		index[task.ID] = i
+	}
+
+	visited := make(map[string]bool)
+	stack := append([]string{}, tasks[idx].DependsOn...)
+	for len(stack) > 0 {
+		id := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if id == targetID {
+			return true
+		}
+		if visited[id] {
+			continue
+		}
+		visited[id] = true
+		if k, ok := index[id]; ok {
+			stack = append(stack, tasks[k].DependsOn...)
+		}
+	}
+	return false
+}
+
+// computeSchedule derives earliest/latest ordering slots from task dependencies
+func computeSchedule(tasks []Task) []TaskSchedule {
+	schedule := make([]TaskSchedule, len(tasks))
+	if len(tasks) == 0 {
+		return schedule
+	}
+
+	index := make(map[string]int, len(tasks))
+	for i, task := range tasks {
+		index[task.ID] = i
+	}
+
+	order := topologicalOrder(tasks)
+
+	// Forward pass: earliest slot
+	earliest := make([]int, len(tasks))
+	horizon := 0
+	for _, i := range order {
+		for _, dep := range tasks[i].DependsOn {
+			if k, ok := index[dep]; ok && earliest[k]+1 > earliest[i] {
+				earliest[i] = earliest[k] + 1
+			}
+		}
+		if earliest[i] > horizon {
+			horizon = earliest[i]
+		}
+	}
+
+	// Backward pass: latest slot that does not push back the final slot
+	latest := make([]int, len(tasks))
+	for i := range latest {
+		latest[i] = horizon
+	}
+	for n := len(order) - 1; n >= 0; n-- {
+		i := order[n]
+		for _, next := range tasks[i].Blocks {
+			if k, ok := index[next]; ok && latest[k]-1 < latest[i] {
+				latest[i] = latest[k] - 1
+			}
+		}
+		if latest[i] < earliest[i] {
+			latest[i] = earliest[i]
+		}
+	}
+
+	for i, task := range tasks {
+		entry := TaskSchedule{
+			TaskID:        task.ID,
+			EarliestStart: earliest[i],
+			LatestStart:   latest[i],
+			Slack:         latest[i] - earliest[i],
+		}
+		for _, c := range task.Constraints {
+			if c.Type == "deadline" && entry.Deadline == "" {
+				entry.Deadline = c.Value
+			}
+			if c.Type == "duration" && entry.Duration == "" {
+				entry.Duration = c.Value
+			}
+		}
+		schedule[i] = entry
+	}
+
+	return schedule
+}
+
+// topologicalOrder sorts task indexes so dependencies come first; tasks caught in a cycle keep their text order
+func topologicalOrder(tasks []Task) []int {
+	index := make(map[string]int, len(tasks))
+	for i, task := range tasks {
+		index[task.ID] = i
+	}
+
+	inDegree := make([]int, len(tasks))
+	for i, task := range tasks {
+		for _, dep := range task.DependsOn {
+			if _, ok := index[dep]; ok {
+				inDegree[i]++
+			}
+		}
+	}
+
+	order := make([]int, 0, len(tasks))
+	queue := []int{}
+	for i := range tasks {
+		if inDegree[i] == 0 {
+			queue = append(queue, i)
+		}
+	}
+	for len(queue) > 0 {
+		i := queue[0]
+		queue = queue[1:]
+		order = append(order, i)
+		for _, next := range tasks[i].Blocks {
+			if k, ok := index[next]; ok {
+				inDegree[k]--
+				if inDegree[k] == 0 {
+					queue = append(queue, k)
+				}
+			}
+		}
+	}
+
+	seen := make([]bool, len(tasks))
+	for _, i := range order {
+		seen[i] = true
+	}
+	for i := range tasks {
+		if !seen[i] {
+			order = append(order, i)
+		}
+	}
+	return order
+}
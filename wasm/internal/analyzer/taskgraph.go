@@ -23,6 +23,11 @@ type Task struct {
 	Confidence       float64           `json:"confidence"`
 	ActionVerbs      []string          `json:"action_verbs"`
 	EstimatedEffort  string            `json:"estimated_effort"` // "small", "medium", "large"
+	Constraints      []TemporalConstraint `json:"constraints"` // Deadlines, durations and explicit ordering
+	Actor            string            `json:"actor"` // Who the task is addressed to, e.g. "you", "backend team"
+	ActorType        string            `json:"actor_type"` // "self", "team", "addressee", "named", "third_party", "unspecified"
+	ParentID         string            `json:"parent_id"` // Parent task from list nesting, empty for top level
+	ChildIDs         []string          `json:"child_ids"`
 }
 
 // TextRange represents the position of text in the original input
@@ -49,14 +54,21 @@ type TaskGraph struct {
 	Relationships  []TaskRelationship `json:"relationships"`
 	RootTasks      []string           `json:"root_tasks"` // Tasks with no dependencies
 	LeafTasks      []string           `json:"leaf_tasks"` // Tasks that nothing depends on
-	CriticalPath   []string           `json:"critical_path"` // Longest dependency chain
+	CriticalPath   []string           `json:"critical_path"` // Dependency chain with the most estimated effort
+	CriticalPathEffort float64        `json:"critical_path_effort"` // Total effort points along the critical path
 	TotalTasks     int                `json:"total_tasks"`
 	GraphComplexity float64           `json:"graph_complexity"`
+	Schedule       []TaskSchedule     `json:"schedule"` // Earliest/latest ordering per task
+	sampling       *StageDegradation  // Set when long texts were sampled before extraction
 }
 
+// defaultMaxTaskSentences bounds how many sentences task extraction reads
+// unless RuntimeConfig.MaxTaskSentences changes it
+const defaultMaxTaskSentences = 100
+
 // ExtractTaskGraph analyzes text and builds a task graph
 func ExtractTaskGraph(text string, sentences []string, clusters []IdeaCluster) *TaskGraph {
-	tasks := extractTasks(text, sentences, clusters)
+	tasks, sampling := extractTasks(text, sentences, clusters)
 	if tasks == nil {
 		tasks = []Task{}
 	}
@@ -65,11 +77,14 @@ func ExtractTaskGraph(text string, sentences []string, clusters []IdeaCluster) *
 	if relationships == nil {
 		relationships = []TaskRelationship{}
 	}
+	relationships = applyOrderingConstraints(tasks, relationships)
+	relationships = append(relationships, assignListHierarchy(text, tasks)...)
 	
 	graph := TaskGraph{
 		Tasks:         tasks,
 		Relationships: relationships,
 		TotalTasks:    len(tasks),
+		sampling:      sampling,
 	}
 	
 	// Identify root and leaf tasks
@@ -77,30 +92,53 @@ func ExtractTaskGraph(text string, sentences []string, clusters []IdeaCluster) *
 	graph.LeafTasks = findLeafTasks(tasks)
 	
 	// Calculate critical path
-	graph.CriticalPath = findCriticalPath(tasks, relationships)
+	graph.CriticalPath, graph.CriticalPathEffort = findCriticalPath(tasks)
 	
 	// Calculate graph complexity
 	graph.GraphComplexity = calculateGraphComplexity(tasks, relationships)
 	
+	// Compute earliest/latest ordering from dependencies and constraints
+	graph.Schedule = computeSchedule(tasks)
+	
 return &graph
 }
 
-// extractTasks identifies actionable items from the text
-func extractTasks(text string, sentences []string, clusters []IdeaCluster) []Task {
+// ExtractTaskGraphFromText builds a task graph using plain sentence splitting
+func ExtractTaskGraphFromText(text string) *TaskGraph {
+	return ExtractTaskGraph(text, extractSentences(text), nil)
+}
+
+// extractTasks identifies actionable items from the text, reporting how the
+// sentences were sampled when there were too many to read
+func extractTasks(text string, sentences []string, clusters []IdeaCluster) ([]Task, *StageDegradation) {
 	var tasks []Task
 	taskID := 1
 	
-	// Limit number of sentences to process to prevent memory issues
-	maxSentences := 100
-	if len(sentences) > maxSentences {
-		sentences = sentences[:maxSentences]
+	// Give each list item its own sentence so nesting can be recovered
+	sentences = splitListSentences(sentences)
+	
+	// Limit number of sentences to process to prevent memory issues, keeping
+	// every task sentence and a share of each section
+	var sampling *StageDegradation
+	maxTaskSentences := CurrentRuntimeConfig().MaxTaskSentences
+	sample := sampleSentences(text, sentences, maxTaskSentences)
+	if len(sample.indexes) < len(sentences) {
+		sampling = &StageDegradation{
+			Stage:     "task_extraction",
+			Mode:      DegradationSampled,
+			Processed: len(sample.indexes),
+			Total:     len(sentences),
+			Reason:    fmt.Sprintf("task extraction reads at most %d sentences", maxTaskSentences),
+			Coverage:  sample.coverage,
+		}
 	}
 	
 	// Track character position
 	charPos := 0
 	textLen := len(text)
 	
-	for sentNum, sentence := range sentences {
+	for _, sentNum := range sample.indexes {
+		sentence := sentences[sentNum]
 		// Ensure we don't go out of bounds
 		if charPos >= textLen {
 			break
@@ -113,6 +151,9 @@ func extractTasks(text string, sentences []string, clusters []IdeaCluster) []Tas
 			idx := strings.Index(remainText, sentence)
 			if idx != -1 {
 				sentStart = charPos + idx
+			} else if idx = strings.Index(text, sentence); idx != -1 {
+				// Sentences grouped by cluster can arrive out of text order
+				sentStart = idx
 			}
 		}
 		
@@ -138,10 +179,12 @@ func extractTasks(text string, sentences []string, clusters []IdeaCluster) []Tas
 			}
 		}
 		
-		charPos = sentEnd
+		if sentEnd > charPos {
+			charPos = sentEnd
+		}
 	}
 	
-	return tasks
+	return tasks, sampling
 }
 
 // extractTaskFromSentence analyzes a single sentence for task indicators
@@ -235,6 +278,9 @@ func extractTaskFromSentence(sentence string, sentNum, startChar, endChar int) *
 	// Estimate effort based on action verbs and complexity
 	effort := estimateEffort(sentence, actionVerbs)
 	
+	// Attribute the task to whoever it is addressed to
+	actor, actorType := detectTaskActor(sentence)
+	
 	return &Task{
 		Title:       title,
 		Description: description,
@@ -251,13 +297,17 @@ func extractTaskFromSentence(sentence string, sentNum, startChar, endChar int) *
 		Confidence:      confidence,
 		ActionVerbs:     actionVerbs,
 		EstimatedEffort: effort,
+		Constraints:     extractTemporalConstraints(sentence, startChar),
+		Actor:           actor,
+		ActorType:       actorType,
+		ChildIDs:        []string{},
 	}
 }
 
 // extractTaskTitle creates a concise title from the sentence
 func extractTaskTitle(sentence string) string {
-	// Remove common prefixes
-	title := sentence
+	// Remove list markers and common prefixes
+	title := strings.TrimSpace(listItemPattern.ReplaceAllString(sentence, ""))
 	prefixes := []string{
 		"I need to ", "I have to ", "I must ", "I should ",
 		"We need to ", "We have to ", "We must ", "We should ",
@@ -306,7 +356,7 @@ func extractKeywords(sentence string) []string {
 	
 	for _, word := range words {
 		// Clean the word
-		word = regexp.MustCompile(`[^\w]`).ReplaceAllString(word, "")
+		word = nonWordCharPattern.ReplaceAllString(word, "")
 		
 		if significantWords[word] || (len(word) > 4 && !isStopWord(word)) {
 			keywords = append(keywords, word)
@@ -350,6 +400,98 @@ func estimateEffort(sentence string, actionVerbs []string) string {
 	return "medium"
 }
 
+var (
+	explicitOwnerPattern = regexp.MustCompile(`(?i)\b(?:assigned to|owned by|owner:|assignee:)\s+@?([a-z][\w-]*(?:\s+[A-Z][\w-]*)?)`)
+	mentionPattern       = regexp.MustCompile(`(?:^|\s)@([A-Za-z][\w-]*)`)
+	responsiblePattern   = regexp.MustCompile(`^\s*((?:the\s+)?[A-Za-z][\w-]*(?:\s+[A-Za-z][\w-]*){0,2}?)\s+(?:is|are)\s+responsible\s+for\b`)
+	subjectModalPattern  = regexp.MustCompile(`(?i)^\s*(?:(?:please|then|first|next|also|and|so)\s*,?\s+)?((?:the\s+)?[a-z][\w-]*(?:\s+[a-z][\w-]*){0,2}?)\s*(?:will|must|should|shall|needs? to|has to|have to|is going to|are going to|am going to|'ll|can|could)\b`)
+	addresseeAskPattern  = regexp.MustCompile(`(?i)^\s*(?:please\s+)?(?:can|could|would|will)\s+you\b`)
+)
+
+// imperativeVerbs are verbs that open a sentence addressed to the reader
+var imperativeVerbs = map[string]bool{
+	"add": true, "analyze": true, "build": true, "check": true, "configure": true,
+	"create": true, "define": true, "delete": true, "deploy": true, "design": true,
+	"develop": true, "document": true, "ensure": true, "explain": true, "find": true,
+	"fix": true, "generate": true, "implement": true, "list": true, "make": true,
+	"migrate": true, "move": true, "please": true, "refactor": true, "remove": true,
+	"review": true, "run": true, "send": true, "set": true, "test": true,
+	"update": true, "use": true, "validate": true, "verify": true, "write": true,
+}
+
+// actorRoleNouns are nouns that identify a person or group as a task owner
+var actorRoleNouns = map[string]bool{
+	"team": true, "group": true, "squad": true, "department": true, "engineer": true,
+	"developer": true, "dev": true, "designer": true, "manager": true, "lead": true,
+	"admin": true, "administrator": true, "owner": true, "analyst": true, "tester": true,
+	"qa": true, "ops": true, "devop": true, "sre": true, "reviewer": true, "maintainer": true,
+	"contractor": true, "client": true, "customer": true, "stakeholder": true, "user": true,
+}
+
+// detectTaskActor determines who a task is addressed to
+func detectTaskActor(sentence string) (string, string) {
+	if m := explicitOwnerPattern.FindStringSubmatch(sentence); m != nil {
+		return m[1], "named"
+	}
+	if m := mentionPattern.FindStringSubmatch(sentence); m != nil {
+		return m[1], "named"
+	}
+	if m := responsiblePattern.FindStringSubmatch(sentence); m != nil {
+		return classifyActor(m[1])
+	}
+	if addresseeAskPattern.MatchString(sentence) {
+		return "you", "addressee"
+	}
+	if m := subjectModalPattern.FindStringSubmatch(sentence); m != nil {
+		if actor, actorType := classifyActor(m[1]); actorType != "unspecified" {
+			return actor, actorType
+		}
+	}
+
+	// Imperative sentences are implicitly addressed to the reader
+	fields := strings.Fields(strings.ToLower(sentence))
+	if len(fields) > 0 && imperativeVerbs[strings.Trim(fields[0], ",:;")] {
+		return "you", "addressee"
+	}
+
+	return "", "unspecified"
+}
+
+// classifyActor maps a subject phrase onto an actor and actor type
+func classifyActor(subject string) (string, string) {
+	subject = strings.TrimSpace(subject)
+	lower := strings.ToLower(subject)
+
+	switch lower {
+	case "i":
+		return "I", "self"
+	case "we", "our team", "us":
+		return "we", "team"
+	case "you", "you all", "y'all":
+		return "you", "addressee"
+	case "he", "she", "they", "someone", "somebody":
+		return lower, "third_party"
+	case "it", "this", "that", "there", "these", "those", "which", "what", "how":
+		return "", "unspecified"
+	}
+
+	// Trim articles from named actors ("the backend team" -> "backend team")
+	if strings.HasPrefix(lower, "the ") {
+		subject = subject[4:]
+		lower = lower[4:]
+	}
+	words := strings.Fields(lower)
+	if len(words) == 0 || isStopWord(words[0]) {
+		return "", "unspecified"
+	}
+
+	// Only accept proper nouns or role nouns so "the tests should pass" isn't an actor
+	if actorRoleNouns[strings.TrimSuffix(words[len(words)-1], "s")] || (subject[0] >= 'A' && subject[0] <= 'Z' && len(words) <= 2) {
+		return subject, "named"
+	}
+	return "", "unspecified"
+}
+
 // enrichTaskWithClusterInfo adds information from idea clusters to tasks
 func enrichTaskWithClusterInfo(task *Task, clusters []IdeaCluster) {
 	for _, cluster := range clusters {
@@ -549,46 +691,72 @@ func findLeafTasks(tasks []Task) []string {
 	return leaves
 }
 
-// findCriticalPath finds the longest dependency chain
-func findCriticalPath(tasks []Task, relationships []TaskRelationship) []string {
-	// Build adjacency list
-	graph := make(map[string][]string)
-	taskMap := make(map[string]*Task)
-	
-	for _, task := range tasks {
-		taskMap[task.ID] = &task
-		graph[task.ID] = task.Blocks
+// effortWeights maps effort estimates to relative effort points
+var effortWeights = map[string]float64{
+	"small":  1,
+	"medium": 3,
+	"large":  8,
+}
+
+// taskEffortWeight returns the effort points for a task, defaulting to medium
+func taskEffortWeight(task Task) float64 {
+	if w, ok := effortWeights[task.EstimatedEffort]; ok {
+		return w
+	}
+	return effortWeights["medium"]
+}
+
+// findCriticalPath finds the dependency chain with the highest total effort
+func findCriticalPath(tasks []Task) ([]string, float64) {
+	if len(tasks) == 0 {
+		return []string{}, 0
 	}
 	
-	// Find longest path using DFS
-	var longestPath []string
-	visited := make(map[string]bool)
+	index := make(map[string]int, len(tasks))
+	for i, task := range tasks {
+		index[task.ID] = i
+	}
 	
-	var dfs func(taskID string, path []string)
-	dfs = func(taskID string, path []string) {
-		if len(path) > len(longestPath) {
-			longestPath = make([]string, len(path))
-			copy(longestPath, path)
-		}
-		
-		for _, nextID := range graph[taskID] {
-			if !visited[nextID] {
-				visited[nextID] = true
-				dfs(nextID, append(path, nextID))
-				visited[nextID] = false
+	// Longest weighted path over the dependency DAG, in topological order
+	best := make([]float64, len(tasks))
+	length := make([]int, len(tasks))
+	prev := make([]int, len(tasks))
+	for i := range prev {
+		prev[i] = -1
+	}
+	for _, i := range topologicalOrder(tasks) {
+		best[i] = taskEffortWeight(tasks[i])
+		length[i] = 1
+		for _, dep := range tasks[i].DependsOn {
+			k, ok := index[dep]
+			if !ok || prev[k] == i {
+				continue
+			}
+			candidate := best[k] + taskEffortWeight(tasks[i])
+			// Prefer more effort, then the longer chain
+			if candidate > best[i] || (candidate == best[i] && length[k]+1 > length[i]) {
+				best[i] = candidate
+				length[i] = length[k] + 1
+				prev[i] = k
 			}
 		}
 	}
 	
-	// Start DFS from each root task
-	roots := findRootTasks(tasks)
-	for _, root := range roots {
-		visited[root] = true
-		dfs(root, []string{root})
-		visited[root] = false
+	end := 0
+	for i := range tasks {
+		if best[i] > best[end] || (best[i] == best[end] && length[i] > length[end]) {
+			end = i
+		}
+	}
+	
+	path := []string{}
+	seen := make(map[int]bool)
+	for i := end; i >= 0 && !seen[i]; i = prev[i] {
+		seen[i] = true
+		path = append([]string{tasks[i].ID}, path...)
 	}
 	
-	return longestPath
+	return path, best[end]
 }
 
 // calculateGraphComplexity calculates the complexity of the task graph
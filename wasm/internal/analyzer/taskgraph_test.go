@@ -0,0 +1,238 @@
+package analyzer
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// buildTestGraph extracts a task graph from sentences joined into one text
+func buildTestGraph(sentences []string) *TaskGraph {
+	text := ""
+	for i, s := range sentences {
+		if i > 0 {
+			text += " "
+		}
+		text += s
+	}
+	return ExtractTaskGraph(text, sentences, nil)
+}
+
+// TestTemporalConstraintExtraction checks deadlines, durations and ordering words
+func TestTemporalConstraintExtraction(t *testing.T) {
+	constraints := extractTemporalConstraints("We need to deploy the api by Friday, within 2 weeks after step 3.", 10)
+
+	found := make(map[string]TemporalConstraint)
+	for _, c := range constraints {
+		found[c.Type] = c
+	}
+
+	if c, ok := found["deadline"]; !ok || c.Value != "friday" {
+		t.Errorf("expected deadline friday, got %+v", found["deadline"])
+	}
+	if c, ok := found["duration"]; !ok || c.Value != "P2W" {
+		t.Errorf("expected duration P2W, got %+v", found["duration"])
+	}
+	if c, ok := found["after"]; !ok || c.Value != "3" {
+		t.Errorf("expected ordering after step 3, got %+v", found["after"])
+	}
+	if c := found["deadline"]; c.StartChar < 10 {
+		t.Errorf("expected offsets relative to the full text, got %d", c.StartChar)
+	}
+}
+
+// TestTaskSchedule checks that explicit ordering feeds earliest/latest slots
+func TestTaskSchedule(t *testing.T) {
+	graph := buildTestGraph([]string{
+		"We need to design the database schema.",
+		"We need to write the deployment docs.",
+		"Implement the migration scripts after step 1.",
+	})
+
+	if len(graph.Schedule) != len(graph.Tasks) {
+		t.Fatalf("expected one schedule entry per task, got %d for %d tasks", len(graph.Schedule), len(graph.Tasks))
+	}
+
+	slots := make(map[string]TaskSchedule)
+	for _, s := range graph.Schedule {
+		slots[s.TaskID] = s
+	}
+
+	if !contains(graph.Tasks[2].DependsOn, "task_1") {
+		t.Fatalf("expected task_3 to depend on task_1, got %v", graph.Tasks[2].DependsOn)
+	}
+	if slots["task_3"].EarliestStart <= slots["task_1"].EarliestStart {
+		t.Errorf("expected task_3 to be scheduled after task_1: %+v", slots)
+	}
+	for _, s := range graph.Schedule {
+		if s.Slack < 0 || s.LatestStart < s.EarliestStart {
+			t.Errorf("invalid schedule window: %+v", s)
+		}
+	}
+}
+
+// TestTaskActorDetection checks who tasks are attributed to
+func TestTaskActorDetection(t *testing.T) {
+	cases := []struct {
+		sentence  string
+		actor     string
+		actorType string
+	}{
+		{"You should update the config file.", "you", "addressee"},
+		{"The backend team must fix the api errors.", "backend team", "named"},
+		{"I will deploy the build tonight.", "I", "self"},
+		{"We need to review the design.", "we", "team"},
+		{"Implement the caching layer.", "you", "addressee"},
+		{"Alice will write the migration.", "Alice", "named"},
+		{"This should be fixed soon.", "", "unspecified"},
+		{"The tests must pass before release.", "", "unspecified"},
+	}
+
+	for _, tc := range cases {
+		actor, actorType := detectTaskActor(tc.sentence)
+		if actor != tc.actor || actorType != tc.actorType {
+			t.Errorf("%q: expected (%q, %q), got (%q, %q)", tc.sentence, tc.actor, tc.actorType, actor, actorType)
+		}
+	}
+}
+
+// TestListHierarchy checks that nested numbered lists become parent-child tasks
+func TestListHierarchy(t *testing.T) {
+	text := "1. Build the payment api\n" +
+		"  1.1 Create the database tables\n" +
+		"  1.2 Implement the refund endpoint\n" +
+		"2. Deploy the service\n" +
+		"  - Configure the staging server\n"
+	graph := ExtractTaskGraph(text, extractSentences(text), nil)
+
+	titles := make(map[string]string)
+	for _, task := range graph.Tasks {
+		titles[task.ID] = task.Title
+	}
+	parentOf := func(fragment string) string {
+		for _, task := range graph.Tasks {
+			if strings.Contains(task.SourceText, fragment) {
+				return titles[task.ParentID]
+			}
+		}
+		t.Fatalf("no task for %q in %+v", fragment, graph.Tasks)
+		return ""
+	}
+
+	if p := parentOf("database tables"); !strings.Contains(p, "payment api") {
+		t.Errorf("expected database task under payment api, got %q", p)
+	}
+	if p := parentOf("refund endpoint"); !strings.Contains(p, "payment api") {
+		t.Errorf("expected refund task under payment api, got %q", p)
+	}
+	if p := parentOf("staging server"); !strings.Contains(p, "Deploy") {
+		t.Errorf("expected staging task under deploy, got %q", p)
+	}
+	if p := parentOf("Deploy the service"); p != "" {
+		t.Errorf("expected deploy task at top level, got parent %q", p)
+	}
+
+	depths := make(map[int]int)
+	graph.WalkTree(func(task Task, depth int) {
+		depths[depth]++
+	})
+	if depths[0] != 2 || depths[1] != 3 {
+		t.Errorf("unexpected tree shape: %v", depths)
+	}
+}
+
+// TestWeightedCriticalPath checks that the critical path follows effort rather than chain length
+func TestWeightedCriticalPath(t *testing.T) {
+	tasks := []Task{
+		{ID: "task_1", EstimatedEffort: "small", Blocks: []string{"task_2", "task_4"}},
+		{ID: "task_2", EstimatedEffort: "small", DependsOn: []string{"task_1"}, Blocks: []string{"task_3"}},
+		{ID: "task_3", EstimatedEffort: "small", DependsOn: []string{"task_2"}},
+		{ID: "task_4", EstimatedEffort: "large", DependsOn: []string{"task_1"}},
+	}
+
+	path, effort := findCriticalPath(tasks)
+	if len(path) != 2 || path[0] != "task_1" || path[1] != "task_4" {
+		t.Errorf("expected [task_1 task_4], got %v", path)
+	}
+	if effort != effortWeights["small"]+effortWeights["large"] {
+		t.Errorf("unexpected critical path effort %.1f", effort)
+	}
+}
+
+// TestTaskGraphExport checks the GitHub, Jira and CSV exporters
+func TestTaskGraphExport(t *testing.T) {
+	graph := ExtractTaskGraphFromText("1. Build the payment api\n  1.1 Create the database tables\n2. Deploy the service by 2024-05-01\n")
+
+	issues := ExportGitHubIssues(graph)
+	if len(issues) != 2 || !strings.Contains(issues[0].Body, "- [ ] Create the database tables") {
+		t.Errorf("expected checklist of subtasks in first issue, got %+v", issues)
+	}
+
+	jira, err := ExportJiraBulkJSON(graph, "FUL")
+	if err != nil {
+		t.Fatalf("jira export failed: %v", err)
+	}
+	for _, want := range []string{`"sub-task-link"`, `"Sub-task"`, `"duedate": "2024-05-01"`} {
+		if !strings.Contains(string(jira), want) {
+			t.Errorf("expected %s in jira export", want)
+		}
+	}
+
+	csvData, err := ExportTasksCSV(graph)
+	if err != nil {
+		t.Fatalf("csv export failed: %v", err)
+	}
+	records, err := csv.NewReader(strings.NewReader(csvData)).ReadAll()
+	if err != nil || len(records) != len(graph.Tasks)+1 {
+		t.Errorf("expected header plus %d rows, got %d (%v)", len(graph.Tasks), len(records), err)
+	}
+}
+
+// TestNormalize checks that nil collections marshal as empty values
+func TestNormalize(t *testing.T) {
+	graph := &TaskGraph{Tasks: []Task{{ID: "task_1"}}}
+	result := struct {
+		Graph  *TaskGraph
+		Counts map[string]int
+		Pairs  map[string][]string
+	}{Graph: graph, Pairs: map[string][]string{"a": nil}}
+
+	Normalize(&result)
+
+	if graph.RootTasks == nil || graph.Relationships == nil || graph.Tasks[0].DependsOn == nil {
+		t.Errorf("expected nil slices to be replaced: %+v", graph)
+	}
+	if result.Counts == nil {
+		t.Errorf("expected nil map to be replaced")
+	}
+	if result.Pairs["a"] == nil {
+		t.Errorf("expected nil slice inside map to be replaced")
+	}
+}
+
+func TestToPlainMatchesJSON(t *testing.T) {
+	text := "Please build a login page by 2025-03-01. Then add tests for the API. Deploy after step 1."
+	grade := GradePromptText(text)
+	graph := ExtractTaskGraphFromText(text)
+	Normalize(grade)
+	Normalize(graph)
+
+	for name, v := range map[string]interface{}{"grade": grade, "graph": graph, "perf": NewPerformanceMetrics("req_1")} {
+		want, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := json.Marshal(ToPlain(v))
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Structs encode in field order; round-trip through a map so both sides sort keys
+		var decoded interface{}
+		json.Unmarshal(want, &decoded)
+		want, _ = json.Marshal(decoded)
+		if string(got) != string(want) {
+			t.Errorf("%s: plain form differs from JSON encoding\n got: %s\nwant: %s", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,394 @@
+package analyzer
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TemporalExpression is a date, time or duration found in the text with its
+// ISO 8601 form
+type TemporalExpression struct {
+	Span
+	Kind string `json:"kind"` // date, time, datetime, week, month, year or duration
+	// Value is e.g. "2025-03-03", "15:00", "2025-03-03T15:00", "2025-W11" or
+	// "P2W"; dates without a year are "--03-03". Empty when the expression is
+	// relative and there is no reference time.
+	Value    string `json:"value,omitempty"`
+	Relative bool   `json:"relative"` // Depends on the reference time, e.g. "next Tuesday"
+}
+
+// Temporal expression kinds
+const (
+	TemporalDate     = "date"
+	TemporalTime     = "time"
+	TemporalDateTime = "datetime"
+	TemporalWeek     = "week"
+	TemporalMonth    = "month"
+	TemporalYear     = "year"
+	TemporalDuration = "duration"
+)
+
+const (
+	monthNames   = `january|february|march|april|may|june|july|august|september|october|november|december|jan|feb|mar|apr|jun|jul|aug|sept?|oct|nov|dec`
+	weekdayNames = `monday|tuesday|wednesday|thursday|friday|saturday|sunday`
+	countWords   = `\d+|an?|one|two|three|four|five|six|seven|eight|nine|ten|a\s+couple\s+of|a\s+few`
+	periodUnits  = `minute|hour|day|week|month|year`
+)
+
+// temporalRule turns one pattern's match into an expression; ok is false
+// when the match is not a real date, such as February 30
+type temporalRule struct {
+	pattern *regexp.Regexp
+	parse   func(m []string, ref time.Time) (kind, value string, relative, ok bool)
+}
+
+var temporalRules = []temporalRule{
+	{regexp.MustCompile(`\b(\d{4})-(\d{2})-(\d{2})(?:[T ](\d{2}):(\d{2}))?\b`), parseISODate},
+	{regexp.MustCompile(`(?i)\b(` + monthNames + `)\.?\s+(\d{1,2})(?:st|nd|rd|th)?(?:,?\s+(\d{4}))?\b`), parseMonthDay},
+	{regexp.MustCompile(`(?i)\b(\d{1,2})(?:st|nd|rd|th)?\s+(?:of\s+)?(` + monthNames + `)\.?(?:,?\s+(\d{4}))?\b`), parseDayMonth},
+	{regexp.MustCompile(`\b(\d{1,2})/(\d{1,2})/(\d{4})\b`), parseNumericDate},
+	{regexp.MustCompile(`(?i)\b(today|tonight|tomorrow|yesterday|eod|eow)\b`), parseDayWord},
+	{regexp.MustCompile(`(?i)\b(?:(next|this|last|coming)\s+)?(` + weekdayNames + `)\b`), parseWeekday},
+	{regexp.MustCompile(`(?i)\b(next|last|this)\s+(week|month|year)\b`), parseRelativePeriod},
+	{regexp.MustCompile(`(?i)\b(?:the\s+)?end\s+of\s+(?:the\s+)?(day|week|month|quarter|year)\b`), parseEndOf},
+	{regexp.MustCompile(`(?i)\bin\s+(` + countWords + `)\s+(` + periodUnits + `)s?\b`), parseOffset},
+	{regexp.MustCompile(`(?i)\b(` + countWords + `)\s+(` + periodUnits + `)s?\s+ago\b`), parseAgo},
+	{regexp.MustCompile(`(?i)\b(?:for|within|over|lasting|takes?)\s+(` + countWords + `)\s+(` + periodUnits + `)s?\b`), parseDurationPhrase},
+	{regexp.MustCompile(`(?i)(?P<token>\b(\d{1,2})(?::([0-5]\d))?\s?([ap])(?:m|\.m\.?))(?:\s|$|[,;.!?)])|\b([01]?\d|2[0-3]):([0-5]\d)\b|\b(noon|midnight)\b`), parseClockTime},
+}
+
+// dateTimeJoinPattern links a date and a following time into one datetime
+var dateTimeJoinPattern = regexp.MustCompile(`(?i)^(?:,?\s+(?:at\s+)?|\s*@\s*)$`)
+
+// ExtractTemporalExpressions finds dates, times and durations in text and
+// normalizes them to ISO 8601. Relative forms ("next Tuesday", "in 2 weeks")
+// are resolved against ref; with a zero ref they are returned unresolved.
+func ExtractTemporalExpressions(text string, ref time.Time) []TemporalExpression {
+	var found []TemporalExpression
+	for _, rule := range temporalRules {
+		for _, idx := range rule.pattern.FindAllStringSubmatchIndex(text, -1) {
+			m := make([]string, len(idx)/2)
+			for i := range m {
+				if idx[2*i] >= 0 {
+					m[i] = text[idx[2*i]:idx[2*i+1]]
+				}
+			}
+			kind, value, relative, ok := rule.parse(m, ref)
+			if !ok {
+				continue
+			}
+			end := idx[1]
+			// Patterns that need a delimiter after the match ("3pm," or "3pm.")
+			// mark the expression itself as the token group
+			if t := rule.pattern.SubexpIndex("token"); t > 0 && idx[2*t] >= 0 {
+				end = idx[2*t+1]
+			}
+			found = append(found, TemporalExpression{Span: newSpan(text, idx[0], end), Kind: kind, Value: value, Relative: relative})
+		}
+	}
+
+	// Longer matches win where patterns overlap
+	sort.SliceStable(found, func(i, j int) bool {
+		if found[i].Start != found[j].Start {
+			return found[i].Start < found[j].Start
+		}
+		return found[i].End > found[j].End
+	})
+	out := []TemporalExpression{}
+	for _, e := range found {
+		if n := len(out); n > 0 && e.Start < out[n-1].End {
+			continue
+		}
+		if n := len(out); n > 0 && e.Kind == TemporalTime && out[n-1].Kind == TemporalDate &&
+			dateTimeJoinPattern.MatchString(text[out[n-1].End:e.Start]) {
+			prev := &out[n-1]
+			prev.Span = newSpan(text, prev.Start, e.End)
+			prev.Kind = TemporalDateTime
+			if prev.Value != "" && !strings.HasPrefix(prev.Value, "--") {
+				prev.Value += "T" + e.Value
+			} else {
+				prev.Value = ""
+			}
+			continue
+		}
+		out = append(out, e)
+	}
+	return out
+}
+
+// ResolveTemporal normalizes one phrase such as "next friday" or "march 3"
+// to an ISO 8601 value, or returns "" when it is not a single expression
+func ResolveTemporal(phrase string, ref time.Time) string {
+	found := ExtractTemporalExpressions(phrase, ref)
+	if len(found) != 1 || found[0].Text != strings.TrimSpace(phrase) {
+		return ""
+	}
+	return found[0].Value
+}
+
+// parseReferenceTime reads AnalysisOptions.ReferenceTime as RFC 3339 or a
+// YYYY-MM-DD date; "" gives the zero time
+func parseReferenceTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.UTC(), nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid reference_time %q (expected RFC 3339 or YYYY-MM-DD)", value)
+	}
+	return t, nil
+}
+
+func isoDate(t time.Time) string { return t.Format("2006-01-02") }
+
+// validDate builds a date, rejecting overflow such as February 30
+func validDate(year, month, day int) (time.Time, bool) {
+	t := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	return t, t.Year() == year && int(t.Month()) == month && t.Day() == day
+}
+
+func monthNumber(name string) int {
+	name = strings.ToLower(name)
+	for i, full := range []string{"january", "february", "march", "april", "may", "june", "july", "august", "september", "october", "november", "december"} {
+		if strings.HasPrefix(full, name[:3]) {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+func parseISODate(m []string, ref time.Time) (string, string, bool, bool) {
+	year, _ := strconv.Atoi(m[1])
+	month, _ := strconv.Atoi(m[2])
+	day, _ := strconv.Atoi(m[3])
+	t, ok := validDate(year, month, day)
+	if !ok {
+		return "", "", false, false
+	}
+	if m[4] != "" {
+		return TemporalDateTime, isoDate(t) + "T" + m[4] + ":" + m[5], false, true
+	}
+	return TemporalDate, isoDate(t), false, true
+}
+
+func parseMonthDay(m []string, ref time.Time) (string, string, bool, bool) {
+	if m[1] == "may" { // "you may 3x the budget" is not a date
+		return "", "", false, false
+	}
+	return calendarDate(monthNumber(m[1]), m[2], m[3], ref)
+}
+
+func parseDayMonth(m []string, ref time.Time) (string, string, bool, bool) {
+	return calendarDate(monthNumber(m[2]), m[1], m[3], ref)
+}
+
+// calendarDate builds a date from a month, day and optional year. Without a
+// year it takes the next such date on or after ref, since prompts mostly
+// name upcoming dates, or "--MM-DD" when there is no reference time.
+func calendarDate(month int, dayText, yearText string, ref time.Time) (string, string, bool, bool) {
+	day, _ := strconv.Atoi(dayText)
+	if yearText != "" {
+		year, _ := strconv.Atoi(yearText)
+		t, ok := validDate(year, month, day)
+		return TemporalDate, isoDate(t), false, ok
+	}
+	if _, ok := validDate(2000, month, day); !ok { // 2000 is a leap year
+		return "", "", false, false
+	}
+	if ref.IsZero() {
+		return TemporalDate, fmt.Sprintf("--%02d-%02d", month, day), true, true
+	}
+	for year := ref.Year(); ; year++ {
+		if t, ok := validDate(year, month, day); ok && !t.Before(startOfDay(ref)) {
+			return TemporalDate, isoDate(t), true, true
+		}
+	}
+}
+
+// parseNumericDate reads slashed dates month first, as US prompts write them
+func parseNumericDate(m []string, ref time.Time) (string, string, bool, bool) {
+	month, _ := strconv.Atoi(m[1])
+	day, _ := strconv.Atoi(m[2])
+	year, _ := strconv.Atoi(m[3])
+	t, ok := validDate(year, month, day)
+	return TemporalDate, isoDate(t), false, ok
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// relativeDate formats a date computed from ref, or leaves it unresolved
+func relativeDate(ref time.Time, kind string, at func(time.Time) string) (string, string, bool, bool) {
+	if ref.IsZero() {
+		return kind, "", true, true
+	}
+	return kind, at(startOfDay(ref)), true, true
+}
+
+// endOfWorkWeek is the Friday of ref's week; "end of week" deadlines mean
+// the working week
+func endOfWorkWeek(day time.Time) time.Time {
+	offset := (int(time.Friday) - int(day.Weekday()) + 7) % 7
+	if day.Weekday() == time.Saturday || day.Weekday() == time.Sunday {
+		offset = -((int(day.Weekday()) + 7 - int(time.Friday)) % 7)
+	}
+	return day.AddDate(0, 0, offset)
+}
+
+func parseDayWord(m []string, ref time.Time) (string, string, bool, bool) {
+	return relativeDate(ref, TemporalDate, func(day time.Time) string {
+		switch strings.ToLower(m[1]) {
+		case "tomorrow":
+			day = day.AddDate(0, 0, 1)
+		case "yesterday":
+			day = day.AddDate(0, 0, -1)
+		case "eow":
+			day = endOfWorkWeek(day)
+		}
+		return isoDate(day)
+	})
+}
+
+// parseWeekday resolves weekdays: a bare, "this" or "coming" weekday is the
+// next one from today on, "next" skips today and "last" is the latest before it
+func parseWeekday(m []string, ref time.Time) (string, string, bool, bool) {
+	var target time.Weekday
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if strings.EqualFold(d.String(), m[2]) {
+			target = d
+		}
+	}
+	return relativeDate(ref, TemporalDate, func(day time.Time) string {
+		ahead := (int(target) - int(day.Weekday()) + 7) % 7
+		switch strings.ToLower(m[1]) {
+		case "next":
+			if ahead == 0 {
+				ahead = 7
+			}
+		case "last":
+			ahead -= 7
+		}
+		return isoDate(day.AddDate(0, 0, ahead))
+	})
+}
+
+func parseRelativePeriod(m []string, ref time.Time) (string, string, bool, bool) {
+	step := map[string]int{"next": 1, "last": -1, "this": 0}[strings.ToLower(m[1])]
+	switch strings.ToLower(m[2]) {
+	case "week":
+		return relativeDate(ref, TemporalWeek, func(day time.Time) string {
+			year, week := day.AddDate(0, 0, 7*step).ISOWeek()
+			return fmt.Sprintf("%d-W%02d", year, week)
+		})
+	case "month":
+		return relativeDate(ref, TemporalMonth, func(day time.Time) string {
+			return time.Date(day.Year(), day.Month()+time.Month(step), 1, 0, 0, 0, 0, time.UTC).Format("2006-01")
+		})
+	}
+	return relativeDate(ref, TemporalYear, func(day time.Time) string {
+		return strconv.Itoa(day.Year() + step)
+	})
+}
+
+// parseEndOf resolves "end of the month" style deadlines to the period's last day
+func parseEndOf(m []string, ref time.Time) (string, string, bool, bool) {
+	return relativeDate(ref, TemporalDate, func(day time.Time) string {
+		switch strings.ToLower(m[1]) {
+		case "week":
+			day = endOfWorkWeek(day)
+		case "month":
+			day = time.Date(day.Year(), day.Month()+1, 0, 0, 0, 0, 0, time.UTC)
+		case "quarter":
+			quarterEnd := time.Month((int(day.Month())-1)/3*3 + 3)
+			day = time.Date(day.Year(), quarterEnd+1, 0, 0, 0, 0, 0, time.UTC)
+		case "year":
+			day = time.Date(day.Year(), time.December, 31, 0, 0, 0, 0, time.UTC)
+		}
+		return isoDate(day)
+	})
+}
+
+// countValue reads "2", "two" or "a couple of"
+func countValue(word string) int {
+	word = strings.ToLower(strings.Join(strings.Fields(word), " "))
+	if n, err := strconv.Atoi(word); err == nil {
+		return n
+	}
+	return durationWords[word]
+}
+
+// shift moves ref by n units, reporting a datetime for hours and minutes
+func shift(ref time.Time, n int, unit string) (string, string, bool, bool) {
+	unit = strings.ToLower(unit)
+	if unit == "hour" || unit == "minute" {
+		if ref.IsZero() {
+			return TemporalDateTime, "", true, true
+		}
+		d := time.Duration(n) * time.Hour
+		if unit == "minute" {
+			d = time.Duration(n) * time.Minute
+		}
+		return TemporalDateTime, ref.Add(d).UTC().Format("2006-01-02T15:04"), true, true
+	}
+	return relativeDate(ref, TemporalDate, func(day time.Time) string {
+		switch unit {
+		case "day":
+			day = day.AddDate(0, 0, n)
+		case "week":
+			day = day.AddDate(0, 0, 7*n)
+		case "month":
+			day = day.AddDate(0, n, 0)
+		default:
+			day = day.AddDate(n, 0, 0)
+		}
+		return isoDate(day)
+	})
+}
+
+func parseOffset(m []string, ref time.Time) (string, string, bool, bool) {
+	return shift(ref, countValue(m[1]), m[2])
+}
+
+func parseAgo(m []string, ref time.Time) (string, string, bool, bool) {
+	return shift(ref, -countValue(m[1]), m[2])
+}
+
+func parseDurationPhrase(m []string, ref time.Time) (string, string, bool, bool) {
+	return TemporalDuration, isoDuration(strings.ToLower(strings.Join(strings.Fields(m[1]), " ")), strings.ToLower(m[2])), false, true
+}
+
+// parseClockTime reads "3pm", "3:30 p.m.", "15:30", "noon" and "midnight"
+func parseClockTime(m []string, ref time.Time) (string, string, bool, bool) {
+	switch {
+	case m[7] != "":
+		if strings.EqualFold(m[7], "noon") {
+			return TemporalTime, "12:00", false, true
+		}
+		return TemporalTime, "00:00", false, true
+	case m[5] != "":
+		hour, _ := strconv.Atoi(m[5])
+		return TemporalTime, fmt.Sprintf("%02d:%s", hour, m[6]), false, true
+	}
+	hour, _ := strconv.Atoi(m[2])
+	if hour < 1 || hour > 12 {
+		return "", "", false, false
+	}
+	minute := m[3]
+	if minute == "" {
+		minute = "00"
+	}
+	if strings.EqualFold(m[4], "p") && hour != 12 {
+		hour += 12
+	} else if strings.EqualFold(m[4], "a") && hour == 12 {
+		hour = 0
+	}
+	return TemporalTime, fmt.Sprintf("%02d:%s", hour, minute), false, true
+}
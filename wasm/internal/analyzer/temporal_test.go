@@ -0,0 +1,109 @@
+package analyzer
+
+import (
+	"testing"
+	"time"
+)
+
+// temporalRef is a Wednesday
+var temporalRef = time.Date(2025, time.March, 5, 10, 30, 0, 0, time.UTC)
+
+func TestExtractTemporalExpressionsNormalizes(t *testing.T) {
+	cases := []struct {
+		text, kind, value string
+	}{
+		{"next Tuesday", TemporalDate, "2025-03-11"},
+		{"Friday", TemporalDate, "2025-03-07"},
+		{"March 3rd, 2025", TemporalDate, "2025-03-03"},
+		{"3 March", TemporalDate, "2026-03-03"},
+		{"in 2 weeks", TemporalDate, "2025-03-19"},
+		{"in an hour", TemporalDateTime, "2025-03-05T11:30"},
+		{"three days ago", TemporalDate, "2025-03-02"},
+		{"tomorrow at 3pm", TemporalDateTime, "2025-03-06T15:00"},
+		{"2025-04-01 09:15", TemporalDateTime, "2025-04-01T09:15"},
+		{"4/15/2025", TemporalDate, "2025-04-15"},
+		{"end of month", TemporalDate, "2025-03-31"},
+		{"end of the quarter", TemporalDate, "2025-03-31"},
+		{"next week", TemporalWeek, "2025-W11"},
+		{"for 3 hours", TemporalDuration, "PT3H"},
+		{"noon", TemporalTime, "12:00"},
+	}
+	for _, c := range cases {
+		got := ExtractTemporalExpressions(c.text, temporalRef)
+		if len(got) != 1 || got[0].Text != c.text || got[0].Kind != c.kind || got[0].Value != c.value {
+			t.Errorf("%q = %+v, want one %s %s", c.text, got, c.kind, c.value)
+		}
+	}
+}
+
+func TestExtractTemporalExpressionsInText(t *testing.T) {
+	text := "Ship the beta next Tuesday at 10:00, then review it for 2 days. You may 10 people, not February 30."
+	got := ExtractTemporalExpressions(text, temporalRef)
+	want := []string{"next Tuesday at 10:00=2025-03-11T10:00", "for 2 days=P2D"}
+	if len(got) != len(want) {
+		t.Fatalf("got %+v, want %v", got, want)
+	}
+	for i, e := range got {
+		if e.Text+"="+e.Value != want[i] || text[e.Start:e.End] != e.Text {
+			t.Errorf("expression %d = %+v, want %s", i, e, want[i])
+		}
+	}
+}
+
+func TestExtractTemporalExpressionsTrailingPunctuation(t *testing.T) {
+	text := "Call them at 3pm. Standup moves to 9:30 a.m.! Is 11am? Ship by 5 p.m., then rest."
+	got := ExtractTemporalExpressions(text, temporalRef)
+	want := []string{"3pm=15:00", "9:30 a.m.=09:30", "11am=11:00", "5 p.m.=17:00"}
+	if len(got) != len(want) {
+		t.Fatalf("got %+v, want %v", got, want)
+	}
+	for i, e := range got {
+		if e.Text+"="+e.Value != want[i] || text[e.Start:e.End] != e.Text {
+			t.Errorf("expression %d = %+v, want %s", i, e, want[i])
+		}
+	}
+}
+
+func TestExtractTemporalExpressionsWithoutReference(t *testing.T) {
+	got := ExtractTemporalExpressions("by Friday, or March 3 or 2025-03-03", time.Time{})
+	if len(got) != 3 {
+		t.Fatalf("got %+v", got)
+	}
+	if !got[0].Relative || got[0].Value != "" {
+		t.Errorf("Friday resolved without a reference time: %+v", got[0])
+	}
+	if got[1].Value != "--03-03" || got[2].Value != "2025-03-03" || got[2].Relative {
+		t.Errorf("dates = %+v, %+v", got[1], got[2])
+	}
+}
+
+func TestResolveDeadlines(t *testing.T) {
+	graph := buildTestGraph([]string{"We need to write the report by next Friday.", "We need to send the invoice by 2025-04-01."})
+	graph.resolveDeadlines(temporalRef)
+	var resolved []string
+	for _, task := range graph.Tasks {
+		for _, c := range task.Constraints {
+			if c.Type == "deadline" {
+				resolved = append(resolved, c.Resolved)
+			}
+		}
+		if len(resolved) > 0 && jiraDueDate(task) != resolved[len(resolved)-1] {
+			t.Errorf("jira due date %q, want %q", jiraDueDate(task), resolved[len(resolved)-1])
+		}
+	}
+	if len(resolved) != 2 || resolved[0] != "2025-03-07" || resolved[1] != "2025-04-01" {
+		t.Errorf("resolved deadlines = %v", resolved)
+	}
+}
+
+func TestParseReferenceTime(t *testing.T) {
+	if ref, err := parseReferenceTime("2025-03-05T10:30:00+01:00"); err != nil || !ref.Equal(temporalRef.Add(-time.Hour)) {
+		t.Errorf("RFC 3339 = %v, %v", ref, err)
+	}
+	if err := (AnalysisOptions{ReferenceTime: "March 5"}).Validate(); err == nil {
+		t.Error("invalid reference time accepted")
+	}
+	if !(AnalysisOptions{Deterministic: true}).referenceTime().IsZero() {
+		t.Error("deterministic runs resolve against the clock")
+	}
+}
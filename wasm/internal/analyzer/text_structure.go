@@ -0,0 +1,72 @@
+package analyzer
+
+import (
+	"regexp"
+	"strings"
+)
+
+// StructuredCleaningSteps is the cleaning pipeline for preserve-structure
+// mode: the default without join_lines, so line breaks reach normalization
+var StructuredCleaningSteps = []string{CleanCollapseWhitespace, CleanRemoveControl, CleanTrim}
+
+// StructuredParagraph is one blank-line-separated block of the structured
+// text with its lines, so lists keep one item per line
+type StructuredParagraph struct {
+	Role  string   `json:"role"` // heading, list or body, from layout alone
+	Lines []string `json:"lines"`
+}
+
+// TextStructure gives the normalized text both flat, on one line, and with
+// its line and paragraph breaks kept
+type TextStructure struct {
+	Flat       string                `json:"flat"`
+	Structured string                `json:"structured"`
+	Paragraphs []StructuredParagraph `json:"paragraphs"`
+}
+
+type EnhancedTextStructure struct {
+	Value                TextStructure `json:"value"`
+	Scale                string        `json:"scale"`
+	HelpText             string        `json:"help_text"`
+	PracticalApplication string        `json:"practical_application"`
+}
+
+var (
+	leadingIndentPattern = regexp.MustCompile(`^[ \t]*`)
+	blankLinesPattern    = regexp.MustCompile(`\n{3,}`)
+)
+
+// normalizeStructuredText normalizes each line like normalizeText, keeping
+// line breaks, a blank line between paragraphs and list indentation
+func normalizeStructuredText(text string) string {
+	lines := strings.Split(lineBreakPattern.ReplaceAllString(text, "\n"), "\n")
+	for i, line := range lines {
+		indent := strings.ReplaceAll(leadingIndentPattern.FindString(line), "\t", "  ")
+		if body := normalizeText(line); body != "" {
+			lines[i] = indent + body
+		} else {
+			lines[i] = ""
+		}
+	}
+	structured := blankLinesPattern.ReplaceAllString(strings.Join(lines, "\n"), "\n\n")
+	return strings.Trim(structured, "\n")
+}
+
+// analyzeTextStructure splits structured text into paragraphs of lines
+func analyzeTextStructure(structured string) TextStructure {
+	ts := TextStructure{Flat: normalizeText(structured), Structured: structured, Paragraphs: []StructuredParagraph{}}
+	for _, b := range paragraphBounds(structured) {
+		para := structured[b[0]:b[1]]
+		ts.Paragraphs = append(ts.Paragraphs, StructuredParagraph{Role: layoutRole(para), Lines: strings.Split(para, "\n")})
+	}
+	return ts
+}
+
+func newEnhancedTextStructure(structured string) *EnhancedTextStructure {
+	return &EnhancedTextStructure{
+		Value:                analyzeTextStructure(structured),
+		Scale:                "Flat and structured text, paragraphs of lines",
+		HelpText:             "The normalized text on one line (flat) and with its line and paragraph breaks kept (structured), plus each paragraph's lines and layout role.",
+		PracticalApplication: "Use the structured form for list and section analysis and the flat form for sentence-level tools that expect one line.",
+	}
+}
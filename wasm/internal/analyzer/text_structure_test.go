@@ -0,0 +1,43 @@
+package analyzer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPreserveStructure(t *testing.T) {
+	text := "# Plan\r\n\r\nSteps:\n- Write   the tests\n  - cover\tedge cases\n- Ship it\n\n\n\nThanks  for the help. "
+	opts := AnalysisOptions{PreserveStructure: true}
+	data := PreprocessTextWithOptions(text, PreprocessOptions{Cleaning: opts.cleaningSteps(), PreserveStructure: true})
+
+	want := "# Plan\n\nSteps:\n- Write the tests\n  - cover edge cases\n- Ship it\n\nThanks for the help."
+	if got := data.NormalizedText.Value; got != want {
+		t.Errorf("normalized = %q\nwant %q", got, want)
+	}
+	if data.StructuredText == nil {
+		t.Fatal("structured_text missing in preserve-structure mode")
+	}
+	s := data.StructuredText.Value
+	if s.Flat != "# Plan Steps: - Write the tests - cover edge cases - Ship it Thanks for the help." || s.Structured != want {
+		t.Errorf("flat = %q, structured = %q", s.Flat, s.Structured)
+	}
+	wantParas := []StructuredParagraph{
+		{Role: RoleHeading, Lines: []string{"# Plan"}},
+		{Role: RoleList, Lines: []string{"Steps:", "- Write the tests", "  - cover edge cases", "- Ship it"}},
+		{Role: RoleBody, Lines: []string{"Thanks for the help."}},
+	}
+	if !reflect.DeepEqual(s.Paragraphs, wantParas) {
+		t.Errorf("paragraphs = %+v", s.Paragraphs)
+	}
+	for _, step := range data.TransformationLog.Value[1:] {
+		before, after, err := data.StepText(step.Step)
+		if got, _ := ApplyEdits(before, step.Diff); err != nil || got != after {
+			t.Errorf("%s: diff does not rebuild the step (%v)", step.Step, err)
+		}
+	}
+
+	flat := PreprocessText(text)
+	if flat.StructuredText != nil || flat.NormalizedText.Value != s.Flat {
+		t.Errorf("default mode normalized = %q, want the flat variant", flat.NormalizedText.Value)
+	}
+}
@@ -0,0 +1,139 @@
+package analyzer
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Title suggestion styles
+const (
+	TitleFromGoal     = "goal"     // The primary goal as a headline
+	TitleFromTopic    = "topic"    // The leading key concepts
+	TitleFromHowTo    = "how_to"   // "How to..." built from the goal
+	TitleFromQuestion = "question" // A question the text answers
+	TitleFromGuide    = "guide"    // "A Guide to..." built from the concepts
+)
+
+// maxTitleWords keeps goal-based titles short enough for a subject line
+const maxTitleWords = 10
+
+// maxTitleSuggestions caps how many candidates are offered
+const maxTitleSuggestions = 5
+
+// TitleSuggestion is one candidate title or subject line
+type TitleSuggestion struct {
+	Title string `json:"title"`
+	Style string `json:"style"` // goal, topic, how_to, question or guide
+}
+
+var (
+	// goalLeadInPattern strips "The goal is to", "I want you to", "Please" from a goal
+	goalLeadInPattern = regexp.MustCompile(`(?i)^(?:(?:(?:my|our|the|your|this|main|primary|overall|end|ultimate|key)\s+)+(?:goal|objective|aim|purpose|task|mission)\s+(?:here\s+)?(?:is|will be)\s+(?:to\s+)?|(?:i|we)(?:'m| am|'re| are) (?:trying|looking|hoping) to\s+|(?:i|we) (?:want|need|would like|'d like) (?:you )?to\s+|your (?:job|role) is to\s+|(?:please|kindly|can you|could you|help me)\s+)+`)
+	// goalClauseBreak ends the part of a goal worth putting in a title
+	goalClauseBreak = regexp.MustCompile(`(?i)[,;:.!?()]|\s(?:so that|because|in order to|which|while|using)\s`)
+)
+
+// titleSmallWords stay lowercase inside a title
+var titleSmallWords = map[string]bool{
+	"a": true, "an": true, "the": true, "and": true, "or": true, "but": true, "nor": true,
+	"for": true, "to": true, "of": true, "in": true, "on": true, "at": true, "by": true,
+	"with": true, "from": true, "as": true, "vs": true, "via": true,
+}
+
+// SuggestTitles drafts three to five candidate titles from the primary goal
+// and the key concepts: the goal as a headline, a topic line, and how-to,
+// question and guide variants. Text with neither a goal nor key concepts gets
+// no suggestions.
+func SuggestTitles(concepts []KeyConcept, goals GoalExtraction) []TitleSuggestion {
+	titles := []TitleSuggestion{}
+	seen := map[string]bool{}
+	add := func(style, title string) {
+		key := strings.ToLower(title)
+		if title == "" || seen[key] || len(titles) >= maxTitleSuggestions {
+			return
+		}
+		seen[key] = true
+		titles = append(titles, TitleSuggestion{Title: title, Style: style})
+	}
+
+	topics := make([]string, 0, 3)
+	for _, c := range concepts {
+		if len(topics) == cap(topics) {
+			break
+		}
+		if c.Concept != "" {
+			topics = append(topics, titleCase(c.Concept))
+		}
+	}
+
+	goal := ""
+	if len(goals.Primary) > 0 {
+		goal = goalTitlePhrase(goals.Primary[0].Span.Text)
+	}
+	if goal != "" {
+		add(TitleFromGoal, titleCase(goal))
+		// "How to" needs the goal to open with its verb and to be a doing goal
+		verb := strings.ToLower(strings.Fields(goal)[0])
+		if intent, ok := goalIntents[verb]; ok && intent != "explain" && intent != "find" {
+			add(TitleFromHowTo, "How to "+titleCase(goal))
+		}
+	}
+
+	switch len(topics) {
+	case 0:
+	case 1:
+		add(TitleFromTopic, topics[0])
+	default:
+		add(TitleFromTopic, topics[0]+" and "+topics[1])
+	}
+	if len(topics) > 0 {
+		switch goals.Intent {
+		case "explain", "":
+			add(TitleFromQuestion, "What Is "+topics[0]+"?")
+		case "analyze":
+			add(TitleFromQuestion, "What Does "+topics[0]+" Tell Us?")
+		case "decide":
+			add(TitleFromQuestion, "Which "+topics[0]+" Should You Choose?")
+		default:
+			add(TitleFromQuestion, "What Matters Most About "+topics[0]+"?")
+		}
+		add(TitleFromGuide, "A Guide to "+strings.Join(topics, ", "))
+		if len(topics) > 1 {
+			add(TitleFromTopic, topics[0]+": "+strings.Join(topics[1:], " and "))
+		}
+	}
+	return titles
+}
+
+// goalTitlePhrase trims a goal sentence to the verb phrase worth titling:
+// lead-ins and trailing clauses go, and overly long goals give no title
+func goalTitlePhrase(goal string) string {
+	phrase := goalLeadInPattern.ReplaceAllString(strings.TrimSpace(goal), "")
+	if m := goalClauseBreak.FindStringIndex(phrase); m != nil {
+		phrase = phrase[:m[0]]
+	}
+	words := strings.Fields(phrase)
+	if len(words) < 2 || len(words) > maxTitleWords {
+		return ""
+	}
+	return strings.Join(words, " ")
+}
+
+// titleCase capitalizes each word except small words after the first, and
+// leaves words that already contain capitals (API, iPhone) alone
+func titleCase(s string) string {
+	words := strings.Fields(s)
+	for i, w := range words {
+		if strings.IndexFunc(w, unicode.IsUpper) >= 0 {
+			continue
+		}
+		if i > 0 && titleSmallWords[w] {
+			continue
+		}
+		r := []rune(w)
+		r[0] = unicode.ToUpper(r[0])
+		words[i] = string(r)
+	}
+	return strings.Join(words, " ")
+}
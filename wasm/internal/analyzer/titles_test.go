@@ -0,0 +1,54 @@
+package analyzer
+
+import "testing"
+
+func TestSuggestTitlesFromGoalAndConcepts(t *testing.T) {
+	text := "I want you to write a migration plan for the billing database, so that we can retire the old cluster. " +
+		"The billing database holds invoices and payments. The migration must keep invoices available."
+	titles := SuggestTitles(AnalyzeIdeas(text).KeyConcepts.Value, ExtractGoals(text))
+	if len(titles) < 3 || len(titles) > 5 {
+		t.Fatalf("got %d titles, want 3-5: %+v", len(titles), titles)
+	}
+	if titles[0].Title != "Write a Migration Plan for the Billing Database" || titles[0].Style != TitleFromGoal {
+		t.Errorf("goal title = %+v", titles[0])
+	}
+	if titles[1].Title != "How to Write a Migration Plan for the Billing Database" || titles[1].Style != TitleFromHowTo {
+		t.Errorf("how-to title = %+v", titles[1])
+	}
+	seen := map[string]bool{}
+	for _, title := range titles {
+		if seen[title.Title] {
+			t.Errorf("duplicate title %q", title.Title)
+		}
+		seen[title.Title] = true
+	}
+}
+
+func TestSuggestTitlesWithoutGoal(t *testing.T) {
+	concepts := []KeyConcept{{Concept: "photosynthesis"}, {Concept: "chlorophyll"}}
+	titles := SuggestTitles(concepts, GoalExtraction{})
+	want := []string{"Photosynthesis and Chlorophyll", "What Is Photosynthesis?", "A Guide to Photosynthesis, Chlorophyll", "Photosynthesis: Chlorophyll"}
+	if len(titles) != len(want) {
+		t.Fatalf("got %+v, want %v", titles, want)
+	}
+	for i, w := range want {
+		if titles[i].Title != w {
+			t.Errorf("title %d = %q, want %q", i, titles[i].Title, w)
+		}
+	}
+	if got := SuggestTitles(nil, GoalExtraction{}); len(got) != 0 {
+		t.Errorf("empty input gave %+v", got)
+	}
+}
+
+func TestTitleCase(t *testing.T) {
+	for in, want := range map[string]string{
+		"the state of the API":   "The State of the API",
+		"fix  flaky tests in ci": "Fix Flaky Tests in Ci",
+		"iPhone battery tips":    "iPhone Battery Tips",
+	} {
+		if got := titleCase(in); got != want {
+			t.Errorf("titleCase(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package analyzer
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// StreamToken is one non-whitespace token with everything a highlighter or
+// search index needs to use it on its own
+type StreamToken struct {
+	Text       string    `json:"text"`
+	Type       TokenType `json:"type"`
+	Start      int       `json:"start"` // Byte offsets in the analyzed text
+	End        int       `json:"end"`
+	CharStart  int       `json:"char_start"` // UTF-16 offsets, as JavaScript strings index
+	CharEnd    int       `json:"char_end"`
+	POS        string    `json:"pos"` // noun, verb, adjective, adverb or unknown for words; the token type otherwise
+	Lemma      string    `json:"lemma"`
+	IsStopWord bool      `json:"is_stop_word"`
+	Syllables  int       `json:"syllables"`
+}
+
+// posTag tags a word with the same lexicon analyzePOS counts with; other
+// tokens are tagged with their type
+func posTag(token Token) string {
+	if token.Type != Word {
+		return string(token.Type)
+	}
+	word := strings.ToLower(token.Text)
+	switch {
+	case commonNouns[word]:
+		return "noun"
+	case commonVerbs[word]:
+		return "verb"
+	case commonAdjectives[word]:
+		return "adjective"
+	case strings.HasSuffix(word, "ly"):
+		return "adverb"
+	}
+	return "unknown"
+}
+
+// tokenStream lists the non-whitespace tokens of text with byte and UTF-16
+// offsets and POS tags. Tokens are in text order, so offsets are counted in
+// one pass.
+func tokenStream(text string, tokens []Token) []StreamToken {
+	stream := make([]StreamToken, 0, len(tokens))
+	pos, units := 0, 0
+	advance := func(to int) int {
+		for pos < to && pos < len(text) {
+			r, size := utf8.DecodeRuneInString(text[pos:])
+			units += utf16Len(r)
+			pos += size
+		}
+		return units
+	}
+	for _, t := range tokens {
+		if t.Type == Whitespace {
+			continue
+		}
+		start := advance(t.Position)
+		stream = append(stream, StreamToken{
+			Text:       t.Text,
+			Type:       t.Type,
+			Start:      t.Position,
+			End:        t.Position + t.Length,
+			CharStart:  start,
+			CharEnd:    advance(t.Position + t.Length),
+			POS:        posTag(t),
+			Lemma:      t.Lemma,
+			IsStopWord: t.IsStopWord,
+			Syllables:  t.Syllables,
+		})
+	}
+	return stream
+}
+
+// utf16Len is how many UTF-16 code units a rune takes; invalid bytes decode
+// to U+FFFD, one unit
+func utf16Len(r rune) int {
+	if r >= 0x10000 {
+		return 2
+	}
+	return 1
+}
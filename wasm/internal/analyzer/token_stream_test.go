@@ -0,0 +1,41 @@
+package analyzer
+
+import (
+	"context"
+	"testing"
+	"unicode/utf16"
+)
+
+func TestTokenStream(t *testing.T) {
+	text := "Café 🚀 quickly ships the code, v2."
+	result, err := Analyze(context.Background(), text, AnalysisOptions{Stages: []string{StageTokens}, TokenStream: true}, AnalysisRun{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stream := result.Tokens.Stream
+	js := utf16.Encode([]rune(text))
+	tags := map[string]string{}
+	for _, tok := range stream {
+		if tok.Type == Whitespace {
+			t.Fatalf("whitespace token in stream: %+v", tok)
+		}
+		if text[tok.Start:tok.End] != tok.Text {
+			t.Errorf("byte offsets %d-%d do not cover %q", tok.Start, tok.End, tok.Text)
+		}
+		if got := string(utf16.Decode(js[tok.CharStart:tok.CharEnd])); got != tok.Text {
+			t.Errorf("UTF-16 offsets %d-%d cover %q, want %q", tok.CharStart, tok.CharEnd, got, tok.Text)
+		}
+		tags[tok.Text] = tok.POS
+	}
+	if tags["quickly"] != "adverb" || tags["the"] != "unknown" || tags[","] != string(Punctuation) {
+		t.Errorf("POS tags = %v", tags)
+	}
+
+	plain, err := Analyze(context.Background(), text, AnalysisOptions{Stages: []string{StageTokens}}, AnalysisRun{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plain.Tokens.Stream) != 0 {
+		t.Errorf("token stream returned without token_stream: %d", len(plain.Tokens.Stream))
+	}
+}
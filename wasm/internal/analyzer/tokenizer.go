@@ -11,10 +11,15 @@ type TokenData struct {
 	Tokens              []Token           `json:"tokens"`
 	TokenCounts         TokenCounts       `json:"token_counts"`
 	NGrams              NGramData         `json:"ngrams"`
+	Phrases             PhraseStatistics  `json:"phrases"` // Frequent bigrams and trigrams, and collocations
 	PartOfSpeech        POSAnalysis       `json:"part_of_speech"`
 	SyntacticStructure  SyntaxAnalysis    `json:"syntactic_structure"`
 	SemanticFeatures    SemanticAnalysis  `json:"semantic_features"`
 	CharacterAnalysis   CharAnalysis      `json:"character_analysis"`
+	StopwordLanguage    string            `json:"stopword_language"` // Language of the stopword list applied
+	// Stream is the per-token view with offsets and POS tags, set when
+	// AnalysisOptions.TokenStream asks for it
+	Stream []StreamToken `json:"stream,omitempty"`
 }
 
 type Token struct {
@@ -101,6 +106,7 @@ type SentimentScore struct {
 	Negative float64 `json:"negative"`
 	Neutral  float64 `json:"neutral"`
 	Overall  float64 `json:"overall"`
+	Emoji    float64 `json:"emoji"` // Part of Overall contributed by emoji and emoticons
 }
 
 type CharAnalysis struct {
@@ -116,20 +122,6 @@ type CharAnalysis struct {
 	Languages     []string       `json:"detected_languages"`
 }
 
-var stopWords = map[string]bool{
-	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true,
-	"be": true, "by": true, "for": true, "from": true, "has": true, "he": true,
-	"in": true, "is": true, "it": true, "its": true, "of": true, "on": true,
-	"that": true, "the": true, "to": true, "was": true, "will": true, "with": true,
-	"but": true, "or": true, "so": true, "if": true, "when": true, "where": true,
-	"why": true, "how": true, "what": true, "who": true, "which": true, "this": true,
-	"these": true, "those": true, "they": true, "them": true, "their": true,
-	"we": true, "us": true, "our": true, "you": true, "your": true, "i": true,
-	"me": true, "my": true, "can": true, "could": true, "should": true, "would": true,
-	"do": true, "does": true, "did": true, "have": true, "had": true, "been": true,
-	"being": true, "am": true, "were": true, "said": true, "say": true, "says": true,
-}
-
 var commonNouns = map[string]bool{
 	"time": true, "person": true, "year": true, "way": true, "day": true, "thing": true,
 	"man": true, "world": true, "life": true, "hand": true, "part": true, "child": true,
@@ -155,7 +147,15 @@ var commonAdjectives = map[string]bool{
 }
 
 func TokenizeText(text string) TokenData {
-	tokens := extractTokens(text)
+	data := TokenizeTextWithStopwords(text, stopWords)
+	data.StopwordLanguage = "en"
+	return data
+}
+
+// TokenizeTextWithStopwords tokenizes text, flagging words in stop as stopwords.
+// The caller records which language the set was built for.
+func TokenizeTextWithStopwords(text string, stop StopwordSet) TokenData {
+	tokens := extractTokens(text, stop)
 
 	tokenData := TokenData{
 		Tokens:             tokens,
@@ -165,53 +165,68 @@ func TokenizeText(text string) TokenData {
 		SyntacticStructure: analyzeSyntax(text),
 		SemanticFeatures:   analyzeSemantics(text, tokens),
 		CharacterAnalysis:  analyzeCharacters(text),
+		Phrases:            analyzePhrases(wordRuns(tokens), stop),
 	}
 
 	return tokenData
 }
 
-func extractTokens(text string) []Token {
-	var tokens []Token
-	position := 0
+var tokenPatterns = map[TokenType]*regexp.Regexp{
+	URL:          urlPattern,
+	Email:        emailPattern,
+	Hashtag:      hashtagPattern,
+	Mention:      atMentionPattern,
+	Number:       regexp.MustCompile(`\d+\.?\d*`),
+	Contraction:  regexp.MustCompile(`[\p{L}\p{N}]+['’][\p{L}\p{N}]+`),
+	Abbreviation: regexp.MustCompile(`[A-Z]{2,}\.|[A-Z]\.[A-Z]\.`),
+	Word:         regexp.MustCompile(`\p{L}[\p{L}\p{M}]*`),
+	Punctuation:  regexp.MustCompile(`[.!?;:,'"()\[\]{}-]`),
+	Symbol:       regexp.MustCompile(`[^\p{L}\p{M}\p{N}\s.!?;:,'"()\[\]{}-]`),
+	Whitespace:   whitespaceRunPattern,
+}
+
+// tokenPatternOrder is the order patterns are tried in, so a URL is not split
+// into words and "don't" is a contraction rather than "don"
+var tokenPatternOrder = []TokenType{
+	URL, Email, Hashtag, Mention, Number, Contraction, Abbreviation, Word, Punctuation, Symbol, Whitespace,
+}
 
-	patterns := map[TokenType]*regexp.Regexp{
-		URL:          regexp.MustCompile(`https?://[^\s]+`),
-		Email:        regexp.MustCompile(`[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}`),
-		Hashtag:      regexp.MustCompile(`#\w+`),
-		Mention:      regexp.MustCompile(`@\w+`),
-		Number:       regexp.MustCompile(`\d+\.?\d*`),
-		Contraction:  regexp.MustCompile(`\w+'\w+`),
-		Abbreviation: regexp.MustCompile(`[A-Z]{2,}\.|[A-Z]\.[A-Z]\.`),
-		Word:         regexp.MustCompile(`\b[a-zA-Z]+\b`),
-		Punctuation:  regexp.MustCompile(`[.!?;:,'"()\[\]{}-]`),
-		Symbol:       regexp.MustCompile(`[^a-zA-Z0-9\s.!?;:,'"()\[\]{}-]`),
-		Whitespace:   regexp.MustCompile(`\s+`),
+// anchoredTokenPatterns only match at the start of the remaining text, so each
+// token costs its own length instead of a search through the rest of the text
+var anchoredTokenPatterns = func() map[TokenType]*regexp.Regexp {
+	anchored := make(map[TokenType]*regexp.Regexp, len(tokenPatterns))
+	for tokenType, pattern := range tokenPatterns {
+		anchored[tokenType] = regexp.MustCompile(`^(?:` + pattern.String() + `)`)
 	}
+	return anchored
+}()
+
+func extractTokens(text string, stop StopwordSet) []Token {
+	var tokens []Token
+	position := 0
 
 	frequencyMap := make(map[string]int)
 
 	for position < len(text) {
 		matched := false
 
-		for tokenType, pattern := range patterns {
-			if match := pattern.FindString(text[position:]); match != "" {
-				if pattern.FindStringIndex(text[position:])[0] == 0 {
-					token := Token{
-						Text:       match,
-						Type:       tokenType,
-						Position:   position,
-						Length:     len(match),
-						Syllables:  countSyllables(match),
-						IsStopWord: isStopWord(match),
-						Lemma:      getLemma(match),
-					}
-
-					frequencyMap[strings.ToLower(match)]++
-					tokens = append(tokens, token)
-					position += len(match)
-					matched = true
-					break
+		for _, tokenType := range tokenPatternOrder {
+			if match := anchoredTokenPatterns[tokenType].FindString(text[position:]); match != "" {
+				token := Token{
+					Text:       match,
+					Type:       tokenType,
+					Position:   position,
+					Length:     len(match),
+					Syllables:  countSyllables(match),
+					IsStopWord: stop.Contains(match),
+					Lemma:      getLemma(match),
 				}
+
+				frequencyMap[strings.ToLower(match)]++
+				tokens = append(tokens, token)
+				position += len(match)
+				matched = true
+				break
 			}
 		}
 
@@ -318,22 +333,19 @@ func analyzePOS(tokens []Token) POSAnalysis {
 	for _, token := range tokens {
 		if token.Type == Word {
 			word := strings.ToLower(token.Text)
+			tag := posTag(token)
 
-			if commonNouns[word] {
+			switch tag {
+			case "noun":
 				analysis.Nouns = append(analysis.Nouns, word)
-				analysis.Distribution["noun"]++
-			} else if commonVerbs[word] {
+			case "verb":
 				analysis.Verbs = append(analysis.Verbs, word)
-				analysis.Distribution["verb"]++
-			} else if commonAdjectives[word] {
+			case "adjective":
 				analysis.Adjectives = append(analysis.Adjectives, word)
-				analysis.Distribution["adjective"]++
-			} else if strings.HasSuffix(word, "ly") {
+			case "adverb":
 				analysis.Adverbs = append(analysis.Adverbs, word)
-				analysis.Distribution["adverb"]++
-			} else {
-				analysis.Distribution["unknown"]++
 			}
+			analysis.Distribution[tag]++
 		}
 	}
 
@@ -373,7 +385,7 @@ func analyzeSemantics(text string, tokens []Token) SemanticAnalysis {
 	entities := extractNamedEntities(text)
 	analysis.NamedEntities = entities
 
-	analysis.SentimentScores = calculateSentiment(tokens)
+	analysis.SentimentScores = calculateSentiment(tokens, AnalyzeEmoji(text).Occurrences)
 
 	topics := []string{"technology", "business", "science", "politics", "sports", "entertainment"}
 	for _, topic := range topics {
@@ -383,10 +395,12 @@ func analyzeSemantics(text string, tokens []Token) SemanticAnalysis {
 	return analysis
 }
 
+var capitalizedWordPattern = regexp.MustCompile(`\b[A-Z][a-z]+\b`)
+
 func extractNamedEntities(text string) []NamedEntity {
 	var entities []NamedEntity
 
-	capitalizedWords := regexp.MustCompile(`\b[A-Z][a-z]+\b`)
+	capitalizedWords := capitalizedWordPattern
 	matches := capitalizedWords.FindAllStringIndex(text, -1)
 
 	for _, match := range matches {
@@ -402,7 +416,7 @@ func extractNamedEntities(text string) []NamedEntity {
 	return entities
 }
 
-func calculateSentiment(tokens []Token) SentimentScore {
+func calculateSentiment(tokens []Token, emoji []Emoji) SentimentScore {
 	positiveWords := map[string]bool{
 		"good": true, "great": true, "excellent": true, "amazing": true, "wonderful": true,
 		"fantastic": true, "awesome": true, "brilliant": true, "outstanding": true, "perfect": true,
@@ -430,6 +444,19 @@ func calculateSentiment(tokens []Token) SentimentScore {
 		}
 	}
 
+	// Each emoji counts as one unit, weighted by the strength of its tone
+	wordTone := float64(positive - negative)
+	emojiTone := 0.0
+	for _, e := range emoji {
+		total++
+		emojiTone += e.Sentiment
+		if e.Sentiment > 0 {
+			positive++
+		} else if e.Sentiment < 0 {
+			negative++
+		}
+	}
+
 	neutral := total - positive - negative
 
 	if total == 0 {
@@ -440,7 +467,8 @@ func calculateSentiment(tokens []Token) SentimentScore {
 		Positive: float64(positive) / float64(total),
 		Negative: float64(negative) / float64(total),
 		Neutral:  float64(neutral) / float64(total),
-		Overall:  (float64(positive) - float64(negative)) / float64(total),
+		Overall:  (wordTone + emojiTone) / float64(total),
+		Emoji:    emojiTone / float64(total),
 	}
 }
 
@@ -479,7 +507,7 @@ func analyzeCharacters(text string) CharAnalysis {
 }
 
 func isStopWord(word string) bool {
-	return stopWords[strings.ToLower(word)]
+	return stopWords.Contains(word)
 }
 
 func getLemma(word string) string {
@@ -0,0 +1,343 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// TextEdit replaces Before[Start:End] (byte offsets) with Text. Applying a
+// step's edits in order to its input gives its output. Edits are aligned by
+// word and then narrowed to the characters that changed, so "Running" to
+// "run" is a replace of "R" and a delete of "ning".
+type TextEdit struct {
+	Op         string `json:"op"` // insert, delete or replace
+	Start      int    `json:"start"`
+	End        int    `json:"end"`
+	Text       string `json:"text"`
+	AfterStart int    `json:"after_start"` // Where Text begins in the step's output, for highlighting it there
+}
+
+// TextEdit operations
+const (
+	EditInsert  = "insert"
+	EditDelete  = "delete"
+	EditReplace = "replace"
+)
+
+// maxDiffEdits bounds the insertions and deletions a diff searches for
+const maxDiffEdits = 600
+
+// ApplyEdits rebuilds a step's output from its input and diff
+func ApplyEdits(before string, edits []TextEdit) (string, error) {
+	var b strings.Builder
+	last := 0
+	for _, e := range edits {
+		if e.Start < last || e.End < e.Start || e.End > len(before) {
+			return "", fmt.Errorf("edit %d-%d out of order or past the text", e.Start, e.End)
+		}
+		b.WriteString(before[last:e.Start])
+		b.WriteString(e.Text)
+		last = e.End
+	}
+	b.WriteString(before[last:])
+	return b.String(), nil
+}
+
+// newTransformStep logs a preprocessing step as lengths plus a word diff, or
+// with the full texts when full is set
+func newTransformStep(step, description, before, after string, full bool) TransformStep {
+	s := TransformStep{Step: step, Description: description, BeforeLength: len(before), AfterLength: len(after)}
+	if full {
+		s.Before, s.After = before, after
+	} else if step != "original" {
+		// The original step's text is already in original_text
+		s.Diff = diffText(before, after)
+	}
+	return s
+}
+
+// maxCharDiffBytes bounds the changed runs of words that get a character
+// diff; longer runs are only narrowed to their changed middle
+const maxCharDiffBytes = 256
+
+// diffText aligns before and after by word, then diffs each changed run of
+// words by character
+func diffText(before, after string) []TextEdit {
+	if before == after {
+		return nil
+	}
+	var edits []TextEdit
+	for _, run := range diffEdits(diffTokens(before), diffTokens(after), 0) {
+		if run.End-run.Start > maxCharDiffBytes || len(run.Text) > maxCharDiffBytes {
+			edits = append(edits, narrowEdit(before, run))
+			continue
+		}
+		for _, e := range diffEdits(runeTokens(before[run.Start:run.End]), runeTokens(run.Text), run.Start) {
+			edits = append(edits, narrowEdit(before, e))
+		}
+	}
+	return finishEdits(edits)
+}
+
+// diffEdits turns the shortest edit script from a to b into one edit per run
+// of changed tokens, with offsets counted from start. Past maxDiffEdits it
+// gives one edit covering everything between the unchanged prefix and suffix.
+func diffEdits(a, b []string, start int) []TextEdit {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	start += tokenLength(a[:prefix])
+	a, b = a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+
+	ops, ok := myersDiff(a, b)
+	if !ok {
+		return []TextEdit{{Start: start, End: start + tokenLength(a), Text: strings.Join(b, "")}}
+	}
+	var edits []TextEdit
+	pos, i, j := start, 0, 0
+	for k := 0; k < len(ops); {
+		if ops[k] == diffKeep {
+			pos += len(a[i])
+			i, j, k = i+1, j+1, k+1
+			continue
+		}
+		edit := TextEdit{Start: pos, End: pos}
+		var text strings.Builder
+		for ; k < len(ops) && ops[k] != diffKeep; k++ {
+			if ops[k] == diffDelete {
+				edit.End += len(a[i])
+				i++
+			} else {
+				text.WriteString(b[j])
+				j++
+			}
+		}
+		edit.Text = text.String()
+		pos = edit.End
+		edits = append(edits, edit)
+	}
+	return edits
+}
+
+// runeTokens splits text into single characters for a character diff
+func runeTokens(text string) []string {
+	tokens := make([]string, 0, len(text))
+	for text != "" {
+		_, n := utf8.DecodeRuneInString(text)
+		tokens, text = append(tokens, text[:n]), text[n:]
+	}
+	return tokens
+}
+
+// narrowEdit drops the characters an edit's old and new text share at either
+// end, so only what changed is highlighted
+func narrowEdit(before string, e TextEdit) TextEdit {
+	old := before[e.Start:e.End]
+	for old != "" && e.Text != "" {
+		_, n := utf8.DecodeRuneInString(old)
+		if !strings.HasPrefix(e.Text, old[:n]) {
+			break
+		}
+		old, e.Text, e.Start = old[n:], e.Text[n:], e.Start+n
+	}
+	for old != "" && e.Text != "" {
+		_, n := utf8.DecodeLastRuneInString(old)
+		if !strings.HasSuffix(e.Text, old[len(old)-n:]) {
+			break
+		}
+		old, e.Text = old[:len(old)-n], e.Text[:len(e.Text)-n]
+	}
+	e.End = e.Start + len(old)
+	return e
+}
+
+// finishEdits drops empty edits and sets each one's operation and position
+// in the output
+func finishEdits(edits []TextEdit) []TextEdit {
+	kept := edits[:0]
+	for _, e := range edits {
+		if e.Start != e.End || e.Text != "" {
+			kept = append(kept, e)
+		}
+	}
+	edits = kept
+	shift := 0
+	for i := range edits {
+		e := &edits[i]
+		switch {
+		case e.Start == e.End:
+			e.Op = EditInsert
+		case e.Text == "":
+			e.Op = EditDelete
+		default:
+			e.Op = EditReplace
+		}
+		e.AfterStart = e.Start + shift
+		shift += len(e.Text) - (e.End - e.Start)
+	}
+	return edits
+}
+
+// diffTokens splits text into words, each with the whitespace after it, so
+// dropping a word also drops its space
+func diffTokens(text string) []string {
+	var tokens []string
+	start := 0
+	inSpace := false
+	for i, r := range text {
+		space := unicode.IsSpace(r)
+		if !space && inSpace {
+			tokens = append(tokens, text[start:i])
+			start = i
+		}
+		inSpace = space
+	}
+	if start < len(text) {
+		tokens = append(tokens, text[start:])
+	}
+	return tokens
+}
+
+func tokenLength(tokens []string) int {
+	n := 0
+	for _, t := range tokens {
+		n += len(t)
+	}
+	return n
+}
+
+type diffOp byte
+
+const (
+	diffKeep diffOp = iota
+	diffDelete
+	diffInsert
+)
+
+// myersDiff returns the shortest edit script from a to b, or false when it
+// needs more than maxDiffEdits insertions and deletions
+func myersDiff(a, b []string) ([]diffOp, bool) {
+	n, m := len(a), len(b)
+	offset := maxDiffEdits + 1
+	v := make([]int, 2*offset+1)
+	var trace [][]int
+	for d := 0; d <= maxDiffEdits; d++ {
+		trace = append(trace, append([]int(nil), v[offset-d:offset+d+1]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x, y = x+1, y+1
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				return myersBacktrack(trace, n, m), true
+			}
+		}
+	}
+	return nil, false
+}
+
+// myersBacktrack walks the saved frontiers back from (n, m); trace[d] holds
+// the frontier before round d for diagonals -d..d
+func myersBacktrack(trace [][]int, n, m int) []diffOp {
+	var ops []diffOp
+	x, y := n, m
+	for d := len(trace) - 1; d > 0; d-- {
+		v := trace[d]
+		at := func(k int) int { return v[k+d] }
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := at(prevK)
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			ops = append(ops, diffKeep)
+			x, y = x-1, y-1
+		}
+		if x == prevX {
+			ops = append(ops, diffInsert)
+		} else {
+			ops = append(ops, diffDelete)
+		}
+		x, y = prevX, prevY
+	}
+	for x > 0 && y > 0 {
+		ops = append(ops, diffKeep)
+		x, y = x-1, y-1
+	}
+	for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+		ops[i], ops[j] = ops[j], ops[i]
+	}
+	return ops
+}
+
+// StepText returns the full input and output of one preprocessing step, for
+// clients that only keep the diffed transformation log
+func (p PreprocessingData) StepText(step string) (before, after string, err error) {
+	texts := []struct{ step, text string }{
+		{"original", p.OriginalText.Value},
+		{"cleaning", p.CleanedText.Value},
+		{"normalization", p.NormalizedText.Value},
+		{"lowercase", p.LowercaseText.Value},
+		{"stop_words_removal", p.WithoutStopWords.Value},
+		{"stemming", p.StemmedText.Value},
+		{"lemmatization", p.LemmatizedText.Value},
+	}
+	if strings.HasPrefix(step, "cleaning:") {
+		return p.replayStep(step)
+	}
+	for i, t := range texts {
+		if t.step != step {
+			continue
+		}
+		switch {
+		case i == 0:
+			return "", t.text, nil
+		case step == "lemmatization":
+			// Lemmatization starts from the stop-word-free text, like stemming
+			return p.WithoutStopWords.Value, t.text, nil
+		}
+		return texts[i-1].text, t.text, nil
+	}
+	return "", "", fmt.Errorf("unknown transformation step %q", step)
+}
+
+// replayStep rebuilds the texts around a cleaning step, whose intermediate
+// results are not kept, by applying each logged cleaning step to the original
+func (p PreprocessingData) replayStep(step string) (before, after string, err error) {
+	text := p.OriginalText.Value
+	for _, s := range p.TransformationLog.Value {
+		if !strings.HasPrefix(s.Step, "cleaning:") {
+			continue
+		}
+		next, err := ApplyEdits(text, s.Diff)
+		if err != nil {
+			return "", "", fmt.Errorf("%s: %w", s.Step, err)
+		}
+		if s.Diff == nil && s.AfterLength == len(s.After) {
+			next = s.After // Full log, or an empty text
+		}
+		if s.Step == step {
+			return text, next, nil
+		}
+		text = next
+	}
+	return "", "", fmt.Errorf("unknown transformation step %q", step)
+}
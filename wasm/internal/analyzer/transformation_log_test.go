@@ -0,0 +1,73 @@
+package analyzer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTransformationLogDiffsRebuildEachStep(t *testing.T) {
+	data := PreprocessText("  The “Quick” brown   fox — jumped over the lazy dogs.\n\nThey were running and it was better. ")
+	for _, step := range data.TransformationLog.Value {
+		if step.Before != "" || step.After != "" {
+			t.Errorf("%s: full texts kept without FullLog", step.Step)
+		}
+		before, after, err := data.StepText(step.Step)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(before) != step.BeforeLength || len(after) != step.AfterLength {
+			t.Errorf("%s: lengths %d/%d, want %d/%d", step.Step, step.BeforeLength, step.AfterLength, len(before), len(after))
+		}
+		if step.Step == "original" {
+			continue
+		}
+		got, err := ApplyEdits(before, step.Diff)
+		if err != nil || got != after {
+			t.Errorf("%s: diff rebuilds %q (%v), want %q", step.Step, got, err, after)
+		}
+		for _, e := range step.Diff {
+			if after[e.AfterStart:e.AfterStart+len(e.Text)] != e.Text {
+				t.Errorf("%s: %+v does not point at its text in the output", step.Step, e)
+			}
+		}
+	}
+
+	full := PreprocessTextWithOptions("The Quick fox", PreprocessOptions{FullLog: true})
+	if step := full.TransformationLog.Value[len(DefaultCleaningSteps)+2]; step.Step != "lowercase" || step.After != "the quick fox" || step.Diff != nil {
+		t.Errorf("full log step = %+v", step)
+	}
+	if _, _, err := data.StepText("tokenizing"); err == nil {
+		t.Error("unknown step accepted")
+	}
+}
+
+func TestDiffTextNarrowsToChangedCharacters(t *testing.T) {
+	got := diffText("The Running dogs bark", "the run bark loudly")
+	want := []TextEdit{
+		{Op: EditReplace, Start: 0, End: 1, Text: "t", AfterStart: 0},
+		{Op: EditReplace, Start: 4, End: 5, Text: "r", AfterStart: 4},
+		{Op: EditDelete, Start: 7, End: 11, Text: "", AfterStart: 7},
+		{Op: EditDelete, Start: 12, End: 17, Text: "", AfterStart: 8},
+		{Op: EditInsert, Start: 21, End: 21, Text: " loudly", AfterStart: 12},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("diff = %+v\nwant %+v", got, want)
+	}
+}
+
+func TestDiffTextFallsBackToOneEdit(t *testing.T) {
+	words := make([]string, 2*maxDiffEdits)
+	for i := range words {
+		words[i] = "word"
+	}
+	before := "Start " + strings.Join(words, " ") + " end"
+	after := strings.ToUpper(before[:6]) + strings.ToUpper(strings.Join(words, " ")) + " end"
+	edits := diffText(before, after)
+	if len(edits) != 1 {
+		t.Fatalf("got %d edits, want one covering the changed range", len(edits))
+	}
+	if got, err := ApplyEdits(before, edits); err != nil || got != after {
+		t.Errorf("fallback edit does not rebuild the output: %v", err)
+	}
+}
@@ -0,0 +1,190 @@
+package analyzer
+
+import (
+	"net/url"
+	"strings"
+)
+
+// URLInfo is a URL found in the text, split into components and classified
+// by the kind of site it points at
+type URLInfo struct {
+	Span
+	Scheme     string `json:"scheme"`
+	Host       string `json:"host"`
+	Port       string `json:"port,omitempty"`
+	Path       string `json:"path,omitempty"`
+	Query      string `json:"query,omitempty"`
+	Fragment   string `json:"fragment,omitempty"`
+	Domain     string `json:"domain"`     // Registrable domain, e.g. github.com for gist.github.com
+	Category   string `json:"category"`   // One of the URLCategory* values
+	Normalized string `json:"normalized"` // Lowercase host, no default port, fragment or trailing slash
+	// Duplicate is set when the same normalized URL appears earlier in the text
+	Duplicate bool `json:"duplicate"`
+	// Credentials names what carries a secret: "userinfo" for user:pass@ or the
+	// query parameter, e.g. "access_token"; empty when there is none
+	Credentials string `json:"credentials,omitempty"`
+}
+
+// URL categories
+const (
+	URLCategoryDocs    = "docs"
+	URLCategoryRepo    = "repo"
+	URLCategoryTracker = "tracker"
+	URLCategorySocial  = "social"
+	URLCategoryOther   = "other"
+)
+
+// urlCategoryDomains classifies well-known sites by registrable domain
+var urlCategoryDomains = map[string]string{
+	"github.com": URLCategoryRepo, "gitlab.com": URLCategoryRepo, "bitbucket.org": URLCategoryRepo,
+	"codeberg.org": URLCategoryRepo, "sourceforge.net": URLCategoryRepo, "sr.ht": URLCategoryRepo,
+	"atlassian.net": URLCategoryTracker, "linear.app": URLCategoryTracker, "trello.com": URLCategoryTracker,
+	"asana.com": URLCategoryTracker, "youtrack.cloud": URLCategoryTracker, "clickup.com": URLCategoryTracker,
+	"readthedocs.io": URLCategoryDocs, "readthedocs.org": URLCategoryDocs, "gitbook.io": URLCategoryDocs,
+	"pkg.go.dev": URLCategoryDocs, "docs.rs": URLCategoryDocs, "mozilla.org": URLCategoryDocs,
+	"notion.so": URLCategoryDocs, "swagger.io": URLCategoryDocs,
+	"twitter.com": URLCategorySocial, "x.com": URLCategorySocial, "linkedin.com": URLCategorySocial,
+	"facebook.com": URLCategorySocial, "instagram.com": URLCategorySocial, "reddit.com": URLCategorySocial,
+	"youtube.com": URLCategorySocial, "youtu.be": URLCategorySocial, "tiktok.com": URLCategorySocial,
+	"threads.net": URLCategorySocial, "mastodon.social": URLCategorySocial, "bsky.app": URLCategorySocial,
+	"ycombinator.com": URLCategorySocial,
+}
+
+// secondLevelSuffixes are public suffixes with two labels, so the registrable
+// domain of www.bbc.co.uk is bbc.co.uk rather than co.uk
+var secondLevelSuffixes = map[string]bool{
+	"co.uk": true, "org.uk": true, "ac.uk": true, "gov.uk": true, "com.au": true, "net.au": true,
+	"co.jp": true, "co.nz": true, "com.br": true, "co.in": true, "com.cn": true, "com.mx": true,
+}
+
+// credentialParams are query parameters that carry secrets; signed-URL
+// signatures count since they grant access on their own
+var credentialParams = map[string]bool{
+	"token": true, "access_token": true, "refresh_token": true, "id_token": true, "auth": true,
+	"api_key": true, "apikey": true, "key": true, "secret": true, "client_secret": true,
+	"password": true, "passwd": true, "pwd": true, "sig": true, "signature": true,
+	"x-amz-signature": true, "x-amz-credential": true, "x-goog-signature": true, "sas": true,
+}
+
+// AnalyzeURLs parses each URL in text into components, classifies its site,
+// marks repeats and flags URLs that carry credentials
+func AnalyzeURLs(text string) []URLInfo {
+	out := []URLInfo{}
+	seen := map[string]bool{}
+	for _, m := range urlPattern.FindAllStringIndex(text, -1) {
+		raw := trimURL(text[m[0]:m[1]])
+		u, err := url.Parse(raw)
+		if err != nil || u.Hostname() == "" {
+			continue
+		}
+		info := URLInfo{
+			Span:     newSpan(text, m[0], m[0]+len(raw)),
+			Scheme:   strings.ToLower(u.Scheme),
+			Host:     strings.ToLower(u.Hostname()),
+			Port:     u.Port(),
+			Path:     u.EscapedPath(),
+			Query:    u.RawQuery,
+			Fragment: u.EscapedFragment(),
+		}
+		info.Domain = registrableDomain(info.Host)
+		info.Category = classifyURL(info.Host, info.Domain, info.Path)
+		info.Normalized = normalizeURL(info)
+		info.Duplicate = seen[info.Normalized]
+		seen[info.Normalized] = true
+		info.Credentials = urlCredentials(u)
+		out = append(out, info)
+	}
+	return out
+}
+
+// trimURL drops sentence punctuation the URL pattern swallows, keeping a
+// closing bracket when the URL opened one, as Wikipedia links do
+func trimURL(raw string) string {
+	for raw != "" {
+		last := raw[len(raw)-1]
+		switch {
+		case strings.IndexByte(`.,;:!?'"`, last) >= 0:
+		case last == ')' && strings.Count(raw, "(") < strings.Count(raw, ")"):
+		case last == ']' && strings.Count(raw, "[") < strings.Count(raw, "]"):
+		default:
+			return raw
+		}
+		raw = raw[:len(raw)-1]
+	}
+	return raw
+}
+
+// registrableDomain keeps the last two labels of a host, or three under a
+// two-label public suffix; IP addresses are returned as they are
+func registrableDomain(host string) string {
+	labels := strings.Split(host, ".")
+	if strings.Contains(host, ":") || len(labels) <= 2 || strings.Trim(host, "0123456789.") == "" {
+		return host
+	}
+	n := 2
+	if secondLevelSuffixes[strings.Join(labels[len(labels)-2:], ".")] {
+		n = 3
+	}
+	return strings.Join(labels[len(labels)-n:], ".")
+}
+
+// classifyURL names the kind of site a URL points at. Issue and pull request
+// pages on code hosts are trackers; docs.* and /docs/ URLs are docs.
+func classifyURL(host, domain, path string) string {
+	lowerPath := strings.ToLower(path)
+	category := urlCategoryDomains[host]
+	if category == "" {
+		category = urlCategoryDomains[domain]
+	}
+	switch {
+	case category == URLCategoryRepo && (strings.Contains(lowerPath, "/issues") || strings.Contains(lowerPath, "/pull") || strings.Contains(lowerPath, "/merge_requests")):
+		return URLCategoryTracker
+	case category == URLCategoryRepo && strings.Contains(lowerPath, "/wiki"):
+		return URLCategoryDocs
+	case category != "":
+		return category
+	case strings.HasPrefix(host, "jira.") || strings.HasPrefix(host, "bugs.") || strings.HasPrefix(host, "bugzilla.") || strings.HasPrefix(host, "issues."):
+		return URLCategoryTracker
+	case strings.HasPrefix(host, "git.") || strings.HasPrefix(host, "gitlab."):
+		return URLCategoryRepo
+	case strings.HasPrefix(host, "docs.") || strings.HasPrefix(host, "developer.") || strings.HasPrefix(host, "wiki.") ||
+		strings.HasPrefix(host, "confluence.") || strings.HasPrefix(lowerPath, "/docs") || strings.HasPrefix(lowerPath, "/documentation"):
+		return URLCategoryDocs
+	}
+	return URLCategoryOther
+}
+
+// normalizeURL builds the form used to spot duplicates: lowercase scheme and
+// host, no default port, fragment or trailing slash
+func normalizeURL(info URLInfo) string {
+	host := info.Host
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	if info.Port != "" && !(info.Scheme == "http" && info.Port == "80") && !(info.Scheme == "https" && info.Port == "443") {
+		host += ":" + info.Port
+	}
+	normalized := info.Scheme + "://" + host + strings.TrimRight(info.Path, "/")
+	if info.Query != "" {
+		normalized += "?" + info.Query
+	}
+	return normalized
+}
+
+// urlCredentials reports where a URL carries a secret: a password or
+// token-length user name in the userinfo, or the first token, key, password
+// or signature query parameter with a value
+func urlCredentials(u *url.URL) string {
+	if u.User != nil {
+		if _, ok := u.User.Password(); ok || len(u.User.Username()) >= 20 {
+			return "userinfo"
+		}
+	}
+	for _, pair := range strings.Split(u.RawQuery, "&") {
+		name, value, _ := strings.Cut(pair, "=")
+		if credentialParams[strings.ToLower(name)] && value != "" {
+			return name
+		}
+	}
+	return ""
+}
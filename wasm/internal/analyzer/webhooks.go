@@ -0,0 +1,322 @@
+//go:build !js
+
+package analyzer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Webhook events
+const (
+	WebhookBatchCompleted = "batch_completed" // A /batch request finished
+	WebhookGateFailed     = "gate_failed"     // An analysis scored below the quality gate
+)
+
+// Webhook payload formats
+const (
+	WebhookFormatJSON  = "json"  // The WebhookEvent as JSON
+	WebhookFormatSlack = "slack" // A Slack incoming-webhook message
+)
+
+// WebhookHook is one endpoint and the events it receives
+type WebhookHook struct {
+	URL    string   `json:"url"`
+	Format string   `json:"format"` // json (the default) or slack
+	Events []string `json:"events"` // Empty means every event
+}
+
+// QualityGate fails analyses whose overall score is below MinScore; 0
+// disables it
+type QualityGate struct {
+	MinScore float64 `json:"min_score"`
+}
+
+// WebhookConfig lists the hooks to notify and the gate that triggers
+// gate_failed
+type WebhookConfig struct {
+	Hooks []WebhookHook `json:"hooks"`
+	Gate  QualityGate   `json:"gate"`
+	// LinkTemplate builds deep links, replacing {id} with the analysis
+	// request ID, e.g. https://fulcrum.example.com/analyses/{id}
+	LinkTemplate string        `json:"link_template"`
+	Timeout      time.Duration `json:"timeout"` // Per delivery; defaults to 10s
+}
+
+// WebhookEvent is the body of a json webhook and the source of a Slack message
+type WebhookEvent struct {
+	Event      string       `json:"event"`
+	Time       time.Time    `json:"time"`
+	AnalysisID string       `json:"analysis_id,omitempty"` // Request ID of the analysis, for gate_failed
+	Link       string       `json:"link,omitempty"`
+	Score      float64      `json:"score"` // Overall score, or the batch average
+	Grade      string       `json:"grade"` // Letter grade of Score
+	MinScore   float64      `json:"min_score,omitempty"`
+	Items      int          `json:"items,omitempty"`  // Batch items
+	Failed     int          `json:"failed,omitempty"` // Batch items that errored
+	GateFailed int          `json:"gate_failed,omitempty"`
+	Tags       AnalysisTags `json:"tags"`
+	// Analyses lists each graded batch item with its deep link
+	Analyses []WebhookAnalysis `json:"analyses,omitempty"`
+}
+
+// WebhookAnalysis is one graded batch item
+type WebhookAnalysis struct {
+	Index      int     `json:"index"`
+	AnalysisID string  `json:"analysis_id"`
+	Link       string  `json:"link,omitempty"`
+	Score      float64 `json:"score"`
+	Grade      string  `json:"grade"`
+}
+
+// WebhookNotifier delivers webhook events in the background. A nil
+// notifier sends nothing, so handlers call it unconditionally.
+type WebhookNotifier struct {
+	config  WebhookConfig
+	client  *http.Client
+	pending sync.WaitGroup
+	// OnError, when set, receives failed deliveries
+	OnError func(hook WebhookHook, err error)
+}
+
+// NewWebhookNotifier validates the hooks and fills in defaults
+func NewWebhookNotifier(cfg WebhookConfig) (*WebhookNotifier, error) {
+	cfg.Hooks = append([]WebhookHook(nil), cfg.Hooks...)
+	for i, hook := range cfg.Hooks {
+		u, err := url.Parse(hook.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("hook %d: url %q must be an http or https URL", i, hook.URL)
+		}
+		if hook.Format == "" {
+			cfg.Hooks[i].Format = WebhookFormatJSON
+		} else if hook.Format != WebhookFormatJSON && hook.Format != WebhookFormatSlack {
+			return nil, fmt.Errorf("hook %d: unknown format %q (expected %q or %q)", i, hook.Format, WebhookFormatJSON, WebhookFormatSlack)
+		}
+		for _, event := range hook.Events {
+			if event != WebhookBatchCompleted && event != WebhookGateFailed {
+				return nil, fmt.Errorf("hook %d: unknown event %q (expected %q or %q)", i, event, WebhookBatchCompleted, WebhookGateFailed)
+			}
+		}
+	}
+	if cfg.Gate.MinScore < 0 || cfg.Gate.MinScore > 100 {
+		return nil, fmt.Errorf("gate min_score must be between 0 and 100")
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	return &WebhookNotifier{config: cfg, client: &http.Client{Timeout: cfg.Timeout}}, nil
+}
+
+// Wait blocks until every delivery started so far has finished
+func (n *WebhookNotifier) Wait() {
+	if n != nil {
+		n.pending.Wait()
+	}
+}
+
+// analysisOutcome is the part of a result the notifier reads
+type analysisOutcome struct {
+	Performance struct {
+		RequestID string `json:"request_id"`
+	} `json:"performance_metrics"`
+	PromptGrade struct {
+		OverallGrade OverallGrade `json:"overall_grade"`
+	} `json:"prompt_grade"`
+	Grades struct {
+		Score float64 `json:"score"`
+		Grade string  `json:"grade"`
+	} `json:"grades"`
+}
+
+// grade is the selected engine's score and grade, read from prompt_grade for
+// results that predate the grade envelope
+func (out analysisOutcome) grade() (float64, string) {
+	if out.Grades.Grade != "" {
+		return out.Grades.Score, out.Grades.Grade
+	}
+	return out.PromptGrade.OverallGrade.Score, out.PromptGrade.OverallGrade.Grade
+}
+
+// outcomeOf reads the request ID and grade from a CombinedResult or its
+// JSON, reporting false when the result carries no grade
+func outcomeOf(result interface{}) (analysisOutcome, bool) {
+	var out analysisOutcome
+	if r, ok := result.(*CombinedResult); ok {
+		out.Performance.RequestID = r.Performance.RequestID
+		out.PromptGrade.OverallGrade = r.PromptGrade.OverallGrade
+		out.Grades.Score, out.Grades.Grade = r.Grades.Score, r.Grades.Grade
+	} else {
+		raw, err := rawJSON(result)
+		if err != nil || json.Unmarshal(raw, &out) != nil {
+			return out, false
+		}
+	}
+	_, grade := out.grade()
+	return out, grade != ""
+}
+
+// gateFailed reports whether an analysis result fails the quality gate
+func (n *WebhookNotifier) gateFailed(result interface{}) (analysisOutcome, bool) {
+	if n.config.Gate.MinScore <= 0 {
+		return analysisOutcome{}, false
+	}
+	out, ok := outcomeOf(result)
+	score, _ := out.grade()
+	return out, ok && score < n.config.Gate.MinScore
+}
+
+// AnalysisCompleted checks one analysis against the quality gate and sends
+// gate_failed when it falls short
+func (n *WebhookNotifier) AnalysisCompleted(req AnalyzeRequest, result interface{}) {
+	if n == nil {
+		return
+	}
+	if out, failed := n.gateFailed(result); failed {
+		n.Send(n.gateEvent(req, out))
+	}
+}
+
+func (n *WebhookNotifier) gateEvent(req AnalyzeRequest, out analysisOutcome) WebhookEvent {
+	score, grade := out.grade()
+	return WebhookEvent{
+		Event:      WebhookGateFailed,
+		Time:       time.Now().UTC(),
+		AnalysisID: out.Performance.RequestID,
+		Link:       n.link(out.Performance.RequestID),
+		Score:      score,
+		Grade:      grade,
+		MinScore:   n.config.Gate.MinScore,
+		Tags:       req.Tags,
+	}
+}
+
+// BatchCompleted sends gate_failed for each item below the gate and then
+// batch_completed with the average score of the graded items
+func (n *WebhookNotifier) BatchCompleted(batch BatchRequest, resp BatchResponse) {
+	if n == nil {
+		return
+	}
+	event := WebhookEvent{Event: WebhookBatchCompleted, Time: time.Now().UTC(), Items: len(resp.Results), MinScore: n.config.Gate.MinScore}
+	total, graded := 0.0, 0
+	for i, item := range resp.Results {
+		if item.Error != "" {
+			event.Failed++
+			continue
+		}
+		out, ok := outcomeOf(item.Result)
+		if !ok {
+			continue
+		}
+		score, grade := out.grade()
+		event.Analyses = append(event.Analyses, WebhookAnalysis{
+			Index:      i,
+			AnalysisID: out.Performance.RequestID,
+			Link:       n.link(out.Performance.RequestID),
+			Score:      score,
+			Grade:      grade,
+		})
+		total += score
+		graded++
+		if _, failed := n.gateFailed(item.Result); failed {
+			event.GateFailed++
+			n.Send(n.gateEvent(batch.Items[i], out))
+		}
+	}
+	if graded > 0 {
+		event.Score = math.Round(total/float64(graded)*10) / 10
+		event.Grade = scoreToGrade(event.Score)
+	}
+	n.Send(event)
+}
+
+// link fills the deep link template with an analysis ID
+func (n *WebhookNotifier) link(id string) string {
+	if n.config.LinkTemplate == "" || id == "" {
+		return ""
+	}
+	return strings.ReplaceAll(n.config.LinkTemplate, "{id}", url.PathEscape(id))
+}
+
+// Send delivers event to every hook subscribed to it, in the background
+func (n *WebhookNotifier) Send(event WebhookEvent) {
+	if n == nil {
+		return
+	}
+	for _, hook := range n.config.Hooks {
+		if len(hook.Events) > 0 && !contains(hook.Events, event.Event) {
+			continue
+		}
+		n.pending.Add(1)
+		go func(hook WebhookHook) {
+			defer n.pending.Done()
+			if err := n.deliver(hook, event); err != nil && n.OnError != nil {
+				n.OnError(hook, err)
+			}
+		}(hook)
+	}
+}
+
+// deliver posts one event to one hook
+func (n *WebhookNotifier) deliver(hook WebhookHook, event WebhookEvent) error {
+	var payload interface{} = event
+	if hook.Format == WebhookFormatSlack {
+		payload = map[string]string{"text": slackText(event)}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), n.config.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook %s failed: %w", hook.URL, err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook %s returned %s", hook.URL, resp.Status)
+	}
+	return nil
+}
+
+// slackText renders an event as one line of Slack mrkdwn
+func slackText(e WebhookEvent) string {
+	var sb strings.Builder
+	switch e.Event {
+	case WebhookGateFailed:
+		fmt.Fprintf(&sb, ":warning: Prompt scored %.1f (%s), below the quality gate of %.1f", e.Score, e.Grade, e.MinScore)
+		if e.Tags.Project != "" {
+			fmt.Fprintf(&sb, " in *%s*", e.Tags.Project)
+		}
+	case WebhookBatchCompleted:
+		fmt.Fprintf(&sb, ":white_check_mark: Batch of %d finished", e.Items)
+		if e.Grade != "" {
+			fmt.Fprintf(&sb, ": average %.1f (%s)", e.Score, e.Grade)
+		}
+		if e.Failed > 0 {
+			fmt.Fprintf(&sb, ", %d failed", e.Failed)
+		}
+		if e.GateFailed > 0 {
+			fmt.Fprintf(&sb, ", %d below the quality gate", e.GateFailed)
+		}
+	}
+	if e.Link != "" {
+		fmt.Fprintf(&sb, " <%s|View analysis>", e.Link)
+	}
+	return sb.String()
+}
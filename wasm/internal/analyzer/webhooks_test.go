@@ -0,0 +1,76 @@
+//go:build !js
+
+package analyzer
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestWebhookNotifier(t *testing.T) {
+	var mu sync.Mutex
+	received := map[string][]string{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		received[r.URL.Path] = append(received[r.URL.Path], string(body))
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	n, err := NewWebhookNotifier(WebhookConfig{
+		Hooks: []WebhookHook{
+			{URL: srv.URL + "/ci"},
+			{URL: srv.URL + "/slack", Format: WebhookFormatSlack, Events: []string{WebhookBatchCompleted}},
+		},
+		Gate:         QualityGate{MinScore: 70},
+		LinkTemplate: "https://fulcrum.example.com/analyses/{id}",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	weak := &CombinedResult{}
+	weak.Performance.RequestID = "req_1"
+	weak.PromptGrade.OverallGrade = OverallGrade{Score: 55, Grade: "D"}
+	strong, _ := json.Marshal(map[string]interface{}{"prompt_grade": map[string]interface{}{"overall_grade": map[string]interface{}{"score": 85, "grade": "B"}}})
+	weakJSON, _ := json.Marshal(weak)
+
+	n.AnalysisCompleted(AnalyzeRequest{Tags: AnalysisTags{Project: "support-bot"}}, weak)
+	n.BatchCompleted(
+		BatchRequest{Items: make([]AnalyzeRequest, 3)},
+		BatchResponse{Results: []BatchItem{{Index: 0, Result: strong}, {Index: 1, Result: weakJSON}, {Index: 2, Error: "boom"}}},
+	)
+	n.Wait()
+
+	// Two gate failures and the batch summary go to /ci; Slack only hears about the batch
+	if len(received["/ci"]) != 3 || len(received["/slack"]) != 1 {
+		t.Fatalf("deliveries = %v", received)
+	}
+	var gate WebhookEvent
+	for _, body := range received["/ci"] {
+		var e WebhookEvent
+		json.Unmarshal([]byte(body), &e)
+		if e.Event == WebhookGateFailed && e.Tags.Project == "support-bot" {
+			gate = e
+		}
+	}
+	if gate.Link != "https://fulcrum.example.com/analyses/req_1" || gate.Grade != "D" || gate.MinScore != 70 {
+		t.Errorf("gate event = %+v", gate)
+	}
+	slack := received["/slack"][0]
+	if !strings.Contains(slack, "Batch of 3 finished: average 70.0") || !strings.Contains(slack, "1 failed") || !strings.Contains(slack, "1 below the quality gate") {
+		t.Errorf("slack message = %s", slack)
+	}
+
+	if _, err := NewWebhookNotifier(WebhookConfig{Hooks: []WebhookHook{{URL: "ftp://example.com"}}}); err == nil {
+		t.Error("expected an error for a non-HTTP hook URL")
+	}
+	var none *WebhookNotifier
+	none.AnalysisCompleted(AnalyzeRequest{}, weak) // A nil notifier is a no-op
+}
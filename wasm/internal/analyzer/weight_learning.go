@@ -0,0 +1,310 @@
+package analyzer
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Weight learning defaults
+const (
+	defaultWeightMinSamples   = 10  // Outcomes a prompt type needs before its weights are learned
+	defaultWeightRidge        = 1.0 // L2 penalty keeping the fit stable with correlated dimensions
+	defaultWeightPriorSamples = 10  // Pull toward the hand-tuned weights, in samples
+)
+
+// modernDimensionNames orders the modern grader's dimensions in weight vectors
+var modernDimensionNames = [6]string{"clarity", "specificity", "completeness", "actionability", "context_provision", "structure_quality"}
+
+// WeightSample is one observed outcome of a prompt, with the modern
+// dimension scores and prompt type it was graded with
+type WeightSample struct {
+	PromptType PromptType         `json:"prompt_type"`
+	Dimensions map[string]float64 `json:"dimensions"` // Modern dimension scores (0-100) by name
+	Outcome    float64            `json:"outcome"`
+}
+
+// WeightLearningOptions tunes LearnDimensionWeights; zero values use the defaults
+type WeightLearningOptions struct {
+	MinSamples   int     // Prompt types with fewer samples keep their hand-tuned weights
+	Ridge        float64 // L2 regularization of the regression
+	PriorSamples float64 // Weight of the hand-tuned table when blending, in samples
+}
+
+// WeightFit reports how well weights predict outcomes for one prompt type:
+// the correlation of the weighted overall score with the outcome, for the
+// learned and the hand-tuned weights
+type WeightFit struct {
+	PromptType         PromptType `json:"prompt_type"`
+	Samples            int        `json:"samples"`
+	Correlation        float64    `json:"correlation"`
+	DefaultCorrelation float64    `json:"default_correlation"`
+}
+
+// LearnedWeights are per-prompt-type dimension weights calibrated on outcome
+// data. Export them as JSON and load them back with LoadLearnedWeights.
+type LearnedWeights struct {
+	Metric  string                          `json:"metric,omitempty"` // Outcome metric they were learned from
+	Samples int                             `json:"samples"`
+	Weights map[PromptType]DimensionWeights `json:"weights"`
+	Fits    []WeightFit                     `json:"fits"`
+	Skipped []PromptType                    `json:"skipped,omitempty"` // Types with too few samples
+}
+
+// LearnDimensionWeights fits, per prompt type, a ridge regression of the
+// outcome on the modern dimension scores. Negative coefficients are dropped
+// (a dimension cannot count against a prompt), the rest are normalized to sum
+// to 1 and blended with the hand-tuned weights of NewModernPromptGrader, so
+// a handful of outcomes nudges the table rather than replacing it.
+func LearnDimensionWeights(samples []WeightSample, opts WeightLearningOptions) (LearnedWeights, error) {
+	if opts.MinSamples <= 0 {
+		opts.MinSamples = defaultWeightMinSamples
+	}
+	if opts.Ridge <= 0 {
+		opts.Ridge = defaultWeightRidge
+	}
+	if opts.PriorSamples < 0 {
+		return LearnedWeights{}, errors.New("prior samples must not be negative")
+	} else if opts.PriorSamples == 0 {
+		opts.PriorSamples = defaultWeightPriorSamples
+	}
+	if len(samples) == 0 {
+		return LearnedWeights{}, errors.New("no outcome samples to learn from")
+	}
+
+	byType := map[PromptType][]WeightSample{}
+	for _, s := range samples {
+		if math.IsNaN(s.Outcome) || math.IsInf(s.Outcome, 0) {
+			return LearnedWeights{}, fmt.Errorf("outcome for %s must be a finite number", s.PromptType)
+		}
+		byType[s.PromptType] = append(byType[s.PromptType], s)
+	}
+	types := make([]PromptType, 0, len(byType))
+	for pt := range byType {
+		types = append(types, pt)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+
+	defaults := NewModernPromptGrader().dimensionWeights
+	learned := LearnedWeights{Samples: len(samples), Weights: map[PromptType]DimensionWeights{}, Fits: []WeightFit{}}
+	for _, pt := range types {
+		group := byType[pt]
+		if len(group) < opts.MinSamples {
+			learned.Skipped = append(learned.Skipped, pt)
+			continue
+		}
+		prior, ok := defaults[pt]
+		if !ok {
+			prior = defaults[General]
+		}
+
+		xs := make([][6]float64, len(group))
+		ys := make([]float64, len(group))
+		for i, s := range group {
+			for j, name := range modernDimensionNames {
+				xs[i][j] = s.Dimensions[name] / 100
+			}
+			ys[i] = s.Outcome
+		}
+		priorVec := prior.vector()
+		priorTotal := 0.0
+		for _, v := range priorVec {
+			priorTotal += v
+		}
+		for j := range priorVec {
+			priorVec[j] /= priorTotal
+		}
+		fit := fitNonNegativeRidge(xs, ys, opts.Ridge)
+		if fit == ([6]float64{}) {
+			fit = priorVec // No dimension predicts the outcome; keep the table
+		}
+
+		n := float64(len(group))
+		var blended [6]float64
+		for j := range blended {
+			blended[j] = math.Round((n*fit[j]+opts.PriorSamples*priorVec[j])/(n+opts.PriorSamples)*1000) / 1000
+		}
+		weights := weightsFromVector(blended)
+		learned.Weights[pt] = weights
+		learned.Fits = append(learned.Fits, WeightFit{
+			PromptType:         pt,
+			Samples:            len(group),
+			Correlation:        weightedCorrelation(xs, ys, blended),
+			DefaultCorrelation: weightedCorrelation(xs, ys, priorVec),
+		})
+	}
+	if len(learned.Weights) == 0 {
+		return learned, fmt.Errorf("no prompt type has the %d samples needed to learn weights", opts.MinSamples)
+	}
+	return learned, nil
+}
+
+// LoadLearnedWeights parses exported weights, rejecting unknown fields and
+// weights a rubric would reject
+func LoadLearnedWeights(data []byte) (LearnedWeights, error) {
+	var lw LearnedWeights
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&lw); err != nil {
+		return LearnedWeights{}, fmt.Errorf("invalid learned weights: %w", err)
+	}
+	if len(lw.Weights) == 0 {
+		return LearnedWeights{}, errors.New("invalid learned weights: no weights")
+	}
+	if err := lw.Rubric("learned").Validate(); err != nil {
+		return LearnedWeights{}, err
+	}
+	return lw, nil
+}
+
+// Rubric returns a rubric that applies the learned weights; build a grader
+// from it with NewModernPromptGraderFromConfig
+func (lw LearnedWeights) Rubric(name string) RubricConfig {
+	weights := make(map[PromptType]DimensionWeights, len(lw.Weights))
+	for pt, w := range lw.Weights {
+		weights[pt] = w
+	}
+	return RubricConfig{Name: name, DimensionWeights: weights}
+}
+
+// vector lists the weights in modernDimensionNames order
+func (w DimensionWeights) vector() [6]float64 {
+	return [6]float64{w.Clarity, w.Specificity, w.Completeness, w.Actionability, w.ContextProvision, w.StructureQuality}
+}
+
+// weightsFromVector is the inverse of DimensionWeights.vector
+func weightsFromVector(v [6]float64) DimensionWeights {
+	return DimensionWeights{
+		Clarity:          v[0],
+		Specificity:      v[1],
+		Completeness:     v[2],
+		Actionability:    v[3],
+		ContextProvision: v[4],
+		StructureQuality: v[5],
+	}
+}
+
+// fitNonNegativeRidge regresses ys on xs with an intercept and an L2 penalty,
+// refitting without any dimension whose coefficient comes out negative. The
+// coefficients are normalized to sum to 1; all zeros means no dimension
+// predicts the outcome.
+func fitNonNegativeRidge(xs [][6]float64, ys []float64, ridge float64) [6]float64 {
+	active := [6]bool{true, true, true, true, true, true}
+	var coef [6]float64
+	for {
+		var cols []int
+		for j, on := range active {
+			if on {
+				cols = append(cols, j)
+			}
+		}
+		coef = [6]float64{}
+		if len(cols) == 0 {
+			return coef
+		}
+
+		// Center so the intercept drops out of the normal equations
+		n := float64(len(xs))
+		var meanX [6]float64
+		meanY := 0.0
+		for i := range xs {
+			for _, j := range cols {
+				meanX[j] += xs[i][j] / n
+			}
+			meanY += ys[i] / n
+		}
+		a := make([][]float64, len(cols))
+		b := make([]float64, len(cols))
+		for r, jr := range cols {
+			a[r] = make([]float64, len(cols))
+			for c, jc := range cols {
+				for i := range xs {
+					a[r][c] += (xs[i][jr] - meanX[jr]) * (xs[i][jc] - meanX[jc])
+				}
+			}
+			a[r][r] += ridge
+			for i := range xs {
+				b[r] += (xs[i][jr] - meanX[jr]) * (ys[i] - meanY)
+			}
+		}
+		solution, ok := solveLinear(a, b)
+		if !ok {
+			return [6]float64{}
+		}
+
+		negative := false
+		for r, j := range cols {
+			if solution[r] < 0 {
+				active[j] = false
+				negative = true
+			}
+			coef[j] = solution[r]
+		}
+		if !negative {
+			break
+		}
+	}
+
+	total := 0.0
+	for _, c := range coef {
+		total += c
+	}
+	if total <= 0 {
+		return [6]float64{}
+	}
+	for j := range coef {
+		coef[j] /= total
+	}
+	return coef
+}
+
+// solveLinear solves a·x = b by Gaussian elimination with partial pivoting,
+// reporting false for a singular system. a and b are overwritten.
+func solveLinear(a [][]float64, b []float64) ([]float64, bool) {
+	n := len(b)
+	for col := 0; col < n; col++ {
+		pivot := col
+		for r := col + 1; r < n; r++ {
+			if math.Abs(a[r][col]) > math.Abs(a[pivot][col]) {
+				pivot = r
+			}
+		}
+		if math.Abs(a[pivot][col]) < 1e-12 {
+			return nil, false
+		}
+		a[col], a[pivot] = a[pivot], a[col]
+		b[col], b[pivot] = b[pivot], b[col]
+		for r := col + 1; r < n; r++ {
+			f := a[r][col] / a[col][col]
+			for c := col; c < n; c++ {
+				a[r][c] -= f * a[col][c]
+			}
+			b[r] -= f * b[col]
+		}
+	}
+	x := make([]float64, n)
+	for r := n - 1; r >= 0; r-- {
+		sum := b[r]
+		for c := r + 1; c < n; c++ {
+			sum -= a[r][c] * x[c]
+		}
+		x[r] = sum / a[r][r]
+	}
+	return x, true
+}
+
+// weightedCorrelation is the correlation of the weighted scores with ys,
+// 0 when either does not vary
+func weightedCorrelation(xs [][6]float64, ys []float64, weights [6]float64) float64 {
+	scores := make([]float64, len(xs))
+	for i, x := range xs {
+		for j := range x {
+			scores[i] += x[j] * weights[j]
+		}
+	}
+	r, _ := pearson(scores, ys)
+	return math.Round(r*1000) / 1000
+}
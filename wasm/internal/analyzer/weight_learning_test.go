@@ -0,0 +1,84 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+// TestLearnDimensionWeights checks a dimension that drives outcomes gains weight
+func TestLearnDimensionWeights(t *testing.T) {
+	var samples []WeightSample
+	for i := 0; i < 40; i++ {
+		dims := map[string]float64{}
+		for j, name := range modernDimensionNames {
+			dims[name] = float64((i*(j+3)*7)%61 + 30) // Spread, weakly related scores
+		}
+		samples = append(samples, WeightSample{PromptType: CodeGeneration, Dimensions: dims, Outcome: dims["context_provision"] / 100})
+	}
+	samples = append(samples, WeightSample{PromptType: Writing, Dimensions: samples[0].Dimensions, Outcome: 1})
+
+	learned, err := LearnDimensionWeights(samples, WeightLearningOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defaults := NewModernPromptGrader().dimensionWeights[CodeGeneration]
+	w, ok := learned.Weights[CodeGeneration]
+	if !ok || w.ContextProvision <= defaults.ContextProvision*2 {
+		t.Fatalf("context provision weight = %.3f (default %.3f)", w.ContextProvision, defaults.ContextProvision)
+	}
+	total := 0.0
+	for _, v := range w.vector() {
+		if v < 0 {
+			t.Errorf("negative weight in %+v", w)
+		}
+		total += v
+	}
+	if math.Abs(total-1) > 0.01 {
+		t.Errorf("weights sum to %.3f", total)
+	}
+	if len(learned.Fits) != 1 || learned.Fits[0].Correlation <= learned.Fits[0].DefaultCorrelation {
+		t.Errorf("fits = %+v", learned.Fits)
+	}
+	if len(learned.Skipped) != 1 || learned.Skipped[0] != Writing {
+		t.Errorf("skipped = %v", learned.Skipped)
+	}
+
+	data, err := json.Marshal(learned)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadLearnedWeights(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	grader, err := NewModernPromptGraderFromConfig(loaded.Rubric("learned"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := grader.dimensionWeights[CodeGeneration]; got != w {
+		t.Errorf("imported weights = %+v, want %+v", got, w)
+	}
+	if got := grader.dimensionWeights[Writing]; got != NewModernPromptGrader().dimensionWeights[Writing] {
+		t.Errorf("skipped type changed: %+v", got)
+	}
+
+	for _, bad := range []string{`{}`, `{"weights": {"general": {"clarity": -1}}}`, `{"weights": {}, "bias": 1}`} {
+		if _, err := LoadLearnedWeights([]byte(bad)); err == nil {
+			t.Errorf("expected error for %s", bad)
+		}
+	}
+	if _, err := LearnDimensionWeights(samples[:5], WeightLearningOptions{}); err == nil {
+		t.Error("learned weights from 5 samples")
+	}
+}
+
+func TestSolveLinear(t *testing.T) {
+	x, ok := solveLinear([][]float64{{0, 2}, {1, 1}}, []float64{4, 3})
+	if !ok || math.Abs(x[0]-1) > 1e-9 || math.Abs(x[1]-2) > 1e-9 {
+		t.Errorf("solution = %v %v", x, ok)
+	}
+	if _, ok := solveLinear([][]float64{{1, 2}, {2, 4}}, []float64{1, 2}); ok {
+		t.Error("singular system solved")
+	}
+}
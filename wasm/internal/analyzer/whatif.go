@@ -0,0 +1,319 @@
+package analyzer
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// What-if change kinds
+const (
+	ChangeSplitLongSentences = "split_long_sentences" // Split sentences over MaxWords at a comma, semicolon or "and"
+	ChangeRemoveCluster      = "remove_cluster"       // Drop every sentence of idea cluster Cluster
+	ChangeRemoveSentences    = "remove_sentences"     // Drop the sentences at the 0-based Sentences indices
+	ChangeReplaceText        = "replace_text"         // Replace every occurrence of Find with Replace
+	ChangeAppendText         = "append_text"          // Add Text as a new paragraph, e.g. an output format
+)
+
+// WhatIfChanges lists the accepted WhatIfChange kinds
+var WhatIfChanges = []string{ChangeSplitLongSentences, ChangeRemoveCluster, ChangeRemoveSentences, ChangeReplaceText, ChangeAppendText}
+
+// defaultWhatIfMaxWords is the sentence length split_long_sentences splits above
+const defaultWhatIfMaxWords = 30
+
+// WhatIfChange is one proposed edit. Only the fields of its kind are read.
+type WhatIfChange struct {
+	Kind      string `json:"kind"`
+	MaxWords  int    `json:"max_words,omitempty"` // split_long_sentences; 0 means 30
+	Cluster   int    `json:"cluster,omitempty"`   // remove_cluster: IdeaCluster.ID
+	Sentences []int  `json:"sentences,omitempty"` // remove_sentences
+	Find      string `json:"find,omitempty"`      // replace_text
+	Replace   string `json:"replace,omitempty"`   // replace_text
+	Text      string `json:"text,omitempty"`      // append_text
+}
+
+// WhatIfRequest is the body of POST /what-if. Changes apply in order, each to
+// the text the previous one left, so cluster IDs and sentence indices refer
+// to the original text only while earlier changes haven't moved them; list
+// removals first.
+type WhatIfRequest struct {
+	Text    string          `json:"text"`
+	Changes []WhatIfChange  `json:"changes"`
+	Options AnalysisOptions `json:"options,omitempty"` // Grader, rules and classifier used for both grades
+}
+
+// AppliedChange reports what a change did to the text
+type AppliedChange struct {
+	Kind        string `json:"kind"`
+	Description string `json:"description"`
+	Edits       int    `json:"edits"` // Sentences split or removed, or occurrences replaced; 0 means the change had no effect
+}
+
+// DimensionDelta is one grade dimension the changes move
+type DimensionDelta struct {
+	Engine    string  `json:"engine"`
+	Dimension string  `json:"dimension"`
+	Before    float64 `json:"before"`
+	After     float64 `json:"after"`
+	Delta     float64 `json:"delta"`
+}
+
+// WhatIfResult predicts the grade of the changed text alongside the current one
+type WhatIfResult struct {
+	Text       string           `json:"text"` // The text with every change applied
+	Changes    []AppliedChange  `json:"changes"`
+	Before     GradeEnvelope    `json:"before"`
+	After      GradeEnvelope    `json:"after"`
+	ScoreDelta float64          `json:"score_delta"`
+	Dimensions []DimensionDelta `json:"dimensions"` // Only dimensions that moved, largest move first
+}
+
+// SimulateChanges applies the proposed changes to the text and grades both
+// versions with the engines opts selects, so a tool can show what an edit
+// would earn before the user makes it
+func SimulateChanges(req WhatIfRequest) (WhatIfResult, error) {
+	if strings.TrimSpace(req.Text) == "" {
+		return WhatIfResult{}, errors.New("text is required")
+	}
+	if len(req.Changes) == 0 {
+		return WhatIfResult{}, errors.New("at least one change is required")
+	}
+	if err := req.Options.Validate(); err != nil {
+		return WhatIfResult{}, err
+	}
+
+	text := req.Text
+	applied := make([]AppliedChange, 0, len(req.Changes))
+	for i, change := range req.Changes {
+		next, a, err := applyWhatIfChange(text, change)
+		if err != nil {
+			return WhatIfResult{}, fmt.Errorf("change %d: %w", i+1, err)
+		}
+		text = next
+		applied = append(applied, a)
+	}
+	if strings.TrimSpace(text) == "" {
+		return WhatIfResult{}, errors.New("the changes leave no text to grade")
+	}
+
+	grade := func(text string) GradeEnvelope {
+		in := AnalyzeForGrading(text, NewPromptClassifierWithModel(req.Options.classifierModel()))
+		envelope, _ := GradeAll(GradersFor(req.Options), in)
+		return envelope
+	}
+	result := WhatIfResult{
+		Text:       text,
+		Changes:    applied,
+		Before:     grade(req.Text),
+		After:      grade(text),
+		Dimensions: []DimensionDelta{},
+	}
+	result.ScoreDelta = math.Round((result.After.Score-result.Before.Score)*10) / 10
+	for i, before := range result.Before.Results {
+		after := result.After.Results[i]
+		for name, score := range before.Dimensions {
+			delta := math.Round((after.Dimensions[name]-score)*10) / 10
+			if delta == 0 {
+				continue
+			}
+			result.Dimensions = append(result.Dimensions, DimensionDelta{
+				Engine: before.Engine, Dimension: name, Before: score, After: after.Dimensions[name], Delta: delta,
+			})
+		}
+	}
+	sort.SliceStable(result.Dimensions, func(i, j int) bool {
+		a, b := result.Dimensions[i], result.Dimensions[j]
+		if math.Abs(a.Delta) != math.Abs(b.Delta) {
+			return math.Abs(a.Delta) > math.Abs(b.Delta)
+		}
+		if a.Engine != b.Engine {
+			return a.Engine < b.Engine
+		}
+		return a.Dimension < b.Dimension
+	})
+	return result, nil
+}
+
+// applyWhatIfChange returns text with change applied
+func applyWhatIfChange(text string, change WhatIfChange) (string, AppliedChange, error) {
+	applied := AppliedChange{Kind: change.Kind}
+	switch change.Kind {
+	case ChangeSplitLongSentences:
+		if change.MaxWords < 0 {
+			return "", applied, errors.New("max_words must not be negative")
+		}
+		maxWords := change.MaxWords
+		if maxWords == 0 {
+			maxWords = defaultWhatIfMaxWords
+		}
+		text, applied.Edits = splitLongSentences(text, maxWords)
+		applied.Description = fmt.Sprintf("Split %d sentences over %d words", applied.Edits, maxWords)
+
+	case ChangeRemoveCluster:
+		var cluster *IdeaCluster
+		clusters := AnalyzeIdeas(text).SemanticClusters.Value
+		for i := range clusters {
+			if clusters[i].ID == change.Cluster {
+				cluster = &clusters[i]
+			}
+		}
+		if cluster == nil {
+			return "", applied, fmt.Errorf("no idea cluster %d", change.Cluster)
+		}
+		starts := map[int]bool{}
+		for _, span := range cluster.SentenceSpans {
+			starts[span.Start] = true
+		}
+		var indices []int
+		for i, span := range locateSentences(text) {
+			if starts[span.Start] {
+				indices = append(indices, i)
+			}
+		}
+		text, applied.Edits = removeSentences(text, indices)
+		applied.Description = fmt.Sprintf("Removed cluster %d (%s): %d sentences", cluster.ID, cluster.MainTopic, applied.Edits)
+
+	case ChangeRemoveSentences:
+		if len(change.Sentences) == 0 {
+			return "", applied, errors.New("sentences is required")
+		}
+		count := len(locateSentences(text))
+		for _, i := range change.Sentences {
+			if i < 0 || i >= count {
+				return "", applied, fmt.Errorf("sentence %d out of range (text has %d)", i, count)
+			}
+		}
+		text, applied.Edits = removeSentences(text, change.Sentences)
+		applied.Description = fmt.Sprintf("Removed %d sentences", applied.Edits)
+
+	case ChangeReplaceText:
+		if change.Find == "" {
+			return "", applied, errors.New("find is required")
+		}
+		applied.Edits = strings.Count(text, change.Find)
+		text = strings.ReplaceAll(text, change.Find, change.Replace)
+		applied.Description = fmt.Sprintf("Replaced %d occurrences of %q", applied.Edits, change.Find)
+
+	case ChangeAppendText:
+		if strings.TrimSpace(change.Text) == "" {
+			return "", applied, errors.New("text is required")
+		}
+		text = strings.TrimRight(text, " \t\n") + "\n\n" + strings.TrimSpace(change.Text)
+		applied.Edits = 1
+		applied.Description = "Appended " + fmt.Sprintf("%d words", len(extractWords(change.Text)))
+
+	default:
+		return "", applied, fmt.Errorf("unknown change kind %q (expected one of %v)", change.Kind, WhatIfChanges)
+	}
+	return text, applied, nil
+}
+
+// removeSentences deletes the sentences at indices along with the terminator
+// and spacing that follow each, reporting how many it removed
+func removeSentences(text string, indices []int) (string, int) {
+	spans := locateSentences(text)
+	remove := map[int]bool{}
+	for _, i := range indices {
+		if i >= 0 && i < len(spans) {
+			remove[i] = true
+		}
+	}
+	if len(remove) == 0 {
+		return text, 0
+	}
+
+	var b strings.Builder
+	cursor := 0
+	for i, span := range spans {
+		if !remove[i] {
+			continue
+		}
+		end := len(text)
+		if i+1 < len(spans) {
+			end = spans[i+1].Start
+		}
+		b.WriteString(text[cursor:span.Start])
+		cursor = end
+	}
+	b.WriteString(text[cursor:])
+	return strings.TrimSpace(b.String()), len(remove)
+}
+
+// sentenceSplitPattern matches the places a long sentence can be split: after
+// a comma or semicolon, dropping a following "and", or at a bare "and"
+var sentenceSplitPattern = regexp.MustCompile(`(?i)[,;]\s+(?:and\s+)?|\s+and\s+`)
+
+// minSplitWords keeps splits from leaving fragments shorter than this
+const minSplitWords = 4
+
+// splitLongSentences splits every sentence over maxWords words, reporting how
+// many sentences it split
+func splitLongSentences(text string, maxWords int) (string, int) {
+	var b strings.Builder
+	cursor, split := 0, 0
+	for _, span := range locateSentences(text) {
+		parts := splitSentence(span.Text, maxWords)
+		if len(parts) < 2 {
+			continue
+		}
+		b.WriteString(text[cursor:span.Start])
+		b.WriteString(strings.Join(parts, ". "))
+		cursor = span.End
+		split++
+	}
+	b.WriteString(text[cursor:])
+	return b.String(), split
+}
+
+// splitSentence breaks sentence at the split point nearest its middle,
+// recursing until each part has at most maxWords words or can't be split
+func splitSentence(sentence string, maxWords int) []string {
+	words := segmentWords(sentence)
+	if len(words) <= maxWords {
+		return []string{sentence}
+	}
+
+	best, bestDistance := []int(nil), len(sentence)
+	middle := words[len(words)/2].Start
+	for _, m := range sentenceSplitPattern.FindAllStringIndex(sentence, -1) {
+		before, after := 0, 0
+		for _, w := range words {
+			if w.End <= m[0] {
+				before++
+			} else if w.Start >= m[1] {
+				after++
+			}
+		}
+		if before < minSplitWords || after < minSplitWords {
+			continue
+		}
+		d := m[0] - middle
+		if d < 0 {
+			d = -d
+		}
+		if d < bestDistance {
+			best, bestDistance = m, d
+		}
+	}
+	if best == nil {
+		return []string{sentence}
+	}
+
+	left := strings.TrimRight(sentence[:best[0]], " \t\n,;")
+	right := capitalizeFirst(sentence[best[1]:])
+	return append(splitSentence(left, maxWords), splitSentence(right, maxWords)...)
+}
+
+// capitalizeFirst upper-cases the first letter of s
+func capitalizeFirst(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	if r == utf8.RuneError {
+		return s
+	}
+	return string(unicode.ToUpper(r)) + s[size:]
+}
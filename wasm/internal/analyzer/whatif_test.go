@@ -0,0 +1,97 @@
+package analyzer
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+const whatIfTestText = "You are a support assistant for our billing platform. Review the customer's last three invoices, compare them against the pricing plan they signed up for, flag any charges that do not match the plan, and write a short explanation of each mismatch that a non-technical customer could follow without help from the support team. Also tell a joke about cats."
+
+func TestSimulateChanges(t *testing.T) {
+	got, err := SimulateChanges(WhatIfRequest{
+		Text:    whatIfTestText,
+		Changes: []WhatIfChange{{Kind: ChangeRemoveSentences, Sentences: []int{2}}, {Kind: ChangeSplitLongSentences}},
+		Options: AnalysisOptions{Grader: GraderBoth},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(got.Text, "joke") || !strings.Contains(got.Text, "the plan. Write a short explanation") {
+		t.Errorf("changed text = %q", got.Text)
+	}
+	if len(got.Changes) != 2 || got.Changes[0].Edits != 1 || got.Changes[1].Edits != 1 {
+		t.Errorf("applied = %+v", got.Changes)
+	}
+	if len(got.Before.Results) != 2 || len(got.After.Results) != 2 {
+		t.Fatalf("expected both engines before and after, got %+v / %+v", got.Before, got.After)
+	}
+	want, _ := GradeAll(GradersFor(AnalysisOptions{Grader: GraderBoth}), AnalyzeForGrading(got.Text, NewPromptClassifier()))
+	if got.After.Score != want.Score || got.After.Grade != want.Grade {
+		t.Errorf("predicted %.2f %s, grading the changed text gives %.2f %s", got.After.Score, got.After.Grade, want.Score, want.Grade)
+	}
+	if len(got.Dimensions) == 0 {
+		t.Fatal("expected dimensions to move")
+	}
+	for i, d := range got.Dimensions {
+		if d.Delta == 0 || (i > 0 && math.Abs(d.Delta) > math.Abs(got.Dimensions[i-1].Delta)) {
+			t.Errorf("dimension %d = %+v, not ordered by move", i, d)
+		}
+	}
+}
+
+func TestSimulateChangesRemoveCluster(t *testing.T) {
+	clusters := AnalyzeIdeas(whatIfTestText).SemanticClusters.Value
+	if len(clusters) == 0 {
+		t.Fatal("expected idea clusters")
+	}
+	target := clusters[len(clusters)-1]
+	got, err := SimulateChanges(WhatIfRequest{Text: whatIfTestText, Changes: []WhatIfChange{{Kind: ChangeRemoveCluster, Cluster: target.ID}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Changes[0].Edits != len(target.Sentences) {
+		t.Errorf("removed %d sentences, cluster has %d", got.Changes[0].Edits, len(target.Sentences))
+	}
+	for _, sentence := range target.Sentences {
+		if strings.Contains(got.Text, sentence) {
+			t.Errorf("%q survived removing its cluster", sentence)
+		}
+	}
+}
+
+func TestSimulateChangesErrors(t *testing.T) {
+	for name, req := range map[string]WhatIfRequest{
+		"no text":        {Changes: []WhatIfChange{{Kind: ChangeAppendText, Text: "Answer in JSON."}}},
+		"no changes":     {Text: whatIfTestText},
+		"unknown kind":   {Text: whatIfTestText, Changes: []WhatIfChange{{Kind: "rewrite"}}},
+		"unknown grader": {Text: whatIfTestText, Changes: []WhatIfChange{{Kind: ChangeSplitLongSentences}}, Options: AnalysisOptions{Grader: "gpt"}},
+		"no cluster":     {Text: whatIfTestText, Changes: []WhatIfChange{{Kind: ChangeRemoveCluster, Cluster: 99}}},
+		"bad sentence":   {Text: whatIfTestText, Changes: []WhatIfChange{{Kind: ChangeRemoveSentences, Sentences: []int{3}}}},
+		"empty find":     {Text: whatIfTestText, Changes: []WhatIfChange{{Kind: ChangeReplaceText}}},
+		"nothing left":   {Text: whatIfTestText, Changes: []WhatIfChange{{Kind: ChangeRemoveSentences, Sentences: []int{0, 1, 2}}}},
+	} {
+		if _, err := SimulateChanges(req); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestSplitSentence(t *testing.T) {
+	sentence := "Read the logs from last night, find the requests that timed out, group them by endpoint and write a summary for the on-call engineer"
+	parts := splitSentence(sentence, 10)
+	if len(parts) < 2 {
+		t.Fatalf("expected a split, got %q", parts)
+	}
+	for _, p := range parts {
+		if n := len(segmentWords(p)); n < minSplitWords {
+			t.Errorf("fragment %q has %d words", p, n)
+		}
+		if p != capitalizeFirst(p) {
+			t.Errorf("part %q is not capitalized", p)
+		}
+	}
+	if got := splitSentence("Fix the bug in the parser", 3); len(got) != 1 {
+		t.Errorf("sentence without split points was split: %q", got)
+	}
+}
@@ -0,0 +1,167 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// DefaultWordCloudTerms is how many terms a word cloud lists when no limit is given
+const DefaultWordCloudTerms = 50
+
+// MaxWordCloudTerms caps the top-N a caller can ask for
+const MaxWordCloudTerms = 500
+
+// wordCloudClusterTerms is how many terms each cluster lists
+const wordCloudClusterTerms = 10
+
+// WordCloudTerm is one weighted term, shaped for word-cloud components that
+// take {text, value} pairs
+type WordCloudTerm struct {
+	Text   string  `json:"text"`   // Most frequent surface form of the lemma
+	Lemma  string  `json:"lemma"`  // Terms are counted by lemma
+	Value  float64 `json:"value"`  // TF-IDF weight
+	Weight float64 `json:"weight"` // Value scaled so the heaviest term is 1
+	Count  int     `json:"count"`
+}
+
+// WordCloudCluster lists the heaviest terms of one idea cluster
+type WordCloudCluster struct {
+	ClusterID int             `json:"cluster_id"`
+	Label     string          `json:"label"`
+	Terms     []WordCloudTerm `json:"terms"`
+}
+
+// WordCloud is the top-N weighted terms of a prompt, with per-cluster lists
+type WordCloud struct {
+	Terms    []WordCloudTerm    `json:"terms"`
+	Clusters []WordCloudCluster `json:"clusters"`
+}
+
+// BuildWordCloud runs the tokens and ideas stages on text and returns its
+// word cloud. topN of 0 means DefaultWordCloudTerms.
+func BuildWordCloud(ctx context.Context, text string, opts AnalysisOptions, topN int) (WordCloud, error) {
+	if topN < 0 || topN > MaxWordCloudTerms {
+		return WordCloud{}, fmt.Errorf("word cloud size must be between 0 and %d, got %d", MaxWordCloudTerms, topN)
+	}
+	opts.Stages = []string{StageTokens, StageIdeas}
+	opts.Fields = nil
+	result, err := Analyze(ctx, text, opts, AnalysisRun{})
+	if err != nil {
+		return WordCloud{}, err
+	}
+	return WordCloudFromResult(text, result, topN), nil
+}
+
+// WordCloudFromResult weights the result's non-stopword words by TF-IDF,
+// treating each sentence as a document, so a term repeated in a few
+// sentences outweighs one mentioned as often in every sentence. text must be
+// the analyzed text; cluster lists come from the ideas stage when it ran.
+func WordCloudFromResult(text string, result *CombinedResult, topN int) WordCloud {
+	if topN <= 0 {
+		topN = DefaultWordCloudTerms
+	}
+	sentences := locateSentences(text)
+	docFreq := map[string]int{}
+	counts := map[string]int{}
+	forms := map[string]map[string]int{}
+	var seen map[string]bool
+	sentence := -1
+	for _, t := range result.Tokens.Tokens {
+		for sentence+1 < len(sentences) && t.Position >= sentences[sentence+1].Start {
+			sentence++
+			seen = map[string]bool{}
+		}
+		lemma, ok := wordCloudLemma(t)
+		if !ok {
+			continue
+		}
+		counts[lemma]++
+		if forms[lemma] == nil {
+			forms[lemma] = map[string]int{}
+		}
+		forms[lemma][strings.ToLower(t.Text)]++
+		if seen != nil && !seen[lemma] {
+			seen[lemma] = true
+			docFreq[lemma]++
+		}
+	}
+
+	// Smoothed IDF, as scikit-learn computes it, so a term in every sentence
+	// still weighs 1 per occurrence
+	idf := func(lemma string) float64 {
+		n := float64(len(sentences))
+		return math.Log((1+n)/(1+float64(docFreq[lemma]))) + 1
+	}
+	weigh := func(termCounts map[string]int, limit int) []WordCloudTerm {
+		terms := make([]WordCloudTerm, 0, len(termCounts))
+		for lemma, count := range termCounts {
+			terms = append(terms, WordCloudTerm{
+				Text:  commonestForm(forms[lemma]),
+				Lemma: lemma,
+				Value: math.Round(float64(count)*idf(lemma)*1000) / 1000,
+				Count: count,
+			})
+		}
+		sort.Slice(terms, func(i, j int) bool {
+			if terms[i].Value != terms[j].Value {
+				return terms[i].Value > terms[j].Value
+			}
+			return terms[i].Lemma < terms[j].Lemma
+		})
+		if len(terms) > limit {
+			terms = terms[:limit]
+		}
+		for i := range terms {
+			terms[i].Weight = math.Round(terms[i].Value/terms[0].Value*1000) / 1000
+		}
+		return terms
+	}
+
+	cloud := WordCloud{Terms: weigh(counts, topN), Clusters: []WordCloudCluster{}}
+	for _, cluster := range result.Ideas.SemanticClusters.Value {
+		clusterCounts := map[string]int{}
+		for _, span := range cluster.SentenceSpans {
+			// Tokens are in text order, so the sentence's first is found by search
+			tokens := result.Tokens.Tokens
+			i := sort.Search(len(tokens), func(i int) bool { return tokens[i].Position >= span.Start })
+			for ; i < len(tokens) && tokens[i].Position < span.End; i++ {
+				if lemma, ok := wordCloudLemma(tokens[i]); ok {
+					clusterCounts[lemma]++
+				}
+			}
+		}
+		cloud.Clusters = append(cloud.Clusters, WordCloudCluster{
+			ClusterID: cluster.ID,
+			Label:     cluster.MainTopic,
+			Terms:     weigh(clusterCounts, wordCloudClusterTerms),
+		})
+	}
+	return cloud
+}
+
+// wordCloudLemma is the lemma a token counts under, or false for tokens a
+// word cloud leaves out: stopwords, non-words and words under three letters
+func wordCloudLemma(t Token) (string, bool) {
+	if t.Type != Word || t.IsStopWord || len(t.Text) < 3 {
+		return "", false
+	}
+	if t.Lemma != "" {
+		return t.Lemma, true
+	}
+	return strings.ToLower(t.Text), true
+}
+
+// commonestForm picks the most frequent surface form, breaking ties by the
+// shortest and then alphabetically
+func commonestForm(forms map[string]int) string {
+	best, bestCount := "", 0
+	for form, count := range forms {
+		if count > bestCount || count == bestCount && (len(form) < len(best) || len(form) == len(best) && form < best) {
+			best, bestCount = form, count
+		}
+	}
+	return best
+}
@@ -0,0 +1,40 @@
+package analyzer
+
+import (
+	"context"
+	"testing"
+)
+
+func TestWordCloudWeights(t *testing.T) {
+	text := "Deploy the services today. Tests cover the service. The service needs tests and more tests."
+	cloud, err := BuildWordCloud(context.Background(), text, AnalysisOptions{Deterministic: true}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	terms := map[string]WordCloudTerm{}
+	for _, term := range cloud.Terms {
+		terms[term.Lemma] = term
+		if term.Lemma == "the" {
+			t.Errorf("stopword in word cloud: %+v", term)
+		}
+	}
+	// "services" and "service" share a lemma and show the commoner form
+	service := terms["service"]
+	if service.Count != 3 || service.Text != "service" {
+		t.Errorf("service = %+v", service)
+	}
+	// "test" occurs as often as "service" but in fewer sentences, so weighs more
+	if test := terms["test"]; test.Count != 3 || test.Value <= service.Value {
+		t.Errorf("test = %+v, service = %+v", test, service)
+	}
+	if cloud.Terms[0].Weight != 1 {
+		t.Errorf("heaviest term weight = %v, want 1", cloud.Terms[0].Weight)
+	}
+	if len(cloud.Clusters) == 0 || len(cloud.Clusters[0].Terms) == 0 {
+		t.Errorf("no per-cluster terms: %+v", cloud.Clusters)
+	}
+
+	if _, err := BuildWordCloud(context.Background(), text, AnalysisOptions{}, MaxWordCloudTerms+1); err == nil {
+		t.Error("expected an error above MaxWordCloudTerms")
+	}
+}
@@ -0,0 +1,148 @@
+package analyzer
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// wordBreakClass is a simplified Word_Break property from UAX #29
+type wordBreakClass int
+
+const (
+	wbOther wordBreakClass = iota
+	wbALetter
+	wbNumeric
+	wbKatakana
+	wbIdeographic // Han and Hiragana: each character is a word of its own
+	wbMidLetter
+	wbMidNum
+	wbMidNumLet
+	wbExtendNumLet
+	wbExtend // Combining marks, format characters and ZWJ
+	wbHyphen
+)
+
+func classifyWordBreak(r rune) wordBreakClass {
+	switch r {
+	case '\'', '.', '\u2018', '\u2019', '\u2024', '\uFE52', '\uFF07', '\uFF0E':
+		return wbMidNumLet
+	case ':', '\u00B7', '\u0387', '\u05F4', '\u2027', '\uFE13', '\uFE55', '\uFF1A':
+		return wbMidLetter
+	case ',', ';', '\u037E', '\u0589', '\u060C', '\u060D', '\u066C', '\u07F8', '\u2044', '\uFE10', '\uFE14', '\uFE50', '\uFE54', '\uFF0C', '\uFF1B':
+		return wbMidNum
+	case '-', '\u2010', '\u2011':
+		return wbHyphen
+	case '\u30FC', '\uFF70':
+		return wbKatakana // Prolonged sound marks belong to katakana words
+	case '\u202F':
+		return wbExtendNumLet
+	case '\u200D':
+		return wbExtend
+	}
+	switch {
+	case unicode.In(r, unicode.Mn, unicode.Me, unicode.Mc, unicode.Cf):
+		return wbExtend
+	case unicode.Is(unicode.Katakana, r):
+		return wbKatakana
+	case unicode.In(r, unicode.Han, unicode.Hiragana):
+		return wbIdeographic
+	case unicode.IsLetter(r):
+		return wbALetter
+	case unicode.IsDigit(r):
+		return wbNumeric
+	case unicode.Is(unicode.Pc, r):
+		return wbExtendNumLet
+	}
+	return wbOther
+}
+
+// wordSegment is a byte range of text holding one word
+type wordSegment struct {
+	Start, End int
+}
+
+// segmentWords splits text into words following the UAX #29 word boundary
+// rules: letters join across apostrophes and periods ("don't", "e.g"),
+// digits across separators ("3.14", "1,000"), and Han or Hiragana characters
+// stand alone. One tailoring keeps hyphenated words ("well-known") whole.
+// Only segments containing a letter or digit are returned.
+func segmentWords(text string) []wordSegment {
+	type unit struct {
+		class      wordBreakClass
+		start, end int
+	}
+	// WB4: combining marks and format characters attach to what precedes them
+	var units []unit
+	for i, r := range text {
+		class := classifyWordBreak(r)
+		size := utf8.RuneLen(r)
+		if size < 0 {
+			size = 1
+		}
+		if class == wbExtend && len(units) > 0 {
+			units[len(units)-1].end = i + size
+			continue
+		}
+		units = append(units, unit{class, i, i + size})
+	}
+
+	isLetter := func(c wordBreakClass) bool { return c == wbALetter }
+	at := func(k int) wordBreakClass {
+		if k < 0 || k >= len(units) {
+			return wbOther
+		}
+		return units[k].class
+	}
+	// joins reports whether there is no word boundary between units k-1 and k
+	joins := func(k int) bool {
+		prev, cur, next := at(k-1), at(k), at(k+1)
+		switch {
+		case isLetter(prev) && isLetter(cur): // WB5
+			return true
+		case (prev == wbNumeric || isLetter(prev)) && (cur == wbNumeric || isLetter(cur)): // WB8-WB10
+			return true
+		case prev == wbKatakana && cur == wbKatakana: // WB13
+			return true
+		case cur == wbExtendNumLet && (isLetter(prev) || prev == wbNumeric || prev == wbKatakana || prev == wbExtendNumLet): // WB13a
+			return true
+		case prev == wbExtendNumLet && (isLetter(cur) || cur == wbNumeric || cur == wbKatakana): // WB13b
+			return true
+		case (cur == wbMidLetter || cur == wbMidNumLet || cur == wbHyphen) && isLetter(prev) && isLetter(next): // WB6, tailored for hyphens
+			return true
+		case (prev == wbMidLetter || prev == wbMidNumLet || prev == wbHyphen) && isLetter(cur) && isLetter(at(k-2)): // WB7
+			return true
+		case (cur == wbMidNum || cur == wbMidNumLet) && prev == wbNumeric && next == wbNumeric: // WB12
+			return true
+		case (prev == wbMidNum || prev == wbMidNumLet) && cur == wbNumeric && at(k-2) == wbNumeric: // WB11
+			return true
+		}
+		return false
+	}
+
+	var segments []wordSegment
+	for k := 0; k < len(units); {
+		end := k + 1
+		for end < len(units) && joins(end) {
+			end++
+		}
+		for _, u := range units[k:end] {
+			if u.class == wbALetter || u.class == wbNumeric || u.class == wbKatakana || u.class == wbIdeographic {
+				segments = append(segments, wordSegment{units[k].start, units[end-1].end})
+				break
+			}
+		}
+		k = end
+	}
+	return segments
+}
+
+// extractWords returns the lowercased words of text, segmented by UAX #29
+func extractWords(text string) []string {
+	segments := segmentWords(text)
+	words := make([]string, 0, len(segments))
+	for _, s := range segments {
+		words = append(words, strings.ToLower(text[s.Start:s.End]))
+	}
+	return words
+}
@@ -0,0 +1,37 @@
+package analyzer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtractWords(t *testing.T) {
+	cases := []struct {
+		text string
+		want []string
+	}{
+		{"Don't break the café’s naïve résumé.", []string{"don't", "break", "the", "café’s", "naïve", "résumé"}},
+		{"A well-known fix costs 1,000.50 dollars -- in Q3 2024.", []string{"a", "well-known", "fix", "costs", "1,000.50", "dollars", "in", "q3", "2024"}},
+		{"Über Straße: größer", []string{"über", "straße", "größer"}},
+		{"Привет, мир!", []string{"привет", "мир"}},
+		{"東京タワーへ行く", []string{"東", "京", "タワー", "へ", "行", "く"}},
+		{"snake_case and e.g. trailing dots...", []string{"snake_case", "and", "e.g", "trailing", "dots"}},
+		{"cafe\u0301 -- ---", []string{"cafe\u0301"}}, // Decomposed accent
+	}
+	for _, tc := range cases {
+		if got := extractWords(tc.text); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("extractWords(%q) = %q, want %q", tc.text, got, tc.want)
+		}
+	}
+}
+
+func TestComplexityCountsNonASCIIWords(t *testing.T) {
+	text := "El niño comió una manzana. La señora leyó un libro."
+	metrics := AnalyzeComplexity(text)
+	if got := metrics.WordStats.TotalWords.Value; got != 10 {
+		t.Errorf("expected 10 words, got %d", got)
+	}
+	if metrics.FleschReadingEase.Value == 0 {
+		t.Error("expected readability to be computed for accented text")
+	}
+}
@@ -1,47 +1,246 @@
 package analyzer
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
-// WorkerPool manages a limited number of goroutines for concurrent processing
+// Priority orders jobs in a WorkerPool. Workers always take interactive jobs
+// first, and batch jobs never hold the last free worker, so a long batch
+// cannot hold up interactive grading.
+type Priority int
+
+// Job priorities, most urgent first
+const (
+	PriorityInteractive Priority = iota
+	PriorityBatch
+	priorityCount
+)
+
+var priorityNames = [priorityCount]string{"interactive", "batch"}
+
+func (p Priority) String() string {
+	if p < 0 || p >= priorityCount {
+		return fmt.Sprintf("priority(%d)", int(p))
+	}
+	return priorityNames[p]
+}
+
+// ErrPoolClosed is returned when submitting to a pool after Close
+var ErrPoolClosed = errors.New("worker pool closed")
+
+// WorkerPool manages a limited number of goroutines for concurrent processing.
+// Each priority has a bounded queue; submitting to a full queue blocks, which
+// pushes back on whoever is producing the work.
 type WorkerPool struct {
 	maxWorkers int
-	tasks      chan func()
+	slots      [priorityCount]chan struct{} // Queue capacity; a job holds a slot until it starts
 	wg         sync.WaitGroup
+
+	mu      sync.Mutex
+	ready   *sync.Cond // Signalled when a job is queued, a batch job ends or the pool closes
+	queues  [priorityCount][]poolJob
+	running [priorityCount]int
+	stats   [priorityCount]JobStats
+	closed  bool
+}
+
+type poolJob struct {
+	task   func()
+	queued time.Time
 }
 
-// NewWorkerPool creates a new worker pool with the specified number of workers
+// JobStats counts one priority's jobs and the time they spent waiting and running
+type JobStats struct {
+	Queued    int64   `json:"queued"` // Waiting for a worker now
+	Running   int64   `json:"running"`
+	Completed int64   `json:"completed"`
+	WaitMs    float64 `json:"wait_ms"` // Total over completed jobs
+	RunMs     float64 `json:"run_ms"`  // Total over completed jobs
+}
+
+// poolCounters track every pool in the process for health checks and metrics
+var poolCounters struct {
+	pools, idle, busy, queued atomic.Int64
+
+	mu         sync.Mutex
+	byPriority [priorityCount]JobStats
+}
+
+// WorkerPoolStats reports the pools currently running in this process
+func WorkerPoolStats() WorkerPoolState {
+	state := WorkerPoolState{
+		Pools:      poolCounters.pools.Load(),
+		Idle:       poolCounters.idle.Load(),
+		Busy:       poolCounters.busy.Load(),
+		Queued:     poolCounters.queued.Load(),
+		Priorities: map[string]JobStats{},
+	}
+	poolCounters.mu.Lock()
+	defer poolCounters.mu.Unlock()
+	for p, s := range poolCounters.byPriority {
+		state.Priorities[Priority(p).String()] = s
+	}
+	return state
+}
+
+// NewWorkerPool creates a new worker pool with the specified number of
+// workers and room for twice that many queued jobs per priority
 func NewWorkerPool(maxWorkers int) *WorkerPool {
+	if maxWorkers <= 0 {
+		maxWorkers = CurrentRuntimeConfig().Workers
+	}
 	if maxWorkers <= 0 {
 		maxWorkers = 2 // Conservative default for WASM
 	}
-	
-	pool := &WorkerPool{
-		maxWorkers: maxWorkers,
-		tasks:      make(chan func(), maxWorkers*2),
+
+	pool := &WorkerPool{maxWorkers: maxWorkers}
+	pool.ready = sync.NewCond(&pool.mu)
+	for p := range pool.slots {
+		pool.slots[p] = make(chan struct{}, maxWorkers*2)
 	}
-	
+
 	// Start worker goroutines
+	poolCounters.pools.Add(1)
+	poolCounters.idle.Add(int64(maxWorkers))
 	for i := 0; i < maxWorkers; i++ {
 		go pool.worker()
 	}
-	
+
 	return pool
 }
 
-// worker processes tasks from the queue
+// worker runs jobs until the pool is closed and drained
 func (p *WorkerPool) worker() {
-	for task := range p.tasks {
-		task()
+	for {
+		job, priority, ok := p.next()
+		if !ok {
+			break
+		}
+		start := time.Now()
+		poolCounters.idle.Add(-1)
+		poolCounters.busy.Add(1)
+		job.task()
+		poolCounters.busy.Add(-1)
+		poolCounters.idle.Add(1)
+		p.finish(priority, start.Sub(job.queued), time.Since(start))
 		p.wg.Done()
 	}
+	poolCounters.idle.Add(-1)
+}
+
+// next waits for the most urgent job a worker may start, returning false
+// once the pool is closed and empty
+func (p *WorkerPool) next() (poolJob, Priority, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for {
+		for priority := Priority(0); priority < priorityCount; priority++ {
+			if len(p.queues[priority]) == 0 || !p.mayStart(priority) {
+				continue
+			}
+			job := p.queues[priority][0]
+			p.queues[priority] = p.queues[priority][1:]
+			p.running[priority]++
+			p.adjust(priority, func(s *JobStats) { s.Queued--; s.Running++ })
+			<-p.slots[priority]
+			poolCounters.queued.Add(-1)
+			return job, priority, true
+		}
+		if p.closed && p.queuedJobs() == 0 {
+			return poolJob{}, 0, false
+		}
+		p.ready.Wait()
+	}
+}
+
+// mayStart keeps one worker back from batch jobs when the pool has more than one
+func (p *WorkerPool) mayStart(priority Priority) bool {
+	if priority != PriorityBatch {
+		return true
+	}
+	return p.running[PriorityBatch] < max(1, p.maxWorkers-1)
+}
+
+func (p *WorkerPool) queuedJobs() int {
+	n := 0
+	for _, q := range p.queues {
+		n += len(q)
+	}
+	return n
 }
 
-// Submit adds a task to the worker pool
+// finish records a completed job's timing
+func (p *WorkerPool) finish(priority Priority, wait, run time.Duration) {
+	p.mu.Lock()
+	p.running[priority]--
+	p.adjust(priority, func(s *JobStats) {
+		s.Running--
+		s.Completed++
+		s.WaitMs += float64(wait.Microseconds()) / 1000
+		s.RunMs += float64(run.Microseconds()) / 1000
+	})
+	p.mu.Unlock()
+	if priority == PriorityBatch {
+		// A worker held back from batch work may now take some
+		p.ready.Broadcast()
+	}
+}
+
+// adjust applies change to the pool's and the process's stats; callers hold p.mu
+func (p *WorkerPool) adjust(priority Priority, change func(*JobStats)) {
+	change(&p.stats[priority])
+	poolCounters.mu.Lock()
+	change(&poolCounters.byPriority[priority])
+	poolCounters.mu.Unlock()
+}
+
+// Submit adds an interactive task to the worker pool, waiting for queue space
 func (p *WorkerPool) Submit(task func()) {
+	p.SubmitContext(context.Background(), PriorityInteractive, task)
+}
+
+// SubmitContext queues task at priority, blocking while that priority's queue
+// is full. It returns ctx's error if ctx ends first, or ErrPoolClosed.
+func (p *WorkerPool) SubmitContext(ctx context.Context, priority Priority, task func()) error {
+	if priority < 0 || priority >= priorityCount {
+		return fmt.Errorf("unknown job priority %d", int(priority))
+	}
+	select {
+	case p.slots[priority] <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		<-p.slots[priority]
+		return ErrPoolClosed
+	}
 	p.wg.Add(1)
-	p.tasks <- task
+	poolCounters.queued.Add(1)
+	p.queues[priority] = append(p.queues[priority], poolJob{task: task, queued: time.Now()})
+	p.adjust(priority, func(s *JobStats) { s.Queued++ })
+	p.ready.Signal()
+	return nil
+}
+
+// Stats reports this pool's jobs by priority
+func (p *WorkerPool) Stats() map[string]JobStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	out := make(map[string]JobStats, priorityCount)
+	for priority, s := range p.stats {
+		out[Priority(priority).String()] = s
+	}
+	return out
 }
 
 // Wait waits for all submitted tasks to complete
@@ -49,7 +248,39 @@ func (p *WorkerPool) Wait() {
 	p.wg.Wait()
 }
 
-// Close shuts down the worker pool
+// Close shuts down the worker pool once queued tasks have run
 func (p *WorkerPool) Close() {
-	close(p.tasks)
-}
\ No newline at end of file
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return
+	}
+	p.closed = true
+	poolCounters.pools.Add(-1)
+	p.ready.Broadcast()
+}
+
+// WritePoolPrometheus writes queue depth, running jobs and job timing totals
+// for every pool in the process in the Prometheus text exposition format
+func WritePoolPrometheus(w io.Writer) error {
+	stats := WorkerPoolStats().Priorities
+	metrics := []struct {
+		name, kind, help string
+		value            func(JobStats) float64
+	}{
+		{"fulcrum_worker_queue_depth", "gauge", "Jobs waiting for a worker.", func(s JobStats) float64 { return float64(s.Queued) }},
+		{"fulcrum_worker_jobs_running", "gauge", "Jobs running now.", func(s JobStats) float64 { return float64(s.Running) }},
+		{"fulcrum_worker_jobs_completed_total", "counter", "Jobs finished.", func(s JobStats) float64 { return float64(s.Completed) }},
+		{"fulcrum_worker_job_wait_seconds_total", "counter", "Time finished jobs spent queued.", func(s JobStats) float64 { return s.WaitMs / 1000 }},
+		{"fulcrum_worker_job_run_seconds_total", "counter", "Time finished jobs spent running.", func(s JobStats) float64 { return s.RunMs / 1000 }},
+	}
+	var b strings.Builder
+	for _, m := range metrics {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s %s\n", m.name, m.help, m.name, m.kind)
+		for _, priority := range priorityNames {
+			fmt.Fprintf(&b, "%s{priority=%q} %g\n", m.name, priority, m.value(stats[priority]))
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
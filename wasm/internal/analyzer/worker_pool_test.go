@@ -0,0 +1,105 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// blockingJob returns a task that signals started and then waits for release
+func blockingJob(started chan<- struct{}, release <-chan struct{}) func() {
+	return func() {
+		started <- struct{}{}
+		<-release
+	}
+}
+
+func TestWorkerPoolRunsInteractiveFirst(t *testing.T) {
+	pool := NewWorkerPool(1)
+	defer pool.Close()
+	started, release := make(chan struct{}), make(chan struct{})
+	pool.Submit(blockingJob(started, release))
+	<-started
+
+	var mu sync.Mutex
+	var order []string
+	record := func(name string) func() {
+		return func() {
+			mu.Lock()
+			order = append(order, name)
+			mu.Unlock()
+		}
+	}
+	pool.SubmitContext(context.Background(), PriorityBatch, record("batch"))
+	pool.SubmitContext(context.Background(), PriorityInteractive, record("interactive"))
+	close(release)
+	pool.Wait()
+
+	if want := []string{"interactive", "batch"}; !reflect.DeepEqual(order, want) {
+		t.Errorf("order = %v, want %v", order, want)
+	}
+	stats := pool.Stats()
+	if stats["batch"].Completed != 1 || stats["interactive"].Completed != 2 || stats["batch"].Queued != 0 {
+		t.Errorf("stats = %+v", stats)
+	}
+	if stats["batch"].WaitMs <= 0 {
+		t.Errorf("batch job wait not recorded: %+v", stats["batch"])
+	}
+}
+
+func TestWorkerPoolKeepsAWorkerForInteractive(t *testing.T) {
+	pool := NewWorkerPool(2)
+	defer pool.Close()
+	started, release := make(chan struct{}, 2), make(chan struct{})
+	pool.SubmitContext(context.Background(), PriorityBatch, blockingJob(started, release))
+	pool.SubmitContext(context.Background(), PriorityBatch, blockingJob(started, release))
+	<-started
+
+	done := make(chan struct{})
+	pool.Submit(func() { close(done) })
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("interactive job starved by batch jobs")
+	}
+	select {
+	case <-started:
+		t.Error("second batch job took the worker kept for interactive jobs")
+	default:
+	}
+	close(release)
+	pool.Wait()
+}
+
+func TestWorkerPoolBackpressure(t *testing.T) {
+	pool := NewWorkerPool(1)
+	defer pool.Close()
+	started, release := make(chan struct{}), make(chan struct{})
+	pool.Submit(blockingJob(started, release))
+	<-started
+
+	// One worker queues two jobs per priority
+	for i := 0; i < 2; i++ {
+		if err := pool.SubmitContext(context.Background(), PriorityBatch, func() {}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := pool.SubmitContext(ctx, PriorityBatch, func() {}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("full queue: got %v, want deadline exceeded", err)
+	}
+	if err := pool.SubmitContext(context.Background(), PriorityInteractive, func() {}); err != nil {
+		t.Errorf("interactive queue should still have room: %v", err)
+	}
+	close(release)
+	pool.Wait()
+
+	pool.Close()
+	if err := pool.SubmitContext(context.Background(), PriorityInteractive, func() {}); !errors.Is(err, ErrPoolClosed) {
+		t.Errorf("closed pool: got %v", err)
+	}
+}
@@ -0,0 +1,391 @@
+package analyzer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DecodeConfig decodes a JSON or YAML document into v, rejecting unknown
+// fields so typos are caught early. A document opening with "{" is read as
+// JSON; anything else as the YAML that config files use: block mappings and
+// sequences, one-line flow collections, plain and quoted scalars, | and >
+// block scalars and comments. Anchors, tags and multiple documents are not
+// supported.
+func DecodeConfig(data []byte, v interface{}) error {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] != '{' {
+		converted, err := yamlToJSON(string(data))
+		if err != nil {
+			return err
+		}
+		data = converted
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	return dec.Decode(v)
+}
+
+// yamlNumberPattern matches the scalars read as numbers rather than strings
+var yamlNumberPattern = regexp.MustCompile(`^[-+]?(\d+\.?\d*|\.\d+)([eE][-+]?\d+)?$`)
+
+// yamlToJSON converts a YAML document to JSON
+func yamlToJSON(src string) ([]byte, error) {
+	p := &yamlParser{lines: strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n")}
+	indent, _, ok, err := p.peek()
+	if err != nil {
+		return nil, err
+	}
+	var value interface{}
+	if ok {
+		if value, err = p.node(indent); err != nil {
+			return nil, err
+		}
+	}
+	if _, _, ok, err := p.peek(); err != nil || ok {
+		if err == nil {
+			err = fmt.Errorf("line %d: unexpected indentation", p.pos+1)
+		}
+		return nil, err
+	}
+	return json.Marshal(value)
+}
+
+// yamlParser reads a YAML document line by line
+type yamlParser struct {
+	lines []string
+	pos   int
+}
+
+// peek skips blank lines, comments and document markers and returns the
+// next line's indentation and content without its comment
+func (p *yamlParser) peek() (int, string, bool, error) {
+	for ; p.pos < len(p.lines); p.pos++ {
+		line := p.lines[p.pos]
+		content := strings.TrimLeft(line, " \t")
+		if content == "" || content[0] == '#' || line == "---" || line == "..." {
+			continue
+		}
+		indent := len(line) - len(content)
+		if strings.Contains(line[:indent], "\t") {
+			return 0, "", false, fmt.Errorf("line %d: tabs are not allowed in YAML indentation", p.pos+1)
+		}
+		return indent, stripYAMLComment(content), true, nil
+	}
+	return 0, "", false, nil
+}
+
+// node parses the mapping or sequence whose lines start at indent
+func (p *yamlParser) node(indent int) (interface{}, error) {
+	_, content, _, _ := p.peek()
+	if isYAMLItem(content) {
+		return p.sequence(indent)
+	}
+	return p.mapping(indent)
+}
+
+// sequence parses "- item" lines at indent
+func (p *yamlParser) sequence(indent int) (interface{}, error) {
+	items := []interface{}{}
+	for {
+		at, content, ok, err := p.peek()
+		if err != nil {
+			return nil, err
+		}
+		if !ok || at < indent || (at == indent && !isYAMLItem(content)) {
+			return items, nil
+		}
+		if at > indent {
+			return nil, fmt.Errorf("line %d: unexpected indentation", p.pos+1)
+		}
+		rest := strings.TrimLeft(strings.TrimPrefix(content, "-"), " ")
+		var item interface{}
+		switch {
+		case rest == "":
+			p.pos++
+			if next, _, ok, err := p.peek(); err != nil {
+				return nil, err
+			} else if ok && next > indent {
+				if item, err = p.node(next); err != nil {
+					return nil, err
+				}
+			}
+		case isYAMLItem(rest) || yamlKeyEnd(rest) >= 0:
+			// A nested list or a mapping that opens on the item's line:
+			// read it as if the dash were indentation
+			inner := indent + len(content) - len(rest)
+			p.lines[p.pos] = strings.Repeat(" ", inner) + rest
+			if item, err = p.node(inner); err != nil {
+				return nil, err
+			}
+		default:
+			p.pos++
+			if item, err = yamlValue(rest, p.pos); err != nil {
+				return nil, err
+			}
+		}
+		items = append(items, item)
+	}
+}
+
+// mapping parses "key: value" lines at indent
+func (p *yamlParser) mapping(indent int) (interface{}, error) {
+	m := map[string]interface{}{}
+	for {
+		at, content, ok, err := p.peek()
+		if err != nil {
+			return nil, err
+		}
+		if !ok || at < indent || (at == indent && isYAMLItem(content)) {
+			return m, nil
+		}
+		if at > indent {
+			return nil, fmt.Errorf("line %d: unexpected indentation", p.pos+1)
+		}
+		end := yamlKeyEnd(content)
+		if end < 0 {
+			return nil, fmt.Errorf("line %d: expected \"key: value\"", p.pos+1)
+		}
+		key := yamlScalar(content[:end])
+		if _, dup := m[key]; dup {
+			return nil, fmt.Errorf("line %d: duplicate key %q", p.pos+1, key)
+		}
+		rest := strings.TrimSpace(content[end+1:])
+		line := p.pos + 1
+		p.pos++
+
+		var value interface{}
+		switch {
+		case rest == "":
+			next, nextContent, ok, err := p.peek()
+			if err != nil {
+				return nil, err
+			}
+			// A sequence may sit at its key's indentation
+			if ok && (next > indent || (next == indent && isYAMLItem(nextContent))) {
+				if value, err = p.node(next); err != nil {
+					return nil, err
+				}
+			}
+		case rest[0] == '|' || rest[0] == '>':
+			value = p.blockScalar(indent, rest)
+		default:
+			if value, err = yamlValue(rest, line); err != nil {
+				return nil, err
+			}
+		}
+		m[key] = value
+	}
+}
+
+// blockScalar reads the lines of a | or > scalar indented under indent
+func (p *yamlParser) blockScalar(indent int, header string) string {
+	var lines []string
+	block := -1
+	for ; p.pos < len(p.lines); p.pos++ {
+		line := strings.TrimRight(p.lines[p.pos], " \t")
+		content := strings.TrimLeft(line, " ")
+		if content == "" {
+			lines = append(lines, "")
+			continue
+		}
+		at := len(line) - len(content)
+		if at <= indent {
+			break
+		}
+		if block < 0 {
+			block = at
+		}
+		lines = append(lines, line[min(block, at):])
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	var text string
+	if header[0] == '|' {
+		text = strings.Join(lines, "\n")
+	} else {
+		var b strings.Builder
+		for i, line := range lines {
+			switch {
+			case line == "":
+				b.WriteString("\n")
+			case i > 0 && lines[i-1] != "":
+				b.WriteString(" " + line)
+			default:
+				b.WriteString(line)
+			}
+		}
+		text = b.String()
+	}
+	if !strings.Contains(header, "-") && text != "" {
+		text += "\n"
+	}
+	return text
+}
+
+// isYAMLItem reports whether content is a "- " sequence item
+func isYAMLItem(content string) bool {
+	return content == "-" || strings.HasPrefix(content, "- ")
+}
+
+// yamlKeyEnd returns the index of the colon ending a mapping key, or -1.
+// The colon must be followed by a space or end the line, and sit outside
+// quotes and flow collections.
+func yamlKeyEnd(content string) int {
+	if content != "" && (content[0] == '[' || content[0] == '{') {
+		return -1
+	}
+	var quote byte
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case (c == '"' || c == '\'') && i == 0:
+			quote = c
+		case c == ':' && (i+1 == len(content) || content[i+1] == ' '):
+			return i
+		}
+	}
+	return -1
+}
+
+// stripYAMLComment cuts a " #" comment that starts outside quotes
+func stripYAMLComment(content string) string {
+	var quote byte
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			if i == 0 || strings.ContainsRune(" [{,:", rune(content[i-1])) {
+				quote = c
+			}
+		case c == '#' && i > 0 && (content[i-1] == ' ' || content[i-1] == '\t'):
+			return strings.TrimRight(content[:i], " \t")
+		}
+	}
+	return strings.TrimRight(content, " \t")
+}
+
+// yamlValue converts an inline value: a flow collection or a scalar
+func yamlValue(s string, line int) (interface{}, error) {
+	if s[0] != '[' && s[0] != '{' {
+		if (s[0] == '"' || s[0] == '\'') && (len(s) == 1 || s[len(s)-1] != s[0]) {
+			return nil, fmt.Errorf("line %d: unclosed %c quote", line, s[0])
+		}
+		return yamlTyped(s), nil
+	}
+	value, rest, err := yamlFlow(s)
+	if err == nil && strings.TrimSpace(rest) != "" {
+		err = fmt.Errorf("unexpected %q after the closing bracket", strings.TrimSpace(rest))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("line %d: %w", line, err)
+	}
+	return value, nil
+}
+
+// yamlFlow parses the [..] or {..} collection s opens with and returns the
+// text after it
+func yamlFlow(s string) (interface{}, string, error) {
+	closing := byte(']')
+	if s[0] == '{' {
+		closing = '}'
+	}
+	list := []interface{}{}
+	m := map[string]interface{}{}
+	s = strings.TrimLeft(s[1:], " ")
+	for {
+		if s == "" {
+			return nil, "", fmt.Errorf("unclosed %c", flowOpener(closing))
+		}
+		if s[0] == closing {
+			if closing == '}' {
+				return m, s[1:], nil
+			}
+			return list, s[1:], nil
+		}
+		var key string
+		if closing == '}' {
+			end := yamlKeyEnd(s)
+			if end < 0 {
+				return nil, "", fmt.Errorf("expected \"key: value\" in {...}")
+			}
+			key = yamlScalar(s[:end])
+			s = strings.TrimLeft(s[end+1:], " ")
+		}
+		var value interface{}
+		if s != "" && (s[0] == '[' || s[0] == '{') {
+			var err error
+			if value, s, err = yamlFlow(s); err != nil {
+				return nil, "", err
+			}
+		} else {
+			end := flowScalarEnd(s)
+			value, s = yamlTyped(strings.TrimSpace(s[:end])), s[end:]
+		}
+		if closing == '}' {
+			m[key] = value
+		} else {
+			list = append(list, value)
+		}
+		s = strings.TrimLeft(s, " ")
+		if strings.HasPrefix(s, ",") {
+			s = strings.TrimLeft(s[1:], " ")
+		} else if s != "" && s[0] != closing {
+			return nil, "", fmt.Errorf("expected , or %c", closing)
+		}
+	}
+}
+
+func flowOpener(closing byte) byte {
+	if closing == '}' {
+		return '{'
+	}
+	return '['
+}
+
+// flowScalarEnd returns where a scalar inside a flow collection ends
+func flowScalarEnd(s string) int {
+	if s != "" && (s[0] == '"' || s[0] == '\'') {
+		if end := strings.IndexByte(s[1:], s[0]); end >= 0 {
+			return end + 2
+		}
+		return len(s)
+	}
+	if end := strings.IndexAny(s, ",]}"); end >= 0 {
+		return end
+	}
+	return len(s)
+}
+
+// yamlTyped reads a scalar as a string, number, boolean or null
+func yamlTyped(s string) interface{} {
+	if s != "" && (s[0] == '"' || s[0] == '\'') {
+		return yamlScalar(s)
+	}
+	switch s {
+	case "", "~", "null", "Null", "NULL":
+		return nil
+	case "true", "True", "TRUE":
+		return true
+	case "false", "False", "FALSE":
+		return false
+	}
+	if yamlNumberPattern.MatchString(s) {
+		if n, err := strconv.ParseFloat(s, 64); err == nil {
+			return n
+		}
+	}
+	return s
+}
@@ -0,0 +1,79 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestYAMLToJSON(t *testing.T) {
+	src := `# Settings
+server:
+  addr: ":9090"   # listen address
+  max_body_bytes: 1048576
+  read_timeout: 15s
+cors:
+  allowed_origins:
+  - https://app.example.com
+  - "https://admin.example.com"
+analysis:
+  stages: [tokens, grade]
+  deterministic: true
+  phone_region: ~
+  glossary:
+    - term: SLA
+      definition: 'Service level agreement, it''s contractual'
+      aliases: []
+  note: |
+    line one
+    line two
+  summary: >-
+    folded
+    text
+hooks: {url: "https://hooks.example.com/a,b", events: [done]}
+`
+	got, err := yamlToJSON(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"analysis":{"deterministic":true,"glossary":[{"aliases":[],"definition":"Service level agreement, it's contractual","term":"SLA"}],"note":"line one\nline two\n","phone_region":null,"stages":["tokens","grade"],"summary":"folded text"},` +
+		`"cors":{"allowed_origins":["https://app.example.com","https://admin.example.com"]},` +
+		`"hooks":{"events":["done"],"url":"https://hooks.example.com/a,b"},` +
+		`"server":{"addr":":9090","max_body_bytes":1048576,"read_timeout":"15s"}}`
+	if string(got) != want {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+}
+
+func TestYAMLToJSONErrors(t *testing.T) {
+	cases := []struct {
+		name, src, err string
+	}{
+		{"tab indentation", "server:\n\taddr: x", "tabs"},
+		{"indented under a value", "name: report\n  format: pdf", "unexpected indentation"},
+		{"duplicate key", "a: 1\na: 2", "duplicate key"},
+		{"not a mapping", "a: 1\njust text", "expected \"key: value\""},
+		{"unclosed quote", "title: \"Q3 review", "unclosed"},
+		{"unclosed flow", "stages: [tokens, grade", "unclosed ["},
+	}
+	for _, c := range cases {
+		if _, err := yamlToJSON(c.src); err == nil || !strings.Contains(err.Error(), c.err) {
+			t.Errorf("%s: expected an error containing %q, got %v", c.name, c.err, err)
+		}
+	}
+}
+
+func TestDecodeConfig(t *testing.T) {
+	var v struct {
+		Name  string  `json:"name"`
+		Score float64 `json:"score"`
+	}
+	if err := DecodeConfig([]byte("name: strict\nscore: 72.5\n"), &v); err != nil || v.Name != "strict" || v.Score != 72.5 {
+		t.Errorf("YAML: %+v, %v", v, err)
+	}
+	if err := DecodeConfig([]byte(`{"name": "json", "score": 1}`), &v); err != nil || v.Name != "json" {
+		t.Errorf("JSON: %+v, %v", v, err)
+	}
+	if err := DecodeConfig([]byte("name: x\nscroe: 1\n"), &v); err == nil || !strings.Contains(err.Error(), "scroe") {
+		t.Errorf("expected the unknown field to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,169 @@
+// Package client is a typed Go client for the Fulcrum HTTP API described by
+// analyzer.OpenAPISpec (served at /openapi.json).
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"fulcrum-wasm/internal/analyzer"
+)
+
+// Client calls one Fulcrum server
+type Client struct {
+	BaseURL    string
+	HTTPClient *http.Client
+}
+
+// Error is a non-2xx response from the server
+type Error struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("fulcrum: %d %s", e.StatusCode, e.Message)
+}
+
+// New returns a client for the server at baseURL, e.g. "http://localhost:8080"
+func New(baseURL string) *Client {
+	return &Client{BaseURL: strings.TrimRight(baseURL, "/"), HTTPClient: http.DefaultClient}
+}
+
+// Analyze runs the analysis and returns the raw result JSON, which callers decode
+// into the fields they need
+func (c *Client) Analyze(ctx context.Context, text string, opts analyzer.AnalysisOptions) (json.RawMessage, error) {
+	var result json.RawMessage
+	err := c.do(ctx, http.MethodPost, "/analyze", analyzer.AnalyzeRequest{Text: text, Options: opts}, &result)
+	return result, err
+}
+
+// Batch analyzes several prompts; per-item failures are reported in the response
+func (c *Client) Batch(ctx context.Context, items []analyzer.AnalyzeRequest) (*analyzer.BatchResponse, error) {
+	var resp analyzer.BatchResponse
+	if err := c.do(ctx, http.MethodPost, "/batch", analyzer.BatchRequest{Items: items}, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Compare grades two versions of a prompt with the grader and rubric in opts
+func (c *Client) Compare(ctx context.Context, original, revised string, opts analyzer.AnalysisOptions) (*analyzer.CompareResponse, error) {
+	var resp analyzer.CompareResponse
+	if err := c.do(ctx, http.MethodPost, "/compare", analyzer.CompareRequest{Original: original, Revised: revised, Options: opts}, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// History lists stored analyses matching filter; pass the previous page's
+// NextCursor to continue
+func (c *Client) History(ctx context.Context, limit int, cursor string, filter analyzer.HistoryFilter) (*analyzer.HistoryResponse, error) {
+	query := filter.Query()
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+	if cursor != "" {
+		query.Set("cursor", cursor)
+	}
+	path := "/history"
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
+	var resp analyzer.HistoryResponse
+	if err := c.do(ctx, http.MethodGet, path, nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Health reports whether the server is up and which result schema it speaks
+func (c *Client) Health(ctx context.Context) (*analyzer.HealthResponse, error) {
+	var resp analyzer.HealthResponse
+	if err := c.do(ctx, http.MethodGet, "/health", nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Ready runs the server's readiness checks. A failing check comes back as an
+// *Error with status 503.
+func (c *Client) Ready(ctx context.Context) (*analyzer.HealthResponse, error) {
+	var resp analyzer.HealthResponse
+	if err := c.do(ctx, http.MethodGet, "/health/ready", nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Report analyzes a prompt and returns the rendered report in format
+// (analyzer.ReportHTML, analyzer.ReportMarkdown or analyzer.ReportPDF)
+func (c *Client) Report(ctx context.Context, text string, opts analyzer.AnalysisOptions, format, title string) ([]byte, error) {
+	query := url.Values{}
+	query.Set("format", format)
+	if title != "" {
+		query.Set("title", title)
+	}
+	return c.send(ctx, http.MethodPost, "/report?"+query.Encode(), analyzer.AnalyzeRequest{Text: text, Options: opts}, "*/*")
+}
+
+// do sends body as JSON and decodes a 2xx response into out
+func (c *Client) do(ctx context.Context, method, path string, body, out interface{}) error {
+	data, err := c.send(ctx, method, path, body, "application/json")
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
+
+// send sends body as JSON and returns a 2xx response body
+func (c *Client) send(ctx context.Context, method, path string, body interface{}, accept string) ([]byte, error) {
+	var reader io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", accept)
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var apiErr analyzer.APIError
+		if json.Unmarshal(data, &apiErr) != nil || apiErr.Error == "" {
+			apiErr.Error = strings.TrimSpace(string(data))
+		}
+		return nil, &Error{StatusCode: resp.StatusCode, Message: apiErr.Error}
+	}
+	return data, nil
+}
@@ -0,0 +1,157 @@
+//go:build !js
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"fulcrum-wasm/internal/analyzer"
+)
+
+// TestAgainstHandlers runs the client against the server's own handlers
+func TestAgainstHandlers(t *testing.T) {
+	cfg := analyzer.DefaultServerConfig()
+	queue := analyzer.NewWorkerPool(2)
+	entries := []analyzer.HistoryEntry{
+		{ID: "e2", Tags: analyzer.AnalysisTags{Project: "support-bot"}},
+		{ID: "e1", Tags: analyzer.AnalysisTags{Project: "coding-agent"}},
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/analyze", analyzer.AnalyzeHandler(cfg, analyzer.FullAnalysis(nil)))
+	mux.Handle("/batch", analyzer.BatchHandler(cfg, queue, analyzer.FullAnalysis(nil)))
+	mux.Handle("/compare", analyzer.CompareHandler(cfg))
+	mux.Handle("/report", analyzer.ReportHandler(cfg))
+	mux.Handle("/history", analyzer.HistoryHandler(func(ctx context.Context, q analyzer.HistoryQuery) (analyzer.HistoryResponse, error) {
+		return analyzer.PageHistory(entries, q)
+	}))
+	mux.Handle("/health", analyzer.LivenessHandler(analyzer.HealthConfig{Started: time.Now()}))
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	c := New(ts.URL + "/")
+	ctx := context.Background()
+
+	raw, err := c.Analyze(ctx, "Write unit tests for the parser.", analyzer.AnalysisOptions{Stages: []string{analyzer.StageGrade}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var result struct {
+		Grades analyzer.GradeEnvelope `json:"grades"`
+	}
+	if err := json.Unmarshal(raw, &result); err != nil || result.Grades.Grade == "" {
+		t.Errorf("Analyze = %s, %v", raw, err)
+	}
+
+	batch, err := c.Batch(ctx, []analyzer.AnalyzeRequest{{Text: "Summarize the report."}, {Text: ""}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(batch.Results) != 2 || batch.Results[0].Error != "" || batch.Results[1].Error == "" {
+		t.Errorf("Batch = %+v", batch.Results)
+	}
+
+	cmp, err := c.Compare(ctx, "Write code.", "Write a Go function that parses ISO 8601 dates.", analyzer.AnalysisOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cmp.OriginalGrade == nil || cmp.RevisedGrade == nil {
+		t.Errorf("Compare = %+v", cmp)
+	}
+
+	history, err := c.History(ctx, 1, "", analyzer.HistoryFilter{Project: "coding-agent"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history.Entries) != 1 || history.Entries[0].ID != "e1" {
+		t.Errorf("History = %+v", history)
+	}
+
+	health, err := c.Health(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if health.Status != "ok" || health.SchemaVersion != analyzer.ResultSchemaVersion {
+		t.Errorf("Health = %+v", health)
+	}
+
+	report, err := c.Report(ctx, "Write unit tests for the parser.", analyzer.AnalysisOptions{}, analyzer.ReportMarkdown, "Parser prompt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(report), "Parser prompt") {
+		t.Errorf("Report is missing its title:\n%s", report)
+	}
+
+	_, err = c.Compare(ctx, "Write code.", "", analyzer.AnalysisOptions{})
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest || !strings.Contains(apiErr.Message, "revised") {
+		t.Errorf("Compare without revised: error = %v", err)
+	}
+}
+
+// TestRequests checks the method, path, query and headers the client sends
+func TestRequests(t *testing.T) {
+	var got *http.Request
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		w.Write([]byte(`{}`))
+	}))
+	defer ts.Close()
+	c := New(ts.URL)
+	ctx := context.Background()
+
+	if _, err := c.History(ctx, 5, "abc", analyzer.HistoryFilter{Author: "ana", Labels: []string{"tone", "safety"}}); err != nil {
+		t.Fatal(err)
+	}
+	q := got.URL.Query()
+	if got.Method != http.MethodGet || got.URL.Path != "/history" || q.Get("limit") != "5" || q.Get("cursor") != "abc" || q.Get("author") != "ana" || len(q["label"]) != 2 {
+		t.Errorf("History sent %s %s", got.Method, got.URL)
+	}
+
+	if _, err := c.Compare(ctx, "a", "b", analyzer.AnalysisOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if got.Method != http.MethodPost || got.Header.Get("Content-Type") != "application/json" || got.Header.Get("Accept") != "application/json" {
+		t.Errorf("Compare sent %s with headers %v", got.Method, got.Header)
+	}
+
+	if _, err := c.Report(ctx, "a", analyzer.AnalysisOptions{}, analyzer.ReportHTML, ""); err != nil {
+		t.Fatal(err)
+	}
+	if got.URL.Query().Get("format") != analyzer.ReportHTML || got.URL.Query().Has("title") || got.Header.Get("Accept") != "*/*" {
+		t.Errorf("Report sent %s with Accept %q", got.URL, got.Header.Get("Accept"))
+	}
+}
+
+// TestErrors checks that non-2xx responses come back as *Error, with the
+// API error message when the body has one
+func TestErrors(t *testing.T) {
+	cases := []struct {
+		status  int
+		body    string
+		message string
+	}{
+		{http.StatusServiceUnavailable, `{"status": "degraded"}`, `{"status": "degraded"}`},
+		{http.StatusBadRequest, `{"error": "text is required"}`, "text is required"},
+		{http.StatusBadGateway, "upstream down\n", "upstream down"},
+	}
+	for _, tc := range cases {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(tc.status)
+			w.Write([]byte(tc.body))
+		}))
+		_, err := New(ts.URL).Ready(context.Background())
+		ts.Close()
+		var apiErr *Error
+		if !errors.As(err, &apiErr) || apiErr.StatusCode != tc.status || apiErr.Message != tc.message {
+			t.Errorf("%d %q: error = %v", tc.status, tc.body, err)
+		}
+	}
+}
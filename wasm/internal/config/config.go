@@ -0,0 +1,387 @@
+//go:build !js
+
+// Package config loads server settings from a YAML or JSON file and FULCRUM_*
+// environment variables so deployments can change them without recompiling.
+package config
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"fulcrum-wasm/internal/analyzer"
+)
+
+// Config is the full server configuration. Environment variables override the file.
+type Config struct {
+	Server   ServerSettings   `json:"server"`
+	CORS     CORSSettings     `json:"cors"`
+	Storage  StorageSettings  `json:"storage"`
+	Admin    AdminSettings    `json:"admin"`
+	LLM      LLMSettings      `json:"llm"`
+	Webhooks WebhookSettings  `json:"webhooks"`
+	Analysis AnalysisSettings `json:"analysis"`
+}
+
+// ServerSettings mirrors analyzer.ServerConfig with human-readable durations
+type ServerSettings struct {
+	Addr            string   `json:"addr"`
+	MaxBodyBytes    int64    `json:"max_body_bytes"`
+	ReadTimeout     Duration `json:"read_timeout"`
+	WriteTimeout    Duration `json:"write_timeout"`
+	RequestTimeout  Duration `json:"request_timeout"`
+	ShutdownTimeout Duration `json:"shutdown_timeout"`
+}
+
+// CORSSettings lists the origins allowed to call the API; "*" allows any
+type CORSSettings struct {
+	AllowedOrigins []string `json:"allowed_origins"`
+}
+
+// StorageSettings points at the result store
+type StorageSettings struct {
+	DSN string `json:"dsn"`
+}
+
+// AdminSettings protects /admin/config. The token is best left to
+// FULCRUM_ADMIN_TOKEN; without one the endpoint is disabled.
+type AdminSettings struct {
+	Token string `json:"token"`
+}
+
+// LLMSettings configures the LLM rewriter. The API key is best left to FULCRUM_LLM_API_KEY.
+type LLMSettings struct {
+	Provider  string   `json:"provider"`
+	Endpoint  string   `json:"endpoint"`
+	APIKey    string   `json:"api_key"`
+	Model     string   `json:"model"`
+	MaxTokens int      `json:"max_tokens"`
+	Timeout   Duration `json:"timeout"`
+}
+
+// WebhookSettings mirrors analyzer.WebhookConfig with a human-readable timeout
+type WebhookSettings struct {
+	Hooks        []analyzer.WebhookHook `json:"hooks"`
+	Gate         analyzer.QualityGate   `json:"gate"`
+	LinkTemplate string                 `json:"link_template"`
+	Timeout      Duration               `json:"timeout"`
+}
+
+// AnalysisSettings holds analyzer defaults applied to every request
+type AnalysisSettings struct {
+	Stages            []string `json:"stages"`
+	Format            string   `json:"format"`
+	MemoryBudgetBytes int64    `json:"memory_budget_bytes"`
+
+	// Rules disables suggestion rules or changes their priority by ID (FUL001...)
+	Rules analyzer.SuggestionRuleConfig `json:"rules"`
+	// Stopwords sets the default stopword language, packs and custom words
+	Stopwords analyzer.StopwordConfig `json:"stopwords"`
+	// Glossary defines the organization's domain terms for every request
+	Glossary []analyzer.GlossaryTerm `json:"glossary"`
+	// Spelling lists words the spell checker should accept
+	Spelling analyzer.SpellingConfig `json:"spelling"`
+	// Classifier holds prompt categories trained on the organization's examples
+	Classifier analyzer.ClassifierModel `json:"classifier"`
+	// ReadingSpeed sets the words per minute behind the reading time estimates
+	ReadingSpeed analyzer.ReadingSpeedConfig `json:"reading_speed"`
+	// InclusiveLanguage enables inclusive language suggestions and the organization's term lists
+	InclusiveLanguage analyzer.InclusiveLanguageConfig `json:"inclusive_language"`
+	// Rubric replaces the modern engine's weights, grade thresholds and
+	// suggestion priorities with the organization's standards
+	Rubric *analyzer.RubricConfig `json:"rubric"`
+	// ClusteringStrategy is greedy, kmeans, community or agglomerative
+	ClusteringStrategy string `json:"clustering_strategy"`
+	// Deterministic zeroes timings and derives request IDs from the text so results are reproducible
+	Deterministic bool `json:"deterministic"`
+	// MaxClusterSentences caps the sentences returned per idea cluster; 0 returns all
+	MaxClusterSentences int `json:"max_cluster_sentences"`
+	// MaxSuggestionExamples caps how many suggestions keep their example text; 0 keeps all
+	MaxSuggestionExamples int `json:"max_suggestion_examples"`
+	// FullTransformationLog keeps full texts in the preprocessing transformation log
+	FullTransformationLog bool `json:"full_transformation_log"`
+	// PhoneRegion reads phone numbers without a calling code, e.g. "GB"; "" means US
+	PhoneRegion string `json:"phone_region"`
+	// ReferenceTime resolves relative dates such as "next Tuesday"; "" uses the time of analysis
+	ReferenceTime string `json:"reference_time"`
+	// StripEmoji removes emoji from the cleaned preprocessing text
+	StripEmoji bool `json:"strip_emoji"`
+	// Cleaning lists the preprocessing cleaning steps in order; empty uses the default pipeline
+	Cleaning []string `json:"cleaning"`
+	// PreserveStructure keeps line and paragraph breaks through preprocessing
+	PreserveStructure bool `json:"preserve_structure"`
+	// TokenStream adds the per-token stream with offsets and POS tags
+	TokenStream bool `json:"token_stream"`
+}
+
+// Duration accepts "30s"-style strings or nanosecond numbers in JSON
+type Duration time.Duration
+
+// UnmarshalJSON parses a duration string or number
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		parsed, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		*d = Duration(parsed)
+		return nil
+	}
+	var n int64
+	if err := json.Unmarshal(data, &n); err != nil {
+		return fmt.Errorf("duration must be a string like \"30s\" or a number of nanoseconds")
+	}
+	*d = Duration(n)
+	return nil
+}
+
+// MarshalJSON writes the duration as a string
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// Default returns the built-in configuration
+func Default() Config {
+	server := analyzer.DefaultServerConfig()
+	return Config{
+		Server: ServerSettings{
+			Addr:            server.Addr,
+			MaxBodyBytes:    server.MaxBodyBytes,
+			ReadTimeout:     Duration(server.ReadTimeout),
+			WriteTimeout:    Duration(server.WriteTimeout),
+			RequestTimeout:  Duration(server.RequestTimeout),
+			ShutdownTimeout: Duration(server.ShutdownTimeout),
+		},
+		LLM: LLMSettings{
+			MaxTokens: 1024,
+			Timeout:   Duration(30 * time.Second),
+		},
+		Webhooks: WebhookSettings{
+			Timeout: Duration(10 * time.Second),
+		},
+		Analysis: AnalysisSettings{
+			MemoryBudgetBytes: analyzer.DefaultMemoryBudgetBytes,
+		},
+	}
+}
+
+// Load reads the defaults, the YAML or JSON file at path (if any) and then
+// the environment. Unknown fields and malformed variables are errors.
+func Load(path string) (Config, error) {
+	return load(path, os.LookupEnv)
+}
+
+func load(path string, lookup func(string) (string, bool)) (Config, error) {
+	cfg := Default()
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return Config{}, fmt.Errorf("read config: %w", err)
+		}
+		if err := analyzer.DecodeConfig(data, &cfg); err != nil {
+			return Config{}, fmt.Errorf("invalid config %s: %w", path, err)
+		}
+	}
+	if err := cfg.applyEnv(lookup); err != nil {
+		return Config{}, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+// FromFlags registers --config on fs, parses args and loads the named file.
+// FULCRUM_CONFIG is used when the flag is not given.
+func FromFlags(fs *flag.FlagSet, args []string) (Config, error) {
+	path := fs.String("config", os.Getenv("FULCRUM_CONFIG"), "path to a YAML or JSON config file")
+	if err := fs.Parse(args); err != nil {
+		return Config{}, err
+	}
+	return Load(*path)
+}
+
+// applyEnv overrides fields from FULCRUM_* variables
+func (cfg *Config) applyEnv(lookup func(string) (string, bool)) error {
+	text := map[string]*string{
+		"FULCRUM_SERVER_ADDR":     &cfg.Server.Addr,
+		"FULCRUM_STORAGE_DSN":     &cfg.Storage.DSN,
+		"FULCRUM_ADMIN_TOKEN":     &cfg.Admin.Token,
+		"FULCRUM_LLM_PROVIDER":    &cfg.LLM.Provider,
+		"FULCRUM_LLM_ENDPOINT":    &cfg.LLM.Endpoint,
+		"FULCRUM_LLM_API_KEY":     &cfg.LLM.APIKey,
+		"FULCRUM_LLM_MODEL":       &cfg.LLM.Model,
+		"FULCRUM_ANALYSIS_FORMAT": &cfg.Analysis.Format,
+		"FULCRUM_WEBHOOK_LINK":    &cfg.Webhooks.LinkTemplate,
+	}
+	for name, target := range text {
+		if v, ok := lookup(name); ok {
+			*target = v
+		}
+	}
+
+	lists := map[string]*[]string{
+		"FULCRUM_CORS_ORIGINS":    &cfg.CORS.AllowedOrigins,
+		"FULCRUM_ANALYSIS_STAGES": &cfg.Analysis.Stages,
+	}
+	for name, target := range lists {
+		if v, ok := lookup(name); ok {
+			*target = splitList(v)
+		}
+	}
+
+	ints := map[string]*int64{
+		"FULCRUM_SERVER_MAX_BODY_BYTES":        &cfg.Server.MaxBodyBytes,
+		"FULCRUM_ANALYSIS_MEMORY_BUDGET_BYTES": &cfg.Analysis.MemoryBudgetBytes,
+	}
+	for name, target := range ints {
+		if v, ok := lookup(name); ok {
+			n, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+			*target = n
+		}
+	}
+	if v, ok := lookup("FULCRUM_LLM_MAX_TOKENS"); ok {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("FULCRUM_LLM_MAX_TOKENS: %w", err)
+		}
+		cfg.LLM.MaxTokens = n
+	}
+
+	durations := map[string]*Duration{
+		"FULCRUM_SERVER_READ_TIMEOUT":     &cfg.Server.ReadTimeout,
+		"FULCRUM_SERVER_WRITE_TIMEOUT":    &cfg.Server.WriteTimeout,
+		"FULCRUM_SERVER_REQUEST_TIMEOUT":  &cfg.Server.RequestTimeout,
+		"FULCRUM_SERVER_SHUTDOWN_TIMEOUT": &cfg.Server.ShutdownTimeout,
+		"FULCRUM_LLM_TIMEOUT":             &cfg.LLM.Timeout,
+		"FULCRUM_WEBHOOK_TIMEOUT":         &cfg.Webhooks.Timeout,
+	}
+	for name, target := range durations {
+		if v, ok := lookup(name); ok {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+			*target = Duration(d)
+		}
+	}
+	return nil
+}
+
+// Validate rejects values that would make the server misbehave at runtime
+func (cfg Config) Validate() error {
+	if cfg.Server.Addr == "" {
+		return fmt.Errorf("server.addr is required")
+	}
+	if cfg.Server.MaxBodyBytes <= 0 {
+		return fmt.Errorf("server.max_body_bytes must be positive")
+	}
+	timeouts := map[string]Duration{
+		"server.read_timeout":     cfg.Server.ReadTimeout,
+		"server.write_timeout":    cfg.Server.WriteTimeout,
+		"server.request_timeout":  cfg.Server.RequestTimeout,
+		"server.shutdown_timeout": cfg.Server.ShutdownTimeout,
+		"llm.timeout":             cfg.LLM.Timeout,
+	}
+	for name, d := range timeouts {
+		if d <= 0 {
+			return fmt.Errorf("%s must be positive", name)
+		}
+	}
+	if cfg.Server.WriteTimeout < cfg.Server.RequestTimeout {
+		return fmt.Errorf("server.write_timeout (%s) must not be shorter than server.request_timeout (%s)",
+			time.Duration(cfg.Server.WriteTimeout), time.Duration(cfg.Server.RequestTimeout))
+	}
+	for _, origin := range cfg.CORS.AllowedOrigins {
+		if origin != "*" && !strings.HasPrefix(origin, "http://") && !strings.HasPrefix(origin, "https://") {
+			return fmt.Errorf("cors.allowed_origins: %q must be \"*\" or start with http:// or https://", origin)
+		}
+	}
+	if cfg.LLM.Provider != "" {
+		if _, err := analyzer.NewLLMRewriter(cfg.LLMConfig()); err != nil {
+			return fmt.Errorf("llm: %w", err)
+		}
+	}
+	if _, err := analyzer.NewWebhookNotifier(cfg.WebhookConfig()); err != nil {
+		return fmt.Errorf("webhooks: %w", err)
+	}
+	if cfg.Analysis.MemoryBudgetBytes <= 0 {
+		return fmt.Errorf("analysis.memory_budget_bytes must be positive")
+	}
+	if err := cfg.AnalysisOptions().Validate(); err != nil {
+		return fmt.Errorf("analysis: %w", err)
+	}
+	return nil
+}
+
+// ServerConfig returns the limits for analyzer.NewServer and AnalyzeHandler
+func (cfg Config) ServerConfig() analyzer.ServerConfig {
+	return analyzer.ServerConfig{
+		Addr:            cfg.Server.Addr,
+		MaxBodyBytes:    cfg.Server.MaxBodyBytes,
+		ReadTimeout:     time.Duration(cfg.Server.ReadTimeout),
+		WriteTimeout:    time.Duration(cfg.Server.WriteTimeout),
+		RequestTimeout:  time.Duration(cfg.Server.RequestTimeout),
+		ShutdownTimeout: time.Duration(cfg.Server.ShutdownTimeout),
+	}
+}
+
+// LLMConfig returns the settings for analyzer.NewLLMRewriter
+func (cfg Config) LLMConfig() analyzer.LLMRewriterConfig {
+	return analyzer.LLMRewriterConfig{
+		Provider:  cfg.LLM.Provider,
+		Endpoint:  cfg.LLM.Endpoint,
+		APIKey:    cfg.LLM.APIKey,
+		Model:     cfg.LLM.Model,
+		MaxTokens: cfg.LLM.MaxTokens,
+		Timeout:   time.Duration(cfg.LLM.Timeout),
+	}
+}
+
+// WebhookConfig returns the settings for analyzer.NewWebhookNotifier
+func (cfg Config) WebhookConfig() analyzer.WebhookConfig {
+	return analyzer.WebhookConfig{
+		Hooks:        cfg.Webhooks.Hooks,
+		Gate:         cfg.Webhooks.Gate,
+		LinkTemplate: cfg.Webhooks.LinkTemplate,
+		Timeout:      time.Duration(cfg.Webhooks.Timeout),
+	}
+}
+
+// AnalysisOptions returns the default stage selection and result format
+func (cfg Config) AnalysisOptions() analyzer.AnalysisOptions {
+	return analyzer.AnalysisOptions{Stages: cfg.Analysis.Stages, Format: cfg.Analysis.Format, Rules: cfg.Analysis.Rules, Stopwords: cfg.Analysis.Stopwords, Glossary: cfg.Analysis.Glossary, Spelling: cfg.Analysis.Spelling, Classifier: cfg.Analysis.Classifier, Rubric: cfg.Analysis.Rubric, ReadingSpeed: cfg.Analysis.ReadingSpeed, InclusiveLanguage: cfg.Analysis.InclusiveLanguage, ClusteringStrategy: cfg.Analysis.ClusteringStrategy, Deterministic: cfg.Analysis.Deterministic, MaxClusterSentences: cfg.Analysis.MaxClusterSentences, MaxSuggestionExamples: cfg.Analysis.MaxSuggestionExamples, FullTransformationLog: cfg.Analysis.FullTransformationLog, PhoneRegion: cfg.Analysis.PhoneRegion, ReferenceTime: cfg.Analysis.ReferenceTime, StripEmoji: cfg.Analysis.StripEmoji, Cleaning: cfg.Analysis.Cleaning, PreserveStructure: cfg.Analysis.PreserveStructure, TokenStream: cfg.Analysis.TokenStream}
+}
+
+// MemoryBudget returns the analyzer memory budget
+func (cfg Config) MemoryBudget() analyzer.MemoryBudget {
+	return analyzer.MemoryBudget{LimitBytes: cfg.Analysis.MemoryBudgetBytes}
+}
+
+// AllowsOrigin reports whether a CORS request from origin should be accepted
+func (cfg Config) AllowsOrigin(origin string) bool {
+	for _, allowed := range cfg.CORS.AllowedOrigins {
+		if allowed == "*" || allowed == origin {
+			return true
+		}
+	}
+	return false
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
@@ -0,0 +1,144 @@
+//go:build !js
+
+package config
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// env returns a lookup over vars in place of os.LookupEnv
+func env(vars map[string]string) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		v, ok := vars[name]
+		return v, ok
+	}
+}
+
+func writeConfig(t *testing.T, name, data string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// TestLoadExample checks that the shipped YAML example loads as written
+func TestLoadExample(t *testing.T) {
+	cfg, err := load("../../config.example.yaml", env(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Server.Addr != ":8080" || time.Duration(cfg.Server.WriteTimeout) != 35*time.Second {
+		t.Errorf("server = %+v", cfg.Server)
+	}
+	if !cfg.AllowsOrigin("http://localhost:8081") || cfg.AllowsOrigin("https://example.com") {
+		t.Errorf("cors = %v", cfg.CORS.AllowedOrigins)
+	}
+	if len(cfg.Analysis.Glossary) != 1 || cfg.Analysis.Glossary[0].Aliases[0] != "service level agreement" {
+		t.Errorf("glossary = %+v", cfg.Analysis.Glossary)
+	}
+}
+
+// TestLoadFormats checks that YAML and JSON files set the same fields and
+// leave the rest at their defaults
+func TestLoadFormats(t *testing.T) {
+	files := map[string]string{
+		"fulcrum.yaml": "server:\n  addr: \":9090\"\n  request_timeout: 5s\nanalysis:\n  stages: [grade]\n",
+		"fulcrum.json": `{"server": {"addr": ":9090", "request_timeout": "5s"}, "analysis": {"stages": ["grade"]}}`,
+	}
+	for name, data := range files {
+		cfg, err := load(writeConfig(t, name, data), env(nil))
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if cfg.Server.Addr != ":9090" || time.Duration(cfg.Server.RequestTimeout) != 5*time.Second {
+			t.Errorf("%s: server = %+v", name, cfg.Server)
+		}
+		if len(cfg.Analysis.Stages) != 1 || cfg.Analysis.Stages[0] != "grade" {
+			t.Errorf("%s: stages = %v", name, cfg.Analysis.Stages)
+		}
+		if cfg.Server.MaxBodyBytes != Default().Server.MaxBodyBytes {
+			t.Errorf("%s: max_body_bytes = %d, want the default", name, cfg.Server.MaxBodyBytes)
+		}
+	}
+}
+
+// TestLoadEnvOverrides checks that FULCRUM_* variables win over the file
+func TestLoadEnvOverrides(t *testing.T) {
+	path := writeConfig(t, "fulcrum.yaml", "server:\n  addr: \":9090\"\n")
+	cfg, err := load(path, env(map[string]string{
+		"FULCRUM_SERVER_ADDR":            ":7070",
+		"FULCRUM_SERVER_MAX_BODY_BYTES":  "2048",
+		"FULCRUM_SERVER_REQUEST_TIMEOUT": "20s",
+		"FULCRUM_CORS_ORIGINS":           "https://a.example, https://b.example",
+		"FULCRUM_ADMIN_TOKEN":            "secret",
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Server.Addr != ":7070" || cfg.Server.MaxBodyBytes != 2048 || time.Duration(cfg.Server.RequestTimeout) != 20*time.Second {
+		t.Errorf("server = %+v", cfg.Server)
+	}
+	if len(cfg.CORS.AllowedOrigins) != 2 || !cfg.AllowsOrigin("https://b.example") {
+		t.Errorf("cors = %v", cfg.CORS.AllowedOrigins)
+	}
+	if cfg.Admin.Token != "secret" {
+		t.Errorf("admin token = %q", cfg.Admin.Token)
+	}
+}
+
+// TestLoadRejects checks that bad files and variables fail instead of
+// falling back to defaults
+func TestLoadRejects(t *testing.T) {
+	cases := []struct {
+		name string
+		file string
+		env  map[string]string
+		want string
+	}{
+		{"unknown field", "server:\n  adress: \":9090\"\n", nil, "adress"},
+		{"bad yaml", "server:\n\taddr: \":9090\"\n", nil, "tab"},
+		{"bad duration in file", "server:\n  read_timeout: soon\n", nil, "soon"},
+		{"bad int variable", "", map[string]string{"FULCRUM_SERVER_MAX_BODY_BYTES": "1MB"}, "FULCRUM_SERVER_MAX_BODY_BYTES"},
+		{"bad duration variable", "", map[string]string{"FULCRUM_SERVER_READ_TIMEOUT": "10"}, "FULCRUM_SERVER_READ_TIMEOUT"},
+		{"negative body limit", "", map[string]string{"FULCRUM_SERVER_MAX_BODY_BYTES": "-1"}, "max_body_bytes"},
+		{"write shorter than request", "server:\n  write_timeout: 5s\n", nil, "write_timeout"},
+		{"bad origin", "cors:\n  allowed_origins: [example.com]\n", nil, "example.com"},
+		{"unknown stage", "analysis:\n  stages: [astrology]\n", nil, "astrology"},
+	}
+	for _, c := range cases {
+		path := ""
+		if c.file != "" {
+			path = writeConfig(t, "fulcrum.yaml", c.file)
+		}
+		_, err := load(path, env(c.env))
+		if err == nil || !strings.Contains(err.Error(), c.want) {
+			t.Errorf("%s: error = %v, want one mentioning %q", c.name, err, c.want)
+		}
+	}
+}
+
+// TestFromFlags checks that -config names the file to load
+func TestFromFlags(t *testing.T) {
+	path := writeConfig(t, "fulcrum.yaml", "server:\n  addr: \":9191\"\n")
+	fs := flag.NewFlagSet("fulcrum-server", flag.ContinueOnError)
+	cfg, err := FromFlags(fs, []string{"-config", path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if os.Getenv("FULCRUM_SERVER_ADDR") == "" && cfg.Server.Addr != ":9191" {
+		t.Errorf("addr = %q", cfg.Server.Addr)
+	}
+
+	fs = flag.NewFlagSet("fulcrum-server", flag.ContinueOnError)
+	if _, err := FromFlags(fs, []string{"-config", filepath.Join(t.TempDir(), "missing.yaml")}); err == nil {
+		t.Error("missing config file: expected an error")
+	}
+}
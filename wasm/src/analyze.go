@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"syscall/js"
+
+	"fulcrum-wasm/internal/analyzer"
+)
+
+// analysisRun carries the per-request state runAnalysis needs
+type analysisRun struct {
+	ctx context.Context
+	id  string // Request ID; generated when empty
+	// yield, when set, is called between stages so async callers can let the
+	// JS event loop run (and deliver abort events)
+	yield func()
+	// onProgress, when set, receives stage start and completion events
+	onProgress func(analyzer.ProgressEvent)
+}
+
+// compressedResult is a JSON result gzipped for opts.Compression; JS receives it
+// as a Uint8Array
+type compressedResult []byte
+
+// runAnalysis runs the selected analysis stages on text and returns the CombinedResult
+// as a JSON string, as a compressedResult when opts.Compression is "gzip", or as plain
+// maps and slices for js.ValueOf when opts.Format is "object".
+// Cancellation is checked before each stage; a stage already running finishes first.
+func runAnalysis(run *analysisRun, text string, opts analyzer.AnalysisOptions) (interface{}, error) {
+	combined, err := analyzer.Analyze(run.ctx, text, opts, analyzer.AnalysisRun{
+		RequestID:  run.id,
+		Yield:      run.yield,
+		OnProgress: run.onProgress,
+	})
+	if err != nil {
+		return nil, err
+	}
+	run.id = combined.Performance.RequestID
+	perf := &combined.Performance
+
+	if opts.Format == analyzer.FormatObject {
+		// Build JS-ready values directly instead of a JSON string
+		perf.AddSubOperation("json_marshaling", 0)
+		conversionTimer := analyzer.NewTimer("object_conversion")
+		perf.AddSubOperation("object_conversion", 0)
+		plain := analyzer.SelectResultFields(analyzer.ToPlain(*combined).(map[string]interface{}), opts)
+		perf.AddSubOperationAt("object_conversion", conversionTimer.StartedAt(), conversionTimer.Stop())
+		recordTelemetry(perf)
+		return plain, nil
+	}
+
+	b, err := analyzer.MarshalResult(combined, opts)
+	recordTelemetry(perf)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Compression != analyzer.CompressionNone {
+		compressed, err := analyzer.CompressResult(b, opts.Compression)
+		if err != nil {
+			return nil, err
+		}
+		return compressedResult(compressed), nil
+	}
+	return string(b), nil
+}
+
+// jsResult converts a runAnalysis result into a value js.ValueOf accepts
+func jsResult(result interface{}) interface{} {
+	if b, ok := result.(compressedResult); ok {
+		arr := js.Global().Get("Uint8Array").New(len(b))
+		js.CopyBytesToJS(arr, b)
+		return arr
+	}
+	return result
+}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"syscall/js"
+	"time"
+
+	"fulcrum-wasm/internal/analyzer"
+)
+
+// analyzeAsync is the JS binding analyzeAsync(text, options) -> Promise<string|object>.
+// See progressCallback for the onProgress option.
+// The analysis runs on a goroutine so the caller's event loop keeps running.
+// options accepts AnalysisOptions fields plus an AbortSignal-style "signal"
+// (anything with an aborted flag and addEventListener("abort", fn)).
+// The returned Promise carries the registry ID as promise.requestId.
+func analyzeAsync(this js.Value, args []js.Value) interface{} {
+	var text string
+	options := js.Undefined()
+	if len(args) > 0 {
+		text = args[0].String()
+	}
+	if len(args) > 1 {
+		options = args[1]
+	}
+
+	var requestID string
+	executor := js.FuncOf(func(this js.Value, p []js.Value) interface{} {
+		resolve, reject := p[0], p[1]
+
+		opts, err := analysisOptionsFromJS(options)
+		if err != nil {
+			reject.Invoke(jsError("TypeError", err.Error()))
+			return nil
+		}
+		if signal := abortSignal(options); signal.Type() == js.TypeObject && signal.Get("aborted").Truthy() {
+			reject.Invoke(jsError("AbortError", "analysis was aborted"))
+			return nil
+		}
+
+		req, ctx := registry.register(false)
+		requestID = req.id
+		release := watchAbortSignal(options, req.id)
+		registry.run(req, ctx, text, opts, progressCallback(options), func(req *analysisRequest) {
+			release()
+			settle(req, resolve, reject)
+		})
+		return nil
+	})
+	// The Promise constructor calls the executor synchronously
+	defer executor.Release()
+	promise := js.Global().Get("Promise").New(executor)
+	if requestID != "" {
+		promise.Set("requestId", requestID)
+	}
+	return promise
+}
+
+// startAnalysis is the JS binding startAnalysis(text, options) -> {success, id} or {success, error}.
+// The result goes to options.onComplete(data, id) or options.onError(error, id)
+// when given; otherwise it is held until getAnalysisResult(id) collects it.
+func startAnalysis(this js.Value, args []js.Value) interface{} {
+	if len(args) < 1 {
+		return map[string]interface{}{
+			"success": false,
+			"error":   "startAnalysis expects text and optional options",
+		}
+	}
+	options := js.Undefined()
+	if len(args) > 1 {
+		options = args[1]
+	}
+	opts, err := analysisOptionsFromJS(options)
+	if err != nil {
+		return map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
+		}
+	}
+
+	onComplete, onError := jsCallback(options, "onComplete"), jsCallback(options, "onError")
+	keep := onComplete.IsUndefined() && onError.IsUndefined()
+
+	req, ctx := registry.register(keep)
+	release := watchAbortSignal(options, req.id)
+	registry.run(req, ctx, args[0].String(), opts, progressCallback(options), func(req *analysisRequest) {
+		release()
+		switch {
+		case req.state == requestDone && !onComplete.IsUndefined():
+			onComplete.Invoke(jsResult(req.result), req.id)
+		case req.state != requestDone && !onError.IsUndefined():
+			onError.Invoke(requestError(req), req.id)
+		}
+	})
+	return map[string]interface{}{
+		"success": true,
+		"id":      req.id,
+	}
+}
+
+// cancelAnalysis is the JS binding cancelAnalysis(id) -> boolean
+func cancelAnalysis(this js.Value, args []js.Value) interface{} {
+	if len(args) < 1 {
+		return false
+	}
+	return registry.cancel(args[0].String())
+}
+
+// getAnalysisResult is the JS binding getAnalysisResult(id) -> {id, state, data?, error?} or null.
+// Finished requests are removed from the registry once returned.
+func getAnalysisResult(this js.Value, args []js.Value) interface{} {
+	if len(args) < 1 {
+		return nil
+	}
+	req, ok := registry.take(args[0].String())
+	if !ok {
+		return nil
+	}
+	return req.status()
+}
+
+// listAnalyses is the JS binding listAnalyses() -> [{id, state, elapsed_ms}]
+func listAnalyses(this js.Value, args []js.Value) interface{} {
+	requests := registry.list()
+	out := make([]interface{}, len(requests))
+	for i, req := range requests {
+		status := req.status()
+		delete(status, "data")
+		out[i] = status
+	}
+	return out
+}
+
+// progressCallback wraps options.onProgress, which is called with
+// {request_id, type: "stage_start"|"stage_complete", stage, completed, total,
+// percent, elapsed_ms, duration_ms}. It returns nil when no callback is set.
+func progressCallback(options js.Value) func(analyzer.ProgressEvent) {
+	fn := jsCallback(options, "onProgress")
+	if fn.IsUndefined() {
+		return nil
+	}
+	return func(event analyzer.ProgressEvent) {
+		fn.Invoke(progressEventToJS(event))
+	}
+}
+
+// progressEventToJS lays out a progress event for JS
+func progressEventToJS(event analyzer.ProgressEvent) map[string]interface{} {
+	percent := 0.0
+	if event.Total > 0 {
+		percent = float64(event.Completed) / float64(event.Total) * 100
+	}
+	return map[string]interface{}{
+		"request_id":  event.RequestID,
+		"type":        event.Type,
+		"stage":       event.Stage,
+		"completed":   event.Completed,
+		"total":       event.Total,
+		"percent":     percent,
+		"elapsed_ms":  event.ElapsedMs,
+		"duration_ms": event.DurationMs,
+	}
+}
+
+// settle resolves or rejects a Promise from a finished request
+func settle(req *analysisRequest, resolve, reject js.Value) {
+	if req.state == requestDone {
+		resolve.Invoke(jsResult(req.result))
+		return
+	}
+	reject.Invoke(requestError(req))
+}
+
+// requestError converts a failed or canceled request into a JS Error
+func requestError(req *analysisRequest) js.Value {
+	if req.state == requestCanceled {
+		return jsError("AbortError", "analysis was aborted")
+	}
+	return jsError("Error", req.err.Error())
+}
+
+// yieldToEventLoop parks the calling goroutine on a short timer. Once every goroutine
+// is parked the Go runtime returns control to JS, which keeps the page responsive
+// and lets pending abort events reach the analysis.
+func yieldToEventLoop() {
+	time.Sleep(time.Millisecond)
+}
+
+// abortSignal returns options.signal, or undefined when there is none
+func abortSignal(options js.Value) js.Value {
+	if options.Type() != js.TypeObject {
+		return js.Undefined()
+	}
+	return options.Get("signal")
+}
+
+// jsCallback returns options[name] if it is a function, or undefined
+func jsCallback(options js.Value, name string) js.Value {
+	if options.Type() != js.TypeObject {
+		return js.Undefined()
+	}
+	if fn := options.Get(name); fn.Type() == js.TypeFunction {
+		return fn
+	}
+	return js.Undefined()
+}
+
+// watchAbortSignal cancels the request when options.signal fires and returns a func that detaches the listener
+func watchAbortSignal(options js.Value, id string) func() {
+	signal := abortSignal(options)
+	if signal.Type() != js.TypeObject {
+		return func() {}
+	}
+	if signal.Get("aborted").Truthy() {
+		registry.cancel(id)
+		return func() {}
+	}
+	if signal.Get("addEventListener").Type() != js.TypeFunction {
+		return func() {}
+	}
+
+	onAbort := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		registry.cancel(id)
+		return nil
+	})
+	signal.Call("addEventListener", "abort", onAbort)
+	return func() {
+		signal.Call("removeEventListener", "abort", onAbort)
+		onAbort.Release()
+	}
+}
+
+// jsError builds a JS Error with the given name, e.g. "AbortError"
+func jsError(name, message string) js.Value {
+	e := js.Global().Get("Error").New(message)
+	e.Set("name", name)
+	return e
+}
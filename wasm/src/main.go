@@ -1,35 +1,84 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"runtime"
 	"strings"
 	"sync"
 	"syscall/js"
-	"time"
 
 	"fulcrum-wasm/internal/analyzer"
 )
 
-type CombinedResult struct {
-	Complexity    analyzer.ComplexityMetrics   `json:"complexity_metrics"`
-	Tokens        analyzer.TokenData           `json:"tokens"`
-	Preprocessing analyzer.PreprocessingData   `json:"preprocessing"`
-	Performance   analyzer.PerformanceMetrics  `json:"performance_metrics"`
-	Ideas         analyzer.IdeaAnalysisMetrics `json:"idea_analysis"`
-	Insights      analyzer.InsightAnalysis     `json:"insights"`
-	TaskGraph     analyzer.TaskGraph           `json:"task_graph"`
-	PromptGrade   analyzer.PromptGrade         `json:"prompt_grade"`
-	TestField     string                       `json:"test_field"`
+// CombinedResult is the full analysis result, shared with server builds
+type CombinedResult = analyzer.CombinedResult
+
+// Per-stage latency across requests plus the most recent request's timings
+var (
+	stageMetrics  = analyzer.NewStageMetrics()
+	telemetryMu   sync.Mutex
+	lastRequestPerf *analyzer.PerformanceMetrics
+)
+
+// recordTelemetry feeds a finished request into the latency registry
+func recordTelemetry(perf *analyzer.PerformanceMetrics) {
+	stageMetrics.Observe(perf)
+	telemetryMu.Lock()
+	lastRequestPerf = perf
+	telemetryMu.Unlock()
+}
+
+// analysisOptionsFromJS reads AnalysisOptions from a JS object or a JSON string
+func analysisOptionsFromJS(v js.Value) (analyzer.AnalysisOptions, error) {
+	switch v.Type() {
+	case js.TypeUndefined, js.TypeNull:
+		return analyzer.AnalysisOptions{}, nil
+	case js.TypeString:
+		return analyzer.ParseAnalysisOptions([]byte(v.String()))
+	}
+	if signal := v.Get("signal"); !signal.IsUndefined() {
+		// The cancel token is read separately; copy the options without it
+		v = js.Global().Get("Object").Call("assign", js.Global().Get("Object").New(), v)
+		v.Delete("signal")
+	}
+	return analyzer.ParseAnalysisOptions([]byte(js.Global().Get("JSON").Call("stringify", v).String()))
+}
+
+// setRuntimeConfig is the JS binding setRuntimeConfig(patch?) -> {success, config, error?}.
+// patch is a partial RuntimeConfig as an object or JSON string, e.g. {workers: 4},
+// which also resizes GOMAXPROCS; without one the current limits are returned unchanged.
+func setRuntimeConfig(this js.Value, args []js.Value) interface{} {
+	patch := "{}"
+	if len(args) > 0 {
+		switch args[0].Type() {
+		case js.TypeString:
+			patch = args[0].String()
+		case js.TypeObject:
+			patch = js.Global().Get("JSON").Call("stringify", args[0]).String()
+		}
+	}
+	cfg, err := analyzer.PatchRuntimeConfig([]byte(patch))
+	if err == nil && cfg.Workers > 0 {
+		runtime.GOMAXPROCS(cfg.Workers)
+	}
+	out := map[string]interface{}{
+		"success": err == nil,
+		"config":  analyzer.ToPlain(cfg),
+	}
+	if err != nil {
+		out["error"] = err.Error()
+	}
+	return out
 }
 
 // processText performs text operations and analysis
 func processText(this js.Value, args []js.Value) interface{} {
-	if len(args) != 2 {
+	if len(args) != 2 && len(args) != 3 {
 		return map[string]interface{}{
 			"success": false,
-			"error":   "processText expects exactly two arguments: operation and text",
+			"error":   "processText expects two or three arguments: operation, text and optional options",
 		}
 	}
 
@@ -38,214 +87,205 @@ func processText(this js.Value, args []js.Value) interface{} {
 
 	switch operation {
 	case "analyze":
-		// Add panic recovery to prevent crashes
-		defer func() {
-			if r := recover(); r != nil {
-				fmt.Printf("Recovered from panic: %v\n", r)
-			}
-		}()
-		
-		// Force garbage collection before heavy analysis
-		runtime.GC()
-		
-		// Initialize performance tracking
-		requestID := fmt.Sprintf("req_%d", time.Now().UnixNano())
-		perf := analyzer.NewPerformanceMetrics(requestID)
-		
-		// Create worker pool with limited goroutines (2 for WASM environment)
-		pool := analyzer.NewWorkerPool(2)
-		defer pool.Close()
-		
-		var comp analyzer.ComplexityMetrics
-		var tok analyzer.TokenData
-		var pre analyzer.PreprocessingData
-		var ideas analyzer.IdeaAnalysisMetrics
-		
-		// Track individual operation durations
-		var complexityDur, tokenDur, preprocessDur, ideaDur time.Duration
-		var mu sync.Mutex // Protect concurrent writes
-
-		// Submit tasks to worker pool instead of creating unlimited goroutines
-		pool.Submit(func() {
-			defer func() {
-				if r := recover(); r != nil {
-					fmt.Printf("Complexity analysis panic: %v\n", r)
-				}
-			}()
-			timer := analyzer.NewTimer("complexity_analysis")
-			result := analyzer.AnalyzeComplexity(text)
-			dur := timer.Stop()
-			mu.Lock()
-			comp = result
-			complexityDur = dur
-			mu.Unlock()
-		})
-		
-		pool.Submit(func() {
-			defer func() {
-				if r := recover(); r != nil {
-					fmt.Printf("Tokenization panic: %v\n", r)
+		opts := analyzer.AnalysisOptions{}
+		if len(args) == 3 {
+			var err error
+			if opts, err = analysisOptionsFromJS(args[2]); err != nil {
+				return map[string]interface{}{
+					"success": false,
+					"error":   err.Error(),
 				}
-			}()
-			timer := analyzer.NewTimer("tokenization")
-			result := analyzer.TokenizeText(text)
-			dur := timer.Stop()
-			mu.Lock()
-			tok = result
-			tokenDur = dur
-			mu.Unlock()
-		})
-		
-		pool.Submit(func() {
-			defer func() {
-				if r := recover(); r != nil {
-					fmt.Printf("Preprocessing panic: %v\n", r)
-				}
-			}()
-			timer := analyzer.NewTimer("preprocessing")
-			result := analyzer.PreprocessText(text)
-			dur := timer.Stop()
-			mu.Lock()
-			pre = result
-			preprocessDur = dur
-			mu.Unlock()
-		})
-		
-		pool.Submit(func() {
-			defer func() {
-				if r := recover(); r != nil {
-					fmt.Printf("Idea analysis panic: %v\n", r)
+			}
+		}
+		run := &analysisRun{ctx: context.Background()}
+		if len(args) == 3 {
+			run.onProgress = progressCallback(args[2])
+		}
+		result, err := runAnalysis(run, text, opts)
+		if err != nil {
+			return map[string]interface{}{
+				"success": false,
+				"error":   err.Error(),
+			}
+		}
+		return map[string]interface{}{
+			"success": true,
+			"data":    jsResult(result),
+		}
+
+	case "rewrite":
+		// The LLM rewriter is server-only; the browser gets the rule-based rewrite
+		comparison := analyzer.CompareRewrites(context.Background(), text, analyzer.NewRuleBasedRewriter())
+		analyzer.Normalize(&comparison)
+		b, err := json.Marshal(comparison)
+		if err != nil {
+			return map[string]interface{}{
+				"success": false,
+				"error":   fmt.Sprintf("failed to marshal rewrite: %v", err),
+			}
+		}
+		return map[string]interface{}{
+			"success": true,
+			"data":    string(b),
+		}
+
+	case "evaluate":
+		// text is a JSON {prompt, response} pair; the browser has no model to answer with
+		var req analyzer.EvaluateRequest
+		if err := json.Unmarshal([]byte(text), &req); err != nil || strings.TrimSpace(req.Prompt) == "" {
+			return map[string]interface{}{
+				"success": false,
+				"error":   "evaluate expects a JSON object with prompt and response",
+			}
+		}
+		b, err := json.Marshal(analyzer.EvaluateResponse(req.Prompt, req.Response))
+		if err != nil {
+			return map[string]interface{}{
+				"success": false,
+				"error":   fmt.Sprintf("failed to marshal evaluation: %v", err),
+			}
+		}
+		return map[string]interface{}{
+			"success": true,
+			"data":    string(b),
+		}
+
+	case "whatif":
+		// text is a JSON WhatIfRequest: the prompt and the changes to simulate
+		var req analyzer.WhatIfRequest
+		if err := json.Unmarshal([]byte(text), &req); err != nil {
+			return map[string]interface{}{
+				"success": false,
+				"error":   "whatif expects a JSON object with text and changes",
+			}
+		}
+		result, err := analyzer.SimulateChanges(req)
+		if err != nil {
+			return map[string]interface{}{
+				"success": false,
+				"error":   err.Error(),
+			}
+		}
+		b, err := json.Marshal(result)
+		if err != nil {
+			return map[string]interface{}{
+				"success": false,
+				"error":   fmt.Sprintf("failed to marshal what-if result: %v", err),
+			}
+		}
+		return map[string]interface{}{
+			"success": true,
+			"data":    string(b),
+		}
+
+	case "metrics":
+		// Prometheus text format for every analysis run in this module instance
+		var sb strings.Builder
+		if err := stageMetrics.WritePrometheus(&sb); err != nil {
+			return map[string]interface{}{
+				"success": false,
+				"error":   fmt.Sprintf("failed to write metrics: %v", err),
+			}
+		}
+		return map[string]interface{}{
+			"success": true,
+			"data":    sb.String(),
+		}
+
+	case "trace":
+		// OTLP/JSON spans for the most recent analysis; text is the service name
+		telemetryMu.Lock()
+		perf := lastRequestPerf
+		telemetryMu.Unlock()
+		if perf == nil {
+			return map[string]interface{}{
+				"success": false,
+				"error":   "no analysis has been run yet",
+			}
+		}
+		serviceName := strings.TrimSpace(text)
+		if serviceName == "" {
+			serviceName = "fulcrum-wasm"
+		}
+		b, err := json.Marshal(perf.ToOTLPTraces(serviceName))
+		if err != nil {
+			return map[string]interface{}{
+				"success": false,
+				"error":   fmt.Sprintf("failed to marshal trace: %v", err),
+			}
+		}
+		return map[string]interface{}{
+			"success": true,
+			"data":    string(b),
+		}
+
+	case "schema":
+		// JSON Schema for the "analyze" result so clients can validate against it
+		b, err := json.Marshal(analyzer.GenerateJSONSchema(CombinedResult{}, "Fulcrum analysis result"))
+		if err != nil {
+			return map[string]interface{}{
+				"success": false,
+				"error":   fmt.Sprintf("failed to marshal schema: %v", err),
+			}
+		}
+		return map[string]interface{}{
+			"success": true,
+			"data":    string(b),
+		}
+
+	case "corpus":
+		// text is a JSON array of {name, text} documents, analyzed with the options
+		var docs []analyzer.CorpusDocument
+		if err := json.Unmarshal([]byte(text), &docs); err != nil {
+			return map[string]interface{}{
+				"success": false,
+				"error":   fmt.Sprintf("corpus expects a JSON array of {name, text} documents: %v", err),
+			}
+		}
+		opts := analyzer.AnalysisOptions{}
+		if len(args) == 3 {
+			var err error
+			if opts, err = analysisOptionsFromJS(args[2]); err != nil {
+				return map[string]interface{}{
+					"success": false,
+					"error":   err.Error(),
 				}
-			}()
-			timer := analyzer.NewTimer("idea_analysis")
-			result := analyzer.AnalyzeIdeas(text)
-			dur := timer.Stop()
-			mu.Lock()
-			ideas = result
-			ideaDur = dur
-			mu.Unlock()
-		})
-
-		// Wait for all tasks to complete
-		pool.Wait()
-		
-		// Force GC after parallel processing
-		runtime.GC()
-		
-		// Extract task graph from ideas
-		taskGraphTimer := analyzer.NewTimer("task_graph_extraction")
-		// Extract sentences from existing idea clusters
-		var sentences []string
-		// Limit debug output for large texts
-		if len(ideas.SemanticClusters.Value) < 30 {
-			fmt.Printf("DEBUG: Number of idea clusters: %d\n", len(ideas.SemanticClusters.Value))
-		}
-		for i, cluster := range ideas.SemanticClusters.Value {
-			// Only log first few clusters to prevent log spam
-			if i < 5 {
-				fmt.Printf("DEBUG: Cluster %d has %d sentences\n", i, len(cluster.Sentences))
 			}
-			sentences = append(sentences, cluster.Sentences...)
-		}
-		fmt.Printf("DEBUG: Total sentences collected: %d\n", len(sentences))
-		
-		// If no sentences from clusters, use a simple split as fallback
-		if len(sentences) == 0 {
-			fmt.Println("DEBUG: No sentences from clusters, using simple split")
-			// Simple sentence split
-			sentences = strings.Split(text, ". ")
-			for i := range sentences {
-				sentences[i] = strings.TrimSpace(sentences[i])
+		}
+		report, err := analyzer.AnalyzeCorpus(context.Background(), docs, opts)
+		if err != nil {
+			return map[string]interface{}{
+				"success": false,
+				"error":   err.Error(),
 			}
-			fmt.Printf("DEBUG: Simple split got %d sentences\n", len(sentences))
-		}
-		
-		taskGraph := analyzer.ExtractTaskGraph(text, sentences, ideas.SemanticClusters.Value)
-		taskGraphDur := taskGraphTimer.Stop()
-		
-		// Debug logging
-		fmt.Printf("DEBUG: TaskGraph parsed - Total tasks: %d\n", taskGraph.TotalTasks)
-		if taskGraph.TotalTasks > 0 {
-			fmt.Printf("DEBUG: First task: %s\n", taskGraph.Tasks[0].Title)
-		}
-		
-		// Ensure arrays are not nil for JSON marshaling
-		if taskGraph.Tasks == nil {
-			taskGraph.Tasks = []analyzer.Task{}
-		}
-		if taskGraph.Relationships == nil {
-			taskGraph.Relationships = []analyzer.TaskRelationship{}
-		}
-		if taskGraph.RootTasks == nil {
-			taskGraph.RootTasks = []string{}
-		}
-		if taskGraph.LeafTasks == nil {
-			taskGraph.LeafTasks = []string{}
-		}
-		if taskGraph.CriticalPath == nil {
-			taskGraph.CriticalPath = []string{}
-		}
-		
-		// Generate insights from all metrics (after all analysis is complete)
-		insightTimer := analyzer.NewTimer("insight_generation")
-		insights := analyzer.TransformToInsights(comp, ideas, tok, pre)
-		insightDur := insightTimer.Stop()
-		
-		// Calculate prompt grade
-		gradeTimer := analyzer.NewTimer("prompt_grade_calculation")
-		promptGrade := analyzer.CalculatePromptGrade(comp, tok, pre, ideas, *taskGraph, text)
-		gradeDur := gradeTimer.Stop()
-		
-		// Debug logging for prompt grade
-		fmt.Printf("DEBUG: PromptGrade calculated - Overall score: %.2f, Grade: %s\n", 
-			promptGrade.OverallGrade.Score, promptGrade.OverallGrade.Grade)
-		
-		// Finalize performance metrics
-		perf.Finalize(complexityDur, tokenDur, preprocessDur)
-		perf.AddSubOperation("idea_analysis", ideaDur)
-		perf.AddSubOperation("task_graph_extraction", taskGraphDur)
-		perf.AddSubOperation("insight_generation", insightDur)
-		perf.AddSubOperation("prompt_grade_calculation", gradeDur)
-		
-		// Add any additional sub-operations timing if needed
-		perf.AddSubOperation("json_marshaling", 0) // Will be updated below
-		
-		marshalTimer := analyzer.NewTimer("json_marshaling")
-	combined := CombinedResult{
-		Complexity:    comp,
-		Tokens:        tok,
-		Preprocessing: pre,
-		Performance:   *perf,
-		Ideas:         ideas,
-		Insights:      insights,
-		TaskGraph:     *taskGraph,
-		PromptGrade:   *promptGrade,
-		TestField:     "THIS IS A TEST",
-	}
-		
-		// Measure JSON marshaling time
-		b, err := json.Marshal(combined)
-		marshalDur := marshalTimer.Stop()
-		
-		// DEBUG: Check if task_graph and prompt_grade are in the JSON
-		if strings.Contains(string(b), "task_graph") {
-			fmt.Println("✅ task_graph found in marshaled JSON")
-		} else {
-			fmt.Println("❌ task_graph NOT FOUND in marshaled JSON")
-		}
-		if strings.Contains(string(b), "prompt_grade") {
-			fmt.Println("✅ prompt_grade found in marshaled JSON")
-		} else {
-			fmt.Println("❌ prompt_grade NOT FOUND in marshaled JSON")
-		}
-		
-		// Update the marshaling timing in performance metrics
-		perf.AddSubOperation("json_marshaling", marshalDur)
-		
+		}
+		b, err := json.Marshal(report)
+		if err != nil {
+			return map[string]interface{}{
+				"success": false,
+				"error":   fmt.Sprintf("failed to marshal corpus report: %v", err),
+			}
+		}
+		return map[string]interface{}{
+			"success": true,
+			"data":    string(b),
+		}
+
+	case "sarif":
+		// SARIF log of the prompt's findings, with the prompt as a single artifact
+		result, err := analyzer.Analyze(context.Background(), text, analyzer.AnalysisOptions{}, analyzer.AnalysisRun{})
 		if err != nil {
 			return map[string]interface{}{
 				"success": false,
-				"error":   fmt.Sprintf("failed to marshal result: %v", err),
+				"error":   err.Error(),
+			}
+		}
+		sarif := analyzer.ExportSARIF([]analyzer.SARIFDocument{{URI: "prompt.txt", Text: text, Result: result}})
+		b, err := json.Marshal(sarif)
+		if err != nil {
+			return map[string]interface{}{
+				"success": false,
+				"error":   fmt.Sprintf("failed to marshal SARIF: %v", err),
 			}
 		}
 		return map[string]interface{}{
@@ -253,6 +293,104 @@ func processText(this js.Value, args []js.Value) interface{} {
 			"data":    string(b),
 		}
 
+	case "rules":
+		// Catalog of suggestion rule IDs for building a rules config
+		b, err := json.Marshal(analyzer.SuggestionRules)
+		if err != nil {
+			return map[string]interface{}{
+				"success": false,
+				"error":   fmt.Sprintf("failed to marshal rules: %v", err),
+			}
+		}
+		return map[string]interface{}{
+			"success": true,
+			"data":    string(b),
+		}
+
+	case "openapi":
+		// OpenAPI description of the HTTP API, shared with server builds
+		b, err := json.Marshal(analyzer.OpenAPISpec(CombinedResult{}))
+		if err != nil {
+			return map[string]interface{}{
+				"success": false,
+				"error":   fmt.Sprintf("failed to marshal OpenAPI spec: %v", err),
+			}
+		}
+		return map[string]interface{}{
+			"success": true,
+			"data":    string(b),
+		}
+
+	case "lint":
+		report := analyzer.LintPromptStructure(text, "")
+		analyzer.Normalize(&report)
+		b, err := json.Marshal(report)
+		if err != nil {
+			return map[string]interface{}{
+				"success": false,
+				"error":   fmt.Sprintf("failed to marshal lint report: %v", err),
+			}
+		}
+		return map[string]interface{}{
+			"success": true,
+			"data":    string(b),
+		}
+
+	case "transformation_step":
+		// Full input and output of one preprocessing step, named by options.step;
+		// the transformation log in analyze results only carries diffs
+		if len(args) != 3 || args[2].Get("step").Type() != js.TypeString {
+			return map[string]interface{}{
+				"success": false,
+				"error":   "transformation_step expects options with a step name, e.g. {step: \"stemming\"}",
+			}
+		}
+		before, after, err := analyzer.PreprocessText(text).StepText(args[2].Get("step").String())
+		if err != nil {
+			return map[string]interface{}{
+				"success": false,
+				"error":   err.Error(),
+			}
+		}
+		return map[string]interface{}{
+			"success": true,
+			"data":    map[string]interface{}{"before": before, "after": after},
+		}
+
+	case "template":
+		// text holds the prompt type, e.g. "data_analysis"
+		return map[string]interface{}{
+			"success": true,
+			"data":    analyzer.GetPromptTemplate(analyzer.PromptType(strings.TrimSpace(text))).Render(),
+		}
+
+	case "export_github", "export_jira", "export_csv":
+		graph := analyzer.ExtractTaskGraphFromText(text)
+		var data string
+		var err error
+		switch operation {
+		case "export_github":
+			var b []byte
+			b, err = json.Marshal(analyzer.ExportGitHubIssues(graph))
+			data = string(b)
+		case "export_jira":
+			var b []byte
+			b, err = analyzer.ExportJiraBulkJSON(graph, "FUL")
+			data = string(b)
+		default:
+			data, err = analyzer.ExportTasksCSV(graph)
+		}
+		if err != nil {
+			return map[string]interface{}{
+				"success": false,
+				"error":   fmt.Sprintf("failed to export task graph: %v", err),
+			}
+		}
+		return map[string]interface{}{
+			"success": true,
+			"data":    data,
+		}
+
 	case "uppercase":
 		return map[string]interface{}{
 			"success": true,
@@ -297,14 +435,23 @@ func processText(this js.Value, args []js.Value) interface{} {
 // Global channel to prevent the program from exiting
 var keepAlive = make(chan struct{})
 
+var shutdownOnce sync.Once
+
+// shutdown lets main return; safe to call more than once
+func shutdown() {
+	shutdownOnce.Do(func() {
+		fmt.Println("Cleaning up WASM module...")
+		close(keepAlive)
+	})
+}
+
 func main() {
-	// Set GOMAXPROCS to a reasonable value for WASM
-	runtime.GOMAXPROCS(2)
+	// Size GOMAXPROCS and the worker pool from the host's cores
+	applyWorkers(hostWorkers())
 	
 	// Set up cleanup handler
 	js.Global().Set("cleanupWasm", js.FuncOf(func(this js.Value, args []js.Value) interface{} {
-		fmt.Println("Cleaning up WASM module...")
-		close(keepAlive)
+		shutdown()
 		return nil
 	}))
 
@@ -319,6 +466,14 @@ func main() {
 		return processText(this, args)
 	}))
 
+	js.Global().Set("analyzeAsync", js.FuncOf(analyzeAsync))
+	js.Global().Set("startAnalysis", js.FuncOf(startAnalysis))
+	js.Global().Set("cancelAnalysis", js.FuncOf(cancelAnalysis))
+	js.Global().Set("getAnalysisResult", js.FuncOf(getAnalysisResult))
+	js.Global().Set("listAnalyses", js.FuncOf(listAnalyses))
+	js.Global().Set("fulcrumHandleMessage", js.FuncOf(handleWorkerMessage))
+	js.Global().Set("setRuntimeConfig", js.FuncOf(setRuntimeConfig))
+
 	// Signal that WASM module is ready
 	js.Global().Set("wasmReady", js.ValueOf(true))
 
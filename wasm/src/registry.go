@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"fulcrum-wasm/internal/analyzer"
+)
+
+// Request states reported to JS
+const (
+	requestRunning  = "running"
+	requestDone     = "done"
+	requestFailed   = "failed"
+	requestCanceled = "canceled"
+)
+
+// analysisRequest is one in-flight or finished analysis tracked by the registry
+type analysisRequest struct {
+	id        string
+	cancel    context.CancelFunc
+	state     string
+	stage     string // Most recently started stage
+	result    interface{}
+	err       error
+	startedAt time.Time
+	elapsed   time.Duration
+	keep      bool // Hold the result until getAnalysisResult collects it
+}
+
+// requestRegistry lets several analyses run at once, each with its own
+// context, so JS can cancel and collect them independently by request ID
+type requestRegistry struct {
+	mu       sync.Mutex
+	next     uint64
+	requests map[string]*analysisRequest
+}
+
+var registry = &requestRegistry{requests: make(map[string]*analysisRequest)}
+
+// register reserves an ID for a new request. If keep is set the entry stays
+// registered after it finishes until take collects it.
+func (r *requestRegistry) register(keep bool) (*analysisRequest, context.Context) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.next++
+	req := &analysisRequest{
+		id:        fmt.Sprintf("req_%d", r.next),
+		cancel:    cancel,
+		state:     requestRunning,
+		startedAt: time.Now(),
+		keep:      keep,
+	}
+	r.requests[req.id] = req
+	return req, ctx
+}
+
+// run executes a registered request on its own goroutine and calls done when it finishes.
+// onProgress, if set, receives the request's stage events.
+func (r *requestRegistry) run(req *analysisRequest, ctx context.Context, text string, opts analyzer.AnalysisOptions,
+	onProgress func(analyzer.ProgressEvent), done func(*analysisRequest)) {
+	cancel := req.cancel
+	run := &analysisRun{ctx: ctx, id: req.id, yield: yieldToEventLoop}
+	run.onProgress = func(event analyzer.ProgressEvent) {
+		if event.Type == analyzer.ProgressStageStart {
+			r.mu.Lock()
+			req.stage = event.Stage
+			r.mu.Unlock()
+		}
+		if onProgress != nil {
+			onProgress(event)
+		}
+	}
+
+	go func() {
+		defer cancel()
+		result, err := runAnalysis(run, text, opts)
+
+		r.mu.Lock()
+		req.elapsed = time.Since(req.startedAt)
+		switch {
+		case errors.Is(err, context.Canceled):
+			req.state, req.err = requestCanceled, err
+		case err != nil:
+			req.state, req.err = requestFailed, err
+		default:
+			req.state, req.result = requestDone, result
+		}
+		if !req.keep {
+			delete(r.requests, req.id)
+		}
+		r.mu.Unlock()
+
+		if done != nil {
+			done(req)
+		}
+	}()
+}
+
+// cancel stops a running request; it reports false for unknown or finished IDs
+func (r *requestRegistry) cancel(id string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	req, ok := r.requests[id]
+	if !ok || req.state != requestRunning {
+		return false
+	}
+	req.cancel()
+	return true
+}
+
+// take returns a snapshot of a request and unregisters it once it has finished
+func (r *requestRegistry) take(id string) (analysisRequest, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	req, ok := r.requests[id]
+	if !ok {
+		return analysisRequest{}, false
+	}
+	if req.state != requestRunning {
+		delete(r.requests, id)
+	}
+	return *req, true
+}
+
+// list returns snapshots of every registered request ordered by start time
+func (r *requestRegistry) list() []analysisRequest {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]analysisRequest, 0, len(r.requests))
+	for _, req := range r.requests {
+		snapshot := *req
+		if snapshot.state == requestRunning {
+			snapshot.elapsed = time.Since(snapshot.startedAt)
+		}
+		out = append(out, snapshot)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].startedAt.Before(out[j].startedAt) })
+	return out
+}
+
+// status describes a request for JS
+func (req analysisRequest) status() map[string]interface{} {
+	status := map[string]interface{}{
+		"id":         req.id,
+		"state":      req.state,
+		"stage":      req.stage,
+		"elapsed_ms": float64(req.elapsed.Microseconds()) / 1000,
+	}
+	if req.state == requestDone {
+		status["data"] = jsResult(req.result)
+	}
+	if req.err != nil {
+		status["error"] = req.err.Error()
+	}
+	return status
+}
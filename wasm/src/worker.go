@@ -0,0 +1,163 @@
+package main
+
+import (
+	"sync"
+	"syscall/js"
+
+	"fulcrum-wasm/internal/analyzer"
+)
+
+// Worker message protocol. The host posts {type, id, payload} and the module
+// replies with messages carrying the same id:
+//
+//	process  {operation, text, options?}  -> result {success, data|error}
+//	analyze  {text, options?}             -> progress* then result {encoding, buffer} | error
+//	cancel   (id of a running analyze)    -> error {name: "AbortError"} for that analyze
+//	shutdown                              -> closed, then the Go program exits
+//
+// analyze results in the default JSON format are sent as a UTF-8 ArrayBuffer in
+// the transfer list so large results move to the host without a copy. With
+// options.compression "gzip" the buffer holds gzipped JSON and encoding is
+// "gzip"; the host decompresses it.
+
+// workerAnalyses maps host message IDs to registry request IDs
+var (
+	workerMu       sync.Mutex
+	workerAnalyses = make(map[int]string)
+)
+
+// handleWorkerMessage is the JS binding fulcrumHandleMessage(data); worker
+// scripts forward every message other than their own init to it
+func handleWorkerMessage(this js.Value, args []js.Value) interface{} {
+	if len(args) < 1 || args[0].Type() != js.TypeObject {
+		return nil
+	}
+	msg := args[0]
+	id := msg.Get("id").Int()
+	payload := msg.Get("payload")
+
+	switch msgType := msg.Get("type").String(); msgType {
+	case "process":
+		processArgs := []js.Value{payload.Get("operation"), payload.Get("text")}
+		if opts := payload.Get("options"); !opts.IsUndefined() {
+			processArgs = append(processArgs, opts)
+		}
+		postWorkerMessage("result", id, js.ValueOf(processText(js.Undefined(), processArgs)), nil)
+
+	case "analyze":
+		startWorkerAnalysis(id, payload)
+
+	case "cancel":
+		workerMu.Lock()
+		requestID, ok := workerAnalyses[id]
+		workerMu.Unlock()
+		if ok {
+			registry.cancel(requestID)
+		}
+
+	case "shutdown":
+		for _, req := range registry.list() {
+			registry.cancel(req.id)
+		}
+		postWorkerMessage("closed", id, js.Undefined(), nil)
+		shutdown()
+
+	default:
+		postWorkerMessage("error", id, js.ValueOf(map[string]interface{}{
+			"name":    "TypeError",
+			"message": "unknown message type: " + msgType,
+		}), nil)
+	}
+	return nil
+}
+
+// startWorkerAnalysis runs an analyze message through the request registry
+func startWorkerAnalysis(id int, payload js.Value) {
+	options := js.Undefined()
+	if payload.Type() == js.TypeObject {
+		options = payload.Get("options")
+	}
+	opts, err := analysisOptionsFromJS(options)
+	if err != nil {
+		postWorkerMessage("error", id, js.ValueOf(map[string]interface{}{
+			"name":    "TypeError",
+			"message": err.Error(),
+		}), nil)
+		return
+	}
+
+	req, ctx := registry.register(false)
+	workerMu.Lock()
+	workerAnalyses[id] = req.id
+	workerMu.Unlock()
+
+	onProgress := func(event analyzer.ProgressEvent) {
+		postWorkerMessage("progress", id, js.ValueOf(progressEventToJS(event)), nil)
+	}
+	registry.run(req, ctx, payload.Get("text").String(), opts, onProgress, func(req *analysisRequest) {
+		workerMu.Lock()
+		delete(workerAnalyses, id)
+		workerMu.Unlock()
+
+		switch {
+		case req.state != requestDone:
+			e := requestError(req)
+			postWorkerMessage("error", id, js.ValueOf(map[string]interface{}{
+				"name":    e.Get("name").String(),
+				"message": e.Get("message").String(),
+			}), nil)
+		case isCompressedResult(req.result):
+			buffer := bytesToArrayBuffer(req.result.(compressedResult))
+			postWorkerMessage("result", id, js.ValueOf(map[string]interface{}{
+				"request_id": req.id,
+				"encoding":   "gzip",
+				"buffer":     buffer,
+			}), []interface{}{buffer})
+		case isJSONResult(req.result):
+			buffer := bytesToArrayBuffer([]byte(req.result.(string)))
+			postWorkerMessage("result", id, js.ValueOf(map[string]interface{}{
+				"request_id": req.id,
+				"encoding":   "json",
+				"buffer":     buffer,
+			}), []interface{}{buffer})
+		default:
+			postWorkerMessage("result", id, js.ValueOf(map[string]interface{}{
+				"request_id": req.id,
+				"encoding":   "object",
+				"data":       req.result,
+			}), nil)
+		}
+	})
+}
+
+// postWorkerMessage posts a reply to the host, transferring any listed buffers
+func postWorkerMessage(msgType string, id int, payload js.Value, transfer []interface{}) {
+	msg := js.ValueOf(map[string]interface{}{"type": msgType, "id": id})
+	if !payload.IsUndefined() {
+		msg.Set("payload", payload)
+	}
+	if len(transfer) > 0 {
+		js.Global().Call("postMessage", msg, js.ValueOf(transfer))
+		return
+	}
+	js.Global().Call("postMessage", msg)
+}
+
+// isJSONResult reports whether a runAnalysis result is a JSON string rather than plain values
+func isJSONResult(result interface{}) bool {
+	_, ok := result.(string)
+	return ok
+}
+
+// isCompressedResult reports whether a runAnalysis result holds gzipped JSON
+func isCompressedResult(result interface{}) bool {
+	_, ok := result.(compressedResult)
+	return ok
+}
+
+// bytesToArrayBuffer copies b into a fresh ArrayBuffer
+func bytesToArrayBuffer(b []byte) js.Value {
+	arr := js.Global().Get("Uint8Array").New(len(b))
+	js.CopyBytesToJS(arr, b)
+	return arr.Get("buffer")
+}
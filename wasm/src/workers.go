@@ -0,0 +1,50 @@
+package main
+
+import (
+	"runtime"
+	"syscall/js"
+
+	"fulcrum-wasm/internal/analyzer"
+)
+
+// Worker pool sizing for the WASM build
+const (
+	defaultWasmWorkers = 2 // When the host does not report its cores
+	maxWasmWorkers     = 8 // Stages per analysis never use more
+)
+
+// hostWorkers picks the pool size: a fulcrumWorkers global set before the
+// module starts wins, then navigator.hardwareConcurrency, then 2 in hosts
+// that report neither (older Node, locked-down embeds, privacy modes that
+// hide the core count)
+func hostWorkers() int {
+	if n := positiveInt(js.Global().Get("fulcrumWorkers")); n > 0 {
+		return min(n, maxWasmWorkers)
+	}
+	if nav := js.Global().Get("navigator"); nav.Type() == js.TypeObject {
+		if n := positiveInt(nav.Get("hardwareConcurrency")); n > 0 {
+			return min(n, maxWasmWorkers)
+		}
+	}
+	return defaultWasmWorkers
+}
+
+// positiveInt reads a JS number, returning 0 for anything else
+func positiveInt(v js.Value) int {
+	if v.Type() != js.TypeNumber {
+		return 0
+	}
+	if n := v.Int(); n > 0 {
+		return n
+	}
+	return 0
+}
+
+// applyWorkers sizes GOMAXPROCS and the analyzer's default pool together
+func applyWorkers(n int) {
+	runtime.GOMAXPROCS(n)
+	analyzer.UpdateRuntimeConfig(func(c *analyzer.RuntimeConfig) error {
+		c.Workers = n
+		return nil
+	})
+}